@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// apiPrefix is the versioned API every request goes to
+const apiPrefix = "/api/v1"
+
+// client calls the REST API with an API key or bearer token
+type client struct {
+	server string
+	apiKey string
+	token  string
+	http   *http.Client
+}
+
+// response is models.TradeResponse with its data left undecoded
+type response struct {
+	Success   bool            `json:"success"`
+	TradeID   string          `json:"tradeId"`
+	Message   string          `json:"message"`
+	Data      json.RawMessage `json:"data"`
+	Error     string          `json:"error"`
+	ErrorCode string          `json:"errorCode"`
+}
+
+// client returns an API client for the configured server and credentials
+func (o *options) client() (*client, error) {
+	if o.apiKey == "" && o.token == "" {
+		o.apiKey = envOr("TRADING_API_KEY", os.Getenv("API_KEY"))
+		o.token = os.Getenv("TRADING_API_TOKEN")
+	}
+	if o.apiKey == "" && o.token == "" {
+		return nil, fmt.Errorf("no credentials: set --api-key or TRADING_API_KEY (or --token / TRADING_API_TOKEN)")
+	}
+	return &client{
+		server: strings.TrimRight(o.server, "/"),
+		apiKey: o.apiKey,
+		token:  o.token,
+		http:   &http.Client{Timeout: o.timeout},
+	}, nil
+}
+
+// do sends a request to path (under /api/v1) and returns the response. A
+// failed request or an unsuccessful response is an error.
+func (c *client) do(ctx context.Context, method, path string, query url.Values, body interface{}) (*response, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	endpoint := c.server + apiPrefix + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.authorize(req.Header)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	var result response
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("unexpected response (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if resp.StatusCode >= http.StatusBadRequest || !result.Success {
+		if result.Error != "" {
+			return &result, fmt.Errorf("%s: %s%s", result.Message, result.Error, codeSuffix(result.ErrorCode))
+		}
+		return &result, fmt.Errorf("%s (HTTP %d)%s", result.Message, resp.StatusCode, codeSuffix(result.ErrorCode))
+	}
+	return &result, nil
+}
+
+// codeSuffix formats an errorCode for error messages
+func codeSuffix(code string) string {
+	if code == "" {
+		return ""
+	}
+	return " [" + code + "]"
+}
+
+// get sends a GET request and decodes the response data into data
+func (c *client) get(ctx context.Context, path string, query url.Values, data interface{}) (*response, error) {
+	resp, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp, resp.decode(data)
+}
+
+// authorize adds the API key or bearer token to request headers
+func (c *client) authorize(header http.Header) {
+	if c.apiKey != "" {
+		header.Set("X-API-Key", c.apiKey)
+	} else if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
+// decode unmarshals the response data
+func (r *response) decode(data interface{}) error {
+	if data == nil || len(r.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.Data, data); err != nil {
+		return fmt.Errorf("unexpected response data: %v", err)
+	}
+	return nil
+}
+
+// printJSON writes the response data indented, for --json
+func printJSON(r *response) error {
+	var out bytes.Buffer
+	if len(r.Data) == 0 {
+		fmt.Println("null")
+		return nil
+	}
+	if err := json.Indent(&out, r.Data, "", "  "); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	_, err := out.WriteTo(os.Stdout)
+	return err
+}
@@ -0,0 +1,67 @@
+// Command trading-cli manages the trading API from a terminal: open and close
+// trades, list positions, show the trading summary and follow live updates.
+//
+// The server and credentials come from flags or the TRADING_API_URL,
+// TRADING_API_KEY and TRADING_API_TOKEN environment variables. Inside the
+// server's container the API_KEY it runs with is used.
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// options are the flags shared by every command
+type options struct {
+	server  string
+	apiKey  string
+	token   string
+	timeout time.Duration
+	json    bool
+}
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	opts := &options{}
+
+	root := &cobra.Command{
+		Use:          "trading-cli",
+		Short:        "Manage the trading API from the command line",
+		SilenceUsage: true,
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.server, "server", envOr("TRADING_API_URL", "http://localhost:8080"), "API base URL (TRADING_API_URL)")
+	// Credentials are read from the environment later so --help never prints them
+	flags.StringVar(&opts.apiKey, "api-key", "", "API key (TRADING_API_KEY, or the server's API_KEY)")
+	flags.StringVar(&opts.token, "token", "", "JWT bearer token instead of an API key (TRADING_API_TOKEN)")
+	flags.DurationVar(&opts.timeout, "timeout", 30*time.Second, "Request timeout")
+	flags.BoolVar(&opts.json, "json", false, "Print the raw JSON response data")
+
+	root.AddCommand(
+		newTradeCommand(opts),
+		newPositionsCommand(opts),
+		newCloseCommand(opts),
+		newMarginCommand(opts),
+		newLeverageCommand(opts),
+		newMarginTypeCommand(opts),
+		newSummaryCommand(opts),
+		newWatchCommand(opts),
+	)
+	return root
+}
+
+// envOr returns an environment variable or a default value
+func envOr(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// position is one entry of GET /positions
+type position struct {
+	Symbol           string  `json:"symbol"`
+	Side             string  `json:"side"`
+	PositionAmt      float64 `json:"positionAmt"`
+	EntryPrice       float64 `json:"entryPrice"`
+	MarkPrice        float64 `json:"markPrice"`
+	UnrealizedProfit float64 `json:"unrealizedProfit"`
+	Leverage         int     `json:"leverage"`
+	LiquidationPrice float64 `json:"liquidationPrice"`
+	MarginType       string  `json:"marginType"`
+}
+
+func newPositionsCommand(opts *options) *cobra.Command {
+	var userID, account string
+
+	cmd := &cobra.Command{
+		Use:   "positions",
+		Short: "List open positions with unrealized PnL",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			defer cancel()
+
+			query := url.Values{}
+			if userID != "" {
+				query.Set("userId", userID)
+			}
+			if account != "" {
+				query.Set("account", account)
+			}
+
+			var data struct {
+				TotalPositions int        `json:"totalPositions"`
+				TotalPnL       float64    `json:"totalPnL"`
+				Positions      []position `json:"positions"`
+			}
+			api, err := opts.client()
+			if err != nil {
+				return err
+			}
+			resp, err := api.get(ctx, "/positions", query, &data)
+			if err != nil {
+				return err
+			}
+			if opts.json {
+				return printJSON(resp)
+			}
+
+			if len(data.Positions) == 0 {
+				fmt.Println("No open positions")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SYMBOL\tAMOUNT\tENTRY\tMARK\tPNL\tLEVERAGE\tLIQUIDATION\tMARGIN")
+			for _, p := range data.Positions {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.2f\t%dx\t%s\t%s\n", p.Symbol, formatFloat(p.PositionAmt),
+					formatFloat(p.EntryPrice), formatFloat(p.MarkPrice), p.UnrealizedProfit, p.Leverage,
+					formatFloat(p.LiquidationPrice), strings.ToUpper(p.MarginType))
+			}
+			w.Flush()
+			fmt.Printf("\n%d positions, unrealized PnL %.2f USDT\n", data.TotalPositions, data.TotalPnL)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&userID, "user", "", "User whose own Binance account to query (default: operator account)")
+	cmd.Flags().StringVar(&account, "account", "", "Operator account to query (main or a BINANCE_ACCOUNTS name)")
+	return cmd
+}
+
+func newCloseCommand(opts *options) *cobra.Command {
+	var tradeID, userID, account string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "close <symbol>",
+		Short: "Close an open position at market",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			symbol := strings.ToUpper(args[0])
+			if !yes && !confirm(fmt.Sprintf("Close the %s position at market?", symbol)) {
+				return fmt.Errorf("aborted")
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			defer cancel()
+
+			body := map[string]string{"symbol": symbol}
+			if tradeID != "" {
+				body["tradeId"] = tradeID
+			}
+			if userID != "" {
+				body["userId"] = userID
+			}
+			if account != "" {
+				body["account"] = account
+			}
+
+			api, err := opts.client()
+			if err != nil {
+				return err
+			}
+			resp, err := api.do(ctx, http.MethodPost, "/position/close", nil, body)
+			if err != nil {
+				return err
+			}
+			if opts.json {
+				return printJSON(resp)
+			}
+			fmt.Println(resp.Message)
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&tradeID, "trade", "", "Trade the position belongs to (records its PnL)")
+	flags.StringVar(&userID, "user", "", "Close on the user's own Binance account")
+	flags.StringVar(&account, "account", "", "Close on this operator account (main or a BINANCE_ACCOUNTS name)")
+	flags.BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+	return cmd
+}
+
+// confirm asks a yes/no question on the terminal
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	var answer string
+	fmt.Scanln(&answer)
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func newMarginCommand(opts *options) *cobra.Command {
+	var tradeID, userID, account string
+
+	cmd := &cobra.Command{
+		Use:   "margin <symbol> <add|remove> <amount>",
+		Short: "Add or remove isolated margin on an open position",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action := strings.ToUpper(args[1])
+			if action != "ADD" && action != "REMOVE" {
+				return fmt.Errorf("action must be add or remove")
+			}
+			amount, err := strconv.ParseFloat(args[2], 64)
+			if err != nil || amount <= 0 {
+				return fmt.Errorf("amount must be a positive number of USDT")
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			defer cancel()
+
+			body := map[string]interface{}{"symbol": strings.ToUpper(args[0]), "type": action, "amount": amount}
+			if tradeID != "" {
+				body["tradeId"] = tradeID
+			}
+			if userID != "" {
+				body["userId"] = userID
+			}
+			if account != "" {
+				body["account"] = account
+			}
+
+			api, err := opts.client()
+			if err != nil {
+				return err
+			}
+			resp, err := api.do(ctx, http.MethodPost, "/position/margin", nil, body)
+			if err != nil {
+				return err
+			}
+			if opts.json {
+				return printJSON(resp)
+			}
+			fmt.Println(resp.Message)
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&tradeID, "trade", "", "Trade the position belongs to (records the change)")
+	flags.StringVar(&userID, "user", "", "Change on the user's own Binance account")
+	flags.StringVar(&account, "account", "", "Change on this operator account (main or a BINANCE_ACCOUNTS name)")
+	return cmd
+}
+
+func newLeverageCommand(opts *options) *cobra.Command {
+	var userID, account string
+
+	cmd := &cobra.Command{
+		Use:   "leverage <symbol> <leverage>",
+		Short: "Change a symbol's leverage without placing an order",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			leverage, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(args[1]), "x"))
+			if err != nil || leverage < 1 {
+				return fmt.Errorf("leverage must be a positive whole number")
+			}
+			body := map[string]interface{}{"symbol": strings.ToUpper(args[0]), "leverage": leverage}
+			return postPositionSetting(cmd, opts, "/position/leverage", body, userID, account)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&userID, "user", "", "Change on the user's own Binance account")
+	flags.StringVar(&account, "account", "", "Change on this operator account (main or a BINANCE_ACCOUNTS name)")
+	return cmd
+}
+
+func newMarginTypeCommand(opts *options) *cobra.Command {
+	var userID, account string
+
+	cmd := &cobra.Command{
+		Use:   "margin-type <symbol> <isolated|crossed>",
+		Short: "Switch a symbol between isolated and cross margin",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			marginType := strings.ToUpper(args[1])
+			if marginType == "CROSS" {
+				marginType = "CROSSED"
+			}
+			if marginType != "ISOLATED" && marginType != "CROSSED" {
+				return fmt.Errorf("margin type must be isolated or crossed")
+			}
+			body := map[string]interface{}{"symbol": strings.ToUpper(args[0]), "marginType": marginType}
+			return postPositionSetting(cmd, opts, "/position/margin-type", body, userID, account)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&userID, "user", "", "Change on the user's own Binance account")
+	flags.StringVar(&account, "account", "", "Change on this operator account (main or a BINANCE_ACCOUNTS name)")
+	return cmd
+}
+
+// postPositionSetting sends a leverage or margin type change and prints the
+// result
+func postPositionSetting(cmd *cobra.Command, opts *options, path string, body map[string]interface{}, userID, account string) error {
+	ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+	defer cancel()
+
+	if userID != "" {
+		body["userId"] = userID
+	}
+	if account != "" {
+		body["account"] = account
+	}
+
+	api, err := opts.client()
+	if err != nil {
+		return err
+	}
+	resp, err := api.do(ctx, http.MethodPost, path, nil, body)
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		return printJSON(resp)
+	}
+	fmt.Println(resp.Message)
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func newSummaryCommand(opts *options) *cobra.Command {
+	var period, userID string
+
+	cmd := &cobra.Command{
+		Use:   "summary",
+		Short: "Show trading statistics for a period",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			defer cancel()
+
+			query := url.Values{"period": {period}}
+			if userID != "" {
+				query.Set("userId", userID)
+			}
+
+			var data struct {
+				TotalTrades       int            `json:"totalTrades"`
+				WinningTrades     int            `json:"winningTrades"`
+				LosingTrades      int            `json:"losingTrades"`
+				WinRate           float64        `json:"winRate"`
+				TotalPnL          float64        `json:"totalPnL"`
+				TotalVolume       float64        `json:"totalVolume"`
+				TotalFees         float64        `json:"totalFees"`
+				NetPnL            float64        `json:"netPnL"`
+				BestTrade         float64        `json:"bestTrade"`
+				WorstTrade        float64        `json:"worstTrade"`
+				AveragePnL        float64        `json:"averagePnL"`
+				SymbolStats       map[string]int `json:"symbolStats"`
+				CurrentAccountPnL *float64       `json:"currentAccountPnL"`
+			}
+			api, err := opts.client()
+			if err != nil {
+				return err
+			}
+			resp, err := api.get(ctx, "/summary", query, &data)
+			if err != nil {
+				return err
+			}
+			if opts.json {
+				return printJSON(resp)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "Period\t%s\n", period)
+			fmt.Fprintf(w, "Trades\t%d (%d won, %d lost)\n", data.TotalTrades, data.WinningTrades, data.LosingTrades)
+			fmt.Fprintf(w, "Win rate\t%.1f%%\n", data.WinRate)
+			fmt.Fprintf(w, "PnL\t%.2f USDT\n", data.TotalPnL)
+			fmt.Fprintf(w, "Fees\t%.2f USDT\n", data.TotalFees)
+			fmt.Fprintf(w, "Net PnL\t%.2f USDT\n", data.NetPnL)
+			fmt.Fprintf(w, "Average\t%.2f USDT\n", data.AveragePnL)
+			fmt.Fprintf(w, "Best / worst\t%.2f / %.2f USDT\n", data.BestTrade, data.WorstTrade)
+			fmt.Fprintf(w, "Volume\t%.2f USDT\n", data.TotalVolume)
+			if data.CurrentAccountPnL != nil {
+				fmt.Fprintf(w, "Unrealized\t%.2f USDT\n", *data.CurrentAccountPnL)
+			}
+			w.Flush()
+
+			if len(data.SymbolStats) > 0 {
+				symbols := make([]string, 0, len(data.SymbolStats))
+				for symbol := range data.SymbolStats {
+					symbols = append(symbols, symbol)
+				}
+				sort.Slice(symbols, func(i, j int) bool {
+					return data.SymbolStats[symbols[i]] > data.SymbolStats[symbols[j]]
+				})
+
+				fmt.Println()
+				w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "SYMBOL\tTRADES")
+				for _, symbol := range symbols {
+					fmt.Fprintf(w, "%s\t%d\n", symbol, data.SymbolStats[symbol])
+				}
+				w.Flush()
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&period, "period", "1d", "1d, 7d, 1w, 1m, 3m or 1y")
+	flags.StringVar(&userID, "user", "", "Only this user's trades")
+	return cmd
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"crypto-trading-api/internal/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newTradeCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trade",
+		Short: "Open and inspect trades",
+	}
+	cmd.AddCommand(newTradeOpenCommand(opts), newTradeGetCommand(opts))
+	return cmd
+}
+
+func newTradeOpenCommand(opts *options) *cobra.Command {
+	req := &models.TradeRequest{}
+	var preview, simulate, priceProtect bool
+
+	cmd := &cobra.Command{
+		Use:   "open",
+		Short: "Place a trade with stop loss and take profit",
+		Example: `  trading-cli trade open --user user123 --symbol BTCUSDT --side BUY --entry 50000 --sl 49000 --tp 52000 --leverage 10 --size 1000
+  trading-cli trade open --user user123 --symbol ETHUSDT --side SELL --entry 3000 --preset scalp-eth
+  trading-cli trade open --user user123 --symbol BTCUSDT --side BUY --entry 50000 --preset scalp-btc --preview
+  trading-cli trade open --user user123 --symbol BTCUSDT --side BUY --entry 50000 --sl 44000 --tp 50100 --leverage 10 --size 1000 --simulate`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req.Symbol = strings.ToUpper(req.Symbol)
+			req.Side = strings.ToUpper(req.Side)
+			req.OrderType = strings.ToUpper(req.OrderType)
+			req.MarginType = strings.ToUpper(req.MarginType)
+			req.WorkingType = strings.ToUpper(req.WorkingType)
+			if cmd.Flags().Changed("price-protect") {
+				req.PriceProtect = &priceProtect
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			defer cancel()
+
+			api, err := opts.client()
+			if err != nil {
+				return err
+			}
+			path := "/trade"
+			switch {
+			case simulate:
+				path = "/trade/simulate"
+			case preview:
+				path = "/trade/preview"
+			}
+			resp, err := api.do(ctx, http.MethodPost, path, nil, req)
+			if err != nil {
+				return err
+			}
+			if opts.json {
+				return printJSON(resp)
+			}
+			if preview || simulate {
+				return printTradePreview(resp)
+			}
+
+			trade, err := tradeFromResponse(resp)
+			if err != nil {
+				return err
+			}
+			fmt.Println(resp.Message)
+			printTrade(trade)
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&req.UserID, "user", "", "User the trade belongs to (required with API keys)")
+	flags.StringVar(&req.Symbol, "symbol", "", "Symbol, e.g. BTCUSDT")
+	flags.StringVar(&req.Side, "side", "", "BUY or SELL")
+	flags.Float64Var(&req.EntryPrice, "entry", 0, "Entry price")
+	flags.Float64Var(&req.StopLoss, "sl", 0, "Stop loss price")
+	flags.Float64Var(&req.TakeProfit, "tp", 0, "Take profit price")
+	flags.IntVar(&req.Leverage, "leverage", 0, "Leverage (1-125)")
+	flags.Float64Var(&req.Size, "size", 0, "Position size in USDT")
+	flags.StringVar(&req.OrderType, "type", "", "MARKET or LIMIT (default MARKET)")
+	flags.StringVar(&req.MarginType, "margin", "", "ISOLATED or CROSSED (default ISOLATED)")
+	flags.StringVar(&req.WorkingType, "working-type", "", "SL/TP trigger: MARK_PRICE or LAST_PRICE (default LAST_PRICE)")
+	flags.BoolVar(&priceProtect, "price-protect", false, "Hold SL/TP while mark and last price diverge")
+	flags.StringVar(&req.Preset, "preset", "", "Strategy preset supplying omitted parameters")
+	flags.StringVar(&req.Account, "account", "", "Operator account to trade on (main or a BINANCE_ACCOUNTS name)")
+	flags.BoolVar(&preview, "preview", false, "Validate the trade and estimate its fees without placing it")
+	flags.BoolVar(&simulate, "simulate", false, "Project PnL at SL, TP and liquidation without placing the trade")
+	cmd.MarkFlagRequired("symbol")
+	cmd.MarkFlagRequired("side")
+	cmd.MarkFlagRequired("entry")
+	return cmd
+}
+
+func newTradeGetCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <tradeId>",
+		Short: "Show a trade",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			defer cancel()
+
+			trade := &models.Trade{}
+			api, err := opts.client()
+			if err != nil {
+				return err
+			}
+			resp, err := api.get(ctx, "/trade/"+args[0], nil, trade)
+			if err != nil {
+				return err
+			}
+			if opts.json {
+				return printJSON(resp)
+			}
+			printTrade(trade)
+			return nil
+		},
+	}
+}
+
+// tradeFromResponse decodes the trade of POST /trade, which comes alone or
+// with its follower copies
+func tradeFromResponse(resp *response) (*models.Trade, error) {
+	var withCopies struct {
+		Trade *models.Trade `json:"trade"`
+	}
+	if err := resp.decode(&withCopies); err == nil && withCopies.Trade != nil {
+		return withCopies.Trade, nil
+	}
+
+	trade := &models.Trade{}
+	if err := json.Unmarshal(resp.Data, trade); err != nil {
+		return nil, fmt.Errorf("unexpected response data: %v", err)
+	}
+	return trade, nil
+}
+
+// printTrade writes a trade's fields, one per line
+func printTrade(trade *models.Trade) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID\t%s\n", trade.ID)
+	fmt.Fprintf(w, "User\t%s\n", trade.UserID)
+	fmt.Fprintf(w, "Symbol\t%s %s\n", trade.Symbol, trade.Side)
+	fmt.Fprintf(w, "Status\t%s\n", trade.Status)
+	if trade.QueuedUntil != 0 && trade.Status == "QUEUED" {
+		fmt.Fprintf(w, "Queued until\t%s\n", time.Unix(trade.QueuedUntil, 0).UTC().Format(time.RFC3339))
+	}
+	if trade.Adopted {
+		fmt.Fprintf(w, "Adopted\tyes\n")
+	}
+	fmt.Fprintf(w, "Entry\t%s\n", formatFloat(trade.EntryPrice))
+	if trade.ExecutedPrice != 0 {
+		fmt.Fprintf(w, "Executed\t%s\n", formatFloat(trade.ExecutedPrice))
+	}
+	fmt.Fprintf(w, "Stop loss\t%s\n", formatFloat(trade.StopLoss))
+	fmt.Fprintf(w, "Take profit\t%s\n", formatFloat(trade.TakeProfit))
+	if trade.WorkingType != "" || trade.PriceProtect {
+		trigger := trade.WorkingType
+		if trigger == "" {
+			trigger = models.WorkingTypeLast
+		}
+		if trade.PriceProtect {
+			trigger += ", price protect"
+		}
+		fmt.Fprintf(w, "Trigger\t%s\n", trigger)
+	}
+	fmt.Fprintf(w, "Size\t%s USDT x%d\n", formatFloat(trade.Size), trade.Leverage)
+	if trade.PnL != 0 {
+		fmt.Fprintf(w, "PnL\t%s\n", formatFloat(trade.PnL))
+	}
+	if trade.MaxAdverseExcursion != 0 || trade.MaxFavorableExcursion != 0 {
+		fmt.Fprintf(w, "MAE / MFE\t%s @ %s / %s @ %s\n",
+			formatFloat(trade.MaxAdverseExcursion), formatFloat(trade.MaxAdversePrice),
+			formatFloat(trade.MaxFavorableExcursion), formatFloat(trade.MaxFavorablePrice))
+	}
+	if timing := trade.Latency; timing != nil && timing.AcknowledgedMs != 0 {
+		fmt.Fprintf(w, "Latency\tack %dms (order %dms)", timing.AcknowledgedMs, timing.OrderMs)
+		if timing.FilledMs != 0 {
+			fmt.Fprintf(w, ", filled %dms", timing.FilledMs)
+		}
+		fmt.Fprintln(w)
+	}
+	if trade.CreatedAt != 0 {
+		fmt.Fprintf(w, "Created\t%s\n", time.Unix(trade.CreatedAt, 0).Format(time.RFC3339))
+	}
+	if trade.Error != "" {
+		fmt.Fprintf(w, "Error\t%s\n", trade.Error)
+	}
+	w.Flush()
+}
+
+// printTradePreview writes what POST /trade/preview says a trade would
+// place and cost, with the projections of POST /trade/simulate when present
+func printTradePreview(resp *response) error {
+	var preview struct {
+		Request        models.TradeRequest `json:"request"`
+		Quantity       float64             `json:"quantity"`
+		Notional       float64             `json:"notional"`
+		LossAtStop     float64             `json:"lossAtStop"`
+		ProfitAtTarget float64             `json:"profitAtTarget"`
+		FeeError       string              `json:"feeError"`
+
+		// Simulation only
+		RequiredMargin   float64  `json:"requiredMargin"`
+		LiquidationPrice float64  `json:"liquidationPrice"`
+		BreakEvenPrice   float64  `json:"breakEvenPrice"`
+		RiskReward       float64  `json:"riskReward"`
+		Warnings         []string `json:"warnings"`
+		Fees             *struct {
+			EntryLiquidity string  `json:"entryLiquidity"`
+			EntryFee       float64 `json:"entryFee"`
+			TotalAtStop    float64 `json:"totalAtStop"`
+			TotalAtTarget  float64 `json:"totalAtTarget"`
+			BNBDiscount    float64 `json:"bnbDiscount"`
+		} `json:"fees"`
+	}
+	if err := resp.decode(&preview); err != nil {
+		return fmt.Errorf("unexpected response data: %v", err)
+	}
+
+	req := preview.Request
+	fmt.Println(resp.Message)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Symbol\t%s %s %s\n", req.Symbol, req.Side, req.OrderType)
+	fmt.Fprintf(w, "Entry\t%s\n", formatFloat(req.EntryPrice))
+	fmt.Fprintf(w, "Stop loss\t%s\n", formatFloat(req.StopLoss))
+	fmt.Fprintf(w, "Take profit\t%s\n", formatFloat(req.TakeProfit))
+	fmt.Fprintf(w, "Size\t%s USDT x%d (%s notional, %s qty)\n", formatFloat(req.Size), req.Leverage, formatFloat(preview.Notional), formatFloat(preview.Quantity))
+	if fees := preview.Fees; fees != nil {
+		fmt.Fprintf(w, "Entry fee\t%s (%s)\n", formatFloat(fees.EntryFee), strings.ToLower(fees.EntryLiquidity))
+		fmt.Fprintf(w, "Fees at SL/TP\t%s / %s\n", formatFloat(fees.TotalAtStop), formatFloat(fees.TotalAtTarget))
+		if fees.BNBDiscount > 0 {
+			fmt.Fprintf(w, "BNB discount\t%s%%\n", formatFloat(fees.BNBDiscount*100))
+		}
+	} else if preview.FeeError != "" {
+		fmt.Fprintf(w, "Fees\tunavailable: %s\n", preview.FeeError)
+	}
+	fmt.Fprintf(w, "Loss at SL\t%s\n", formatFloat(preview.LossAtStop))
+	fmt.Fprintf(w, "Profit at TP\t%s\n", formatFloat(preview.ProfitAtTarget))
+	if preview.BreakEvenPrice != 0 {
+		fmt.Fprintf(w, "Margin\t%s\n", formatFloat(preview.RequiredMargin))
+		fmt.Fprintf(w, "Break-even\t%s\n", formatFloat(preview.BreakEvenPrice))
+		if preview.LiquidationPrice != 0 {
+			fmt.Fprintf(w, "Liquidation\t%s\n", formatFloat(preview.LiquidationPrice))
+		}
+		fmt.Fprintf(w, "Risk:reward\t%s\n", formatFloat(preview.RiskReward))
+	}
+	for _, warning := range preview.Warnings {
+		fmt.Fprintf(w, "Warning\t%s\n", warning)
+	}
+	return w.Flush()
+}
+
+// formatFloat prints a number without trailing zeros
+func formatFloat(value float64) string {
+	return fmt.Sprintf("%g", value)
+}
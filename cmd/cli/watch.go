@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/push"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spf13/cobra"
+)
+
+// maxReconnectDelay caps the wait between reconnects to /ws
+const maxReconnectDelay = 30 * time.Second
+
+// message is push.Message with its data left undecoded
+type message struct {
+	Type   string          `json:"type"`
+	UserID string          `json:"userId"`
+	Data   json.RawMessage `json:"data"`
+	Time   int64           `json:"time"`
+}
+
+func newWatchCommand(opts *options) *cobra.Command {
+	var types string
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Follow live trade, position and balance updates",
+		Long:  "Stream updates from /ws until interrupted, reconnecting when the connection drops.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			api, err := opts.client()
+			if err != nil {
+				return err
+			}
+			endpoint, err := watchURL(api.server, types)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			header := http.Header{}
+			api.authorize(header)
+
+			delay := time.Second
+			for {
+				connected, err := watch(ctx, endpoint, header, opts.json)
+				if ctx.Err() != nil {
+					return nil
+				}
+				// Refused outright (bad credentials or types): retrying will not help
+				var refused *handshakeError
+				if errors.As(err, &refused) {
+					return err
+				}
+				if connected {
+					delay = time.Second
+				}
+
+				fmt.Fprintf(os.Stderr, "Disconnected: %v (reconnecting in %v)\n", err, delay)
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					return nil
+				}
+				if delay < maxReconnectDelay {
+					delay *= 2
+				}
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&types, "types", "", "Comma-separated message types: trade, position, balance (default: all)")
+	return cmd
+}
+
+// watchURL turns the API base URL into the /ws endpoint
+func watchURL(server, types string) (string, error) {
+	u, err := url.Parse(strings.TrimRight(server, "/"))
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL: %v", err)
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http", "":
+		u.Scheme = "ws"
+	}
+	u.Path += "/ws"
+	if types != "" {
+		u.RawQuery = url.Values{"types": {types}}.Encode()
+	}
+	return u.String(), nil
+}
+
+// handshakeError is a /ws upgrade the server refused
+type handshakeError struct {
+	status int
+	reason string
+}
+
+func (e *handshakeError) Error() string {
+	return fmt.Sprintf("server refused connection (HTTP %d): %s", e.status, e.reason)
+}
+
+// watch prints messages until the connection drops or ctx is done. It
+// reports whether the connection was established.
+func watch(ctx context.Context, endpoint string, header http.Header, raw bool) (bool, error) {
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, endpoint, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode >= http.StatusBadRequest && resp.StatusCode < http.StatusInternalServerError &&
+			resp.StatusCode != http.StatusTooManyRequests {
+			var body response
+			json.NewDecoder(resp.Body).Decode(&body)
+			reason := body.Message
+			if body.Error != "" {
+				reason += ": " + body.Error
+			}
+			return false, &handshakeError{status: resp.StatusCode, reason: reason}
+		}
+		return false, err
+	}
+	defer conn.Close()
+
+	fmt.Fprintf(os.Stderr, "Connected to %s\n", endpoint)
+
+	// Unblock ReadMessage on interrupt
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		_, payload, err := conn.ReadMessage()
+		if err != nil {
+			return true, err
+		}
+		if raw {
+			fmt.Println(string(payload))
+			continue
+		}
+
+		var msg message
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			fmt.Println(string(payload))
+			continue
+		}
+		fmt.Println(formatMessage(&msg))
+	}
+}
+
+// formatMessage renders a push message on one line
+func formatMessage(msg *message) string {
+	at := time.Unix(msg.Time, 0).Format("15:04:05")
+
+	switch msg.Type {
+	case push.TypeTrade:
+		var trade models.Trade
+		if json.Unmarshal(msg.Data, &trade) == nil {
+			line := fmt.Sprintf("%s  trade     %s %-4s %-9s %s (user %s)", at, trade.Symbol, trade.Side, trade.Status, trade.ID, trade.UserID)
+			if trade.PnL != 0 {
+				line += fmt.Sprintf(" pnl %.2f", trade.PnL)
+			}
+			return line
+		}
+	case push.TypePosition:
+		var pos push.Position
+		if json.Unmarshal(msg.Data, &pos) == nil {
+			if pos.PositionAmt == 0 {
+				return fmt.Sprintf("%s  position  %s closed", at, pos.Symbol)
+			}
+			return fmt.Sprintf("%s  position  %s %s @ %s mark %s pnl %.2f", at, pos.Symbol, formatFloat(pos.PositionAmt),
+				formatFloat(pos.EntryPrice), formatFloat(pos.MarkPrice), pos.UnrealizedProfit)
+		}
+	case push.TypeBalance:
+		var bal push.Balance
+		if json.Unmarshal(msg.Data, &bal) == nil {
+			line := fmt.Sprintf("%s  balance   %s %.2f", at, bal.Asset, bal.WalletBalance)
+			if bal.Reason != "" {
+				line += " (" + bal.Reason + ")"
+			}
+			return line
+		}
+	}
+	return fmt.Sprintf("%s  %-9s %s", at, msg.Type, msg.Data)
+}
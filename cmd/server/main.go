@@ -5,13 +5,34 @@ import (
 	"crypto-trading-api/config"
 	_ "crypto-trading-api/docs" // Import generated Swagger docs
 	docs "crypto-trading-api/docs"
+	"crypto-trading-api/internal/alerts"
 	"crypto-trading-api/internal/api"
 	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/bot"
+	"crypto-trading-api/internal/calendar"
+	"crypto-trading-api/internal/cluster"
+	"crypto-trading-api/internal/dashboard"
+	"crypto-trading-api/internal/events"
 	"crypto-trading-api/internal/firebase"
-	"log"
+	"crypto-trading-api/internal/jwtauth"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/notifications"
+	"crypto-trading-api/internal/policy"
+	"crypto-trading-api/internal/push"
+	"crypto-trading-api/internal/redis"
+	"crypto-trading-api/internal/reports"
+	"crypto-trading-api/internal/storage"
+	"crypto-trading-api/internal/tracing"
+	"crypto-trading-api/internal/tradehistory"
+	"crypto-trading-api/internal/userstats"
+	"crypto-trading-api/internal/vault"
+	"crypto-trading-api/internal/webhooks"
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
@@ -62,6 +83,20 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Structured logs; credentials never reach the output
+	if err := logging.Init(logging.Config{Level: cfg.LogLevel, Format: cfg.LogFormat}); err != nil {
+		logging.Fatal().Err(err).Msg("Invalid logging configuration")
+	}
+	logging.AddSecrets(cfg.APIKey, cfg.TradeSigningSecret, cfg.JWTSecret, cfg.BinanceAPIKey, cfg.BinanceSecretKey,
+		cfg.CredentialsMasterKey, cfg.TelegramBotToken, cfg.SMTPPassword, cfg.VaultToken, os.Getenv("AWS_SECRET_ACCESS_KEY"))
+	for _, follower := range cfg.Followers {
+		logging.AddSecrets(follower.APIKey, follower.SecretKey)
+	}
+	for _, account := range cfg.Accounts {
+		logging.AddSecrets(account.APIKey, account.SecretKey)
+	}
+	defer func() { logging.Info().Msg("Server exited") }()
+
 	// Set Gin mode
 	gin.SetMode(cfg.GinMode)
 
@@ -69,34 +104,653 @@ func main() {
 	docs.SwaggerInfo.Host = cfg.SwaggerHost
 	docs.SwaggerInfo.Schemes = []string{"http", "https"}
 
-	// Initialize Firebase
-	firebaseClient, err := firebase.InitClient()
+	// OpenTelemetry spans for requests and their Binance/Firebase calls
+	if cfg.TracingEnabled {
+		shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+			Endpoint:    cfg.TracingEndpoint,
+			Insecure:    cfg.TracingInsecure,
+			ServiceName: cfg.TracingServiceName,
+			SampleRatio: cfg.TracingSampleRatio,
+		})
+		if err != nil {
+			logging.Warn().Err(err).Msg("Tracing disabled")
+		} else {
+			defer shutdownTracing(context.Background())
+		}
+	}
+
+	// Timeouts, retries, circuit breaker and connection pool for Firebase and
+	// Firestore calls
+	firebase.SetRequestPolicy(firebase.RequestPolicy{
+		Timeout:         cfg.FirebaseTimeout,
+		Retries:         cfg.FirebaseRetries,
+		RetryBackoff:    cfg.FirebaseRetryBackoff,
+		BreakerFailures: cfg.FirebaseBreakerFailures,
+		BreakerReset:    cfg.FirebaseBreakerReset,
+		HTTP:            cfg.FirebaseHTTP,
+	})
+
+	// Initialize storage (Firebase, Firestore, Postgres or SQLite)
+	store, err := storage.Open(context.Background(), storage.Config{
+		Backend:                 cfg.StorageBackend,
+		FirebaseURL:             cfg.FirebaseDBURL,
+		FirebaseCredentialsFile: cfg.FirebaseCredentialsFile,
+		FirestoreProjectID:      cfg.FirestoreProjectID,
+		FirestoreDatabase:       cfg.FirestoreDatabase,
+		FirestoreEmulatorHost:   cfg.FirestoreEmulatorHost,
+		PostgresURL:             cfg.DatabaseURL,
+		SQLitePath:              cfg.SQLitePath,
+	})
 	if err != nil {
-		log.Fatalf("Failed to initialize Firebase: %v", err)
+		logging.Fatal().Err(err).Msgf("Failed to initialize %s storage", cfg.StorageBackend)
+	}
+	// Trade writes are stored in the background so order execution never
+	// waits on the database; they are journaled until stored, and Close
+	// stores what is still queued
+	if cfg.WriteBehindEnabled {
+		store, err = storage.NewWriteBehind(store, storage.WriteBehindConfig{
+			DrainTimeout: cfg.WriteBehindDrainTimeout,
+			JournalDir:   cfg.WriteBehindJournalDir,
+		})
+		if err != nil {
+			logging.Fatal().Err(err).Msg("Failed to start the write-behind queue")
+		}
 	}
-	defer firebaseClient.Close()
+	// Runs last on shutdown: store writes still held in memory before the
+	// SHUTDOWN_TIMEOUT deadline (set once the signal arrives)
+	var shutdownDeadline time.Time
+	defer func() {
+		ctx, cancel := context.WithDeadline(context.Background(), shutdownDeadline)
+		defer cancel()
+		if err := store.Flush(ctx); err != nil {
+			logging.Warn().Err(err).Msg("Queued writes lost at shutdown")
+		}
+		store.Close()
+	}()
+
+	// Retries, circuit breaker and connection pool for Binance calls, before
+	// any client exists
+	binance.SetRequestPolicy(binance.RequestPolicy{
+		Retries:         cfg.BinanceRetries,
+		RetryBackoff:    cfg.BinanceRetryBackoff,
+		BreakerFailures: cfg.BinanceBreakerFailures,
+		BreakerReset:    cfg.BinanceBreakerReset,
+		HTTP:            cfg.BinanceHTTP,
+	})
 
 	// Initialize Binance client
-	binanceClient := binance.InitClient()
+	binanceClient, err := binance.NewClient(cfg.BinanceAPIKey, cfg.BinanceSecretKey, cfg.BinanceTestnet)
+	if err != nil {
+		logging.Fatal().Err(err).Msg("Failed to initialize Binance client")
+	}
+
+	// Deadlines for Binance calls so a slow exchange cannot hang requests
+	binance.SetTimeouts(cfg.BinanceRequestTimeout, cfg.BinanceOrderTimeout)
+
+	// Orders are submitted a few at a time, in order per symbol
+	binance.SetOrderWorkers(cfg.OrderWorkers)
+
+	// Optional Redis: exchange rules and prices, rate limits, notification
+	// delivery and reconciliation runs shared between server instances
+	var redisClient *redis.Client
+	if cfg.RedisURL != "" {
+		redisClient, err = redis.New(context.Background(), redis.Config{URL: cfg.RedisURL, Prefix: cfg.RedisPrefix})
+		if err != nil {
+			logging.Fatal().Err(err).Msg("Failed to initialize Redis")
+		}
+		defer redisClient.Close()
+		binance.SetSharedCache(redisClient)
+	}
+
+	// Leader election: with Redis, one instance at a time runs the jobs that
+	// must not be duplicated (registered with elector.Run); without it this
+	// instance always leads
+	var sharedLocks cluster.SharedLocks
+	if redisClient != nil {
+		sharedLocks = redisClient
+	}
+	locker := cluster.NewLocker(cfg.InstanceID, sharedLocks)
+	elector := cluster.NewElector(locker, cfg.LeaderLockTTL)
+
+	// Keep signed requests within Binance's timestamp window despite clock drift
+	binance.SetRecvWindow(cfg.BinanceRecvWindow)
+	timeSync := binance.NewTimeSync(binanceClient, cfg.BinanceTimeSyncInterval)
+	timeSync.Start()
+	defer timeSync.Stop()
+
+	// Symbol filters kept in memory for order placement and pre-trade checks
+	symbolRefresh := binance.NewSymbolRefresh(binanceClient, cfg.SymbolRulesRefresh)
+	symbolRefresh.Start()
+	defer symbolRefresh.Stop()
+
+	// Notifications (Telegram and/or email, depending on what is configured)
+	notifier := notifications.NewNotifier(map[string]bool{
+		notifications.EventTradeOpened:       cfg.NotifyTradeOpened,
+		notifications.EventTradeClosed:       cfg.NotifyTradeClosed,
+		notifications.EventStopLossHit:       cfg.NotifyStopLossHit,
+		notifications.EventTakeProfitHit:     cfg.NotifyTakeProfitHit,
+		notifications.EventLiquidationRisk:   cfg.NotifyLiquidationRisk,
+		notifications.EventKillSwitch:        cfg.NotifyKillSwitch,
+		notifications.EventReport:            cfg.NotifyReports,
+		notifications.EventPriceAlert:        cfg.NotifyPriceAlert,
+		notifications.EventUnmanagedPosition: cfg.NotifyUnmanaged,
+		notifications.EventVolatilityHalt:    cfg.NotifyVolatilityHalt,
+		notifications.EventPerformanceAlert:  cfg.NotifyPerformance,
+	})
+	if cfg.TelegramBotToken != "" && cfg.TelegramChatID != "" {
+		notifier.AddChannel(notifications.NewTelegramChannel(cfg.TelegramBotToken, cfg.TelegramChatID))
+	}
+	if cfg.SMTPHost != "" && cfg.EmailFrom != "" && len(cfg.EmailTo) > 0 {
+		// Email is reserved for critical alerts plus the opt-in daily digest
+		emailEvents := []string{notifications.EventKillSwitch, notifications.EventLiquidationRisk, notifications.EventVolatilityHalt, notifications.EventPerformanceAlert}
+		if cfg.EmailDailyDigest {
+			emailEvents = append(emailEvents, notifications.EventReport)
+		}
+		notifier.AddChannel(notifications.NewEmailChannel(notifications.EmailConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.EmailFrom,
+			To:       cfg.EmailTo,
+		}), emailEvents...)
+	}
+	if redisClient != nil {
+		notifier.UseQueue(redisClient)
+	}
+	notifier.UsePreferences(func(userID, eventType string) bool {
+		settings, err := store.GetUserSettings(context.Background(), userID)
+		return err == nil && settings != nil && settings.Mutes(eventType)
+	})
+
+	// Outbound webhooks for trade lifecycle events
+	webhookDispatcher := webhooks.NewDispatcher(store)
+
+	// In-process event bus: exchange, trade and risk events published once,
+	// consumed by notifications, webhooks and /ws clients
+	eventBus := events.NewBus()
+	notifier.Listen(eventBus)
+	webhookDispatcher.Listen(eventBus)
+	api.SubscribeTradeEvents(eventBus, store, binanceClient)
+
+	// Server-wide trading pause (kill switch)
+	tradingPause := policy.NewTradingPause()
+
+	// Start auto-deleverage / margin top-up automation
+	var marginGuard *binance.MarginGuard
+	if cfg.AutoDeleverageEnabled {
+		marginGuard, err = binance.NewMarginGuard(binanceClient, store, eventBus, binance.MarginGuardConfig{
+			Mode:          cfg.AutoDeleverageMode,
+			MinDistance:   cfg.AutoDeleverageMinDistance,
+			TopUpAmount:   cfg.AutoDeleverageTopUpAmount,
+			ReducePercent: cfg.AutoDeleverageReducePercent,
+			CheckInterval: cfg.AutoDeleverageInterval,
+			Cooldown:      cfg.AutoDeleverageCooldown,
+		})
+		if err != nil {
+			logging.Fatal().Err(err).Msg("Invalid AUTO_DELEVERAGE_MODE")
+		}
+		elector.Run("margin-guard", marginGuard.Start, marginGuard.Stop)
+	}
+
+	// Positions closed outside the API: their SL/TP orders are cancelled and
+	// trades closed as soon as the user data stream reports them
+	if cfg.CloseWatcherEnabled {
+		binance.NewCloseWatcher(binanceClient, store, eventBus, cfg.CloseWatcherGrace)
+	}
+
+	// Orphaned SL/TP orders, stale trades and positions opened outside the API
+	// (adopted as trades with ORDER_RECONCILE_ADOPT)
+	orderReconciler := binance.NewOrderReconciler(binanceClient, store, eventBus, notifier, binance.OrderReconcilerConfig{
+		Interval:    cfg.OrderReconcileInterval,
+		GracePeriod: cfg.OrderReconcileGrace,
+		Adopt:       cfg.OrderReconcileAdopt,
+		AdoptUserID: cfg.AdoptUserID,
+		Protect:     cfg.AdoptProtect,
+	})
+	if cfg.OrderReconcileEnabled && redisClient != nil {
+		redisClient.Every("order-reconcile", cfg.OrderReconcileInterval, func(ctx context.Context) { orderReconciler.Reconcile(ctx) })
+	} else if cfg.OrderReconcileEnabled {
+		orderReconciler.Start()
+		defer orderReconciler.Stop()
+	}
+
+	// Move old finished trades out of the hot dataset
+	if cfg.ArchiveEnabled {
+		archiver := storage.NewArchiver(store, storage.ArchiverConfig{
+			Interval: cfg.ArchiveInterval,
+			MaxAge:   cfg.ArchiveAfter,
+		})
+		if redisClient != nil {
+			redisClient.Every("trade-archive", cfg.ArchiveInterval, func(ctx context.Context) { archiver.Archive(ctx) })
+		} else {
+			archiver.Start()
+			defer archiver.Stop()
+		}
+	}
+
+	// Keep Binance's daily account snapshots beyond their 30-day window
+	if cfg.BalanceHistoryEnabled {
+		balanceRecorder := reports.NewBalanceRecorder(binanceClient, store, reports.BalanceRecorderConfig{
+			Interval: cfg.BalanceHistoryInterval,
+		})
+		if redisClient != nil {
+			redisClient.Every("balance-history", cfg.BalanceHistoryInterval, func(ctx context.Context) { balanceRecorder.Capture(ctx) })
+		} else {
+			balanceRecorder.Start()
+			defer balanceRecorder.Stop()
+		}
+	}
+
+	// Daily/weekly summary reports
+	if cfg.ReportsEnabled {
+		reportScheduler := reports.NewScheduler(store, binanceClient, notifier, reports.SchedulerConfig{
+			Daily:  cfg.ReportsDaily,
+			Weekly: cfg.ReportsWeekly,
+			Hour:   cfg.ReportsHour,
+		})
+		elector.Run("reports", reportScheduler.Start, reportScheduler.Stop)
+	}
+
+	// Drawdown, losing streak and daily loss alerts; acknowledging and
+	// snoozing them works on every instance
+	performanceAlerts := alerts.NewPerformanceMonitor(store, notifier, alerts.PerformanceConfig{
+		Interval: cfg.PerformanceAlertInterval,
+		Window:   cfg.PerformanceAlertWindow,
+		Repeat:   cfg.PerformanceAlertRepeat,
+		Defaults: models.PerformanceThresholds{
+			MaxDrawdown:  cfg.AlertMaxDrawdown,
+			LosingStreak: cfg.AlertLosingStreak,
+			DailyLoss:    cfg.AlertDailyLoss,
+		},
+	})
+	if cfg.PerformanceAlertsEnabled {
+		elector.Run("performance-alerts", performanceAlerts.Start, performanceAlerts.Stop)
+	}
+
+	// Symbol allow/block lists (persisted admin changes override env defaults)
+	symbolPolicy := policy.NewSymbolPolicy(cfg.SymbolAllowlist, cfg.SymbolBlocklist)
+	if saved, err := store.GetSymbolPolicy(context.Background()); err != nil {
+		logging.Warn().Err(err).Msg("Could not load symbol policy")
+	} else if saved != nil {
+		symbolPolicy.Update(*saved)
+		logging.Info().Msgf("Loaded symbol policy from Firebase (allow=%d, block=%d)", len(saved.Allowlist), len(saved.Blocklist))
+	}
+
+	// Copy-trading follower accounts (a failing account is skipped, not fatal)
+	followers := []api.Follower{}
+	followerClients := make(map[string]*binance.Client)
+	for _, account := range cfg.Followers {
+		followerClient, err := binance.NewAccountClient(account.APIKey, account.SecretKey)
+		if err != nil {
+			logging.Warn().Err(err).Msgf("Follower account %s unavailable", account.Name)
+			continue
+		}
+		followers = append(followers, api.Follower{Name: account.Name, Client: followerClient, Multiplier: account.Multiplier})
+		followerClients[account.Name] = followerClient
+		logging.Info().Msgf("Copy trading to follower %s (x%.2f)", account.Name, account.Multiplier)
+	}
+
+	// Per-user Binance accounts from encrypted keys (operator account otherwise)
+	var credentialCipher *vault.Cipher
+	if cfg.CredentialsMasterKey != "" {
+		credentialCipher, err = vault.NewCipher(cfg.CredentialsMasterKey)
+		if err != nil {
+			logging.Fatal().Err(err).Msg("Invalid CREDENTIALS_MASTER_KEY")
+		}
+		logging.Info().Msg("Per-user Binance keys enabled")
+	} else if cfg.RequireUserKeys {
+		logging.Warn().Msg("REQUIRE_USER_KEYS is set but CREDENTIALS_MASTER_KEY is empty, all trades use the operator account")
+	}
+	clientPool := binance.NewClientPool(binanceClient, store, credentialCipher, cfg.RequireUserKeys)
+
+	// Maximum concurrent positions per user (queued trades drain as slots free up)
+	positionLimit := policy.NewPositionLimit(cfg.MaxConcurrentPositions, cfg.PositionLimitMode,
+		cfg.PositionQueueTTL, store, clientPool)
+
+	// Named operator accounts selected with "account" on trades and queries
+	// (a failing account is skipped, not fatal)
+	for _, account := range cfg.Accounts {
+		accountClient, err := binance.NewAccountClient(account.APIKey, account.SecretKey)
+		if err != nil {
+			logging.Warn().Err(err).Msgf("Binance account %s unavailable", account.Name)
+			continue
+		}
+		clientPool.AddAccount(account.Name, accountClient)
+		logging.Info().Msgf("Binance account %s available", account.Name)
+	}
+
+	// Reconcile closed trade PnL against Binance REALIZED_PNL income, on
+	// every account trades are placed on
+	if cfg.PnLReconcileEnabled {
+		pnlReconciler := binance.NewPnLReconciler(clientPool, store, binance.PnLReconcilerConfig{
+			Interval:  cfg.PnLReconcileInterval,
+			Lookback:  cfg.PnLReconcileLookback,
+			Tolerance: cfg.PnLReconcileTolerance,
+			Followers: followerClients,
+		})
+		if redisClient != nil {
+			redisClient.Every("pnl-reconcile", cfg.PnLReconcileInterval, func(context.Context) { pnlReconciler.Reconcile() })
+		} else {
+			pnlReconciler.Start()
+			defer pnlReconciler.Stop()
+		}
+	}
+
+	// Shared mark price feed (price alerts, live PnL for /ws clients). Its
+	// prices also serve order sizing and risk checks while fresh.
+	binance.SetPriceMaxAge(cfg.PriceCacheMaxAge)
+	priceFeed := binance.NewPriceFeed()
+	defer priceFeed.Close()
+	for _, symbol := range cfg.PriceCacheSymbols {
+		priceFeed.Subscribe(strings.ToUpper(symbol), func(string, float64) {})
+	}
+
+	// Live updates for /ws clients: every trade write, plus the operator
+	// account's positions and balances
+	pushHub := push.NewHub()
+	push.NewPositionRelay(pushHub, binanceClient, priceFeed, eventBus)
+	store.OnTradeSaved(func(_ context.Context, trade *models.Trade) {
+		pushHub.Publish(&push.Message{Type: push.TypeTrade, UserID: trade.UserID, Data: trade})
+	})
+
+	// Per-trade state history (GET /api/trade/:tradeId/history)
+	store.OnTradeSaved(tradehistory.NewRecorder(store).Record)
+
+	// Per-user totals updated as trades close (GET /api/users/:userId/summary)
+	userSummaries := userstats.NewAggregator(store, cfg.SummaryCacheTTL)
+	store.OnTradeSaved(userSummaries.Record)
+
+	// Worst/best unrealized PnL of open trades, saved when they close
+	if cfg.ExcursionTrackingEnabled {
+		excursions := binance.NewExcursionTracker(priceFeed, store, eventBus, cfg.ExcursionTrackingInterval)
+		elector.Run("excursion-tracker", excursions.Start, excursions.Stop)
+	}
+
+	// Supervised user data stream: order updates for trade monitors, SL/TP
+	// notifications and /ws clients (trade monitors poll while it is down)
+	wsManager := binance.NewWebSocketManager(binanceClient, eventBus, priceFeed, binance.WebSocketConfig{
+		StaleAfter:    cfg.UserDataStreamStaleAfter,
+		CandleHistory: cfg.CandleHistory,
+	})
+	if cfg.UserDataStreamEnabled {
+		elector.Run("user-data-stream", wsManager.Start, wsManager.StopUserDataStream)
+	}
+	defer wsManager.StopAllStreams()
+
+	// Every other operator account has its own user data stream on a private
+	// bus, so its trade monitors follow its orders and no one else's
+	for _, name := range clientPool.Accounts() {
+		if name == binance.PrimaryAccount {
+			continue
+		}
+		accountClient, _ := clientPool.Account(name)
+		accountStream := binance.NewWebSocketManager(accountClient, events.NewBus(), priceFeed, binance.WebSocketConfig{
+			StaleAfter: cfg.UserDataStreamStaleAfter,
+		})
+		if cfg.UserDataStreamEnabled {
+			elector.Run("user-data-stream:"+name, accountStream.Start, accountStream.StopUserDataStream)
+		}
+		defer accountStream.StopAllStreams()
+	}
+
+	// In-memory candles from kline and aggTrade streams (CANDLE_STREAMS=BTCUSDT:1m,...)
+	for _, pair := range cfg.CandleStreams {
+		symbol, interval, ok := strings.Cut(pair, ":")
+		if !ok {
+			logging.Warn().Msgf("Ignoring CANDLE_STREAMS entry %q (expected SYMBOL:interval)", pair)
+			continue
+		}
+		if err := wsManager.StartCandleStream(symbol, interval); err != nil {
+			logging.Warn().Err(err).Msgf("Candle stream %s unavailable", pair)
+		}
+	}
+
+	// Monitors following trades' entry orders, each locked by the instance
+	// running it
+	monitorManager := api.NewMonitorManager(store, webhookDispatcher, locker)
+	defer monitorManager.Stop()
+
+	// Single trade intake shared by the API and background trade sources
+	tradeIntake := api.NewTradeIntake(store, binanceClient, symbolPolicy, positionLimit,
+		tradingPause, eventBus, webhookDispatcher, followers, clientPool, monitorManager)
+	api.SetTriggerDistance(api.TriggerDistance{MinTicks: cfg.TriggerMinTicks, MinPercent: cfg.TriggerMinPercent})
+
+	// Volatility circuit breaker: every instance halts symbols on extreme
+	// moves for its own intake; the leader notifies
+	var volatilityGuard *binance.VolatilityGuard
+	if cfg.VolatilityGuardEnabled {
+		volatilityGuard = binance.NewVolatilityGuard(binanceClient, notifier, binance.VolatilityGuardConfig{
+			Symbols:       cfg.VolatilitySymbols,
+			MaxChange:     cfg.VolatilityMaxChange,
+			Window:        cfg.VolatilityWindow,
+			MaxDivergence: cfg.VolatilityMaxDivergence,
+			Cooldown:      cfg.VolatilityCooldown,
+			Interval:      cfg.VolatilityInterval,
+			Announce:      elector.IsLeader,
+		})
+		volatilityGuard.Start()
+		defer volatilityGuard.Stop()
+		tradeIntake.UseVolatilityGuard(volatilityGuard)
+	}
+
+	// Economic calendar: news blackouts for presets with newsBlackout (every
+	// entry with CALENDAR_BLOCK_ENTRIES) and tighter stops during them
+	var cal *calendar.Calendar
+	if cfg.CalendarEnabled {
+		provider, err := calendar.NewProvider(cfg.CalendarProvider, cfg.CalendarURL)
+		if err != nil {
+			logging.Warn().Err(err).Msg("Economic calendar disabled")
+		} else {
+			cal = calendar.New(provider, calendar.Config{
+				Currencies:     cfg.CalendarCurrencies,
+				BlackoutImpact: cfg.CalendarBlackoutImpact,
+				Before:         cfg.CalendarBlackoutBefore,
+				After:          cfg.CalendarBlackoutAfter,
+				BlockEntries:   cfg.CalendarBlockEntries,
+				Refresh:        cfg.CalendarRefreshInterval,
+			})
+			cal.Start()
+			defer cal.Stop()
+			tradeIntake.UseCalendar(cal)
+
+			if cfg.CalendarTightenStopsPercent > 0 {
+				stopGuard := calendar.NewStopGuard(cal, binanceClient, store, cfg.CalendarTightenStopsPercent, 0)
+				elector.Run("news-stop-guard", stopGuard.Start, stopGuard.Stop)
+			}
+		}
+	}
+
+	// Resume monitoring trades that were still open when the server stopped
+	if recovered, err := tradeIntake.RecoverMonitors(context.Background()); err != nil {
+		logging.Warn().Err(err).Msg("Failed to recover trade monitors")
+	} else if recovered > 0 {
+		logging.Info().Msgf("Resumed monitoring %d active trades", recovered)
+	}
+	// The leader adopts the trades of instances that stopped
+	if locker.Shared() {
+		elector.Run("monitor-recovery", func() { tradeIntake.StartMonitorRecovery(time.Minute) }, tradeIntake.StopMonitorRecovery)
+	}
+
+	// Places trades queued by the position limit (QUEUE mode, even without a
+	// limit, which a config reload may set) and by preset trading hours
+	elector.Run("position-queue", func() {
+		positionLimit.StartQueueDrain(cfg.PositionQueueInterval, tradeIntake.ExecuteQueued)
+	}, positionLimit.StopQueueDrain)
+
+	// Price alerts on the shared mark price feed (optionally auto-submitting a trade)
+	alertEngine := alerts.NewEngine(priceFeed, store, notifier,
+		func(ctx context.Context, req *models.TradeRequest) (*models.Trade, error) {
+			outcome := tradeIntake.Submit(ctx, req)
+			return outcome.Trade, outcome.Err
+		})
+	alertEngine.UseLocker(locker)
+	if err := alertEngine.Start(context.Background()); err != nil {
+		logging.Warn().Err(err).Msg("Could not load price alerts")
+	}
+	defer alertEngine.Stop()
+
+	// Telegram bot commands for mobile control
+	if cfg.TelegramBotEnabled && cfg.TelegramBotToken != "" {
+		if len(cfg.TelegramAllowedChats) == 0 {
+			logging.Warn().Msg("TELEGRAM_BOT_ENABLED but no allowed chat IDs configured, bot not started")
+		} else {
+			telegramBot := bot.NewTelegramBot(cfg.TelegramBotToken, cfg.TelegramAllowedChats)
+			api.RegisterBotCommands(telegramBot, store, binanceClient, tradingPause, notifier, eventBus)
+			elector.Run("telegram-bot", telegramBot.Start, telegramBot.Stop)
+		}
+	}
+
+	// Funding rate arbitrage (endpoints always on, bot optional)
+	fundingArb := binance.NewFundingArbitrage(binanceClient, store, tradingPause, binance.FundingArbConfig{
+		MinRate:   cfg.FundingArbMinRate,
+		MinVolume: cfg.FundingArbMinVolume,
+		ExitRate:  cfg.FundingArbExitRate,
+		Notional:  cfg.FundingArbNotional,
+		Leverage:  cfg.FundingArbLeverage,
+		MaxGroups: cfg.FundingArbMaxGroups,
+		Interval:  cfg.FundingArbInterval,
+		UserID:    cfg.FundingArbUserID,
+	})
+	if cfg.FundingArbEnabled {
+		elector.Run("funding-arbitrage", fundingArb.Start, fundingArb.Stop)
+	}
+
+	// Campaign once every leader duty is registered; stepping down on
+	// shutdown stops them before the monitors and streams
+	elector.Start()
+	defer elector.Stop()
+
+	// Optional HMAC signature verification for incoming trade webhooks
+	signatureVerifier := api.NewSignatureVerifier(cfg.TradeSigningSecret, cfg.TradeSignatureRequired, cfg.TradeSignatureTolerance)
+	if redisClient != nil {
+		signatureVerifier.SetShared(redisClient)
+	}
+	if cfg.TradeSignatureRequired && !signatureVerifier.Enabled() {
+		logging.Warn().Msg("TRADE_SIGNATURE_REQUIRED is set but TRADE_SIGNING_SECRET is empty, signatures are not checked")
+	}
+
+	// Managed API keys alongside the static API_KEY
+	apiKeys := api.NewAPIKeyManager(store, cfg.APIKey)
+
+	// Credentials rotated in the secrets manager: Binance keys and API_KEY
+	// switch over live, other secrets on the next restart
+	if rotator := config.WatchSecrets(cfg.SecretsRefreshInterval); rotator != nil {
+		binanceKeys := &binanceKeyPair{apiKey: cfg.BinanceAPIKey, secretKey: cfg.BinanceSecretKey}
+		rotator.OnChange(func(changed map[string]string) {
+			rotateCredentials(changed, binanceKeys, binanceClient, apiKeys)
+		})
+		rotator.Start()
+		defer rotator.Stop()
+	}
+
+	// Optional JWT bearer tokens (Firebase Auth or a configured issuer)
+	tokenVerifier := jwtauth.NewVerifier(jwtConfig(cfg))
+	if tokenVerifier.Enabled() {
+		logging.Info().Msg("JWT authentication enabled")
+	}
+
+	// Roles of token holders (viewer, trader, admin), stored in Firebase
+	switch cfg.DefaultRole {
+	case models.RoleViewer, models.RoleTrader, models.RoleAdmin:
+	default:
+		logging.Fatal().Msgf("Invalid DEFAULT_ROLE %q (expected viewer, trader or admin)", cfg.DefaultRole)
+	}
+	roleManager := api.NewRoleManager(store, cfg.DefaultRole)
+
+	// Sliding-window rate limits by IP, caller and route
+	rateLimitConfig := api.RateLimitConfig{
+		PerIP:  cfg.RateLimitPerIP,
+		PerKey: cfg.RateLimitPerKey,
+		Routes: cfg.RateLimitRoutes,
+	}
+	if redisClient != nil {
+		rateLimitConfig.Shared = redisClient
+	}
+	rateLimiter := api.NewRateLimiter(rateLimitConfig)
+
+	// Responses to POSTs with an Idempotency-Key, replayed for retries
+	idempotencyConfig := api.IdempotencyConfig{
+		TTL:    cfg.IdempotencyTTL,
+		Store:  store,
+		Locker: locker,
+	}
+	if redisClient != nil {
+		idempotencyConfig.Shared = redisClient
+	}
+	idempotency := api.NewIdempotency(idempotencyConfig)
+
+	// Non-credential settings re-read from CONFIG_FILE on SIGHUP or
+	// POST /api/admin/config/reload, without dropping streams
+	settings := &runtimeSettings{
+		current:       cfg,
+		store:         store,
+		rateLimiter:   rateLimiter,
+		symbolPolicy:  symbolPolicy,
+		positionLimit: positionLimit,
+		marginGuard:   marginGuard,
+	}
+	settings.WatchSignals()
 
 	// Setup router
-	router := api.SetupRouter(firebaseClient, binanceClient)
+	router := api.SetupRouter(api.RouterDeps{
+		Store:       store,
+		Binance:     binanceClient,
+		Intake:      tradeIntake,
+		Symbols:     symbolPolicy,
+		PriceAlerts: alertEngine,
+		Signatures:  signatureVerifier,
+		APIKeys:     apiKeys,
+		Tokens:      tokenVerifier,
+		Roles:       roleManager,
+		RateLimiter: rateLimiter,
+		Clients:     clientPool,
+		Monitors:    monitorManager,
+		FundingArb:  fundingArb,
+		Reconciler:  orderReconciler,
+		Streams:     wsManager,
+		Pause:       tradingPause,
+		Notifier:    notifier,
+		Bus:         eventBus,
+		PushHub:     pushHub,
+		Elector:     elector,
+		Idempotency: idempotency,
+		Reload:      settings.Reload,
+		Legacy:      api.LegacyAPIConfig{DeprecatedAt: cfg.LegacyAPIDeprecatedAt, Sunset: cfg.LegacyAPISunset},
+		Calendar:    cal,
+		Volatility:  volatilityGuard,
+		Summaries:   userSummaries,
+		Performance: performanceAlerts,
+	})
+
+	// Admin web dashboard, fed by the REST API and /ws
+	if cfg.DashboardEnabled {
+		dashboard.Register(router)
+	}
+
+	// Placing a trade may use the order timeout twice (entry, then SL/TP)
+	writeTimeout := 10 * time.Second
+	if t := 2*cfg.BinanceOrderTimeout + 5*time.Second; t > writeTimeout {
+		writeTimeout = t
+	}
 
 	// Server configuration
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
 		Handler:      router,
 		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
+		WriteTimeout: writeTimeout,
 		IdleTimeout:  120 * time.Second,
 	}
+	// Shutdown does not wait for upgraded /ws connections; close them
+	srv.RegisterOnShutdown(pushHub.Close)
 
 	// Start server in goroutine
 	go func() {
-		log.Printf("🚀 Server starting on port %s", cfg.Port)
-		log.Printf("📄 Swagger docs: http://localhost:%s/swagger/index.html", cfg.Port)
+		logging.Info().Msgf("Server starting on port %s", cfg.Port)
+		logging.Info().Msgf("Swagger docs: http://localhost:%s/swagger/index.html", cfg.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
+			logging.Fatal().Err(err).Msg("Server failed to start")
 		}
 	}()
 
@@ -105,13 +759,94 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("🛑 Shutting down server...")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	logging.Info().Msgf("Shutting down server (up to %v)...", cfg.ShutdownTimeout)
+	shutdownDeadline = time.Now().Add(cfg.ShutdownTimeout)
+	ctx, cancel := context.WithDeadline(context.Background(), shutdownDeadline)
 	defer cancel()
 
+	// Refuse new trades (503, readiness fails) and let trades being placed
+	// get their SL/TP orders and records
+	if err := tradeIntake.Drain(ctx); err != nil {
+		logging.Warn().Err(err).Msg("Shutdown did not wait for every trade")
+	}
+
+	// Stop accepting connections and finish in-flight requests
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+		logging.Warn().Err(err).Msg("Server forced to shutdown")
+	}
+	logging.Info().Msg("HTTP server stopped")
+
+	// The deferred calls then stop the trade sources and background jobs,
+	// trade monitors, WebSocket streams (closing the listen key) and the
+	// price feed, and finally flush the store
+}
+
+// binanceKeyPair is the Binance key pair in use, so a rotation of only one
+// half is applied together with the other
+type binanceKeyPair struct {
+	apiKey    string
+	secretKey string
+}
+
+// rotateCredentials applies rotated secrets to the clients using them. The
+// rotated values are handed over directly, never through the environment.
+func rotateCredentials(changed map[string]string, current *binanceKeyPair, bn *binance.Client, keys *api.APIKeyManager) {
+	restart := []string{}
+	next := *current
+	for key, value := range changed {
+		logging.AddSecrets(value)
+
+		switch key {
+		case "API_KEY":
+			if value != "" {
+				keys.SetMasterKey(value)
+				logging.Info().Msg("API_KEY rotated")
+			}
+		case "BINANCE_API_KEY":
+			next.apiKey = value
+		case "BINANCE_SECRET_KEY":
+			next.secretKey = value
+		case "FIREBASE_CREDENTIALS_JSON":
+			// Rewritten by config; reloaded when Firebase refuses a token
+		default:
+			restart = append(restart, key)
+		}
+	}
+
+	if next != *current {
+		if err := bn.SetCredentials(next.apiKey, next.secretKey); err != nil {
+			logging.Error().Err(err).Msg("Rotated Binance keys not applied")
+		} else {
+			*current = next
+			logging.Info().Msg("Binance API keys rotated")
+		}
+	}
+
+	if len(restart) > 0 {
+		sort.Strings(restart)
+		logging.Warn().Strs("settings", restart).Msg("Rotated secrets take effect after a restart")
+	}
+}
+
+// jwtConfig builds the token verifier settings. FIREBASE_AUTH_PROJECT_ID
+// accepts Firebase Auth ID tokens; explicit JWT_* values override it.
+func jwtConfig(cfg *config.Config) jwtauth.Config {
+	jwtCfg := jwtauth.Config{}
+	if cfg.FirebaseAuthProjectID != "" {
+		jwtCfg = jwtauth.FirebaseConfig(cfg.FirebaseAuthProjectID)
+	}
+
+	if cfg.JWTIssuer != "" {
+		jwtCfg.Issuer = cfg.JWTIssuer
+	}
+	if cfg.JWTAudience != "" {
+		jwtCfg.Audience = cfg.JWTAudience
+	}
+	if cfg.JWTJWKSURL != "" {
+		jwtCfg.JWKSURL = cfg.JWTJWKSURL
 	}
+	jwtCfg.Secret = cfg.JWTSecret
+	jwtCfg.UserClaim = cfg.JWTUserClaim
 
-	log.Println("✅ Server exited")
+	return jwtCfg
 }
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"crypto-trading-api/config"
+	"crypto-trading-api/internal/api"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/policy"
+	"crypto-trading-api/internal/storage"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// runtimeSettings applies the non-credential settings that may change
+// without a restart: rate limits, symbol lists, the position limit, the
+// SL/TP distance, margin guard thresholds and the log level. Everything else (credentials, storage,
+// streams, intervals) keeps its startup value.
+type runtimeSettings struct {
+	current       *config.Config
+	store         storage.TradeStore
+	rateLimiter   *api.RateLimiter
+	symbolPolicy  *policy.SymbolPolicy
+	positionLimit *policy.PositionLimit
+	marginGuard   *binance.MarginGuard // nil when auto-deleverage is off
+	mu            sync.Mutex
+}
+
+// reloadable lists the Config fields applied by apply, with the setting
+// names reported for them
+var reloadable = map[string]string{
+	"LogLevel":                    "LOG_LEVEL",
+	"RateLimitPerIP":              "RATE_LIMIT_PER_IP",
+	"RateLimitPerKey":             "RATE_LIMIT_PER_KEY",
+	"RateLimitRoutes":             "RATE_LIMIT_ROUTES",
+	"SymbolAllowlist":             "SYMBOL_ALLOWLIST",
+	"SymbolBlocklist":             "SYMBOL_BLOCKLIST",
+	"MaxConcurrentPositions":      "MAX_CONCURRENT_POSITIONS",
+	"PositionQueueTTL":            "POSITION_QUEUE_TTL",
+	"TriggerMinTicks":             "SLTP_MIN_TICKS",
+	"TriggerMinPercent":           "SLTP_MIN_DISTANCE",
+	"AutoDeleverageMode":          "AUTO_DELEVERAGE_MODE",
+	"AutoDeleverageMinDistance":   "AUTO_DELEVERAGE_MIN_DISTANCE",
+	"AutoDeleverageTopUpAmount":   "AUTO_DELEVERAGE_TOPUP_AMOUNT",
+	"AutoDeleverageReducePercent": "AUTO_DELEVERAGE_REDUCE_PERCENT",
+	"AutoDeleverageCooldown":      "AUTO_DELEVERAGE_COOLDOWN",
+}
+
+// rotatedCredentials are Config fields kept current by secret rotation
+// (rotateCredentials), not by a reload
+var rotatedCredentials = map[string]bool{
+	"APIKey":           true,
+	"BinanceAPIKey":    true,
+	"BinanceSecretKey": true,
+}
+
+// Reload re-reads the configuration and applies what changed
+func (s *runtimeSettings) Reload(ctx context.Context) (*models.ConfigReloadResult, error) {
+	next, err := config.Reload()
+	if err != nil {
+		return nil, err
+	}
+	if next.RateLimitPerIP < 0 || next.RateLimitPerKey < 0 {
+		return nil, fmt.Errorf("rate limits cannot be negative")
+	}
+	if next.MaxConcurrentPositions < 0 {
+		return nil, fmt.Errorf("MAX_CONCURRENT_POSITIONS cannot be negative")
+	}
+	if next.TriggerMinTicks < 0 || next.TriggerMinPercent < 0 {
+		return nil, fmt.Errorf("SLTP_MIN_TICKS and SLTP_MIN_DISTANCE cannot be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Applied first: an invalid level rejects the reload before anything changed
+	if err := logging.SetLevel(next.LogLevel); err != nil {
+		return nil, err
+	}
+
+	// Lists saved with PUT /api/admin/symbols override the configured ones
+	saved, err := s.store.GetSymbolPolicy(ctx)
+	if err != nil {
+		logging.Warn().Err(err).Msg("Could not load symbol policy, keeping the current lists")
+	}
+	keepSymbols := err != nil || saved != nil
+
+	result := &models.ConfigReloadResult{
+		Applied:         []models.SettingChange{},
+		RestartRequired: []string{},
+		ReloadedAt:      time.Now().Unix(),
+	}
+	for _, field := range changedFields(s.current, next) {
+		if rotatedCredentials[field] {
+			continue
+		}
+		setting, ok := reloadable[field]
+		if !ok {
+			result.RestartRequired = append(result.RestartRequired, field)
+			continue
+		}
+		if keepSymbols && (field == "SymbolAllowlist" || field == "SymbolBlocklist") {
+			continue
+		}
+		result.Applied = append(result.Applied, models.SettingChange{
+			Setting: setting,
+			Old:     settingValue(s.current, field),
+			New:     settingValue(next, field),
+		})
+	}
+
+	s.apply(next, keepSymbols)
+	s.current = next
+
+	if len(result.RestartRequired) > 0 {
+		logging.Warn().Strs("settings", result.RestartRequired).Msg("Changed settings take effect after a restart")
+	}
+	logging.Info().Int("applied", len(result.Applied)).Msg("Configuration reloaded")
+	return result, nil
+}
+
+// apply hands the reloadable settings (other than the log level) to the
+// components using them
+func (s *runtimeSettings) apply(cfg *config.Config, keepSymbols bool) {
+	s.rateLimiter.SetLimits(cfg.RateLimitPerIP, cfg.RateLimitPerKey, cfg.RateLimitRoutes)
+
+	if !keepSymbols {
+		s.symbolPolicy.Update(models.SymbolPolicyConfig{Allowlist: cfg.SymbolAllowlist, Blocklist: cfg.SymbolBlocklist})
+	}
+
+	s.positionLimit.SetMax(cfg.MaxConcurrentPositions, cfg.PositionQueueTTL)
+	api.SetTriggerDistance(api.TriggerDistance{MinTicks: cfg.TriggerMinTicks, MinPercent: cfg.TriggerMinPercent})
+
+	if s.marginGuard != nil {
+		err := s.marginGuard.SetThresholds(binance.MarginGuardConfig{
+			Mode:          cfg.AutoDeleverageMode,
+			MinDistance:   cfg.AutoDeleverageMinDistance,
+			TopUpAmount:   cfg.AutoDeleverageTopUpAmount,
+			ReducePercent: cfg.AutoDeleverageReducePercent,
+			Cooldown:      cfg.AutoDeleverageCooldown,
+		})
+		if err != nil {
+			logging.Warn().Err(err).Msg("Margin guard settings not applied")
+		}
+	}
+}
+
+// WatchSignals reloads the configuration on every SIGHUP
+func (s *runtimeSettings) WatchSignals() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		for range hup {
+			logging.Info().Msg("SIGHUP received, reloading configuration")
+			if _, err := s.Reload(context.Background()); err != nil {
+				logging.Error().Err(err).Msg("Configuration reload failed, keeping the running configuration")
+			}
+		}
+	}()
+}
+
+// changedFields lists the Config fields that differ, sorted by name
+func changedFields(old, next *config.Config) []string {
+	oldValue, nextValue := reflect.ValueOf(old).Elem(), reflect.ValueOf(next).Elem()
+	fields := []string{}
+	for i := 0; i < oldValue.NumField(); i++ {
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), nextValue.Field(i).Interface()) {
+			fields = append(fields, oldValue.Type().Field(i).Name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// settingValue formats a reloadable field the way it is configured
+func settingValue(cfg *config.Config, field string) string {
+	switch value := reflect.ValueOf(cfg).Elem().FieldByName(field).Interface().(type) {
+	case []string:
+		return strings.Join(value, ",")
+	case map[string]int:
+		pairs := make([]string, 0, len(value))
+		for route, limit := range value {
+			pairs = append(pairs, fmt.Sprintf("%s=%d", route, limit))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
-	"log"
-	"os"
+	"crypto-trading-api/internal/httpclient"
+	"crypto-trading-api/internal/logging"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,24 +17,292 @@ type Config struct {
 	Port        string
 	GinMode     string
 	SwaggerHost string
+	LogLevel    string // debug, info, warn or error
+	LogFormat   string // json or console
+	ConfigFile  string // YAML or TOML file under the environment (re-read on SIGHUP)
+
+	// Budget for draining trades, requests and queued writes on SIGTERM
+	ShutdownTimeout time.Duration
+
+	// Retirement of the unversioned /api paths (/api/v1 is current)
+	LegacyAPIDeprecatedAt time.Time
+	LegacyAPISunset       time.Time
+
+	// Admin web dashboard at /dashboard
+	DashboardEnabled bool
+
+	// How long responses to POSTs with an Idempotency-Key are replayed (0 disables)
+	IdempotencyTTL time.Duration
 
 	// Security
-	APIKey string
+	APIKey                  string
+	TradeSigningSecret      string
+	TradeSignatureRequired  bool
+	TradeSignatureTolerance time.Duration
+
+	// Secrets manager (Vault, AWS or GCP) holding the credentials
+	SecretsProvider        string
+	SecretsRefreshInterval time.Duration
+	VaultToken             string // Redacted from logs
+
+	// JWT authentication (Firebase Auth or another issuer)
+	FirebaseAuthProjectID string
+	JWTIssuer             string
+	JWTAudience           string
+	JWTJWKSURL            string
+	JWTSecret             string
+	JWTUserClaim          string
+	DefaultRole           string // Role of token holders without a role assignment
+
+	// Rate limiting (requests per minute, sliding window)
+	RateLimitPerIP  int
+	RateLimitPerKey int
+	RateLimitRoutes map[string]int
 
 	// Binance
-	BinanceAPIKey    string
-	BinanceSecretKey string
+	BinanceAPIKey            string
+	BinanceSecretKey         string
+	BinanceTestnet           bool
+	BinanceRecvWindow        time.Duration
+	BinanceTimeSyncInterval  time.Duration
+	BinanceRequestTimeout    time.Duration
+	BinanceOrderTimeout      time.Duration
+	BinanceRetries           int
+	BinanceRetryBackoff      time.Duration
+	BinanceBreakerFailures   int
+	BinanceBreakerReset      time.Duration
+	SymbolRulesRefresh       time.Duration
+	UserDataStreamEnabled    bool
+	UserDataStreamStaleAfter time.Duration
+	CandleStreams            []string // SYMBOL:interval pairs kept as in-memory candles
+	CandleHistory            int
+	PriceCacheMaxAge         time.Duration
+	PriceCacheSymbols        []string // Mark prices streamed from boot so GetPrice rarely needs REST
+	OrderWorkers             int      // Order submissions sent to Binance at once
+	BinanceHTTP              httpclient.Config
+
+	// Named operator accounts (sub-accounts) besides "main"
+	Accounts []BinanceAccount
 
-	// Firebase
+	// Per-user Binance keys (encrypted at rest)
+	CredentialsMasterKey string
+	RequireUserKeys      bool
+
+	// Storage
+	StorageBackend          string // firebase, firestore, postgres or sqlite
+	DatabaseURL             string // Postgres connection URL
+	SQLitePath              string
 	FirebaseDBURL           string
 	FirebaseCredentialsFile string
+	FirestoreProjectID      string
+	FirestoreDatabase       string
+	FirestoreEmulatorHost   string
+	FirebaseTimeout         time.Duration
+	FirebaseRetries         int
+	FirebaseRetryBackoff    time.Duration
+	FirebaseBreakerFailures int
+	FirebaseBreakerReset    time.Duration
+	FirebaseHTTP            httpclient.Config
+	WriteBehindEnabled      bool
+	WriteBehindDrainTimeout time.Duration
+	WriteBehindJournalDir   string
+
+	// Redis (optional, shared between server instances)
+	RedisURL    string
+	RedisPrefix string
+
+	// Leader election between instances (through Redis)
+	InstanceID    string
+	LeaderLockTTL time.Duration
+
+	// Auto-deleverage / margin top-up
+	AutoDeleverageEnabled       bool
+	AutoDeleverageMode          string
+	AutoDeleverageMinDistance   float64
+	AutoDeleverageTopUpAmount   float64
+	AutoDeleverageReducePercent float64
+	AutoDeleverageInterval      time.Duration
+	AutoDeleverageCooldown      time.Duration
+
+	// Symbol policy
+	SymbolAllowlist []string
+	SymbolBlocklist []string
+
+	// Position limits
+	MaxConcurrentPositions int
+	PositionLimitMode      string
+	PositionQueueTTL       time.Duration
+	PositionQueueInterval  time.Duration
+
+	// Minimum SL/TP distance from the mark price
+	TriggerMinTicks   int
+	TriggerMinPercent float64
+
+	// Realized PnL reconciliation
+	PnLReconcileEnabled   bool
+	PnLReconcileInterval  time.Duration
+	PnLReconcileLookback  time.Duration
+	PnLReconcileTolerance float64
+
+	// Max adverse/favorable excursion tracking
+	ExcursionTrackingEnabled  bool
+	ExcursionTrackingInterval time.Duration
+
+	// Volatility circuit breaker
+	VolatilityGuardEnabled  bool
+	VolatilitySymbols       []string
+	VolatilityMaxChange     float64
+	VolatilityWindow        time.Duration
+	VolatilityMaxDivergence float64
+	VolatilityCooldown      time.Duration
+	VolatilityInterval      time.Duration
+
+	// Economic calendar and news blackouts
+	CalendarEnabled             bool
+	CalendarProvider            string
+	CalendarURL                 string
+	CalendarCurrencies          []string
+	CalendarBlackoutImpact      string
+	CalendarBlackoutBefore      time.Duration
+	CalendarBlackoutAfter       time.Duration
+	CalendarBlockEntries        bool
+	CalendarTightenStopsPercent float64
+	CalendarRefreshInterval     time.Duration
+
+	// External close detection
+	CloseWatcherEnabled bool
+	CloseWatcherGrace   time.Duration
+
+	// Orphaned order reconciliation
+	OrderReconcileEnabled  bool
+	OrderReconcileInterval time.Duration
+	OrderReconcileGrace    time.Duration
+	OrderReconcileAdopt    bool
+	AdoptUserID            string
+	AdoptProtect           bool
+
+	// Trade archival
+	ArchiveEnabled  bool
+	ArchiveInterval time.Duration
+	ArchiveAfter    time.Duration
+
+	// OpenTelemetry tracing
+	TracingEnabled     bool
+	TracingEndpoint    string
+	TracingInsecure    bool
+	TracingServiceName string
+	TracingSampleRatio float64
+
+	// Daily balance snapshot capture
+	BalanceHistoryEnabled  bool
+	BalanceHistoryInterval time.Duration
+
+	// Scheduled summary reports
+	ReportsEnabled bool
+	ReportsDaily   bool
+	ReportsWeekly  bool
+	ReportsHour    int
+
+	// Per-user summaries (GET /api/users/:userId/summary)
+	SummaryCacheTTL time.Duration
+
+	// Drawdown, losing streak and daily loss alerts
+	PerformanceAlertsEnabled bool
+	PerformanceAlertInterval time.Duration
+	PerformanceAlertWindow   time.Duration
+	PerformanceAlertRepeat   time.Duration
+	AlertMaxDrawdown         float64
+	AlertLosingStreak        int
+	AlertDailyLoss           float64
+
+	// Notifications
+	TelegramBotToken      string
+	TelegramChatID        string
+	TelegramBotEnabled    bool
+	TelegramAllowedChats  []int64
+	NotifyTradeOpened     bool
+	NotifyTradeClosed     bool
+	NotifyStopLossHit     bool
+	NotifyTakeProfitHit   bool
+	NotifyLiquidationRisk bool
+	NotifyKillSwitch      bool
+	NotifyReports         bool
+	NotifyPriceAlert      bool
+	NotifyUnmanaged       bool
+	NotifyVolatilityHalt  bool
+	NotifyPerformance     bool
+
+	// Email notifications
+	SMTPHost         string
+	SMTPPort         int
+	SMTPUsername     string
+	SMTPPassword     string
+	EmailFrom        string
+	EmailTo          []string
+	EmailDailyDigest bool
+
+	// Copy trading
+	Followers []FollowerAccount
+
+	// Funding rate arbitrage bot
+	FundingArbEnabled   bool
+	FundingArbMinRate   float64
+	FundingArbMinVolume float64
+	FundingArbExitRate  float64
+	FundingArbNotional  float64
+	FundingArbLeverage  int
+	FundingArbMaxGroups int
+	FundingArbInterval  time.Duration
+	FundingArbUserID    string
 }
 
-// Load loads configuration from environment variables
+// FollowerAccount is a Binance account that copies every executed trade
+type FollowerAccount struct {
+	Name       string
+	APIKey     string
+	SecretKey  string
+	Multiplier float64
+}
+
+// BinanceAccount is a named operator account trades and queries can select
+// with their account field
+type BinanceAccount struct {
+	Name      string
+	APIKey    string
+	SecretKey string
+}
+
+// Load loads configuration from environment variables, layered over the
+// optional CONFIG_FILE
 func Load() *Config {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
+		logging.Info().Msg("No .env file found, using environment variables")
+	}
+
+	config, err := read()
+	if err != nil {
+		logging.Fatal().Err(err).Msg("Invalid configuration")
+	}
+	return config
+}
+
+// Reload re-reads CONFIG_FILE and builds a fresh configuration. Unlike Load
+// it never exits: an invalid file returns an error and the caller keeps the
+// configuration it has. Environment variables are fixed for the life of the
+// process, so only settings taken from the file can change.
+func Reload() (*Config, error) {
+	return read()
+}
+
+// read builds and validates the configuration from the environment and
+// CONFIG_FILE
+func read() (*Config, error) {
+	if err := loadFile(); err != nil {
+		return nil, err
+	}
+	if err := loadSecrets(); err != nil {
+		return nil, fmt.Errorf("failed to load secrets: %v", err)
 	}
 
 	config := &Config{
@@ -38,39 +310,456 @@ func Load() *Config {
 		Port:        getEnv("PORT", "8080"),
 		GinMode:     getEnv("GIN_MODE", "release"),
 		SwaggerHost: getEnv("SWAGGER_HOST", "localhost:8080"),
+		LogLevel:    getEnv("LOG_LEVEL", "info"),
+		LogFormat:   getEnv("LOG_FORMAT", "json"),
+		ConfigFile:  getEnv("CONFIG_FILE", ""),
+
+		ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+
+		// API versioning
+		LegacyAPIDeprecatedAt: getEnvDate("LEGACY_API_DEPRECATED_AT"),
+		LegacyAPISunset:       getEnvDate("LEGACY_API_SUNSET"),
+
+		// Dashboard
+		DashboardEnabled: getEnvBool("DASHBOARD_ENABLED", true),
+
+		// Idempotency keys
+		IdempotencyTTL: getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
 
 		// Security
-		APIKey: getEnv("API_KEY", ""),
+		APIKey:                  getEnv("API_KEY", ""),
+		TradeSigningSecret:      getEnv("TRADE_SIGNING_SECRET", ""),
+		TradeSignatureRequired:  getEnvBool("TRADE_SIGNATURE_REQUIRED", false),
+		TradeSignatureTolerance: getEnvDuration("TRADE_SIGNATURE_TOLERANCE", 5*time.Minute),
+
+		// Secrets manager
+		SecretsProvider:        getEnv("SECRETS_PROVIDER", ""),
+		SecretsRefreshInterval: getEnvDuration("SECRETS_REFRESH_INTERVAL", 5*time.Minute),
+		VaultToken:             getEnv("VAULT_TOKEN", ""),
+
+		// JWT authentication
+		FirebaseAuthProjectID: getEnv("FIREBASE_AUTH_PROJECT_ID", ""),
+		JWTIssuer:             getEnv("JWT_ISSUER", ""),
+		JWTAudience:           getEnv("JWT_AUDIENCE", ""),
+		JWTJWKSURL:            getEnv("JWT_JWKS_URL", ""),
+		JWTSecret:             getEnv("JWT_SECRET", ""),
+		JWTUserClaim:          getEnv("JWT_USER_CLAIM", "sub"),
+		DefaultRole:           strings.ToLower(getEnv("DEFAULT_ROLE", "trader")),
+
+		// Rate limiting
+		RateLimitPerIP:  getEnvInt("RATE_LIMIT_PER_IP", 100),
+		RateLimitPerKey: getEnvInt("RATE_LIMIT_PER_KEY", 0),
+		RateLimitRoutes: getEnvIntMap("RATE_LIMIT_ROUTES"),
 
 		// Binance
-		BinanceAPIKey:    getEnv("BINANCE_API_KEY", ""),
-		BinanceSecretKey: getEnv("BINANCE_SECRET_KEY", ""),
+		BinanceAPIKey:            getEnv("BINANCE_API_KEY", ""),
+		BinanceSecretKey:         getEnv("BINANCE_SECRET_KEY", ""),
+		BinanceTestnet:           getEnvBool("BINANCE_TESTNET", false),
+		BinanceRecvWindow:        getEnvDuration("BINANCE_RECV_WINDOW", 5*time.Second),
+		BinanceTimeSyncInterval:  getEnvDuration("BINANCE_TIME_SYNC_INTERVAL", 10*time.Minute),
+		BinanceRequestTimeout:    getEnvDuration("BINANCE_REQUEST_TIMEOUT", 10*time.Second),
+		BinanceOrderTimeout:      getEnvDuration("BINANCE_ORDER_TIMEOUT", 30*time.Second),
+		BinanceRetries:           getEnvInt("BINANCE_RETRIES", 2),
+		BinanceRetryBackoff:      getEnvDuration("BINANCE_RETRY_BACKOFF", 250*time.Millisecond),
+		BinanceBreakerFailures:   getEnvInt("BINANCE_BREAKER_FAILURES", 5),
+		BinanceBreakerReset:      getEnvDuration("BINANCE_BREAKER_RESET", 30*time.Second),
+		SymbolRulesRefresh:       getEnvDuration("SYMBOL_RULES_REFRESH_INTERVAL", 5*time.Minute),
+		UserDataStreamEnabled:    getEnvBool("USER_DATA_STREAM_ENABLED", true),
+		UserDataStreamStaleAfter: getEnvDuration("USER_DATA_STREAM_STALE_AFTER", 10*time.Minute),
+		CandleStreams:            getEnvList("CANDLE_STREAMS"),
+		CandleHistory:            getEnvInt("CANDLE_HISTORY", 500),
+		PriceCacheMaxAge:         getEnvDuration("PRICE_CACHE_MAX_AGE", 5*time.Second),
+		PriceCacheSymbols:        getEnvList("PRICE_CACHE_SYMBOLS"),
+		OrderWorkers:             getEnvInt("ORDER_WORKERS", 8),
+		BinanceHTTP:              getHTTPClient("BINANCE_HTTP_"),
+
+		// Named operator accounts
+		Accounts: getBinanceAccounts(),
 
-		// Firebase
+		// Per-user Binance keys
+		CredentialsMasterKey: getEnv("CREDENTIALS_MASTER_KEY", ""),
+		RequireUserKeys:      getEnvBool("REQUIRE_USER_KEYS", false),
+
+		// Storage
+		StorageBackend:          strings.ToLower(getEnv("STORAGE_BACKEND", "firebase")),
+		DatabaseURL:             getEnv("DATABASE_URL", ""),
+		SQLitePath:              getEnv("SQLITE_PATH", "./data/trading.db"),
 		FirebaseDBURL:           getEnv("FIREBASE_DATABASE_URL", ""),
-		FirebaseCredentialsFile: getEnv("FIREBASE_CREDENTIALS_FILE", ""),
+		FirebaseCredentialsFile: getEnv("FIREBASE_CREDENTIALS_FILE", firebaseCredentialsPath),
+		FirestoreProjectID:      getEnv("FIRESTORE_PROJECT_ID", ""),
+		FirestoreDatabase:       getEnv("FIRESTORE_DATABASE", "(default)"),
+		FirestoreEmulatorHost:   getEnv("FIRESTORE_EMULATOR_HOST", ""),
+		FirebaseTimeout:         getEnvDuration("FIREBASE_TIMEOUT", 10*time.Second),
+		FirebaseRetries:         getEnvInt("FIREBASE_RETRIES", 2),
+		FirebaseRetryBackoff:    getEnvDuration("FIREBASE_RETRY_BACKOFF", 200*time.Millisecond),
+		FirebaseBreakerFailures: getEnvInt("FIREBASE_BREAKER_FAILURES", 5),
+		FirebaseBreakerReset:    getEnvDuration("FIREBASE_BREAKER_RESET", 30*time.Second),
+		FirebaseHTTP:            getHTTPClient("FIREBASE_HTTP_"),
+		WriteBehindEnabled:      getEnvBool("WRITE_BEHIND_ENABLED", true),
+		WriteBehindDrainTimeout: getEnvDuration("WRITE_BEHIND_DRAIN_TIMEOUT", 30*time.Second),
+		WriteBehindJournalDir:   getEnv("WRITE_BEHIND_JOURNAL_DIR", "./data/write-behind"),
+
+		// Redis
+		RedisURL:    getEnv("REDIS_URL", ""),
+		RedisPrefix: getEnv("REDIS_PREFIX", "tradingapi:"),
+
+		// Leader election
+		InstanceID:    getEnv("INSTANCE_ID", ""),
+		LeaderLockTTL: getEnvDuration("LEADER_LOCK_TTL", 15*time.Second),
+
+		// Auto-deleverage / margin top-up
+		AutoDeleverageEnabled:       getEnvBool("AUTO_DELEVERAGE_ENABLED", false),
+		AutoDeleverageMode:          strings.ToUpper(getEnv("AUTO_DELEVERAGE_MODE", "ADD_MARGIN")),
+		AutoDeleverageMinDistance:   getEnvFloat("AUTO_DELEVERAGE_MIN_DISTANCE", 8),
+		AutoDeleverageTopUpAmount:   getEnvFloat("AUTO_DELEVERAGE_TOPUP_AMOUNT", 0),
+		AutoDeleverageReducePercent: getEnvFloat("AUTO_DELEVERAGE_REDUCE_PERCENT", 25),
+		AutoDeleverageInterval:      getEnvDuration("AUTO_DELEVERAGE_INTERVAL", 30*time.Second),
+		AutoDeleverageCooldown:      getEnvDuration("AUTO_DELEVERAGE_COOLDOWN", 5*time.Minute),
+
+		// Symbol policy
+		SymbolAllowlist: getEnvList("SYMBOL_ALLOWLIST"),
+		SymbolBlocklist: getEnvList("SYMBOL_BLOCKLIST"),
+
+		// Position limits
+		MaxConcurrentPositions: getEnvInt("MAX_CONCURRENT_POSITIONS", 0),
+		PositionLimitMode:      getEnv("POSITION_LIMIT_MODE", "REJECT"),
+		PositionQueueTTL:       getEnvDuration("POSITION_QUEUE_TTL", time.Hour),
+		PositionQueueInterval:  getEnvDuration("POSITION_QUEUE_INTERVAL", 15*time.Second),
+
+		// Minimum SL/TP distance from the mark price
+		TriggerMinTicks:   getEnvInt("SLTP_MIN_TICKS", 2),
+		TriggerMinPercent: getEnvFloat("SLTP_MIN_DISTANCE", 0),
+
+		// Realized PnL reconciliation
+		PnLReconcileEnabled:   getEnvBool("PNL_RECONCILE_ENABLED", false),
+		PnLReconcileInterval:  getEnvDuration("PNL_RECONCILE_INTERVAL", time.Hour),
+		PnLReconcileLookback:  getEnvDuration("PNL_RECONCILE_LOOKBACK", 7*24*time.Hour),
+		PnLReconcileTolerance: getEnvFloat("PNL_RECONCILE_TOLERANCE", 0.01),
+
+		// Max adverse/favorable excursion tracking
+		ExcursionTrackingEnabled:  getEnvBool("EXCURSION_TRACKING_ENABLED", true),
+		ExcursionTrackingInterval: getEnvDuration("EXCURSION_TRACKING_INTERVAL", time.Minute),
+
+		// Volatility circuit breaker
+		VolatilityGuardEnabled:  getEnvBool("VOLATILITY_GUARD_ENABLED", false),
+		VolatilitySymbols:       getEnvList("VOLATILITY_SYMBOLS"),
+		VolatilityMaxChange:     getEnvFloat("VOLATILITY_MAX_CHANGE", 5),
+		VolatilityWindow:        getEnvDuration("VOLATILITY_WINDOW", time.Minute),
+		VolatilityMaxDivergence: getEnvFloat("VOLATILITY_MAX_DIVERGENCE", 1),
+		VolatilityCooldown:      getEnvDuration("VOLATILITY_COOLDOWN", 5*time.Minute),
+		VolatilityInterval:      getEnvDuration("VOLATILITY_INTERVAL", 5*time.Second),
+
+		// Economic calendar and news blackouts
+		CalendarEnabled:             getEnvBool("CALENDAR_ENABLED", false),
+		CalendarProvider:            getEnv("CALENDAR_PROVIDER", "forexfactory"),
+		CalendarURL:                 getEnv("CALENDAR_URL", ""),
+		CalendarCurrencies:          getEnvList("CALENDAR_CURRENCIES"),
+		CalendarBlackoutImpact:      getEnv("CALENDAR_BLACKOUT_IMPACT", "HIGH"),
+		CalendarBlackoutBefore:      getEnvDuration("CALENDAR_BLACKOUT_BEFORE", 15*time.Minute),
+		CalendarBlackoutAfter:       getEnvDuration("CALENDAR_BLACKOUT_AFTER", 15*time.Minute),
+		CalendarBlockEntries:        getEnvBool("CALENDAR_BLOCK_ENTRIES", false),
+		CalendarTightenStopsPercent: getEnvFloat("CALENDAR_TIGHTEN_STOPS_PERCENT", 0),
+		CalendarRefreshInterval:     getEnvDuration("CALENDAR_REFRESH_INTERVAL", time.Hour),
+
+		// External close detection
+		CloseWatcherEnabled: getEnvBool("CLOSE_WATCHER_ENABLED", true),
+		CloseWatcherGrace:   getEnvDuration("CLOSE_WATCHER_GRACE", 3*time.Second),
+
+		// Orphaned order reconciliation
+		OrderReconcileEnabled:  getEnvBool("ORDER_RECONCILE_ENABLED", false),
+		OrderReconcileInterval: getEnvDuration("ORDER_RECONCILE_INTERVAL", 5*time.Minute),
+		OrderReconcileGrace:    getEnvDuration("ORDER_RECONCILE_GRACE", 2*time.Minute),
+		OrderReconcileAdopt:    getEnvBool("ORDER_RECONCILE_ADOPT", false),
+		AdoptUserID:            getEnv("ORDER_RECONCILE_ADOPT_USER_ID", "operator"),
+		AdoptProtect:           getEnvBool("ORDER_RECONCILE_ADOPT_PROTECT", true),
+
+		// Trade archival
+		ArchiveEnabled:  getEnvBool("ARCHIVE_ENABLED", false),
+		ArchiveInterval: getEnvDuration("ARCHIVE_INTERVAL", 24*time.Hour),
+		ArchiveAfter:    getEnvDuration("ARCHIVE_AFTER", 90*24*time.Hour),
+
+		// OpenTelemetry tracing
+		TracingEnabled:     getEnvBool("TRACING_ENABLED", false),
+		TracingEndpoint:    getEnv("TRACING_ENDPOINT", "localhost:4318"),
+		TracingInsecure:    getEnvBool("TRACING_INSECURE", true),
+		TracingServiceName: getEnv("TRACING_SERVICE_NAME", "crypto-trading-api"),
+		TracingSampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+
+		// Daily balance snapshot capture
+		BalanceHistoryEnabled:  getEnvBool("BALANCE_HISTORY_ENABLED", false),
+		BalanceHistoryInterval: getEnvDuration("BALANCE_HISTORY_INTERVAL", 6*time.Hour),
+
+		// Scheduled summary reports
+		ReportsEnabled: getEnvBool("REPORTS_ENABLED", false),
+		ReportsDaily:   getEnvBool("REPORTS_DAILY", true),
+		ReportsWeekly:  getEnvBool("REPORTS_WEEKLY", true),
+		ReportsHour:    getEnvInt("REPORTS_HOUR", 0),
+
+		// Per-user summaries
+		SummaryCacheTTL: getEnvDuration("SUMMARY_CACHE_TTL", 30*time.Second),
+
+		// Drawdown, losing streak and daily loss alerts
+		PerformanceAlertsEnabled: getEnvBool("PERFORMANCE_ALERTS_ENABLED", false),
+		PerformanceAlertInterval: getEnvDuration("PERFORMANCE_ALERT_INTERVAL", 5*time.Minute),
+		PerformanceAlertWindow:   getEnvDuration("PERFORMANCE_ALERT_WINDOW", 30*24*time.Hour),
+		PerformanceAlertRepeat:   getEnvDuration("PERFORMANCE_ALERT_REPEAT", 4*time.Hour),
+		AlertMaxDrawdown:         getEnvFloat("ALERT_MAX_DRAWDOWN", 0),
+		AlertLosingStreak:        getEnvInt("ALERT_LOSING_STREAK", 0),
+		AlertDailyLoss:           getEnvFloat("ALERT_DAILY_LOSS", 0),
+
+		// Notifications
+		TelegramBotToken:      getEnv("TELEGRAM_BOT_TOKEN", ""),
+		TelegramChatID:        getEnv("TELEGRAM_CHAT_ID", ""),
+		TelegramBotEnabled:    getEnvBool("TELEGRAM_BOT_ENABLED", false),
+		TelegramAllowedChats:  getEnvInt64List("TELEGRAM_ALLOWED_CHAT_IDS"),
+		NotifyTradeOpened:     getEnvBool("NOTIFY_TRADE_OPENED", true),
+		NotifyTradeClosed:     getEnvBool("NOTIFY_TRADE_CLOSED", true),
+		NotifyStopLossHit:     getEnvBool("NOTIFY_SL_HIT", true),
+		NotifyTakeProfitHit:   getEnvBool("NOTIFY_TP_HIT", true),
+		NotifyLiquidationRisk: getEnvBool("NOTIFY_LIQUIDATION_RISK", true),
+		NotifyKillSwitch:      getEnvBool("NOTIFY_KILL_SWITCH", true),
+		NotifyReports:         getEnvBool("NOTIFY_REPORTS", true),
+		NotifyPriceAlert:      getEnvBool("NOTIFY_PRICE_ALERT", true),
+		NotifyUnmanaged:       getEnvBool("NOTIFY_UNMANAGED_POSITION", true),
+		NotifyVolatilityHalt:  getEnvBool("NOTIFY_VOLATILITY_HALT", true),
+		NotifyPerformance:     getEnvBool("NOTIFY_PERFORMANCE_ALERT", true),
+
+		// Email notifications
+		SMTPHost:         getEnv("SMTP_HOST", ""),
+		SMTPPort:         getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+		EmailFrom:        getEnv("EMAIL_FROM", ""),
+		EmailTo:          getEnvList("EMAIL_TO"),
+		EmailDailyDigest: getEnvBool("EMAIL_DAILY_DIGEST", false),
+
+		// Copy trading
+		Followers: getFollowerAccounts(),
+
+		// Funding rate arbitrage bot
+		FundingArbEnabled:   getEnvBool("FUNDING_ARB_ENABLED", false),
+		FundingArbMinRate:   getEnvFloat("FUNDING_ARB_MIN_RATE", 0.0005),
+		FundingArbMinVolume: getEnvFloat("FUNDING_ARB_MIN_VOLUME", 0),
+		FundingArbExitRate:  getEnvFloat("FUNDING_ARB_EXIT_RATE", 0.0001),
+		FundingArbNotional:  getEnvFloat("FUNDING_ARB_NOTIONAL", 100),
+		FundingArbLeverage:  getEnvInt("FUNDING_ARB_LEVERAGE", 1),
+		FundingArbMaxGroups: getEnvInt("FUNDING_ARB_MAX_GROUPS", 1),
+		FundingArbInterval:  getEnvDuration("FUNDING_ARB_INTERVAL", 15*time.Minute),
+		FundingArbUserID:    getEnv("FUNDING_ARB_USER_ID", "funding-bot"),
+	}
+
+	// The notification chat may always command the bot
+	if len(config.TelegramAllowedChats) == 0 {
+		if chatID, err := strconv.ParseInt(config.TelegramChatID, 10, 64); err == nil {
+			config.TelegramAllowedChats = []int64{chatID}
+		}
+	}
+
+	// US releases move crypto the most
+	if config.CalendarCurrencies == nil {
+		config.CalendarCurrencies = []string{"USD"}
 	}
 
 	// Validate required fields
 	if config.APIKey == "" {
-		log.Fatal("API_KEY environment variable is required")
+		return nil, fmt.Errorf("API_KEY environment variable is required")
 	}
 
 	if config.BinanceAPIKey == "" || config.BinanceSecretKey == "" {
-		log.Fatal("BINANCE_API_KEY and BINANCE_SECRET_KEY environment variables are required")
+		return nil, fmt.Errorf("BINANCE_API_KEY and BINANCE_SECRET_KEY environment variables are required")
 	}
 
-	if config.FirebaseDBURL == "" {
-		log.Fatal("FIREBASE_DATABASE_URL environment variable is required")
+	if config.StorageBackend == "firebase" && config.FirebaseDBURL == "" {
+		return nil, fmt.Errorf("FIREBASE_DATABASE_URL environment variable is required")
 	}
 
-	return config
+	if config.AutoDeleverageEnabled && config.AutoDeleverageMode != "ADD_MARGIN" && config.AutoDeleverageMode != "REDUCE_POSITION" {
+		return nil, fmt.Errorf("AUTO_DELEVERAGE_MODE must be ADD_MARGIN or REDUCE_POSITION, got %q", config.AutoDeleverageMode)
+	}
+
+	return config, nil
 }
 
-// getEnv retrieves an environment variable or returns a fallback value
+// getEnv retrieves a setting (environment, then config file) or returns a fallback value
 func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
+	if value := lookup(key); value != "" {
 		return value
 	}
 	return fallback
 }
+
+// getEnvBool retrieves a boolean environment variable or returns a fallback value
+func getEnvBool(key string, fallback bool) bool {
+	if value := lookup(key); value != "" {
+		return value == "true" || value == "1"
+	}
+	return fallback
+}
+
+// getEnvInt retrieves an integer environment variable or returns a fallback value
+func getEnvInt(key string, fallback int) int {
+	if value := lookup(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		logging.Warn().Msgf("Invalid value for %s, using default %v", key, fallback)
+	}
+	return fallback
+}
+
+// getEnvFloat retrieves a float environment variable or returns a fallback value
+func getEnvFloat(key string, fallback float64) float64 {
+	if value := lookup(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+		logging.Warn().Msgf("Invalid value for %s, using default %v", key, fallback)
+	}
+	return fallback
+}
+
+// getEnvDuration retrieves a duration environment variable (e.g. "30s", "5m") or returns a fallback value
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value := lookup(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+		logging.Warn().Msgf("Invalid value for %s, using default %v", key, fallback)
+	}
+	return fallback
+}
+
+// getEnvDate retrieves a date (2006-01-02, UTC) or RFC 3339 timestamp, or
+// the zero time when unset or invalid
+func getEnvDate(key string) time.Time {
+	value := lookup(key)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	logging.Warn().Msgf("Invalid value for %s (expected YYYY-MM-DD), ignoring", key)
+	return time.Time{}
+}
+
+// getEnvList retrieves a comma-separated environment variable as a slice
+func getEnvList(key string) []string {
+	value := lookup(key)
+	if value == "" {
+		return nil
+	}
+
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getFollowerAccounts reads COPY_TRADING_FOLLOWERS=name1,name2 and the
+// FOLLOWER_<NAME>_API_KEY / _SECRET_KEY / _MULTIPLIER variables for each
+func getFollowerAccounts() []FollowerAccount {
+	followers := []FollowerAccount{}
+	for _, name := range getEnvList("COPY_TRADING_FOLLOWERS") {
+		prefix := "FOLLOWER_" + strings.ToUpper(name) + "_"
+		follower := FollowerAccount{
+			Name:       name,
+			APIKey:     getEnv(prefix+"API_KEY", ""),
+			SecretKey:  getEnv(prefix+"SECRET_KEY", ""),
+			Multiplier: getEnvFloat(prefix+"MULTIPLIER", 1),
+		}
+		if follower.APIKey == "" || follower.SecretKey == "" {
+			logging.Warn().Msgf("Follower %q has no %sAPI_KEY/%sSECRET_KEY, skipping", name, prefix, prefix)
+			continue
+		}
+		if follower.Multiplier <= 0 {
+			logging.Warn().Msgf("Follower %q has a non-positive multiplier, skipping", name)
+			continue
+		}
+		followers = append(followers, follower)
+	}
+	return followers
+}
+
+// getBinanceAccounts reads BINANCE_ACCOUNTS=name1,name2 and the
+// BINANCE_ACCOUNT_<NAME>_API_KEY / _SECRET_KEY variables for each. The
+// BINANCE_API_KEY account is always "main".
+func getBinanceAccounts() []BinanceAccount {
+	accounts := []BinanceAccount{}
+	seen := map[string]bool{"main": true}
+	for _, name := range getEnvList("BINANCE_ACCOUNTS") {
+		if seen[name] {
+			logging.Warn().Msgf("Duplicate or reserved account name %q in BINANCE_ACCOUNTS, skipping", name)
+			continue
+		}
+		prefix := "BINANCE_ACCOUNT_" + strings.ToUpper(name) + "_"
+		account := BinanceAccount{
+			Name:      name,
+			APIKey:    getEnv(prefix+"API_KEY", ""),
+			SecretKey: getEnv(prefix+"SECRET_KEY", ""),
+		}
+		if account.APIKey == "" || account.SecretKey == "" {
+			logging.Warn().Msgf("Account %q has no %sAPI_KEY/%sSECRET_KEY, skipping", name, prefix, prefix)
+			continue
+		}
+		seen[name] = true
+		accounts = append(accounts, account)
+	}
+	return accounts
+}
+
+// getHTTPClient reads the connection pool settings of an outbound client
+// from the variables starting with prefix (e.g. BINANCE_HTTP_MAX_IDLE_CONNS)
+func getHTTPClient(prefix string) httpclient.Config {
+	defaults := httpclient.Defaults
+	return httpclient.Config{
+		MaxIdleConns:          getEnvInt(prefix+"MAX_IDLE_CONNS", defaults.MaxIdleConns),
+		MaxIdleConnsPerHost:   getEnvInt(prefix+"MAX_IDLE_CONNS_PER_HOST", defaults.MaxIdleConnsPerHost),
+		MaxConnsPerHost:       getEnvInt(prefix+"MAX_CONNS_PER_HOST", defaults.MaxConnsPerHost),
+		IdleConnTimeout:       getEnvDuration(prefix+"IDLE_CONN_TIMEOUT", defaults.IdleConnTimeout),
+		KeepAlive:             getEnvDuration(prefix+"KEEP_ALIVE", defaults.KeepAlive),
+		DialTimeout:           getEnvDuration(prefix+"DIAL_TIMEOUT", defaults.DialTimeout),
+		TLSHandshakeTimeout:   getEnvDuration(prefix+"TLS_HANDSHAKE_TIMEOUT", defaults.TLSHandshakeTimeout),
+		ResponseHeaderTimeout: getEnvDuration(prefix+"RESPONSE_HEADER_TIMEOUT", defaults.ResponseHeaderTimeout),
+		TLSSessionCache:       getEnvInt(prefix+"TLS_SESSION_CACHE", defaults.TLSSessionCache),
+	}
+}
+
+// getEnvInt64List gets a comma-separated list of integers (e.g. chat IDs)
+func getEnvInt64List(key string) []int64 {
+	values := []int64{}
+	for _, item := range getEnvList(key) {
+		v, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			logging.Warn().Msgf("Invalid value %q in %s, skipping", item, key)
+			continue
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+// getEnvIntMap gets comma-separated name=value pairs with integer values
+// (e.g. "POST /api/trade=30,/api/trades=120")
+func getEnvIntMap(key string) map[string]int {
+	values := map[string]int{}
+	for _, item := range getEnvList(key) {
+		sep := strings.LastIndex(item, "=")
+		if sep <= 0 {
+			logging.Warn().Msgf("Invalid entry %q in %s, skipping", item, key)
+			continue
+		}
+		v, err := strconv.Atoi(strings.TrimSpace(item[sep+1:]))
+		if err != nil {
+			logging.Warn().Msgf("Invalid value %q in %s, skipping", item, key)
+			continue
+		}
+		values[strings.TrimSpace(item[:sep])] = v
+	}
+	return values
+}
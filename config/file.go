@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	fileMu       sync.RWMutex
+	fileValues   map[string]string
+	secretValues map[string]string // From SECRETS_PROVIDER, see secrets.go
+)
+
+// lookup returns a setting from the environment, falling back to the
+// secrets manager and then the config file. Environment variables always win.
+func lookup(key string) string {
+	fileMu.RLock()
+	defer fileMu.RUnlock()
+
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	if value, ok := secretValues[key]; ok {
+		return value
+	}
+	return fileValues[key]
+}
+
+// loadFile reads CONFIG_FILE (YAML or TOML) into the values behind lookup.
+// Without CONFIG_FILE only the environment is used.
+func loadFile() error {
+	path := os.Getenv("CONFIG_FILE")
+	values := map[string]string{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %v", err)
+		}
+
+		var doc map[string]interface{}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(data, &doc)
+		case ".toml":
+			err = toml.Unmarshal(data, &doc)
+		default:
+			return fmt.Errorf("unsupported config file %s (expected .yaml, .yml or .toml)", path)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse config file %s: %v", path, err)
+		}
+
+		flatten("", doc, values)
+	}
+
+	fileMu.Lock()
+	defer fileMu.Unlock()
+
+	fileValues = values
+	return nil
+}
+
+// flatten turns nested keys into the environment variable names they stand
+// for: {binance: {testnet: true}} sets BINANCE_TESTNET=true. Lists become
+// comma-separated values and maps of scalars under a list-style key (e.g.
+// rate_limit_routes) become name=value pairs.
+func flatten(prefix string, node interface{}, values map[string]string) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if prefix != "" && isPairList(v) {
+			values[prefix] = joinPairs(v)
+			return
+		}
+		for key, child := range v {
+			name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+			if prefix != "" {
+				name = prefix + "_" + name
+			}
+			flatten(name, child, values)
+		}
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		values[prefix] = strings.Join(items, ",")
+	case nil:
+	default:
+		values[prefix] = fmt.Sprint(v)
+	}
+}
+
+// isPairList reports whether a table holds name=value pairs rather than
+// nested settings: its keys are routes (they contain a slash)
+func isPairList(table map[string]interface{}) bool {
+	for key, value := range table {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+		if !strings.Contains(key, "/") {
+			return false
+		}
+	}
+	return len(table) > 0
+}
+
+// joinPairs formats a table as sorted name=value pairs
+func joinPairs(table map[string]interface{}) string {
+	pairs := make([]string, 0, len(table))
+	for key, value := range table {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/secrets"
+	"fmt"
+	"os"
+	"time"
+)
+
+// secretsFetchTimeout bounds the secret read at startup
+const secretsFetchTimeout = 30 * time.Second
+
+var (
+	secretProvider          secrets.Provider
+	firebaseCredentialsPath string // Temporary file written from FIREBASE_CREDENTIALS_JSON
+)
+
+// loadSecrets reads the credentials secret named by SECRETS_PROVIDER and
+// SECRETS_NAME once; afterwards a Rotator (see WatchSecrets) keeps the values
+// current. The secret is a JSON object of settings, e.g.
+// {"BINANCE_API_KEY": "...", "BINANCE_SECRET_KEY": "...", "API_KEY": "..."}.
+func loadSecrets() error {
+	if secretProvider != nil || lookup("SECRETS_PROVIDER") == "" {
+		return nil
+	}
+
+	provider, err := secrets.New(secrets.Config{
+		Provider:       lookup("SECRETS_PROVIDER"),
+		Name:           lookup("SECRETS_NAME"),
+		VaultAddr:      lookup("VAULT_ADDR"),
+		VaultToken:     lookup("VAULT_TOKEN"),
+		VaultMount:     lookup("VAULT_MOUNT"),
+		VaultNamespace: lookup("VAULT_NAMESPACE"),
+		AWSRegion:      lookup("AWS_REGION"),
+		GCPProject:     lookup("SECRETS_GCP_PROJECT"),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretsFetchTimeout)
+	defer cancel()
+	values, err := provider.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	secretProvider = provider
+	return setSecrets(values)
+}
+
+// setSecrets replaces the values layered from the secret
+func setSecrets(values map[string]string) error {
+	fileMu.Lock()
+	secretValues = values
+	fileMu.Unlock()
+
+	return writeFirebaseCredentials()
+}
+
+// writeFirebaseCredentials stores FIREBASE_CREDENTIALS_JSON (a service
+// account kept in the secrets manager) in a private temporary file, which
+// becomes FirebaseCredentialsFile unless a file is configured. Rotated
+// credentials overwrite the file; Firebase reloads it when a token is refused.
+func writeFirebaseCredentials() error {
+	credentials := lookup("FIREBASE_CREDENTIALS_JSON")
+	if credentials == "" || lookup("FIREBASE_CREDENTIALS_FILE") != "" {
+		return nil
+	}
+
+	if firebaseCredentialsPath == "" {
+		file, err := os.CreateTemp("", "firebase-credentials-*.json")
+		if err != nil {
+			return fmt.Errorf("failed to store FIREBASE_CREDENTIALS_JSON: %v", err)
+		}
+		file.Close()
+		firebaseCredentialsPath = file.Name()
+	}
+
+	if err := os.WriteFile(firebaseCredentialsPath, []byte(credentials), 0o600); err != nil {
+		return fmt.Errorf("failed to store FIREBASE_CREDENTIALS_JSON: %v", err)
+	}
+	return nil
+}
+
+// WatchSecrets returns a rotator that re-reads the secret every interval
+// and updates the layered values before notifying its other listeners, or
+// nil without SECRETS_PROVIDER. The caller starts and stops it.
+func WatchSecrets(interval time.Duration) *secrets.Rotator {
+	if secretProvider == nil {
+		return nil
+	}
+
+	fileMu.RLock()
+	initial := make(map[string]string, len(secretValues))
+	for key, value := range secretValues {
+		initial[key] = value
+	}
+	fileMu.RUnlock()
+
+	rotator := secrets.NewRotator(secretProvider, initial, interval)
+	rotator.OnChange(func(changed map[string]string) {
+		values := make(map[string]string, len(initial))
+		fileMu.RLock()
+		for key, value := range secretValues {
+			values[key] = value
+		}
+		fileMu.RUnlock()
+
+		for key, value := range changed {
+			if value == "" {
+				delete(values, key)
+			} else {
+				values[key] = value
+			}
+		}
+		if err := setSecrets(values); err != nil {
+			logging.Warn().Err(err).Msg("Could not apply rotated secrets")
+		}
+	})
+	return rotator
+}
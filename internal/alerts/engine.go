@@ -0,0 +1,231 @@
+package alerts
+
+import (
+	"context"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/cluster"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/notifications"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Alert statuses
+const (
+	StatusActive    = "ACTIVE"
+	StatusTriggered = "TRIGGERED"
+	StatusCanceled  = "CANCELED"
+)
+
+// claimTTL keeps a fired alert claimed long after every instance saw the price
+const claimTTL = 24 * time.Hour
+
+// ErrNotActive is returned when canceling an alert that has already fired
+var ErrNotActive = errors.New("price alert is no longer active")
+
+// Store persists price alerts
+type Store interface {
+	GetPriceAlerts(ctx context.Context) ([]*models.PriceAlert, error)
+	SavePriceAlert(ctx context.Context, alert *models.PriceAlert) error
+}
+
+// TradeSubmitter executes a trade request through the normal intake
+type TradeSubmitter func(ctx context.Context, req *models.TradeRequest) (*models.Trade, error)
+
+// Engine evaluates active price alerts against the shared price feed
+type Engine struct {
+	feed        *binance.PriceFeed
+	store       Store
+	notifier    *notifications.Notifier
+	submit      TradeSubmitter
+	locker      *cluster.Locker                          // Claims alerts so each fires once across instances
+	alerts      map[string]map[string]*models.PriceAlert // symbol -> alert ID -> alert
+	fired       map[string]time.Time                     // alert ID -> when it fired here, for claimTTL
+	unsubscribe map[string]func()
+	mu          sync.Mutex
+}
+
+// NewEngine creates a price alert engine
+func NewEngine(feed *binance.PriceFeed, store Store, notifier *notifications.Notifier, submit TradeSubmitter) *Engine {
+	return &Engine{
+		feed:        feed,
+		store:       store,
+		notifier:    notifier,
+		submit:      submit,
+		alerts:      make(map[string]map[string]*models.PriceAlert),
+		fired:       make(map[string]time.Time),
+		unsubscribe: make(map[string]func()),
+	}
+}
+
+// UseLocker makes instances watching the same alert claim it before firing,
+// so its notification and trade happen once
+func (e *Engine) UseLocker(locker *cluster.Locker) {
+	e.locker = locker
+}
+
+// Start loads active alerts from the store and begins watching them
+func (e *Engine) Start(ctx context.Context) error {
+	alerts, err := e.store.GetPriceAlerts(ctx)
+	if err != nil {
+		return err
+	}
+
+	active := 0
+	for _, alert := range alerts {
+		if alert.Status == StatusActive {
+			e.track(alert)
+			active++
+		}
+	}
+
+	logging.Info().Msgf("Price alert engine started (%d active alerts)", active)
+	return nil
+}
+
+// Stop closes every price subscription
+func (e *Engine) Stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for symbol, unsubscribe := range e.unsubscribe {
+		unsubscribe()
+		delete(e.unsubscribe, symbol)
+	}
+	e.alerts = make(map[string]map[string]*models.PriceAlert)
+}
+
+// Add stores a new alert and starts watching it
+func (e *Engine) Add(ctx context.Context, alert *models.PriceAlert) error {
+	alert.Status = StatusActive
+	if err := e.store.SavePriceAlert(ctx, alert); err != nil {
+		return err
+	}
+
+	e.track(alert)
+	return nil
+}
+
+// Cancel stops watching an alert and marks it CANCELED. The alert is taken
+// from the engine under its lock, so a price arriving meanwhile cannot fire
+// it too; ErrNotActive means it fired first.
+func (e *Engine) Cancel(ctx context.Context, alert *models.PriceAlert) error {
+	e.mu.Lock()
+	_, fired := e.fired[alert.ID]
+	if !fired {
+		e.removeLocked(alert)
+	}
+	e.mu.Unlock()
+
+	if fired {
+		return ErrNotActive
+	}
+
+	// Another instance may be watching it: claim it like fire does, so only
+	// one of them wins
+	if e.locker != nil {
+		claimed, err := e.locker.TryLock(ctx, "alert:"+alert.ID, claimTTL)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return ErrNotActive
+		}
+	}
+
+	alert.Status = StatusCanceled
+	return e.store.SavePriceAlert(ctx, alert)
+}
+
+func (e *Engine) track(alert *models.PriceAlert) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.alerts[alert.Symbol] == nil {
+		e.alerts[alert.Symbol] = make(map[string]*models.PriceAlert)
+	}
+	e.alerts[alert.Symbol][alert.ID] = alert
+
+	if _, ok := e.unsubscribe[alert.Symbol]; !ok {
+		e.unsubscribe[alert.Symbol] = e.feed.Subscribe(alert.Symbol, e.onPrice)
+	}
+}
+
+// removeLocked drops an alert and closes the symbol subscription when it was the last one
+func (e *Engine) removeLocked(alert *models.PriceAlert) {
+	delete(e.alerts[alert.Symbol], alert.ID)
+
+	if len(e.alerts[alert.Symbol]) == 0 {
+		delete(e.alerts, alert.Symbol)
+		if unsubscribe, ok := e.unsubscribe[alert.Symbol]; ok {
+			unsubscribe()
+			delete(e.unsubscribe, alert.Symbol)
+		}
+	}
+}
+
+// onPrice fires every alert on the symbol whose condition is met
+func (e *Engine) onPrice(symbol string, price float64) {
+	e.mu.Lock()
+	triggered := []*models.PriceAlert{}
+	for _, alert := range e.alerts[symbol] {
+		if (alert.Condition == models.AlertConditionAbove && price >= alert.Price) ||
+			(alert.Condition == models.AlertConditionBelow && price <= alert.Price) {
+			alert.Status = StatusTriggered
+			alert.TriggeredAt = time.Now().Unix()
+			alert.TriggerPrice = price
+			triggered = append(triggered, alert)
+		}
+	}
+	now := time.Now()
+	for id, at := range e.fired {
+		if now.Sub(at) > claimTTL {
+			delete(e.fired, id)
+		}
+	}
+	for _, alert := range triggered {
+		e.removeLocked(alert)
+		e.fired[alert.ID] = now
+	}
+	e.mu.Unlock()
+
+	for _, alert := range triggered {
+		go e.fire(alert)
+	}
+}
+
+// fire submits the alert's trade template (if any), persists and announces it
+func (e *Engine) fire(alert *models.PriceAlert) {
+	ctx := context.Background()
+
+	if e.locker != nil {
+		claimed, err := e.locker.TryLock(ctx, "alert:"+alert.ID, claimTTL)
+		if err != nil {
+			logging.Warn().Err(err).Msgf("Price alert %s: could not claim, firing anyway", alert.ID)
+		} else if !claimed {
+			logging.Info().Msgf("Price alert %s fired by another instance", alert.ID)
+			return
+		}
+	}
+
+	logging.Info().Msgf("Price alert %s: %s %s %.4f (mark %.4f)", alert.ID, alert.Symbol, alert.Condition, alert.Price, alert.TriggerPrice)
+
+	if alert.TradeTemplate != nil && e.submit != nil {
+		trade, err := e.submit(ctx, alert.TradeTemplate)
+		if trade != nil {
+			alert.TradeID = trade.ID
+		}
+		if err != nil {
+			alert.TradeError = err.Error()
+			logging.Error().Err(err).Msgf("Price alert %s: trade template failed", alert.ID)
+		}
+	}
+
+	if err := e.store.SavePriceAlert(ctx, alert); err != nil {
+		logging.Warn().Err(err).Msgf("Price alert %s: failed to save", alert.ID)
+	}
+
+	e.notifier.Publish(notifications.PriceAlertTriggered(alert))
+}
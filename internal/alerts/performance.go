@@ -0,0 +1,266 @@
+package alerts
+
+import (
+	"context"
+	"crypto-trading-api/internal/analytics"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/notifications"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PerformanceStore provides the trades and settings performance alerts are
+// evaluated from, and persists the alerts
+type PerformanceStore interface {
+	GetAllTrades(ctx context.Context) ([]*models.Trade, error)
+	GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error)
+	GetPerformanceAlerts(ctx context.Context) ([]*models.PerformanceAlert, error)
+	SavePerformanceAlert(ctx context.Context, alert *models.PerformanceAlert) error
+}
+
+// PerformanceConfig configures the performance alert job
+type PerformanceConfig struct {
+	Interval time.Duration // How often users' trades are evaluated
+	Window   time.Duration // Rolling window of the drawdown
+	Repeat   time.Duration // Reminder interval of unacknowledged alerts (0 = none)
+	Defaults models.PerformanceThresholds
+}
+
+// PerformanceMonitor periodically measures each user's drawdown, losing
+// streak and daily loss, opens an alert (and notifies) when one crosses its
+// threshold and resolves it once it is back within. Users' settings override
+// the default thresholds.
+type PerformanceMonitor struct {
+	store    PerformanceStore
+	notifier *notifications.Notifier
+	config   PerformanceConfig
+	mu       sync.Mutex // Serializes evaluations with acknowledgements and snoozes
+	stopChan chan struct{}
+}
+
+// NewPerformanceMonitor creates a performance alert monitor
+func NewPerformanceMonitor(store PerformanceStore, notifier *notifications.Notifier, config PerformanceConfig) *PerformanceMonitor {
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Minute
+	}
+	if config.Window <= 0 {
+		config.Window = 30 * 24 * time.Hour
+	}
+	return &PerformanceMonitor{
+		store:    store,
+		notifier: notifier,
+		config:   config,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs the evaluation loop in the background. It may be started again
+// after Stop.
+func (m *PerformanceMonitor) Start() {
+	stop := make(chan struct{})
+	m.stopChan = stop
+
+	logging.Info().Msgf("Performance alerts started (interval=%v, window=%v)", m.config.Interval, m.config.Window)
+
+	go func() {
+		ticker := time.NewTicker(m.config.Interval)
+		defer ticker.Stop()
+
+		m.Evaluate(context.Background())
+		for {
+			select {
+			case <-ticker.C:
+				m.Evaluate(context.Background())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the evaluation loop
+func (m *PerformanceMonitor) Stop() {
+	close(m.stopChan)
+}
+
+// Evaluate measures every user with closed trades or open alerts once
+func (m *PerformanceMonitor) Evaluate(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	trades, err := m.store.GetAllTrades(ctx)
+	if err != nil {
+		logging.Warn().Err(err).Msg("Performance alerts: failed to get trades")
+		return
+	}
+	existing, err := m.store.GetPerformanceAlerts(ctx)
+	if err != nil {
+		logging.Warn().Err(err).Msg("Performance alerts: failed to get alerts")
+		return
+	}
+
+	byUser := make(map[string][]*models.Trade)
+	for _, trade := range trades {
+		if trade.UserID != "" && trade.Status == "CLOSED" {
+			byUser[trade.UserID] = append(byUser[trade.UserID], trade)
+		}
+	}
+	open := make(map[string]map[string]*models.PerformanceAlert) // user -> kind -> alert
+	for _, alert := range existing {
+		if !alert.Open() {
+			continue
+		}
+		if open[alert.UserID] == nil {
+			open[alert.UserID] = make(map[string]*models.PerformanceAlert)
+			if _, ok := byUser[alert.UserID]; !ok {
+				byUser[alert.UserID] = nil
+			}
+		}
+		open[alert.UserID][alert.Kind] = alert
+	}
+
+	now := time.Now()
+	for userID, userTrades := range byUser {
+		thresholds, loc := m.userThresholds(ctx, userID)
+		performance := analytics.MeasurePerformance(userTrades, now, m.config.Window, loc)
+
+		m.check(ctx, now, userID, models.PerformanceAlertDrawdown, performance.Drawdown, thresholds.MaxDrawdown, "", open[userID])
+		m.check(ctx, now, userID, models.PerformanceAlertLosingStreak, float64(performance.LosingStreak), float64(thresholds.LosingStreak), "", open[userID])
+		m.check(ctx, now, userID, models.PerformanceAlertDailyLoss, performance.DailyLoss, thresholds.DailyLoss, performance.Day, open[userID])
+	}
+}
+
+// userThresholds returns a user's thresholds (settings over the defaults)
+// and time zone
+func (m *PerformanceMonitor) userThresholds(ctx context.Context, userID string) (models.PerformanceThresholds, *time.Location) {
+	thresholds, loc := m.config.Defaults, time.UTC
+
+	settings, err := m.store.GetUserSettings(ctx, userID)
+	if err != nil {
+		logging.Warn().Err(err).Msgf("Performance alerts: failed to get the settings of %s, using defaults", userID)
+		return thresholds, loc
+	}
+	if settings == nil {
+		return thresholds, loc
+	}
+
+	if settings.AlertThresholds.MaxDrawdown > 0 {
+		thresholds.MaxDrawdown = settings.AlertThresholds.MaxDrawdown
+	}
+	if settings.AlertThresholds.LosingStreak > 0 {
+		thresholds.LosingStreak = settings.AlertThresholds.LosingStreak
+	}
+	if settings.AlertThresholds.DailyLoss > 0 {
+		thresholds.DailyLoss = settings.AlertThresholds.DailyLoss
+	}
+	if settings.Timezone != "" {
+		if userLoc, err := time.LoadLocation(settings.Timezone); err == nil {
+			loc = userLoc
+		}
+	}
+	return thresholds, loc
+}
+
+// check opens, updates or resolves a user's alert of one kind. A daily loss
+// alert belongs to its day, so the next day resolves it.
+func (m *PerformanceMonitor) check(ctx context.Context, now time.Time, userID, kind string, value, threshold float64, day string, open map[string]*models.PerformanceAlert) {
+	alert := open[kind]
+	breached := threshold > 0 && value >= threshold
+
+	if alert != nil && (!breached || alert.Day != day) {
+		alert.Status = models.PerformanceAlertResolved
+		alert.Value = value
+		alert.ResolvedAt = now.Unix()
+		alert.UpdatedAt = now.Unix()
+		m.save(ctx, alert)
+		logging.Info().Msgf("Performance alert %s: %s of %s resolved", alert.ID, kind, userID)
+		alert = nil
+	}
+	if !breached {
+		return
+	}
+
+	if alert == nil {
+		alert = &models.PerformanceAlert{
+			ID:          uuid.New().String(),
+			UserID:      userID,
+			Kind:        kind,
+			Status:      models.PerformanceAlertActive,
+			Threshold:   threshold,
+			Value:       value,
+			Worst:       value,
+			Day:         day,
+			TriggeredAt: now.Unix(),
+			NotifiedAt:  now.Unix(),
+			UpdatedAt:   now.Unix(),
+		}
+		m.save(ctx, alert)
+		logging.Warn().Msgf("Performance alert %s: %s of %s at %.2f (threshold %.2f)", alert.ID, kind, userID, value, threshold)
+		m.notifier.Publish(notifications.PerformanceAlertTriggered(alert, false))
+		return
+	}
+
+	changed := alert.Value != value || alert.Threshold != threshold
+	alert.Value, alert.Threshold = value, threshold
+	if value > alert.Worst {
+		alert.Worst = value
+	}
+
+	// Reminders come every Repeat, or once a snooze ends
+	remind := alert.Status == models.PerformanceAlertActive && now.Unix() >= alert.SnoozedUntil &&
+		(alert.SnoozedUntil > 0 || (m.config.Repeat > 0 && now.Sub(time.Unix(alert.NotifiedAt, 0)) >= m.config.Repeat))
+	if remind {
+		alert.NotifiedAt = now.Unix()
+		alert.SnoozedUntil = 0
+		changed = true
+	}
+	if changed {
+		alert.UpdatedAt = now.Unix()
+		m.save(ctx, alert)
+	}
+	if remind {
+		m.notifier.Publish(notifications.PerformanceAlertTriggered(alert, true))
+	}
+}
+
+func (m *PerformanceMonitor) save(ctx context.Context, alert *models.PerformanceAlert) {
+	if err := m.store.SavePerformanceAlert(ctx, alert); err != nil {
+		logging.Warn().Err(err).Msgf("Performance alert %s: failed to save", alert.ID)
+	}
+}
+
+// Acknowledge stops the reminders of an open alert; it stays open until
+// resolved
+func (m *PerformanceMonitor) Acknowledge(ctx context.Context, alert *models.PerformanceAlert, by string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !alert.Open() {
+		return fmt.Errorf("alert status is %s", alert.Status)
+	}
+	now := time.Now().Unix()
+	alert.Status = models.PerformanceAlertAcknowledged
+	alert.AcknowledgedAt = now
+	alert.AcknowledgedBy = by
+	alert.SnoozedUntil = 0
+	alert.UpdatedAt = now
+	return m.store.SavePerformanceAlert(ctx, alert)
+}
+
+// Snooze holds back the reminders of an ACTIVE alert until the given time;
+// the first evaluation after it reminds again if the alert is still open
+func (m *PerformanceMonitor) Snooze(ctx context.Context, alert *models.PerformanceAlert, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if alert.Status != models.PerformanceAlertActive {
+		return fmt.Errorf("alert status is %s", alert.Status)
+	}
+	alert.SnoozedUntil = until.Unix()
+	alert.UpdatedAt = time.Now().Unix()
+	return m.store.SavePerformanceAlert(ctx, alert)
+}
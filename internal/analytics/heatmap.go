@@ -0,0 +1,126 @@
+package analytics
+
+import (
+	"crypto-trading-api/internal/models"
+	"time"
+)
+
+// Heatmap bucketing: by when trades were opened or closed
+const (
+	HeatmapByOpen  = "open"
+	HeatmapByClose = "close"
+)
+
+// HeatmapCell represents the closed trades of one bucket
+type HeatmapCell struct {
+	Hour       *int    `json:"hour,omitempty"`    // 0-23 in the report's time zone
+	Weekday    string  `json:"weekday,omitempty"` // Monday-Sunday
+	Trades     int     `json:"trades"`
+	Wins       int     `json:"wins"`
+	Losses     int     `json:"losses"`
+	WinRate    float64 `json:"winRate"` // Percent of trades
+	PnL        float64 `json:"pnl"`
+	NetPnL     float64 `json:"netPnl"` // PnL minus fees
+	AveragePnL float64 `json:"averagePnl"`
+}
+
+// Heatmap represents closed-trade results by hour of day and day of week
+type Heatmap struct {
+	Period    string          `json:"period"`
+	Timezone  string          `json:"timezone"`
+	By        string          `json:"by"` // open or close time
+	Trades    int             `json:"trades"`
+	ByHour    []HeatmapCell   `json:"byHour"`    // 24 cells, hour 0 first
+	ByWeekday []HeatmapCell   `json:"byWeekday"` // 7 cells, Monday first
+	Grid      [][]HeatmapCell `json:"grid"`      // Weekday (Monday first) x hour
+}
+
+// weekdays in report order, Monday first
+var weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+// BuildHeatmap buckets the closed trades whose open (or close) time is at or
+// after startTime by hour of day and day of week in loc. Trades without that
+// time are left out.
+func BuildHeatmap(trades []*models.Trade, startTime int64, by string, loc *time.Location) *Heatmap {
+	heatmap := &Heatmap{
+		Timezone:  loc.String(),
+		By:        by,
+		ByHour:    make([]HeatmapCell, 24),
+		ByWeekday: make([]HeatmapCell, len(weekdays)),
+		Grid:      make([][]HeatmapCell, len(weekdays)),
+	}
+	for hour := range heatmap.ByHour {
+		heatmap.ByHour[hour].Hour = intPtr(hour)
+	}
+	for day, weekday := range weekdays {
+		heatmap.ByWeekday[day].Weekday = weekday.String()
+		heatmap.Grid[day] = make([]HeatmapCell, 24)
+		for hour := range heatmap.Grid[day] {
+			heatmap.Grid[day][hour].Hour = intPtr(hour)
+			heatmap.Grid[day][hour].Weekday = weekday.String()
+		}
+	}
+
+	for _, trade := range trades {
+		if trade.Status != "CLOSED" {
+			continue
+		}
+		at := tradeTime(trade, by)
+		if at == 0 || at < startTime {
+			continue
+		}
+
+		t := time.Unix(at, 0).In(loc)
+		day := (int(t.Weekday()) + 6) % 7 // Monday = 0
+		heatmap.Trades++
+		heatmap.ByHour[t.Hour()].add(trade)
+		heatmap.ByWeekday[day].add(trade)
+		heatmap.Grid[day][t.Hour()].add(trade)
+	}
+
+	for hour := range heatmap.ByHour {
+		heatmap.ByHour[hour].finish()
+	}
+	for day := range heatmap.ByWeekday {
+		heatmap.ByWeekday[day].finish()
+		for hour := range heatmap.Grid[day] {
+			heatmap.Grid[day][hour].finish()
+		}
+	}
+	return heatmap
+}
+
+// tradeTime returns when a trade was opened (filled, or created if the fill
+// time is missing) or closed, in Unix seconds
+func tradeTime(trade *models.Trade, by string) int64 {
+	if by == HeatmapByClose {
+		return trade.ClosedAt
+	}
+	if trade.ExecutedAt > 0 {
+		return trade.ExecutedAt
+	}
+	return trade.CreatedAt
+}
+
+func (c *HeatmapCell) add(trade *models.Trade) {
+	c.Trades++
+	switch {
+	case trade.PnL > 0:
+		c.Wins++
+	case trade.PnL < 0:
+		c.Losses++
+	}
+	c.PnL += trade.PnL
+	c.NetPnL += trade.PnL - trade.Commission
+}
+
+func (c *HeatmapCell) finish() {
+	if c.Trades > 0 {
+		c.WinRate = float64(c.Wins) / float64(c.Trades) * 100
+		c.AveragePnL = c.PnL / float64(c.Trades)
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,89 @@
+package analytics
+
+import (
+	"crypto-trading-api/internal/models"
+	"sort"
+	"time"
+)
+
+// IncomeTotal is the sum of one income type in one asset
+type IncomeTotal struct {
+	Type   string  `json:"type"`
+	Asset  string  `json:"asset"`
+	Amount float64 `json:"amount"` // Signed: negative = paid (commissions, funding paid, withdrawals)
+	Count  int     `json:"count"`
+}
+
+// IncomeDay is one asset's balance changes on one day
+type IncomeDay struct {
+	Date   string             `json:"date"` // YYYY-MM-DD (UTC)
+	Asset  string             `json:"asset"`
+	ByType map[string]float64 `json:"byType"`
+	Net    float64            `json:"net"` // Sum of all types
+}
+
+// IncomeHistory lists balance changes of every type with their totals
+type IncomeHistory struct {
+	From    int64                  `json:"from"` // Unix seconds
+	To      int64                  `json:"to"`
+	Records []*models.IncomeRecord `json:"records"` // Oldest first
+	Totals  []IncomeTotal          `json:"totals"`  // By type and asset
+	Daily   []IncomeDay            `json:"daily"`   // By day and asset
+}
+
+// BuildIncomeHistory totals income records by type and by day
+func BuildIncomeHistory(from, to int64, records []*models.IncomeRecord) *IncomeHistory {
+	sorted := make([]*models.IncomeRecord, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time < sorted[j].Time
+	})
+
+	totals := make(map[string]*IncomeTotal)
+	days := make(map[string]*IncomeDay)
+	for _, record := range sorted {
+		key := record.IncomeType + "|" + record.Asset
+		if totals[key] == nil {
+			totals[key] = &IncomeTotal{Type: record.IncomeType, Asset: record.Asset}
+		}
+		totals[key].Amount += record.Income
+		totals[key].Count++
+
+		date := time.UnixMilli(record.Time).UTC().Format("2006-01-02")
+		key = date + "|" + record.Asset
+		if days[key] == nil {
+			days[key] = &IncomeDay{Date: date, Asset: record.Asset, ByType: make(map[string]float64)}
+		}
+		days[key].ByType[record.IncomeType] += record.Income
+		days[key].Net += record.Income
+	}
+
+	history := &IncomeHistory{
+		From:    from,
+		To:      to,
+		Records: sorted,
+		Totals:  make([]IncomeTotal, 0, len(totals)),
+		Daily:   make([]IncomeDay, 0, len(days)),
+	}
+	for _, total := range totals {
+		history.Totals = append(history.Totals, *total)
+	}
+	for _, day := range days {
+		history.Daily = append(history.Daily, *day)
+	}
+
+	sort.Slice(history.Totals, func(i, j int) bool {
+		if history.Totals[i].Type != history.Totals[j].Type {
+			return history.Totals[i].Type < history.Totals[j].Type
+		}
+		return history.Totals[i].Asset < history.Totals[j].Asset
+	})
+	sort.Slice(history.Daily, func(i, j int) bool {
+		if history.Daily[i].Date != history.Daily[j].Date {
+			return history.Daily[i].Date < history.Daily[j].Date
+		}
+		return history.Daily[i].Asset < history.Daily[j].Asset
+	})
+
+	return history
+}
@@ -0,0 +1,171 @@
+package analytics
+
+import (
+	"crypto-trading-api/internal/models"
+	"math"
+	"sort"
+)
+
+// Leaderboard sort keys
+const (
+	RankByPnL      = "pnl"
+	RankByNetPnL   = "netPnl"
+	RankByWinRate  = "winRate"
+	RankByAverageR = "averageR"
+	RankByFees     = "fees"
+	RankByTrades   = "trades"
+)
+
+// RankKeys lists the keys a leaderboard can be sorted by
+var RankKeys = []string{RankByPnL, RankByNetPnL, RankByWinRate, RankByAverageR, RankByFees, RankByTrades}
+
+// SymbolStats represents the closed trades of one symbol in a period
+type SymbolStats struct {
+	Rank       int     `json:"rank"`
+	Symbol     string  `json:"symbol"`
+	Trades     int     `json:"trades"`
+	Wins       int     `json:"wins"`
+	Losses     int     `json:"losses"`
+	WinRate    float64 `json:"winRate"` // Percent of trades
+	PnL        float64 `json:"pnl"`
+	Fees       float64 `json:"fees"`
+	NetPnL     float64 `json:"netPnl"` // PnL minus fees
+	AveragePnL float64 `json:"averagePnl"`
+	AverageR   float64 `json:"averageR"` // Mean PnL in multiples of the risk at entry
+	RTrades    int     `json:"rTrades"`  // Trades with a stop loss, which AverageR covers
+	BestTrade  float64 `json:"bestTrade"`
+	WorstTrade float64 `json:"worstTrade"`
+	Volume     float64 `json:"volume"` // Sum of trade sizes (margin, USDT)
+
+	rSum float64
+}
+
+// SymbolLeaderboard ranks the symbols traded in a period
+type SymbolLeaderboard struct {
+	Period    string        `json:"period"`
+	SortBy    string        `json:"sortBy"`
+	Order     string        `json:"order"` // asc or desc
+	MinTrades int           `json:"minTrades"`
+	Trades    int           `json:"trades"`   // Closed trades in the period, of every symbol
+	Excluded  []string      `json:"excluded"` // Symbols with fewer than MinTrades trades
+	Symbols   []SymbolStats `json:"symbols"`
+}
+
+// BuildSymbolLeaderboard ranks the symbols of the trades closed at or after
+// startTime by sortBy, best first unless ascending. Symbols with fewer than
+// minTrades closed trades are listed as excluded instead.
+func BuildSymbolLeaderboard(trades []*models.Trade, startTime int64, minTrades int, sortBy string, ascending bool) *SymbolLeaderboard {
+	board := &SymbolLeaderboard{
+		SortBy:    sortBy,
+		Order:     "desc",
+		MinTrades: minTrades,
+		Excluded:  []string{},
+		Symbols:   []SymbolStats{},
+	}
+	if ascending {
+		board.Order = "asc"
+	}
+
+	bySymbol := make(map[string]*SymbolStats)
+	for _, trade := range trades {
+		if trade.Status != "CLOSED" || trade.ClosedAt < startTime {
+			continue
+		}
+		stats, ok := bySymbol[trade.Symbol]
+		if !ok {
+			stats = &SymbolStats{Symbol: trade.Symbol, BestTrade: trade.PnL, WorstTrade: trade.PnL}
+			bySymbol[trade.Symbol] = stats
+		}
+		board.Trades++
+		stats.add(trade)
+	}
+
+	for symbol, stats := range bySymbol {
+		if stats.Trades < minTrades {
+			board.Excluded = append(board.Excluded, symbol)
+			continue
+		}
+		stats.finish()
+		board.Symbols = append(board.Symbols, *stats)
+	}
+	sort.Strings(board.Excluded)
+
+	sort.SliceStable(board.Symbols, func(i, j int) bool {
+		a, b := rankValue(&board.Symbols[i], sortBy), rankValue(&board.Symbols[j], sortBy)
+		if a == b {
+			return board.Symbols[i].Symbol < board.Symbols[j].Symbol
+		}
+		if ascending {
+			return a < b
+		}
+		return a > b
+	})
+	for i := range board.Symbols {
+		board.Symbols[i].Rank = i + 1
+	}
+	return board
+}
+
+// InitialRisk returns what a trade stood to lose at its stop loss when it
+// was opened, in USDT (0 without a stop loss)
+func InitialRisk(trade *models.Trade) float64 {
+	entry := trade.ExecutedPrice
+	if entry <= 0 {
+		entry = trade.EntryPrice
+	}
+	if entry <= 0 || trade.StopLoss <= 0 || trade.Leverage <= 0 {
+		return 0
+	}
+	return trade.Size * float64(trade.Leverage) * math.Abs(entry-trade.StopLoss) / entry
+}
+
+func (s *SymbolStats) add(trade *models.Trade) {
+	s.Trades++
+	switch {
+	case trade.PnL > 0:
+		s.Wins++
+	case trade.PnL < 0:
+		s.Losses++
+	}
+	s.PnL += trade.PnL
+	s.Fees += trade.Commission
+	s.Volume += trade.Size
+	if trade.PnL > s.BestTrade {
+		s.BestTrade = trade.PnL
+	}
+	if trade.PnL < s.WorstTrade {
+		s.WorstTrade = trade.PnL
+	}
+	if risk := InitialRisk(trade); risk > 0 {
+		s.rSum += trade.PnL / risk
+		s.RTrades++
+	}
+}
+
+func (s *SymbolStats) finish() {
+	s.NetPnL = s.PnL - s.Fees
+	if s.Trades > 0 {
+		s.WinRate = float64(s.Wins) / float64(s.Trades) * 100
+		s.AveragePnL = s.PnL / float64(s.Trades)
+	}
+	if s.RTrades > 0 {
+		s.AverageR = s.rSum / float64(s.RTrades)
+	}
+}
+
+func rankValue(s *SymbolStats, sortBy string) float64 {
+	switch sortBy {
+	case RankByNetPnL:
+		return s.NetPnL
+	case RankByWinRate:
+		return s.WinRate
+	case RankByAverageR:
+		return s.AverageR
+	case RankByFees:
+		return s.Fees
+	case RankByTrades:
+		return float64(s.Trades)
+	default:
+		return s.PnL
+	}
+}
@@ -0,0 +1,130 @@
+package analytics
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// MonteCarloConfig configures a trade-sequence resampling simulation
+type MonteCarloConfig struct {
+	Simulations  int     // Number of simulated sequences
+	Trades       int     // Trades per sequence
+	SizeFraction float64 // Position size as a fraction of equity
+	RuinLevel    float64 // Drawdown from starting equity counted as ruin (0.5 = -50%)
+	Seed         int64
+}
+
+// MonteCarloReport represents the outcome distribution of resampled trade sequences
+type MonteCarloReport struct {
+	SampleSize             int                `json:"sampleSize"` // Historical trades resampled
+	Simulations            int                `json:"simulations"`
+	TradesPerSimulation    int                `json:"tradesPerSimulation"`
+	SizeFraction           float64            `json:"sizeFraction"`
+	ExpectedReturnPerTrade float64            `json:"expectedReturnPerTrade"` // Mean return on position size
+	ProbabilityOfRuin      float64            `json:"probabilityOfRuin"`      // Percent of sequences hitting the ruin level
+	RuinLevel              float64            `json:"ruinLevel"`
+	MaxDrawdown            map[string]float64 `json:"maxDrawdown"`       // Percentiles of peak-to-trough drawdown (%)
+	FinalReturn            map[string]float64 `json:"finalReturn"`       // Percentiles of total return (%)
+	ProbabilityOfLoss      float64            `json:"probabilityOfLoss"` // Percent of sequences ending below start
+}
+
+// MonteCarlo resamples per-trade returns (PnL / position size) with replacement
+// and compounds them at a fixed fraction of equity
+func MonteCarlo(returns []float64, cfg MonteCarloConfig) (*MonteCarloReport, error) {
+	if len(returns) == 0 {
+		return nil, fmt.Errorf("no trade returns to resample")
+	}
+	if cfg.Simulations <= 0 || cfg.Trades <= 0 {
+		return nil, fmt.Errorf("simulations and trades must be greater than 0")
+	}
+	if cfg.SizeFraction <= 0 {
+		return nil, fmt.Errorf("size fraction must be greater than 0")
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	drawdowns := make([]float64, cfg.Simulations)
+	finals := make([]float64, cfg.Simulations)
+	ruined := 0
+	losing := 0
+	ruinEquity := 1 - cfg.RuinLevel
+
+	for s := 0; s < cfg.Simulations; s++ {
+		equity := 1.0
+		peak := 1.0
+		maxDD := 0.0
+		isRuined := false
+
+		for t := 0; t < cfg.Trades; t++ {
+			equity += equity * cfg.SizeFraction * returns[rng.Intn(len(returns))]
+			if equity <= 0 {
+				equity = 0
+			}
+
+			if equity > peak {
+				peak = equity
+			}
+			if dd := (peak - equity) / peak; dd > maxDD {
+				maxDD = dd
+			}
+			if equity <= ruinEquity {
+				isRuined = true
+			}
+			if equity == 0 {
+				break
+			}
+		}
+
+		if isRuined {
+			ruined++
+		}
+		if equity < 1 {
+			losing++
+		}
+		drawdowns[s] = maxDD * 100
+		finals[s] = (equity - 1) * 100
+	}
+
+	sort.Float64s(drawdowns)
+	sort.Float64s(finals)
+
+	return &MonteCarloReport{
+		SampleSize:             len(returns),
+		Simulations:            cfg.Simulations,
+		TradesPerSimulation:    cfg.Trades,
+		SizeFraction:           cfg.SizeFraction,
+		ExpectedReturnPerTrade: Mean(returns),
+		ProbabilityOfRuin:      float64(ruined) / float64(cfg.Simulations) * 100,
+		RuinLevel:              cfg.RuinLevel,
+		MaxDrawdown: map[string]float64{
+			"p50": Percentile(drawdowns, 50),
+			"p90": Percentile(drawdowns, 90),
+			"p95": Percentile(drawdowns, 95),
+			"p99": Percentile(drawdowns, 99),
+		},
+		FinalReturn: map[string]float64{
+			"p5":  Percentile(finals, 5),
+			"p25": Percentile(finals, 25),
+			"p50": Percentile(finals, 50),
+			"p75": Percentile(finals, 75),
+			"p95": Percentile(finals, 95),
+		},
+		ProbabilityOfLoss: float64(losing) / float64(cfg.Simulations) * 100,
+	}, nil
+}
+
+// Percentile returns the p-th percentile of sorted values (nearest rank)
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p/100*float64(len(sorted)-1) + 0.5)
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
@@ -0,0 +1,61 @@
+package analytics
+
+import (
+	"crypto-trading-api/internal/models"
+	"sort"
+	"time"
+)
+
+// Performance represents the measures performance alerts watch, over one
+// user's closed trades
+type Performance struct {
+	Drawdown     float64 `json:"drawdown"`     // Net PnL below its peak within the window (USDT)
+	Peak         float64 `json:"peak"`         // Highest net PnL within the window (USDT)
+	LosingStreak int     `json:"losingStreak"` // Latest closed trades that lost, in a row
+	DailyLoss    float64 `json:"dailyLoss"`    // Net loss of the trades closed today (USDT, 0 on a winning day)
+	Day          string  `json:"day"`          // Today in loc, YYYY-MM-DD
+}
+
+// MeasurePerformance measures the current drawdown of the net PnL (after
+// fees) of the trades closed within window before now, the losing streak
+// and the net loss of the trades closed on now's day in loc
+func MeasurePerformance(trades []*models.Trade, now time.Time, window time.Duration, loc *time.Location) *Performance {
+	closed := make([]*models.Trade, 0, len(trades))
+	for _, trade := range trades {
+		if trade.Status == "CLOSED" && trade.ClosedAt > 0 {
+			closed = append(closed, trade)
+		}
+	}
+	sort.Slice(closed, func(i, j int) bool {
+		return closed[i].ClosedAt < closed[j].ClosedAt
+	})
+
+	local := now.In(loc)
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc).Unix()
+	windowStart := now.Add(-window).Unix()
+
+	performance := &Performance{Day: local.Format("2006-01-02")}
+	cumulative, dayPnL := 0.0, 0.0
+	for _, trade := range closed {
+		net := trade.PnL - trade.Commission
+		if trade.ClosedAt >= windowStart {
+			cumulative += net
+			if cumulative > performance.Peak {
+				performance.Peak = cumulative
+			}
+		}
+		if trade.ClosedAt >= dayStart {
+			dayPnL += net
+		}
+		if trade.PnL < 0 {
+			performance.LosingStreak++
+		} else {
+			performance.LosingStreak = 0
+		}
+	}
+	performance.Drawdown = performance.Peak - cumulative
+	if dayPnL < 0 {
+		performance.DailyLoss = -dayPnL
+	}
+	return performance
+}
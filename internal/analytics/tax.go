@@ -0,0 +1,162 @@
+package analytics
+
+import (
+	"crypto-trading-api/internal/models"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TaxRow represents the totals of one asset in one month
+type TaxRow struct {
+	Month       string  `json:"month"` // YYYY-MM (UTC)
+	Asset       string  `json:"asset"`
+	RealizedPnL float64 `json:"realizedPnl"`
+	Funding     float64 `json:"funding"`     // Signed: positive = received
+	Fees        float64 `json:"fees"`        // Commissions paid
+	Net         float64 `json:"net"`         // RealizedPnL + Funding - Fees
+	Trades      int     `json:"trades"`      // Trades closed in the month (Firebase)
+	RecordedPnL float64 `json:"recordedPnl"` // Sum of trade PnL stored in Firebase
+}
+
+// TaxReport represents a per-year accounting statement
+type TaxReport struct {
+	Year    int               `json:"year"`
+	UserID  string            `json:"userId,omitempty"`
+	Rows    []TaxRow          `json:"rows"`
+	Totals  map[string]TaxRow `json:"totals"` // Year totals by asset
+	Created int64             `json:"createdAt"`
+}
+
+// BuildTaxReport groups income history and closed trades by month and asset
+func BuildTaxReport(year int, incomes []*models.IncomeRecord, trades []*models.Trade) *TaxReport {
+	rows := make(map[string]*TaxRow)
+
+	row := func(t time.Time, asset string) *TaxRow {
+		month := t.UTC().Format("2006-01")
+		key := month + "|" + asset
+		if rows[key] == nil {
+			rows[key] = &TaxRow{Month: month, Asset: asset}
+		}
+		return rows[key]
+	}
+
+	for _, income := range incomes {
+		t := time.UnixMilli(income.Time)
+		if t.UTC().Year() != year {
+			continue
+		}
+
+		r := row(t, income.Asset)
+		switch income.IncomeType {
+		case "REALIZED_PNL":
+			r.RealizedPnL += income.Income
+		case "FUNDING_FEE":
+			r.Funding += income.Income
+		case "COMMISSION":
+			r.Fees -= income.Income // Binance reports commissions as negative income
+		}
+	}
+
+	for _, trade := range trades {
+		if trade.Status != "CLOSED" || trade.ClosedAt == 0 {
+			continue
+		}
+		t := time.Unix(trade.ClosedAt, 0)
+		if t.UTC().Year() != year {
+			continue
+		}
+
+		r := row(t, quoteAsset(trade.Symbol))
+		r.Trades++
+		r.RecordedPnL += trade.PnL
+	}
+
+	report := &TaxReport{
+		Year:    year,
+		Rows:    make([]TaxRow, 0, len(rows)),
+		Totals:  make(map[string]TaxRow),
+		Created: time.Now().Unix(),
+	}
+
+	for _, r := range rows {
+		r.Net = r.RealizedPnL + r.Funding - r.Fees
+		report.Rows = append(report.Rows, *r)
+
+		total := report.Totals[r.Asset]
+		total.Asset = r.Asset
+		total.RealizedPnL += r.RealizedPnL
+		total.Funding += r.Funding
+		total.Fees += r.Fees
+		total.Net += r.Net
+		total.Trades += r.Trades
+		total.RecordedPnL += r.RecordedPnL
+		report.Totals[r.Asset] = total
+	}
+
+	sort.Slice(report.Rows, func(i, j int) bool {
+		if report.Rows[i].Month != report.Rows[j].Month {
+			return report.Rows[i].Month < report.Rows[j].Month
+		}
+		return report.Rows[i].Asset < report.Rows[j].Asset
+	})
+
+	return report
+}
+
+// WriteCSV writes the report rows followed by per-asset year totals
+func (r *TaxReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"month", "asset", "realized_pnl", "funding", "fees", "net", "trades", "recorded_pnl"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range r.Rows {
+		if err := writer.Write(taxRecord(row.Month, row)); err != nil {
+			return err
+		}
+	}
+
+	assets := make([]string, 0, len(r.Totals))
+	for asset := range r.Totals {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+
+	for _, asset := range assets {
+		if err := writer.Write(taxRecord(fmt.Sprintf("%d-TOTAL", r.Year), r.Totals[asset])); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func taxRecord(label string, row TaxRow) []string {
+	return []string{
+		label,
+		row.Asset,
+		fmt.Sprintf("%.8f", row.RealizedPnL),
+		fmt.Sprintf("%.8f", row.Funding),
+		fmt.Sprintf("%.8f", row.Fees),
+		fmt.Sprintf("%.8f", row.Net),
+		fmt.Sprintf("%d", row.Trades),
+		fmt.Sprintf("%.8f", row.RecordedPnL),
+	}
+}
+
+// quoteAsset guesses the margin asset of a futures symbol
+func quoteAsset(symbol string) string {
+	for _, quote := range []string{"USDT", "USDC", "BUSD", "FDUSD"} {
+		if strings.HasSuffix(symbol, quote) {
+			return quote
+		}
+	}
+	return "USDT"
+}
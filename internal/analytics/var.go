@@ -0,0 +1,180 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Exposure represents a signed position notional (negative = short)
+type Exposure struct {
+	Symbol   string
+	Notional float64
+}
+
+// PositionVaR represents the standalone Value-at-Risk of one position
+type PositionVaR struct {
+	Symbol     string  `json:"symbol"`
+	Notional   float64 `json:"notional"`
+	Volatility float64 `json:"volatility"` // Std dev of per-period log returns
+	VaR        float64 `json:"var"`        // Loss (USDT) not exceeded at the confidence level
+	Share      float64 `json:"share"`      // Share of total gross exposure (%)
+}
+
+// VaRReport represents a parametric Value-at-Risk and correlation report
+type VaRReport struct {
+	Confidence             float64                       `json:"confidence"`
+	HorizonPeriods         int                           `json:"horizonPeriods"`
+	GrossExposure          float64                       `json:"grossExposure"`
+	PortfolioVaR           float64                       `json:"portfolioVaR"`
+	UndiversifiedVaR       float64                       `json:"undiversifiedVaR"` // Sum of standalone VaRs
+	DiversificationBenefit float64                       `json:"diversificationBenefit"`
+	LargestShare           float64                       `json:"largestShare"` // Largest single position share (%)
+	Positions              []PositionVaR                 `json:"positions"`
+	Correlations           map[string]map[string]float64 `json:"correlations"`
+}
+
+// LogReturns converts a close price series into log returns
+func LogReturns(closes []float64) []float64 {
+	returns := []float64{}
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1] <= 0 || closes[i] <= 0 {
+			continue
+		}
+		returns = append(returns, math.Log(closes[i]/closes[i-1]))
+	}
+	return returns
+}
+
+// Mean returns the arithmetic mean
+func Mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+// StdDev returns the sample standard deviation
+func StdDev(xs []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	mean := Mean(xs)
+	sum := 0.0
+	for _, x := range xs {
+		sum += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(sum / float64(len(xs)-1))
+}
+
+// Correlation returns the Pearson correlation of the overlapping tails of two series
+func Correlation(a, b []float64) float64 {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	if n < 2 {
+		return 0
+	}
+	a, b = a[len(a)-n:], b[len(b)-n:]
+
+	meanA, meanB := Mean(a), Mean(b)
+	var cov, varA, varB float64
+	for i := 0; i < n; i++ {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
+
+// ZScore returns the one-sided standard normal quantile for a confidence level
+func ZScore(confidence float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*confidence-1)
+}
+
+// ComputeVaR builds a variance-covariance VaR report from position exposures
+// and each symbol's close price history
+func ComputeVaR(exposures []Exposure, closes map[string][]float64, confidence float64, horizon int) (*VaRReport, error) {
+	if confidence <= 0.5 || confidence >= 1 {
+		return nil, fmt.Errorf("confidence must be between 0.5 and 1")
+	}
+	if horizon <= 0 {
+		horizon = 1
+	}
+
+	z := ZScore(confidence)
+	scale := z * math.Sqrt(float64(horizon))
+
+	report := &VaRReport{
+		Confidence:     confidence,
+		HorizonPeriods: horizon,
+		Positions:      []PositionVaR{},
+		Correlations:   make(map[string]map[string]float64),
+	}
+
+	returns := make(map[string][]float64)
+	vols := make(map[string]float64)
+	for _, e := range exposures {
+		r := LogReturns(closes[e.Symbol])
+		if len(r) < 2 {
+			return nil, fmt.Errorf("not enough price history for %s", e.Symbol)
+		}
+		returns[e.Symbol] = r
+		vols[e.Symbol] = StdDev(r)
+		report.GrossExposure += math.Abs(e.Notional)
+	}
+
+	// Standalone VaR per position
+	for _, e := range exposures {
+		v := math.Abs(e.Notional) * vols[e.Symbol] * scale
+		share := 0.0
+		if report.GrossExposure > 0 {
+			share = math.Abs(e.Notional) / report.GrossExposure * 100
+		}
+		if share > report.LargestShare {
+			report.LargestShare = share
+		}
+		report.UndiversifiedVaR += v
+		report.Positions = append(report.Positions, PositionVaR{
+			Symbol:     e.Symbol,
+			Notional:   e.Notional,
+			Volatility: vols[e.Symbol],
+			VaR:        v,
+			Share:      share,
+		})
+	}
+
+	sort.Slice(report.Positions, func(i, j int) bool {
+		return report.Positions[i].VaR > report.Positions[j].VaR
+	})
+
+	// Portfolio variance from signed exposures and the correlation matrix
+	variance := 0.0
+	for _, a := range exposures {
+		report.Correlations[a.Symbol] = make(map[string]float64)
+		for _, b := range exposures {
+			corr := 1.0
+			if a.Symbol != b.Symbol {
+				corr = Correlation(returns[a.Symbol], returns[b.Symbol])
+			}
+			report.Correlations[a.Symbol][b.Symbol] = corr
+			variance += a.Notional * b.Notional * vols[a.Symbol] * vols[b.Symbol] * corr
+		}
+	}
+
+	if variance > 0 {
+		report.PortfolioVaR = math.Sqrt(variance) * scale
+	}
+	report.DiversificationBenefit = report.UndiversifiedVaR - report.PortfolioVaR
+
+	return report, nil
+}
@@ -0,0 +1,128 @@
+package analytics
+
+import (
+	"crypto-trading-api/internal/models"
+	"math"
+	"sort"
+	"time"
+)
+
+// Volatility screener sort keys
+const (
+	VolatilitySortRealized = "volatility"
+	VolatilitySortATR      = "atr"
+	VolatilitySortRange    = "range"
+)
+
+// VolatilitySortKeys lists the keys the screener can be sorted by
+var VolatilitySortKeys = []string{VolatilitySortRealized, VolatilitySortATR, VolatilitySortRange}
+
+// VolatilityStats represents how much one symbol moved over its candles
+type VolatilityStats struct {
+	Rank               int     `json:"rank"`
+	Symbol             string  `json:"symbol"`
+	Candles            int     `json:"candles"`
+	LastPrice          float64 `json:"lastPrice"`
+	RealizedVolatility float64 `json:"realizedVolatility"` // Std dev of log returns, annualized (%)
+	ATR                float64 `json:"atr"`                // Wilder's average true range, in price
+	ATRPercent         float64 `json:"atrPercent"`         // ATR / last price (%): a typical candle's move
+	High24h            float64 `json:"high24h"`
+	Low24h             float64 `json:"low24h"`
+	Range24hPercent    float64 `json:"range24hPercent"` // (high - low) / low over the last 24h (%)
+	QuoteVolume        float64 `json:"quoteVolume,omitempty"`
+	Source             string  `json:"source"` // stream (in-memory candles) or rest
+}
+
+// VolatilityScreen ranks symbols by how much they move
+type VolatilityScreen struct {
+	Interval  string             `json:"interval"`
+	Lookback  int                `json:"lookback"` // Candles per symbol
+	ATRPeriod int                `json:"atrPeriod"`
+	SortBy    string             `json:"sortBy"`
+	Order     string             `json:"order"` // asc or desc
+	Symbols   []*VolatilityStats `json:"symbols"`
+	Skipped   []string           `json:"skipped"` // Symbols without enough candles or whose klines failed to load
+}
+
+// MeasureVolatility measures the realized volatility, ATR over atrPeriod
+// candles and 24h range of candles of the given interval (oldest first).
+// Returns are taken between closed candles only; the forming one counts
+// towards the ATR and range. Nil without at least atrPeriod+1 candles.
+func MeasureVolatility(symbol string, candles []models.Candle, interval time.Duration, atrPeriod int) *VolatilityStats {
+	if atrPeriod < 1 || len(candles) < atrPeriod+1 {
+		return nil
+	}
+	last := candles[len(candles)-1]
+	if last.Close <= 0 {
+		return nil
+	}
+
+	stats := &VolatilityStats{Symbol: symbol, Candles: len(candles), LastPrice: last.Close}
+
+	closes := make([]float64, 0, len(candles))
+	for _, candle := range candles {
+		if candle.Final {
+			closes = append(closes, candle.Close)
+		}
+	}
+	periodsPerYear := float64(365*24*time.Hour) / float64(interval)
+	stats.RealizedVolatility = StdDev(LogReturns(closes)) * math.Sqrt(periodsPerYear) * 100
+
+	// Wilder's ATR: seeded with the mean true range of the first atrPeriod
+	// candles, then smoothed
+	atr := 0.0
+	for i := 1; i < len(candles); i++ {
+		prevClose := candles[i-1].Close
+		tr := math.Max(candles[i].High-candles[i].Low, math.Max(math.Abs(candles[i].High-prevClose), math.Abs(candles[i].Low-prevClose)))
+		if i <= atrPeriod {
+			atr += tr / float64(atrPeriod)
+		} else {
+			atr = (atr*float64(atrPeriod-1) + tr) / float64(atrPeriod)
+		}
+	}
+	stats.ATR = atr
+	stats.ATRPercent = atr / last.Close * 100
+
+	since := last.CloseTime - (24 * time.Hour).Milliseconds()
+	for i := len(candles) - 1; i >= 0 && candles[i].OpenTime > since; i-- {
+		if stats.High24h == 0 || candles[i].High > stats.High24h {
+			stats.High24h = candles[i].High
+		}
+		if stats.Low24h == 0 || candles[i].Low < stats.Low24h {
+			stats.Low24h = candles[i].Low
+		}
+	}
+	if stats.Low24h > 0 {
+		stats.Range24hPercent = (stats.High24h - stats.Low24h) / stats.Low24h * 100
+	}
+	return stats
+}
+
+// RankVolatility sorts stats by sortBy, most volatile first unless
+// ascending, and numbers them
+func RankVolatility(stats []*VolatilityStats, sortBy string, ascending bool) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		x, y := volatilityValue(stats[i], sortBy), volatilityValue(stats[j], sortBy)
+		if x == y {
+			return stats[i].Symbol < stats[j].Symbol
+		}
+		if ascending {
+			return x < y
+		}
+		return x > y
+	})
+	for i := range stats {
+		stats[i].Rank = i + 1
+	}
+}
+
+func volatilityValue(s *VolatilityStats, sortBy string) float64 {
+	switch sortBy {
+	case VolatilitySortATR:
+		return s.ATRPercent
+	case VolatilitySortRange:
+		return s.Range24hPercent
+	default:
+		return s.RealizedVolatility
+	}
+}
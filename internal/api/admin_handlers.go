@@ -0,0 +1,342 @@
+package api
+
+import (
+	"context"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/notifications"
+	"crypto-trading-api/internal/policy"
+	"crypto-trading-api/internal/storage"
+	"crypto-trading-api/internal/validation"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSymbolPolicyHandler - Get the symbol allow/block lists
+// @Summary      Get symbol policy
+// @Description  Retrieve the symbols currently allowed or blocked for trading
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.TradeResponse{data=models.SymbolPolicyConfig}  "Symbol policy retrieved"
+// @Failure      401  {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Router       /api/admin/symbols [get]
+func GetSymbolPolicyHandler(symbols *policy.SymbolPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Symbol policy retrieved successfully",
+			Data:      symbols.Config(),
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// UpdateSymbolPolicyHandler - Replace the symbol allow/block lists
+// @Summary      Update symbol policy
+// @Description  Replace the trading allowlist and blocklist. An empty allowlist allows every symbol that is not blocked. Changes are persisted to Firebase and take effect immediately.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        policy  body      models.SymbolPolicyConfig  true  "Allowlist and blocklist"
+// @Success      200     {object}  models.TradeResponse{data=models.SymbolPolicyConfig}  "Symbol policy updated"
+// @Failure      400     {object}  models.TradeResponse  "Invalid request"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      500     {object}  models.TradeResponse  "Failed to persist symbol policy"
+// @Router       /api/admin/symbols [put]
+func UpdateSymbolPolicyHandler(symbols *policy.SymbolPolicy, fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.SymbolPolicyConfig
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		symbols.Update(req)
+		cfg := symbols.Config()
+
+		if err := fb.SaveSymbolPolicy(c.Request.Context(), &cfg); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Symbol policy applied but failed to persist",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Symbol policy updated successfully",
+			Data:      cfg,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// GetTradingPauseHandler - Get the trading pause state
+// @Summary      Get trading pause
+// @Description  Report whether new trade intake is paused, why, by whom and since when
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.TradeResponse{data=models.TradingPauseStatus}  "Pause state"
+// @Failure      401  {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403  {object}  models.TradeResponse  "Admin access required"
+// @Router       /api/admin/pause [get]
+func GetTradingPauseHandler(pause *policy.TradingPause) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Trading pause state retrieved",
+			Data:      pause.Status(),
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// PauseTradingHandler - Pause new trade intake
+// @Summary      Pause trading
+// @Description  Put the server into maintenance mode: new trades (API, TradingView, alerts, queued retries, arbitrage) are rejected with 503 and the reason until resumed. Read-only endpoints, closing positions and cancelling orders keep working.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      models.TradingPauseRequest  false  "Reason shown to rejected callers"
+// @Success      200      {object}  models.TradeResponse{data=models.TradingPauseStatus}  "Trading paused"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403      {object}  models.TradeResponse  "Admin access required"
+// @Router       /api/admin/pause [post]
+func PauseTradingHandler(pause *policy.TradingPause, notifier *notifications.Notifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.TradingPauseRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				err = validation.FromBinding(err)
+				c.JSON(http.StatusBadRequest, models.TradeResponse{
+					Success:   false,
+					Message:   "Invalid request",
+					Error:     err.Error(),
+					Details:   validation.Details(err),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+		}
+		if req.Reason == "" {
+			req.Reason = "paused by administrator"
+		}
+
+		pause.Pause(req.Reason, auditActor(c))
+		notifier.Publish(notifications.KillSwitch(true, req.Reason))
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Trading paused",
+			Data:      pause.Status(),
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// ResumeTradingHandler - Resume trade intake
+// @Summary      Resume trading
+// @Description  Leave maintenance mode so new trades are accepted again. Queued trades are retried on their next attempt.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.TradeResponse{data=models.TradingPauseStatus}  "Trading resumed"
+// @Failure      401  {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403  {object}  models.TradeResponse  "Admin access required"
+// @Router       /api/admin/resume [post]
+func ResumeTradingHandler(pause *policy.TradingPause, notifier *notifications.Notifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !pause.Status().Paused {
+			c.JSON(http.StatusOK, models.TradeResponse{
+				Success:   true,
+				Message:   "Trading is not paused",
+				Data:      pause.Status(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		pause.Resume()
+		notifier.Publish(notifications.KillSwitch(false, "resumed by "+auditActor(c)))
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Trading resumed",
+			Data:      pause.Status(),
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// GetOrderReconcileHandler - Get the latest orphaned order reconciliation
+// @Summary      Get reconciliation report
+// @Description  Retrieve the latest comparison of Binance open orders/positions with Firebase trades: trades marked CLOSED, orphaned SL/TP orders cancelled and positions opened outside the API
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.TradeResponse{data=models.OrderReconcileReport}  "Latest report"
+// @Failure      401  {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403  {object}  models.TradeResponse  "Admin access required"
+// @Failure      404  {object}  models.TradeResponse  "No reconciliation has run yet"
+// @Router       /api/admin/reconcile [get]
+func GetOrderReconcileHandler(reconciler *binance.OrderReconciler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := reconciler.LastReport()
+		if report == nil {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "No reconciliation has run yet",
+				Error:     "enable ORDER_RECONCILE_ENABLED or run POST /api/admin/reconcile",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Reconciliation report retrieved",
+			Data:      report,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// RunOrderReconcileHandler - Reconcile orders and positions now
+// @Summary      Run reconciliation
+// @Description  Compare Binance open orders/positions with Firebase trades now: cancel SL/TP orders whose positions are gone, mark trades CLOSED when the exchange shows no position and report unmanaged positions
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.TradeResponse{data=models.OrderReconcileReport}  "Reconciliation finished"
+// @Failure      401  {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403  {object}  models.TradeResponse  "Admin access required"
+// @Failure      500  {object}  models.TradeResponse{data=models.OrderReconcileReport}  "Reconciliation failed"
+// @Router       /api/admin/reconcile [post]
+func RunOrderReconcileHandler(reconciler *binance.OrderReconciler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := reconciler.Reconcile(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Reconciliation failed",
+				Data:      report,
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Reconciliation finished",
+			Data:      report,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// AdoptPositionsHandler - Adopt positions opened outside the API
+// @Summary      Adopt unmanaged positions
+// @Description  Create FILLED trades (adopted=true) for the operator account's positions no open trade or funding arbitrage pair accounts for, so SL/TP fills, the margin guard and reports cover them. SL/TP orders already on the exchange are linked; with protect, missing ones are placed at the owner's defaultStopLoss/defaultTakeProfit (user settings) from the entry price. An empty body adopts every unmanaged position.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      models.AdoptRequest  false  "Positions to adopt and their owner"
+// @Success      200      {object}  models.TradeResponse{data=[]models.AdoptedPosition}  "Positions adopted (see error of each)"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403      {object}  models.TradeResponse  "Admin access required"
+// @Failure      500      {object}  models.TradeResponse  "Failed to read positions, orders or trades"
+// @Router       /api/admin/positions/adopt [post]
+func AdoptPositionsHandler(reconciler *binance.OrderReconciler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.AdoptRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				err = validation.FromBinding(err)
+				c.JSON(http.StatusBadRequest, models.TradeResponse{
+					Success:   false,
+					Message:   "Invalid request",
+					Error:     err.Error(),
+					Details:   validation.Details(err),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+		}
+
+		adopted, err := reconciler.Adopt(c.Request.Context(), req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to adopt positions",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("%d positions adopted", len(adopted)),
+			Data:      adopted,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// ConfigReloader re-reads the configuration and applies the settings that
+// can change without a restart
+type ConfigReloader func(ctx context.Context) (*models.ConfigReloadResult, error)
+
+// ReloadConfigHandler - Reload non-credential settings
+// @Summary      Reload configuration
+// @Description  Re-read CONFIG_FILE and apply the settings that can change at runtime (rate limits, symbol allow/block lists, position limit, margin guard thresholds, log level) without restarting or dropping WebSocket streams. Same as sending SIGHUP. Other changed settings are listed in restartRequired. An invalid file is rejected and the running configuration is kept.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.TradeResponse{data=models.ConfigReloadResult}  "Configuration reloaded"
+// @Failure      400  {object}  models.TradeResponse  "Invalid configuration, nothing applied"
+// @Failure      401  {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403  {object}  models.TradeResponse  "Admin access required"
+// @Router       /api/admin/config/reload [post]
+func ReloadConfigHandler(reload ConfigReloader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := reload(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid configuration, nothing applied",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("Configuration reloaded (%d settings applied)", len(result.Applied)),
+			Data:      result,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -1,13 +1,24 @@
 package api
 
 import (
+	"context"
 	"crypto-trading-api/internal/binance"
-	"crypto-trading-api/internal/firebase"
+	"crypto-trading-api/internal/cluster"
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/logging"
 	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/storage"
+	"crypto-trading-api/internal/tradehistory"
+	"crypto-trading-api/internal/validation"
+	"errors"
+	"math"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/adshao/go-binance/v2/futures"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,7 +26,7 @@ var serverStartTime = time.Now().Unix()
 
 // SystemStatusHandler - Get system status
 // @Summary      Get system status
-// @Description  Retrieve comprehensive system status including server, Binance connection, and Firebase stats
+// @Description  Retrieve comprehensive system status including server, Binance connection and circuit breakers, Firebase stats and this instance's part in leader election
 // @Tags         System
 // @Produce      json
 // @Security     ApiKeyAuth
@@ -23,7 +34,7 @@ var serverStartTime = time.Now().Unix()
 // @Failure      401  {object}  models.TradeResponse  "Unauthorized - Invalid API key"
 // @Failure      500  {object}  models.TradeResponse  "Internal server error"
 // @Router       /api/status [get]
-func SystemStatusHandler(fb *firebase.Client, bn *binance.Client) gin.HandlerFunc {
+func SystemStatusHandler(fb storage.TradeStore, bn *binance.Client, elector *cluster.Elector) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 
@@ -40,19 +51,20 @@ func SystemStatusHandler(fb *firebase.Client, bn *binance.Client) gin.HandlerFun
 		}
 
 		// Get Binance server time (to check connection)
-		serverTime, err := bn.GetServerTime()
+		serverTime, err := bn.GetServerTime(ctx)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
 				Message:   "Failed to connect to Binance",
 				Error:     err.Error(),
+				Data:      gin.H{"binance": gin.H{"circuitBreakers": binance.CircuitStates()}},
 				Timestamp: time.Now().Unix(),
 			})
 			return
 		}
 
 		// Get account status
-		account, err := bn.GetAccountInfo()
+		account, err := bn.GetAccountInfo(ctx)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
@@ -71,16 +83,23 @@ func SystemStatusHandler(fb *firebase.Client, bn *binance.Client) gin.HandlerFun
 				"version":   "1.1.0",
 			},
 			"binance": gin.H{
-				"status":      "connected",
-				"serverTime":  serverTime,
-				"canTrade":    account.CanTrade,
-				"canDeposit":  account.CanDeposit,
-				"canWithdraw": account.CanWithdraw,
+				"status":           "connected",
+				"serverTime":       serverTime,
+				"canTrade":         account.CanTrade,
+				"canDeposit":       account.CanDeposit,
+				"canWithdraw":      account.CanWithdraw,
+				"circuitBreakers":  binance.CircuitStates(),
+				"symbolCache":      binance.SymbolCacheStats(),
+				"orderSubmissions": binance.SubmitPoolStats(),
 			},
 			"firebase": gin.H{
 				"status":       "connected",
 				"activeTrades": len(activeTrades),
 			},
+			"cluster": elector.Status(ctx),
+		}
+		if writeBehind, ok := fb.(*storage.WriteBehind); ok {
+			status["firebase"].(gin.H)["writeBehind"] = writeBehind.Backlog()
 		}
 
 		c.JSON(http.StatusOK, models.TradeResponse{
@@ -98,13 +117,27 @@ func SystemStatusHandler(fb *firebase.Client, bn *binance.Client) gin.HandlerFun
 // @Tags         Account
 // @Produce      json
 // @Security     ApiKeyAuth
+// @Param        userId  query     string  false  "User whose own Binance account to query (default: operator account)"
+// @Param        account query     string  false  "Operator account to query: main or a BINANCE_ACCOUNTS name (default: as userId selects)"
 // @Success      200  {object}  models.TradeResponse{data=object}  "Account balance retrieved successfully"
+// @Failure      400  {object}  models.TradeResponse  "Unknown account"
 // @Failure      401  {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403  {object}  models.TradeResponse  "No Binance account for user"
 // @Failure      500  {object}  models.TradeResponse  "Failed to get account balance"
 // @Router       /api/balance [get]
-func AccountBalanceHandler(bn *binance.Client) gin.HandlerFunc {
+func AccountBalanceHandler(clients *binance.ClientPool) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		account, err := bn.GetAccountInfo()
+		userID, account := c.Query("userId"), c.Query("account")
+		if !claimOwnership(c, &userID) || !requireAccountAccess(c, account) {
+			return
+		}
+
+		bn, ok := userClient(c, clients, userID, account)
+		if !ok {
+			return
+		}
+
+		info, err := bn.GetAccountInfo(c.Request.Context())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
@@ -116,7 +149,7 @@ func AccountBalanceHandler(bn *binance.Client) gin.HandlerFunc {
 		}
 
 		// Calculate total balance
-		balance := bn.CalculateBalance(account)
+		balance := bn.CalculateBalance(c.Request.Context(), info)
 
 		c.JSON(http.StatusOK, models.TradeResponse{
 			Success:   true,
@@ -127,19 +160,118 @@ func AccountBalanceHandler(bn *binance.Client) gin.HandlerFunc {
 	}
 }
 
+// CommissionRatesHandler - Get the account's commission rates
+// @Summary      Get commission rates
+// @Description  Retrieve the account's maker/taker commission rates for a symbol (from its VIP tier) and whether fees get the BNB discount: the account pays fees in BNB and has BNB in its futures wallet
+// @Tags         Account
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        symbol   query     string  true   "Trading symbol (e.g., BTCUSDT)"
+// @Param        userId   query     string  false  "User whose own Binance account to query"
+// @Param        account  query     string  false  "Operator account to query (main or a BINANCE_ACCOUNTS name)"
+// @Success      200      {object}  models.TradeResponse{data=binance.CommissionRates}  "Commission rates retrieved successfully"
+// @Failure      400      {object}  models.TradeResponse  "Missing symbol or unknown account"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403      {object}  models.TradeResponse  "No Binance account for user"
+// @Failure      500      {object}  models.TradeResponse  "Failed to get commission rates"
+// @Router       /api/account/commission [get]
+func CommissionRatesHandler(clients *binance.ClientPool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		symbol := strings.ToUpper(c.Query("symbol"))
+		if symbol == "" {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     "symbol is required",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		userID, account := c.Query("userId"), c.Query("account")
+		if !claimOwnership(c, &userID) || !requireAccountAccess(c, account) {
+			return
+		}
+
+		bn, ok := userClient(c, clients, userID, account)
+		if !ok {
+			return
+		}
+
+		rates, err := bn.GetCommissionRates(c.Request.Context(), symbol)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get commission rates",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Commission rates retrieved successfully",
+			Data:      rates,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
 // OpenPositionsHandler - Get open positions with PnL
 // @Summary      Get open positions
-// @Description  Retrieve all open futures positions with profit/loss information
+// @Description  Retrieve all open futures positions with profit/loss information, ordered by symbol then side (Binance does not report when a position was opened). With limit, pass nextCursor from a page as cursor to get the next one; the totals always cover every position. fields limits each position to the listed fields for slim payloads.
 // @Tags         Positions
 // @Produce      json
 // @Security     ApiKeyAuth
+// @Param        userId  query     string  false  "User whose own Binance account to query (default: operator account)"
+// @Param        account query     string  false  "Operator account to query: main or a BINANCE_ACCOUNTS name (default: as userId selects)"
+// @Param        limit   query     int     false  "Page size, 1-500 (default: all positions)"
+// @Param        cursor  query     string  false  "nextCursor of the previous page"
+// @Param        fields  query     string  false  "Comma-separated position fields to return, e.g. symbol,unrealizedProfit (default: all)"
 // @Success      200  {object}  models.TradeResponse{data=object}  "Open positions retrieved successfully"
+// @Failure      400  {object}  models.TradeResponse  "Unknown account or invalid parameters"
 // @Failure      401  {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403  {object}  models.TradeResponse  "No Binance account for user"
 // @Failure      500  {object}  models.TradeResponse  "Failed to get open positions"
 // @Router       /api/positions [get]
-func OpenPositionsHandler(bn *binance.Client) gin.HandlerFunc {
+func OpenPositionsHandler(clients *binance.ClientPool) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		positions, err := bn.GetOpenPositions()
+		userID, account := c.Query("userId"), c.Query("account")
+		if !claimOwnership(c, &userID) || !requireAccountAccess(c, account) {
+			return
+		}
+
+		var after *positionCursor
+		var limit int
+		var errLimit, errCursor error
+		if c.Query("limit") != "" {
+			limit, errLimit = strconv.Atoi(c.Query("limit"))
+		}
+		if c.Query("cursor") != "" {
+			after, errCursor = parsePositionCursor(c.Query("cursor"))
+		}
+		fields, errFields := parseFields(c, itemFields(positionItem(&binance.PositionInfo{})))
+		if errLimit != nil || errCursor != nil || errFields != nil || (c.Query("limit") != "" && (limit < 1 || limit > 500)) {
+			message := "limit 1-500, cursor a nextCursor value"
+			if errFields != nil {
+				message = errFields.Error()
+			}
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     message,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		bn, ok := userClient(c, clients, userID, account)
+		if !ok {
+			return
+		}
+
+		positions, err := bn.GetOpenPositions(c.Request.Context())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
@@ -149,36 +281,48 @@ func OpenPositionsHandler(bn *binance.Client) gin.HandlerFunc {
 			})
 			return
 		}
+		sort.Slice(positions, func(i, j int) bool {
+			if positions[i].Symbol != positions[j].Symbol {
+				return positions[i].Symbol < positions[j].Symbol
+			}
+			return positions[i].PositionSide < positions[j].PositionSide
+		})
 
-		// Calculate total PNL
+		// Calculate total PNL (of every position, not just the page)
 		totalPnL := 0.0
 		totalPositions := 0
-		positionDetails := []gin.H{}
+		page := []*binance.PositionInfo{}
 
 		for _, pos := range positions {
 			if pos.PositionAmt != 0 {
 				totalPositions++
 				totalPnL += pos.UnrealizedProfit
 
-				positionDetails = append(positionDetails, gin.H{
-					"symbol":           pos.Symbol,
-					"side":             pos.PositionSide,
-					"positionAmt":      pos.PositionAmt,
-					"entryPrice":       pos.EntryPrice,
-					"markPrice":        pos.MarkPrice,
-					"unrealizedProfit": pos.UnrealizedProfit,
-					"leverage":         pos.Leverage,
-					"liquidationPrice": pos.LiquidationPrice,
-					"marginType":       pos.MarginType,
-				})
+				if after == nil || !after.precedes(pos.Symbol, pos.PositionSide) {
+					page = append(page, pos)
+				}
 			}
 		}
 
+		nextCursor := ""
+		if limit > 0 && len(page) > limit {
+			page = page[:limit]
+			last := page[limit-1]
+			nextCursor = positionCursor{Symbol: last.Symbol, Side: last.PositionSide}.String()
+		}
+		positionDetails := []gin.H{}
+		for _, pos := range page {
+			positionDetails = append(positionDetails, fields.pick(positionItem(pos)))
+		}
+
 		data := gin.H{
 			"totalPositions": totalPositions,
 			"totalPnL":       totalPnL,
 			"positions":      positionDetails,
 		}
+		if nextCursor != "" {
+			data["nextCursor"] = nextCursor
+		}
 
 		c.JSON(http.StatusOK, models.TradeResponse{
 			Success:   true,
@@ -189,22 +333,77 @@ func OpenPositionsHandler(bn *binance.Client) gin.HandlerFunc {
 	}
 }
 
+// positionItem lists the fields of an open position
+func positionItem(pos *binance.PositionInfo) gin.H {
+	return gin.H{
+		"symbol":           pos.Symbol,
+		"side":             pos.PositionSide,
+		"positionAmt":      pos.PositionAmt,
+		"entryPrice":       pos.EntryPrice,
+		"markPrice":        pos.MarkPrice,
+		"unrealizedProfit": pos.UnrealizedProfit,
+		"leverage":         pos.Leverage,
+		"liquidationPrice": pos.LiquidationPrice,
+		"marginType":       pos.MarginType,
+	}
+}
+
 // PendingOrdersHandler - Get pending orders
 // @Summary      Get pending orders
-// @Description  Retrieve all pending orders, optionally filtered by symbol
+// @Description  Retrieve all pending orders, optionally filtered by symbol, oldest first by creation time. With limit, pass nextCursor from a page as cursor to get the next one; totalOrders always counts every pending order. fields limits each order to the listed fields for slim payloads.
 // @Tags         Orders
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Param        symbol  query     string  false  "Filter by trading symbol (e.g., BTCUSDT)"
+// @Param        userId  query     string  false  "User whose own Binance account to query (default: operator account)"
+// @Param        account query     string  false  "Operator account to query: main or a BINANCE_ACCOUNTS name (default: as userId selects)"
+// @Param        limit   query     int     false  "Page size, 1-500 (default: all orders)"
+// @Param        cursor  query     string  false  "nextCursor of the previous page"
+// @Param        fields  query     string  false  "Comma-separated order fields to return, e.g. orderId,symbol,stopPrice (default: all)"
 // @Success      200     {object}  models.TradeResponse{data=object}  "Pending orders retrieved successfully"
+// @Failure      400     {object}  models.TradeResponse  "Unknown account or invalid parameters"
 // @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403     {object}  models.TradeResponse  "No Binance account for user"
 // @Failure      500     {object}  models.TradeResponse  "Failed to get pending orders"
 // @Router       /api/orders [get]
-func PendingOrdersHandler(bn *binance.Client) gin.HandlerFunc {
+func PendingOrdersHandler(clients *binance.ClientPool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		symbol := c.Query("symbol") // Optional: filter by symbol
+		userID, account := c.Query("userId"), c.Query("account")
+		if !claimOwnership(c, &userID) || !requireAccountAccess(c, account) {
+			return
+		}
+
+		var after *binance.OrderCursor
+		var limit int
+		var errLimit, errCursor error
+		if c.Query("limit") != "" {
+			limit, errLimit = strconv.Atoi(c.Query("limit"))
+		}
+		if c.Query("cursor") != "" {
+			after, errCursor = binance.ParseOrderCursor(c.Query("cursor"))
+		}
+		fields, errFields := parseFields(c, itemFields(orderItem(&futures.Order{})))
+		if errLimit != nil || errCursor != nil || errFields != nil || (c.Query("limit") != "" && (limit < 1 || limit > 500)) {
+			message := "limit 1-500, cursor a nextCursor value"
+			if errFields != nil {
+				message = errFields.Error()
+			}
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     message,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		bn, ok := userClient(c, clients, userID, account)
+		if !ok {
+			return
+		}
 
-		orders, err := bn.GetOpenOrders(symbol)
+		orders, err := bn.GetOpenOrders(c.Request.Context(), symbol)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
@@ -215,28 +414,19 @@ func PendingOrdersHandler(bn *binance.Client) gin.HandlerFunc {
 			return
 		}
 
+		page := binance.PageOrders(orders, after, limit)
 		orderDetails := []gin.H{}
-		for _, order := range orders {
-			orderDetails = append(orderDetails, gin.H{
-				"orderId":       order.OrderID,
-				"symbol":        order.Symbol,
-				"side":          order.Side,
-				"type":          order.Type,
-				"price":         order.Price,
-				"stopPrice":     order.StopPrice,
-				"quantity":      order.OrigQuantity,
-				"status":        order.Status,
-				"timeInForce":   order.TimeInForce,
-				"createdTime":   order.Time,
-				"reduceOnly":    order.ReduceOnly,
-				"closePosition": order.ClosePosition,
-			})
+		for _, order := range page.Orders {
+			orderDetails = append(orderDetails, fields.pick(orderItem(order)))
 		}
 
 		data := gin.H{
-			"totalOrders": len(orderDetails),
+			"totalOrders": len(orders),
 			"orders":      orderDetails,
 		}
+		if page.NextCursor != "" {
+			data["nextCursor"] = page.NextCursor
+		}
 
 		c.JSON(http.StatusOK, models.TradeResponse{
 			Success:   true,
@@ -247,6 +437,24 @@ func PendingOrdersHandler(bn *binance.Client) gin.HandlerFunc {
 	}
 }
 
+// orderItem lists the fields of a pending order
+func orderItem(order *futures.Order) gin.H {
+	return gin.H{
+		"orderId":       order.OrderID,
+		"symbol":        order.Symbol,
+		"side":          order.Side,
+		"type":          order.Type,
+		"price":         order.Price,
+		"stopPrice":     order.StopPrice,
+		"quantity":      order.OrigQuantity,
+		"status":        order.Status,
+		"timeInForce":   order.TimeInForce,
+		"createdTime":   order.Time,
+		"reduceOnly":    order.ReduceOnly,
+		"closePosition": order.ClosePosition,
+	}
+}
+
 // CancelOrdersHandler - Cancel pending orders
 // @Summary      Cancel orders
 // @Description  Cancel pending orders by symbol, specific order ID, or all orders
@@ -275,7 +483,7 @@ func CancelOrdersHandler(bn *binance.Client) gin.HandlerFunc {
 
 		if req.OrderID != 0 && req.Symbol != "" {
 			// Cancel specific order
-			err := bn.CancelOrder(req.Symbol, req.OrderID)
+			err := bn.CancelOrder(c.Request.Context(), req.Symbol, req.OrderID)
 			if err != nil {
 				errors = append(errors, err.Error())
 			} else {
@@ -288,7 +496,7 @@ func CancelOrdersHandler(bn *binance.Client) gin.HandlerFunc {
 			}
 		} else if req.Symbol != "" {
 			// Cancel all orders for symbol
-			result, err := bn.CancelAllOrders(req.Symbol)
+			result, err := bn.CancelAllOrders(c.Request.Context(), req.Symbol)
 			if err != nil {
 				errors = append(errors, err.Error())
 			} else {
@@ -301,7 +509,7 @@ func CancelOrdersHandler(bn *binance.Client) gin.HandlerFunc {
 			}
 		} else {
 			// Cancel all orders (all symbols)
-			symbols, err := bn.GetActiveSymbols()
+			symbols, err := bn.GetActiveSymbols(c.Request.Context())
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, models.TradeResponse{
 					Success:   false,
@@ -313,7 +521,7 @@ func CancelOrdersHandler(bn *binance.Client) gin.HandlerFunc {
 			}
 
 			for _, symbol := range symbols {
-				result, err := bn.CancelAllOrders(symbol)
+				result, err := bn.CancelAllOrders(c.Request.Context(), symbol)
 				if err != nil {
 					errors = append(errors, err.Error())
 				} else {
@@ -357,24 +565,31 @@ func CancelOrdersHandler(bn *binance.Client) gin.HandlerFunc {
 // @Success      200      {object}  models.TradeResponse{data=object}  "Position closed successfully"
 // @Failure      400      {object}  models.TradeResponse  "Invalid request"
 // @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403      {object}  models.TradeResponse  "No Binance account for user"
 // @Failure      500      {object}  models.TradeResponse  "Failed to close position"
 // @Router       /api/position/close [post]
-func ClosePositionHandler(bn *binance.Client, fb *firebase.Client) gin.HandlerFunc {
+func ClosePositionHandler(clients *binance.ClientPool, fb storage.TradeStore, bus *events.Bus) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req models.ClosePositionRequest
 
 		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
 			c.JSON(http.StatusBadRequest, models.TradeResponse{
 				Success:   false,
 				Message:   "Invalid request",
 				Error:     err.Error(),
+				Details:   validation.Details(err),
 				Timestamp: time.Now().Unix(),
 			})
 			return
 		}
 
-		// Close position on Binance
-		result, err := bn.ClosePosition(req.Symbol)
+		bn, ok := positionClient(c, clients, fb, req.TradeID, req.UserID, req.Account)
+		if !ok {
+			return
+		}
+
+		result, err := ClosePosition(c.Request.Context(), bn, fb, bus, req.Symbol, req.TradeID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
@@ -385,60 +600,329 @@ func ClosePositionHandler(bn *binance.Client, fb *firebase.Client) gin.HandlerFu
 			return
 		}
 
-		// Update trade in Firebase if tradeId provided
-		if req.TradeID != "" {
-			trade, err := fb.GetTrade(c.Request.Context(), req.TradeID)
-			if err == nil {
-				trade.Status = "CLOSED"
-				trade.ClosedAt = time.Now().Unix()
-				trade.PnL = result.RealizedProfit
-				fb.UpdateTrade(c.Request.Context(), trade)
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Position closed successfully",
+			Data:      result,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// positionClient resolves the account of a position change: the account a
+// linked trade was opened on, otherwise the userId or account of the request.
+// Token holders may only change positions of their own trades.
+func positionClient(c *gin.Context, clients *binance.ClientPool, fb storage.TradeStore, tradeID, userID, account string) (*binance.Client, bool) {
+	if !requireAccountAccess(c, account) {
+		return nil, false
+	}
+
+	if tradeID != "" {
+		if trade, err := fb.GetTrade(c.Request.Context(), tradeID); err == nil && trade != nil {
+			userID, account = trade.UserID, tradeAccount(trade)
+		}
+	} else if authenticatedUser(c) != "" {
+		c.JSON(http.StatusBadRequest, models.TradeResponse{
+			Success:   false,
+			Message:   "Invalid request",
+			Error:     "tradeId is required when authenticated with a token",
+			Timestamp: time.Now().Unix(),
+		})
+		return nil, false
+	}
+	if !requireOwner(c, userID) {
+		return nil, false
+	}
+
+	return userClient(c, clients, userID, account)
+}
+
+// ClosePosition closes a position on Binance, marks the linked trade (if any)
+// CLOSED with its realized PnL and exit fees, and publishes the close
+func ClosePosition(ctx context.Context, bn *binance.Client, fb storage.TradeStore, bus *events.Bus, symbol, tradeID string) (*binance.ClosePositionResult, error) {
+	result, err := bn.ClosePosition(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// The position is closed on Binance: record it even if the caller has gone away
+	ctx = tradehistory.WithSource(context.WithoutCancel(ctx), tradehistory.SourceClosePosition)
+
+	closed := events.PositionClosed{Symbol: symbol, RealizedPnL: result.RealizedProfit, Reason: events.CloseManual}
+
+	// Update trade in Firebase if tradeId provided
+	if tradeID != "" {
+		trade, err := fb.GetTrade(ctx, tradeID)
+		if err == nil {
+			closed.Trade = trade
+			trade.Status = "CLOSED"
+			trade.ClosedAt = time.Now().Unix()
+			trade.PnL = result.RealizedProfit
+
+			// Record exit fees alongside entry fees
+			if err := bn.RecordOrderFees(ctx, trade, result.OrderID); err != nil {
+				logging.Ctx(ctx).Warn().Err(err).Msg("Failed to record exit fees")
 			}
+			fb.UpdateTrade(ctx, trade)
+		}
+	}
+
+	bus.Publish(closed)
+	return result, nil
+}
+
+// PositionMarginHandler - Add or remove isolated margin
+// @Summary      Adjust position margin
+// @Description  Add or remove isolated margin on an open ISOLATED position. With tradeId the change is recorded on the trade (marginAdded and its history).
+// @Tags         Positions
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      models.PositionMarginRequest  true  "Margin change"
+// @Success      200      {object}  models.TradeResponse{data=object}  "Position margin changed"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request or unknown account"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403      {object}  models.TradeResponse  "No Binance account for user"
+// @Failure      500      {object}  models.TradeResponse  "Failed to change position margin"
+// @Router       /api/position/margin [post]
+func PositionMarginHandler(clients *binance.ClientPool, fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.PositionMarginRequest
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		bn, ok := positionClient(c, clients, fb, req.TradeID, req.UserID, req.Account)
+		if !ok {
+			return
+		}
+
+		amount := req.Amount
+		if req.Type == "REMOVE" {
+			amount = -amount
+		}
+
+		trade, err := AdjustPositionMargin(c.Request.Context(), bn, fb, req.Symbol, amount, req.TradeID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to change position margin",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		data := gin.H{"symbol": req.Symbol, "type": req.Type, "amount": req.Amount}
+		if trade != nil {
+			data["tradeId"] = trade.ID
+			data["marginAdded"] = trade.MarginAdded
 		}
 
 		c.JSON(http.StatusOK, models.TradeResponse{
 			Success:   true,
-			Message:   "Position closed successfully",
+			TradeID:   req.TradeID,
+			Message:   "Position margin changed",
+			Data:      data,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// AdjustPositionMargin adds (amount > 0) or removes (amount < 0) isolated
+// margin on a position and records the change on the linked trade, if any,
+// which is returned
+func AdjustPositionMargin(ctx context.Context, bn *binance.Client, fb storage.TradeStore, symbol string, amount float64, tradeID string) (*models.Trade, error) {
+	if err := bn.ChangePositionMargin(ctx, symbol, math.Abs(amount), amount > 0); err != nil {
+		return nil, err
+	}
+	if tradeID == "" {
+		return nil, nil
+	}
+
+	// The margin has changed on Binance: record it even if the caller has gone away
+	ctx = tradehistory.WithSource(context.WithoutCancel(ctx), tradehistory.SourceMarginAdjust)
+
+	trade, err := fb.GetTrade(ctx, tradeID)
+	if err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, tradeID).Msgf("Margin changed but trade %s not found", tradeID)
+		return nil, nil
+	}
+	trade.MarginAdded += amount
+	if err := fb.UpdateTrade(ctx, trade); err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, tradeID).Msgf("Margin changed but not recorded on trade %s", tradeID)
+	}
+	return trade, nil
+}
+
+// PositionLeverageHandler - Change a symbol's leverage
+// @Summary      Change leverage
+// @Description  Set a symbol's leverage without placing an order. The leverage must be within the symbol's brackets, an open ISOLATED position's leverage cannot be lowered and an open position must stay within the maximum notional at the new leverage.
+// @Tags         Positions
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      models.PositionLeverageRequest  true  "Leverage change"
+// @Success      200      {object}  models.TradeResponse{data=binance.LeverageChange}  "Leverage changed"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request, leverage out of range or unknown account"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403      {object}  models.TradeResponse  "No Binance account for user"
+// @Failure      409      {object}  models.TradeResponse  "Not allowed with the open position"
+// @Failure      500      {object}  models.TradeResponse  "Failed to change leverage"
+// @Router       /api/position/leverage [post]
+func PositionLeverageHandler(clients *binance.ClientPool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.PositionLeverageRequest
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !claimOwnership(c, &req.UserID) || !requireAccountAccess(c, req.Account) {
+			return
+		}
+		bn, ok := userClient(c, clients, req.UserID, req.Account)
+		if !ok {
+			return
+		}
+
+		result, err := bn.ChangeLeverage(c.Request.Context(), req.Symbol, req.Leverage)
+		if err != nil {
+			positionSettingError(c, "Failed to change leverage", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Leverage changed",
 			Data:      result,
 			Timestamp: time.Now().Unix(),
 		})
 	}
 }
 
+// MarginTypeHandler - Switch a symbol between ISOLATED and CROSSED margin
+// @Summary      Change margin type
+// @Description  Switch a symbol between ISOLATED and CROSSED margin without placing an order. Binance only allows it with no open position or orders on the symbol.
+// @Tags         Positions
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      models.MarginTypeRequest  true  "Margin type change"
+// @Success      200      {object}  models.TradeResponse{data=binance.MarginTypeChange}  "Margin type changed"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request or unknown account"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403      {object}  models.TradeResponse  "No Binance account for user"
+// @Failure      409      {object}  models.TradeResponse  "Not allowed with the open position or orders"
+// @Failure      500      {object}  models.TradeResponse  "Failed to change margin type"
+// @Router       /api/position/margin-type [post]
+func MarginTypeHandler(clients *binance.ClientPool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.MarginTypeRequest
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !claimOwnership(c, &req.UserID) || !requireAccountAccess(c, req.Account) {
+			return
+		}
+		bn, ok := userClient(c, clients, req.UserID, req.Account)
+		if !ok {
+			return
+		}
+
+		result, err := bn.ChangeMarginType(c.Request.Context(), req.Symbol, req.MarginType)
+		if err != nil {
+			positionSettingError(c, "Failed to change margin type", err)
+			return
+		}
+
+		message := "Margin type changed"
+		if !result.Changed {
+			message = "Margin type already set"
+		}
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   message,
+			Data:      result,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// positionSettingError responds to a failed leverage or margin type change:
+// 400 for a leverage the symbol does not offer, 409 for a change the open
+// position or orders do not allow, otherwise 500
+func positionSettingError(c *gin.Context, message string, err error) {
+	status, code := http.StatusInternalServerError, ""
+	switch {
+	case errors.Is(err, binance.ErrLeverageRange):
+		status, code = http.StatusBadRequest, models.ErrInvalidRequest
+	case errors.Is(err, binance.ErrPositionConstraint):
+		status, code = http.StatusConflict, models.ErrOpenPosition
+	}
+
+	c.JSON(status, models.TradeResponse{
+		Success:   false,
+		Message:   message,
+		Error:     err.Error(),
+		ErrorCode: code,
+		Timestamp: time.Now().Unix(),
+	})
+}
+
 // TradingSummaryHandler - Get trading summary for period
 // @Summary      Get trading summary
 // @Description  Retrieve comprehensive trading statistics and performance metrics for a specified time period
 // @Tags         Analytics
 // @Produce      json
 // @Security     ApiKeyAuth
-// @Param        period  query     string  false  "Time period: 1d, 7d, 1w, 1m (default: 1d)"
+// @Param        period  query     string  false  "Time period: 1d, 7d, 1w, 1m, 3m, 1y (default: 1d)"
 // @Param        userId  query     string  false  "Filter by user ID (optional)"
 // @Success      200     {object}  models.TradeResponse{data=object}  "Trading summary retrieved successfully"
 // @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403     {object}  models.TradeResponse  "Forbidden - another user's trades"
 // @Failure      500     {object}  models.TradeResponse  "Failed to get trading summary"
 // @Router       /api/summary [get]
-func TradingSummaryHandler(fb *firebase.Client, bn *binance.Client) gin.HandlerFunc {
+func TradingSummaryHandler(fb storage.TradeStore, bn *binance.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		period := c.DefaultQuery("period", "1d") // 1d, 7d, 1w, 1m
 		userID := c.Query("userId")              // Optional: filter by user
 
-		// Calculate time range
-		now := time.Now()
-		var startTime int64
-
-		switch period {
-		case "1d":
-			startTime = now.AddDate(0, 0, -1).Unix()
-		case "7d":
-			startTime = now.AddDate(0, 0, -7).Unix()
-		case "1w":
-			startTime = now.AddDate(0, 0, -7).Unix()
-		case "1m":
-			startTime = now.AddDate(0, -1, 0).Unix()
-		default:
-			startTime = now.AddDate(0, 0, -1).Unix()
+		// Admins see every user unless they pick one; others only themselves
+		if c.GetString(authRoleKey) != models.RoleAdmin && !claimOwnership(c, &userID) {
+			return
 		}
 
+		// Calculate time range
+		startTime := periodStartTime(period)
+
 		// Get trades from Firebase
 		var trades []*models.Trade
 		var err error
@@ -462,9 +946,12 @@ func TradingSummaryHandler(fb *firebase.Client, bn *binance.Client) gin.HandlerF
 		// Calculate statistics
 		summary := calculateTradingSummary(trades, startTime)
 
-		// Get current account PnL from Binance
-		accountPnL, _ := bn.GetAccountPnL()
-		summary["currentAccountPnL"] = accountPnL
+		// Get current account PnL from Binance. It covers the whole
+		// operator account, so only admins and API keys see it.
+		if authenticatedUser(c) == "" || c.GetString(authRoleKey) == models.RoleAdmin {
+			accountPnL, _ := bn.GetAccountPnL(c.Request.Context())
+			summary["currentAccountPnL"] = accountPnL
+		}
 
 		c.JSON(http.StatusOK, models.TradeResponse{
 			Success:   true,
@@ -475,6 +962,24 @@ func TradingSummaryHandler(fb *firebase.Client, bn *binance.Client) gin.HandlerF
 	}
 }
 
+// periodStartTime converts a period (1d, 7d, 1w, 1m) into a start Unix timestamp
+func periodStartTime(period string) int64 {
+	now := time.Now()
+
+	switch period {
+	case "7d", "1w":
+		return now.AddDate(0, 0, -7).Unix()
+	case "1m":
+		return now.AddDate(0, -1, 0).Unix()
+	case "3m":
+		return now.AddDate(0, -3, 0).Unix()
+	case "1y":
+		return now.AddDate(-1, 0, 0).Unix()
+	default:
+		return now.AddDate(0, 0, -1).Unix()
+	}
+}
+
 // Helper function to calculate trading summary
 func calculateTradingSummary(trades []*models.Trade, startTime int64) gin.H {
 	totalTrades := 0
@@ -482,6 +987,7 @@ func calculateTradingSummary(trades []*models.Trade, startTime int64) gin.H {
 	losingTrades := 0
 	totalPnL := 0.0
 	totalVolume := 0.0
+	totalFees := 0.0
 	bestTrade := 0.0
 	worstTrade := 0.0
 
@@ -502,6 +1008,7 @@ func calculateTradingSummary(trades []*models.Trade, startTime int64) gin.H {
 		}
 
 		totalPnL += trade.PnL
+		totalFees += trade.Commission
 
 		if trade.PnL > bestTrade {
 			bestTrade = trade.PnL
@@ -527,6 +1034,8 @@ func calculateTradingSummary(trades []*models.Trade, startTime int64) gin.H {
 		"winRate":       winRate,
 		"totalPnL":      totalPnL,
 		"totalVolume":   totalVolume,
+		"totalFees":     totalFees,
+		"netPnL":        totalPnL - totalFees,
 		"bestTrade":     bestTrade,
 		"worstTrade":    worstTrade,
 		"averagePnL":    avgPnL,
@@ -550,7 +1059,7 @@ func ExchangeInfoHandler(bn *binance.Client) gin.HandlerFunc {
 		symbol := c.Query("symbol") // Optional: filter by specific symbol
 
 		// Get exchange info from Binance
-		exchangeInfo, err := bn.GetExchangeInfo(symbol)
+		exchangeInfo, err := bn.GetExchangeInfo(c.Request.Context(), symbol)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
@@ -587,12 +1096,26 @@ func ExchangeInfoHandler(bn *binance.Client) gin.HandlerFunc {
 // @Param        startTime  query     int     false  "Start time (Unix timestamp in milliseconds)"
 // @Param        endTime    query     int     false  "End time (Unix timestamp in milliseconds)"
 // @Param        limit      query     int     false  "Number of days (7-30, default 7)"
+// @Param        userId     query     string  false  "User whose own Binance account to query (default: operator account)"
+// @Param        account    query     string  false  "Operator account to query: main or a BINANCE_ACCOUNTS name (default: as userId selects)"
 // @Success      200        {object}  models.TradeResponse{data=object}  "Account snapshot retrieved successfully"
+// @Failure      400        {object}  models.TradeResponse  "Unknown account"
 // @Failure      401        {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403        {object}  models.TradeResponse  "No Binance account for user"
 // @Failure      500        {object}  models.TradeResponse  "Failed to get account snapshot"
 // @Router       /api/account/snapshot [get]
-func AccountSnapshotHandler(bn *binance.Client) gin.HandlerFunc {
+func AccountSnapshotHandler(clients *binance.ClientPool) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		userID, account := c.Query("userId"), c.Query("account")
+		if !claimOwnership(c, &userID) || !requireAccountAccess(c, account) {
+			return
+		}
+
+		bn, ok := userClient(c, clients, userID, account)
+		if !ok {
+			return
+		}
+
 		// Parse query parameters
 		var startTime, endTime int64
 		var limit int
@@ -616,7 +1139,7 @@ func AccountSnapshotHandler(bn *binance.Client) gin.HandlerFunc {
 		}
 
 		// Get snapshot from Binance
-		snapshot, err := bn.GetAccountSnapshot(startTime, endTime, limit)
+		snapshot, err := bn.GetAccountSnapshot(c.Request.Context(), startTime, endTime, limit)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
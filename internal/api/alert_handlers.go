@@ -0,0 +1,492 @@
+package api
+
+import (
+	"crypto-trading-api/internal/alerts"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/policy"
+	"crypto-trading-api/internal/storage"
+	"crypto-trading-api/internal/validation"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreatePriceAlertHandler - Register a price alert
+// @Summary      Create price alert
+// @Description  Register a one-shot alert that fires when the symbol's mark price rises to/above (ABOVE) or falls to/below (BELOW) the target price. Triggered alerts are sent to the configured notification channels. If tradeTemplate is set, it is submitted through the normal trade intake (pause, symbol policy, position limits) when the alert fires.
+// @Tags         Alerts
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        alert  body      models.PriceAlertRequest  true  "Price alert"
+// @Success      200    {object}  models.TradeResponse{data=models.PriceAlert}  "Price alert created"
+// @Failure      400    {object}  models.TradeResponse  "Invalid request"
+// @Failure      401    {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403    {object}  models.TradeResponse  "Symbol not allowed"
+// @Failure      500    {object}  models.TradeResponse  "Failed to save price alert"
+// @Router       /api/alerts/price [post]
+func CreatePriceAlertHandler(engine *alerts.Engine, symbols *policy.SymbolPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.PriceAlertRequest
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !requireOwner(c, req.UserID) {
+			return
+		}
+
+		if err := validatePriceAlertRequest(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid price alert",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if err := symbols.Check(req.Symbol); err != nil {
+			c.JSON(http.StatusForbidden, models.TradeResponse{
+				Success:   false,
+				Message:   "Symbol not allowed",
+				Error:     err.Error(),
+				ErrorCode: models.ErrSymbolNotAllowed,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		alert := &models.PriceAlert{
+			ID:            uuid.New().String(),
+			UserID:        req.UserID,
+			Symbol:        req.Symbol,
+			Condition:     req.Condition,
+			Price:         req.Price,
+			Note:          req.Note,
+			TradeTemplate: req.TradeTemplate,
+			CreatedAt:     time.Now().Unix(),
+		}
+
+		if err := engine.Add(c.Request.Context(), alert); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to save price alert",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Price alert created successfully",
+			Data:      alert,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// ListPriceAlertsHandler - List a user's price alerts
+// @Summary      List price alerts
+// @Description  List a user's price alerts, newest first, optionally filtered by status
+// @Tags         Alerts
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  query     string  true   "User ID"
+// @Param        status  query     string  false  "Filter by status (ACTIVE, TRIGGERED, CANCELED)"
+// @Success      200     {object}  models.TradeResponse{data=[]models.PriceAlert}  "Price alerts retrieved"
+// @Failure      400     {object}  models.TradeResponse  "Missing userId parameter"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get price alerts"
+// @Router       /api/alerts/price [get]
+func ListPriceAlertsHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Query("userId")
+		if !claimOwnership(c, &userID) {
+			return
+		}
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "userId parameter is required",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		status := strings.ToUpper(c.Query("status"))
+
+		all, err := fb.GetPriceAlerts(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get price alerts",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		userAlerts := []*models.PriceAlert{}
+		for _, alert := range all {
+			if alert.UserID == userID && (status == "" || alert.Status == status) {
+				userAlerts = append(userAlerts, alert)
+			}
+		}
+		sort.Slice(userAlerts, func(i, j int) bool {
+			return userAlerts[i].CreatedAt > userAlerts[j].CreatedAt
+		})
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Price alerts retrieved successfully",
+			Data:      userAlerts,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// CancelPriceAlertHandler - Cancel an active price alert
+// @Summary      Cancel price alert
+// @Description  Stop watching an ACTIVE price alert and mark it CANCELED
+// @Tags         Alerts
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        alertId  path      string  true  "Price alert ID"
+// @Success      200      {object}  models.TradeResponse{data=models.PriceAlert}  "Price alert canceled"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      404      {object}  models.TradeResponse  "Price alert not found"
+// @Failure      409      {object}  models.TradeResponse  "Price alert is no longer active"
+// @Failure      500      {object}  models.TradeResponse  "Failed to cancel price alert"
+// @Router       /api/alerts/price/{alertId} [delete]
+func CancelPriceAlertHandler(engine *alerts.Engine, fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		alertID := c.Param("alertId")
+
+		all, err := fb.GetPriceAlerts(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get price alerts",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		var alert *models.PriceAlert
+		for _, a := range all {
+			if a.ID == alertID {
+				alert = a
+				break
+			}
+		}
+		if alert == nil {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "Price alert not found",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !requireOwner(c, alert.UserID) {
+			return
+		}
+
+		if alert.Status != alerts.StatusActive {
+			c.JSON(http.StatusConflict, models.TradeResponse{
+				Success:   false,
+				Message:   "Price alert is no longer active",
+				Error:     fmt.Sprintf("alert status is %s", alert.Status),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if err := engine.Cancel(c.Request.Context(), alert); err != nil {
+			// Fired between the status check above and the cancel
+			if errors.Is(err, alerts.ErrNotActive) {
+				c.JSON(http.StatusConflict, models.TradeResponse{
+					Success:   false,
+					Message:   "Price alert is no longer active",
+					Error:     err.Error(),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to cancel price alert",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Price alert canceled successfully",
+			Data:      alert,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// Normalize and validate a price alert request and its optional trade template
+func validatePriceAlertRequest(req *models.PriceAlertRequest) error {
+	req.Symbol = strings.ToUpper(req.Symbol)
+	req.Condition = strings.ToUpper(req.Condition)
+
+	if req.Condition != models.AlertConditionAbove && req.Condition != models.AlertConditionBelow {
+		return fmt.Errorf("condition must be %s or %s", models.AlertConditionAbove, models.AlertConditionBelow)
+	}
+
+	if req.TradeTemplate != nil {
+		tpl := req.TradeTemplate
+		tpl.Symbol = strings.ToUpper(tpl.Symbol)
+		if tpl.Symbol != req.Symbol {
+			return fmt.Errorf("tradeTemplate.symbol must match the alert symbol")
+		}
+		// Preset-based templates are completed and validated when the alert fires
+		if tpl.Preset == "" {
+			if err := validateTradeParams(tpl); err != nil {
+				return fmt.Errorf("invalid tradeTemplate: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ListPerformanceAlertsHandler - List a user's performance alerts
+// @Summary      List performance alerts
+// @Description  List a user's drawdown, losing streak and daily loss alerts, newest first, optionally filtered by status. ACTIVE alerts are repeated every PERFORMANCE_ALERT_REPEAT until acknowledged; RESOLVED ones are back within their threshold.
+// @Tags         Alerts
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  query     string  true   "User ID"
+// @Param        status  query     string  false  "Filter by status (ACTIVE, ACKNOWLEDGED, RESOLVED)"
+// @Success      200     {object}  models.TradeResponse{data=[]models.PerformanceAlert}  "Performance alerts retrieved"
+// @Failure      400     {object}  models.TradeResponse  "Missing userId parameter"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get performance alerts"
+// @Router       /api/alerts/performance [get]
+func ListPerformanceAlertsHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Query("userId")
+		if !claimOwnership(c, &userID) {
+			return
+		}
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "userId parameter is required",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		status := strings.ToUpper(c.Query("status"))
+
+		all, err := fb.GetPerformanceAlerts(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get performance alerts",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		userAlerts := []*models.PerformanceAlert{}
+		for _, alert := range all {
+			if alert.UserID == userID && (status == "" || alert.Status == status) {
+				userAlerts = append(userAlerts, alert)
+			}
+		}
+		sort.Slice(userAlerts, func(i, j int) bool {
+			return userAlerts[i].TriggeredAt > userAlerts[j].TriggeredAt
+		})
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Performance alerts retrieved successfully",
+			Data:      userAlerts,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// AcknowledgePerformanceAlertHandler - Acknowledge a performance alert
+// @Summary      Acknowledge performance alert
+// @Description  Stop the reminders of an open performance alert. It stays ACKNOWLEDGED until its value is back within the threshold; crossing it again later opens a new alert.
+// @Tags         Alerts
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        alertId  path      string  true  "Performance alert ID"
+// @Success      200      {object}  models.TradeResponse{data=models.PerformanceAlert}  "Performance alert acknowledged"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      404      {object}  models.TradeResponse  "Performance alert not found"
+// @Failure      409      {object}  models.TradeResponse  "Performance alert is resolved"
+// @Failure      500      {object}  models.TradeResponse  "Failed to acknowledge performance alert"
+// @Router       /api/alerts/performance/{alertId}/acknowledge [post]
+func AcknowledgePerformanceAlertHandler(monitor *alerts.PerformanceMonitor, fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		alert, ok := findPerformanceAlert(c, fb)
+		if !ok {
+			return
+		}
+
+		if !alert.Open() {
+			c.JSON(http.StatusConflict, models.TradeResponse{
+				Success:   false,
+				Message:   "Performance alert is resolved",
+				Error:     fmt.Sprintf("alert status is %s", alert.Status),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		by := authenticatedUser(c)
+		if by == "" {
+			by = alert.UserID
+		}
+		if err := monitor.Acknowledge(c.Request.Context(), alert, by); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to acknowledge performance alert",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Performance alert acknowledged",
+			Data:      alert,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// SnoozePerformanceAlertHandler - Snooze a performance alert
+// @Summary      Snooze performance alert
+// @Description  Hold back the reminders of an ACTIVE performance alert for a duration (1m to 7 days). If the alert is still open when the snooze ends, it is sent again.
+// @Tags         Alerts
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        alertId  path      string                true  "Performance alert ID"
+// @Param        snooze   body      models.SnoozeRequest  true  "Snooze duration"
+// @Success      200      {object}  models.TradeResponse{data=models.PerformanceAlert}  "Performance alert snoozed"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      404      {object}  models.TradeResponse  "Performance alert not found"
+// @Failure      409      {object}  models.TradeResponse  "Performance alert is not active"
+// @Failure      500      {object}  models.TradeResponse  "Failed to snooze performance alert"
+// @Router       /api/alerts/performance/{alertId}/snooze [post]
+func SnoozePerformanceAlertHandler(monitor *alerts.PerformanceMonitor, fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.SnoozeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil || duration < time.Minute || duration > 7*24*time.Hour {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     "duration must be between 1m and 168h, e.g. 4h",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		alert, ok := findPerformanceAlert(c, fb)
+		if !ok {
+			return
+		}
+
+		if alert.Status != models.PerformanceAlertActive {
+			c.JSON(http.StatusConflict, models.TradeResponse{
+				Success:   false,
+				Message:   "Performance alert is not active",
+				Error:     fmt.Sprintf("alert status is %s", alert.Status),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if err := monitor.Snooze(c.Request.Context(), alert, time.Now().Add(duration)); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to snooze performance alert",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("Performance alert snoozed for %v", duration),
+			Data:      alert,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// findPerformanceAlert looks up the :alertId performance alert and checks the
+// caller owns it, responding 404/403/500 otherwise
+func findPerformanceAlert(c *gin.Context, fb storage.TradeStore) (*models.PerformanceAlert, bool) {
+	alertID := c.Param("alertId")
+
+	all, err := fb.GetPerformanceAlerts(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.TradeResponse{
+			Success:   false,
+			Message:   "Failed to get performance alerts",
+			Error:     err.Error(),
+			Timestamp: time.Now().Unix(),
+		})
+		return nil, false
+	}
+
+	for _, alert := range all {
+		if alert.ID == alertID {
+			return alert, requireOwner(c, alert.UserID)
+		}
+	}
+
+	c.JSON(http.StatusNotFound, models.TradeResponse{
+		Success:   false,
+		Message:   "Performance alert not found",
+		Timestamp: time.Now().Unix(),
+	})
+	return nil, false
+}
@@ -0,0 +1,812 @@
+package api
+
+import (
+	"crypto-trading-api/internal/analytics"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/latency"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/reports"
+	"crypto-trading-api/internal/storage"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValueAtRiskHandler - Get parametric VaR and correlation of open positions
+// @Summary      Get Value-at-Risk report
+// @Description  Compute parametric (variance-covariance) Value-at-Risk and pairwise correlation of open positions from recent kline returns (in-memory candles when streamed with enough history, REST otherwise)
+// @Tags         Risk Management
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        confidence  query     number  false  "Confidence level (default: 0.95)" example(0.95)
+// @Param        interval    query     string  false  "Kline interval used for returns (default: 1h)" example("1h")
+// @Param        lookback    query     int     false  "Number of klines of history (default: 168, max: 1500)" example(168)
+// @Param        horizon     query     int     false  "VaR horizon in intervals (default: 24)" example(24)
+// @Param        userId      query     string  false  "User whose own Binance account to query (default: operator account)"
+// @Param        account     query     string  false  "Operator account to query: main or a BINANCE_ACCOUNTS name (default: as userId selects)"
+// @Success      200         {object}  models.TradeResponse{data=analytics.VaRReport}  "VaR report calculated"
+// @Failure      400         {object}  models.TradeResponse  "Invalid parameters or unknown account"
+// @Failure      401         {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403         {object}  models.TradeResponse  "No Binance account for user"
+// @Failure      500         {object}  models.TradeResponse  "Failed to calculate VaR"
+// @Router       /api/risk/var [get]
+func ValueAtRiskHandler(clients *binance.ClientPool, streams *binance.WebSocketManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, account := c.Query("userId"), c.Query("account")
+		if !claimOwnership(c, &userID) || !requireAccountAccess(c, account) {
+			return
+		}
+
+		confidence, _ := strconv.ParseFloat(c.DefaultQuery("confidence", "0.95"), 64)
+		interval := c.DefaultQuery("interval", "1h")
+		lookback, _ := strconv.Atoi(c.DefaultQuery("lookback", "168"))
+		horizon, _ := strconv.Atoi(c.DefaultQuery("horizon", "24"))
+
+		if lookback < 3 || lookback > 1500 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     "lookback must be between 3 and 1500",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		bn, ok := userClient(c, clients, userID, account)
+		if !ok {
+			return
+		}
+
+		positions, err := bn.GetOpenPositions(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get open positions",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		exposures := []analytics.Exposure{}
+		closes := make(map[string][]float64)
+		for _, pos := range positions {
+			exposures = append(exposures, analytics.Exposure{
+				Symbol:   pos.Symbol,
+				Notional: pos.PositionAmt * pos.MarkPrice,
+			})
+
+			if _, ok := closes[pos.Symbol]; ok {
+				continue
+			}
+			if series, ok := streams.KlineCloses(pos.Symbol, interval, lookback); ok {
+				closes[pos.Symbol] = series
+				continue
+			}
+			series, err := bn.GetKlineCloses(c.Request.Context(), pos.Symbol, interval, lookback)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, models.TradeResponse{
+					Success:   false,
+					Message:   "Failed to get price history",
+					Error:     err.Error(),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+			closes[pos.Symbol] = series
+		}
+
+		report, err := analytics.ComputeVaR(exposures, closes, confidence, horizon)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to calculate VaR",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "VaR report calculated successfully",
+			Data:      report,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// FeesReportHandler - Get fees paid per symbol and per day
+// @Summary      Get fee report
+// @Description  Report trading commissions recorded on trades for a period, grouped by symbol and day, alongside the COMMISSION total from Binance income history
+// @Tags         Analytics
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        period  query     string  false  "Time period: 1d, 7d, 1w, 1m, 3m, 1y (default: 1m)"
+// @Param        userId  query     string  false  "Filter by user ID (optional)"
+// @Param        symbol  query     string  false  "Filter by symbol (optional)"
+// @Success      200     {object}  models.TradeResponse{data=object}  "Fee report retrieved successfully"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get fee report"
+// @Router       /api/analytics/fees [get]
+func FeesReportHandler(fb storage.TradeStore, bn *binance.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "1m")
+		userID := c.Query("userId")
+		symbol := c.Query("symbol")
+		startTime := periodStartTime(period)
+
+		// Admins see every user unless they pick one; others only themselves
+		if c.GetString(authRoleKey) != models.RoleAdmin && !claimOwnership(c, &userID) {
+			return
+		}
+
+		var trades []*models.Trade
+		var err error
+
+		if userID != "" {
+			trades, err = fb.GetUserTrades(c.Request.Context(), userID)
+		} else {
+			trades, err = fb.GetAllTrades(c.Request.Context())
+		}
+
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get trades",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		totalFees := 0.0
+		totalPnL := 0.0
+		bySymbol := make(map[string]float64)
+		byDay := make(map[string]float64)
+
+		for _, trade := range trades {
+			if trade.CreatedAt < startTime || (symbol != "" && trade.Symbol != symbol) {
+				continue
+			}
+
+			totalFees += trade.Commission
+			totalPnL += trade.PnL
+			bySymbol[trade.Symbol] += trade.Commission
+			byDay[time.Unix(trade.CreatedAt, 0).UTC().Format("2006-01-02")] += trade.Commission
+		}
+
+		data := gin.H{
+			"period":    period,
+			"totalFees": totalFees,
+			"grossPnL":  totalPnL,
+			"netPnL":    totalPnL - totalFees,
+			"bySymbol":  bySymbol,
+			"byDay":     byDay,
+		}
+
+		// Binance reports commissions as negative income. The exchange
+		// totals cover the whole account, so they are left out of a single
+		// user's report.
+		if userID == "" {
+			if exchangeFees, err := bn.GetIncomeTotal(c.Request.Context(), "COMMISSION", symbol, startTime, time.Now().Unix()); err == nil {
+				data["exchangeFees"] = -exchangeFees
+			}
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Fee report retrieved successfully",
+			Data:      data,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// LatencyReportHandler - Get order round-trip latency percentiles
+// @Summary      Get latency report
+// @Description  Percentiles of the entry timings recorded on trades created in a period (signal received → order sent → acknowledged → filled, and SL/TP placement), the slowest trades, and this instance's recent call latencies to Binance (by endpoint) and Firebase
+// @Tags         Analytics
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        period  query     string  false  "Time period: 1d, 7d, 1w, 1m, 3m, 1y (default: 1d)"
+// @Param        userId  query     string  false  "Filter by user ID (optional)"
+// @Param        symbol  query     string  false  "Filter by symbol (optional)"
+// @Success      200     {object}  models.TradeResponse{data=models.LatencyReport}  "Latency report retrieved successfully"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403     {object}  models.TradeResponse  "Forbidden - another user's trades"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get trades"
+// @Router       /api/analytics/latency [get]
+func LatencyReportHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "1d")
+		userID := c.Query("userId")
+		symbol := strings.ToUpper(c.Query("symbol"))
+		startTime := periodStartTime(period)
+
+		if !claimOwnership(c, &userID) {
+			return
+		}
+
+		var trades []*models.Trade
+		var err error
+		if userID != "" {
+			trades, err = fb.GetUserTrades(c.Request.Context(), userID)
+		} else {
+			trades, err = fb.GetAllTrades(c.Request.Context())
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get trades",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		report := tradeLatencyReport(trades, startTime, symbol)
+		report.Period = period
+		dependencies, since := latency.Report()
+		report.Dependencies, report.DependenciesSince = dependencies, since.Unix()
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Latency report retrieved successfully",
+			Data:      report,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// slowestTrades is how many trades a latency report lists
+const slowestTrades = 5
+
+// tradeLatencyReport summarizes the entry timings of the trades created
+// since startTime (on symbol, if set). Zero timings of a stage (no SL/TP,
+// fill not seen) are left out of it.
+func tradeLatencyReport(trades []*models.Trade, startTime int64, symbol string) *models.LatencyReport {
+	stages := map[string][]float64{}
+	add := func(stage string, ms int64) {
+		if ms > 0 {
+			stages[stage] = append(stages[stage], float64(ms))
+		}
+	}
+
+	timed := []*models.Trade{}
+	for _, trade := range trades {
+		if trade.Latency == nil || trade.Latency.AcknowledgedMs == 0 || trade.CreatedAt < startTime || (symbol != "" && trade.Symbol != symbol) {
+			continue
+		}
+		timed = append(timed, trade)
+		add("validation", trade.Latency.ValidationMs)
+		add("order", trade.Latency.OrderMs)
+		add("acknowledged", trade.Latency.AcknowledgedMs)
+		add("protection", trade.Latency.ProtectionMs)
+		add("filled", trade.Latency.FilledMs)
+	}
+
+	report := &models.LatencyReport{
+		Trades:  len(timed),
+		Stages:  make(map[string]models.LatencyStats, len(stages)),
+		Slowest: []models.SlowTrade{},
+	}
+	for stage, samples := range stages {
+		report.Stages[stage] = latency.Summarize(samples)
+	}
+
+	sort.Slice(timed, func(i, j int) bool {
+		return timed[i].Latency.AcknowledgedMs > timed[j].Latency.AcknowledgedMs
+	})
+	for _, trade := range timed {
+		if len(report.Slowest) == slowestTrades {
+			break
+		}
+		report.Slowest = append(report.Slowest, models.SlowTrade{
+			TradeID:   trade.ID,
+			Symbol:    trade.Symbol,
+			OrderType: trade.OrderType,
+			CreatedAt: trade.CreatedAt,
+			Latency:   *trade.Latency,
+		})
+	}
+	return report
+}
+
+// HeatmapHandler - Get PnL and win rate by hour of day and day of week
+// @Summary      Get PnL heatmap
+// @Description  PnL, net PnL (after fees) and win rate of closed trades bucketed by hour of day, by day of week (Monday first) and by both, in a time zone, to show when a strategy performs. Trades are bucketed by when they were opened (fill time, else creation time) or, with time=close, by when they closed.
+// @Tags         Analytics
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        period  query     string  false  "Time period: 1d, 7d, 1w, 1m, 3m, 1y (default: 3m)"
+// @Param        userId  query     string  false  "Filter by user ID (optional)"
+// @Param        symbol  query     string  false  "Filter by symbol (optional)"
+// @Param        tz      query     string  false  "IANA time zone, e.g. Asia/Bangkok (default: the user's settings timezone, else UTC)"
+// @Param        time    query     string  false  "Bucket by open or close time (default: open)"
+// @Success      200     {object}  models.TradeResponse{data=analytics.Heatmap}  "Heatmap calculated"
+// @Failure      400     {object}  models.TradeResponse  "Invalid parameters"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403     {object}  models.TradeResponse  "Forbidden - another user's trades"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get trades"
+// @Router       /api/analytics/heatmap [get]
+func HeatmapHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "3m")
+		userID := c.Query("userId")
+		symbol := strings.ToUpper(c.Query("symbol"))
+		by := strings.ToLower(c.DefaultQuery("time", analytics.HeatmapByOpen))
+		startTime := periodStartTime(period)
+
+		if !claimOwnership(c, &userID) {
+			return
+		}
+
+		if by != analytics.HeatmapByOpen && by != analytics.HeatmapByClose {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     "time must be open or close",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		timezone := c.Query("tz")
+		if timezone == "" && userID != "" {
+			settings, err := fb.GetUserSettings(c.Request.Context(), userID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, models.TradeResponse{
+					Success:   false,
+					Message:   "Failed to get user settings",
+					Error:     err.Error(),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+			if settings != nil {
+				timezone = settings.Timezone
+			}
+		}
+		loc := time.UTC
+		if timezone != "" {
+			var err error
+			if loc, err = time.LoadLocation(timezone); err != nil {
+				c.JSON(http.StatusBadRequest, models.TradeResponse{
+					Success:   false,
+					Message:   "Invalid parameters",
+					Error:     fmt.Sprintf("unknown timezone %q", timezone),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+		}
+
+		var trades []*models.Trade
+		var err error
+		if userID != "" {
+			trades, err = fb.GetUserTrades(c.Request.Context(), userID)
+		} else {
+			trades, err = fb.GetAllTrades(c.Request.Context())
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get trades",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if symbol != "" {
+			filtered := []*models.Trade{}
+			for _, trade := range trades {
+				if trade.Symbol == symbol {
+					filtered = append(filtered, trade)
+				}
+			}
+			trades = filtered
+		}
+
+		heatmap := analytics.BuildHeatmap(trades, startTime, by, loc)
+		heatmap.Period = period
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("Heatmap of %d closed trades", heatmap.Trades),
+			Data:      heatmap,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// SymbolLeaderboardHandler - Rank symbols by their closed-trade results
+// @Summary      Get symbol leaderboard
+// @Description  Rank the symbols of the trades closed in a period by total PnL, net PnL, win rate, average R (PnL in multiples of the risk between entry and stop loss), fees paid or trade count, to find the instruments a strategy loses on. Symbols with fewer than minTrades closed trades are listed under excluded instead of ranked. Use order=asc to list the worst first.
+// @Tags         Analytics
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        period     query     string  false  "Time period: 1d, 7d, 1w, 1m, 3m, 1y (default: 3m)"
+// @Param        userId     query     string  false  "Filter by user ID (optional)"
+// @Param        sort       query     string  false  "Rank by pnl, netPnl, winRate, averageR, fees or trades (default: pnl)"
+// @Param        order      query     string  false  "desc (best first) or asc (default: desc)"
+// @Param        minTrades  query     int     false  "Minimum closed trades for a symbol to be ranked (default: 1)"
+// @Success      200        {object}  models.TradeResponse{data=analytics.SymbolLeaderboard}  "Leaderboard calculated"
+// @Failure      400        {object}  models.TradeResponse  "Invalid parameters"
+// @Failure      401        {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403        {object}  models.TradeResponse  "Forbidden - another user's trades"
+// @Failure      500        {object}  models.TradeResponse  "Failed to get trades"
+// @Router       /api/analytics/symbols [get]
+func SymbolLeaderboardHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "3m")
+		userID := c.Query("userId")
+		sortBy := c.DefaultQuery("sort", analytics.RankByPnL)
+		order := strings.ToLower(c.DefaultQuery("order", "desc"))
+		minTrades, errMin := strconv.Atoi(c.DefaultQuery("minTrades", "1"))
+		startTime := periodStartTime(period)
+
+		if !claimOwnership(c, &userID) {
+			return
+		}
+
+		validSort := false
+		for _, key := range analytics.RankKeys {
+			validSort = validSort || key == sortBy
+		}
+		if !validSort || (order != "asc" && order != "desc") || errMin != nil || minTrades < 1 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     fmt.Sprintf("sort must be one of %s, order asc or desc and minTrades at least 1", strings.Join(analytics.RankKeys, ", ")),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		var trades []*models.Trade
+		var err error
+		if userID != "" {
+			trades, err = fb.GetUserTrades(c.Request.Context(), userID)
+		} else {
+			trades, err = fb.GetAllTrades(c.Request.Context())
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get trades",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		board := analytics.BuildSymbolLeaderboard(trades, startTime, minTrades, sortBy, order == "asc")
+		board.Period = period
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("%d symbols ranked", len(board.Symbols)),
+			Data:      board,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// TaxReportHandler - Generate a yearly tax/accounting statement
+// @Summary      Get tax report
+// @Description  Per-year realized PnL, funding and fee totals grouped by month and asset, from Binance income history and Firebase trades. Income history is account-wide; userId only filters the Firebase trade counts.
+// @Tags         Analytics
+// @Produce      json
+// @Produce      text/csv
+// @Security     ApiKeyAuth
+// @Param        year    query     int     false  "Calendar year, UTC (default: current year)" example(2024)
+// @Param        format  query     string  false  "json or csv (default: json)" example("csv")
+// @Param        userId  query     string  false  "Filter Firebase trades by user (optional)"
+// @Success      200     {object}  models.TradeResponse{data=analytics.TaxReport}  "Tax report generated"
+// @Failure      400     {object}  models.TradeResponse  "Invalid parameters"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      500     {object}  models.TradeResponse  "Failed to generate tax report"
+// @Router       /api/reports/tax [get]
+func TaxReportHandler(fb storage.TradeStore, bn *binance.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now().UTC()
+		year, err := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(now.Year())))
+		format := c.DefaultQuery("format", "json")
+		userID := c.Query("userId")
+
+		// Admins see every user unless they pick one; others only themselves
+		if c.GetString(authRoleKey) != models.RoleAdmin && !claimOwnership(c, &userID) {
+			return
+		}
+
+		if err != nil || year < 2019 || year > now.Year() {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     "year must be between 2019 and the current year",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if format != "json" && format != "csv" {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     "format must be json or csv",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		startTime := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+		endTime := time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC).Unix() - 1
+		if endTime > now.Unix() {
+			endTime = now.Unix()
+		}
+
+		// Binance income covers the whole account; a single user's report
+		// has only their recorded trades
+		var incomes []*models.IncomeRecord
+		if userID == "" {
+			incomes, err = bn.GetIncomeRecordsRange(c.Request.Context(), "", startTime, endTime)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, models.TradeResponse{
+					Success:   false,
+					Message:   "Failed to get income history",
+					Error:     err.Error(),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+		}
+
+		var trades []*models.Trade
+		if userID != "" {
+			trades, err = fb.GetUserTrades(c.Request.Context(), userID)
+		} else {
+			trades, err = fb.GetAllTrades(c.Request.Context())
+		}
+
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get trades",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		report := analytics.BuildTaxReport(year, incomes, trades)
+		report.UserID = userID
+
+		if format == "csv" {
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=tax-report-%d.csv", year))
+			c.Header("Content-Type", "text/csv")
+			c.Status(http.StatusOK)
+			if err := report.WriteCSV(c.Writer); err != nil {
+				logging.Ctx(c.Request.Context()).Warn().Err(err).Msg("Failed to write tax report CSV")
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Tax report generated successfully",
+			Data:      report,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// MonteCarloHandler - Simulate trade outcomes by resampling closed-trade returns
+// @Summary      Get Monte Carlo simulation
+// @Description  Resample historical closed-trade returns (net PnL / margin) into random trade sequences to estimate probability of ruin and drawdown percentiles at the current position sizing
+// @Tags         Analytics
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId       query     string  false  "Filter trades by user (optional)"
+// @Param        simulations  query     int     false  "Number of simulated sequences (default: 1000, max: 10000)" example(1000)
+// @Param        trades       query     int     false  "Trades per sequence (default: 100, max: 1000)" example(100)
+// @Param        ruin         query     number  false  "Drawdown from start counted as ruin (default: 0.5)" example(0.5)
+// @Param        size         query     number  false  "Margin per trade in USDT (default: average of recent trades)" example(100)
+// @Param        equity       query     number  false  "Account equity in USDT (default: Binance margin balance)" example(5000)
+// @Success      200          {object}  models.TradeResponse{data=analytics.MonteCarloReport}  "Simulation completed"
+// @Failure      400          {object}  models.TradeResponse  "Invalid parameters or not enough closed trades"
+// @Failure      401          {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      500          {object}  models.TradeResponse  "Simulation failed"
+// @Router       /api/analytics/montecarlo [get]
+func MonteCarloHandler(fb storage.TradeStore, bn *binance.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Query("userId")
+		simulations, _ := strconv.Atoi(c.DefaultQuery("simulations", "1000"))
+		tradeCount, _ := strconv.Atoi(c.DefaultQuery("trades", "100"))
+		ruin, _ := strconv.ParseFloat(c.DefaultQuery("ruin", "0.5"), 64)
+		size, _ := strconv.ParseFloat(c.Query("size"), 64)
+		equity, _ := strconv.ParseFloat(c.Query("equity"), 64)
+
+		// Admins see every user unless they pick one; others only themselves
+		if c.GetString(authRoleKey) != models.RoleAdmin && !claimOwnership(c, &userID) {
+			return
+		}
+
+		if simulations < 1 || simulations > 10000 || tradeCount < 1 || tradeCount > 1000 || ruin <= 0 || ruin > 1 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     "simulations must be 1-10000, trades 1-1000 and ruin between 0 and 1",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		var trades []*models.Trade
+		var err error
+
+		if userID != "" {
+			trades, err = fb.GetUserTrades(c.Request.Context(), userID)
+		} else {
+			trades, err = fb.GetAllTrades(c.Request.Context())
+		}
+
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get trades",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		// Oldest first so the sizing default reflects the most recent trades
+		sort.Slice(trades, func(i, j int) bool { return trades[i].ClosedAt < trades[j].ClosedAt })
+
+		returns := []float64{}
+		sizes := []float64{}
+		for _, trade := range trades {
+			if trade.Status != "CLOSED" || trade.Size <= 0 {
+				continue
+			}
+			returns = append(returns, (trade.PnL-trade.Commission)/trade.Size)
+			sizes = append(sizes, trade.Size)
+		}
+
+		if len(returns) < 10 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Not enough closed trades",
+				Error:     fmt.Sprintf("need at least 10 closed trades, found %d", len(returns)),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if size <= 0 {
+			recent := sizes
+			if len(recent) > 20 {
+				recent = recent[len(recent)-20:]
+			}
+			size = analytics.Mean(recent)
+		}
+
+		if equity <= 0 {
+			account, err := bn.GetAccountInfo(c.Request.Context())
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, models.TradeResponse{
+					Success:   false,
+					Message:   "Failed to get account balance",
+					Error:     err.Error(),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+			equity = bn.CalculateBalance(c.Request.Context(), account).TotalMarginBalance
+		}
+
+		if equity <= 0 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     "account equity must be greater than 0",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		report, err := analytics.MonteCarlo(returns, analytics.MonteCarloConfig{
+			Simulations:  simulations,
+			Trades:       tradeCount,
+			SizeFraction: size / equity,
+			RuinLevel:    ruin,
+			Seed:         time.Now().UnixNano(),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Simulation failed",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Monte Carlo simulation completed",
+			Data:      report,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// BalanceHistoryHandler - Daily equity series from stored balance snapshots
+// @Summary      Get balance history
+// @Description  Daily futures account equity from Binance account snapshots stored by the balance history recorder (BALANCE_HISTORY_ENABLED), so the series reaches back further than Binance's 30-day snapshot window. One point per UTC day: days without a snapshot repeat the previous balance (filled=true), change is the equity change since the previous day and index the equity relative to the first day (= 100). Equity counts USD stablecoin assets (USDT, USDC, BUSD, FDUSD).
+// @Tags         Analytics
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        from  query     int  false  "Range start, Unix seconds (default: 90 days before to)"
+// @Param        to    query     int  false  "Range end, Unix seconds (default: now)"
+// @Success      200   {object}  models.TradeResponse{data=models.BalanceHistory}  "Balance history"
+// @Failure      400   {object}  models.TradeResponse  "Invalid parameters"
+// @Failure      401   {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403   {object}  models.TradeResponse  "Balance history is limited to admins and API keys"
+// @Failure      500   {object}  models.TradeResponse  "Failed to get balance history"
+// @Router       /api/analytics/balance-history [get]
+func BalanceHistoryHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Snapshots are of the operator account
+		if !requireAccountWide(c) {
+			return
+		}
+
+		to := time.Now().Unix()
+		var errFrom, errTo error
+		if c.Query("to") != "" {
+			to, errTo = strconv.ParseInt(c.Query("to"), 10, 64)
+		}
+		from := to - 90*24*60*60
+		if c.Query("from") != "" {
+			from, errFrom = strconv.ParseInt(c.Query("from"), 10, 64)
+		}
+		if errFrom != nil || errTo != nil || from > to {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     "from/to must be Unix seconds with from <= to",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		snapshots, err := fb.GetBalanceSnapshots(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get balance history",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		history := reports.BuildBalanceHistory(snapshots, time.Unix(from, 0).UTC(), time.Unix(to, 0).UTC())
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("%d days of balance history", len(history.Points)),
+			Data:      history,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/validation"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IssueAPIKeyHandler - Issue a managed API key
+// @Summary      Issue API key
+// @Description  Issue an API key with scopes (read-only, trade, admin), an optional expiry and an optional per-key rate limit. The key is only returned in this response; Firebase stores its SHA-256 hash.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      models.APIKeyRequest  true  "Key settings"
+// @Success      200      {object}  models.TradeResponse{data=models.IssuedAPIKey}  "Key issued"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403      {object}  models.TradeResponse  "Admin scope required"
+// @Failure      500      {object}  models.TradeResponse  "Failed to issue key"
+// @Router       /api/admin/apikeys [post]
+func IssueAPIKeyHandler(keys *APIKeyManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.APIKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if err := validateScopes(req.Scopes); err != nil {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid scopes",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		if req.ExpiresIn != "" {
+			if ttl, err := time.ParseDuration(req.ExpiresIn); err != nil || ttl <= 0 {
+				c.JSON(http.StatusBadRequest, models.TradeResponse{
+					Success:   false,
+					Message:   "Invalid expiresIn",
+					Error:     "expiresIn must be a positive duration such as 720h",
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+		}
+
+		plaintext, key, err := keys.Issue(c.Request.Context(), &req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to issue key",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		withholdResponse(c)
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "API key issued. Store it now, it cannot be retrieved again.",
+			Data:      models.IssuedAPIKey{Key: plaintext, APIKey: key},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// ListAPIKeysHandler - List managed API keys
+// @Summary      List API keys
+// @Description  List every managed API key (including revoked and expired ones) without the key values
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.TradeResponse{data=[]models.APIKey}  "Keys retrieved"
+// @Failure      401  {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403  {object}  models.TradeResponse  "Admin scope required"
+// @Failure      500  {object}  models.TradeResponse  "Failed to list keys"
+// @Router       /api/admin/apikeys [get]
+func ListAPIKeysHandler(keys *APIKeyManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := keys.List(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to list keys",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "API keys retrieved",
+			Data:      list,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// RotateAPIKeyHandler - Replace an API key
+// @Summary      Rotate API key
+// @Description  Issue a replacement key with the same name, scopes, rate limit and expiry. The old key is revoked immediately, or keeps working for gracePeriod so clients can switch over.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        keyId    path      string                      true   "API key ID"
+// @Param        request  body      models.APIKeyRotateRequest  false  "Rotation options"
+// @Success      200      {object}  models.TradeResponse{data=models.IssuedAPIKey}  "Key rotated"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request or key revoked"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403      {object}  models.TradeResponse  "Admin scope required"
+// @Failure      404      {object}  models.TradeResponse  "Key not found"
+// @Router       /api/admin/apikeys/{keyId}/rotate [post]
+func RotateAPIKeyHandler(keys *APIKeyManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.APIKeyRotateRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				err = validation.FromBinding(err)
+				c.JSON(http.StatusBadRequest, models.TradeResponse{
+					Success:   false,
+					Message:   "Invalid request",
+					Error:     err.Error(),
+					Details:   validation.Details(err),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+		}
+
+		var grace time.Duration
+		if req.GracePeriod != "" {
+			var err error
+			grace, err = time.ParseDuration(req.GracePeriod)
+			if err != nil || grace < 0 {
+				c.JSON(http.StatusBadRequest, models.TradeResponse{
+					Success:   false,
+					Message:   "Invalid gracePeriod",
+					Error:     "gracePeriod must be a duration such as 1h",
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+		}
+
+		plaintext, key, err := keys.Rotate(c.Request.Context(), c.Param("keyId"), grace)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to rotate key",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		if key == nil {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "API key not found",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		withholdResponse(c)
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "API key rotated. Store the new key now, it cannot be retrieved again.",
+			Data:      models.IssuedAPIKey{Key: plaintext, APIKey: key},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// RevokeAPIKeyHandler - Revoke an API key
+// @Summary      Revoke API key
+// @Description  Disable an API key immediately. The record is kept for auditing.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        keyId  path      string  true  "API key ID"
+// @Success      200    {object}  models.TradeResponse{data=models.APIKey}  "Key revoked"
+// @Failure      401    {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403    {object}  models.TradeResponse  "Admin scope required"
+// @Failure      404    {object}  models.TradeResponse  "Key not found"
+// @Failure      500    {object}  models.TradeResponse  "Failed to revoke key"
+// @Router       /api/admin/apikeys/{keyId} [delete]
+func RevokeAPIKeyHandler(keys *APIKeyManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := keys.Revoke(c.Request.Context(), c.Param("keyId"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to revoke key",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		if key == nil {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "API key not found",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "API key revoked",
+			Data:      key,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
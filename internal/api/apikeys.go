@@ -0,0 +1,321 @@
+package api
+
+import (
+	"context"
+	"crypto-trading-api/internal/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	apiKeyPrefix   = "tk_"
+	apiKeyCacheTTL = 30 * time.Second // Revocations on other instances apply within this window
+
+	// masterKeyID identifies requests authenticated with the static API_KEY
+	masterKeyID = "master"
+
+	// Request context keys set by AuthMiddleware
+	authKeyIDKey  = "authKeyID"
+	authScopesKey = "authScopes"
+	authUserIDKey = "authUserID" // Set for JWT-authenticated requests
+	authRoleKey   = "authRole"   // Role of the JWT-authenticated user
+)
+
+// APIKeyStore persists managed API keys
+type APIKeyStore interface {
+	SaveAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKey(ctx context.Context, keyID string) (*models.APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]*models.APIKey, error)
+}
+
+// APIKeyManager issues, rotates, revokes and authenticates managed API keys.
+// Keys look like tk_<id>_<secret>; only their SHA-256 hash is stored. The
+// static API_KEY stays valid as a bootstrap admin key.
+type APIKeyManager struct {
+	store     APIKeyStore
+	masterKey string
+	cache     map[string]cachedAPIKey
+	mu        sync.Mutex
+}
+
+type cachedAPIKey struct {
+	key      *models.APIKey
+	loadedAt time.Time
+}
+
+// NewAPIKeyManager creates an API key manager
+func NewAPIKeyManager(store APIKeyStore, masterKey string) *APIKeyManager {
+	return &APIKeyManager{
+		store:     store,
+		masterKey: masterKey,
+		cache:     make(map[string]cachedAPIKey),
+	}
+}
+
+// SetMasterKey replaces the static API_KEY (secret rotation). The old key
+// stops working immediately.
+func (m *APIKeyManager) SetMasterKey(masterKey string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.masterKey = masterKey
+}
+
+// Issue creates a new key and returns its plaintext value (shown only once)
+func (m *APIKeyManager) Issue(ctx context.Context, req *models.APIKeyRequest) (string, *models.APIKey, error) {
+	if err := validateScopes(req.Scopes); err != nil {
+		return "", nil, err
+	}
+
+	var expiresAt int64
+	if req.ExpiresIn != "" {
+		ttl, err := time.ParseDuration(req.ExpiresIn)
+		if err != nil || ttl <= 0 {
+			return "", nil, fmt.Errorf("expiresIn must be a positive duration such as 720h")
+		}
+		expiresAt = time.Now().Add(ttl).Unix()
+	}
+
+	return m.issue(ctx, &models.APIKey{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		RateLimit: req.RateLimit,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// Rotate issues a replacement key with the same settings and retires the old
+// one after gracePeriod (immediately when zero). It returns nil when the key
+// does not exist.
+func (m *APIKeyManager) Rotate(ctx context.Context, keyID string, gracePeriod time.Duration) (string, *models.APIKey, error) {
+	old, err := m.store.GetAPIKey(ctx, keyID)
+	if err != nil || old == nil {
+		return "", nil, err
+	}
+	if old.RevokedAt > 0 {
+		return "", nil, fmt.Errorf("api key %s is revoked", keyID)
+	}
+
+	// The replacement keeps the remaining lifetime of the old key
+	plaintext, replacement, err := m.issue(ctx, &models.APIKey{
+		Name:      old.Name,
+		Scopes:    old.Scopes,
+		RateLimit: old.RateLimit,
+		ExpiresAt: old.ExpiresAt,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	old.RotatedTo = replacement.ID
+	retireAt := time.Now().Add(gracePeriod).Unix()
+	if gracePeriod <= 0 {
+		old.RevokedAt = time.Now().Unix()
+	} else if old.ExpiresAt == 0 || old.ExpiresAt > retireAt {
+		old.ExpiresAt = retireAt
+	}
+	if err := m.save(ctx, old); err != nil {
+		return "", nil, err
+	}
+
+	return plaintext, replacement, nil
+}
+
+// Revoke disables a key immediately. It returns nil when the key does not exist.
+func (m *APIKeyManager) Revoke(ctx context.Context, keyID string) (*models.APIKey, error) {
+	key, err := m.store.GetAPIKey(ctx, keyID)
+	if err != nil || key == nil {
+		return nil, err
+	}
+
+	if key.RevokedAt == 0 {
+		key.RevokedAt = time.Now().Unix()
+		if err := m.save(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+
+	return redactAPIKey(key), nil
+}
+
+// List returns every key record without hashes
+func (m *APIKeyManager) List(ctx context.Context) ([]*models.APIKey, error) {
+	keys, err := m.store.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := make([]*models.APIKey, 0, len(keys))
+	for _, key := range keys {
+		redacted = append(redacted, redactAPIKey(key))
+	}
+	return redacted, nil
+}
+
+// Authenticate resolves a presented key to its record, rejecting unknown,
+// revoked and expired keys
+func (m *APIKeyManager) Authenticate(ctx context.Context, presented string) (*models.APIKey, error) {
+	m.mu.Lock()
+	masterKey := m.masterKey
+	m.mu.Unlock()
+
+	if subtle.ConstantTimeCompare([]byte(presented), []byte(masterKey)) == 1 {
+		return &models.APIKey{ID: masterKeyID, Name: "API_KEY", Scopes: []string{models.ScopeAdmin}}, nil
+	}
+
+	keyID, ok := parseAPIKeyID(presented)
+	if !ok {
+		return nil, fmt.Errorf("the provided API key is invalid")
+	}
+
+	key, err := m.lookup(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || subtle.ConstantTimeCompare([]byte(hashAPIKey(presented)), []byte(key.KeyHash)) != 1 {
+		return nil, fmt.Errorf("the provided API key is invalid")
+	}
+	if key.RevokedAt > 0 {
+		return nil, fmt.Errorf("the provided API key has been revoked")
+	}
+	if key.ExpiresAt > 0 && time.Now().Unix() >= key.ExpiresAt {
+		return nil, fmt.Errorf("the provided API key has expired")
+	}
+
+	return key, nil
+}
+
+// issue generates the secret for a new key record and stores it
+func (m *APIKeyManager) issue(ctx context.Context, key *models.APIKey) (string, *models.APIKey, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	plaintext := apiKeyPrefix + id + "_" + secret
+	key.ID = id
+	key.Prefix = apiKeyPrefix + id
+	key.KeyHash = hashAPIKey(plaintext)
+	key.CreatedAt = time.Now().Unix()
+
+	if err := m.save(ctx, key); err != nil {
+		return "", nil, err
+	}
+
+	return plaintext, redactAPIKey(key), nil
+}
+
+// save stores a key and refreshes the local cache
+func (m *APIKeyManager) save(ctx context.Context, key *models.APIKey) error {
+	if err := m.store.SaveAPIKey(ctx, key); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.cache[key.ID] = cachedAPIKey{key: key, loadedAt: time.Now()}
+	m.mu.Unlock()
+	return nil
+}
+
+// lookup loads a key record, caching it briefly to spare Firebase a read per request
+func (m *APIKeyManager) lookup(ctx context.Context, keyID string) (*models.APIKey, error) {
+	m.mu.Lock()
+	cached, ok := m.cache[keyID]
+	m.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < apiKeyCacheTTL {
+		return cached.key, nil
+	}
+
+	key, err := m.store.GetAPIKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.cache[keyID] = cachedAPIKey{key: key, loadedAt: time.Now()}
+	m.mu.Unlock()
+	return key, nil
+}
+
+// adminRoutes are system and account-wide endpoints outside /api/admin that
+// need the admin scope
+var adminRoutes = map[string]bool{
+	"/api/status":             true,
+	"/api/orders/cancel":      true,
+	"/api/websocket/start":    true,
+	"/api/system/time":        true,
+	"/api/system/server-time": true,
+}
+
+// scopeAllows reports whether scopes grant access to a route: GET needs
+// read-only, other methods need trade, /api/admin and adminRoutes need admin
+func scopeAllows(scopes []string, method, path string) bool {
+	required := models.ScopeTrade
+	if strings.HasPrefix(path, "/api/admin/") || adminRoutes[path] {
+		required = models.ScopeAdmin
+	} else if method == http.MethodGet {
+		required = models.ScopeReadOnly
+	}
+
+	for _, scope := range scopes {
+		if scope == models.ScopeAdmin || scope == required ||
+			(required == models.ScopeReadOnly && scope == models.ScopeTrade) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateScopes checks requested scopes against the known ones
+func validateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		switch scope {
+		case models.ScopeReadOnly, models.ScopeTrade, models.ScopeAdmin:
+		default:
+			return fmt.Errorf("unknown scope %q (expected %s, %s or %s)", scope, models.ScopeReadOnly, models.ScopeTrade, models.ScopeAdmin)
+		}
+	}
+	return nil
+}
+
+// parseAPIKeyID extracts the ID from a tk_<id>_<secret> key
+func parseAPIKeyID(presented string) (string, bool) {
+	if !strings.HasPrefix(presented, apiKeyPrefix) {
+		return "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(presented, apiKeyPrefix), "_", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", false
+	}
+	return parts[0], true
+}
+
+// redactAPIKey returns a copy of the record without its hash
+func redactAPIKey(key *models.APIKey) *models.APIKey {
+	redacted := *key
+	redacted.KeyHash = ""
+	return &redacted
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random key: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
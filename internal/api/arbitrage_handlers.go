@@ -0,0 +1,363 @@
+package api
+
+import (
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/policy"
+	"crypto-trading-api/internal/storage"
+	"crypto-trading-api/internal/validation"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FundingScreenerHandler - Rank every perpetual by funding
+// @Summary      Funding rate screener
+// @Description  Rank every perpetual by its current funding rate, its predicted rate (estimated from the mark/index premium with Binance's funding formula) or its annualized rate (which accounts for 4h/1h funding intervals), largest absolute first. Filter by 24h quote volume, minimum absolute rate and sign. The funding arbitrage bot picks its pairs from the same data.
+// @Tags         Funding
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        sort       query     string  false  "Rank by rate, predicted or annualized (default: rate)"
+// @Param        minVolume  query     number  false  "Minimum 24h quote volume, e.g. 10000000 (default: 0)"
+// @Param        minRate    query     number  false  "Minimum absolute rate of the ranked rate per period, e.g. 0.0001 = 0.01% (default: 0)"
+// @Param        side       query     string  false  "positive or negative rates only (default: both)"
+// @Param        limit      query     int     false  "Maximum results (default 50, 0 = all)"
+// @Success      200        {object}  models.TradeResponse{data=[]binance.FundingScreenEntry}  "Perpetuals ranked"
+// @Failure      400        {object}  models.TradeResponse  "Invalid parameters"
+// @Failure      401        {object}  models.TradeResponse  "Unauthorized"
+// @Failure      500        {object}  models.TradeResponse  "Failed to screen funding rates"
+// @Router       /api/funding/screener [get]
+func FundingScreenerHandler(bn *binance.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := binance.FundingScreenFilter{SortBy: c.DefaultQuery("sort", binance.FundingSortRate)}
+		var errVolume, errRate, errLimit error
+		filter.MinQuoteVolume, errVolume = strconv.ParseFloat(c.DefaultQuery("minVolume", "0"), 64)
+		filter.MinRate, errRate = strconv.ParseFloat(c.DefaultQuery("minRate", "0"), 64)
+		filter.Limit, errLimit = strconv.Atoi(c.DefaultQuery("limit", "50"))
+		switch strings.ToLower(c.Query("side")) {
+		case "positive":
+			filter.Sign = 1
+		case "negative":
+			filter.Sign = -1
+		case "":
+		default:
+			errRate = fmt.Errorf("invalid side")
+		}
+
+		validSort := filter.SortBy == binance.FundingSortRate || filter.SortBy == binance.FundingSortPredicted || filter.SortBy == binance.FundingSortAnnualized
+		if !validSort || errVolume != nil || errRate != nil || errLimit != nil || filter.MinQuoteVolume < 0 || filter.MinRate < 0 || filter.Limit < 0 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     "sort must be rate, predicted or annualized, side positive or negative, and minVolume, minRate and limit non-negative numbers",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		entries, err := bn.ScreenFunding(c.Request.Context(), filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to screen funding rates",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("%d perpetuals ranked", len(entries)),
+			Data:      entries,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// FundingOpportunitiesHandler - Find symbols with extreme funding rates
+// @Summary      Funding rate opportunities
+// @Description  Scan every perpetual for funding rates whose absolute value is at least minRate, most extreme first. Positive rates can be harvested with long spot + short perp (LONG_SPOT_SHORT_PERP) when a spot market exists. Perpetuals below FUNDING_ARB_MIN_VOLUME (24h quote volume) are left out.
+// @Tags         Funding
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        minRate  query     number  false  "Minimum absolute funding rate per period (default 0.0005 = 0.05%)"
+// @Param        limit    query     int     false  "Maximum results (default 20)"
+// @Success      200      {object}  models.TradeResponse{data=[]binance.FundingOpportunity}  "Opportunities retrieved"
+// @Failure      400      {object}  models.TradeResponse  "Invalid parameters"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized"
+// @Failure      500      {object}  models.TradeResponse  "Failed to scan funding rates"
+// @Router       /api/funding/opportunities [get]
+func FundingOpportunitiesHandler(arb *binance.FundingArbitrage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		minRate, err := strconv.ParseFloat(c.DefaultQuery("minRate", "0.0005"), 64)
+		if err != nil || minRate < 0 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid minRate parameter",
+				Error:     "minRate must be a non-negative number",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid limit parameter",
+				Error:     "limit must be a positive integer",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		opportunities, err := arb.Opportunities(c.Request.Context(), minRate, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to scan funding rates",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Funding opportunities retrieved successfully",
+			Data:      opportunities,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// OpenFundingArbHandler - Open a delta-neutral funding arbitrage pair
+// @Summary      Open funding arbitrage
+// @Description  Buy notional USDT of spot and short the same quantity on the perpetual to collect positive funding. Both legs are tracked as one group; if the perp leg fails the spot leg is sold back and the group is marked FAILED.
+// @Tags         Funding
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      models.FundingArbRequest  true  "Funding arbitrage parameters"
+// @Success      200      {object}  models.TradeResponse{data=models.FundingArbGroup}  "Pair opened"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request or funding not positive"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403      {object}  models.TradeResponse  "Symbol not allowed"
+// @Failure      500      {object}  models.TradeResponse  "Failed to open pair"
+// @Failure      503      {object}  models.TradeResponse  "Trading paused"
+// @Router       /api/funding/arbitrage [post]
+func OpenFundingArbHandler(arb *binance.FundingArbitrage, symbols *policy.SymbolPolicy, pause *policy.TradingPause) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.FundingArbRequest
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !requireOwner(c, req.UserID) {
+			return
+		}
+		req.Symbol = strings.ToUpper(req.Symbol)
+
+		if err := pause.Check(); err != nil {
+			c.JSON(http.StatusServiceUnavailable, models.TradeResponse{
+				Success:   false,
+				Message:   "Trading paused",
+				Error:     err.Error(),
+				ErrorCode: models.ErrTradingPaused,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if err := symbols.Check(req.Symbol); err != nil {
+			c.JSON(http.StatusForbidden, models.TradeResponse{
+				Success:   false,
+				Message:   "Symbol not allowed",
+				Error:     err.Error(),
+				ErrorCode: models.ErrSymbolNotAllowed,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		group, err := arb.Open(c.Request.Context(), req.UserID, req.Symbol, req.Notional, req.Leverage, "api")
+		if err != nil {
+			// No group means the request was refused before any order was placed
+			status := http.StatusBadRequest
+			if group != nil {
+				status = http.StatusInternalServerError
+			}
+			c.JSON(status, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to open funding arbitrage",
+				Data:      group,
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Funding arbitrage opened successfully",
+			Data:      group,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// ListFundingArbHandler - List funding arbitrage groups
+// @Summary      List funding arbitrage
+// @Description  List a user's funding arbitrage groups (both legs and funding collected so far), newest first
+// @Tags         Funding
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  query     string  true   "User ID"
+// @Param        status  query     string  false  "Filter by status (OPEN, CLOSED, FAILED)"
+// @Success      200     {object}  models.TradeResponse{data=[]models.FundingArbGroup}  "Groups retrieved"
+// @Failure      400     {object}  models.TradeResponse  "Missing userId parameter"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get groups"
+// @Router       /api/funding/arbitrage [get]
+func ListFundingArbHandler(arb *binance.FundingArbitrage, fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Query("userId")
+		if !claimOwnership(c, &userID) {
+			return
+		}
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "userId parameter is required",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		status := strings.ToUpper(c.Query("status"))
+
+		all, err := fb.GetFundingArbGroups(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get funding arbitrage groups",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		groups := []*models.FundingArbGroup{}
+		for _, group := range all {
+			if group.UserID != userID || (status != "" && group.Status != status) {
+				continue
+			}
+			if group.Status == models.ArbStatusOpen {
+				arb.RefreshFunding(c.Request.Context(), group)
+			}
+			groups = append(groups, group)
+		}
+		sort.Slice(groups, func(i, j int) bool {
+			return groups[i].OpenedAt > groups[j].OpenedAt
+		})
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Funding arbitrage groups retrieved successfully",
+			Data:      groups,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// CloseFundingArbHandler - Close both legs of a funding arbitrage pair
+// @Summary      Close funding arbitrage
+// @Description  Buy back the perp leg and sell the spot leg of an OPEN group. If the spot sale fails the group stays OPEN with the error recorded and can be closed again (the perp leg is not repeated).
+// @Tags         Funding
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        groupId  path      string  true  "Funding arbitrage group ID"
+// @Success      200      {object}  models.TradeResponse{data=models.FundingArbGroup}  "Pair closed"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized"
+// @Failure      404      {object}  models.TradeResponse  "Group not found"
+// @Failure      409      {object}  models.TradeResponse  "Group is not open"
+// @Failure      500      {object}  models.TradeResponse  "Failed to close pair"
+// @Router       /api/funding/arbitrage/{groupId}/close [post]
+func CloseFundingArbHandler(arb *binance.FundingArbitrage, fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID := c.Param("groupId")
+
+		all, err := fb.GetFundingArbGroups(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get funding arbitrage groups",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		var group *models.FundingArbGroup
+		for _, g := range all {
+			if g.ID == groupID {
+				group = g
+				break
+			}
+		}
+		if group == nil {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "Funding arbitrage group not found",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !requireOwner(c, group.UserID) {
+			return
+		}
+
+		if group.Status != models.ArbStatusOpen {
+			c.JSON(http.StatusConflict, models.TradeResponse{
+				Success:   false,
+				Message:   "Funding arbitrage group is not open",
+				Error:     "group status is " + group.Status,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if err := arb.Close(c.Request.Context(), group); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to close funding arbitrage",
+				Data:      group,
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Funding arbitrage closed successfully",
+			Data:      group,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
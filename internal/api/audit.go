@@ -0,0 +1,243 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/storage"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxAuditResponse caps how much of a response is kept to read its outcome
+const maxAuditResponse = 64 * 1024
+
+// AuditStore persists audit log entries
+type AuditStore interface {
+	SaveAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+}
+
+// AuditMiddleware records every state-changing request (anything but GET,
+// HEAD and OPTIONS): who made it, the SHA-256 of its body, the response
+// status and the trade/order it produced. Entries are written in the
+// background so Firebase latency never delays the response.
+func AuditMiddleware(store AuditStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		var body []byte
+		if c.Request.Body != nil {
+			body, _ = io.ReadAll(c.Request.Body)
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+		}
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		entry := &models.AuditEntry{
+			ID:          auditEntryID(start),
+			Timestamp:   start.Unix(),
+			Actor:       auditActor(c),
+			Method:      c.Request.Method,
+			Route:       c.FullPath(),
+			URL:         c.Request.URL.RequestURI(),
+			ClientIP:    c.ClientIP(),
+			UserAgent:   c.Request.UserAgent(),
+			PayloadSize: len(body),
+			Status:      writer.Status(),
+			Success:     writer.Status() < http.StatusBadRequest,
+			DurationMs:  time.Since(start).Milliseconds(),
+		}
+		if len(body) > 0 {
+			sum := sha256.Sum256(body)
+			entry.PayloadHash = hex.EncodeToString(sum[:])
+		}
+		readAuditOutcome(writer.body.Bytes(), entry)
+
+		go func() {
+			if err := store.SaveAuditEntry(context.Background(), entry); err != nil {
+				logging.Warn().Err(err).Msgf("Audit: failed to record %s %s", entry.Method, entry.URL)
+			}
+		}()
+	}
+}
+
+// auditResponseWriter keeps a copy of the response body
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	if w.body.Len()+len(b) <= maxAuditResponse {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// auditActor describes who made the request
+func auditActor(c *gin.Context) string {
+	if userID := authenticatedUser(c); userID != "" {
+		return "user:" + userID
+	}
+	if keyID := c.GetString(authKeyIDKey); keyID != "" {
+		return "key:" + keyID
+	}
+	if c.GetBool(signatureVerifiedKey) {
+		return "signature"
+	}
+	return "anonymous"
+}
+
+// readAuditOutcome copies message, error, trade ID and order ID from a
+// TradeResponse body
+func readAuditOutcome(body []byte, entry *models.AuditEntry) {
+	var resp struct {
+		Message string          `json:"message"`
+		Error   string          `json:"error"`
+		TradeID string          `json:"tradeId"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return
+	}
+
+	entry.Message = resp.Message
+	entry.Error = resp.Error
+	entry.TradeID = resp.TradeID
+
+	// Trade data directly, or {trade, copies} for copy-traded orders
+	var data struct {
+		ID      string `json:"id"`
+		OrderID int64  `json:"orderId"`
+		Trade   *struct {
+			ID      string `json:"id"`
+			OrderID int64  `json:"orderId"`
+		} `json:"trade"`
+	}
+	if len(resp.Data) == 0 || json.Unmarshal(resp.Data, &data) != nil {
+		return
+	}
+	if data.Trade != nil {
+		data.ID, data.OrderID = data.Trade.ID, data.Trade.OrderID
+	}
+	if entry.TradeID == "" {
+		entry.TradeID = data.ID
+	}
+	entry.OrderID = data.OrderID
+}
+
+// auditEntryID starts with the zero-padded Unix nanosecond time so that
+// Firebase key order is chronological
+func auditEntryID(t time.Time) string {
+	return fmt.Sprintf("%019d-%s", t.UnixNano(), uuid.New().String()[:8])
+}
+
+// auditRangeID is the entry ID bound for a Unix time in seconds
+func auditRangeID(unix int64, end bool) string {
+	id := fmt.Sprintf("%019d", time.Unix(unix, 0).UnixNano())
+	if end {
+		id += "~" // Sorts after every suffix of the same nanosecond
+	}
+	return id
+}
+
+// AuditLogHandler - Query the audit log
+// @Summary      Query audit log
+// @Description  List recorded state-changing API calls (who, what, when, payload hash, result) in a time range, oldest first. Filter by actor, route, trade ID or order ID to trace which webhook produced which order.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        from     query     int     false  "Start time, Unix seconds (default: 24 hours ago)"
+// @Param        to       query     int     false  "End time, Unix seconds (default: now)"
+// @Param        limit    query     int     false  "Maximum entries read, newest kept (default 100, max 1000)"
+// @Param        actor    query     string  false  "Actor, e.g. key:3f9a1c0e7b2d4a65, user:user123, signature"
+// @Param        route    query     string  false  "Route, e.g. /api/trade"
+// @Param        tradeId  query     string  false  "Trade ID produced by the call"
+// @Param        orderId  query     int     false  "Binance order ID produced by the call"
+// @Success      200      {object}  models.TradeResponse{data=[]models.AuditEntry}  "Audit entries"
+// @Failure      400      {object}  models.TradeResponse  "Invalid parameters"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403      {object}  models.TradeResponse  "Admin access required"
+// @Failure      500      {object}  models.TradeResponse  "Failed to read audit log"
+// @Router       /api/admin/audit [get]
+func AuditLogHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now().Unix()
+		from, errFrom := strconv.ParseInt(c.DefaultQuery("from", strconv.FormatInt(now-24*60*60, 10)), 10, 64)
+		to, errTo := strconv.ParseInt(c.DefaultQuery("to", strconv.FormatInt(now, 10)), 10, 64)
+		limit, errLimit := strconv.Atoi(c.DefaultQuery("limit", "100"))
+		var orderID int64
+		var errOrder error
+		if c.Query("orderId") != "" {
+			orderID, errOrder = strconv.ParseInt(c.Query("orderId"), 10, 64)
+		}
+		if errFrom != nil || errTo != nil || errLimit != nil || errOrder != nil || from > to || limit < 1 || limit > 1000 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     "from/to must be Unix seconds with from <= to, limit 1-1000, orderId numeric",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		entries, err := fb.GetAuditEntries(c.Request.Context(), auditRangeID(from, false), auditRangeID(to, true), limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to read audit log",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		actor := c.Query("actor")
+		route := c.Query("route")
+		tradeID := c.Query("tradeId")
+
+		filtered := make([]*models.AuditEntry, 0, len(entries))
+		for _, entry := range entries {
+			if actor != "" && entry.Actor != actor {
+				continue
+			}
+			if route != "" && !strings.EqualFold(entry.Route, route) {
+				continue
+			}
+			if tradeID != "" && entry.TradeID != tradeID {
+				continue
+			}
+			if orderID != 0 && entry.OrderID != orderID {
+				continue
+			}
+			filtered = append(filtered, entry)
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("%d audit entries", len(filtered)),
+			Data:      filtered,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
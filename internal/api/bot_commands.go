@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/bot"
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/notifications"
+	"crypto-trading-api/internal/policy"
+	"crypto-trading-api/internal/storage"
+	"fmt"
+	"strings"
+)
+
+// RegisterBotCommands wires Telegram bot commands to the same logic the REST handlers use
+func RegisterBotCommands(tg *bot.TelegramBot, fb storage.TradeStore, bn *binance.Client, pause *policy.TradingPause, notifier *notifications.Notifier, bus *events.Bus) {
+	tg.Handle("/positions", "List open positions", func(ctx context.Context, chatID int64, args []string) (string, error) {
+		positions, err := bn.GetOpenPositions(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get positions: %v", err)
+		}
+		if len(positions) == 0 {
+			return "No open positions.", nil
+		}
+
+		var sb strings.Builder
+		for _, pos := range positions {
+			side := "LONG"
+			if pos.PositionAmt < 0 {
+				side = "SHORT"
+			}
+			fmt.Fprintf(&sb, "%s %s %g @ %.4f | mark %.4f | uPnL %.2f | x%d\n",
+				pos.Symbol, side, pos.PositionAmt, pos.EntryPrice, pos.MarkPrice, pos.UnrealizedProfit, pos.Leverage)
+		}
+		return strings.TrimSpace(sb.String()), nil
+	})
+
+	tg.Handle("/balance", "Show account balance", func(ctx context.Context, chatID int64, args []string) (string, error) {
+		account, err := bn.GetAccountInfo(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get account balance: %v", err)
+		}
+		balance := bn.CalculateBalance(ctx, account)
+
+		return fmt.Sprintf("Wallet: %.2f USDT\nMargin balance: %.2f\nAvailable: %.2f\nUnrealized PnL: %.2f",
+			balance.TotalBalance, balance.TotalMarginBalance, balance.AvailableBalance, balance.TotalUnrealizedPnL), nil
+	})
+
+	tg.Handle("/close", "Close a position: /close BTCUSDT [tradeId]", func(ctx context.Context, chatID int64, args []string) (string, error) {
+		if len(args) == 0 {
+			return "", fmt.Errorf("usage: /close SYMBOL [tradeId]")
+		}
+
+		symbol := strings.ToUpper(args[0])
+		tradeID := ""
+		if len(args) > 1 {
+			tradeID = args[1]
+		}
+
+		result, err := ClosePosition(ctx, bn, fb, bus, symbol, tradeID)
+		if err != nil {
+			return "", fmt.Errorf("failed to close position: %v", err)
+		}
+		return fmt.Sprintf("✅ %s closed (%s %s), realized PnL %.2f", result.Symbol, result.Side, result.Quantity, result.RealizedProfit), nil
+	})
+
+	tg.Handle("/pause", "Pause new trade intake: /pause [reason]", func(ctx context.Context, chatID int64, args []string) (string, error) {
+		reason := strings.Join(args, " ")
+		if reason == "" {
+			reason = "paused from Telegram"
+		}
+
+		pause.Pause(reason, fmt.Sprintf("telegram:%d", chatID))
+		notifier.Publish(notifications.KillSwitch(true, reason))
+		return "⏸️ Trading paused: " + reason, nil
+	})
+
+	tg.Handle("/resume", "Resume trade intake", func(ctx context.Context, chatID int64, args []string) (string, error) {
+		if !pause.Status().Paused {
+			return "Trading is not paused.", nil
+		}
+
+		pause.Resume()
+		notifier.Publish(notifications.KillSwitch(false, fmt.Sprintf("resumed from Telegram chat %d", chatID)))
+		return "▶️ Trading resumed.", nil
+	})
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"crypto-trading-api/internal/calendar"
+	"crypto-trading-api/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpcomingEventsHandler - List upcoming economic calendar events
+// @Summary      Upcoming economic events
+// @Description  Economic calendar releases from now until `hours` ahead (default 24, at most 168), at `impact` or above (default LOW: all), optionally for one currency. Events at the blackout impact carry their blackout window; activeBlackout is the event whose window covers now. During blackouts new entries are refused when blockEntries is set, and for presets whose tradingHours.newsBlackout is set.
+// @Tags         Calendar
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        hours     query     int     false  "Hours ahead (default 24, max 168)"
+// @Param        impact    query     string  false  "Lowest impact: LOW, MEDIUM or HIGH (default LOW)"
+// @Param        currency  query     string  false  "Currency, e.g. USD"
+// @Success      200       {object}  models.TradeResponse{data=calendar.Upcoming}  "Upcoming events"
+// @Failure      400       {object}  models.TradeResponse  "Invalid parameters"
+// @Failure      401       {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      501       {object}  models.TradeResponse  "Economic calendar not enabled"
+// @Router       /api/calendar/upcoming [get]
+func UpcomingEventsHandler(cal *calendar.Calendar) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cal == nil {
+			c.JSON(http.StatusNotImplemented, models.TradeResponse{
+				Success:   false,
+				Message:   "Economic calendar is not enabled",
+				Error:     "set CALENDAR_ENABLED=true to follow economic events",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		hours, err := strconv.Atoi(c.DefaultQuery("hours", "24"))
+		if err != nil || hours <= 0 || hours > 168 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid hours parameter",
+				Error:     "hours must be between 1 and 168",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		impact := strings.ToUpper(c.DefaultQuery("impact", models.ImpactLow))
+		if impact != models.ImpactLow && impact != models.ImpactMedium && impact != models.ImpactHigh {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid impact parameter",
+				Error:     "impact must be LOW, MEDIUM or HIGH",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		upcoming := cal.Upcoming(time.Now(), time.Duration(hours)*time.Hour, impact, strings.ToUpper(c.Query("currency")))
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Upcoming events retrieved successfully",
+			Data:      upcoming,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package api
+
+import (
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/storage"
+	"crypto-trading-api/internal/validation"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetBinanceKeysHandler - Store a user's own Binance API keys
+// @Summary      Set user Binance keys
+// @Description  Validate a user's Binance API keys against their account and store them AES-256-GCM encrypted. Trades submitted with this userId are then executed on the user's account.
+// @Tags         Credentials
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId   path      string                          true  "User ID"
+// @Param        request  body      models.UserCredentialsRequest  true  "Binance API key and secret"
+// @Success      200      {object}  models.TradeResponse{data=models.UserCredentialsStatus}  "Keys stored"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request or keys rejected by Binance"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized"
+// @Failure      500      {object}  models.TradeResponse  "Failed to store keys"
+// @Failure      501      {object}  models.TradeResponse  "Per-user keys not enabled"
+// @Router       /api/users/{userId}/binance-keys [put]
+func SetBinanceKeysHandler(clients *binance.ClientPool, fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireUserKeysEnabled(c, clients) {
+			return
+		}
+
+		userID := c.Param("userId")
+		if !requireOwner(c, userID) {
+			return
+		}
+
+		var req models.UserCredentialsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		// Reject keys Binance doesn't accept before storing them
+		if _, err := binance.NewAccountClient(req.APIKey, req.SecretKey); err != nil {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Binance rejected the API keys",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		creds, err := encryptCredentials(clients, userID, &req)
+		if err == nil {
+			if existing, getErr := fb.GetUserCredentials(c.Request.Context(), userID); getErr == nil && existing != nil {
+				creds.CreatedAt = existing.CreatedAt
+			}
+			err = fb.SaveUserCredentials(c.Request.Context(), creds)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to store keys",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		// Drop the cached client so the next trade uses the new keys
+		clients.Invalidate(userID)
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Binance keys stored",
+			Data:      credentialsStatus(userID, creds),
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// GetBinanceKeysHandler - Show whether a user has Binance keys stored
+// @Summary      Get user Binance key status
+// @Description  Report whether a user has their own Binance keys stored. Keys are never returned, only the last characters of the API key.
+// @Tags         Credentials
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  path      string  true  "User ID"
+// @Success      200     {object}  models.TradeResponse{data=models.UserCredentialsStatus}  "Key status"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get key status"
+// @Router       /api/users/{userId}/binance-keys [get]
+func GetBinanceKeysHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Param("userId")
+		if !requireOwner(c, userID) {
+			return
+		}
+
+		creds, err := fb.GetUserCredentials(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get key status",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Key status retrieved",
+			Data:      credentialsStatus(userID, creds),
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// DeleteBinanceKeysHandler - Remove a user's Binance API keys
+// @Summary      Delete user Binance keys
+// @Description  Remove a user's stored Binance keys. Their subsequent trades fall back to the operator account, or are rejected when REQUIRE_USER_KEYS is set.
+// @Tags         Credentials
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  path      string  true  "User ID"
+// @Success      200     {object}  models.TradeResponse  "Keys removed"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized"
+// @Failure      500     {object}  models.TradeResponse  "Failed to remove keys"
+// @Router       /api/users/{userId}/binance-keys [delete]
+func DeleteBinanceKeysHandler(clients *binance.ClientPool, fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Param("userId")
+		if !requireOwner(c, userID) {
+			return
+		}
+
+		if err := fb.DeleteUserCredentials(c.Request.Context(), userID); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to remove keys",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		clients.Invalidate(userID)
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Binance keys removed",
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// requireUserKeysEnabled responds 501 when no CREDENTIALS_MASTER_KEY is configured
+func requireUserKeysEnabled(c *gin.Context, clients *binance.ClientPool) bool {
+	if clients.Enabled() {
+		return true
+	}
+
+	c.JSON(http.StatusNotImplemented, models.TradeResponse{
+		Success:   false,
+		Message:   "Per-user Binance keys are not enabled",
+		Error:     "set CREDENTIALS_MASTER_KEY to store user keys",
+		Timestamp: time.Now().Unix(),
+	})
+	return false
+}
+
+// userClient resolves the Binance account for an optional userId, or the
+// named operator account when account is set. It writes a 400 response for
+// an unknown account and a 403 when the user has no account to trade on.
+func userClient(c *gin.Context, clients *binance.ClientPool, userID, account string) (*binance.Client, bool) {
+	client, err := clients.Resolve(c.Request.Context(), userID, account)
+	if errors.Is(err, binance.ErrUnknownAccount) {
+		c.JSON(http.StatusBadRequest, models.TradeResponse{
+			Success:   false,
+			Message:   "Unknown account",
+			Error:     err.Error(),
+			ErrorCode: models.ErrNoAccount,
+			Timestamp: time.Now().Unix(),
+		})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.TradeResponse{
+			Success:   false,
+			Message:   "No Binance account for user",
+			Error:     err.Error(),
+			Timestamp: time.Now().Unix(),
+		})
+		return nil, false
+	}
+	return client, true
+}
+
+// encryptCredentials builds the stored record for a user's keys
+func encryptCredentials(clients *binance.ClientPool, userID string, req *models.UserCredentialsRequest) (*models.UserCredentials, error) {
+	apiKeyEncrypted, err := clients.Cipher().Encrypt(req.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	secretEncrypted, err := clients.Cipher().Encrypt(req.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	return &models.UserCredentials{
+		UserID:          userID,
+		APIKeyEncrypted: apiKeyEncrypted,
+		SecretEncrypted: secretEncrypted,
+		APIKeyHint:      keyHint(req.APIKey),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}, nil
+}
+
+// credentialsStatus describes stored credentials without the keys themselves
+func credentialsStatus(userID string, creds *models.UserCredentials) *models.UserCredentialsStatus {
+	status := &models.UserCredentialsStatus{UserID: userID}
+	if creds != nil {
+		status.Configured = true
+		status.APIKeyHint = creds.APIKeyHint
+		status.UpdatedAt = creds.UpdatedAt
+	}
+	return status
+}
+
+// keyHint keeps the last four characters of an API key
+func keyHint(apiKey string) string {
+	if len(apiKey) <= 4 {
+		return "..."
+	}
+	return "..." + apiKey[len(apiKey)-4:]
+}
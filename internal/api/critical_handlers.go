@@ -3,6 +3,7 @@ package api
 import (
 	"crypto-trading-api/internal/binance"
 	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/validation"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,53 +11,28 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// Global WebSocket manager
-var wsManager *binance.WebSocketManager
-
-// InitWebSocketManager initializes the WebSocket manager
-func InitWebSocketManager(bn *binance.Client) {
-	wsManager = binance.NewWebSocketManager(bn)
-}
-
 // StartWebSocketHandler - Start WebSocket user data stream
 // @Summary      Start WebSocket user data stream
-// @Description  Start real-time WebSocket stream for order updates and account changes
+// @Description  Start the supervised real-time stream of order updates and account changes. The stream starts on boot unless USER_DATA_STREAM_ENABLED=false; this endpoint starts it afterwards and does nothing if it is already running.
 // @Tags         WebSocket
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Success      200  {object}  models.TradeResponse  "WebSocket started successfully"
 // @Failure      401  {object}  models.TradeResponse  "Unauthorized"
-// @Failure      500  {object}  models.TradeResponse  "Failed to start WebSocket"
 // @Router       /api/websocket/start [post]
-func StartWebSocketHandler(bn *binance.Client) gin.HandlerFunc {
+func StartWebSocketHandler(streams *binance.WebSocketManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if wsManager == nil {
-			InitWebSocketManager(bn)
-		}
-
-		// Start user data stream
-		err := wsManager.StartUserDataStream(
-			// Order update callback
-			func(event *binance.OrderUpdateEvent) {
-				// Log order updates
-				// In production, you might want to update Firebase here
-			},
-			// Account update callback
-			func(event *binance.AccountUpdateEvent) {
-				// Log account updates
-			},
-		)
-
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, models.TradeResponse{
-				Success:   false,
-				Message:   "Failed to start WebSocket stream",
-				Error:     err.Error(),
+		if streams.Running() {
+			c.JSON(http.StatusOK, models.TradeResponse{
+				Success:   true,
+				Message:   "WebSocket user data stream already running",
 				Timestamp: time.Now().Unix(),
 			})
 			return
 		}
 
+		streams.Start()
+
 		c.JSON(http.StatusOK, models.TradeResponse{
 			Success:   true,
 			Message:   "WebSocket user data stream started successfully",
@@ -67,31 +43,150 @@ func StartWebSocketHandler(bn *binance.Client) gin.HandlerFunc {
 
 // WebSocketStatusHandler - Get WebSocket connection status
 // @Summary      Get WebSocket status
-// @Description  Check the status of all active WebSocket connections
+// @Description  Check the status of all active WebSocket connections: whether the user data stream is connected, when it last received an event and renewed its listen key, how often the watchdog restarted it, and the last price time and connection of every mark price stream
 // @Tags         WebSocket
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Success      200  {object}  models.TradeResponse  "WebSocket status retrieved"
 // @Failure      401  {object}  models.TradeResponse  "Unauthorized"
 // @Router       /api/websocket/status [get]
-func WebSocketStatusHandler() gin.HandlerFunc {
+func WebSocketStatusHandler(streams *binance.WebSocketManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if wsManager == nil {
-			c.JSON(http.StatusOK, models.TradeResponse{
-				Success:   true,
-				Message:   "WebSocket not initialized",
-				Data:      gin.H{"status": "not_initialized"},
+		status := streams.GetStreamStatus()
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "WebSocket status retrieved",
+			Data:      status,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// CandlesHandler - Get in-memory candles
+// @Summary      Get candles
+// @Description  Get rolling candles kept in memory from kline and aggTrade streams (oldest first; the last one may still be forming). Only symbols and intervals started with CANDLE_STREAMS or POST /api/admin/candles are available.
+// @Tags         WebSocket
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        symbol    query     string  true   "Trading symbol" example("BTCUSDT")
+// @Param        interval  query     string  true   "Kline interval" example("1m")
+// @Param        limit     query     int     false  "Most recent candles returned (default: all kept)"
+// @Success      200       {object}  models.TradeResponse{data=[]models.Candle}  "Candles retrieved"
+// @Failure      400       {object}  models.TradeResponse  "Missing parameters"
+// @Failure      401       {object}  models.TradeResponse  "Unauthorized"
+// @Failure      404       {object}  models.TradeResponse  "Candles not streamed"
+// @Router       /api/candles [get]
+func CandlesHandler(streams *binance.WebSocketManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		symbol := c.Query("symbol")
+		interval := c.Query("interval")
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+		if symbol == "" || interval == "" || err != nil || limit < 0 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Missing parameters",
+				Error:     "symbol and interval are required, limit must be a positive number",
 				Timestamp: time.Now().Unix(),
 			})
 			return
 		}
 
-		status := wsManager.GetStreamStatus()
+		candles, ok := streams.Candles(symbol, interval, limit)
+		if !ok {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "Candles not streamed",
+				Error:     "no candle stream for " + symbol + " " + interval,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
 
 		c.JSON(http.StatusOK, models.TradeResponse{
 			Success:   true,
-			Message:   "WebSocket status retrieved",
-			Data:      status,
+			Message:   "Candles retrieved",
+			Data:      candles,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// StartCandleStreamHandler - Start in-memory candles for a symbol
+// @Summary      Start candle stream
+// @Description  Load recent klines and keep rolling candles for a symbol and interval (1m to 1d) in memory from the kline and aggTrade streams. Does nothing if already streamed.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      models.CandleStreamRequest  true  "Symbol and interval"
+// @Success      200      {object}  models.TradeResponse  "Candle stream started"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403      {object}  models.TradeResponse  "Admin access required"
+// @Failure      500      {object}  models.TradeResponse  "Failed to start candle stream"
+// @Router       /api/admin/candles [post]
+func StartCandleStreamHandler(streams *binance.WebSocketManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.CandleStreamRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if err := streams.StartCandleStream(req.Symbol, req.Interval); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to start candle stream",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Candle stream started",
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// StopCandleStreamHandler - Stop in-memory candles for a symbol
+// @Summary      Stop candle stream
+// @Description  Stop the kline stream of a symbol and interval and drop its candles
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        symbol    path      string  true  "Trading symbol" example("BTCUSDT")
+// @Param        interval  path      string  true  "Kline interval" example("1m")
+// @Success      200       {object}  models.TradeResponse  "Candle stream stopped"
+// @Failure      401       {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403       {object}  models.TradeResponse  "Admin access required"
+// @Failure      404       {object}  models.TradeResponse  "Candles not streamed"
+// @Router       /api/admin/candles/{symbol}/{interval} [delete]
+func StopCandleStreamHandler(streams *binance.WebSocketManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !streams.StopCandleStream(c.Param("symbol"), c.Param("interval")) {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "Candles not streamed",
+				Error:     "no candle stream for " + c.Param("symbol") + " " + c.Param("interval"),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Candle stream stopped",
 			Timestamp: time.Now().Unix(),
 		})
 	}
@@ -122,7 +217,7 @@ func FundingRateHandler(bn *binance.Client) gin.HandlerFunc {
 			return
 		}
 
-		fundingRate, err := bn.GetFundingRate(symbol)
+		fundingRate, err := bn.GetFundingRate(c.Request.Context(), symbol)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
@@ -174,7 +269,7 @@ func FundingRateHistoryHandler(bn *binance.Client) gin.HandlerFunc {
 		startTime, _ := strconv.ParseInt(c.Query("startTime"), 10, 64)
 		endTime, _ := strconv.ParseInt(c.Query("endTime"), 10, 64)
 
-		history, err := bn.GetFundingRateHistory(symbol, limit, startTime, endTime)
+		history, err := bn.GetFundingRateHistory(c.Request.Context(), symbol, limit, startTime, endTime)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
@@ -201,14 +296,22 @@ func FundingRateHistoryHandler(bn *binance.Client) gin.HandlerFunc {
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Param        symbol  query     string  true  "Trading symbol" example("BTCUSDT")
+// @Param        userId  query     string  false  "User whose own Binance account to query (default: operator account)"
+// @Param        account query     string  false  "Operator account to query: main or a BINANCE_ACCOUNTS name (default: as userId selects)"
 // @Success      200     {object}  models.TradeResponse{data=binance.LiquidationRisk}  "Liquidation risk calculated"
-// @Failure      400     {object}  models.TradeResponse  "Missing symbol parameter"
+// @Failure      400     {object}  models.TradeResponse  "Missing symbol parameter or unknown account"
 // @Failure      401     {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403     {object}  models.TradeResponse  "No Binance account for user"
 // @Failure      404     {object}  models.TradeResponse  "No position found"
 // @Failure      500     {object}  models.TradeResponse  "Failed to calculate risk"
 // @Router       /api/risk/liquidation [get]
-func LiquidationRiskHandler(bn *binance.Client) gin.HandlerFunc {
+func LiquidationRiskHandler(clients *binance.ClientPool) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		userID, account := c.Query("userId"), c.Query("account")
+		if !claimOwnership(c, &userID) || !requireAccountAccess(c, account) {
+			return
+		}
+
 		symbol := c.Query("symbol")
 		if symbol == "" {
 			c.JSON(http.StatusBadRequest, models.TradeResponse{
@@ -220,7 +323,12 @@ func LiquidationRiskHandler(bn *binance.Client) gin.HandlerFunc {
 			return
 		}
 
-		risk, err := bn.GetLiquidationRisk(symbol)
+		bn, ok := userClient(c, clients, userID, account)
+		if !ok {
+			return
+		}
+
+		risk, err := bn.GetLiquidationRisk(c.Request.Context(), symbol)
 		if err != nil {
 			statusCode := http.StatusInternalServerError
 			if err.Error() == "no position found for "+symbol ||
@@ -246,6 +354,52 @@ func LiquidationRiskHandler(bn *binance.Client) gin.HandlerFunc {
 	}
 }
 
+// AccountHealthHandler - Get aggregated account margin health
+// @Summary      Get account health
+// @Description  Aggregate maintenance margin, margin ratio, available margin, total exposure and a health score across all open positions
+// @Tags         Risk Management
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId   query     string  false  "User whose own Binance account to query (default: operator account)"
+// @Param        account  query     string  false  "Operator account to query: main or a BINANCE_ACCOUNTS name (default: as userId selects)"
+// @Success      200  {object}  models.TradeResponse{data=binance.AccountHealth}  "Account health calculated"
+// @Failure      400  {object}  models.TradeResponse  "Unknown account"
+// @Failure      401  {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403  {object}  models.TradeResponse  "No Binance account for user"
+// @Failure      500  {object}  models.TradeResponse  "Failed to calculate account health"
+// @Router       /api/risk/account [get]
+func AccountHealthHandler(clients *binance.ClientPool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, account := c.Query("userId"), c.Query("account")
+		if !claimOwnership(c, &userID) || !requireAccountAccess(c, account) {
+			return
+		}
+
+		bn, ok := userClient(c, clients, userID, account)
+		if !ok {
+			return
+		}
+
+		health, err := bn.GetAccountHealth(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to calculate account health",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Account health calculated successfully",
+			Data:      health,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
 // TimeSyncHandler - Get Binance server time and sync status
 // @Summary      Check time synchronization
 // @Description  Get Binance server time and check if local time is synchronized
@@ -258,7 +412,7 @@ func LiquidationRiskHandler(bn *binance.Client) gin.HandlerFunc {
 // @Router       /api/system/time [get]
 func TimeSyncHandler(bn *binance.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		isInSync, offset, err := bn.CheckTimeSyncStatus()
+		isInSync, offset, err := bn.CheckTimeSyncStatus(c.Request.Context())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
@@ -269,18 +423,20 @@ func TimeSyncHandler(bn *binance.Client) gin.HandlerFunc {
 			return
 		}
 
-		serverTime, _ := bn.GetBinanceServerTime()
+		serverTime, _ := bn.GetBinanceServerTime(c.Request.Context())
 
 		data := gin.H{
-			"isInSync":       isInSync,
-			"offsetMs":       offset,
-			"serverTime":     serverTime,
-			"localTime":      time.Now().UnixMilli(),
-			"recommendation": "",
+			"isInSync":        isInSync,
+			"offsetMs":        offset,
+			"appliedOffsetMs": binance.TimeOffset(),
+			"recvWindowMs":    binance.RecvWindow(),
+			"serverTime":      serverTime,
+			"localTime":       time.Now().UnixMilli(),
+			"recommendation":  "",
 		}
 
 		if !isInSync {
-			data["recommendation"] = "Clock drift detected. Sync your system clock using NTP: ntpdate pool.ntp.org"
+			data["recommendation"] = "Clock drift detected and compensated on signed requests. Sync your system clock using NTP: ntpdate pool.ntp.org"
 		}
 
 		c.JSON(http.StatusOK, models.TradeResponse{
@@ -302,7 +458,7 @@ func TimeSyncHandler(bn *binance.Client) gin.HandlerFunc {
 // @Router       /api/system/server-time [get]
 func ServerTimeHandler(bn *binance.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		serverTime, err := bn.GetBinanceServerTime()
+		serverTime, err := bn.GetBinanceServerTime(c.Request.Context())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"bytes"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/resilience"
+	"crypto-trading-api/internal/validation"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// binanceErrorCodes maps Binance error codes to errorCode values; other
+// Binance rejections are ERR_EXCHANGE_REJECTED
+var binanceErrorCodes = map[int]string{
+	binance.ErrCodeTimestampOutOfSync:    models.ErrClockSkew,
+	binance.ErrCodeInvalidSignature:      models.ErrExchangeAuth,
+	binance.ErrCodeUnauthorized:          models.ErrExchangeAuth,
+	binance.ErrCodeInsufficientBalance:   models.ErrInsufficientBalance,
+	binance.ErrCodeMarginInsufficient:    models.ErrInsufficientMargin,
+	binance.ErrCodePositionSideInvalid:   models.ErrPositionSide,
+	binance.ErrCodeMinNotional:           models.ErrMinNotional,
+	binance.ErrCodeRateLimitExceeded:     models.ErrExchangeRateLimited,
+	binance.ErrCodeOrderWouldTrigger:     models.ErrOrderWouldTrigger,
+	binance.ErrCodeReduceOnlyReject:      models.ErrReduceOnlyRejected,
+	binance.ErrCodeMaxPositionAtLeverage: models.ErrOpenPosition,
+	binance.ErrCodeOpenOrdersExist:       models.ErrOpenPosition,
+	binance.ErrCodePositionExists:        models.ErrOpenPosition,
+	binance.ErrCodeIsolatedLeverageCut:   models.ErrOpenPosition,
+}
+
+// ErrorCode classifies a failed request for the errorCode field: invalid
+// input, known internal failures, Binance rejections (through
+// HandleBinanceError) and otherwise the HTTP status
+func ErrorCode(status int, err error) string {
+	if err != nil {
+		switch {
+		case validation.Details(err) != nil:
+			return models.ErrInvalidRequest
+		case errors.Is(err, errShuttingDown):
+			return models.ErrShuttingDown
+		case errors.Is(err, resilience.ErrCircuitOpen):
+			return models.ErrExchangeUnavailable
+		}
+		if status >= http.StatusInternalServerError {
+			if code := exchangeErrorCode(err); code != "" {
+				return code
+			}
+		}
+	}
+	return statusErrorCode(status)
+}
+
+// exchangeErrorCode classifies a Binance failure, "" if err is not one.
+// Errors are often wrapped as text, so the message is parsed too.
+func exchangeErrorCode(err error) string {
+	var binanceErr *binance.BinanceError
+	if !errors.As(binance.HandleBinanceError(err), &binanceErr) {
+		if strings.Contains(err.Error(), resilience.ErrCircuitOpen.Error()) {
+			return models.ErrExchangeUnavailable
+		}
+		return ""
+	}
+	if code, ok := binanceErrorCodes[binanceErr.Code]; ok {
+		return code
+	}
+	return models.ErrExchangeRejected
+}
+
+// statusErrorCode is the errorCode for a status when nothing more specific
+// is known
+func statusErrorCode(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return models.ErrUnauthorized
+	case http.StatusForbidden:
+		return models.ErrForbidden
+	case http.StatusNotFound:
+		return models.ErrNotFound
+	case http.StatusConflict:
+		return models.ErrConflict
+	case http.StatusGone:
+		return models.ErrGone
+	case http.StatusTooManyRequests:
+		return models.ErrRateLimited
+	case http.StatusServiceUnavailable:
+		return models.ErrUnavailable
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return models.ErrExchangeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return models.ErrInternal
+	}
+	return models.ErrInvalidRequest
+}
+
+// ErrorCodeMiddleware adds errorCode to failed JSON responses ("success":
+// false) that do not set one, classified from the status and their error
+// text, so every failure carries a code without each handler choosing one
+func ErrorCodeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer = &errorCodeWriter{ResponseWriter: c.Writer}
+		c.Next()
+	}
+}
+
+// errorCodeWriter rewrites the body of a failed response
+type errorCodeWriter struct {
+	gin.ResponseWriter
+	written bool
+}
+
+func (w *errorCodeWriter) Write(b []byte) (int, error) {
+	// JSON responses are written in one piece; later writes pass through
+	first := !w.written
+	w.written = true
+	if !first || w.Status() < http.StatusBadRequest || !strings.Contains(w.Header().Get("Content-Type"), "json") {
+		return w.ResponseWriter.Write(b)
+	}
+
+	body, ok := withErrorCode(w.Status(), b)
+	if !ok {
+		return w.ResponseWriter.Write(b)
+	}
+	if _, err := w.ResponseWriter.Write(body); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *errorCodeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// withErrorCode inserts errorCode into a failed response object that has
+// none, keeping the other fields as they are
+func withErrorCode(status int, body []byte) ([]byte, bool) {
+	var resp struct {
+		Success   *bool   `json:"success"`
+		Error     string  `json:"error"`
+		ErrorCode *string `json:"errorCode"`
+	}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) < 2 || trimmed[0] != '{' || json.Unmarshal(trimmed, &resp) != nil {
+		return nil, false
+	}
+	if resp.Success == nil || *resp.Success || resp.ErrorCode != nil {
+		return nil, false
+	}
+
+	var err error
+	if resp.Error != "" {
+		err = errors.New(resp.Error)
+	}
+	code, _ := json.Marshal(ErrorCode(status, err))
+
+	out := make([]byte, 0, len(trimmed)+len(code)+16)
+	out = append(out, `{"errorCode":`...)
+	out = append(out, code...)
+	if rest := bytes.TrimSpace(trimmed[1:]); len(rest) > 0 && rest[0] != '}' {
+		out = append(out, ',')
+	}
+	out = append(out, trimmed[1:]...)
+	return out, true
+}
@@ -3,13 +3,20 @@ package api
 import (
 	"context"
 	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/logging"
 	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/tradehistory"
+	"crypto-trading-api/internal/tradingview"
+	"crypto-trading-api/internal/validation"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+	"github.com/gin-gonic/gin/binding"
 )
 
 // FirebaseInterface defines methods needed from Firebase client
@@ -18,12 +25,24 @@ type FirebaseInterface interface {
 	UpdateTrade(ctx context.Context, trade *models.Trade) error
 	GetTrade(ctx context.Context, tradeID string) (*models.Trade, error)
 	GetUserTrades(ctx context.Context, userID string) ([]*models.Trade, error)
+	QueryTrades(ctx context.Context, query models.TradeQuery) (*models.TradePage, error)
+	GetTradeEvents(ctx context.Context, tradeID string) ([]*models.TradeEvent, error)
+	GetArchivedTrade(ctx context.Context, tradeID string) (*models.Trade, error)
+	GetActiveTrades(ctx context.Context) ([]*models.Trade, error)
+	GetStrategyPreset(ctx context.Context, name string) (*models.StrategyPreset, error)
+	GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error)
 }
 
 // BinanceInterface defines methods needed from Binance client
 type BinanceInterface interface {
-	PlaceFuturesOrder(trade *models.Trade) (*binance.OrderResult, error)
-	MonitorTrade(trade *models.Trade, fb interface {
+	PlaceFuturesOrder(ctx context.Context, trade *models.Trade) (*binance.OrderResult, error)
+	SymbolRules(ctx context.Context, symbol string) (*binance.SymbolInfo, error)
+	GetMarkPrice(ctx context.Context, symbol string) (float64, error)
+	GetAccountInfo(ctx context.Context) (*binance.AccountInfo, error)
+	GetCommissionRates(ctx context.Context, symbol string) (*binance.CommissionRates, error)
+	MaintenanceBracket(ctx context.Context, symbol string, notional float64) (*binance.MaintenanceBracket, error)
+	CancelEntry(ctx context.Context, trade *models.Trade) error
+	MonitorTrade(ctx context.Context, trade *models.Trade, fb interface {
 		UpdateTrade(ctx context.Context, trade *models.Trade) error
 	})
 }
@@ -31,22 +50,28 @@ type BinanceInterface interface {
 // TradeHandler - Main function to handle trade requests
 // @Summary      Execute a new trade
 // @Description  Execute a futures trade on Binance with stop loss and take profit. API key can be provided via X-API-Key header, Authorization Bearer token, or apiKey field in request body (useful for TradingView alerts).
+// @Description  TradingView alerts can also be sent as-is: a plain-text message of key=value pairs (e.g. "template=btc-scalp ticker={{ticker}} action={{strategy.order.action}} price={{close}}"), TradingView's default strategy message, or JSON with a "template" field. The named template (see /api/tradingview/templates) supplies userId, size, leverage and SL/TP percentages.
 // @Tags         Trading
 // @Accept       json
+// @Accept       plain
 // @Produce      json
 // @Security     ApiKeyAuth
-// @Param        trade  body      models.TradeRequest  true  "Trade parameters (apiKey field is optional for authentication)"
+// @Param        trade     body      models.TradeRequest  true   "Trade parameters (apiKey field is optional for authentication)"
+// @Param        template  query     string               false  "TradingView template name (for alerts that do not name one)"
 // @Success      200    {object}  models.TradeResponse  "Trade executed successfully"
+// @Success      202    {object}  models.TradeResponse  "Trade queued until a position slot frees up"
 // @Failure      400    {object}  models.TradeResponse  "Invalid request or trade parameters"
 // @Failure      401    {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403    {object}  models.TradeResponse  "Symbol not allowed for trading"
+// @Failure      404    {object}  models.TradeResponse  "TradingView template not found"
+// @Failure      409    {object}  models.TradeResponse  "Maximum concurrent positions reached"
 // @Failure      500    {object}  models.TradeResponse  "Internal server error - Trade execution failed"
+// @Failure      503    {object}  models.TradeResponse  "Trading paused"
 // @Router       /api/trade [post]
-func TradeHandler(fb FirebaseInterface, bn BinanceInterface) gin.HandlerFunc {
+func TradeHandler(intake *TradeIntake, templates tradingview.TemplateStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var req models.TradeRequest
-
-		// Validate request body
-		if err := c.ShouldBindJSON(&req); err != nil {
+		body, err := c.GetRawData()
+		if err != nil {
 			c.JSON(http.StatusBadRequest, models.TradeResponse{
 				Success:   false,
 				Message:   "Invalid request",
@@ -56,116 +81,220 @@ func TradeHandler(fb FirebaseInterface, bn BinanceInterface) gin.HandlerFunc {
 			return
 		}
 
-		// Validate trade parameters
-		if err := validateTradeParams(&req); err != nil {
+		// TradingView alerts (plain text or JSON referencing a template)
+		if c.Query("template") != "" || tradingview.IsTemplated(body) {
+			req, status, err := buildTradingViewRequest(c, templates, body)
+			if err != nil {
+				c.JSON(status, models.TradeResponse{
+					Success:   false,
+					Message:   "Invalid TradingView alert",
+					Error:     err.Error(),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+
+			if !claimOwnership(c, &req.UserID) {
+				return
+			}
+
+			respondTradeOutcome(c, intake.Submit(c.Request.Context(), req))
+			return
+		}
+
+		var req models.TradeRequest
+
+		// Validate request body
+		if err := binding.JSON.BindBody(body, &req); err != nil {
+			err = validation.FromBinding(err)
 			c.JSON(http.StatusBadRequest, models.TradeResponse{
 				Success:   false,
-				Message:   "Invalid trade parameters",
+				Message:   "Invalid request",
 				Error:     err.Error(),
+				Details:   validation.Details(err),
 				Timestamp: time.Now().Unix(),
 			})
 			return
 		}
 
-		// Generate unique trade ID
-		tradeID := uuid.New().String()
-
-		// Set default order type if not specified
-		orderType := req.OrderType
-		if orderType == "" {
-			orderType = "MARKET" // Default to MARKET order
+		// Token holders trade as themselves (userId may be omitted)
+		if !claimOwnership(c, &req.UserID) || !requireAccountAccess(c, req.Account) {
+			return
 		}
 
-		// Set default margin type if not specified
-		marginType := req.MarginType
-		if marginType == "" {
-			marginType = "ISOLATED" // Default to ISOLATED margin
-		}
+		respondTradeOutcome(c, intake.Submit(c.Request.Context(), &req))
+	}
+}
+
+// buildTradingViewRequest parses a TradingView alert and maps it through its template.
+// The template is named in the alert (template=...) or the ?template= query parameter.
+func buildTradingViewRequest(c *gin.Context, templates tradingview.TemplateStore, body []byte) (*models.TradeRequest, int, error) {
+	alert, err := tradingview.Parse(body)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	name := alert.Template()
+	if name == "" {
+		name = c.Query("template")
+	}
+	if name == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("alert does not reference a template (add template=<name> or ?template=<name>)")
+	}
+
+	tpl, err := templates.GetTradingViewTemplate(c.Request.Context(), name)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if tpl == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("template %q not found", name)
+	}
+
+	req, err := tradingview.BuildTradeRequest(alert, tpl)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	return req, http.StatusOK, nil
+}
 
-		// Create trade record
-		trade := &models.Trade{
-			ID:         tradeID,
-			UserID:     req.UserID,
-			Symbol:     req.Symbol,
-			Side:       req.Side,
-			OrderType:  orderType,
-			MarginType: marginType,
-			EntryPrice: req.EntryPrice,
-			StopLoss:   req.StopLoss,
-			TakeProfit: req.TakeProfit,
-			Leverage:   req.Leverage,
-			Size:       req.Size,
-			Status:     "PENDING",
-			CreatedAt:  time.Now().Unix(),
+// respondTradeOutcome writes a trade submission result as an API response
+func respondTradeOutcome(c *gin.Context, outcome *TradeOutcome) {
+	resp := models.TradeResponse{
+		Success:   outcome.Err == nil,
+		Message:   outcome.Message,
+		Timestamp: time.Now().Unix(),
+	}
+	if outcome.Trade != nil {
+		resp.TradeID = outcome.Trade.ID
+		if outcome.Err == nil {
+			resp.Data = outcome.Trade
+			if len(outcome.Copies) > 0 {
+				resp.Data = gin.H{"trade": outcome.Trade, "copies": outcome.Copies}
+			}
 		}
+	}
+	if outcome.Err != nil {
+		resp.Error = outcome.Err.Error()
+		resp.ErrorCode = outcome.Code
+		if resp.ErrorCode == "" {
+			resp.ErrorCode = ErrorCode(outcome.Status, outcome.Err)
+		}
+		resp.Details = validation.Details(outcome.Err)
+	}
 
-		// Execute trade on Binance
-		orderResult, err := bn.PlaceFuturesOrder(trade)
-		if err != nil {
-			trade.Status = "FAILED"
-			trade.Error = err.Error()
-			fb.SaveTrade(c.Request.Context(), trade)
+	c.JSON(outcome.Status, resp)
+}
 
-			c.JSON(http.StatusInternalServerError, models.TradeResponse{
-				Success:   false,
-				TradeID:   tradeID,
-				Message:   "Failed to execute trade",
-				Error:     err.Error(),
-				Timestamp: time.Now().Unix(),
-			})
+// GetTradesHandler - Get trades for a user
+// @Summary      Get user trades
+// @Description  Retrieve all trades for a specific user ID, optionally filtered by journal tag
+// @Tags         Trading
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  path      string  true   "User ID"
+// @Param        tag     query     string  false  "Only trades whose journal has this tag"
+// @Success      200     {object}  models.TradeResponse{data=[]models.Trade}  "Trades retrieved successfully"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      500     {object}  models.TradeResponse  "Internal server error - Failed to fetch trades"
+// @Router       /api/trades/{userId} [get]
+func GetTradesHandler(fb FirebaseInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Param("userId")
+		if !requireOwner(c, userID) {
 			return
 		}
+		tag := c.Query("tag") // Optional: filter by journal tag
 
-		// Update trade with order result
-		trade.Status = "ACTIVE"
-		trade.OrderID = orderResult.OrderID
-		trade.SLOrderID = orderResult.SLOrderID
-		trade.TPOrderID = orderResult.TPOrderID
-		trade.ExecutedPrice = orderResult.AvgPrice
-		trade.ExecutedAt = time.Now().Unix()
-
-		// Save to Firebase
-		if err := fb.SaveTrade(c.Request.Context(), trade); err != nil {
+		trades, err := fb.GetUserTrades(c.Request.Context(), userID)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
-				TradeID:   tradeID,
-				Message:   "Trade executed but failed to save",
+				Message:   "Failed to fetch trades",
 				Error:     err.Error(),
 				Timestamp: time.Now().Unix(),
 			})
 			return
 		}
 
-		// Start monitoring for SL/TP (in goroutine)
-		go bn.MonitorTrade(trade, fb)
+		if tag != "" {
+			trades = filterTradesByTag(trades, tag)
+		}
 
-		// Success response
 		c.JSON(http.StatusOK, models.TradeResponse{
 			Success:   true,
-			TradeID:   tradeID,
-			Message:   "Trade executed successfully",
-			Data:      trade,
+			Message:   "Trades fetched successfully",
+			Data:      trades,
 			Timestamp: time.Now().Unix(),
 		})
 	}
 }
 
-// GetTradesHandler - Get trades for a user
-// @Summary      Get user trades
-// @Description  Retrieve all trades for a specific user ID
+// QueryTradesHandler - Search trades with filters and pagination
+// @Summary      Search trades
+// @Description  Page through trades newest first, filtered by user, status, symbol and creation time. The query runs on the storage backend's indexes, so history size does not matter. Pass nextCursor from a page as cursor to get the next one. fields limits each trade to the listed fields for slim payloads. Non-admin token holders only see their own trades.
 // @Tags         Trading
 // @Produce      json
 // @Security     ApiKeyAuth
-// @Param        userId  path      string  true  "User ID"
-// @Success      200     {object}  models.TradeResponse{data=[]models.Trade}  "Trades retrieved successfully"
+// @Param        userId  query     string  false  "User ID"
+// @Param        status  query     string  false  "Trade status, e.g. ACTIVE or CLOSED"
+// @Param        symbol  query     string  false  "Symbol, e.g. BTCUSDT"
+// @Param        from    query     int     false  "Created at or after (Unix seconds)"
+// @Param        to      query     int     false  "Created at or before (Unix seconds)"
+// @Param        limit   query     int     false  "Page size, 1-500 (default: 50)"
+// @Param        cursor  query     string  false  "nextCursor of the previous page"
+// @Param        fields  query     string  false  "Comma-separated trade fields to return, e.g. id,symbol,status,pnl (default: all)"
+// @Success      200     {object}  models.TradeResponse{data=models.TradePage}  "Trades retrieved successfully"
+// @Failure      400     {object}  models.TradeResponse  "Invalid parameters"
 // @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403     {object}  models.TradeResponse  "Another user's trades"
 // @Failure      500     {object}  models.TradeResponse  "Internal server error - Failed to fetch trades"
-// @Router       /api/trades/{userId} [get]
-func GetTradesHandler(fb FirebaseInterface) gin.HandlerFunc {
+// @Router       /api/trades [get]
+func QueryTradesHandler(fb FirebaseInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID := c.Param("userId")
+		query := models.TradeQuery{
+			UserID: c.Query("userId"),
+			Status: strings.ToUpper(c.Query("status")),
+			Symbol: strings.ToUpper(c.Query("symbol")),
+		}
+		// Token holders only see their own trades unless they are admins
+		if c.GetString(authRoleKey) != models.RoleAdmin && !claimOwnership(c, &query.UserID) {
+			return
+		}
 
-		trades, err := fb.GetUserTrades(c.Request.Context(), userID)
+		var errFrom, errTo, errLimit, errCursor error
+		if c.Query("from") != "" {
+			query.From, errFrom = strconv.ParseInt(c.Query("from"), 10, 64)
+		}
+		if c.Query("to") != "" {
+			query.To, errTo = strconv.ParseInt(c.Query("to"), 10, 64)
+		}
+		query.Limit, errLimit = strconv.Atoi(c.DefaultQuery("limit", "50"))
+		if c.Query("cursor") != "" {
+			query.After, errCursor = models.ParseTradeCursor(c.Query("cursor"))
+		}
+		if errFrom != nil || errTo != nil || errLimit != nil || errCursor != nil ||
+			(query.To != 0 && query.From > query.To) || query.Limit < 1 || query.Limit > 500 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     "from/to must be Unix seconds with from <= to, limit 1-500, cursor a nextCursor value",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		fields, err := parseFields(c, tradeFields)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		page, err := fb.QueryTrades(c.Request.Context(), query)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.TradeResponse{
 				Success:   false,
@@ -176,10 +305,33 @@ func GetTradesHandler(fb FirebaseInterface) gin.HandlerFunc {
 			return
 		}
 
+		var data interface{} = page
+		if fields != nil {
+			trades := make([]gin.H, 0, len(page.Trades))
+			for _, trade := range page.Trades {
+				item, err := tradeItem(trade)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, models.TradeResponse{
+						Success:   false,
+						Message:   "Failed to fetch trades",
+						Error:     err.Error(),
+						Timestamp: time.Now().Unix(),
+					})
+					return
+				}
+				trades = append(trades, fields.pick(item))
+			}
+			slim := gin.H{"trades": trades}
+			if page.NextCursor != "" {
+				slim["nextCursor"] = page.NextCursor
+			}
+			data = slim
+		}
+
 		c.JSON(http.StatusOK, models.TradeResponse{
 			Success:   true,
-			Message:   "Trades fetched successfully",
-			Data:      trades,
+			Message:   fmt.Sprintf("%d trades", len(page.Trades)),
+			Data:      data,
 			Timestamp: time.Now().Unix(),
 		})
 	}
@@ -187,7 +339,7 @@ func GetTradesHandler(fb FirebaseInterface) gin.HandlerFunc {
 
 // GetTradeHandler - Get single trade
 // @Summary      Get trade by ID
-// @Description  Retrieve a specific trade by its unique ID
+// @Description  Retrieve a specific trade by its unique ID, including archived trades
 // @Tags         Trading
 // @Produce      json
 // @Security     ApiKeyAuth
@@ -200,7 +352,7 @@ func GetTradeHandler(fb FirebaseInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tradeID := c.Param("tradeId")
 
-		trade, err := fb.GetTrade(c.Request.Context(), tradeID)
+		trade, err := getTradeOrArchived(c.Request.Context(), fb, tradeID)
 		if err != nil {
 			c.JSON(http.StatusNotFound, models.TradeResponse{
 				Success:   false,
@@ -211,6 +363,10 @@ func GetTradeHandler(fb FirebaseInterface) gin.HandlerFunc {
 			return
 		}
 
+		if !requireOwner(c, trade.UserID) {
+			return
+		}
+
 		c.JSON(http.StatusOK, models.TradeResponse{
 			Success:   true,
 			Message:   "Trade fetched successfully",
@@ -220,31 +376,451 @@ func GetTradeHandler(fb FirebaseInterface) gin.HandlerFunc {
 	}
 }
 
-// Validate trade parameters
+// CancelTradeHandler - Cancel a pending trade
+// @Summary      Cancel trade
+// @Description  Abort a trade that has not opened a position: a QUEUED trade, or an ACTIVE LIMIT trade whose entry order has not filled. The entry order and the SL/TP orders placed with it are cancelled, the trade's monitor stopped and the trade marked CANCELED. Once the entry has filled, even partly, close the position with /api/position/close instead. Follower copies are cancelled by their own trade ID.
+// @Tags         Trading
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        tradeId  path      string  true  "Trade ID"
+// @Success      200      {object}  models.TradeResponse{data=models.Trade}  "Trade cancelled"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403      {object}  models.TradeResponse  "Not your trade"
+// @Failure      404      {object}  models.TradeResponse  "Trade not found"
+// @Failure      409      {object}  models.TradeResponse  "Trade not pending or entry already filled"
+// @Failure      500      {object}  models.TradeResponse  "Failed to cancel trade"
+// @Router       /api/trade/{tradeId}/cancel [post]
+func CancelTradeHandler(intake *TradeIntake, fb FirebaseInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tradeID := c.Param("tradeId")
+
+		trade, ok := pendingTrade(c, fb, tradeID)
+		if !ok {
+			return
+		}
+
+		if err := intake.CancelTrade(c.Request.Context(), trade); err != nil {
+			status, message := http.StatusInternalServerError, "Failed to cancel trade"
+			if errors.Is(err, binance.ErrEntryFilled) {
+				status, message = http.StatusConflict, "Entry already filled; close the position instead"
+			}
+			c.JSON(status, models.TradeResponse{
+				Success:   false,
+				Message:   message,
+				Error:     err.Error(),
+				ErrorCode: ErrorCode(status, err),
+				TradeID:   tradeID,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Trade cancelled",
+			Data:      trade,
+			TradeID:   tradeID,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// ReplaceTradeHandler - Cancel and replace a pending trade
+// @Summary      Replace trade
+// @Description  Move a trade that has not opened a position to a new entry price, stop loss, take profit or size, keeping its trade ID and history. A QUEUED trade is updated; an ACTIVE LIMIT trade whose entry has not filled has its entry and SL/TP orders cancelled and placed again at the new prices. Omitted fields keep their value. The new parameters pass the same checks as a new trade; if Binance rejects the new orders, the previous ones are placed again.
+// @Tags         Trading
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        tradeId  path      string                      true  "Trade ID"
+// @Param        request  body      models.TradeReplaceRequest  true  "New entry parameters"
+// @Success      200      {object}  models.TradeResponse{data=models.Trade}  "Trade replaced"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request or trade parameters"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403      {object}  models.TradeResponse  "Not your trade or risk limit exceeded"
+// @Failure      404      {object}  models.TradeResponse  "Trade not found"
+// @Failure      409      {object}  models.TradeResponse  "Trade not pending or entry already filled"
+// @Failure      500      {object}  models.TradeResponse  "Failed to replace trade"
+// @Failure      503      {object}  models.TradeResponse  "Trading paused or symbol halted"
+// @Router       /api/trade/{tradeId}/replace [post]
+func ReplaceTradeHandler(intake *TradeIntake, fb FirebaseInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.TradeReplaceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		if req.EntryPrice == 0 && req.StopLoss == 0 && req.TakeProfit == 0 && req.Size == 0 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     "set at least one of entryPrice, stopLoss, takeProfit and size",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		trade, ok := pendingTrade(c, fb, c.Param("tradeId"))
+		if !ok {
+			return
+		}
+
+		respondTradeOutcome(c, intake.Replace(c.Request.Context(), trade, &req))
+	}
+}
+
+// TradeProtectionHandler - Move a live trade's stop loss and take profit
+// @Summary      Adjust trade SL/TP
+// @Description  Move the stop loss and/or take profit of an ACTIVE or FILLED trade: the current order is cancelled and a new one placed at the new price, or the previous one placed again if Binance rejects it. Prices must fit the symbol's tick size and lie on the right side of the current price (below it for a long's stop loss, above for its take profit). The change is recorded in the trade's history.
+// @Tags         Trading
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        tradeId  path      string                         true  "Trade ID"
+// @Param        request  body      models.TradeProtectionRequest  true  "New SL/TP prices"
+// @Success      200      {object}  models.TradeResponse{data=models.Trade}  "Protection moved"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request or prices"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403      {object}  models.TradeResponse  "Not your trade"
+// @Failure      404      {object}  models.TradeResponse  "Trade not found"
+// @Failure      409      {object}  models.TradeResponse  "Trade not open"
+// @Failure      500      {object}  models.TradeResponse  "Failed to move SL/TP"
+// @Router       /api/trade/{tradeId}/protection [patch]
+func TradeProtectionHandler(clients *binance.ClientPool, fb FirebaseInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.TradeProtectionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		if req.StopLoss == 0 && req.TakeProfit == 0 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     "set stopLoss, takeProfit or both",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		tradeID := c.Param("tradeId")
+		trade, err := fb.GetTrade(c.Request.Context(), tradeID)
+		if err != nil || trade == nil {
+			if err == nil {
+				err = fmt.Errorf("trade %s not found", tradeID)
+			}
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "Trade not found",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		if !requireOwner(c, trade.UserID) {
+			return
+		}
+		if trade.Status != "ACTIVE" && trade.Status != "FILLED" {
+			c.JSON(http.StatusConflict, models.TradeResponse{
+				Success:   false,
+				Message:   "Trade is not open",
+				Error:     fmt.Sprintf("trade %s is %s; only ACTIVE and FILLED trades have SL/TP orders", tradeID, trade.Status),
+				ErrorCode: models.ErrConflict,
+				TradeID:   tradeID,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		bn, ok := userClient(c, clients, trade.UserID, tradeAccount(trade))
+		if !ok {
+			return
+		}
+
+		if err := validateProtection(c.Request.Context(), bn, trade, &req); err != nil {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid SL/TP prices",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				TradeID:   tradeID,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		// Orders are being replaced: record the outcome even if the caller
+		// has gone away
+		ctx := tradehistory.WithSource(context.WithoutCancel(c.Request.Context()), tradehistory.SourceAPI)
+
+		var replaceErr error
+		if req.StopLoss > 0 {
+			replaceErr = bn.ReplaceStopLoss(ctx, trade, req.StopLoss)
+		}
+		if req.TakeProfit > 0 && replaceErr == nil {
+			replaceErr = bn.ReplaceTakeProfit(ctx, trade, req.TakeProfit)
+		}
+
+		if err := fb.UpdateTrade(ctx, trade); err != nil {
+			logging.Ctx(ctx).Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("SL/TP of trade %s moved but not saved", trade.ID)
+			if replaceErr == nil {
+				replaceErr = fmt.Errorf("failed to save trade: %v", err)
+			}
+		}
+
+		if replaceErr != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to move SL/TP",
+				Error:     replaceErr.Error(),
+				ErrorCode: ErrorCode(http.StatusInternalServerError, replaceErr),
+				TradeID:   tradeID,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Protection moved",
+			Data:      trade,
+			TradeID:   tradeID,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// validateProtection checks new SL/TP prices against the symbol's price
+// filter and the current price: a stop that already crossed it would
+// trigger at once
+func validateProtection(ctx context.Context, bn *binance.Client, trade *models.Trade, req *models.TradeProtectionRequest) error {
+	if err := checkSymbolRules(ctx, bn, &models.TradeRequest{
+		Symbol:     trade.Symbol,
+		Side:       trade.Side,
+		OrderType:  "MARKET",
+		StopLoss:   req.StopLoss,
+		TakeProfit: req.TakeProfit,
+	}); err != nil {
+		return err
+	}
+
+	price, err := bn.GetPrice(ctx, trade.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get the current price: %v", err)
+	}
+
+	var errs validation.Errors
+	current := formatPrice(price)
+	long := trade.Side == "BUY"
+	if req.StopLoss > 0 && (long && req.StopLoss >= price || !long && req.StopLoss <= price) {
+		expected, suggestion := "< price "+current, "Use a stopLoss below "+current
+		if !long {
+			expected, suggestion = "> price "+current, "Use a stopLoss above "+current
+		}
+		errs.Add("stopLoss", validation.CodeConflict, expected,
+			fmt.Sprintf("stop loss %s would trigger at once at the current price %s", formatPrice(req.StopLoss), current), suggestion)
+	}
+	if req.TakeProfit > 0 && (long && req.TakeProfit <= price || !long && req.TakeProfit >= price) {
+		expected, suggestion := "> price "+current, "Use a takeProfit above "+current
+		if !long {
+			expected, suggestion = "< price "+current, "Use a takeProfit below "+current
+		}
+		errs.Add("takeProfit", validation.CodeConflict, expected,
+			fmt.Sprintf("take profit %s would trigger at once at the current price %s", formatPrice(req.TakeProfit), current), suggestion)
+	}
+	return errs.Err()
+}
+
+// pendingTrade loads a trade the caller may cancel or replace: their own,
+// QUEUED or ACTIVE. It responds 404, 403 or 409 otherwise.
+func pendingTrade(c *gin.Context, fb FirebaseInterface, tradeID string) (*models.Trade, bool) {
+	trade, err := fb.GetTrade(c.Request.Context(), tradeID)
+	if err != nil || trade == nil {
+		if err == nil {
+			err = fmt.Errorf("trade %s not found", tradeID)
+		}
+		c.JSON(http.StatusNotFound, models.TradeResponse{
+			Success:   false,
+			Message:   "Trade not found",
+			Error:     err.Error(),
+			Timestamp: time.Now().Unix(),
+		})
+		return nil, false
+	}
+
+	if !requireOwner(c, trade.UserID) {
+		return nil, false
+	}
+
+	if trade.Status != "QUEUED" && trade.Status != "ACTIVE" {
+		c.JSON(http.StatusConflict, models.TradeResponse{
+			Success:   false,
+			Message:   "Trade is not pending",
+			Error:     fmt.Sprintf("trade %s is %s; only QUEUED trades and ACTIVE trades with an unfilled entry can be changed", tradeID, trade.Status),
+			ErrorCode: models.ErrConflict,
+			TradeID:   tradeID,
+			Timestamp: time.Now().Unix(),
+		})
+		return nil, false
+	}
+	return trade, true
+}
+
+// TradeHistoryHandler - Get a trade's state history
+// @Summary      Get trade history
+// @Description  List every recorded state change of a trade, oldest first: status, order IDs, prices and what made the change
+// @Tags         Trading
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        tradeId  path      string  true  "Trade ID"
+// @Success      200      {object}  models.TradeResponse{data=[]models.TradeEvent}  "Trade history retrieved successfully"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      404      {object}  models.TradeResponse  "Trade not found"
+// @Failure      500      {object}  models.TradeResponse  "Failed to fetch trade history"
+// @Router       /api/trade/{tradeId}/history [get]
+func TradeHistoryHandler(fb FirebaseInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tradeID := c.Param("tradeId")
+
+		trade, err := getTradeOrArchived(c.Request.Context(), fb, tradeID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "Trade not found",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !requireOwner(c, trade.UserID) {
+			return
+		}
+
+		events, err := fb.GetTradeEvents(c.Request.Context(), tradeID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to fetch trade history",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("%d events", len(events)),
+			Data:      events,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// getTradeOrArchived looks a trade up among the current trades, then in the
+// archive
+func getTradeOrArchived(ctx context.Context, fb FirebaseInterface, tradeID string) (*models.Trade, error) {
+	trade, err := fb.GetTrade(ctx, tradeID)
+	if err == nil {
+		return trade, nil
+	}
+	if archived, archiveErr := fb.GetArchivedTrade(ctx, tradeID); archiveErr == nil {
+		return archived, nil
+	}
+	return nil, err
+}
+
+// validateTradeParams checks a trade request after presets and user defaults
+// were applied, reporting every invalid field
 func validateTradeParams(req *models.TradeRequest) error {
+	var errs validation.Errors
+
+	if req.UserID == "" {
+		errs.Add("userId", validation.CodeRequired, "", "userId is required", "Add the userId the trade is for")
+	}
+
 	if req.Side != "BUY" && req.Side != "SELL" {
-		return fmt.Errorf("side must be BUY or SELL")
+		suggestion := "Use BUY or SELL"
+		if upper := strings.ToUpper(req.Side); upper == "BUY" || upper == "SELL" {
+			suggestion = "Use " + upper
+		}
+		errs.Add("side", validation.CodeInvalid, "BUY or SELL", "side must be BUY or SELL", suggestion)
 	}
 
 	if req.EntryPrice <= 0 {
-		return fmt.Errorf("entry price must be greater than 0")
+		errs.Add("entryPrice", validation.CodeRange, "> 0", "entry price must be greater than 0", "Send the price the signal fired at")
 	}
 
-	if req.Side == "BUY" {
-		if req.StopLoss >= req.EntryPrice {
-			return fmt.Errorf("stop loss must be less than entry price for BUY")
-		}
-		if req.TakeProfit <= req.EntryPrice {
-			return fmt.Errorf("take profit must be greater than entry price for BUY")
+	if req.WorkingType != "" && req.WorkingType != models.WorkingTypeMark && req.WorkingType != models.WorkingTypeLast {
+		suggestion := "Use MARK_PRICE or LAST_PRICE"
+		if upper := strings.ToUpper(req.WorkingType); upper == models.WorkingTypeMark || upper == models.WorkingTypeLast {
+			suggestion = "Use " + upper
 		}
-	} else {
-		if req.StopLoss <= req.EntryPrice {
-			return fmt.Errorf("stop loss must be greater than entry price for SELL")
-		}
-		if req.TakeProfit >= req.EntryPrice {
-			return fmt.Errorf("take profit must be less than entry price for SELL")
+		errs.Add("workingType", validation.CodeInvalid, "MARK_PRICE or LAST_PRICE", "workingType must be MARK_PRICE or LAST_PRICE", suggestion)
+	}
+
+	if req.Leverage < 1 || req.Leverage > 125 {
+		errs.Add("leverage", validation.CodeRange, "1-125", "leverage must be between 1 and 125",
+			"Set leverage, or reference a preset or user default that sets it")
+	}
+
+	if req.Size <= 0 {
+		errs.Add("size", validation.CodeRange, "> 0", "size must be greater than 0",
+			"Set size in USDT, or reference a preset or user default that sets it")
+	}
+
+	if req.StopLoss <= 0 {
+		errs.Add("stopLoss", validation.CodeRequired, "", "stop loss is required",
+			"Set stopLoss, or reference a preset that sets stopLossPercent")
+	}
+	if req.TakeProfit <= 0 {
+		errs.Add("takeProfit", validation.CodeRequired, "", "take profit is required",
+			"Set takeProfit, or reference a preset that sets takeProfitPercent")
+	}
+
+	// Stops on the right side of the entry
+	if req.EntryPrice > 0 {
+		entry := formatPrice(req.EntryPrice)
+		switch req.Side {
+		case "BUY":
+			if req.StopLoss > 0 && req.StopLoss >= req.EntryPrice {
+				errs.Add("stopLoss", validation.CodeConflict, "< entryPrice "+entry,
+					"stop loss must be less than entry price for BUY", "Use a stopLoss below "+entry)
+			}
+			if req.TakeProfit > 0 && req.TakeProfit <= req.EntryPrice {
+				errs.Add("takeProfit", validation.CodeConflict, "> entryPrice "+entry,
+					"take profit must be greater than entry price for BUY", "Use a takeProfit above "+entry)
+			}
+		case "SELL":
+			if req.StopLoss > 0 && req.StopLoss <= req.EntryPrice {
+				errs.Add("stopLoss", validation.CodeConflict, "> entryPrice "+entry,
+					"stop loss must be greater than entry price for SELL", "Use a stopLoss above "+entry)
+			}
+			if req.TakeProfit > 0 && req.TakeProfit >= req.EntryPrice {
+				errs.Add("takeProfit", validation.CodeConflict, "< entryPrice "+entry,
+					"take profit must be less than entry price for SELL", "Use a takeProfit below "+entry)
+			}
 		}
 	}
 
-	return nil
+	return errs.Err()
+}
+
+// formatPrice prints a price without trailing zeros
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', -1, 64)
 }
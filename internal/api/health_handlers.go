@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/storage"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// readinessTimeout bounds each dependency check of /health/ready
+	readinessTimeout = 5 * time.Second
+
+	// maxClockDrift is the largest drift (ms) of the compensated clock at
+	// which signed requests are still safely inside Binance's window
+	maxClockDrift = 1000
+)
+
+// Dependency check results
+const (
+	checkOK       = "ok"
+	checkFailed   = "fail"
+	checkDisabled = "disabled"
+)
+
+// DependencyCheck is the result of one readiness check
+type DependencyCheck struct {
+	Status    string                 `json:"status" example:"ok"` // ok, fail or disabled
+	LatencyMs int64                  `json:"latencyMs" example:"42"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// LivenessHandler godoc
+// @Summary      Liveness probe
+// @Description  Reports that the process is up and serving requests. Never checks dependencies, so a Binance or Firebase outage does not get the process restarted.
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "Process is up"
+// @Router       /health/live [get]
+func LivenessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "alive",
+		"uptime": time.Now().Unix() - serverStartTime,
+		"time":   time.Now().Unix(),
+	})
+}
+
+// ReadinessHandler godoc
+// @Summary      Readiness probe
+// @Description  Checks, in parallel, that trading can actually function: Binance is reachable, storage is reachable and accepts writes, the user data stream is connected and the clock used for signed requests is in sync. Returns 503 with the failing checks otherwise, and while the server is shutting down.
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "Ready: every check passed"
+// @Failure      503  {object}  map[string]interface{}  "Not ready: see checks"
+// @Router       /health/ready [get]
+func ReadinessHandler(fb storage.TradeStore, bn *binance.Client, streams *binance.WebSocketManager, intake *TradeIntake) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Take the instance out of rotation while it drains
+		if intake.Draining() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "shutting_down",
+				"time":   time.Now().Unix(),
+			})
+			return
+		}
+
+		checks := map[string]func(ctx context.Context) DependencyCheck{
+			"binance":        func(ctx context.Context) DependencyCheck { return checkBinance(ctx, bn) },
+			"storage":        func(ctx context.Context) DependencyCheck { return checkStorage(ctx, fb) },
+			"userDataStream": func(ctx context.Context) DependencyCheck { return checkUserDataStream(streams) },
+			"clock":          func(ctx context.Context) DependencyCheck { return checkClock(ctx, bn) },
+		}
+
+		results := make(map[string]DependencyCheck, len(checks))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for name, check := range checks {
+			wg.Add(1)
+			go func(name string, check func(ctx context.Context) DependencyCheck) {
+				defer wg.Done()
+
+				ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+				defer cancel()
+				result := check(ctx)
+
+				mu.Lock()
+				results[name] = result
+				mu.Unlock()
+			}(name, check)
+		}
+		wg.Wait()
+
+		status, code := "ready", http.StatusOK
+		for _, result := range results {
+			if result.Status == checkFailed {
+				status, code = "not_ready", http.StatusServiceUnavailable
+				break
+			}
+		}
+
+		c.JSON(code, gin.H{
+			"status": status,
+			"checks": results,
+			"time":   time.Now().Unix(),
+		})
+	}
+}
+
+// timedCheck runs fn and records its latency and error
+func timedCheck(fn func() error) DependencyCheck {
+	start := time.Now()
+	err := fn()
+	result := DependencyCheck{Status: checkOK, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = checkFailed
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// checkBinance calls the public server time endpoint
+func checkBinance(ctx context.Context, bn *binance.Client) DependencyCheck {
+	return timedCheck(func() error {
+		_, err := bn.GetBinanceServerTime(ctx)
+		return err
+	})
+}
+
+// checkStorage writes a probe record, bypassing the write queue
+func checkStorage(ctx context.Context, fb storage.TradeStore) DependencyCheck {
+	result := timedCheck(func() error {
+		return fb.Ping(ctx)
+	})
+	if writeBehind, ok := fb.(*storage.WriteBehind); ok {
+		backlog := writeBehind.Backlog()
+		result.Details = map[string]interface{}{"writeBehind": backlog}
+
+		// Writes are never dropped, so one that keeps failing stays visible
+		// here until it is stored
+		if result.Status == checkOK && (backlog.Failing > 0 || backlog.Unpersisted > 0) {
+			result.Status = checkFailed
+			result.Error = fmt.Sprintf("%d trade writes failing, %d not journaled: %s", backlog.Failing, backlog.Unpersisted, backlog.LastError)
+		}
+	}
+	return result
+}
+
+// checkUserDataStream reports the user data stream connection; trade
+// monitors only learn about fills from it
+func checkUserDataStream(streams *binance.WebSocketManager) DependencyCheck {
+	if !streams.Running() {
+		return DependencyCheck{Status: checkDisabled}
+	}
+
+	connected, lastEvent := streams.UserDataStreamConnected()
+	result := DependencyCheck{Status: checkOK, Details: map[string]interface{}{"lastEvent": ""}}
+	if !lastEvent.IsZero() {
+		result.Details["lastEvent"] = lastEvent.Format(time.RFC3339)
+	}
+	if !connected {
+		result.Status = checkFailed
+		result.Error = "user data stream is not connected"
+	}
+	return result
+}
+
+// checkClock measures the drift of the clock used for signed requests
+func checkClock(ctx context.Context, bn *binance.Client) DependencyCheck {
+	var drift int64
+	result := timedCheck(func() error {
+		var err error
+		if drift, err = bn.ClockDrift(ctx); err != nil {
+			return err
+		}
+		if drift > maxClockDrift || drift < -maxClockDrift {
+			return fmt.Errorf("clock drift %dms exceeds %dms", drift, maxClockDrift)
+		}
+		return nil
+	})
+	result.Details = map[string]interface{}{
+		"offsetMs":     binance.TimeOffset(),
+		"driftMs":      drift,
+		"recvWindowMs": binance.RecvWindow(),
+	}
+	return result
+}
@@ -0,0 +1,317 @@
+package api
+
+import (
+	"crypto-trading-api/internal/analytics"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Exchange history ranges: the default when from is omitted, and the
+// longest allowed (each week of each symbol costs Binance requests)
+const (
+	defaultHistoryRange = 7 * 24 * time.Hour
+	maxHistoryRange     = 90 * 24 * time.Hour
+)
+
+// PositionHistoryHandler - Closed positions rebuilt from Binance fills
+// @Summary      Get position history
+// @Description  Reconstruct closed positions (entry, exit, duration, realized PnL, fees, funding) from Binance account trades and income history, independent of stored trades, so positions opened outside this API are included. Positions still open at the end of the range, or opened before its start, are left out.
+// @Tags         Positions
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  query     string  false  "User whose own Binance account to query (default: operator account)"
+// @Param        account query     string  false  "Operator account to query: main or a BINANCE_ACCOUNTS name (default: as userId selects)"
+// @Param        symbol  query     string  false  "Symbol, e.g. BTCUSDT (default: every symbol with realized PnL in the range)"
+// @Param        from    query     int     false  "Range start, Unix seconds (default: 7 days before to)"
+// @Param        to      query     int     false  "Range end, Unix seconds (default: now; at most 90 days after from)"
+// @Success      200     {object}  models.TradeResponse{data=[]binance.ClosedPosition}  "Closed positions, most recently closed first"
+// @Failure      400     {object}  models.TradeResponse  "Invalid parameters"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403     {object}  models.TradeResponse  "No Binance account for user"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get position history"
+// @Router       /api/positions/history [get]
+func PositionHistoryHandler(clients *binance.ClientPool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, account := c.Query("userId"), c.Query("account")
+		if !claimOwnership(c, &userID) || !requireAccountAccess(c, account) {
+			return
+		}
+
+		from, to, ok := historyRange(c)
+		if !ok {
+			return
+		}
+
+		bn, ok := userClient(c, clients, userID, account)
+		if !ok {
+			return
+		}
+
+		positions, err := bn.GetPositionHistory(c.Request.Context(), strings.ToUpper(c.Query("symbol")), from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get position history",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("%d closed positions", len(positions)),
+			Data:      positions,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// IncomeHistoryHandler - Balance changes of every type from Binance
+// @Summary      Get income history
+// @Description  List Binance income history entries of all types (TRANSFER, COMMISSION, FUNDING_FEE, REALIZED_PNL, ...), with totals by type and asset and per-day totals (UTC), so deposits, withdrawals, fees and funding can be reconciled with PnL.
+// @Tags         Analytics
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  query     string  false  "User whose own Binance account to query (default: operator account)"
+// @Param        account query     string  false  "Operator account to query: main or a BINANCE_ACCOUNTS name (default: as userId selects)"
+// @Param        type    query     string  false  "Comma-separated income types, e.g. TRANSFER,FUNDING_FEE (default: all)"
+// @Param        symbol  query     string  false  "Symbol, e.g. BTCUSDT (transfers have none)"
+// @Param        from    query     int     false  "Range start, Unix seconds (default: 7 days before to)"
+// @Param        to      query     int     false  "Range end, Unix seconds (default: now; at most 90 days after from)"
+// @Success      200     {object}  models.TradeResponse{data=analytics.IncomeHistory}  "Income history"
+// @Failure      400     {object}  models.TradeResponse  "Invalid parameters"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403     {object}  models.TradeResponse  "No Binance account for user"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get income history"
+// @Router       /api/account/income [get]
+func IncomeHistoryHandler(clients *binance.ClientPool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, account := c.Query("userId"), c.Query("account")
+		if !claimOwnership(c, &userID) || !requireAccountAccess(c, account) {
+			return
+		}
+
+		from, to, ok := historyRange(c)
+		if !ok {
+			return
+		}
+
+		types := make(map[string]bool)
+		if c.Query("type") != "" {
+			for _, incomeType := range strings.Split(strings.ToUpper(c.Query("type")), ",") {
+				types[strings.TrimSpace(incomeType)] = true
+			}
+		}
+		symbol := strings.ToUpper(c.Query("symbol"))
+
+		bn, ok := userClient(c, clients, userID, account)
+		if !ok {
+			return
+		}
+
+		// One type can be filtered by Binance, several only here
+		fetchType := ""
+		if len(types) == 1 {
+			for incomeType := range types {
+				fetchType = incomeType
+			}
+		}
+
+		records, err := bn.GetIncomeRecordsRange(c.Request.Context(), fetchType, from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get income history",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		filtered := records[:0]
+		for _, record := range records {
+			if len(types) > 0 && !types[record.IncomeType] {
+				continue
+			}
+			if symbol != "" && record.Symbol != symbol {
+				continue
+			}
+			filtered = append(filtered, record)
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("%d income entries", len(filtered)),
+			Data:      analytics.BuildIncomeHistory(from, to, filtered),
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// orderStatuses are the statuses Binance reports for futures orders
+var orderStatuses = []string{"NEW", "PARTIALLY_FILLED", "FILLED", "CANCELED", "REJECTED", "EXPIRED", "EXPIRED_IN_MATCH"}
+
+// OrderHistoryHandler - Past and open orders of a symbol from Binance
+// @Summary      Get order history
+// @Description  Page through a symbol's orders from Binance's all-orders history, oldest first, optionally filtered by status, e.g. to audit SL/TP orders that were filled, cancelled or expired. Pass nextCursor from a page as cursor to get the next one. Binance keeps cancelled and expired orders without fills for 3 days only, other orders for 90 days.
+// @Tags         Orders
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  query     string  false  "User whose own Binance account to query (default: operator account)"
+// @Param        account query     string  false  "Operator account to query: main or a BINANCE_ACCOUNTS name (default: as userId selects)"
+// @Param        symbol  query     string  true   "Symbol, e.g. BTCUSDT"
+// @Param        status  query     string  false  "Comma-separated statuses: NEW, PARTIALLY_FILLED, FILLED, CANCELED, REJECTED, EXPIRED, EXPIRED_IN_MATCH"
+// @Param        from    query     int     false  "Created at or after, Unix seconds (default: 7 days before to)"
+// @Param        to      query     int     false  "Created at or before, Unix seconds (default: now; at most 90 days after from)"
+// @Param        limit   query     int     false  "Page size, 1-500 (default: 100)"
+// @Param        cursor  query     string  false  "nextCursor of the previous page"
+// @Success      200     {object}  models.TradeResponse{data=object}  "Orders retrieved successfully"
+// @Failure      400     {object}  models.TradeResponse  "Invalid parameters"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403     {object}  models.TradeResponse  "No Binance account for user"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get order history"
+// @Router       /api/orders/history [get]
+func OrderHistoryHandler(clients *binance.ClientPool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, account := c.Query("userId"), c.Query("account")
+		if !claimOwnership(c, &userID) || !requireAccountAccess(c, account) {
+			return
+		}
+
+		from, to, ok := historyRange(c)
+		if !ok {
+			return
+		}
+
+		query := binance.OrderHistoryQuery{
+			Symbol:    strings.ToUpper(c.Query("symbol")),
+			StartTime: from,
+			EndTime:   to,
+		}
+		if c.Query("status") != "" {
+			for _, status := range strings.Split(strings.ToUpper(c.Query("status")), ",") {
+				query.Statuses = append(query.Statuses, strings.TrimSpace(status))
+			}
+		}
+
+		var errLimit, errCursor error
+		query.Limit, errLimit = strconv.Atoi(c.DefaultQuery("limit", "100"))
+		if c.Query("cursor") != "" {
+			query.After, errCursor = binance.ParseOrderCursor(c.Query("cursor"))
+		}
+		if query.Symbol == "" || errLimit != nil || errCursor != nil || query.Limit < 1 || query.Limit > 500 || !knownOrderStatuses(query.Statuses) {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     "symbol is required, status a comma-separated list of " + strings.Join(orderStatuses, ", ") + ", limit 1-500, cursor a nextCursor value",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		bn, ok := userClient(c, clients, userID, account)
+		if !ok {
+			return
+		}
+
+		page, err := bn.GetOrderHistory(c.Request.Context(), query)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get order history",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		orderDetails := []gin.H{}
+		for _, order := range page.Orders {
+			orderDetails = append(orderDetails, gin.H{
+				"orderId":       order.OrderID,
+				"clientOrderId": order.ClientOrderID,
+				"symbol":        order.Symbol,
+				"side":          order.Side,
+				"positionSide":  order.PositionSide,
+				"type":          order.Type,
+				"origType":      order.OrigType,
+				"price":         order.Price,
+				"stopPrice":     order.StopPrice,
+				"avgPrice":      order.AvgPrice,
+				"quantity":      order.OrigQuantity,
+				"executedQty":   order.ExecutedQuantity,
+				"status":        order.Status,
+				"timeInForce":   order.TimeInForce,
+				"reduceOnly":    order.ReduceOnly,
+				"closePosition": order.ClosePosition,
+				"createdTime":   order.Time,
+				"updateTime":    order.UpdateTime,
+			})
+		}
+
+		data := gin.H{
+			"totalOrders": len(orderDetails),
+			"orders":      orderDetails,
+		}
+		if page.NextCursor != "" {
+			data["nextCursor"] = page.NextCursor
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Order history retrieved successfully",
+			Data:      data,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// knownOrderStatuses reports whether every status is a Binance order status
+func knownOrderStatuses(statuses []string) bool {
+	for _, status := range statuses {
+		known := false
+		for _, valid := range orderStatuses {
+			if status == valid {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return false
+		}
+	}
+	return true
+}
+
+// historyRange parses the from/to query (Unix seconds), writing a 400
+// response when it is invalid or longer than maxHistoryRange
+func historyRange(c *gin.Context) (int64, int64, bool) {
+	to := time.Now().Unix()
+	var errFrom, errTo error
+	if c.Query("to") != "" {
+		to, errTo = strconv.ParseInt(c.Query("to"), 10, 64)
+	}
+	from := to - int64(defaultHistoryRange.Seconds())
+	if c.Query("from") != "" {
+		from, errFrom = strconv.ParseInt(c.Query("from"), 10, 64)
+	}
+
+	if errFrom != nil || errTo != nil || from > to || to-from > int64(maxHistoryRange.Seconds()) {
+		c.JSON(http.StatusBadRequest, models.TradeResponse{
+			Success:   false,
+			Message:   "Invalid parameters",
+			Error:     "from/to must be Unix seconds with from <= to, at most 90 days apart",
+			Timestamp: time.Now().Unix(),
+		})
+		return 0, 0, false
+	}
+	return from, to, true
+}
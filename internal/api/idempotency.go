@@ -0,0 +1,348 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto-trading-api/internal/cluster"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader names the header a client sets to make a POST safe
+	// to retry
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotencyReplayedHeader marks a response replayed from a stored record
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKey      = 255
+	maxIdempotencyResponse = 1 << 20 // Larger responses are not stored
+
+	// idempotencySecretKey is set on the context of a response that carries
+	// a secret (see withholdResponse)
+	idempotencySecretKey = "idempotency_secret"
+
+	// idempotencyLockTTL bounds how long a key stays claimed by a request
+	// whose instance died before storing the response
+	idempotencyLockTTL = 2 * time.Minute
+)
+
+// IdempotencyStore persists replayable responses (the trade store)
+type IdempotencyStore interface {
+	SaveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error
+	GetIdempotencyRecord(ctx context.Context, key string) (*models.IdempotencyRecord, error)
+}
+
+// IdempotencyCache keeps replayable responses with an expiry, shared by every
+// server instance (Redis)
+type IdempotencyCache interface {
+	GetJSON(ctx context.Context, key string, value interface{}) (bool, error)
+	SetJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+}
+
+// IdempotencyConfig configures Idempotency
+type IdempotencyConfig struct {
+	TTL    time.Duration    // How long a response is replayed; 0 disables the middleware
+	Store  IdempotencyStore // Used when Shared is nil; expired records are ignored
+	Shared IdempotencyCache // Redis; records expire on their own
+	Locker *cluster.Locker  // Claims a key across instances while its first request runs
+}
+
+// Idempotency replays the stored response when a POST is retried with the
+// same Idempotency-Key, so a webhook retried by a proxy or a client timeout
+// cannot open a second position.
+type Idempotency struct {
+	config IdempotencyConfig
+
+	mu       sync.Mutex
+	inFlight map[string]bool // Keys whose first request is running on this instance
+}
+
+// NewIdempotency creates the idempotency middleware's state
+func NewIdempotency(config IdempotencyConfig) *Idempotency {
+	return &Idempotency{
+		config:   config,
+		inFlight: make(map[string]bool),
+	}
+}
+
+// Middleware handles POST requests carrying an Idempotency-Key header: the
+// first response is stored for the TTL and replayed, with an
+// Idempotent-Replayed header, for retries by the same caller. A retry while
+// the first request is still running gets 409, and reusing a key for a
+// different request gets 422. Needs the caller from auth.
+func (i *Idempotency) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if i.config.TTL <= 0 || c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid Idempotency-Key",
+				"error":   fmt.Sprintf("key is longer than %d characters", maxIdempotencyKey),
+			})
+			c.Abort()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			body, _ = io.ReadAll(c.Request.Body)
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+		}
+
+		recordKey := hashParts(auditActor(c), key)
+		fingerprint := hashParts(c.Request.Method, unversionedPath(c), string(body))
+		ctx := c.Request.Context()
+
+		if record := i.lookup(ctx, recordKey); record != nil {
+			i.replay(c, record, fingerprint)
+			return
+		}
+
+		if !i.claim(ctx, recordKey) {
+			c.Header("Retry-After", "1")
+			c.JSON(http.StatusConflict, gin.H{
+				"success": false,
+				"message": "Request in progress",
+				"error":   "a request with this Idempotency-Key is still being processed, retry shortly",
+			})
+			c.Abort()
+			return
+		}
+
+		// The first request may have finished between the lookup and the claim
+		if record := i.lookup(ctx, recordKey); record != nil {
+			i.release(recordKey)
+			i.replay(c, record, fingerprint)
+			return
+		}
+
+		writer := &idempotentResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		if !writer.storable() {
+			i.release(recordKey)
+			return
+		}
+
+		now := time.Now()
+		record := &models.IdempotencyRecord{
+			Key:         recordKey,
+			Fingerprint: fingerprint,
+			Status:      writer.Status(),
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.String(),
+			CreatedAt:   now.Unix(),
+			ExpiresAt:   now.Add(i.config.TTL).Unix(),
+		}
+		// Secrets (API keys, webhook secrets) are never stored: a retry only
+		// learns that the request succeeded
+		if c.GetBool(idempotencySecretKey) {
+			record.ContentType = ""
+			record.Body = ""
+			record.Withheld = true
+		}
+
+		// Keep the key claimed until the record is stored, so a retry never
+		// runs the request again
+		method, path := c.Request.Method, c.Request.URL.Path
+		go func() {
+			defer i.release(recordKey)
+			if err := i.save(context.Background(), record); err != nil {
+				logging.Warn().Err(err).Msgf("Idempotency: failed to store response for %s %s", method, path)
+			}
+		}()
+	}
+}
+
+// replay writes a stored response, or 422 when the key was first used for a
+// different request
+func (i *Idempotency) replay(c *gin.Context, record *models.IdempotencyRecord, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"success": false,
+			"message": "Idempotency-Key reused",
+			"error":   "this Idempotency-Key was already used for a different request",
+		})
+		c.Abort()
+		return
+	}
+
+	c.Header(idempotencyReplayedHeader, "true")
+	if record.Withheld {
+		c.JSON(record.Status, models.TradeResponse{
+			Success:   record.Status < http.StatusBadRequest,
+			Message:   "Request already processed",
+			Error:     "the original response carried a secret, which is not stored for replay",
+			Timestamp: time.Now().Unix(),
+		})
+		c.Abort()
+		return
+	}
+
+	contentType := record.ContentType
+	if contentType == "" {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Data(record.Status, contentType, []byte(record.Body))
+	c.Abort()
+}
+
+// withholdResponse keeps the response of a request that issues a secret
+// out of idempotency records; only its status is replayed
+func withholdResponse(c *gin.Context) {
+	c.Set(idempotencySecretKey, true)
+}
+
+// lookup returns the unexpired record for a key, nil if there is none or
+// storage fails (the request then runs normally)
+func (i *Idempotency) lookup(ctx context.Context, key string) *models.IdempotencyRecord {
+	var record *models.IdempotencyRecord
+	if i.config.Shared != nil {
+		var cached models.IdempotencyRecord
+		found, err := i.config.Shared.GetJSON(ctx, "idempotency:"+key, &cached)
+		if err != nil {
+			logging.Warn().Err(err).Msg("Idempotency: failed to read shared record")
+			return nil
+		}
+		if found {
+			record = &cached
+		}
+	} else if i.config.Store != nil {
+		stored, err := i.config.Store.GetIdempotencyRecord(ctx, key)
+		if err != nil {
+			logging.Warn().Err(err).Msg("Idempotency: failed to read record")
+			return nil
+		}
+		record = stored
+	}
+
+	if record == nil || record.ExpiresAt <= time.Now().Unix() {
+		return nil
+	}
+	return record
+}
+
+// save stores a record until it expires
+func (i *Idempotency) save(ctx context.Context, record *models.IdempotencyRecord) error {
+	if i.config.Shared != nil {
+		return i.config.Shared.SetJSON(ctx, "idempotency:"+record.Key, record, i.config.TTL)
+	}
+	if i.config.Store != nil {
+		return i.config.Store.SaveIdempotencyRecord(ctx, record)
+	}
+	return nil
+}
+
+// claim marks a key in flight on this instance and, with shared locks, on
+// every instance. A failing lock store does not block the request.
+func (i *Idempotency) claim(ctx context.Context, key string) bool {
+	i.mu.Lock()
+	if i.inFlight[key] {
+		i.mu.Unlock()
+		return false
+	}
+	i.inFlight[key] = true
+	i.mu.Unlock()
+
+	if i.config.Locker == nil {
+		return true
+	}
+	acquired, err := i.config.Locker.TryLock(ctx, "idempotency:"+key, idempotencyLockTTL)
+	if err != nil {
+		logging.Warn().Err(err).Msg("Idempotency: failed to claim key")
+		return true
+	}
+	if !acquired {
+		i.mu.Lock()
+		delete(i.inFlight, key)
+		i.mu.Unlock()
+	}
+	return acquired
+}
+
+// release frees a claimed key
+func (i *Idempotency) release(key string) {
+	if i.config.Locker != nil {
+		if err := i.config.Locker.Unlock(context.Background(), "idempotency:"+key); err != nil {
+			logging.Warn().Err(err).Msg("Idempotency: failed to release key")
+		}
+	}
+
+	i.mu.Lock()
+	delete(i.inFlight, key)
+	i.mu.Unlock()
+}
+
+// idempotentResponseWriter keeps a copy of the response to store
+type idempotentResponseWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *idempotentResponseWriter) Write(b []byte) (int, error) {
+	if w.body.Len()+len(b) <= maxIdempotencyResponse {
+		w.body.Write(b)
+	} else {
+		w.overflow = true
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotentResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// storable reports whether the response should be replayed. 429 means the
+// request was turned away before doing anything, and 5xx that it failed, so
+// a retry must run it again.
+func (w *idempotentResponseWriter) storable() bool {
+	if w.overflow {
+		return false
+	}
+	return w.Status() != http.StatusTooManyRequests && w.Status() < http.StatusInternalServerError
+}
+
+// unversionedPath is the request path and query with /api/v1 shortened to
+// /api, so a retry may switch between the two
+func unversionedPath(c *gin.Context) string {
+	path := c.Request.URL.Path
+	if rest, ok := strings.CutPrefix(path, versionedAPIPrefix+"/"); ok {
+		path = legacyAPIPrefix + "/" + rest
+	}
+	if c.Request.URL.RawQuery != "" {
+		path += "?" + c.Request.URL.RawQuery
+	}
+	return path
+}
+
+// hashParts is the hex SHA-256 of the parts, separated so that no two
+// different part lists hash alike
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(strconv.Itoa(len(part))))
+		h.Write([]byte{':'})
+		h.Write([]byte(part))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
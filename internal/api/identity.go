@@ -0,0 +1,94 @@
+package api
+
+import (
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authenticatedUser returns the user a JWT-authenticated request acts as
+// ("" for API key and signed requests, which may act for any user)
+func authenticatedUser(c *gin.Context) string {
+	return c.GetString(authUserIDKey)
+}
+
+// claimOwnership fills an empty userID with the token's user and rejects
+// (403) a non-admin token holder acting for another user
+func claimOwnership(c *gin.Context, userID *string) bool {
+	caller := authenticatedUser(c)
+	if caller == "" {
+		return true
+	}
+
+	if *userID == "" {
+		*userID = caller
+		return true
+	}
+	return requireOwner(c, *userID)
+}
+
+// requireOwner rejects (403) a token holder accessing another user's
+// resource, unless the holder is an admin
+func requireOwner(c *gin.Context, owner string) bool {
+	caller := authenticatedUser(c)
+	if caller == "" || caller == owner || c.GetString(authRoleKey) == models.RoleAdmin {
+		return true
+	}
+
+	c.JSON(http.StatusForbidden, models.TradeResponse{
+		Success:   false,
+		Message:   "Forbidden",
+		Error:     fmt.Sprintf("authenticated user %s cannot act for user %s", caller, owner),
+		Timestamp: time.Now().Unix(),
+	})
+	return false
+}
+
+// requireAccountAccess rejects (403) a non-admin token holder selecting an
+// operator account, which is left to API key, signed and admin requests
+func requireAccountAccess(c *gin.Context, account string) bool {
+	if account == "" || authenticatedUser(c) == "" || c.GetString(authRoleKey) == models.RoleAdmin {
+		return true
+	}
+
+	c.JSON(http.StatusForbidden, models.TradeResponse{
+		Success:   false,
+		Message:   "Forbidden",
+		Error:     fmt.Sprintf("authenticated user %s cannot select account %s", authenticatedUser(c), account),
+		Timestamp: time.Now().Unix(),
+	})
+	return false
+}
+
+// requireAccountWide rejects (403) a non-admin token holder reading data
+// aggregated over every user, which cannot be narrowed to one
+func requireAccountWide(c *gin.Context) bool {
+	if authenticatedUser(c) == "" || c.GetString(authRoleKey) == models.RoleAdmin {
+		return true
+	}
+
+	c.JSON(http.StatusForbidden, models.TradeResponse{
+		Success:   false,
+		Message:   "Forbidden",
+		Error:     fmt.Sprintf("authenticated user %s cannot read account-wide reports", authenticatedUser(c)),
+		Timestamp: time.Now().Unix(),
+	})
+	return false
+}
+
+// tradeAccount is the operator account a recorded trade was placed on, as
+// ClientPool.Resolve takes it: "" for trades on a user's own keys
+func tradeAccount(trade *models.Trade) string {
+	switch {
+	case trade.Account == "":
+		return binance.PrimaryAccount
+	case strings.HasPrefix(trade.Account, models.UserAccount("")):
+		return ""
+	}
+	return trade.Account
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/validation"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxJournalNotes       = 10000
+	maxJournalTags        = 20
+	maxJournalScreenshots = 10
+)
+
+// UpdateTradeJournalHandler - Attach notes, tags and screenshots to a trade
+// @Summary      Update trade journal
+// @Description  Attach free-text notes, tags and external chart-image URLs to a trade. Only the fields present in the body are changed; send an empty list to clear tags or screenshots.
+// @Tags         Trading
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        tradeId  path      string                      true  "Trade ID"
+// @Param        journal  body      models.TradeJournalRequest  true  "Journal fields to update"
+// @Success      200      {object}  models.TradeResponse{data=models.Trade}  "Journal updated"
+// @Failure      400      {object}  models.TradeResponse  "Invalid journal entry"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403      {object}  models.TradeResponse  "Trade belongs to another user"
+// @Failure      404      {object}  models.TradeResponse  "Trade not found"
+// @Failure      500      {object}  models.TradeResponse  "Failed to save journal"
+// @Router       /api/trade/{tradeId}/journal [patch]
+func UpdateTradeJournalHandler(fb FirebaseInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tradeID := c.Param("tradeId")
+
+		var req models.TradeJournalRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if err := validateJournalRequest(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid journal entry",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		trade, err := fb.GetTrade(c.Request.Context(), tradeID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "Trade not found",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !requireOwner(c, trade.UserID) {
+			return
+		}
+
+		if trade.Journal == nil {
+			trade.Journal = &models.TradeJournal{}
+		}
+		if req.Notes != nil {
+			trade.Journal.Notes = *req.Notes
+		}
+		if req.Tags != nil {
+			trade.Journal.Tags = normalizeTags(req.Tags)
+		}
+		if req.Screenshots != nil {
+			trade.Journal.Screenshots = req.Screenshots
+		}
+		trade.Journal.UpdatedAt = time.Now().Unix()
+
+		if err := fb.UpdateTrade(c.Request.Context(), trade); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to save journal",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			TradeID:   trade.ID,
+			Message:   "Trade journal updated successfully",
+			Data:      trade,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// Validate journal fields before touching the trade
+func validateJournalRequest(req *models.TradeJournalRequest) error {
+	if req.Notes != nil && len(*req.Notes) > maxJournalNotes {
+		return fmt.Errorf("notes must be at most %d characters", maxJournalNotes)
+	}
+
+	if len(req.Tags) > maxJournalTags {
+		return fmt.Errorf("at most %d tags are allowed", maxJournalTags)
+	}
+	for _, tag := range req.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("tags must not be empty")
+		}
+	}
+
+	if len(req.Screenshots) > maxJournalScreenshots {
+		return fmt.Errorf("at most %d screenshots are allowed", maxJournalScreenshots)
+	}
+	for _, link := range req.Screenshots {
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid screenshot URL: %s", link)
+		}
+	}
+
+	return nil
+}
+
+// normalizeTags lowercases, trims and de-duplicates tags
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+
+	return result
+}
+
+// filterTradesByTag keeps trades whose journal carries the tag
+func filterTradesByTag(trades []*models.Trade, tag string) []*models.Trade {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	filtered := []*models.Trade{}
+
+	for _, trade := range trades {
+		if trade.Journal == nil {
+			continue
+		}
+		for _, t := range trade.Journal.Tags {
+			if t == tag {
+				filtered = append(filtered, trade)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
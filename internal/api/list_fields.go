@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"crypto-trading-api/internal/models"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fieldSelection is the set of fields a list endpoint returns per item, from
+// its fields= parameter, so mobile clients can ask for slim payloads. nil
+// selects every field.
+type fieldSelection map[string]bool
+
+// parseFields reads fields=a,b,c. It fails on names not among known.
+func parseFields(c *gin.Context, known []string) (fieldSelection, error) {
+	if c.Query("fields") == "" {
+		return nil, nil
+	}
+	valid := make(map[string]bool, len(known))
+	for _, name := range known {
+		valid[name] = true
+	}
+
+	selection := fieldSelection{}
+	var unknown []string
+	for _, name := range strings.Split(c.Query("fields"), ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+		case !valid[name]:
+			unknown = append(unknown, name)
+		default:
+			selection[name] = true
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown fields %s, available: %s", strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	if len(selection) == 0 {
+		return nil, nil
+	}
+	return selection, nil
+}
+
+// pick keeps the selected fields of item (all of them for a nil selection).
+// Fields the item omits stay omitted.
+func (f fieldSelection) pick(item gin.H) gin.H {
+	if f == nil {
+		return item
+	}
+	picked := make(gin.H, len(f))
+	for name := range f {
+		if value, ok := item[name]; ok {
+			picked[name] = value
+		}
+	}
+	return picked
+}
+
+// itemFields lists the fields of an item, sorted
+func itemFields(item gin.H) []string {
+	names := make([]string, 0, len(item))
+	for name := range item {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tradeFields lists the JSON fields of a trade, including those omitted
+// when empty
+var tradeFields = jsonFields(reflect.TypeOf(models.Trade{}))
+
+func jsonFields(t reflect.Type) []string {
+	names := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" && t.Field(i).IsExported() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tradeItem converts a trade to its JSON fields so a selection can be picked.
+// Numbers are kept as written, so large IDs do not lose precision.
+func tradeItem(trade *models.Trade) (gin.H, error) {
+	data, err := json.Marshal(trade)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	item := gin.H{}
+	if err := decoder.Decode(&item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// positionCursor is the place of a position in the list of open positions,
+// ordered by symbol then side (Binance does not report when positions were
+// opened)
+type positionCursor struct {
+	Symbol string
+	Side   string
+}
+
+// String encodes the cursor for the nextCursor response field
+func (c positionCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.Symbol + ":" + c.Side))
+}
+
+// precedes reports whether a position comes at or before the cursor
+func (c positionCursor) precedes(symbol, side string) bool {
+	return symbol < c.Symbol || (symbol == c.Symbol && side <= c.Side)
+}
+
+// parsePositionCursor decodes a cursor returned as nextCursor
+func parsePositionCursor(s string) (*positionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	symbol, side, ok := strings.Cut(string(data), ":")
+	if !ok || symbol == "" {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &positionCursor{Symbol: symbol, Side: side}, nil
+}
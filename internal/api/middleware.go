@@ -2,16 +2,17 @@ package api
 
 import (
 	"bytes"
+	"crypto-trading-api/internal/jwtauth"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/tradingview"
 	"encoding/json"
+	"fmt"
 	"io"
-	"log"
 	"net/http"
-	"os"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
 )
 
 // CORSMiddleware - CORS handling
@@ -19,8 +20,8 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-API-Key")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-API-Key, Idempotency-Key")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -31,14 +32,54 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
-// AuthMiddleware - API Key based authentication
-func AuthMiddleware() gin.HandlerFunc {
-	apiKey := os.Getenv("API_KEY")
-	if apiKey == "" {
-		log.Fatal("API_KEY environment variable must be set")
-	}
-
+// AuthMiddleware - API Key based authentication (static API_KEY or managed keys),
+// or JWT bearer tokens when a verifier is configured. Token holders get the
+// access of their role.
+func AuthMiddleware(keys *APIKeyManager, tokens *jwtauth.Verifier, roles *RoleManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Requests already authenticated by SignatureVerifier need no API key
+		if c.GetBool(signatureVerifiedKey) {
+			c.Next()
+			return
+		}
+
+		// JWT bearer tokens identify the user making the request
+		if bearer := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "); tokens.Enabled() && jwtauth.LooksLikeJWT(bearer) {
+			_, userID, err := tokens.Verify(c.Request.Context(), bearer)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"success": false,
+					"message": "Invalid token",
+					"error":   err.Error(),
+				})
+				c.Abort()
+				return
+			}
+
+			role, err := roles.RoleFor(c.Request.Context(), userID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"success": false,
+					"message": "Failed to resolve role",
+					"error":   err.Error(),
+				})
+				c.Abort()
+				return
+			}
+
+			scopes := roleScopes(role)
+			if !checkScopes(c, scopes) {
+				return
+			}
+
+			c.Set(authUserIDKey, userID)
+			c.Request = c.Request.WithContext(logging.With(c.Request.Context(), logging.FieldUserID, userID))
+			c.Set(authRoleKey, role)
+			c.Set(authScopesKey, scopes)
+			c.Next()
+			return
+		}
+
 		// Get API key from header
 		requestKey := c.GetHeader("X-API-Key")
 
@@ -51,7 +92,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		// For /trade endpoint, also check request body for apiKey (TradingView compatibility)
-		if requestKey == "" && c.Request.Method == "POST" && c.FullPath() == "/api/trade" {
+		if requestKey == "" && c.Request.Method == "POST" && routePath(c) == "/api/trade" {
 			// Read the body
 			bodyBytes, err := io.ReadAll(c.Request.Body)
 			if err == nil {
@@ -66,6 +107,9 @@ func AuthMiddleware() gin.HandlerFunc {
 							requestKey = keyStr
 						}
 					}
+				} else if alert, err := tradingview.Parse(bodyBytes); err == nil {
+					// Plain-text TradingView alert (apiKey=...)
+					requestKey = alert.APIKey()
 				}
 			}
 		}
@@ -80,66 +124,41 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if requestKey != apiKey {
+		key, err := keys.Authenticate(c.Request.Context(), requestKey)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"success": false,
 				"message": "Invalid API key",
-				"error":   "The provided API key is invalid",
+				"error":   err.Error(),
 			})
 			c.Abort()
 			return
 		}
 
-		c.Next()
-	}
-}
-
-// Rate Limiting Middleware
-var (
-	limiters = make(map[string]*rate.Limiter)
-	mu       sync.Mutex
-)
-
-func RateLimitMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		ip := c.ClientIP()
-		
-		mu.Lock()
-		limiter, exists := limiters[ip]
-		if !exists {
-			// Allow 100 requests per minute per IP
-			limiter = rate.NewLimiter(rate.Every(time.Minute/100), 100)
-			limiters[ip] = limiter
-		}
-		mu.Unlock()
-
-		if !limiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"success": false,
-				"message": "Rate limit exceeded",
-				"error":   "Too many requests, please try again later",
-			})
-			c.Abort()
+		if !checkScopes(c, key.Scopes) {
 			return
 		}
 
+		c.Set(authKeyIDKey, key.ID)
+		c.Set(authScopesKey, key.Scopes)
+		c.Set(authRateLimitKey, key.RateLimit)
 		c.Next()
 	}
 }
 
-// Cleanup old limiters periodically
-func init() {
-	go func() {
-		ticker := time.NewTicker(1 * time.Hour)
-		defer ticker.Stop()
-
-		for range ticker.C {
-			mu.Lock()
-			// Clear all limiters to free memory
-			limiters = make(map[string]*rate.Limiter)
-			mu.Unlock()
-		}
-	}()
+// checkScopes aborts with 403 when scopes do not cover the route
+func checkScopes(c *gin.Context, scopes []string) bool {
+	if scopeAllows(scopes, c.Request.Method, routePath(c)) {
+		return true
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{
+		"success": false,
+		"message": "Insufficient scope",
+		"error":   fmt.Sprintf("scopes %v do not allow %s %s", scopes, c.Request.Method, c.FullPath()),
+	})
+	c.Abort()
+	return false
 }
 
 // LoggerMiddleware - Request logging
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListMonitorsHandler - List running trade monitors
+// @Summary      List trade monitors
+// @Description  List the monitors currently following trades' entry orders until they fill or are cancelled. Monitors of ACTIVE trades are re-attached at startup (recovered=true).
+// @Tags         Trading
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  query     string  false  "Only this user's monitors (token holders always see their own)"
+// @Success      200     {object}  models.TradeResponse{data=[]models.TradeMonitor}  "Running monitors"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403     {object}  models.TradeResponse  "Not your monitors"
+// @Router       /api/monitors [get]
+func ListMonitorsHandler(monitors *MonitorManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Query("userId")
+		if !claimOwnership(c, &userID) {
+			return
+		}
+
+		list := monitors.List(userID)
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("%d monitors running", len(list)),
+			Data:      list,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// CancelMonitorHandler - Stop monitoring a trade
+// @Summary      Cancel trade monitor
+// @Description  Stop following a trade's entry order. The trade and its orders on Binance are left unchanged; its status is no longer updated from the order.
+// @Tags         Trading
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        tradeId  path      string  true  "Trade ID"
+// @Success      200      {object}  models.TradeResponse{data=models.TradeMonitor}  "Monitor stopped"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403      {object}  models.TradeResponse  "Not your trade"
+// @Failure      404      {object}  models.TradeResponse  "No monitor for this trade"
+// @Router       /api/monitors/{tradeId} [delete]
+func CancelMonitorHandler(monitors *MonitorManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tradeID := c.Param("tradeId")
+
+		monitor, ok := monitors.Get(tradeID)
+		if !ok {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "No monitor for this trade",
+				Error:     "trade " + tradeID + " is not being monitored",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !requireOwner(c, monitor.UserID) {
+			return
+		}
+
+		monitors.Cancel(tradeID)
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Monitor stopped",
+			Data:      monitor,
+			TradeID:   tradeID,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
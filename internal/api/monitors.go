@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"crypto-trading-api/internal/cluster"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/webhooks"
+	"sort"
+	"sync"
+	"time"
+)
+
+// monitorLockTTL is how long a trade's monitor lock outlives an instance
+// that stopped renewing it
+const monitorLockTTL = 30 * time.Second
+
+// MonitorManager runs the monitors that follow trades' entry orders until
+// they fill or are cancelled, and keeps a registry so they can be listed and
+// stopped individually. With several instances each trade is monitored by
+// the one holding its lock, so SL/TP orders are placed once.
+type MonitorManager struct {
+	store    lifecycleStore
+	locker   *cluster.Locker
+	ctx      context.Context
+	stop     context.CancelFunc
+	monitors map[string]*runningMonitor
+	running  sync.WaitGroup
+	mu       sync.Mutex
+}
+
+type runningMonitor struct {
+	info   models.TradeMonitor
+	cancel context.CancelFunc
+}
+
+// NewMonitorManager creates a monitor manager
+func NewMonitorManager(fb FirebaseInterface, hooks *webhooks.Dispatcher, locker *cluster.Locker) *MonitorManager {
+	ctx, stop := context.WithCancel(context.Background())
+
+	m := &MonitorManager{
+		store:    lifecycleStore{fb: fb, hooks: hooks},
+		locker:   locker,
+		ctx:      ctx,
+		stop:     stop,
+		monitors: make(map[string]*runningMonitor),
+	}
+	if locker.Shared() {
+		go m.renewLocks()
+	}
+	return m
+}
+
+// Watch starts monitoring a trade, replacing any monitor already running for
+// it. It reports false if another instance holds the trade's lock, or for a
+// recovered trade if the lock cannot be checked; a new trade is monitored by
+// the instance that placed it even then.
+func (m *MonitorManager) Watch(bn BinanceInterface, trade *models.Trade, recovered bool) bool {
+	lockCtx, cancelLock := context.WithTimeout(m.ctx, 5*time.Second)
+	acquired, err := m.locker.TryLock(lockCtx, monitorLock(trade.ID), monitorLockTTL)
+	cancelLock()
+	if err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("Could not lock monitor of trade %s", trade.ID)
+		if recovered {
+			return false
+		}
+	} else if !acquired {
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	monitor := &runningMonitor{
+		info: models.TradeMonitor{
+			TradeID:   trade.ID,
+			UserID:    trade.UserID,
+			Symbol:    trade.Symbol,
+			OrderID:   trade.OrderID,
+			Account:   trade.Account,
+			Instance:  m.locker.Instance(),
+			StartedAt: time.Now().Unix(),
+			Recovered: recovered,
+		},
+		cancel: cancel,
+	}
+
+	m.mu.Lock()
+	if existing, ok := m.monitors[trade.ID]; ok {
+		existing.cancel()
+	}
+	m.monitors[trade.ID] = monitor
+	m.running.Add(1)
+	m.mu.Unlock()
+
+	go func() {
+		defer m.running.Done()
+		bn.MonitorTrade(ctx, trade, m.store)
+		cancel()
+
+		m.mu.Lock()
+		current := m.monitors[trade.ID] == monitor
+		if current {
+			delete(m.monitors, trade.ID)
+		}
+		m.mu.Unlock()
+
+		// A replacement monitor keeps the lock
+		if current {
+			m.unlock(trade.ID)
+		}
+	}()
+	return true
+}
+
+// Get returns the monitor of a trade
+func (m *MonitorManager) Get(tradeID string) (*models.TradeMonitor, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	monitor, ok := m.monitors[tradeID]
+	if !ok {
+		return nil, false
+	}
+	info := monitor.info
+	return &info, true
+}
+
+// Cancel stops monitoring a trade. The trade itself is left unchanged.
+func (m *MonitorManager) Cancel(tradeID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	monitor, ok := m.monitors[tradeID]
+	if !ok {
+		return false
+	}
+	monitor.cancel()
+	delete(m.monitors, tradeID)
+	go m.unlock(tradeID)
+	return true
+}
+
+// List returns the running monitors, oldest first (userID = "" for all users)
+func (m *MonitorManager) List(userID string) []*models.TradeMonitor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	monitors := make([]*models.TradeMonitor, 0, len(m.monitors))
+	for _, monitor := range m.monitors {
+		if userID != "" && monitor.info.UserID != userID {
+			continue
+		}
+		info := monitor.info
+		monitors = append(monitors, &info)
+	}
+
+	sort.Slice(monitors, func(i, j int) bool {
+		return monitors[i].StartedAt < monitors[j].StartedAt
+	})
+	return monitors
+}
+
+// Stop stops every monitor and waits for them to return, so none writes
+// after the store is flushed. Their trades stay ACTIVE and are monitored
+// again after a restart (RecoverMonitors).
+func (m *MonitorManager) Stop() {
+	m.mu.Lock()
+	count := len(m.monitors)
+	m.mu.Unlock()
+
+	m.stop()
+	m.running.Wait()
+	logging.Info().Msgf("Trade monitors stopped (%d resume after restart)", count)
+}
+
+// renewLocks extends the locks of the running monitors every third of their
+// TTL and stops any monitor whose lock another instance took over
+func (m *MonitorManager) renewLocks() {
+	ticker := time.NewTicker(monitorLockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.ctx.Done():
+			return
+		}
+
+		m.mu.Lock()
+		tradeIDs := make([]string, 0, len(m.monitors))
+		for tradeID := range m.monitors {
+			tradeIDs = append(tradeIDs, tradeID)
+		}
+		m.mu.Unlock()
+
+		for _, tradeID := range tradeIDs {
+			ctx, cancel := context.WithTimeout(m.ctx, 5*time.Second)
+			held, err := m.locker.TryLock(ctx, monitorLock(tradeID), monitorLockTTL)
+			cancel()
+			if err != nil {
+				// Keep monitoring: no other instance can take the lock meanwhile
+				logging.Warn().Err(err).Str(logging.FieldTradeID, tradeID).Msgf("Could not renew monitor lock of trade %s", tradeID)
+				continue
+			}
+			if held {
+				continue
+			}
+
+			m.mu.Lock()
+			if monitor, ok := m.monitors[tradeID]; ok {
+				monitor.cancel()
+				delete(m.monitors, tradeID)
+			}
+			m.mu.Unlock()
+			logging.Warn().Str(logging.FieldTradeID, tradeID).Msgf("Stopped monitoring trade %s: monitored by another instance", tradeID)
+		}
+	}
+}
+
+// unlock releases a trade's monitor lock so another instance can adopt it
+func (m *MonitorManager) unlock(tradeID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.locker.Unlock(ctx, monitorLock(tradeID)); err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, tradeID).Msg("Failed to release monitor lock")
+	}
+}
+
+// monitorLock names the lock of a trade's monitor
+func monitorLock(tradeID string) string {
+	return "monitor:" + tradeID
+}
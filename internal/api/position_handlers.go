@@ -0,0 +1,432 @@
+package api
+
+import (
+	"context"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/storage"
+	"crypto-trading-api/internal/tradehistory"
+	"crypto-trading-api/internal/validation"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DetailedPositionsHandler - Open positions with the context of their trades
+// @Summary      Get detailed positions
+// @Description  Join every open Binance position with the open trades holding it: journal tags, SL/TP prices and order IDs, age, MAE/MFE, and the funding received or paid since the oldest trade was filled. Positions no trade holds (opened outside the API and not adopted) are listed with managed=false. Token holders only see their own trades.
+// @Tags         Positions
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  query     string  false  "User whose own Binance account to query (default: operator account)"
+// @Param        account query     string  false  "Operator account to query: main or a BINANCE_ACCOUNTS name (default: as userId selects)"
+// @Success      200  {object}  models.TradeResponse{data=models.DetailedPositions}  "Detailed positions"
+// @Failure      400  {object}  models.TradeResponse  "Unknown account"
+// @Failure      401  {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403  {object}  models.TradeResponse  "No Binance account for user"
+// @Failure      500  {object}  models.TradeResponse  "Failed to get positions or trades"
+// @Router       /api/positions/detailed [get]
+func DetailedPositionsHandler(clients *binance.ClientPool, fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, account := c.Query("userId"), c.Query("account")
+		if !claimOwnership(c, &userID) || !requireAccountAccess(c, account) {
+			return
+		}
+
+		bn, ok := userClient(c, clients, userID, account)
+		if !ok {
+			return
+		}
+		ctx := c.Request.Context()
+
+		positions, err := bn.GetOpenPositions(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get open positions",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		trades, err := positionTrades(ctx, fb, positionAccount(clients, bn, userID, account))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get trades",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		if caller := authenticatedUser(c); caller != "" && c.GetString(authRoleKey) != models.RoleAdmin {
+			for symbol, symbolTrades := range trades {
+				owned := symbolTrades[:0]
+				for _, trade := range symbolTrades {
+					if trade.UserID == caller {
+						owned = append(owned, trade)
+					}
+				}
+				trades[symbol] = owned
+			}
+		}
+
+		now := time.Now()
+		result := models.DetailedPositions{Positions: []models.DetailedPosition{}}
+		for _, pos := range positions {
+			if pos.PositionAmt == 0 {
+				continue
+			}
+			position := detailedPosition(pos, trades[pos.Symbol], now)
+			if !position.Managed {
+				result.Unmanaged++
+			}
+			result.TotalPositions++
+			result.TotalPnL += pos.UnrealizedProfit
+			result.Positions = append(result.Positions, position)
+		}
+
+		if err := addFunding(ctx, bn, result.Positions, now); err != nil {
+			result.FundingError = err.Error()
+		}
+		for _, position := range result.Positions {
+			result.TotalFunding += position.FundingAccrued
+		}
+		result.TotalFunding = math.Round(result.TotalFunding*1e8) / 1e8
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("%d open positions", result.TotalPositions),
+			Data:      result,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// positionAccount is the Trade.Account of trades placed on the account a
+// request selects
+func positionAccount(clients *binance.ClientPool, bn *binance.Client, userID, account string) string {
+	switch {
+	case account == binance.PrimaryAccount:
+		return ""
+	case account != "":
+		return account
+	case clients.IsPrimary(bn):
+		return ""
+	}
+	return models.UserAccount(userID)
+}
+
+// positionTrades loads the open trades placed on an account, by symbol,
+// oldest first
+func positionTrades(ctx context.Context, fb storage.TradeStore, account string) (map[string][]*models.Trade, error) {
+	trades := make(map[string][]*models.Trade)
+	for _, status := range []string{"ACTIVE", "FILLED"} {
+		byStatus, err := fb.GetTradesByStatus(ctx, status)
+		if err != nil {
+			return nil, err
+		}
+		for _, trade := range byStatus {
+			if trade.Account == account {
+				trades[trade.Symbol] = append(trades[trade.Symbol], trade)
+			}
+		}
+	}
+
+	for _, symbolTrades := range trades {
+		sort.Slice(symbolTrades, func(i, j int) bool {
+			return tradeOpenedAt(symbolTrades[i]) < tradeOpenedAt(symbolTrades[j])
+		})
+	}
+	return trades, nil
+}
+
+// tradeOpenedAt is when a trade's entry filled, or its creation when unknown
+func tradeOpenedAt(trade *models.Trade) int64 {
+	if trade.ExecutedAt != 0 {
+		return trade.ExecutedAt
+	}
+	return trade.CreatedAt
+}
+
+// detailedPosition joins a position with the trades holding it
+func detailedPosition(pos *binance.PositionInfo, trades []*models.Trade, now time.Time) models.DetailedPosition {
+	side := "LONG"
+	if pos.PositionAmt < 0 {
+		side = "SHORT"
+	}
+
+	position := models.DetailedPosition{
+		Symbol:           pos.Symbol,
+		Side:             side,
+		PositionSide:     pos.PositionSide,
+		PositionAmt:      pos.PositionAmt,
+		EntryPrice:       pos.EntryPrice,
+		MarkPrice:        pos.MarkPrice,
+		Notional:         math.Round(math.Abs(pos.PositionAmt)*pos.MarkPrice*100) / 100,
+		UnrealizedProfit: pos.UnrealizedProfit,
+		Leverage:         pos.Leverage,
+		LiquidationPrice: pos.LiquidationPrice,
+		MarginType:       pos.MarginType,
+		Managed:          len(trades) > 0,
+		Trades:           make([]models.PositionTrade, 0, len(trades)),
+	}
+
+	for _, trade := range trades {
+		openedAt := tradeOpenedAt(trade)
+		var tags []string
+		if trade.Journal != nil {
+			tags = trade.Journal.Tags
+		}
+		position.Trades = append(position.Trades, models.PositionTrade{
+			TradeID:               trade.ID,
+			UserID:                trade.UserID,
+			Side:                  trade.Side,
+			Status:                trade.Status,
+			Tags:                  tags,
+			ExecutedPrice:         trade.ExecutedPrice,
+			Size:                  trade.Size,
+			StopLoss:              trade.StopLoss,
+			TakeProfit:            trade.TakeProfit,
+			SLOrderID:             trade.SLOrderID,
+			TPOrderID:             trade.TPOrderID,
+			OpenedAt:              openedAt,
+			AgeSeconds:            now.Unix() - openedAt,
+			MaxAdverseExcursion:   trade.MaxAdverseExcursion,
+			MaxFavorableExcursion: trade.MaxFavorableExcursion,
+			Adopted:               trade.Adopted,
+		})
+	}
+	if len(position.Trades) > 0 {
+		position.OpenedAt = position.Trades[0].OpenedAt
+		position.AgeSeconds = position.Trades[0].AgeSeconds
+	}
+	return position
+}
+
+// addFunding sets the funding each managed position accrued since it opened,
+// from one income history read back to the oldest of them
+func addFunding(ctx context.Context, bn *binance.Client, positions []models.DetailedPosition, now time.Time) error {
+	from := int64(0)
+	for _, position := range positions {
+		if position.OpenedAt != 0 && (from == 0 || position.OpenedAt < from) {
+			from = position.OpenedAt
+		}
+	}
+	if from == 0 {
+		return nil
+	}
+
+	records, err := bn.GetIncomeRecordsRange(ctx, "FUNDING_FEE", from, now.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to get funding fees: %v", err)
+	}
+
+	for i := range positions {
+		position := &positions[i]
+		if position.OpenedAt == 0 {
+			continue
+		}
+		for _, record := range records {
+			if record.Symbol == position.Symbol && record.Time/1000 >= position.OpenedAt {
+				position.FundingAccrued += record.Income
+			}
+		}
+		position.FundingAccrued = math.Round(position.FundingAccrued*1e8) / 1e8
+	}
+	return nil
+}
+
+// CloseAllPositionsHandler - Close every open position
+// @Summary      Close all positions
+// @Description  Close every open position of the account at market, optionally only LONG or SHORT positions or the listed symbols, several at a time on the order submission workers (ORDER_WORKERS). The reduce-only orders left on each symbol (SL/TP) are cancelled and the open trades holding it marked CLOSED; the realized PnL is recorded on the oldest. Token holders only close positions held by their own trades. The body may be omitted.
+// @Tags         Positions
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      models.CloseAllRequest  false  "Positions to close (default: all)"
+// @Success      200      {object}  models.TradeResponse{data=models.CloseAllResult}  "Per-symbol results"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request or unknown account"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403      {object}  models.TradeResponse  "No Binance account for user"
+// @Failure      500      {object}  models.TradeResponse  "Failed to get positions, or no position could be closed"
+// @Router       /api/positions/close-all [post]
+func CloseAllPositionsHandler(clients *binance.ClientPool, fb storage.TradeStore, bus *events.Bus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.CloseAllRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				err = validation.FromBinding(err)
+				c.JSON(http.StatusBadRequest, models.TradeResponse{
+					Success:   false,
+					Message:   "Invalid request",
+					Error:     err.Error(),
+					Details:   validation.Details(err),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+		}
+
+		if !claimOwnership(c, &req.UserID) || !requireAccountAccess(c, req.Account) {
+			return
+		}
+		bn, ok := userClient(c, clients, req.UserID, req.Account)
+		if !ok {
+			return
+		}
+		// Positions are closed even if the caller goes away
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		positions, err := bn.GetOpenPositions(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get open positions",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		account := positionAccount(clients, bn, req.UserID, req.Account)
+		trades, err := positionTrades(ctx, fb, account)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get trades",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		wanted := make(map[string]bool, len(req.Symbols))
+		for _, symbol := range req.Symbols {
+			wanted[strings.ToUpper(symbol)] = true
+		}
+		caller := authenticatedUser(c)
+		ownOnly := caller != "" && c.GetString(authRoleKey) != models.RoleAdmin
+
+		var selected []*binance.PositionInfo
+		for _, pos := range positions {
+			if pos.PositionAmt == 0 || (len(wanted) > 0 && !wanted[pos.Symbol]) {
+				continue
+			}
+			if (req.Side == "LONG" && pos.PositionAmt < 0) || (req.Side == "SHORT" && pos.PositionAmt > 0) {
+				continue
+			}
+			if ownOnly && !ownsAll(trades[pos.Symbol], caller) {
+				continue
+			}
+			selected = append(selected, pos)
+		}
+
+		// Closed on the order submission workers, after any order already
+		// being placed on the same symbol
+		result := models.CloseAllResult{Results: make([]models.ClosedPositionResult, len(selected))}
+		var wg sync.WaitGroup
+		for i, pos := range selected {
+			wg.Add(1)
+			go func(i int, pos *binance.PositionInfo) {
+				defer wg.Done()
+				binance.SubmitOrder(ctx, binance.OrderKey(account, pos.Symbol), func() {
+					result.Results[i] = closeOnePosition(ctx, bn, fb, bus, pos, trades[pos.Symbol])
+				})
+			}(i, pos)
+		}
+		wg.Wait()
+
+		sort.Slice(result.Results, func(i, j int) bool {
+			return result.Results[i].Symbol < result.Results[j].Symbol
+		})
+		for _, closed := range result.Results {
+			if closed.Error != "" {
+				result.Failed++
+				continue
+			}
+			result.Closed++
+			result.TotalRealizedPnL += closed.RealizedPnL
+		}
+
+		if result.Closed == 0 && result.Failed > 0 {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to close positions",
+				Error:     result.Results[0].Error,
+				Data:      result,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   result.Failed == 0,
+			Message:   fmt.Sprintf("%d of %d positions closed", result.Closed, len(selected)),
+			Data:      result,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// ownsAll reports whether a position is held by trades, all of userID
+func ownsAll(trades []*models.Trade, userID string) bool {
+	for _, trade := range trades {
+		if trade.UserID != userID {
+			return false
+		}
+	}
+	return len(trades) > 0
+}
+
+// closeOnePosition closes a position at market with ClosePosition, which
+// records the PnL on the oldest trade, marks the other trades CLOSED and
+// cancels the reduce-only orders left on the symbol
+func closeOnePosition(ctx context.Context, bn *binance.Client, fb storage.TradeStore, bus *events.Bus, pos *binance.PositionInfo, trades []*models.Trade) models.ClosedPositionResult {
+	side := "LONG"
+	if pos.PositionAmt < 0 {
+		side = "SHORT"
+	}
+	closed := models.ClosedPositionResult{Symbol: pos.Symbol, Side: side, PositionAmt: pos.PositionAmt}
+
+	tradeID := ""
+	if len(trades) > 0 {
+		tradeID = trades[0].ID
+	}
+	result, err := ClosePosition(ctx, bn, fb, bus, pos.Symbol, tradeID)
+	if err != nil {
+		closed.Error = err.Error()
+		return closed
+	}
+	closed.OrderID, closed.Price, closed.RealizedPnL = result.OrderID, result.Price, result.RealizedProfit
+
+	ctx = tradehistory.WithSource(ctx, tradehistory.SourceClosePosition)
+	for i, trade := range trades {
+		closed.TradeIDs = append(closed.TradeIDs, trade.ID)
+		if i == 0 {
+			continue
+		}
+		// Their realized PnL is left to the PnL reconciler
+		trade.Status = "CLOSED"
+		trade.ClosedAt = time.Now().Unix()
+		if err := fb.UpdateTrade(ctx, trade); err != nil {
+			logging.Ctx(ctx).Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("Position %s closed but trade %s not updated", pos.Symbol, trade.ID)
+		}
+	}
+
+	cancelled, err := bn.CancelProtectiveOrders(ctx, pos.Symbol)
+	closed.CancelledOrders = cancelled
+	if err != nil {
+		// The order reconciler cancels them later as orphans
+		logging.Ctx(ctx).Warn().Err(err).Str(logging.FieldSymbol, pos.Symbol).Msgf("Position %s closed but its orders not all cancelled", pos.Symbol)
+	}
+	return closed
+}
@@ -0,0 +1,337 @@
+package api
+
+import (
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/policy"
+	"crypto-trading-api/internal/storage"
+	"crypto-trading-api/internal/validation"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SavePresetHandler - Create or update a strategy preset
+// @Summary      Save strategy preset
+// @Description  Create or replace a named preset of default trade parameters. A TradeRequest with "preset": "<name>" only needs userId, symbol, side and entryPrice; leverage, margin/order type, SL/TP (as percentages of entry) and size come from the preset unless set on the request. sizingMode FIXED uses size (USDT); RISK_PERCENT sizes the position so hitting the stop loss loses riskPercent of account equity. tradingHours limits when the preset's trades are placed (UTC windows, weekdays and blackout periods); trades outside them are rejected with ERR_OUTSIDE_TRADING_HOURS, or QUEUED until they open when tradingHours.outside is QUEUE.
+// @Tags         Presets
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        name    path      string                        true  "Preset name (lowercase letters, digits, - and _)"
+// @Param        preset  body      models.StrategyPresetRequest  true  "Preset settings"
+// @Success      200     {object}  models.TradeResponse{data=models.StrategyPreset}  "Preset saved"
+// @Failure      400     {object}  models.TradeResponse  "Invalid request"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403     {object}  models.TradeResponse  "Preset belongs to another user"
+// @Failure      500     {object}  models.TradeResponse  "Failed to save preset"
+// @Router       /api/presets/{name} [put]
+func SavePresetHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := strings.ToLower(c.Param("name"))
+		if !templateNamePattern.MatchString(name) {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid preset name",
+				Error:     "name must be 1-64 lowercase letters, digits, '-' or '_'",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		var req models.StrategyPresetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !requireOwner(c, req.UserID) {
+			return
+		}
+
+		if err := validatePresetRequest(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid preset",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		existing, err := fb.GetStrategyPreset(c.Request.Context(), name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get preset",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		now := time.Now().Unix()
+		preset := &models.StrategyPreset{
+			Name:              name,
+			UserID:            req.UserID,
+			Leverage:          req.Leverage,
+			MarginType:        req.MarginType,
+			OrderType:         req.OrderType,
+			StopLossPercent:   req.StopLossPercent,
+			TakeProfitPercent: req.TakeProfitPercent,
+			SizingMode:        req.SizingMode,
+			Size:              req.Size,
+			RiskPercent:       req.RiskPercent,
+			AllowedSymbols:    req.AllowedSymbols,
+			TradingHours:      req.TradingHours,
+			WorkingType:       req.WorkingType,
+			PriceProtect:      req.PriceProtect,
+			MaxPositions:      req.MaxPositions,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+		if existing != nil {
+			if existing.UserID != req.UserID {
+				c.JSON(http.StatusForbidden, models.TradeResponse{
+					Success:   false,
+					Message:   "Preset belongs to another user",
+					Error:     fmt.Sprintf("preset %q is already in use", name),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+			preset.CreatedAt = existing.CreatedAt
+		}
+
+		if err := fb.SaveStrategyPreset(c.Request.Context(), preset); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to save preset",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Preset saved successfully",
+			Data:      preset,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// ListPresetsHandler - List a user's strategy presets
+// @Summary      List strategy presets
+// @Description  List the strategy presets owned by a user
+// @Tags         Presets
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  query     string  true  "User ID"
+// @Success      200     {object}  models.TradeResponse{data=[]models.StrategyPreset}  "Presets retrieved"
+// @Failure      400     {object}  models.TradeResponse  "Missing userId parameter"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get presets"
+// @Router       /api/presets [get]
+func ListPresetsHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Query("userId")
+		if !claimOwnership(c, &userID) {
+			return
+		}
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "userId parameter is required",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		all, err := fb.GetStrategyPresets(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get presets",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		presets := []*models.StrategyPreset{}
+		for _, preset := range all {
+			if preset.UserID == userID {
+				presets = append(presets, preset)
+			}
+		}
+		sort.Slice(presets, func(i, j int) bool {
+			return presets[i].Name < presets[j].Name
+		})
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Presets retrieved successfully",
+			Data:      presets,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// GetPresetHandler - Get a strategy preset
+// @Summary      Get strategy preset
+// @Description  Get a strategy preset by name
+// @Tags         Presets
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        name  path      string  true  "Preset name"
+// @Success      200   {object}  models.TradeResponse{data=models.StrategyPreset}  "Preset retrieved"
+// @Failure      401   {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      404   {object}  models.TradeResponse  "Preset not found"
+// @Failure      500   {object}  models.TradeResponse  "Failed to get preset"
+// @Router       /api/presets/{name} [get]
+func GetPresetHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := strings.ToLower(c.Param("name"))
+
+		preset, err := fb.GetStrategyPreset(c.Request.Context(), name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get preset",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		if preset == nil {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "Preset not found",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Preset retrieved successfully",
+			Data:      preset,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// DeletePresetHandler - Remove a strategy preset
+// @Summary      Delete strategy preset
+// @Description  Remove a strategy preset. Trade requests still referencing it are rejected with 404.
+// @Tags         Presets
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        name  path      string  true  "Preset name"
+// @Success      200   {object}  models.TradeResponse  "Preset deleted"
+// @Failure      401   {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      404   {object}  models.TradeResponse  "Preset not found"
+// @Failure      500   {object}  models.TradeResponse  "Failed to delete preset"
+// @Router       /api/presets/{name} [delete]
+func DeletePresetHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := strings.ToLower(c.Param("name"))
+
+		preset, err := fb.GetStrategyPreset(c.Request.Context(), name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get preset",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		if preset == nil {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "Preset not found",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !requireOwner(c, preset.UserID) {
+			return
+		}
+
+		if err := fb.DeleteStrategyPreset(c.Request.Context(), name); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to delete preset",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Preset deleted successfully",
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// Normalize and validate preset settings
+func validatePresetRequest(req *models.StrategyPresetRequest) error {
+	req.OrderType = strings.ToUpper(req.OrderType)
+	req.MarginType = strings.ToUpper(req.MarginType)
+	req.SizingMode = strings.ToUpper(req.SizingMode)
+	req.WorkingType = strings.ToUpper(req.WorkingType)
+	if req.SizingMode == "" {
+		req.SizingMode = models.SizingModeFixed
+	}
+
+	if req.OrderType != "" && req.OrderType != "MARKET" && req.OrderType != "LIMIT" {
+		return fmt.Errorf("orderType must be MARKET or LIMIT")
+	}
+	if req.MarginType != "" && req.MarginType != "ISOLATED" && req.MarginType != "CROSSED" {
+		return fmt.Errorf("marginType must be ISOLATED or CROSSED")
+	}
+	if req.WorkingType != "" && req.WorkingType != models.WorkingTypeMark && req.WorkingType != models.WorkingTypeLast {
+		return fmt.Errorf("workingType must be %s or %s", models.WorkingTypeMark, models.WorkingTypeLast)
+	}
+
+	switch req.SizingMode {
+	case models.SizingModeFixed:
+		if req.Size <= 0 {
+			return fmt.Errorf("size is required for FIXED sizing")
+		}
+	case models.SizingModeRiskPercent:
+		if req.RiskPercent <= 0 {
+			return fmt.Errorf("riskPercent is required for RISK_PERCENT sizing")
+		}
+	default:
+		return fmt.Errorf("sizingMode must be %s or %s", models.SizingModeFixed, models.SizingModeRiskPercent)
+	}
+
+	for i, symbol := range req.AllowedSymbols {
+		req.AllowedSymbols[i] = strings.ToUpper(strings.TrimSpace(symbol))
+	}
+
+	if req.TradingHours != nil {
+		if err := policy.NormalizeTradingHours(req.TradingHours); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/push"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// pushUpgrader accepts WebSocket connections from any origin, like CORSMiddleware
+var pushUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// PushQueryAuth lets browsers, which cannot set headers on WebSocket
+// requests, authenticate with ?token= (API key or JWT)
+func PushQueryAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.Query("token"); token != "" && c.GetHeader("Authorization") == "" && c.GetHeader("X-API-Key") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		c.Next()
+	}
+}
+
+// PushHandler - Live updates over WebSocket
+// @Summary      Live updates (WebSocket)
+// @Description  Upgrade to a WebSocket that pushes JSON messages {type, userId, data, time}: "trade" whenever a trade is created or changes, "position" for operator account position changes and mark price PnL ticks (at most one per second per symbol), "balance" for wallet balance changes. Token holders without the admin role only receive their own trades. Browsers may pass the API key or JWT as ?token=.
+// @Tags         WebSocket
+// @Security     ApiKeyAuth
+// @Param        types  query     string  false  "Comma-separated message types: trade, position, balance (default: all)"
+// @Param        token  query     string  false  "API key or JWT, for clients that cannot set headers"
+// @Success      101    {object}  push.Message  "Switching protocols, then a stream of messages"
+// @Failure      400    {object}  models.TradeResponse  "Unknown message type"
+// @Failure      401    {object}  models.TradeResponse  "Unauthorized"
+// @Router       /ws [get]
+func PushHandler(hub *push.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sub := push.Subscription{Types: make(map[string]bool)}
+		if types := c.Query("types"); types != "" {
+			for _, t := range strings.Split(types, ",") {
+				t = strings.ToLower(strings.TrimSpace(t))
+				if !isPushType(t) {
+					c.JSON(http.StatusBadRequest, models.TradeResponse{
+						Success:   false,
+						Message:   "Unknown message type",
+						Error:     "types must be a comma-separated list of " + strings.Join(push.Types, ", "),
+						Timestamp: time.Now().Unix(),
+					})
+					return
+				}
+				sub.Types[t] = true
+			}
+		}
+
+		// Token holders follow their own trades; the operator account's
+		// positions and balances are for admins and API keys
+		if userID := authenticatedUser(c); userID != "" && c.GetString(authRoleKey) != models.RoleAdmin {
+			sub.UserID = userID
+		}
+
+		conn, err := pushUpgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			logging.Ctx(c.Request.Context()).Warn().Err(err).Msg("Push: upgrade failed")
+			return
+		}
+
+		logging.Ctx(c.Request.Context()).Info().Msgf("Push client connected: %s (%s)", c.ClientIP(), rateLimitCaller(c))
+		hub.Serve(conn, sub)
+		logging.Ctx(c.Request.Context()).Info().Msgf("Push client disconnected: %s", c.ClientIP())
+	}
+}
+
+// isPushType reports whether t is a known push message type
+func isPushType(t string) bool {
+	for _, known := range push.Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitWindow is the period every limit is expressed in
+const rateLimitWindow = time.Minute
+
+// Request context keys used by RateLimiter
+const (
+	authRateLimitKey      = "authRateLimit"      // Per-key limit set by AuthMiddleware (managed keys)
+	rateLimitRemainingKey = "rateLimitRemaining" // Lowest remaining count reported in headers so far
+)
+
+// RateLimitConfig holds the request limits per minute. Zero disables a limit.
+type RateLimitConfig struct {
+	PerIP  int               // Every request, by client IP
+	PerKey int               // Per API key, token user or signed caller (managed keys may set their own)
+	Routes map[string]int    // Per caller and route, keyed "POST /api/trade" or "/api/trade" (any method)
+	Shared SharedRateLimiter // Counts across server instances when set; in-memory windows are the fallback
+}
+
+// SharedRateLimiter counts requests in sliding windows shared by every server
+// instance (Redis)
+type SharedRateLimiter interface {
+	Take(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, remaining int, reset time.Time, err error)
+}
+
+// RateLimiter enforces sliding-window request limits by client IP, caller and
+// route, and reports the most restrictive one in X-RateLimit-* headers.
+type RateLimiter struct {
+	config    RateLimitConfig
+	configMu  sync.RWMutex
+	windows   map[string]*slidingWindow
+	lastPrune time.Time
+	mu        sync.Mutex
+}
+
+// slidingWindow counts requests in the current and previous fixed window.
+// The previous window's count is weighted by how much of it still overlaps
+// the sliding window ending now.
+type slidingWindow struct {
+	start    time.Time
+	current  int
+	previous int
+}
+
+// rateLimitResult describes one limit after counting a request
+type rateLimitResult struct {
+	allowed   bool
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// NewRateLimiter creates a rate limiter
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		config:    config,
+		windows:   make(map[string]*slidingWindow),
+		lastPrune: time.Now(),
+	}
+}
+
+// SetLimits replaces the per-IP, per-key and per-route limits (config
+// reload). Requests already counted stay in their windows.
+func (l *RateLimiter) SetLimits(perIP, perKey int, routes map[string]int) {
+	l.configMu.Lock()
+	defer l.configMu.Unlock()
+
+	l.config.PerIP = perIP
+	l.config.PerKey = perKey
+	l.config.Routes = routes
+}
+
+// limits returns the current configuration
+func (l *RateLimiter) limits() RateLimitConfig {
+	l.configMu.RLock()
+	defer l.configMu.RUnlock()
+	return l.config
+}
+
+// IPMiddleware limits every request by client IP. It runs before
+// authentication so unauthenticated floods are rejected too.
+func (l *RateLimiter) IPMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if perIP := l.limits().PerIP; perIP > 0 && !l.apply(c, "ip:"+c.ClientIP(), perIP) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// Middleware limits authenticated requests per caller and per route. It runs
+// after AuthMiddleware, which identifies the caller.
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller := rateLimitCaller(c)
+
+		config := l.limits()
+		limit := config.PerKey
+		if keyLimit := c.GetInt(authRateLimitKey); keyLimit > 0 {
+			limit = keyLimit
+		}
+		if limit > 0 && !l.apply(c, caller, limit) {
+			return
+		}
+
+		route := routePath(c) // /api/trade and /api/v1/trade share limits
+		if routeLimit, ok := routeLimit(config.Routes, c.Request.Method, route); ok {
+			if !l.apply(c, c.Request.Method+" "+route+"|"+caller, routeLimit) {
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// routeLimit finds the limit for a route, preferring a method-specific entry
+func routeLimit(routes map[string]int, method, route string) (int, bool) {
+	if limit, ok := routes[method+" "+route]; ok && limit > 0 {
+		return limit, true
+	}
+	if limit, ok := routes[route]; ok && limit > 0 {
+		return limit, true
+	}
+	return 0, false
+}
+
+// apply counts a request against one limit, sets the headers and aborts with
+// 429 when the limit is exhausted
+func (l *RateLimiter) apply(c *gin.Context, key string, limit int) bool {
+	result := l.count(c.Request.Context(), key, limit)
+	setRateLimitHeaders(c, result)
+	if result.allowed {
+		return true
+	}
+
+	retryAfter := int(math.Ceil(time.Until(result.reset).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"success": false,
+		"message": "Rate limit exceeded",
+		"error":   fmt.Sprintf("limit of %d requests per minute reached, retry in %ds", limit, retryAfter),
+	})
+	c.Abort()
+	return false
+}
+
+// count counts a request in the shared windows, or in memory when there are
+// none or the shared store fails
+func (l *RateLimiter) count(ctx context.Context, key string, limit int) rateLimitResult {
+	if l.config.Shared != nil {
+		allowed, remaining, reset, err := l.config.Shared.Take(ctx, key, limit, rateLimitWindow)
+		if err == nil {
+			return rateLimitResult{allowed: allowed, limit: limit, remaining: remaining, reset: reset}
+		}
+		logging.Warn().Err(err).Msg("Shared rate limit unavailable, counting locally")
+	}
+	return l.take(key, limit, time.Now())
+}
+
+// take counts a request in the key's window if the limit allows it
+func (l *RateLimiter) take(key string, limit int, now time.Time) rateLimitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.prune(now)
+
+	w, exists := l.windows[key]
+	if !exists {
+		w = &slidingWindow{start: now.Truncate(rateLimitWindow)}
+		l.windows[key] = w
+	}
+
+	// Roll the fixed windows forward
+	if elapsed := now.Sub(w.start); elapsed >= 2*rateLimitWindow {
+		w.start, w.previous, w.current = now.Truncate(rateLimitWindow), 0, 0
+	} else if elapsed >= rateLimitWindow {
+		w.start, w.previous, w.current = w.start.Add(rateLimitWindow), w.current, 0
+	}
+
+	overlap := 1 - float64(now.Sub(w.start))/float64(rateLimitWindow)
+	used := int(math.Ceil(float64(w.previous)*overlap)) + w.current
+
+	result := rateLimitResult{limit: limit, reset: w.start.Add(rateLimitWindow)}
+	if used >= limit {
+		return result
+	}
+
+	w.current++
+	result.allowed = true
+	result.remaining = limit - used - 1
+	return result
+}
+
+// prune drops windows idle long enough to no longer affect any count
+func (l *RateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < 2*rateLimitWindow {
+		return
+	}
+
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= 2*rateLimitWindow {
+			delete(l.windows, key)
+		}
+	}
+	l.lastPrune = now
+}
+
+// setRateLimitHeaders reports a limit unless a more restrictive one was
+// already reported for this request
+func setRateLimitHeaders(c *gin.Context, result rateLimitResult) {
+	if remaining, exists := c.Get(rateLimitRemainingKey); exists && remaining.(int) <= result.remaining {
+		return
+	}
+
+	c.Set(rateLimitRemainingKey, result.remaining)
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(result.reset.Unix(), 10))
+}
+
+// rateLimitCaller identifies who a request is counted against: the API key,
+// the token user, or the client IP for signed requests (counted separately
+// from the IP limit)
+func rateLimitCaller(c *gin.Context) string {
+	if userID := authenticatedUser(c); userID != "" {
+		return "user:" + userID
+	}
+	if keyID := c.GetString(authKeyIDKey); keyID != "" {
+		return "key:" + keyID
+	}
+	return "signed:" + c.ClientIP()
+}
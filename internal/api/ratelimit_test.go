@@ -0,0 +1,90 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSlidingWindow(t *testing.T) {
+	start := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC) // On a window boundary
+
+	tests := []struct {
+		name          string
+		limit         int
+		requests      []time.Duration // Offsets from start of the requests made first
+		at            time.Duration   // Offset of the checked request
+		wantAllowed   bool
+		wantRemaining int
+	}{
+		{name: "first request", limit: 3, at: 0, wantAllowed: true, wantRemaining: 2},
+		{name: "under limit", limit: 3, requests: []time.Duration{0, time.Second}, at: 2 * time.Second, wantAllowed: true, wantRemaining: 0},
+		{name: "limit reached", limit: 3, requests: []time.Duration{0, time.Second, 2 * time.Second}, at: 3 * time.Second, wantAllowed: false},
+		// 3 requests in the previous window, 3/4 of which still overlaps: ceil(2.25) = 3 used
+		{name: "previous window weighted", limit: 3, requests: []time.Duration{0, 0, 0}, at: 75 * time.Second, wantAllowed: false},
+		// Half overlap: ceil(1.5) = 2 used
+		{name: "previous window half gone", limit: 3, requests: []time.Duration{0, 0, 0}, at: 90 * time.Second, wantAllowed: true, wantRemaining: 0},
+		{name: "idle two windows", limit: 3, requests: []time.Duration{0, 0, 0}, at: 2 * time.Minute, wantAllowed: true, wantRemaining: 2},
+		// The third request was rejected: ceil(2 * 0.5) = 1 used, not ceil(3 * 0.5) = 2
+		{name: "rejected requests not counted", limit: 2, requests: []time.Duration{0, time.Second, 2 * time.Second}, at: 90 * time.Second, wantAllowed: true, wantRemaining: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(RateLimitConfig{})
+			for _, offset := range tt.requests {
+				limiter.take("key", tt.limit, start.Add(offset))
+			}
+
+			result := limiter.take("key", tt.limit, start.Add(tt.at))
+			if result.allowed != tt.wantAllowed {
+				t.Fatalf("allowed = %v, want %v", result.allowed, tt.wantAllowed)
+			}
+			if tt.wantAllowed && result.remaining != tt.wantRemaining {
+				t.Errorf("remaining = %d, want %d", result.remaining, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestRateLimiterKeysIndependent(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{})
+	now := time.Now()
+
+	tests := []struct {
+		key         string
+		wantAllowed bool
+	}{
+		{"ip:10.0.0.1", true},
+		{"ip:10.0.0.1", false},
+		{"ip:10.0.0.2", true},
+		{"user:u1", true},
+	}
+
+	for _, tt := range tests {
+		if result := limiter.take(tt.key, 1, now); result.allowed != tt.wantAllowed {
+			t.Errorf("take(%s) allowed = %v, want %v", tt.key, result.allowed, tt.wantAllowed)
+		}
+	}
+}
+
+func TestRouteLimit(t *testing.T) {
+	routes := map[string]int{"POST /api/trade": 5, "/api/trade": 20, "/api/positions": 0}
+
+	tests := []struct {
+		method, route string
+		want          int
+		wantOK        bool
+	}{
+		{"POST", "/api/trade", 5, true},
+		{"GET", "/api/trade", 20, true},
+		{"GET", "/api/positions", 0, false},
+		{"GET", "/api/balance", 0, false},
+	}
+
+	for _, tt := range tests {
+		limit, ok := routeLimit(routes, tt.method, tt.route)
+		if limit != tt.want || ok != tt.wantOK {
+			t.Errorf("routeLimit(%s %s) = %d, %v, want %d, %v", tt.method, tt.route, limit, ok, tt.want, tt.wantOK)
+		}
+	}
+}
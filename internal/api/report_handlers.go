@@ -0,0 +1,71 @@
+package api
+
+import (
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/storage"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetReportsHandler - List stored daily/weekly summary reports
+// @Summary      Get summary reports
+// @Description  Retrieve past scheduled summary reports (PnL, win rate, best/worst trades, open risk), newest first
+// @Tags         Analytics
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        period  query     string  false  "DAILY or WEEKLY (default: all)" example("DAILY")
+// @Param        limit   query     int     false  "Maximum number of reports (default: 30)" example(30)
+// @Success      200     {object}  models.TradeResponse{data=[]models.SummaryReport}  "Reports retrieved successfully"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403     {object}  models.TradeResponse  "Summaries are limited to admins and API keys"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get reports"
+// @Router       /api/reports [get]
+func GetReportsHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := strings.ToUpper(c.Query("period"))
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
+
+		// Summaries cover every user's trades
+		if !requireAccountWide(c) {
+			return
+		}
+
+		reports, err := fb.GetReports(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get reports",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		filtered := []*models.SummaryReport{}
+		for _, report := range reports {
+			if period == "" || report.Period == period {
+				filtered = append(filtered, report)
+			}
+		}
+
+		sort.Slice(filtered, func(i, j int) bool {
+			return filtered[i].StartTime > filtered[j].StartTime
+		})
+
+		if limit > 0 && len(filtered) > limit {
+			filtered = filtered[:limit]
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Reports retrieved successfully",
+			Data:      filtered,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
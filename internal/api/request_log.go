@@ -0,0 +1,58 @@
+package api
+
+import (
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/tracing"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogMiddleware - Structured access log
+// Tags the request's logger with its X-Request-ID and trace ID, so every
+// line logged while handling it (Binance orders, storage errors) can be
+// correlated, and logs one line per request once it completes.
+func RequestLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx := logging.With(c.Request.Context(),
+			logging.FieldRequestID, c.GetString("RequestID"),
+			logging.FieldTraceID, tracing.TraceID(c.Request.Context()))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		event := logging.Ctx(ctx).Info()
+		if strings.HasPrefix(c.FullPath(), "/health") {
+			// Probes poll every few seconds
+			event = logging.Ctx(ctx).Debug()
+		}
+		if status >= http.StatusInternalServerError {
+			event = logging.Ctx(ctx).Error()
+		} else if status >= http.StatusBadRequest {
+			event = logging.Ctx(ctx).Warn()
+		}
+
+		if userID := authenticatedUser(c); userID != "" {
+			event = event.Str(logging.FieldUserID, userID)
+		}
+		if version := c.GetString(apiVersionKey); version != "" {
+			event = event.Str("api_version", version)
+		}
+		if len(c.Errors) > 0 {
+			event = event.Str("error", c.Errors.String())
+		}
+		event.
+			Str("method", c.Request.Method).
+			Str("route", c.FullPath()).
+			Str("path", c.Request.URL.Path).
+			Int("status", status).
+			Float64("latency_ms", float64(time.Since(start).Microseconds())/1000).
+			Str("client_ip", c.ClientIP()).
+			Int("bytes", c.Writer.Size()).
+			Msg("request")
+	}
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/validation"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AssignRoleHandler - Assign a role to a user
+// @Summary      Assign role
+// @Description  Assign viewer (GET endpoints only), trader (own trades) or admin (any user plus system, cancel-all and configuration endpoints) to a token-authenticated user
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId   path      string                        true  "User ID (token subject)"
+// @Param        request  body      models.RoleAssignmentRequest  true  "Role"
+// @Success      200      {object}  models.TradeResponse{data=models.RoleAssignment}  "Role assigned"
+// @Failure      400      {object}  models.TradeResponse  "Invalid role"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403      {object}  models.TradeResponse  "Admin access required"
+// @Failure      500      {object}  models.TradeResponse  "Failed to assign role"
+// @Router       /api/admin/roles/{userId} [put]
+func AssignRoleHandler(roles *RoleManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.RoleAssignmentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid role",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		assignedBy := authenticatedUser(c)
+		if assignedBy == "" {
+			assignedBy = c.GetString(authKeyIDKey)
+		}
+
+		assignment, err := roles.Assign(c.Request.Context(), c.Param("userId"), req.Role, assignedBy)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to assign role",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Role assigned",
+			Data:      assignment,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// ListRolesHandler - List role assignments
+// @Summary      List roles
+// @Description  List every role assignment. Users without one get the default role (DEFAULT_ROLE).
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.TradeResponse{data=object}  "Role assignments and default role"
+// @Failure      401  {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403  {object}  models.TradeResponse  "Admin access required"
+// @Failure      500  {object}  models.TradeResponse  "Failed to list roles"
+// @Router       /api/admin/roles [get]
+func ListRolesHandler(roles *RoleManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		assignments, err := roles.List(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to list roles",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success: true,
+			Message: "Roles retrieved",
+			Data: gin.H{
+				"defaultRole": roles.DefaultRole(),
+				"assignments": assignments,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// RemoveRoleHandler - Remove a user's role assignment
+// @Summary      Remove role
+// @Description  Remove a user's role assignment so the default role applies again
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  path      string  true  "User ID (token subject)"
+// @Success      200     {object}  models.TradeResponse  "Role removed"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403     {object}  models.TradeResponse  "Admin access required"
+// @Failure      500     {object}  models.TradeResponse  "Failed to remove role"
+// @Router       /api/admin/roles/{userId} [delete]
+func RemoveRoleHandler(roles *RoleManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := roles.Remove(c.Request.Context(), c.Param("userId")); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to remove role",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Role removed, default role " + roles.DefaultRole() + " applies",
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
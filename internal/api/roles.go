@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"crypto-trading-api/internal/models"
+	"sync"
+	"time"
+)
+
+// roleCacheTTL bounds how long a role change on another instance takes to apply
+const roleCacheTTL = 30 * time.Second
+
+// RoleStore persists role assignments
+type RoleStore interface {
+	SaveRoleAssignment(ctx context.Context, assignment *models.RoleAssignment) error
+	GetRoleAssignment(ctx context.Context, userID string) (*models.RoleAssignment, error)
+	GetRoleAssignments(ctx context.Context) ([]*models.RoleAssignment, error)
+	DeleteRoleAssignment(ctx context.Context, userID string) error
+}
+
+// RoleManager resolves the role of token-authenticated users. Users without
+// an assignment get the default role.
+type RoleManager struct {
+	store       RoleStore
+	defaultRole string
+	cache       map[string]cachedRole
+	mu          sync.Mutex
+}
+
+type cachedRole struct {
+	role     string
+	loadedAt time.Time
+}
+
+// NewRoleManager creates a role manager
+func NewRoleManager(store RoleStore, defaultRole string) *RoleManager {
+	return &RoleManager{
+		store:       store,
+		defaultRole: defaultRole,
+		cache:       make(map[string]cachedRole),
+	}
+}
+
+// RoleFor returns a user's role
+func (m *RoleManager) RoleFor(ctx context.Context, userID string) (string, error) {
+	m.mu.Lock()
+	cached, ok := m.cache[userID]
+	m.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < roleCacheTTL {
+		return cached.role, nil
+	}
+
+	assignment, err := m.store.GetRoleAssignment(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	role := m.defaultRole
+	if assignment != nil {
+		role = assignment.Role
+	}
+
+	m.mu.Lock()
+	m.cache[userID] = cachedRole{role: role, loadedAt: time.Now()}
+	m.mu.Unlock()
+	return role, nil
+}
+
+// Assign stores a user's role
+func (m *RoleManager) Assign(ctx context.Context, userID, role, assignedBy string) (*models.RoleAssignment, error) {
+	assignment := &models.RoleAssignment{
+		UserID:    userID,
+		Role:      role,
+		UpdatedAt: time.Now().Unix(),
+		UpdatedBy: assignedBy,
+	}
+
+	if err := m.store.SaveRoleAssignment(ctx, assignment); err != nil {
+		return nil, err
+	}
+
+	m.forget(userID)
+	return assignment, nil
+}
+
+// Remove deletes a user's assignment so the default role applies again
+func (m *RoleManager) Remove(ctx context.Context, userID string) error {
+	if err := m.store.DeleteRoleAssignment(ctx, userID); err != nil {
+		return err
+	}
+
+	m.forget(userID)
+	return nil
+}
+
+// List returns every role assignment
+func (m *RoleManager) List(ctx context.Context) ([]*models.RoleAssignment, error) {
+	return m.store.GetRoleAssignments(ctx)
+}
+
+// DefaultRole returns the role of users without an assignment
+func (m *RoleManager) DefaultRole() string {
+	return m.defaultRole
+}
+
+func (m *RoleManager) forget(userID string) {
+	m.mu.Lock()
+	delete(m.cache, userID)
+	m.mu.Unlock()
+}
+
+// roleScopes maps a role to the API key scope with the same access
+func roleScopes(role string) []string {
+	switch role {
+	case models.RoleAdmin:
+		return []string{models.ScopeAdmin}
+	case models.RoleTrader:
+		return []string{models.ScopeTrade}
+	case models.RoleViewer:
+		return []string{models.ScopeReadOnly}
+	}
+	return nil
+}
@@ -1,65 +1,217 @@
 package api
 
 import (
+	"crypto-trading-api/internal/alerts"
 	"crypto-trading-api/internal/binance"
-	"crypto-trading-api/internal/firebase"
+	"crypto-trading-api/internal/calendar"
+	"crypto-trading-api/internal/cluster"
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/jwtauth"
+	"crypto-trading-api/internal/notifications"
+	"crypto-trading-api/internal/policy"
+	"crypto-trading-api/internal/push"
+	"crypto-trading-api/internal/storage"
+	"crypto-trading-api/internal/userstats"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// RouterDeps holds the services the routes are served by
+type RouterDeps struct {
+	Store       storage.TradeStore
+	Binance     *binance.Client
+	Intake      *TradeIntake
+	Symbols     *policy.SymbolPolicy
+	PriceAlerts *alerts.Engine
+	Signatures  *SignatureVerifier
+	APIKeys     *APIKeyManager
+	Tokens      *jwtauth.Verifier
+	Roles       *RoleManager
+	RateLimiter *RateLimiter
+	Clients     *binance.ClientPool
+	Monitors    *MonitorManager
+	FundingArb  *binance.FundingArbitrage
+	Reconciler  *binance.OrderReconciler
+	Streams     *binance.WebSocketManager
+	Pause       *policy.TradingPause
+	Notifier    *notifications.Notifier
+	Bus         *events.Bus
+	PushHub     *push.Hub
+	Elector     *cluster.Elector
+	Idempotency *Idempotency
+	Reload      ConfigReloader
+	Legacy      LegacyAPIConfig
+	Calendar    *calendar.Calendar
+	Volatility  *binance.VolatilityGuard
+	Summaries   *userstats.Aggregator
+	Performance *alerts.PerformanceMonitor
+}
+
 // SetupRouter configures all routes and middleware
-func SetupRouter(fb *firebase.Client, bn *binance.Client) *gin.Engine {
-	router := gin.Default()
+func SetupRouter(deps RouterDeps) *gin.Engine {
+	router := gin.New()
 
 	// Middleware
 	router.Use(gin.Recovery())
+	router.Use(RequestIDMiddleware())
+	router.Use(TracingMiddleware())    // Server span per request (no-op unless TRACING_ENABLED)
+	router.Use(RequestLogMiddleware()) // JSON access log; tags request logs with request/trace IDs
 	router.Use(CORSMiddleware())
-	router.Use(RateLimitMiddleware())
+	router.Use(ErrorCodeMiddleware()) // errorCode on failed JSON responses that do not set one
+	router.Use(deps.RateLimiter.IPMiddleware())
 
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Health check
+	// Health checks
 	router.GET("/health", HealthCheck)
+	router.GET("/health/live", LivenessHandler)                                                        // Process up
+	router.GET("/health/ready", ReadinessHandler(deps.Store, deps.Binance, deps.Streams, deps.Intake)) // Binance, storage, user data stream and clock
+
+	// Live trade, position and balance updates (authenticated like /api)
+	router.GET("/ws", PushQueryAuth(), AuthMiddleware(deps.APIKeys, deps.Tokens, deps.Roles), deps.RateLimiter.Middleware(), PushHandler(deps.PushHub))
 
-	// Basic API routes
-	apiGroup := router.Group("/api")
-	apiGroup.Use(AuthMiddleware())
+	// Basic API routes, versioned under /api/v1
+	apiGroup := router.Group(versionedAPIPrefix)
+	apiGroup.Use(APIVersionMiddleware(deps.Legacy))                     // API-Version header; deprecation headers on unversioned paths
+	apiGroup.Use(AuditMiddleware(deps.Store))                           // Record state-changing calls (wraps auth to capture the caller and rejections)
+	apiGroup.Use(deps.Signatures.Middleware())                          // Optional HMAC-signed /api/trade (runs before API key auth)
+	apiGroup.Use(AuthMiddleware(deps.APIKeys, deps.Tokens, deps.Roles)) // API_KEY, managed keys or JWT bearer tokens (role-based)
+	apiGroup.Use(deps.RateLimiter.Middleware())                         // Per-key and per-route limits (needs the caller from auth)
+	apiGroup.Use(deps.Idempotency.Middleware())                         // Replay responses to POSTs retried with an Idempotency-Key
 	{
 		// Core trading endpoints
-		apiGroup.POST("/trade", TradeHandler(fb, bn))
-		apiGroup.GET("/trades/:userId", GetTradesHandler(fb))
-		apiGroup.GET("/trade/:tradeId", GetTradeHandler(fb))
+		apiGroup.POST("/trade", TradeHandler(deps.Intake, deps.Store))
+		apiGroup.POST("/trade/preview", TradePreviewHandler(deps.Intake))   // Validate a trade and estimate its fees
+		apiGroup.POST("/trade/simulate", TradeSimulateHandler(deps.Intake)) // Projected PnL at SL, TP and liquidation
+		apiGroup.GET("/trades", QueryTradesHandler(deps.Store))             // Filtered, paginated trade search
+		apiGroup.GET("/trades/:userId", GetTradesHandler(deps.Store))
+		apiGroup.GET("/trade/:tradeId", GetTradeHandler(deps.Store))
+		apiGroup.GET("/trade/:tradeId/history", TradeHistoryHandler(deps.Store))                       // State changes, oldest first
+		apiGroup.POST("/trade/:tradeId/cancel", CancelTradeHandler(deps.Intake, deps.Store))           // Abort a QUEUED or unfilled LIMIT trade
+		apiGroup.POST("/trade/:tradeId/replace", ReplaceTradeHandler(deps.Intake, deps.Store))         // New entry price/SL/TP, same trade ID
+		apiGroup.PATCH("/trade/:tradeId/protection", TradeProtectionHandler(deps.Clients, deps.Store)) // Move a live trade's SL/TP
+		apiGroup.PATCH("/trade/:tradeId/journal", UpdateTradeJournalHandler(deps.Store))
+		apiGroup.GET("/monitors", ListMonitorsHandler(deps.Monitors))              // Running trade monitors
+		apiGroup.DELETE("/monitors/:tradeId", CancelMonitorHandler(deps.Monitors)) // Stop monitoring a trade
 
 		// Advanced endpoints
-		apiGroup.GET("/status", SystemStatusHandler(fb, bn))           // System status
-		apiGroup.GET("/balance", AccountBalanceHandler(bn))            // Account balance
-		apiGroup.GET("/positions", OpenPositionsHandler(bn))           // Open positions
-		apiGroup.GET("/orders", PendingOrdersHandler(bn))              // Pending orders
-		apiGroup.POST("/orders/cancel", CancelOrdersHandler(bn))       // Cancel orders
-		apiGroup.POST("/position/close", ClosePositionHandler(bn, fb)) // Close position
-		apiGroup.GET("/summary", TradingSummaryHandler(fb, bn))        // Trading summary
-		apiGroup.GET("/exchange/info", ExchangeInfoHandler(bn))        // Exchange info (min trade sizes, etc.)
-		apiGroup.GET("/account/snapshot", AccountSnapshotHandler(bn))  // Daily account snapshot
+		apiGroup.GET("/status", SystemStatusHandler(deps.Store, deps.Binance, deps.Elector))                // System status
+		apiGroup.GET("/balance", AccountBalanceHandler(deps.Clients))                                       // Account balance
+		apiGroup.GET("/positions", OpenPositionsHandler(deps.Clients))                                      // Open positions
+		apiGroup.GET("/positions/history", PositionHistoryHandler(deps.Clients))                            // Closed positions rebuilt from Binance fills
+		apiGroup.GET("/positions/detailed", DetailedPositionsHandler(deps.Clients, deps.Store))             // Positions joined with their trades
+		apiGroup.POST("/positions/close-all", CloseAllPositionsHandler(deps.Clients, deps.Store, deps.Bus)) // Close every (or some) open position
+		apiGroup.GET("/orders", PendingOrdersHandler(deps.Clients))                                         // Pending orders
+		apiGroup.GET("/orders/history", OrderHistoryHandler(deps.Clients))                                  // Past orders from Binance, filtered by status
+		apiGroup.POST("/orders/cancel", CancelOrdersHandler(deps.Binance))                                  // Cancel orders
+		apiGroup.POST("/position/close", ClosePositionHandler(deps.Clients, deps.Store, deps.Bus))          // Close position
+		apiGroup.POST("/position/margin", PositionMarginHandler(deps.Clients, deps.Store))                  // Add or remove isolated margin
+		apiGroup.POST("/position/leverage", PositionLeverageHandler(deps.Clients))                          // Change a symbol's leverage
+		apiGroup.POST("/position/margin-type", MarginTypeHandler(deps.Clients))                             // Switch ISOLATED/CROSSED margin
+		apiGroup.GET("/summary", TradingSummaryHandler(deps.Store, deps.Binance))                           // Trading summary
+		apiGroup.GET("/analytics/fees", FeesReportHandler(deps.Store, deps.Binance))                        // Fees per period/symbol
+		apiGroup.GET("/analytics/latency", LatencyReportHandler(deps.Store))                                // Order round-trip and dependency latency percentiles
+		apiGroup.GET("/analytics/heatmap", HeatmapHandler(deps.Store))                                      // PnL and win rate by hour of day / day of week
+		apiGroup.GET("/analytics/symbols", SymbolLeaderboardHandler(deps.Store))                            // Symbols ranked by PnL, win rate, average R or fees
+		apiGroup.GET("/analytics/montecarlo", MonteCarloHandler(deps.Store, deps.Binance))                  // Probability of ruin / drawdown simulation
+		apiGroup.GET("/analytics/balance-history", BalanceHistoryHandler(deps.Store))                       // Daily equity from stored snapshots
+		apiGroup.GET("/reports", GetReportsHandler(deps.Store))                                             // Scheduled daily/weekly summaries
+		apiGroup.GET("/reports/tax", TaxReportHandler(deps.Store, deps.Binance))                            // Yearly tax/accounting statement
+		apiGroup.GET("/exchange/info", ExchangeInfoHandler(deps.Binance))                                   // Exchange info (min trade sizes, etc.)
+		apiGroup.GET("/account/snapshot", AccountSnapshotHandler(deps.Clients))                             // Daily account snapshot
+		apiGroup.GET("/account/income", IncomeHistoryHandler(deps.Clients))                                 // Transfers, fees, funding and PnL by type and day
+		apiGroup.GET("/account/commission", CommissionRatesHandler(deps.Clients))                           // Maker/taker rates and BNB fee discount
 
 		// 🆕 CRITICAL FEATURES - WebSocket, Funding, Risk, Time Sync
 		// WebSocket endpoints
-		apiGroup.POST("/websocket/start", StartWebSocketHandler(bn))   // Start WebSocket stream
-		apiGroup.GET("/websocket/status", WebSocketStatusHandler())    // WebSocket status
+		apiGroup.POST("/websocket/start", StartWebSocketHandler(deps.Streams))                    // Start WebSocket stream
+		apiGroup.GET("/websocket/status", WebSocketStatusHandler(deps.Streams))                   // WebSocket status
+		apiGroup.GET("/candles", CandlesHandler(deps.Streams))                                    // In-memory candles from kline/aggTrade streams
+		apiGroup.GET("/market/volatility", VolatilityScreenerHandler(deps.Binance, deps.Streams)) // Symbols ranked by realized volatility, ATR% and 24h range
 
 		// Funding rate endpoints
-		apiGroup.GET("/funding/rate", FundingRateHandler(bn))          // Current funding rate
-		apiGroup.GET("/funding/history", FundingRateHistoryHandler(bn)) // Funding rate history
+		apiGroup.GET("/funding/rate", FundingRateHandler(deps.Binance))                                         // Current funding rate
+		apiGroup.GET("/funding/history", FundingRateHistoryHandler(deps.Binance))                               // Funding rate history
+		apiGroup.GET("/funding/screener", FundingScreenerHandler(deps.Binance))                                 // Every perpetual ranked by current/predicted funding
+		apiGroup.GET("/funding/opportunities", FundingOpportunitiesHandler(deps.FundingArb))                    // Extreme funding rates
+		apiGroup.POST("/funding/arbitrage", OpenFundingArbHandler(deps.FundingArb, deps.Symbols, deps.Pause))   // Open spot+perp pair
+		apiGroup.GET("/funding/arbitrage", ListFundingArbHandler(deps.FundingArb, deps.Store))                  // List pairs
+		apiGroup.POST("/funding/arbitrage/:groupId/close", CloseFundingArbHandler(deps.FundingArb, deps.Store)) // Close both legs
 
 		// Risk management endpoints
-		apiGroup.GET("/risk/liquidation", LiquidationRiskHandler(bn))  // Liquidation risk analysis
+		apiGroup.GET("/risk/liquidation", LiquidationRiskHandler(deps.Clients))   // Liquidation risk analysis
+		apiGroup.GET("/risk/account", AccountHealthHandler(deps.Clients))         // Margin ratio and account health
+		apiGroup.GET("/risk/var", ValueAtRiskHandler(deps.Clients, deps.Streams)) // Value-at-Risk and correlation
+		apiGroup.GET("/risk/halts", VolatilityHaltsHandler(deps.Volatility))      // Symbols halted by extreme moves
+		apiGroup.GET("/calendar/upcoming", UpcomingEventsHandler(deps.Calendar))  // Economic events and news blackouts
 
 		// System/Time sync endpoints
-		apiGroup.GET("/system/time", TimeSyncHandler(bn))              // Time synchronization check
-		apiGroup.GET("/system/server-time", ServerTimeHandler(bn))     // Binance server time
+		apiGroup.GET("/system/time", TimeSyncHandler(deps.Binance))          // Time synchronization check
+		apiGroup.GET("/system/server-time", ServerTimeHandler(deps.Binance)) // Binance server time
+
+		// Strategy presets (referenced by TradeRequest.preset)
+		apiGroup.PUT("/presets/:name", SavePresetHandler(deps.Store))      // Create/update a preset
+		apiGroup.GET("/presets", ListPresetsHandler(deps.Store))           // List a user's presets
+		apiGroup.GET("/presets/:name", GetPresetHandler(deps.Store))       // Get a preset
+		apiGroup.DELETE("/presets/:name", DeletePresetHandler(deps.Store)) // Remove a preset
+
+		// Per-user Binance keys (trades with that userId run on the user's account)
+		apiGroup.PUT("/users/:userId/binance-keys", SetBinanceKeysHandler(deps.Clients, deps.Store))       // Store encrypted keys
+		apiGroup.GET("/users/:userId/binance-keys", GetBinanceKeysHandler(deps.Store))                     // Key status (never the keys)
+		apiGroup.DELETE("/users/:userId/binance-keys", DeleteBinanceKeysHandler(deps.Clients, deps.Store)) // Remove keys
+
+		// Per-user settings (trade defaults, notification preferences, risk limits)
+		apiGroup.PUT("/users/:userId/settings", SaveUserSettingsHandler(deps.Store))               // Create/replace settings
+		apiGroup.GET("/users/:userId/settings", GetUserSettingsHandler(deps.Store))                // Get settings
+		apiGroup.DELETE("/users/:userId/settings", DeleteUserSettingsHandler(deps.Store))          // Remove settings
+		apiGroup.GET("/users/:userId/summary", UserSummaryHandler(deps.Summaries))                 // Cached all-time summary
+		apiGroup.POST("/users/:userId/summary/refresh", RefreshUserSummaryHandler(deps.Summaries)) // Recount from the user's trades
+
+		// TradingView alert templates
+		apiGroup.PUT("/tradingview/templates/:name", SaveTradingViewTemplateHandler(deps.Store))      // Create/update a template
+		apiGroup.GET("/tradingview/templates", ListTradingViewTemplatesHandler(deps.Store))           // List a user's templates
+		apiGroup.DELETE("/tradingview/templates/:name", DeleteTradingViewTemplateHandler(deps.Store)) // Remove a template
+
+		// Webhook endpoints
+		apiGroup.POST("/webhooks", RegisterWebhookHandler(deps.Store))            // Register trade lifecycle callback
+		apiGroup.GET("/webhooks", ListWebhooksHandler(deps.Store))                // List a user's webhooks
+		apiGroup.DELETE("/webhooks/:webhookId", DeleteWebhookHandler(deps.Store)) // Remove a webhook
+
+		// Price alert endpoints
+		apiGroup.POST("/alerts/price", CreatePriceAlertHandler(deps.PriceAlerts, deps.Symbols))                                     // Register a price alert
+		apiGroup.GET("/alerts/price", ListPriceAlertsHandler(deps.Store))                                                           // List a user's price alerts
+		apiGroup.DELETE("/alerts/price/:alertId", CancelPriceAlertHandler(deps.PriceAlerts, deps.Store))                            // Cancel a price alert
+		apiGroup.GET("/alerts/performance", ListPerformanceAlertsHandler(deps.Store))                                               // Drawdown, losing streak and daily loss alerts
+		apiGroup.POST("/alerts/performance/:alertId/acknowledge", AcknowledgePerformanceAlertHandler(deps.Performance, deps.Store)) // Stop an alert's reminders
+		apiGroup.POST("/alerts/performance/:alertId/snooze", SnoozePerformanceAlertHandler(deps.Performance, deps.Store))           // Pause an alert's reminders
+
+		// Admin endpoints
+		apiGroup.GET("/admin/symbols", GetSymbolPolicyHandler(deps.Symbols))                       // Symbol allow/block lists
+		apiGroup.PUT("/admin/symbols", UpdateSymbolPolicyHandler(deps.Symbols, deps.Store))        // Update symbol allow/block lists
+		apiGroup.POST("/admin/apikeys", IssueAPIKeyHandler(deps.APIKeys))                          // Issue a scoped API key
+		apiGroup.GET("/admin/apikeys", ListAPIKeysHandler(deps.APIKeys))                           // List API keys
+		apiGroup.POST("/admin/apikeys/:keyId/rotate", RotateAPIKeyHandler(deps.APIKeys))           // Replace an API key
+		apiGroup.DELETE("/admin/apikeys/:keyId", RevokeAPIKeyHandler(deps.APIKeys))                // Revoke an API key
+		apiGroup.PUT("/admin/roles/:userId", AssignRoleHandler(deps.Roles))                        // Assign viewer/trader/admin
+		apiGroup.GET("/admin/roles", ListRolesHandler(deps.Roles))                                 // List role assignments
+		apiGroup.DELETE("/admin/roles/:userId", RemoveRoleHandler(deps.Roles))                     // Back to the default role
+		apiGroup.GET("/admin/audit", AuditLogHandler(deps.Store))                                  // Query the audit log
+		apiGroup.GET("/admin/pause", GetTradingPauseHandler(deps.Pause))                           // Trading pause state
+		apiGroup.POST("/admin/pause", PauseTradingHandler(deps.Pause, deps.Notifier))              // Maintenance mode: reject new trades with 503
+		apiGroup.POST("/admin/resume", ResumeTradingHandler(deps.Pause, deps.Notifier))            // Accept new trades again
+		apiGroup.GET("/admin/reconcile", GetOrderReconcileHandler(deps.Reconciler))                // Latest orphaned order reconciliation
+		apiGroup.POST("/admin/reconcile", RunOrderReconcileHandler(deps.Reconciler))               // Reconcile orders and positions now
+		apiGroup.POST("/admin/positions/adopt", AdoptPositionsHandler(deps.Reconciler))            // Adopt positions opened outside the API
+		apiGroup.POST("/admin/candles", StartCandleStreamHandler(deps.Streams))                    // Keep candles for a symbol/interval
+		apiGroup.DELETE("/admin/candles/:symbol/:interval", StopCandleStreamHandler(deps.Streams)) // Drop a candle stream
+		apiGroup.POST("/admin/config/reload", ReloadConfigHandler(deps.Reload))                    // Re-read CONFIG_FILE (same as SIGHUP)
 	}
 
+	// The same routes at their unversioned /api paths (existing clients, TradingView alerts)
+	mountLegacyAPI(router, apiGroup)
+
 	return router
 }
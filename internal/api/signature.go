@@ -0,0 +1,217 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Signed request headers for POST /api/trade
+const (
+	SignatureHeader = "X-Signature" // sha256=<hex HMAC-SHA256 of "timestamp.nonce.body">
+	TimestampHeader = "X-Timestamp" // Unix seconds
+	NonceHeader     = "X-Nonce"     // Unique per request
+
+	maxNonceLength = 128
+)
+
+// signatureVerifiedKey marks a request as authenticated by its signature
+const signatureVerifiedKey = "signatureVerified"
+
+// SignatureVerifier checks HMAC-signed trade webhooks and rejects replays.
+// A valid signature authenticates the request on its own, so senders that
+// can sign never have to put the API key in the body.
+type SignatureVerifier struct {
+	secret    []byte
+	required  bool
+	tolerance time.Duration
+	nonces    *replayCache
+	shared    SharedNonceCache
+}
+
+// SharedNonceCache remembers nonces for every server instance (Redis), so a
+// request cannot be replayed against another one
+type SharedNonceCache interface {
+	SetOnce(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// NewSignatureVerifier creates a verifier. With an empty secret verification
+// is disabled; with required=false unsigned requests fall back to API key auth.
+func NewSignatureVerifier(secret string, required bool, tolerance time.Duration) *SignatureVerifier {
+	if tolerance <= 0 {
+		tolerance = 5 * time.Minute
+	}
+	return &SignatureVerifier{
+		secret:    []byte(secret),
+		required:  required,
+		tolerance: tolerance,
+		nonces:    newReplayCache(),
+	}
+}
+
+// SetShared remembers nonces in a cache shared by every server instance;
+// the in-memory cache is the fallback when it fails
+func (v *SignatureVerifier) SetShared(shared SharedNonceCache) {
+	v.shared = shared
+}
+
+// Enabled reports whether a signing secret is configured
+func (v *SignatureVerifier) Enabled() bool {
+	return v != nil && len(v.secret) > 0
+}
+
+// Middleware verifies signed POST /api/trade requests. It must run before AuthMiddleware.
+func (v *SignatureVerifier) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !v.Enabled() || c.Request.Method != "POST" || routePath(c) != "/api/trade" {
+			c.Next()
+			return
+		}
+
+		signature := c.GetHeader(SignatureHeader)
+		if signature == "" {
+			if v.required {
+				rejectSignature(c, "Missing request signature",
+					fmt.Errorf("%s, %s and %s headers are required", SignatureHeader, TimestampHeader, NonceHeader))
+				return
+			}
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			rejectSignature(c, "Invalid request signature", err)
+			return
+		}
+		// Restore the body for the handler
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+		if err := v.verify(c.Request.Context(), signature, c.GetHeader(TimestampHeader), c.GetHeader(NonceHeader), body); err != nil {
+			logging.Ctx(c.Request.Context()).Warn().Err(err).Msgf("Rejected signed trade request from %s", c.ClientIP())
+			rejectSignature(c, "Invalid request signature", err)
+			return
+		}
+
+		c.Set(signatureVerifiedKey, true)
+		c.Next()
+	}
+}
+
+// Verify checks the signature, timestamp window and nonce uniqueness
+func (v *SignatureVerifier) Verify(signature, timestamp, nonce string, body []byte) error {
+	return v.verify(context.Background(), signature, timestamp, nonce, body)
+}
+
+func (v *SignatureVerifier) verify(ctx context.Context, signature, timestamp, nonce string, body []byte) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s must be a unix timestamp in seconds", TimestampHeader)
+	}
+
+	skew := time.Since(time.Unix(ts, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > v.tolerance {
+		return fmt.Errorf("timestamp outside the allowed window of %v", v.tolerance)
+	}
+
+	if nonce == "" || len(nonce) > maxNonceLength {
+		return fmt.Errorf("%s must be 1-%d characters", NonceHeader, maxNonceLength)
+	}
+
+	expected := SignRequest(string(v.secret), timestamp, nonce, body)
+	given := strings.TrimPrefix(signature, "sha256=")
+	if !hmac.Equal([]byte(given), []byte(expected)) {
+		return fmt.Errorf("signature mismatch")
+	}
+
+	// Only remember nonces of authentic requests, and keep them past the
+	// timestamp window so the same request cannot be replayed within it
+	if !v.claimNonce(ctx, nonce) {
+		return fmt.Errorf("nonce already used (replayed request)")
+	}
+
+	return nil
+}
+
+// claimNonce records a nonce in the shared cache, or in memory when there is
+// none or it fails, and returns false if it was already used
+func (v *SignatureVerifier) claimNonce(ctx context.Context, nonce string) bool {
+	ttl := 2 * v.tolerance
+	if v.shared != nil {
+		claimed, err := v.shared.SetOnce(ctx, "nonce:"+nonce, ttl)
+		if err == nil {
+			return claimed
+		}
+		logging.Warn().Err(err).Msg("Shared nonce cache unavailable, checking locally")
+	}
+	return v.nonces.add(nonce, time.Now().Add(ttl))
+}
+
+// SignRequest computes the hex HMAC-SHA256 of "timestamp.nonce.body"
+func SignRequest(secret, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write([]byte(nonce))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func rejectSignature(c *gin.Context, message string, err error) {
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"success": false,
+		"message": message,
+		"error":   err.Error(),
+	})
+	c.Abort()
+}
+
+// replayCache remembers nonces until they expire
+type replayCache struct {
+	entries   map[string]time.Time
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+func newReplayCache() *replayCache {
+	return &replayCache{entries: make(map[string]time.Time), lastSweep: time.Now()}
+}
+
+// add records a nonce and returns false if it is already present
+func (r *replayCache) add(nonce string, expires time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.lastSweep) > time.Minute {
+		for n, exp := range r.entries {
+			if now.After(exp) {
+				delete(r.entries, n)
+			}
+		}
+		r.lastSweep = now
+	}
+
+	if exp, seen := r.entries[nonce]; seen && now.Before(exp) {
+		return false
+	}
+
+	r.entries[nonce] = expires
+	return true
+}
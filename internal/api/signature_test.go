@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignatureVerify(t *testing.T) {
+	const secret = "webhook-secret"
+	body := []byte(`{"symbol":"BTCUSDT","side":"BUY"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	sign := func(timestamp, nonce string) string {
+		return "sha256=" + SignRequest(secret, timestamp, nonce, body)
+	}
+
+	tests := []struct {
+		name      string
+		signature string
+		timestamp string
+		nonce     string
+		body      []byte
+		wantErr   string // Substring of the error ("" = accepted)
+	}{
+		{name: "valid", signature: sign(now, "n1"), timestamp: now, nonce: "n1"},
+		{name: "without prefix", signature: SignRequest(secret, now, "n2", body), timestamp: now, nonce: "n2"},
+		{name: "replayed nonce", signature: sign(now, "n1"), timestamp: now, nonce: "n1", wantErr: "replayed"},
+		{name: "other secret", signature: "sha256=" + SignRequest("other", now, "n3", body), timestamp: now, nonce: "n3", wantErr: "mismatch"},
+		{name: "tampered body", signature: sign(now, "n4"), timestamp: now, nonce: "n4", body: []byte(`{"symbol":"ETHUSDT"}`), wantErr: "mismatch"},
+		{name: "nonce not signed", signature: sign(now, "n5"), timestamp: now, nonce: "n6", wantErr: "mismatch"},
+		{name: "stale timestamp", signature: sign("1000", "n7"), timestamp: "1000", nonce: "n7", wantErr: "outside the allowed window"},
+		{name: "future timestamp", signature: sign(strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10), "n8"), timestamp: strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10), nonce: "n8", wantErr: "outside the allowed window"},
+		{name: "bad timestamp", signature: sign("soon", "n9"), timestamp: "soon", nonce: "n9", wantErr: "unix timestamp"},
+		{name: "empty nonce", signature: sign(now, ""), timestamp: now, nonce: "", wantErr: "characters"},
+		{name: "long nonce", signature: sign(now, strings.Repeat("n", maxNonceLength+1)), timestamp: now, nonce: strings.Repeat("n", maxNonceLength+1), wantErr: "characters"},
+	}
+
+	// Cases run in order on one verifier: the replay reuses the first nonce
+	verifier := NewSignatureVerifier(secret, true, time.Minute)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestBody := body
+			if tt.body != nil {
+				requestBody = tt.body
+			}
+			err := verifier.Verify(tt.signature, tt.timestamp, tt.nonce, requestBody)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Verify: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Verify = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignatureRejectedNonceNotRemembered(t *testing.T) {
+	const secret = "webhook-secret"
+	body := []byte(`{}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	verifier := NewSignatureVerifier(secret, true, time.Minute)
+
+	// A forged request must not burn the nonce of the genuine one
+	if err := verifier.Verify("sha256=forged", now, "n1", body); err == nil {
+		t.Fatal("forged signature accepted")
+	}
+	if err := verifier.Verify(SignRequest(secret, now, "n1", body), now, "n1", body); err != nil {
+		t.Errorf("genuine request after forgery: %v", err)
+	}
+}
+
+// sharedNonces is an in-memory SharedNonceCache standing in for Redis
+type sharedNonces map[string]bool
+
+func (s sharedNonces) SetOnce(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if s[key] {
+		return false, nil
+	}
+	s[key] = true
+	return true, nil
+}
+
+func TestSignatureSharedNonces(t *testing.T) {
+	const secret = "webhook-secret"
+	body := []byte(`{}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	shared := sharedNonces{}
+
+	// Two instances sharing one cache: a request accepted by one is a replay on the other
+	first := NewSignatureVerifier(secret, true, time.Minute)
+	first.SetShared(shared)
+	second := NewSignatureVerifier(secret, true, time.Minute)
+	second.SetShared(shared)
+
+	if err := first.Verify(SignRequest(secret, now, "n1", body), now, "n1", body); err != nil {
+		t.Fatalf("first instance: %v", err)
+	}
+	if err := second.Verify(SignRequest(secret, now, "n1", body), now, "n1", body); err == nil || !strings.Contains(err.Error(), "replayed") {
+		t.Errorf("replay on second instance = %v, want replayed", err)
+	}
+}
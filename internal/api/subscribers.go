@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/storage"
+)
+
+// SubscribeTradeEvents links filled SL/TP orders of the primary account to
+// their trades, records their exit fees and publishes the resulting position
+// closes
+func SubscribeTradeEvents(bus *events.Bus, fb storage.TradeStore, bn *binance.Client) {
+	bus.Subscribe(events.TopicOrderFilled, "protective-fills", func(event events.Event) {
+		order := event.(events.OrderFilled).Order
+
+		var reason string
+		switch order.ProtectiveType() {
+		case "STOP_MARKET", "STOP":
+			reason = events.CloseStopLoss
+		case "TAKE_PROFIT_MARKET", "TAKE_PROFIT":
+			reason = events.CloseTakeProfit
+		default:
+			return
+		}
+
+		// Entry fills move trades to FILLED, so ACTIVE alone is not enough
+		trades, err := fb.GetAllTrades(context.Background())
+		if err != nil {
+			logging.Warn().Err(err).Str(logging.FieldSymbol, order.Symbol).Msgf("Failed to look up trade for %s order %d", order.Symbol, order.OrderID)
+			return
+		}
+		for _, trade := range trades {
+			// The user data stream belongs to the primary account
+			if trade.Account == "" && (trade.SLOrderID == order.OrderID || trade.TPOrderID == order.OrderID) {
+				bn.RecordExitFill(trade, order, fb)
+				bus.Publish(events.PositionClosed{
+					Trade:       trade,
+					Symbol:      order.Symbol,
+					RealizedPnL: order.RealizedPnL,
+					Reason:      reason,
+				})
+				return
+			}
+		}
+	})
+}
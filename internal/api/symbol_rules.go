@@ -0,0 +1,234 @@
+package api
+
+import (
+	"context"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/validation"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// checkSymbolRules checks a trade against its symbol's exchange filters, so
+// what Binance would reject is reported field by field before leverage or
+// orders are touched. The rules come from the cached exchange info; when
+// they cannot be loaded the trade goes ahead and order placement checks
+// quantity and notional itself.
+func checkSymbolRules(ctx context.Context, bn BinanceInterface, req *models.TradeRequest) error {
+	rules, err := bn.SymbolRules(ctx, req.Symbol)
+	if err != nil {
+		logging.Warn().Err(err).Str(logging.FieldSymbol, req.Symbol).Msgf("Failed to load exchange rules for %s, skipping pre-trade checks", req.Symbol)
+		return nil
+	}
+	if err := validateSymbolRules(req, rules); err != nil {
+		return err
+	}
+	return checkTriggerDistance(ctx, bn, req, rules)
+}
+
+// TriggerDistance is how far SL/TP must be from the mark price when a trade
+// is placed or its SL/TP moved. Binance rejects stops that would trigger at
+// once, and ones a tick away fire on the next print.
+type TriggerDistance struct {
+	MinTicks   int     // Tick sizes (0 = no minimum)
+	MinPercent float64 // Percent of the mark price (0 = no minimum)
+}
+
+var (
+	triggerDistance   TriggerDistance
+	triggerDistanceMu sync.RWMutex
+)
+
+// SetTriggerDistance sets the minimum SL/TP distance from the mark price;
+// the larger of the two minimums applies
+func SetTriggerDistance(distance TriggerDistance) {
+	triggerDistanceMu.Lock()
+	defer triggerDistanceMu.Unlock()
+	triggerDistance = distance
+}
+
+// checkTriggerDistance checks the request's SL/TP against the current mark
+// price. When the mark price cannot be read the trade goes ahead and Binance
+// has the last word.
+func checkTriggerDistance(ctx context.Context, bn BinanceInterface, req *models.TradeRequest, rules *binance.SymbolInfo) error {
+	triggerDistanceMu.RLock()
+	distance := triggerDistance
+	triggerDistanceMu.RUnlock()
+
+	if (distance.MinTicks <= 0 && distance.MinPercent <= 0) || (req.StopLoss <= 0 && req.TakeProfit <= 0) {
+		return nil
+	}
+	mark, err := bn.GetMarkPrice(ctx, req.Symbol)
+	if err != nil {
+		logging.Warn().Err(err).Str(logging.FieldSymbol, req.Symbol).Msgf("Failed to get the mark price of %s, skipping the SL/TP distance check", req.Symbol)
+		return nil
+	}
+	return validateTriggerDistance(req, rules, distance, mark)
+}
+
+// validateTriggerDistance checks that SL/TP sit on the closing side of the
+// mark price, at least the minimum distance away
+func validateTriggerDistance(req *models.TradeRequest, rules *binance.SymbolInfo, distance TriggerDistance, mark float64) error {
+	var errs validation.Errors
+
+	tick := parseFilter(rules.TickSize)
+	minGap := mark * distance.MinPercent / 100
+	if tick > 0 {
+		minGap = math.Ceil(math.Max(minGap, float64(distance.MinTicks)*tick)/tick-1e-9) * tick
+	}
+
+	// Long stops close below the mark price and long targets above it;
+	// shorts the other way round
+	stopBelow := req.Side == "BUY"
+	prices := []struct {
+		priceField
+		below bool
+	}{
+		{priceField{"stopLoss", req.StopLoss}, stopBelow},
+		{priceField{"takeProfit", req.TakeProfit}, !stopBelow},
+	}
+	for _, price := range prices {
+		if price.value <= 0 {
+			continue
+		}
+		gap, limit, relation, suggestion := mark-price.value, mark-minGap, "<=", "%s or lower"
+		if !price.below {
+			gap, limit, relation, suggestion = price.value-mark, mark+minGap, ">=", "%s or higher"
+		}
+		if gap >= minGap-1e-9 {
+			continue
+		}
+		if tick > 0 {
+			// Rounded away from the mark price, so the suggestion keeps the distance
+			if price.below {
+				limit = math.Floor(limit/tick+1e-9) * tick
+			} else {
+				limit = math.Ceil(limit/tick-1e-9) * tick
+			}
+		}
+		nearest := strconv.FormatFloat(limit, 'f', rules.PricePrecision, 64)
+		errs.Add(price.field, validation.CodeTriggerDistance, relation+" "+nearest,
+			fmt.Sprintf("%s %s is less than %s from the mark price %s for %s on the closing side",
+				price.field, formatPrice(price.value), formatPrice(roundToPrecision(minGap, rules.PricePrecision)), formatPrice(mark), req.Symbol),
+			"Use "+fmt.Sprintf(suggestion, nearest))
+	}
+	return errs.Err()
+}
+
+// validateSymbolRules checks prices against the tick size and price range,
+// and the quantity the order would get against the lot size and minimum
+// notional. Quantity is estimated at the entry price, which MARKET orders
+// replace with the current price.
+func validateSymbolRules(req *models.TradeRequest, rules *binance.SymbolInfo) error {
+	var errs validation.Errors
+
+	if rules == nil {
+		errs.Add("symbol", validation.CodeUnknown, "", fmt.Sprintf("%s is not listed on Binance Futures", req.Symbol),
+			"Use a USDT-margined futures symbol such as BTCUSDT")
+		return errs
+	}
+	if rules.Status != "" && rules.Status != "TRADING" {
+		errs.Add("symbol", validation.CodeNotTradeable, "status TRADING", fmt.Sprintf("%s is not trading (status %s)", req.Symbol, rules.Status),
+			"Retry once the symbol is trading again")
+		return errs
+	}
+
+	// Prices to the symbol's price precision. Order placement rounds them to
+	// the tick size, which could move a stop, so off-tick prices are rejected.
+	tick := parseFilter(rules.TickSize)
+	minPrice, maxPrice := parseFilter(rules.MinPrice), parseFilter(rules.MaxPrice)
+	prices := []priceField{{"stopLoss", req.StopLoss}, {"takeProfit", req.TakeProfit}}
+	if req.OrderType == "LIMIT" {
+		prices = append(prices, priceField{"entryPrice", req.EntryPrice})
+	}
+	for _, price := range prices {
+		if price.value <= 0 {
+			continue
+		}
+		sent := roundToPrecision(price.value, rules.PricePrecision)
+		switch {
+		case minPrice > 0 && sent < minPrice:
+			errs.Add(price.field, validation.CodeRange, ">= "+rules.MinPrice,
+				fmt.Sprintf("%s %s is below the minimum price %s for %s", price.field, formatPrice(price.value), rules.MinPrice, req.Symbol),
+				"Use "+rules.MinPrice+" or more")
+		case maxPrice > 0 && sent > maxPrice:
+			errs.Add(price.field, validation.CodeRange, "<= "+rules.MaxPrice,
+				fmt.Sprintf("%s %s is above the maximum price %s for %s", price.field, formatPrice(price.value), rules.MaxPrice, req.Symbol),
+				"Use "+rules.MaxPrice+" or less")
+		case tick > 0 && !isMultiple(sent, tick):
+			nearest := strconv.FormatFloat(math.Round(sent/tick)*tick, 'f', rules.PricePrecision, 64)
+			errs.Add(price.field, validation.CodeTickSize, "multiple of "+rules.TickSize,
+				fmt.Sprintf("%s %s is not a multiple of the tick size %s for %s", price.field, formatPrice(price.value), rules.TickSize, req.Symbol),
+				"Use "+nearest)
+		}
+	}
+
+	// Quantity as order placement computes it: size x leverage / price,
+	// rounded to the step size and at least one step
+	if req.EntryPrice <= 0 || req.Size <= 0 || req.Leverage <= 0 {
+		return errs.Err()
+	}
+	step := parseFilter(rules.StepSize)
+	if step <= 0 {
+		step = math.Pow10(-rules.QuantityPrecision)
+	}
+	leverage := float64(req.Leverage)
+	quantity := math.Round(req.Size*leverage/req.EntryPrice/step) * step
+	if quantity < step {
+		quantity = step
+	}
+
+	// sizeFor is the smallest size (USDT, rounded up to the cent) whose
+	// quantity reaches qty
+	sizeFor := func(qty float64) string {
+		qty = math.Ceil(qty/step-1e-9) * step
+		return fmt.Sprintf("%.2f", math.Ceil(qty*req.EntryPrice/leverage*100)/100)
+	}
+
+	minQty, maxQty, minNotional := parseFilter(rules.MinQuantity), parseFilter(rules.MaxQuantity), parseFilter(rules.MinNotional)
+	switch {
+	case minQty > 0 && quantity < minQty:
+		errs.Add("size", validation.CodeMinQuantity, "quantity >= "+rules.MinQuantity,
+			fmt.Sprintf("size %s USDT at %dx buys %s %s, below the minimum quantity %s", formatPrice(req.Size), req.Leverage, formatPrice(quantity), req.Symbol, rules.MinQuantity),
+			fmt.Sprintf("Use a size of at least %s USDT at %dx", sizeFor(minQty), req.Leverage))
+	case maxQty > 0 && quantity > maxQty:
+		maxSize := math.Floor(maxQty*req.EntryPrice/leverage*100) / 100
+		errs.Add("size", validation.CodeMaxQuantity, "quantity <= "+rules.MaxQuantity,
+			fmt.Sprintf("size %s USDT at %dx buys %s %s, above the maximum quantity %s", formatPrice(req.Size), req.Leverage, formatPrice(quantity), req.Symbol, rules.MaxQuantity),
+			fmt.Sprintf("Use a size of at most %.2f USDT at %dx", maxSize, req.Leverage))
+	case minNotional > 0 && quantity*req.EntryPrice < minNotional:
+		errs.Add("size", validation.CodeMinNotional, "notional >= "+rules.MinNotional+" USDT",
+			fmt.Sprintf("order value %.2f USDT is below the minimum notional %s USDT for %s", quantity*req.EntryPrice, rules.MinNotional, req.Symbol),
+			fmt.Sprintf("Use a size of at least %s USDT at %dx, or raise leverage", sizeFor(minNotional/req.EntryPrice), req.Leverage))
+	}
+
+	return errs.Err()
+}
+
+// priceField is a request price checked against the price filter
+type priceField struct {
+	field string
+	value float64
+}
+
+// parseFilter reads a numeric exchange filter value, 0 when unset
+func parseFilter(value string) float64 {
+	parsed, _ := strconv.ParseFloat(value, 64)
+	return parsed
+}
+
+// roundToPrecision rounds a value to a number of decimals
+func roundToPrecision(value float64, decimals int) float64 {
+	scale := math.Pow10(decimals)
+	return math.Round(value*scale) / scale
+}
+
+// isMultiple reports whether value is a whole number of steps, allowing for
+// floating point error
+func isMultiple(value, step float64) bool {
+	steps := value / step
+	return math.Abs(steps-math.Round(steps)) < 1e-6
+}
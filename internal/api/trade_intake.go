@@ -0,0 +1,922 @@
+package api
+
+import (
+	"context"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/calendar"
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/policy"
+	"crypto-trading-api/internal/tradehistory"
+	"crypto-trading-api/internal/webhooks"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TradeIntake validates trade requests, applies the intake policies and
+// executes them. It backs POST /api/trade and every other trade source
+// (queued trades, price alerts, ...) so they all follow the same rules.
+type TradeIntake struct {
+	fb         FirebaseInterface
+	bn         BinanceInterface
+	symbols    *policy.SymbolPolicy
+	limit      *policy.PositionLimit
+	pause      *policy.TradingPause
+	bus        *events.Bus
+	hooks      *webhooks.Dispatcher
+	followers  []Follower
+	clients    *binance.ClientPool
+	monitors   *MonitorManager
+	calendar   *calendar.Calendar       // Economic calendar blackouts; nil when disabled
+	volatility *binance.VolatilityGuard // Symbols halted by extreme moves; nil when disabled
+
+	executing sync.WaitGroup // Trades being validated and placed
+	draining  bool           // Shutting down: new trades are refused
+	recovery  chan struct{}  // Stops periodic monitor recovery
+	mu        sync.Mutex
+}
+
+// errShuttingDown refuses trades submitted after shutdown began
+var errShuttingDown = fmt.Errorf("server is shutting down")
+
+// Follower is an additional Binance account that copies every executed trade
+type Follower struct {
+	Name       string
+	Client     BinanceInterface
+	Multiplier float64 // Follower size = primary size * Multiplier
+}
+
+// TradeOutcome represents the result of submitting a trade request
+type TradeOutcome struct {
+	Trade   *models.Trade
+	Status  int    // HTTP status for API callers
+	Code    string // errorCode of a failure; classified from Status and Err when empty
+	Message string
+	Err     error
+	Copies  []*models.Trade // Follower account trades (successful or FAILED)
+}
+
+// NewTradeIntake creates the trade intake
+func NewTradeIntake(fb FirebaseInterface, bn BinanceInterface, symbols *policy.SymbolPolicy, limit *policy.PositionLimit,
+	pause *policy.TradingPause, bus *events.Bus, hooks *webhooks.Dispatcher, followers []Follower, clients *binance.ClientPool, monitors *MonitorManager) *TradeIntake {
+	return &TradeIntake{
+		fb:        fb,
+		bn:        bn,
+		symbols:   symbols,
+		limit:     limit,
+		pause:     pause,
+		bus:       bus,
+		hooks:     hooks,
+		followers: followers,
+		clients:   clients,
+		monitors:  monitors,
+	}
+}
+
+// UseCalendar closes trading during the calendar's blackouts: for every
+// trade when it blocks entries, else for presets with newsBlackout
+func (t *TradeIntake) UseCalendar(cal *calendar.Calendar) {
+	t.calendar = cal
+}
+
+// UseVolatilityGuard refuses new entries on symbols the volatility circuit
+// breaker halts
+func (t *TradeIntake) UseVolatilityGuard(guard *binance.VolatilityGuard) {
+	t.volatility = guard
+}
+
+// Submit validates and executes a trade request
+func (t *TradeIntake) Submit(ctx context.Context, req *models.TradeRequest) *TradeOutcome {
+	received := time.Now()
+	ctx = tradehistory.WithSource(ctx, tradehistory.SourceAPI)
+
+	if !t.begin() {
+		return &TradeOutcome{Status: http.StatusServiceUnavailable, Code: models.ErrShuttingDown, Message: "Server shutting down", Err: errShuttingDown}
+	}
+	defer t.executing.Done()
+
+	// Refuse new trades while trading is paused
+	if err := t.pause.Check(); err != nil {
+		return &TradeOutcome{Status: http.StatusServiceUnavailable, Code: models.ErrTradingPaused, Message: "Trading paused", Err: err}
+	}
+
+	bn, account, preset, rejected := t.prepare(ctx, req)
+	if rejected != nil {
+		return rejected
+	}
+
+	// Refuse entries on a symbol while its prices move too wildly
+	if err := t.volatility.Check(req.Symbol); err != nil {
+		return &TradeOutcome{Status: http.StatusServiceUnavailable, Code: models.ErrSymbolHalted, Message: "Symbol halted", Err: err}
+	}
+
+	// Outside the preset's trading hours the signal is refused, or queued
+	// until they open when the preset says so
+	var opensAt time.Time
+	if hours := t.tradingHours(preset); hours != nil {
+		var err error
+		if opensAt, err = policy.CheckTradingHours(hours, time.Now()); err != nil {
+			if opensAt.IsZero() || hours.Outside != models.OutsideHoursQueue {
+				return &TradeOutcome{Status: http.StatusForbidden, Code: models.ErrOutsideTradingHours, Message: "Outside trading hours", Err: err}
+			}
+		}
+	}
+
+	// Set default order type if not specified
+	orderType := req.OrderType
+	if orderType == "" {
+		orderType = "MARKET" // Default to MARKET order
+	}
+
+	// Set default margin type if not specified
+	marginType := req.MarginType
+	if marginType == "" {
+		marginType = "ISOLATED" // Default to ISOLATED margin
+	}
+
+	// Create trade record
+	trade := &models.Trade{
+		ID:         uuid.New().String(),
+		UserID:     req.UserID,
+		Symbol:     req.Symbol,
+		Side:       req.Side,
+		OrderType:  orderType,
+		MarginType: marginType,
+		EntryPrice: req.EntryPrice,
+		StopLoss:   req.StopLoss,
+		TakeProfit: req.TakeProfit,
+		Leverage:   req.Leverage,
+		Size:       req.Size,
+		Status:     "PENDING",
+		Account:    account,
+		Preset:     req.Preset,
+		CreatedAt:  time.Now().Unix(),
+
+		WorkingType: req.WorkingType,
+		Latency:     &models.TradeLatency{ReceivedAt: received.UnixMilli()},
+	}
+	if req.PriceProtect != nil {
+		trade.PriceProtect = *req.PriceProtect
+	}
+
+	if !opensAt.IsZero() {
+		trade.Status = "QUEUED"
+		trade.QueuedUntil = opensAt.Unix()
+		if err := t.fb.SaveTrade(ctx, trade); err != nil {
+			return &TradeOutcome{Trade: trade, Status: http.StatusInternalServerError, Code: models.ErrStorage, Message: "Failed to queue trade", Err: err}
+		}
+
+		return &TradeOutcome{
+			Trade:   trade,
+			Status:  http.StatusAccepted,
+			Message: "Trade queued until trading hours open at " + opensAt.UTC().Format(time.RFC3339),
+		}
+	}
+
+	// Enforce maxConcurrentPositions and the preset's maxPositions (reject or
+	// queue), holding the slot while the trade is placed
+	if t.limit != nil {
+		ok, limitErr, err := t.limit.Reserve(ctx, trade, preset)
+		if err != nil {
+			return &TradeOutcome{Status: http.StatusInternalServerError, Message: "Failed to check open positions", Err: err}
+		}
+
+		if !ok {
+			if t.limit.Mode() != policy.LimitModeQueue {
+				return &TradeOutcome{Status: http.StatusConflict, Code: models.ErrPositionLimit, Message: "Maximum concurrent positions reached", Err: fmt.Errorf("%s", limitErr)}
+			}
+
+			trade.Status = "QUEUED"
+			if err := t.fb.SaveTrade(ctx, trade); err != nil {
+				return &TradeOutcome{Trade: trade, Status: http.StatusInternalServerError, Code: models.ErrStorage, Message: "Failed to queue trade", Err: err}
+			}
+
+			return &TradeOutcome{
+				Trade:   trade,
+				Status:  http.StatusAccepted,
+				Message: "Trade queued until a position slot frees up (" + limitErr + ")",
+			}
+		}
+	}
+
+	// Execute trade on Binance
+	placeErr := placeTrade(ctx, bn, trade)
+
+	// The order may be on the exchange now: record it even if the caller has gone away
+	ctx = context.WithoutCancel(ctx)
+
+	if placeErr != nil {
+		t.limit.Release(trade.ID)
+		t.fb.SaveTrade(ctx, trade)
+		return &TradeOutcome{Trade: trade, Status: http.StatusInternalServerError, Message: "Failed to execute trade", Err: placeErr}
+	}
+
+	// Save to Firebase
+	if err := t.fb.SaveTrade(ctx, trade); err != nil {
+		return &TradeOutcome{Trade: trade, Status: http.StatusInternalServerError, Code: models.ErrStorage, Message: "Trade executed but failed to save", Err: err}
+	}
+
+	// Start monitoring for SL/TP (in goroutine)
+	t.monitors.Watch(bn, trade, false)
+
+	t.bus.Publish(events.TradeOpened{Trade: trade})
+
+	// Followers copy the operator account only
+	outcome := &TradeOutcome{Trade: trade, Status: http.StatusOK, Message: "Trade executed successfully"}
+	if account == "" && len(t.followers) > 0 {
+		outcome.Copies = t.copyToFollowers(ctx, trade)
+		outcome.Message += fmt.Sprintf(" (copied to %d/%d follower accounts)", countExecuted(outcome.Copies), len(outcome.Copies))
+	}
+
+	return outcome
+}
+
+// tradingHours returns the hours a trade may be placed in: its preset's,
+// with the calendar's blackouts added when the preset or the calendar asks
+// for them. nil means any time.
+func (t *TradeIntake) tradingHours(preset *models.StrategyPreset) *models.TradingHours {
+	var hours *models.TradingHours
+	if preset != nil && preset.TradingHours != nil {
+		copied := *preset.TradingHours
+		hours = &copied
+	}
+
+	if t.calendar == nil || (!t.calendar.BlocksEntries() && (hours == nil || !hours.NewsBlackout)) {
+		return hours
+	}
+	if hours == nil {
+		hours = &models.TradingHours{Outside: models.OutsideHoursReject}
+	}
+	hours.Blackouts = append(append([]models.Blackout(nil), hours.Blackouts...), t.calendar.Blackouts(time.Now())...)
+	return hours
+}
+
+// prepare resolves the account a trade request runs on, completes it from
+// its preset (returned when set) and the user's defaults and checks it
+// against the trade rules, risk limits, symbol policy and exchange filters. A
+// rejected request returns its outcome.
+func (t *TradeIntake) prepare(ctx context.Context, req *models.TradeRequest) (BinanceInterface, string, *models.StrategyPreset, *TradeOutcome) {
+	// Trade on the requested operator account, else on the user's own
+	// Binance account when they stored API keys
+	bn, account, err := t.clientFor(ctx, req.UserID, req.Account)
+	if errors.Is(err, binance.ErrUnknownAccount) {
+		return nil, "", nil, &TradeOutcome{Status: http.StatusBadRequest, Code: models.ErrNoAccount, Message: "Unknown account", Err: err}
+	}
+	if err != nil {
+		return nil, "", nil, &TradeOutcome{Status: http.StatusForbidden, Code: models.ErrNoAccount, Message: "No Binance account for user", Err: err}
+	}
+
+	// Fill omitted parameters from the referenced strategy preset
+	var preset *models.StrategyPreset
+	if req.Preset != "" {
+		var status int
+		if preset, status, err = t.applyPreset(ctx, bn, req); err != nil {
+			return nil, "", nil, &TradeOutcome{Status: status, Message: "Invalid preset", Err: err}
+		}
+	}
+
+	// Fill what the request and preset left out from the user's own defaults
+	var settings *models.UserSettings
+	if req.UserID != "" {
+		if settings, err = t.fb.GetUserSettings(ctx, req.UserID); err != nil {
+			return nil, "", nil, &TradeOutcome{Status: http.StatusInternalServerError, Code: models.ErrStorage, Message: "Failed to load user settings", Err: err}
+		}
+	}
+	applyUserDefaults(settings, req)
+
+	// Validate trade parameters
+	if err := validateTradeParams(req); err != nil {
+		return nil, "", nil, &TradeOutcome{Status: http.StatusBadRequest, Message: "Invalid trade parameters", Err: err}
+	}
+
+	if err := checkRiskLimits(settings, req); err != nil {
+		return nil, "", nil, &TradeOutcome{Status: http.StatusForbidden, Code: models.ErrRiskLimit, Message: "Risk limit exceeded", Err: err}
+	}
+
+	// Reject disallowed symbols before touching Binance
+	if err := t.symbols.Check(req.Symbol); err != nil {
+		return nil, "", nil, &TradeOutcome{Status: http.StatusForbidden, Code: models.ErrSymbolNotAllowed, Message: "Symbol not allowed", Err: err}
+	}
+
+	// Check prices and size against the symbol's exchange filters
+	if err := checkSymbolRules(ctx, bn, req); err != nil {
+		return nil, "", nil, &TradeOutcome{Status: http.StatusBadRequest, Message: "Invalid trade parameters", Err: err}
+	}
+
+	return bn, account, preset, nil
+}
+
+// ExecuteQueued places a previously QUEUED trade and starts monitoring it
+func (t *TradeIntake) ExecuteQueued(ctx context.Context, trade *models.Trade) error {
+	ctx = tradehistory.WithSource(ctx, tradehistory.SourcePositionQueue)
+
+	// Queued trades stay QUEUED and are placed after the restart
+	if !t.begin() {
+		return errShuttingDown
+	}
+	defer t.executing.Done()
+
+	// Leave queued trades waiting while trading is paused or the symbol halted
+	if err := t.pause.Check(); err != nil {
+		return err
+	}
+	if err := t.volatility.Check(trade.Symbol); err != nil {
+		return err
+	}
+
+	// Operator accounts stay as requested; user accounts are resolved again
+	// in case the user's keys changed while the trade was queued
+	requested := trade.Account
+	if strings.HasPrefix(requested, models.UserAccount("")) {
+		requested = ""
+	}
+	bn, account, err := t.clientFor(ctx, trade.UserID, requested)
+	if err != nil {
+		return err
+	}
+	trade.Account = account
+
+	// Timed from its release: the wait in the queue is not execution latency
+	trade.Latency = &models.TradeLatency{ReceivedAt: time.Now().UnixMilli()}
+	placeErr := placeTrade(ctx, bn, trade)
+	ctx = context.WithoutCancel(ctx)
+
+	if err := t.fb.UpdateTrade(ctx, trade); err != nil {
+		return fmt.Errorf("failed to save queued trade: %v", err)
+	}
+
+	if placeErr != nil {
+		return placeErr
+	}
+
+	t.monitors.Watch(bn, trade, false)
+
+	t.bus.Publish(events.TradeOpened{Trade: trade})
+
+	if account == "" {
+		t.copyToFollowers(ctx, trade)
+	}
+	return nil
+}
+
+// CancelTrade aborts a QUEUED trade, or an ACTIVE one whose entry order has
+// not filled: the entry and its SL/TP orders are cancelled, its monitor
+// stopped and the trade marked CANCELED. It fails with binance.ErrEntryFilled
+// once the entry has filled, even partly.
+func (t *TradeIntake) CancelTrade(ctx context.Context, trade *models.Trade) error {
+	if trade.Status == "ACTIVE" {
+		bn, err := t.clientForAccount(ctx, trade)
+		if err != nil {
+			return err
+		}
+		if err := bn.CancelEntry(ctx, trade); err != nil {
+			return err
+		}
+	}
+
+	// The orders are gone: record it even if the caller has gone away
+	ctx = context.WithoutCancel(ctx)
+	t.monitors.Cancel(trade.ID)
+
+	trade.Status = "CANCELED"
+	trade.ClosedAt = time.Now().Unix()
+	if err := t.fb.UpdateTrade(ctx, trade); err != nil {
+		return fmt.Errorf("failed to save cancelled trade: %v", err)
+	}
+	return nil
+}
+
+// Replace moves a pending trade to new entry parameters under the same trade
+// ID: a QUEUED trade is updated, an ACTIVE one whose entry has not filled has
+// its entry and SL/TP orders cancelled and placed again. If the new orders
+// are rejected, the previous ones are placed again.
+func (t *TradeIntake) Replace(ctx context.Context, trade *models.Trade, change *models.TradeReplaceRequest) *TradeOutcome {
+	received := time.Now()
+	ctx = tradehistory.WithSource(ctx, tradehistory.SourceAPI)
+
+	if !t.begin() {
+		return &TradeOutcome{Status: http.StatusServiceUnavailable, Code: models.ErrShuttingDown, Message: "Server shutting down", Err: errShuttingDown}
+	}
+	defer t.executing.Done()
+
+	if err := t.pause.Check(); err != nil {
+		return &TradeOutcome{Status: http.StatusServiceUnavailable, Code: models.ErrTradingPaused, Message: "Trading paused", Err: err}
+	}
+	if err := t.volatility.Check(trade.Symbol); err != nil {
+		return &TradeOutcome{Status: http.StatusServiceUnavailable, Code: models.ErrSymbolHalted, Message: "Symbol halted", Err: err}
+	}
+
+	bn, err := t.clientForAccount(ctx, trade)
+	if err != nil {
+		return &TradeOutcome{Status: http.StatusInternalServerError, Message: "Failed to resolve the trade's account", Err: err}
+	}
+
+	// The replaced trade passes the same checks as a new one
+	req := &models.TradeRequest{
+		UserID:     trade.UserID,
+		Symbol:     trade.Symbol,
+		Side:       trade.Side,
+		EntryPrice: trade.EntryPrice,
+		StopLoss:   trade.StopLoss,
+		TakeProfit: trade.TakeProfit,
+		Leverage:   trade.Leverage,
+		Size:       trade.Size,
+		OrderType:  trade.OrderType,
+		MarginType: trade.MarginType,
+	}
+	if change.EntryPrice > 0 {
+		req.EntryPrice = change.EntryPrice
+	}
+	if change.StopLoss > 0 {
+		req.StopLoss = change.StopLoss
+	}
+	if change.TakeProfit > 0 {
+		req.TakeProfit = change.TakeProfit
+	}
+	if change.Size > 0 {
+		req.Size = change.Size
+	}
+	if err := validateTradeParams(req); err != nil {
+		return &TradeOutcome{Status: http.StatusBadRequest, Message: "Invalid trade parameters", Err: err}
+	}
+	settings, err := t.fb.GetUserSettings(ctx, trade.UserID)
+	if err != nil {
+		return &TradeOutcome{Status: http.StatusInternalServerError, Code: models.ErrStorage, Message: "Failed to load user settings", Err: err}
+	}
+	if err := checkRiskLimits(settings, req); err != nil {
+		return &TradeOutcome{Status: http.StatusForbidden, Code: models.ErrRiskLimit, Message: "Risk limit exceeded", Err: err}
+	}
+	if err := checkSymbolRules(ctx, bn, req); err != nil {
+		return &TradeOutcome{Status: http.StatusBadRequest, Message: "Invalid trade parameters", Err: err}
+	}
+
+	previous := *trade
+	trade.EntryPrice, trade.StopLoss, trade.TakeProfit, trade.Size = req.EntryPrice, req.StopLoss, req.TakeProfit, req.Size
+
+	if previous.Status == "QUEUED" {
+		if err := t.fb.UpdateTrade(ctx, trade); err != nil {
+			return &TradeOutcome{Trade: trade, Status: http.StatusInternalServerError, Code: models.ErrStorage, Message: "Failed to save trade", Err: err}
+		}
+		return &TradeOutcome{Trade: trade, Status: http.StatusOK, Message: "Queued trade replaced"}
+	}
+
+	if err := bn.CancelEntry(ctx, &previous); err != nil {
+		*trade = previous
+		if errors.Is(err, binance.ErrEntryFilled) {
+			return &TradeOutcome{Trade: trade, Status: http.StatusConflict, Code: models.ErrConflict, Message: "Entry already filled", Err: err}
+		}
+		return &TradeOutcome{Trade: trade, Status: http.StatusInternalServerError, Message: "Failed to cancel the entry", Err: err}
+	}
+
+	// The old orders are gone: finish even if the caller has gone away
+	ctx = context.WithoutCancel(ctx)
+	t.monitors.Cancel(trade.ID)
+
+	message := "Trade replaced"
+	trade.Latency = &models.TradeLatency{ReceivedAt: received.UnixMilli()}
+	placeErr := placeTrade(ctx, bn, trade)
+	if placeErr != nil {
+		logging.Ctx(ctx).Warn().Err(placeErr).Str(logging.FieldTradeID, trade.ID).Msgf("Replacement of trade %s rejected, placing the previous entry again", trade.ID)
+		*trade = previous
+		trade.Latency = &models.TradeLatency{ReceivedAt: received.UnixMilli()}
+		if err := placeTrade(ctx, bn, trade); err != nil {
+			message = "Replacement rejected and the previous entry could not be placed again"
+		} else {
+			message = "Replacement rejected; the previous entry was placed again"
+		}
+	}
+
+	if err := t.fb.UpdateTrade(ctx, trade); err != nil {
+		return &TradeOutcome{Trade: trade, Status: http.StatusInternalServerError, Code: models.ErrStorage, Message: "Trade replaced but failed to save", Err: err}
+	}
+	if trade.Status == "ACTIVE" {
+		t.monitors.Watch(bn, trade, false)
+	}
+
+	if placeErr != nil {
+		return &TradeOutcome{Trade: trade, Status: http.StatusInternalServerError, Message: message, Err: placeErr}
+	}
+	return &TradeOutcome{Trade: trade, Status: http.StatusOK, Message: message}
+}
+
+// begin registers a trade execution, false once the intake is draining
+func (t *TradeIntake) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.draining {
+		return false
+	}
+	t.executing.Add(1)
+	return true
+}
+
+// Drain refuses new trades from now on and waits until the trades being
+// placed have their orders, SL/TP orders and records, or ctx is done
+func (t *TradeIntake) Drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.executing.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("trades still executing: %v", ctx.Err())
+	}
+}
+
+// Draining reports whether the intake refuses new trades for shutdown
+func (t *TradeIntake) Draining() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.draining
+}
+
+// clientFor returns the Binance account a trade is placed on and its
+// Trade.Account: "" for the main account, the name of another operator
+// account, or the user's account (models.UserAccount)
+func (t *TradeIntake) clientFor(ctx context.Context, userID, account string) (BinanceInterface, string, error) {
+	if account == binance.PrimaryAccount {
+		account = ""
+	}
+	if t.clients == nil {
+		if account != "" {
+			return nil, "", fmt.Errorf("%w %q", binance.ErrUnknownAccount, account)
+		}
+		return t.bn, "", nil
+	}
+
+	if account != "" {
+		client, err := t.clients.Account(account)
+		if err != nil {
+			return nil, "", err
+		}
+		return client, account, nil
+	}
+
+	if !t.clients.Enabled() {
+		return t.bn, "", nil
+	}
+	client, err := t.clients.ForUser(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if t.clients.IsPrimary(client) {
+		return t.bn, "", nil
+	}
+	return client, models.UserAccount(userID), nil
+}
+
+// clientForAccount returns the Binance account a recorded trade was placed on
+func (t *TradeIntake) clientForAccount(ctx context.Context, trade *models.Trade) (BinanceInterface, error) {
+	switch {
+	case trade.Account == "":
+		return t.bn, nil
+	case trade.Account == models.UserAccount(trade.UserID):
+		if t.clients == nil || !t.clients.Enabled() {
+			return nil, fmt.Errorf("per-user Binance keys are not enabled")
+		}
+		return t.clients.ForUser(ctx, trade.UserID)
+	}
+
+	if t.clients != nil {
+		if client, err := t.clients.Account(trade.Account); err == nil {
+			return client, nil
+		}
+	}
+
+	for _, follower := range t.followers {
+		if follower.Name == trade.Account {
+			return follower.Client, nil
+		}
+	}
+	return nil, fmt.Errorf("account %q is not configured", trade.Account)
+}
+
+// RecoverMonitors re-attaches monitors to ACTIVE trades left open by a
+// previous run, or by an instance that stopped, and returns how many were
+// started. Trades monitored here or by another instance are skipped.
+func (t *TradeIntake) RecoverMonitors(ctx context.Context) (int, error) {
+	trades, err := t.fb.GetActiveTrades(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	recovered := 0
+	for _, trade := range trades {
+		if trade.OrderID == 0 {
+			continue
+		}
+		if _, ok := t.monitors.Get(trade.ID); ok {
+			continue
+		}
+
+		bn, err := t.clientForAccount(ctx, trade)
+		if err != nil {
+			logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("Cannot resume monitoring trade %s", trade.ID)
+			continue
+		}
+
+		if t.monitors.Watch(bn, trade, true) {
+			recovered++
+		}
+	}
+	return recovered, nil
+}
+
+// StartMonitorRecovery runs RecoverMonitors every interval, adopting the
+// trades of instances that stopped. It may be started again after
+// StopMonitorRecovery.
+func (t *TradeIntake) StartMonitorRecovery(interval time.Duration) {
+	stop := make(chan struct{})
+	t.mu.Lock()
+	t.recovery = stop
+	t.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if recovered, err := t.RecoverMonitors(context.Background()); err != nil {
+					logging.Warn().Err(err).Msg("Failed to recover trade monitors")
+				} else if recovered > 0 {
+					logging.Info().Msgf("Adopted %d unmonitored active trades", recovered)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopMonitorRecovery stops periodic monitor recovery
+func (t *TradeIntake) StopMonitorRecovery() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.recovery != nil {
+		close(t.recovery)
+		t.recovery = nil
+	}
+}
+
+// copyToFollowers replicates an executed primary trade to every follower
+// account in parallel. Each copy is an independent Trade record; a failure on
+// one account is recorded on its copy and never affects the others.
+func (t *TradeIntake) copyToFollowers(ctx context.Context, primary *models.Trade) []*models.Trade {
+	ctx = tradehistory.WithSource(ctx, tradehistory.SourceCopyTrading)
+	copies := make([]*models.Trade, len(t.followers))
+
+	var wg sync.WaitGroup
+	for i, follower := range t.followers {
+		copies[i] = &models.Trade{
+			ID:         uuid.New().String(),
+			UserID:     primary.UserID,
+			Symbol:     primary.Symbol,
+			Side:       primary.Side,
+			OrderType:  primary.OrderType,
+			MarginType: primary.MarginType,
+			EntryPrice: primary.EntryPrice,
+			StopLoss:   primary.StopLoss,
+			TakeProfit: primary.TakeProfit,
+			Leverage:   primary.Leverage,
+			Size:       primary.Size * follower.Multiplier,
+			Status:     "PENDING",
+			CreatedAt:  time.Now().Unix(),
+			Account:    follower.Name,
+			CopyOf:     primary.ID,
+
+			WorkingType:  primary.WorkingType,
+			PriceProtect: primary.PriceProtect,
+		}
+		if primary.Latency != nil {
+			copies[i].Latency = &models.TradeLatency{ReceivedAt: primary.Latency.ReceivedAt}
+		}
+
+		wg.Add(1)
+		go func(follower Follower, trade *models.Trade) {
+			defer wg.Done()
+
+			if err := placeTrade(ctx, follower.Client, trade); err != nil {
+				logging.Ctx(ctx).Error().Err(err).Str(logging.FieldTradeID, trade.ID).Str("copy_of", primary.ID).Msgf("Copy of trade %s failed on account %s", primary.ID, follower.Name)
+				t.fb.SaveTrade(ctx, trade)
+				return
+			}
+
+			if err := t.fb.SaveTrade(ctx, trade); err != nil {
+				logging.Ctx(ctx).Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Str("copy_of", primary.ID).Msgf("Copy of trade %s executed on account %s but failed to save", primary.ID, follower.Name)
+			}
+
+			t.monitors.Watch(follower.Client, trade, false)
+			logging.Ctx(ctx).Info().Str(logging.FieldTradeID, trade.ID).Str("copy_of", primary.ID).Msgf("Trade %s copied to account %s (size %.2f)", primary.ID, follower.Name, trade.Size)
+		}(follower, copies[i])
+	}
+	wg.Wait()
+
+	return copies
+}
+
+// countExecuted counts copies that were placed on their account
+func countExecuted(copies []*models.Trade) int {
+	n := 0
+	for _, trade := range copies {
+		if trade.Status != "FAILED" {
+			n++
+		}
+	}
+	return n
+}
+
+// applyUserDefaults fills leverage and margin type the request (and its
+// preset) left empty from the user's settings
+func applyUserDefaults(settings *models.UserSettings, req *models.TradeRequest) {
+	if settings == nil {
+		return
+	}
+	if req.Leverage == 0 {
+		req.Leverage = settings.DefaultLeverage
+	}
+	if req.MarginType == "" {
+		req.MarginType = settings.DefaultMarginType
+	}
+}
+
+// checkRiskLimits rejects trades beyond the limits in the user's settings
+func checkRiskLimits(settings *models.UserSettings, req *models.TradeRequest) error {
+	if settings == nil {
+		return nil
+	}
+	limits := settings.RiskLimits
+	if limits.MaxLeverage > 0 && req.Leverage > limits.MaxLeverage {
+		return fmt.Errorf("leverage %dx exceeds the user's limit of %dx", req.Leverage, limits.MaxLeverage)
+	}
+	if limits.MaxPositionSize > 0 && req.Size > limits.MaxPositionSize {
+		return fmt.Errorf("size %.2f USDT exceeds the user's limit of %.2f USDT", req.Size, limits.MaxPositionSize)
+	}
+	return nil
+}
+
+// applyPreset fills the request fields left empty from its strategy preset
+// and enforces the preset's symbol list. It returns the preset, and an HTTP
+// status on error.
+func (t *TradeIntake) applyPreset(ctx context.Context, bn BinanceInterface, req *models.TradeRequest) (*models.StrategyPreset, int, error) {
+	preset, err := t.fb.GetStrategyPreset(ctx, req.Preset)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if preset == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("preset %q not found", req.Preset)
+	}
+	if preset.UserID != req.UserID {
+		return nil, http.StatusForbidden, fmt.Errorf("preset %q belongs to another user", req.Preset)
+	}
+
+	if len(preset.AllowedSymbols) > 0 {
+		allowed := false
+		for _, symbol := range preset.AllowedSymbols {
+			if strings.EqualFold(symbol, req.Symbol) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, http.StatusForbidden, fmt.Errorf("symbol %s is not allowed by preset %q", req.Symbol, req.Preset)
+		}
+	}
+
+	if req.Leverage == 0 {
+		req.Leverage = preset.Leverage
+	}
+	if req.OrderType == "" {
+		req.OrderType = preset.OrderType
+	}
+	if req.MarginType == "" {
+		req.MarginType = preset.MarginType
+	}
+	if req.WorkingType == "" {
+		req.WorkingType = preset.WorkingType
+	}
+	if req.PriceProtect == nil && preset.PriceProtect {
+		req.PriceProtect = &preset.PriceProtect
+	}
+
+	// SL/TP as a distance from the entry price, on the correct side for BUY/SELL
+	if req.EntryPrice > 0 {
+		direction := 1.0
+		if req.Side == "SELL" {
+			direction = -1.0
+		}
+		if req.StopLoss == 0 && preset.StopLossPercent > 0 {
+			req.StopLoss = req.EntryPrice * (1 - direction*preset.StopLossPercent/100)
+		}
+		if req.TakeProfit == 0 && preset.TakeProfitPercent > 0 {
+			req.TakeProfit = req.EntryPrice * (1 + direction*preset.TakeProfitPercent/100)
+		}
+	}
+
+	if req.Size == 0 {
+		switch preset.SizingMode {
+		case models.SizingModeRiskPercent:
+			if req.EntryPrice <= 0 || req.StopLoss <= 0 || req.Leverage <= 0 {
+				return nil, http.StatusBadRequest, fmt.Errorf("risk-based sizing needs entry price, stop loss and leverage")
+			}
+
+			account, err := bn.GetAccountInfo(ctx)
+			if err != nil {
+				return nil, http.StatusInternalServerError, fmt.Errorf("failed to get account equity: %v", err)
+			}
+
+			// Loss at stop = size * leverage * stopDistance; solve for size
+			stopDistance := math.Abs(req.EntryPrice-req.StopLoss) / req.EntryPrice
+			riskAmount := account.TotalMarginBalance * preset.RiskPercent / 100
+			req.Size = math.Round(riskAmount/(stopDistance*float64(req.Leverage))*100) / 100
+		default:
+			req.Size = preset.Size
+		}
+	}
+
+	return preset, http.StatusOK, nil
+}
+
+// placeTrade executes a trade on Binance and records the order result on it
+func placeTrade(ctx context.Context, bn BinanceInterface, trade *models.Trade) error {
+	var orderResult *binance.OrderResult
+	var err error
+	if submitErr := binance.SubmitOrder(ctx, binance.OrderKey(trade.Account, trade.Symbol), func() {
+		orderResult, err = bn.PlaceFuturesOrder(ctx, trade)
+	}); submitErr != nil {
+		err = fmt.Errorf("order not submitted: %v", submitErr)
+	}
+	if err != nil {
+		trade.Status = "FAILED"
+		trade.Error = err.Error()
+		return err
+	}
+
+	// Update trade with order result
+	trade.Status = "ACTIVE"
+	trade.Error = ""
+	trade.OrderID = orderResult.OrderID
+	trade.SLOrderID = orderResult.SLOrderID
+	trade.TPOrderID = orderResult.TPOrderID
+	trade.ExecutedPrice = orderResult.AvgPrice
+	trade.ExecutedAt = time.Now().Unix()
+	recordOrderLatency(trade, orderResult)
+
+	return nil
+}
+
+// recordOrderLatency records the placement timings of a timed trade
+func recordOrderLatency(trade *models.Trade, result *binance.OrderResult) {
+	timing := trade.Latency
+	if timing == nil || result.SentAt.IsZero() {
+		return
+	}
+	since := func(t time.Time) int64 {
+		return t.UnixMilli() - timing.ReceivedAt
+	}
+
+	timing.ValidationMs = since(result.SentAt)
+	timing.OrderMs = result.AckAt.Sub(result.SentAt).Milliseconds()
+	timing.AcknowledgedMs = since(result.AckAt)
+	if !result.ProtectedAt.IsZero() {
+		timing.ProtectionMs = result.ProtectedAt.Sub(result.AckAt).Milliseconds()
+	}
+	if !result.FilledAt.IsZero() && since(result.FilledAt) > 0 {
+		timing.FilledMs = since(result.FilledAt)
+	}
+}
+
+// lifecycleStore fires the FILLED webhook when MonitorTrade records the entry fill
+type lifecycleStore struct {
+	fb    FirebaseInterface
+	hooks *webhooks.Dispatcher
+}
+
+func (s lifecycleStore) UpdateTrade(ctx context.Context, trade *models.Trade) error {
+	if err := s.fb.UpdateTrade(ctx, trade); err != nil {
+		return err
+	}
+	if trade.Status == "FILLED" {
+		s.hooks.Dispatch(webhooks.EventFilled, trade)
+	}
+	return nil
+}
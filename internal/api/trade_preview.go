@@ -0,0 +1,266 @@
+package api
+
+import (
+	"context"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/policy"
+	"crypto-trading-api/internal/validation"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TradePreview is what a trade request would place, and what it is expected
+// to cost, without placing it
+type TradePreview struct {
+	Request        *models.TradeRequest `json:"request"`                              // Completed from the preset and user defaults
+	Account        string               `json:"account,omitempty" example:"sub1"`     // Trade.Account the trade would get
+	Quantity       float64              `json:"quantity" example:"0.2"`               // At the entry price, before step size rounding
+	Notional       float64              `json:"notional" example:"10000"`             // Size x leverage, in USDT
+	Fees           *binance.FeeEstimate `json:"fees,omitempty"`                       // Missing when the account's fee rates could not be read
+	LossAtStop     float64              `json:"lossAtStop" example:"208.91"`          // Price loss at the stop loss plus fees
+	ProfitAtTarget float64              `json:"profitAtTarget" example:"390.82"`      // Price gain at the take profit less fees
+	FeeError       string               `json:"feeError,omitempty" example:"timeout"` // Why fees are missing
+	OutsideHours   string               `json:"outsideHours,omitempty"`               // Why trading hours or a news blackout would reject or queue it now
+	Halted         string               `json:"halted,omitempty"`                     // Why the volatility circuit breaker would refuse it now
+}
+
+// Preview runs a trade request through the same checks as Submit (except
+// the pause, position limits, trading hours and volatility halts, which
+// depend on when it is placed) and estimates its fees from the account's commission rates
+func (t *TradeIntake) Preview(ctx context.Context, req *models.TradeRequest) (*TradePreview, *TradeOutcome) {
+	_, preview, rejected := t.preview(ctx, req)
+	return preview, rejected
+}
+
+// preview builds a trade request's preview and returns the account it
+// would be placed on
+func (t *TradeIntake) preview(ctx context.Context, req *models.TradeRequest) (BinanceInterface, *TradePreview, *TradeOutcome) {
+	bn, account, preset, rejected := t.prepare(ctx, req)
+	if rejected != nil {
+		return nil, nil, rejected
+	}
+
+	if req.OrderType == "" {
+		req.OrderType = "MARKET"
+	}
+	if req.MarginType == "" {
+		req.MarginType = "ISOLATED"
+	}
+	req.APIKey = ""
+
+	preview := &TradePreview{
+		Request:  req,
+		Account:  account,
+		Notional: req.Size * float64(req.Leverage),
+	}
+	preview.Quantity = preview.Notional / req.EntryPrice
+	preview.LossAtStop = roundToPrecision(preview.Quantity*math.Abs(req.EntryPrice-req.StopLoss), 8)
+	preview.ProfitAtTarget = roundToPrecision(preview.Quantity*math.Abs(req.TakeProfit-req.EntryPrice), 8)
+	if hours := t.tradingHours(preset); hours != nil {
+		if _, err := policy.CheckTradingHours(hours, time.Now()); err != nil {
+			preview.OutsideHours = err.Error()
+		}
+	}
+	if err := t.volatility.Check(req.Symbol); err != nil {
+		preview.Halted = err.Error()
+	}
+
+	// The preview is still useful without fees
+	rates, err := bn.GetCommissionRates(ctx, req.Symbol)
+	if err != nil {
+		logging.Ctx(ctx).Warn().Err(err).Str(logging.FieldSymbol, req.Symbol).Msg("Failed to get commission rates for trade preview")
+		preview.FeeError = err.Error()
+		return bn, preview, nil
+	}
+	preview.Fees = binance.EstimateFees(rates, req.OrderType, req.Size, req.Leverage, req.EntryPrice, req.StopLoss, req.TakeProfit)
+	preview.LossAtStop = roundToPrecision(preview.LossAtStop+preview.Fees.TotalAtStop, 8)
+	preview.ProfitAtTarget = roundToPrecision(preview.ProfitAtTarget-preview.Fees.TotalAtTarget, 8)
+	return bn, preview, nil
+}
+
+// TradePreviewHandler - Validate a trade and estimate its cost
+// @Summary      Preview a trade
+// @Description  Validate a trade request like POST /api/trade (preset, user defaults, risk limits, symbol policy and exchange filters) without placing it, and estimate its fees from the account's maker/taker rates, including the BNB fee discount when the account pays fees in BNB. A LIMIT entry is estimated at the maker rate, a MARKET entry and the SL/TP exits at the taker rate.
+// @Tags         Trading
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        trade  body      models.TradeRequest  true  "Trade parameters"
+// @Success      200    {object}  models.TradeResponse{data=TradePreview}  "Trade preview"
+// @Failure      400    {object}  models.TradeResponse  "Invalid request or trade parameters"
+// @Failure      401    {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403    {object}  models.TradeResponse  "Symbol not allowed or risk limit exceeded"
+// @Router       /api/trade/preview [post]
+func TradePreviewHandler(intake *TradeIntake) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.TradeRequest
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !claimOwnership(c, &req.UserID) || !requireAccountAccess(c, req.Account) {
+			return
+		}
+
+		preview, rejected := intake.Preview(c.Request.Context(), &req)
+		if rejected != nil {
+			respondTradeOutcome(c, rejected)
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Trade is valid",
+			Data:      preview,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// TradeSimulation projects a trade's outcomes: the preview's loss at the stop
+// loss and profit at the take profit, where an ISOLATED position backed by
+// its margin is liquidated, the price that covers the fees and the
+// risk:reward ratio
+type TradeSimulation struct {
+	*TradePreview
+	RequiredMargin     float64                     `json:"requiredMargin" example:"1000"`                 // Initial margin (notional / leverage)
+	MaintenanceBracket *binance.MaintenanceBracket `json:"maintenanceBracket,omitempty"`                  // Bracket of the notional
+	LiquidationPrice   float64                     `json:"liquidationPrice,omitempty" example:"45180.72"` // Estimated from the margin alone
+	LossAtLiquidation  float64                     `json:"lossAtLiquidation,omitempty" example:"968.63"`  // Price loss at liquidation plus the entry fee
+	BreakEvenPrice     float64                     `json:"breakEvenPrice" example:"50045.02"`             // Exit price that covers the entry and exit fees
+	RiskReward         float64                     `json:"riskReward" example:"1.87"`                     // profitAtTarget / lossAtStop
+	Warnings           []string                    `json:"warnings,omitempty"`                            // Parameters that look wrong
+}
+
+// Simulate previews a trade request and projects its outcomes. Parameters
+// that pass validation but look like mistakes (a stop beyond liquidation, a
+// target inside the fees) are reported as warnings.
+func (t *TradeIntake) Simulate(ctx context.Context, req *models.TradeRequest) (*TradeSimulation, *TradeOutcome) {
+	bn, preview, rejected := t.preview(ctx, req)
+	if rejected != nil {
+		return nil, rejected
+	}
+
+	sim := &TradeSimulation{
+		TradePreview:   preview,
+		RequiredMargin: roundToPrecision(preview.Notional/float64(req.Leverage), 8),
+		BreakEvenPrice: req.EntryPrice,
+	}
+	if preview.LossAtStop > 0 {
+		sim.RiskReward = roundToPrecision(preview.ProfitAtTarget/preview.LossAtStop, 2)
+	}
+
+	var entryFee float64
+	if fees := preview.Fees; fees != nil {
+		entryFee = fees.EntryFee
+		sim.BreakEvenPrice = roundToPrecision(binance.BreakEvenPrice(req.Side, req.EntryPrice, fees.EntryRate, fees.ExitRate), 8)
+	} else {
+		sim.Warnings = append(sim.Warnings, "fee rates unavailable: projections leave out fees")
+	}
+
+	bracket, err := bn.MaintenanceBracket(ctx, req.Symbol, preview.Notional)
+	if err != nil {
+		logging.Ctx(ctx).Warn().Err(err).Str(logging.FieldSymbol, req.Symbol).Msg("Failed to get leverage brackets for trade simulation")
+		sim.Warnings = append(sim.Warnings, "leverage brackets unavailable: no liquidation price")
+	} else {
+		sim.MaintenanceBracket = bracket
+		sim.LiquidationPrice = roundToPrecision(binance.LiquidationPrice(req.Side, preview.Quantity, req.EntryPrice, sim.RequiredMargin, bracket), 8)
+		sim.LossAtLiquidation = roundToPrecision(preview.Quantity*math.Abs(req.EntryPrice-sim.LiquidationPrice)+entryFee, 8)
+		if req.Leverage > bracket.MaxLeverage {
+			sim.Warnings = append(sim.Warnings, fmt.Sprintf("leverage %dx is above the %dx %s allows for a %s notional",
+				req.Leverage, bracket.MaxLeverage, req.Symbol, formatPrice(preview.Notional)))
+		}
+	}
+
+	sim.Warnings = append(sim.Warnings, simulationWarnings(req, sim)...)
+	return sim, nil
+}
+
+// simulationWarnings reports trade parameters that would not work out as
+// intended
+func simulationWarnings(req *models.TradeRequest, sim *TradeSimulation) []string {
+	var warnings []string
+	long := req.Side == "BUY"
+
+	if liq := sim.LiquidationPrice; liq > 0 {
+		if (long && req.StopLoss <= liq) || (!long && req.StopLoss >= liq) {
+			warnings = append(warnings, fmt.Sprintf("stop loss %s is beyond the estimated liquidation price %s: the position would be liquidated first",
+				formatPrice(req.StopLoss), formatPrice(liq)))
+		}
+	}
+	if (long && req.TakeProfit <= sim.BreakEvenPrice) || (!long && req.TakeProfit >= sim.BreakEvenPrice) {
+		warnings = append(warnings, fmt.Sprintf("take profit %s does not cover the fees (break-even %s)",
+			formatPrice(req.TakeProfit), formatPrice(sim.BreakEvenPrice)))
+	}
+	if sim.RiskReward > 0 && sim.RiskReward < 1 {
+		warnings = append(warnings, fmt.Sprintf("risk:reward is %.2f: the stop loss risks more than the take profit gains", sim.RiskReward))
+	}
+	return warnings
+}
+
+// TradeSimulateHandler - Project a trade's PnL at SL, TP and liquidation
+// @Summary      Simulate a trade
+// @Description  Validate a trade request like POST /api/trade/preview and project its outcomes without placing it: loss at the stop loss and profit at the take profit (net of fees), the estimated liquidation price of an ISOLATED position backed by its margin and the loss there, the required margin, the break-even price including fees and the risk:reward ratio. Parameters that look wrong (a stop beyond liquidation, a target inside the fees, risk:reward below 1) are listed in warnings.
+// @Tags         Trading
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        trade  body      models.TradeRequest  true  "Trade parameters"
+// @Success      200    {object}  models.TradeResponse{data=TradeSimulation}  "Trade simulation"
+// @Failure      400    {object}  models.TradeResponse  "Invalid request or trade parameters"
+// @Failure      401    {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403    {object}  models.TradeResponse  "Symbol not allowed or risk limit exceeded"
+// @Router       /api/trade/simulate [post]
+func TradeSimulateHandler(intake *TradeIntake) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.TradeRequest
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !claimOwnership(c, &req.UserID) || !requireAccountAccess(c, req.Account) {
+			return
+		}
+
+		sim, rejected := intake.Simulate(c.Request.Context(), &req)
+		if rejected != nil {
+			respondTradeOutcome(c, rejected)
+			return
+		}
+
+		message := "Trade simulated"
+		if len(sim.Warnings) > 0 {
+			message = fmt.Sprintf("Trade simulated with %d warnings", len(sim.Warnings))
+		}
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   message,
+			Data:      sim,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package api
+
+import (
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/storage"
+	"crypto-trading-api/internal/validation"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// templateNamePattern restricts template names to URL/Firebase-safe slugs
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// SaveTradingViewTemplateHandler - Create or update a TradingView alert template
+// @Summary      Save TradingView template
+// @Description  Create or replace a named template that maps TradingView alerts onto trades. Alerts sent to POST /api/trade reference it with template=<name> and only carry the per-signal fields (ticker, action, price); size, leverage, order/margin type and SL/TP distances come from the template.
+// @Tags         Trading
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        name      path      string                             true  "Template name (lowercase letters, digits, - and _)"
+// @Param        template  body      models.TradingViewTemplateRequest  true  "Template settings"
+// @Success      200       {object}  models.TradeResponse{data=models.TradingViewTemplate}  "Template saved"
+// @Failure      400       {object}  models.TradeResponse  "Invalid request"
+// @Failure      401       {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      403       {object}  models.TradeResponse  "Template belongs to another user"
+// @Failure      500       {object}  models.TradeResponse  "Failed to save template"
+// @Router       /api/tradingview/templates/{name} [put]
+func SaveTradingViewTemplateHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := strings.ToLower(c.Param("name"))
+		if !templateNamePattern.MatchString(name) {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid template name",
+				Error:     "name must be 1-64 lowercase letters, digits, '-' or '_'",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		var req models.TradingViewTemplateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !requireOwner(c, req.UserID) {
+			return
+		}
+
+		if err := validateTradingViewTemplate(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid template",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		existing, err := fb.GetTradingViewTemplate(c.Request.Context(), name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get template",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		now := time.Now().Unix()
+		tpl := &models.TradingViewTemplate{
+			Name:              name,
+			UserID:            req.UserID,
+			Leverage:          req.Leverage,
+			Size:              req.Size,
+			OrderType:         req.OrderType,
+			MarginType:        req.MarginType,
+			StopLossPercent:   req.StopLossPercent,
+			TakeProfitPercent: req.TakeProfitPercent,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+		if existing != nil {
+			if existing.UserID != req.UserID {
+				c.JSON(http.StatusForbidden, models.TradeResponse{
+					Success:   false,
+					Message:   "Template belongs to another user",
+					Error:     fmt.Sprintf("template %q is already in use", name),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+			tpl.CreatedAt = existing.CreatedAt
+		}
+
+		if err := fb.SaveTradingViewTemplate(c.Request.Context(), tpl); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to save template",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Template saved successfully",
+			Data:      tpl,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// ListTradingViewTemplatesHandler - List a user's TradingView alert templates
+// @Summary      List TradingView templates
+// @Description  List the TradingView alert templates owned by a user
+// @Tags         Trading
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  query     string  true  "User ID"
+// @Success      200     {object}  models.TradeResponse{data=[]models.TradingViewTemplate}  "Templates retrieved"
+// @Failure      400     {object}  models.TradeResponse  "Missing userId parameter"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get templates"
+// @Router       /api/tradingview/templates [get]
+func ListTradingViewTemplatesHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Query("userId")
+		if !claimOwnership(c, &userID) {
+			return
+		}
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "userId parameter is required",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		all, err := fb.GetTradingViewTemplates(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get templates",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		templates := []*models.TradingViewTemplate{}
+		for _, tpl := range all {
+			if tpl.UserID == userID {
+				templates = append(templates, tpl)
+			}
+		}
+		sort.Slice(templates, func(i, j int) bool {
+			return templates[i].Name < templates[j].Name
+		})
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Templates retrieved successfully",
+			Data:      templates,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// DeleteTradingViewTemplateHandler - Remove a TradingView alert template
+// @Summary      Delete TradingView template
+// @Description  Remove a TradingView alert template. Alerts still referencing it are rejected with 404.
+// @Tags         Trading
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        name  path      string  true  "Template name"
+// @Success      200   {object}  models.TradeResponse  "Template deleted"
+// @Failure      401   {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      404   {object}  models.TradeResponse  "Template not found"
+// @Failure      500   {object}  models.TradeResponse  "Failed to delete template"
+// @Router       /api/tradingview/templates/{name} [delete]
+func DeleteTradingViewTemplateHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := strings.ToLower(c.Param("name"))
+
+		tpl, err := fb.GetTradingViewTemplate(c.Request.Context(), name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get template",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		if tpl == nil {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "Template not found",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !requireOwner(c, tpl.UserID) {
+			return
+		}
+
+		if err := fb.DeleteTradingViewTemplate(c.Request.Context(), name); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to delete template",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Template deleted successfully",
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// Validate template order/margin types
+func validateTradingViewTemplate(req *models.TradingViewTemplateRequest) error {
+	req.OrderType = strings.ToUpper(req.OrderType)
+	req.MarginType = strings.ToUpper(req.MarginType)
+
+	if req.OrderType != "" && req.OrderType != "MARKET" && req.OrderType != "LIMIT" {
+		return fmt.Errorf("orderType must be MARKET or LIMIT")
+	}
+	if req.MarginType != "" && req.MarginType != "ISOLATED" && req.MarginType != "CROSSED" {
+		return fmt.Errorf("marginType must be ISOLATED or CROSSED")
+	}
+
+	return nil
+}
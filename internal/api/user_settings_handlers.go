@@ -0,0 +1,234 @@
+package api
+
+import (
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/notifications"
+	"crypto-trading-api/internal/storage"
+	"crypto-trading-api/internal/validation"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mutableEvents are the notification types that belong to a user's trades,
+// and so can be muted per user
+var mutableEvents = []string{notifications.EventTradeOpened, notifications.EventTradeClosed, notifications.EventPerformanceAlert}
+
+// SaveUserSettingsHandler - Create or replace a user's settings
+// @Summary      Save user settings
+// @Description  Store a user's trade defaults, notification preferences and risk limits. defaultLeverage and defaultMarginType fill trades (webhooks, TradingView alerts) that set neither themselves nor through a preset. Trades above riskLimits.maxLeverage or riskLimits.maxPositionSize (USDT) are rejected with 403. mutedEvents turns off TRADE_OPENED/TRADE_CLOSED/PERFORMANCE_ALERT notifications for the user. alertThresholds (drawdown and daily loss in USDT, losing streak in trades) override the server's performance alert thresholds; 0 keeps the server default.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId    path      string                      true  "User ID"
+// @Param        settings  body      models.UserSettingsRequest  true  "Settings (replaces all stored settings)"
+// @Success      200       {object}  models.TradeResponse{data=models.UserSettings}  "Settings saved"
+// @Failure      400       {object}  models.TradeResponse  "Invalid settings"
+// @Failure      401       {object}  models.TradeResponse  "Unauthorized"
+// @Failure      500       {object}  models.TradeResponse  "Failed to save settings"
+// @Router       /api/users/{userId}/settings [put]
+func SaveUserSettingsHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Param("userId")
+		if !requireOwner(c, userID) {
+			return
+		}
+
+		var req models.UserSettingsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if err := validateUserSettingsRequest(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid settings",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		existing, err := fb.GetUserSettings(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get settings",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		now := time.Now().Unix()
+		settings := &models.UserSettings{
+			UserID:            userID,
+			DefaultLeverage:   req.DefaultLeverage,
+			DefaultMarginType: req.DefaultMarginType,
+			DefaultStopLoss:   req.DefaultStopLoss,
+			DefaultTakeProfit: req.DefaultTakeProfit,
+			Timezone:          req.Timezone,
+			MutedEvents:       req.MutedEvents,
+			RiskLimits:        req.RiskLimits,
+			AlertThresholds:   req.AlertThresholds,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+		if existing != nil {
+			settings.CreatedAt = existing.CreatedAt
+		}
+
+		if err := fb.SaveUserSettings(c.Request.Context(), settings); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to save settings",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Settings saved",
+			Data:      settings,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// GetUserSettingsHandler - Get a user's settings
+// @Summary      Get user settings
+// @Description  Get a user's trade defaults, notification preferences and risk limits. Users without stored settings get empty settings (no defaults, no limits).
+// @Tags         Users
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  path      string  true  "User ID"
+// @Success      200     {object}  models.TradeResponse{data=models.UserSettings}  "User settings"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get settings"
+// @Router       /api/users/{userId}/settings [get]
+func GetUserSettingsHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Param("userId")
+		if !requireOwner(c, userID) {
+			return
+		}
+
+		settings, err := fb.GetUserSettings(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get settings",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		if settings == nil {
+			settings = &models.UserSettings{UserID: userID}
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Settings retrieved",
+			Data:      settings,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// DeleteUserSettingsHandler - Remove a user's settings
+// @Summary      Delete user settings
+// @Description  Remove a user's settings. Their trades fall back to the server defaults, without per-user limits.
+// @Tags         Users
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  path      string  true  "User ID"
+// @Success      200     {object}  models.TradeResponse  "Settings removed"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized"
+// @Failure      500     {object}  models.TradeResponse  "Failed to remove settings"
+// @Router       /api/users/{userId}/settings [delete]
+func DeleteUserSettingsHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Param("userId")
+		if !requireOwner(c, userID) {
+			return
+		}
+
+		if err := fb.DeleteUserSettings(c.Request.Context(), userID); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to remove settings",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Settings removed",
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// validateUserSettingsRequest normalizes and checks a settings update
+func validateUserSettingsRequest(req *models.UserSettingsRequest) error {
+	req.DefaultMarginType = strings.ToUpper(req.DefaultMarginType)
+	if req.DefaultMarginType != "" && req.DefaultMarginType != "ISOLATED" && req.DefaultMarginType != "CROSSED" {
+		return fmt.Errorf("defaultMarginType must be ISOLATED or CROSSED")
+	}
+
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", req.Timezone)
+		}
+	}
+
+	for i, event := range req.MutedEvents {
+		event = strings.ToUpper(strings.TrimSpace(event))
+		known := false
+		for _, mutable := range mutableEvents {
+			if event == mutable {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("mutedEvents may only contain %s", strings.Join(mutableEvents, ", "))
+		}
+		req.MutedEvents[i] = event
+	}
+
+	limits := req.RiskLimits
+	if limits.MaxLeverage < 0 || limits.MaxLeverage > 125 {
+		return fmt.Errorf("riskLimits.maxLeverage must be between 1 and 125 (0 for no limit)")
+	}
+	if limits.MaxPositionSize < 0 {
+		return fmt.Errorf("riskLimits.maxPositionSize cannot be negative")
+	}
+	if limits.MaxLeverage > 0 && req.DefaultLeverage > limits.MaxLeverage {
+		return fmt.Errorf("defaultLeverage exceeds riskLimits.maxLeverage")
+	}
+
+	thresholds := req.AlertThresholds
+	if thresholds.MaxDrawdown < 0 || thresholds.LosingStreak < 0 || thresholds.DailyLoss < 0 {
+		return fmt.Errorf("alertThresholds cannot be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/userstats"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserSummaryHandler - Get a user's trading summary
+// @Summary      Get user summary
+// @Description  Get a user's all-time trading summary over their closed trades: counts, win rate, PnL, volume, fees, best/worst trade and trades per symbol. It is read from totals updated as each trade closes (users/{userId}/stats) and cached in memory for SUMMARY_CACHE_TTL, so its cost does not grow with the history. The first request for a user counts their trades once.
+// @Tags         Users
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  path      string  true  "User ID"
+// @Success      200     {object}  models.TradeResponse{data=models.UserSummary}  "User summary"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403     {object}  models.TradeResponse  "Another user's summary"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get summary"
+// @Router       /api/users/{userId}/summary [get]
+func UserSummaryHandler(summaries *userstats.Aggregator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Param("userId")
+		if !requireOwner(c, userID) {
+			return
+		}
+
+		summary, err := summaries.Summary(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get summary",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Summary retrieved",
+			Data:      summary,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// RefreshUserSummaryHandler - Recount a user's trading summary
+// @Summary      Refresh user summary
+// @Description  Recount a user's totals from their closed trades and return the new summary, e.g. after trades were edited or deleted by hand. Archived trades stay counted.
+// @Tags         Users
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  path      string  true  "User ID"
+// @Success      200     {object}  models.TradeResponse{data=models.UserSummary}  "User summary recounted"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized"
+// @Failure      403     {object}  models.TradeResponse  "Another user's summary"
+// @Failure      500     {object}  models.TradeResponse  "Failed to refresh summary"
+// @Router       /api/users/{userId}/summary/refresh [post]
+func RefreshUserSummaryHandler(summaries *userstats.Aggregator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Param("userId")
+		if !requireOwner(c, userID) {
+			return
+		}
+
+		summary, err := summaries.Refresh(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to refresh summary",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Summary recounted",
+			Data:      summary,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
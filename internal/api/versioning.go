@@ -0,0 +1,99 @@
+package api
+
+import (
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersion is the version of the routes and response envelope served
+// under /api/v1
+const APIVersion = "v1"
+
+const (
+	legacyAPIPrefix    = "/api"
+	versionedAPIPrefix = legacyAPIPrefix + "/" + APIVersion
+
+	apiVersionKey = "apiVersion" // Request context key: "v1" or "legacy"
+)
+
+// LegacyAPIConfig schedules the retirement of the unversioned /api paths
+type LegacyAPIConfig struct {
+	DeprecatedAt time.Time // Announced in the Deprecation header (zero: "true")
+	Sunset       time.Time // Announced in the Sunset header; afterwards the paths answer 410
+}
+
+// APIVersionMiddleware tags responses with the API version. Requests on the
+// unversioned /api paths also get Deprecation, Sunset and successor Link
+// headers, and are refused with 410 once the sunset has passed.
+func APIVersionMiddleware(legacy LegacyAPIConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("API-Version", APIVersion)
+		if isVersionedRoute(c.FullPath()) {
+			c.Set(apiVersionKey, APIVersion)
+			c.Next()
+			return
+		}
+
+		c.Set(apiVersionKey, "legacy")
+		successor := versionedAPIPrefix + strings.TrimPrefix(c.Request.URL.Path, legacyAPIPrefix)
+		c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		if legacy.DeprecatedAt.IsZero() {
+			c.Header("Deprecation", "true")
+		} else {
+			c.Header("Deprecation", "@"+strconv.FormatInt(legacy.DeprecatedAt.Unix(), 10))
+		}
+
+		if !legacy.Sunset.IsZero() {
+			c.Header("Sunset", legacy.Sunset.UTC().Format(http.TimeFormat))
+			if time.Now().After(legacy.Sunset) {
+				c.AbortWithStatusJSON(http.StatusGone, models.TradeResponse{
+					Success:   false,
+					Message:   "Unversioned API paths have been retired",
+					Error:     fmt.Sprintf("use %s %s", c.Request.Method, successor),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// mountLegacyAPI serves every /api/v1 route at its unversioned /api path
+// through the same middleware, so existing clients and TradingView alerts
+// keep working while they migrate
+func mountLegacyAPI(router *gin.Engine, versioned *gin.RouterGroup) {
+	// The group's own middleware; Handle adds the global ones again
+	middleware := versioned.Handlers[len(router.Handlers):]
+
+	for _, route := range router.Routes() {
+		rest, ok := strings.CutPrefix(route.Path, versionedAPIPrefix+"/")
+		if !ok {
+			continue
+		}
+		handlers := append(append([]gin.HandlerFunc{}, middleware...), route.HandlerFunc)
+		router.Handle(route.Method, legacyAPIPrefix+"/"+rest, handlers...)
+	}
+}
+
+// isVersionedRoute reports whether a route pattern is under /api/v1
+func isVersionedRoute(route string) bool {
+	return route == versionedAPIPrefix || strings.HasPrefix(route, versionedAPIPrefix+"/")
+}
+
+// routePath returns the matched route without its version (/api/trade for
+// both /api/trade and /api/v1/trade), for policies keyed by route: scopes,
+// rate limits, signature and body key checks
+func routePath(c *gin.Context) string {
+	route := c.FullPath()
+	if isVersionedRoute(route) {
+		return legacyAPIPrefix + strings.TrimPrefix(route, versionedAPIPrefix)
+	}
+	return route
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"crypto-trading-api/internal/analytics"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VolatilityHaltsHandler - List symbols halted by the volatility circuit breaker
+// @Summary      Volatility halts
+// @Description  Symbols whose new entries the volatility circuit breaker pauses: the price moved more than VOLATILITY_MAX_CHANGE within VOLATILITY_WINDOW, or the mark price left the index by more than VOLATILITY_MAX_DIVERGENCE. calmSince is set once conditions are back within limits; entries resume VOLATILITY_COOLDOWN later.
+// @Tags         Risk Management
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.TradeResponse{data=[]models.VolatilityHalt}  "Halted symbols"
+// @Failure      401  {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      501  {object}  models.TradeResponse  "Volatility circuit breaker not enabled"
+// @Router       /api/risk/halts [get]
+func VolatilityHaltsHandler(guard *binance.VolatilityGuard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if guard == nil {
+			c.JSON(http.StatusNotImplemented, models.TradeResponse{
+				Success:   false,
+				Message:   "Volatility circuit breaker is not enabled",
+				Error:     "set VOLATILITY_GUARD_ENABLED=true to halt symbols on extreme moves",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Volatility halts retrieved successfully",
+			Data:      guard.Halts(),
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// screenerFetchers bounds the kline requests the volatility screener makes
+// at once
+const screenerFetchers = 8
+
+// VolatilityScreenerHandler - Rank symbols by realized volatility, ATR% and 24h range
+// @Summary      Volatility screener
+// @Description  Rank symbols by annualized realized volatility, ATR as a percent of price (a typical candle's move, to compare with SL/TP distances) or 24h high-low range. Candles come from memory when streamed with enough history, otherwise from klines fetched over REST and reused for a minute. Without symbols, the top perpetuals by 24h quote volume are screened. The 24h range covers at most the lookback.
+// @Tags         Market Data
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        symbols    query     string  false  "Comma-separated symbols (default: the top perpetuals by 24h volume)" example("BTCUSDT,ETHUSDT")
+// @Param        top        query     int     false  "Perpetuals screened without symbols (default: 30, max: 100)"
+// @Param        interval   query     string  false  "Kline interval, 1m to 1d (default: 1h)" example("1h")
+// @Param        lookback   query     int     false  "Klines per symbol (default: 168, max: 1500)" example(168)
+// @Param        atrPeriod  query     int     false  "ATR period in klines (default: 14)" example(14)
+// @Param        sort       query     string  false  "Rank by volatility, atr or range (default: volatility)"
+// @Param        order      query     string  false  "desc (most volatile first, default) or asc"
+// @Param        minAtr     query     number  false  "Minimum ATR percent, e.g. 0.5 (default: 0)"
+// @Param        maxAtr     query     number  false  "Maximum ATR percent (default: none)"
+// @Param        limit      query     int     false  "Maximum results (default: 0 = all)"
+// @Success      200        {object}  models.TradeResponse{data=analytics.VolatilityScreen}  "Symbols ranked"
+// @Failure      400        {object}  models.TradeResponse  "Invalid parameters"
+// @Failure      401        {object}  models.TradeResponse  "Unauthorized"
+// @Failure      500        {object}  models.TradeResponse  "Failed to screen volatility"
+// @Router       /api/market/volatility [get]
+func VolatilityScreenerHandler(bn *binance.Client, streams *binance.WebSocketManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		screen := &analytics.VolatilityScreen{
+			Interval: c.DefaultQuery("interval", "1h"),
+			SortBy:   c.DefaultQuery("sort", analytics.VolatilitySortRealized),
+			Order:    strings.ToLower(c.DefaultQuery("order", "desc")),
+			Symbols:  []*analytics.VolatilityStats{},
+			Skipped:  []string{},
+		}
+		var errLookback, errATR error
+		top, errTop := strconv.Atoi(c.DefaultQuery("top", "30"))
+		screen.Lookback, errLookback = strconv.Atoi(c.DefaultQuery("lookback", "168"))
+		screen.ATRPeriod, errATR = strconv.Atoi(c.DefaultQuery("atrPeriod", "14"))
+		minATR, errMin := strconv.ParseFloat(c.DefaultQuery("minAtr", "0"), 64)
+		maxATR, errMax := strconv.ParseFloat(c.DefaultQuery("maxAtr", "0"), 64)
+		limit, errLimit := strconv.Atoi(c.DefaultQuery("limit", "0"))
+
+		symbols := []string{}
+		for _, symbol := range strings.Split(c.Query("symbols"), ",") {
+			if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+				symbols = append(symbols, symbol)
+			}
+		}
+
+		interval, validInterval := binance.CandleInterval(screen.Interval)
+		validSort := screen.SortBy == analytics.VolatilitySortRealized || screen.SortBy == analytics.VolatilitySortATR || screen.SortBy == analytics.VolatilitySortRange
+		validOrder := screen.Order == "asc" || screen.Order == "desc"
+		if errTop != nil || errLookback != nil || errATR != nil || errMin != nil || errMax != nil || errLimit != nil ||
+			!validInterval || !validSort || !validOrder || top < 1 || top > 100 || len(symbols) > 100 ||
+			screen.ATRPeriod < 1 || screen.Lookback <= screen.ATRPeriod || screen.Lookback > 1500 ||
+			minATR < 0 || maxATR < 0 || (maxATR > 0 && maxATR < minATR) || limit < 0 {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid parameters",
+				Error:     "interval must be 1m to 1d, sort volatility, atr or range, order asc or desc, top and symbols at most 100, lookback above atrPeriod and at most 1500, and minAtr, maxAtr and limit non-negative numbers",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		var volumes map[string]float64
+		if len(symbols) == 0 {
+			var err error
+			symbols, volumes, err = bn.TopSymbolsByVolume(c.Request.Context(), top)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, models.TradeResponse{
+					Success:   false,
+					Message:   "Failed to screen volatility",
+					Error:     err.Error(),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+		}
+
+		measured := make([]*analytics.VolatilityStats, len(symbols))
+		fetchers := make(chan struct{}, screenerFetchers)
+		var wg sync.WaitGroup
+		for i, symbol := range symbols {
+			if candles, ok := streams.Candles(symbol, screen.Interval, screen.Lookback); ok && len(candles) >= screen.Lookback {
+				if stats := analytics.MeasureVolatility(symbol, candles, interval, screen.ATRPeriod); stats != nil {
+					stats.Source = "stream"
+					measured[i] = stats
+				}
+				continue
+			}
+
+			wg.Add(1)
+			go func(i int, symbol string) {
+				defer wg.Done()
+				fetchers <- struct{}{}
+				defer func() { <-fetchers }()
+
+				candles, err := bn.GetCachedCandles(c.Request.Context(), symbol, screen.Interval, screen.Lookback)
+				if err != nil {
+					logging.Warn().Err(err).Str(logging.FieldSymbol, symbol).Msgf("Volatility screener: failed to get %s klines", symbol)
+					return
+				}
+				if stats := analytics.MeasureVolatility(symbol, candles, interval, screen.ATRPeriod); stats != nil {
+					stats.Source = "rest"
+					measured[i] = stats
+				}
+			}(i, symbol)
+		}
+		wg.Wait()
+
+		for i, stats := range measured {
+			if stats == nil {
+				screen.Skipped = append(screen.Skipped, symbols[i])
+				continue
+			}
+			if stats.ATRPercent < minATR || (maxATR > 0 && stats.ATRPercent > maxATR) {
+				continue
+			}
+			stats.QuoteVolume = volumes[stats.Symbol]
+			screen.Symbols = append(screen.Symbols, stats)
+		}
+		sort.Strings(screen.Skipped)
+
+		analytics.RankVolatility(screen.Symbols, screen.SortBy, screen.Order == "asc")
+		if limit > 0 && len(screen.Symbols) > limit {
+			screen.Symbols = screen.Symbols[:limit]
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   fmt.Sprintf("%d symbols ranked", len(screen.Symbols)),
+			Data:      screen,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/storage"
+	"crypto-trading-api/internal/validation"
+	"crypto-trading-api/internal/webhooks"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RegisterWebhookHandler - Register a callback URL for trade lifecycle events
+// @Summary      Register webhook
+// @Description  Register a URL that receives signed JSON payloads when the user's trades change state (FILLED, SL_HIT, TP_HIT, CLOSED). Each request carries X-Webhook-Timestamp and X-Webhook-Signature (sha256=HMAC-SHA256 of "timestamp.body" keyed by the secret). Failed deliveries are retried with exponential backoff. The secret is only returned in this response.
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        webhook  body      models.WebhookRequest  true  "Webhook registration"
+// @Success      200      {object}  models.TradeResponse{data=models.Webhook}  "Webhook registered"
+// @Failure      400      {object}  models.TradeResponse  "Invalid request"
+// @Failure      401      {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      500      {object}  models.TradeResponse  "Failed to save webhook"
+// @Router       /api/webhooks [post]
+func RegisterWebhookHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.WebhookRequest
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = validation.FromBinding(err)
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid request",
+				Error:     err.Error(),
+				Details:   validation.Details(err),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !requireOwner(c, req.UserID) {
+			return
+		}
+
+		if err := validateWebhookRequest(c.Request.Context(), &req); err != nil {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "Invalid webhook",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		secret := req.Secret
+		if secret == "" {
+			generated, err := generateWebhookSecret()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, models.TradeResponse{
+					Success:   false,
+					Message:   "Failed to generate webhook secret",
+					Error:     err.Error(),
+					Timestamp: time.Now().Unix(),
+				})
+				return
+			}
+			secret = generated
+		}
+
+		hook := &models.Webhook{
+			ID:        uuid.New().String(),
+			UserID:    req.UserID,
+			URL:       req.URL,
+			Secret:    secret,
+			Events:    req.Events,
+			CreatedAt: time.Now().Unix(),
+		}
+
+		if err := fb.SaveWebhook(c.Request.Context(), hook); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to save webhook",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		withholdResponse(c)
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Webhook registered successfully",
+			Data:      hook,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// ListWebhooksHandler - List a user's webhooks
+// @Summary      List webhooks
+// @Description  List the webhooks registered for a user (secrets are not returned)
+// @Tags         Webhooks
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  query     string  true  "User ID"
+// @Success      200     {object}  models.TradeResponse{data=[]models.Webhook}  "Webhooks retrieved"
+// @Failure      400     {object}  models.TradeResponse  "Missing userId parameter"
+// @Failure      401     {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      500     {object}  models.TradeResponse  "Failed to get webhooks"
+// @Router       /api/webhooks [get]
+func ListWebhooksHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Query("userId")
+		if !claimOwnership(c, &userID) {
+			return
+		}
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, models.TradeResponse{
+				Success:   false,
+				Message:   "userId parameter is required",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		hooks, err := fb.GetWebhooks(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to get webhooks",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		userHooks := []*models.Webhook{}
+		for _, hook := range hooks {
+			if hook.UserID == userID {
+				hook.Secret = ""
+				userHooks = append(userHooks, hook)
+			}
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Webhooks retrieved successfully",
+			Data:      userHooks,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// DeleteWebhookHandler - Remove a webhook
+// @Summary      Delete webhook
+// @Description  Remove a webhook registration
+// @Tags         Webhooks
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        webhookId  path      string  true  "Webhook ID"
+// @Success      200        {object}  models.TradeResponse  "Webhook deleted"
+// @Failure      401        {object}  models.TradeResponse  "Unauthorized - Invalid API key"
+// @Failure      404        {object}  models.TradeResponse  "Webhook not found"
+// @Failure      500        {object}  models.TradeResponse  "Failed to delete webhook"
+// @Router       /api/webhooks/{webhookId} [delete]
+func DeleteWebhookHandler(fb storage.TradeStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hookID := c.Param("webhookId")
+
+		hook, err := fb.GetWebhook(c.Request.Context(), hookID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.TradeResponse{
+				Success:   false,
+				Message:   "Webhook not found",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if !requireOwner(c, hook.UserID) {
+			return
+		}
+
+		if err := fb.DeleteWebhook(c.Request.Context(), hookID); err != nil {
+			c.JSON(http.StatusInternalServerError, models.TradeResponse{
+				Success:   false,
+				Message:   "Failed to delete webhook",
+				Error:     err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.TradeResponse{
+			Success:   true,
+			Message:   "Webhook deleted successfully",
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// Validate webhook URL (public addresses only) and event names
+func validateWebhookRequest(ctx context.Context, req *models.WebhookRequest) error {
+	if err := webhooks.CheckURL(ctx, req.URL); err != nil {
+		return err
+	}
+
+	for _, event := range req.Events {
+		valid := false
+		for _, known := range webhooks.Events {
+			if event == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown event %q (allowed: %v)", event, webhooks.Events)
+		}
+	}
+
+	return nil
+}
+
+// generateWebhookSecret returns a random signing secret
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %v", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
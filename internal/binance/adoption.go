@@ -0,0 +1,212 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/tradehistory"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/google/uuid"
+)
+
+// Adopt creates trades for the primary account's positions no open trade or
+// funding arbitrage pair accounts for (all of them, or those of req.Symbols),
+// so SL/TP fills, the margin guard, excursions and reports cover them. SL/TP
+// orders already on the exchange are linked; missing ones are placed from
+// the owner's defaultStopLoss/defaultTakeProfit when protection is on. It
+// fails only if the positions, orders or trades cannot be read.
+func (r *OrderReconciler) Adopt(ctx context.Context, req models.AdoptRequest) ([]models.AdoptedPosition, error) {
+	ctx = tradehistory.WithSource(ctx, tradehistory.SourceAdoption)
+	r.running.Lock()
+	defer r.running.Unlock()
+
+	userID := req.UserID
+	if userID == "" {
+		userID = r.config.AdoptUserID
+	}
+	protect := r.config.Protect
+	if req.Protect != nil {
+		protect = *req.Protect
+	}
+	wanted := make(map[string]bool, len(req.Symbols))
+	for _, symbol := range req.Symbols {
+		wanted[strings.ToUpper(symbol)] = true
+	}
+
+	positions, err := r.client.GetOpenPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %v", err)
+	}
+	orders, err := r.client.GetOpenOrders(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open orders: %v", err)
+	}
+	trades, err := r.store.GetAllTrades(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trades: %v", err)
+	}
+
+	managed := make(map[string]bool)
+	for _, trade := range trades {
+		if trade.Account == "" && (trade.Status == "ACTIVE" || trade.Status == "FILLED") {
+			managed[trade.Symbol] = true
+		}
+	}
+	if err := r.addArbSymbols(ctx, managed); err != nil {
+		return nil, err
+	}
+
+	adopted := []models.AdoptedPosition{}
+	for _, pos := range positions {
+		if managed[pos.Symbol] || (len(wanted) > 0 && !wanted[pos.Symbol]) {
+			continue
+		}
+		adopted = append(adopted, r.adopt(ctx, pos, orders, userID, protect))
+	}
+	return adopted, nil
+}
+
+// addArbSymbols marks the perp legs of open funding arbitrage pairs managed
+func (r *OrderReconciler) addArbSymbols(ctx context.Context, managed map[string]bool) error {
+	groups, err := r.store.GetFundingArbGroups(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get funding arbitrage pairs: %v", err)
+	}
+	for _, group := range groups {
+		if group.Status == models.ArbStatusOpen {
+			managed[group.Symbol] = true
+		}
+	}
+	return nil
+}
+
+// adopt saves a FILLED trade for an unmanaged position, linking its SL/TP
+// orders on the exchange and placing the missing ones when protect is set
+func (r *OrderReconciler) adopt(ctx context.Context, pos *PositionInfo, orders []*futures.Order, userID string, protect bool) models.AdoptedPosition {
+	ctx = tradehistory.WithSource(ctx, tradehistory.SourceAdoption)
+	adopted := models.AdoptedPosition{
+		Symbol:      pos.Symbol,
+		PositionAmt: pos.PositionAmt,
+		EntryPrice:  pos.EntryPrice,
+	}
+
+	side, closeSide := "BUY", futures.SideTypeSell
+	if pos.PositionAmt < 0 {
+		side, closeSide = "SELL", futures.SideTypeBuy
+	}
+	leverage := pos.Leverage
+	if leverage <= 0 {
+		leverage = 1
+	}
+	marginType := "ISOLATED"
+	if strings.EqualFold(pos.MarginType, "cross") || strings.EqualFold(pos.MarginType, "crossed") {
+		marginType = "CROSSED"
+	}
+
+	now := time.Now().Unix()
+	trade := &models.Trade{
+		ID:            uuid.New().String(),
+		UserID:        userID,
+		Symbol:        pos.Symbol,
+		Side:          side,
+		OrderType:     "MARKET",
+		MarginType:    marginType,
+		EntryPrice:    pos.EntryPrice,
+		ExecutedPrice: pos.EntryPrice,
+		Leverage:      leverage,
+		Size:          math.Round(math.Abs(pos.PositionAmt)*pos.EntryPrice/float64(leverage)*1e8) / 1e8,
+		Status:        "FILLED",
+		CreatedAt:     now,
+		ExecutedAt:    now,
+		Adopted:       true,
+	}
+	ctx = logging.WithTrade(ctx, trade.ID, trade.Symbol)
+
+	// Protective orders placed with the position (Binance app, another bot)
+	for _, order := range orders {
+		if order.Symbol != pos.Symbol || order.Side != closeSide || !isProtectiveOrder(order) {
+			continue
+		}
+		stopPrice, _ := strconv.ParseFloat(order.StopPrice, 64)
+		switch futures.OrderType(order.OrigType) {
+		case futures.OrderTypeStopMarket, futures.OrderTypeStop:
+			trade.SLOrderID, trade.StopLoss = order.OrderID, stopPrice
+			adopted.Linked = true
+		case futures.OrderTypeTakeProfitMarket, futures.OrderTypeTakeProfit:
+			trade.TPOrderID, trade.TakeProfit = order.OrderID, stopPrice
+			adopted.Linked = true
+		}
+	}
+
+	var protectErr error
+	if protect && (trade.SLOrderID == 0 || trade.TPOrderID == 0) {
+		protectErr = r.protect(ctx, trade)
+	}
+
+	if err := r.store.SaveTrade(ctx, trade); err != nil {
+		adopted.Error = fmt.Sprintf("failed to save trade: %v", err)
+		logging.Ctx(ctx).Warn().Err(err).Msgf("Order reconciler: failed to adopt %s position", pos.Symbol)
+		return adopted
+	}
+
+	adopted.TradeID = trade.ID
+	adopted.StopLoss, adopted.TakeProfit = trade.StopLoss, trade.TakeProfit
+	adopted.SLOrderID, adopted.TPOrderID = trade.SLOrderID, trade.TPOrderID
+	if protectErr != nil {
+		adopted.Error = protectErr.Error()
+	}
+	logging.Ctx(ctx).Info().Msgf("Order reconciler: adopted %s position %g as trade %s (SL %d, TP %d)",
+		pos.Symbol, pos.PositionAmt, trade.ID, trade.SLOrderID, trade.TPOrderID)
+	return adopted
+}
+
+// protect places the SL/TP an adopted trade lacks, at the owner's default
+// distances from the entry price
+func (r *OrderReconciler) protect(ctx context.Context, trade *models.Trade) error {
+	settings, err := r.store.GetUserSettings(ctx, trade.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get settings of %s: %v", trade.UserID, err)
+	}
+	if settings == nil || (settings.DefaultStopLoss <= 0 && settings.DefaultTakeProfit <= 0) {
+		return fmt.Errorf("no defaultStopLoss or defaultTakeProfit in the settings of %s", trade.UserID)
+	}
+
+	symbolInfo, err := r.client.getSymbolInfo(ctx, trade.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get symbol info: %v", err)
+	}
+
+	direction := 1.0
+	if trade.Side == "SELL" {
+		direction = -1
+	}
+
+	var failures []string
+	if trade.SLOrderID == 0 && settings.DefaultStopLoss > 0 {
+		stopLoss := trade.EntryPrice * (1 - direction*settings.DefaultStopLoss/100)
+		if orderID, err := r.client.placeStopLoss(ctx, trade.Symbol, trade.Side, "", stopLoss, TradeTrigger(trade), symbolInfo.TickSize, symbolInfo.PricePrecision); err != nil {
+			failures = append(failures, err.Error())
+		} else {
+			trade.SLOrderID, trade.StopLoss = orderID, stopLoss
+		}
+	}
+	if trade.TPOrderID == 0 && settings.DefaultTakeProfit > 0 {
+		takeProfit := trade.EntryPrice * (1 + direction*settings.DefaultTakeProfit/100)
+		if orderID, err := r.client.placeTakeProfit(ctx, trade.Symbol, trade.Side, "", takeProfit, TradeTrigger(trade), symbolInfo.TickSize, symbolInfo.PricePrecision); err != nil {
+			failures = append(failures, err.Error())
+		} else {
+			trade.TPOrderID, trade.TakeProfit = orderID, takeProfit
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
@@ -2,19 +2,17 @@ package binance
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
+	"crypto-trading-api/internal/models"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/adshao/go-binance/v2/futures"
+	"github.com/shopspring/decimal"
 )
 
 // AccountInfo represents Binance account information
@@ -73,8 +71,10 @@ type ClosePositionResult struct {
 }
 
 // GetServerTime - Get Binance server time
-func (b *Client) GetServerTime() (int64, error) {
-	serverTime, err := b.client.NewServerTimeService().Do(context.Background())
+func (b *Client) GetServerTime(ctx context.Context) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	serverTime, err := b.client.NewServerTimeService().Do(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -82,8 +82,9 @@ func (b *Client) GetServerTime() (int64, error) {
 }
 
 // GetAccountInfo - Get account information
-func (b *Client) GetAccountInfo() (*AccountInfo, error) {
-	ctx := context.Background()
+func (b *Client) GetAccountInfo(ctx context.Context) (*AccountInfo, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 	account, err := b.client.NewGetAccountService().Do(ctx)
 	if err != nil {
 		return nil, err
@@ -108,8 +109,9 @@ func (b *Client) GetAccountInfo() (*AccountInfo, error) {
 }
 
 // CalculateBalance - Calculate detailed balance information
-func (b *Client) CalculateBalance(account *AccountInfo) *BalanceInfo {
-	ctx := context.Background()
+func (b *Client) CalculateBalance(ctx context.Context, account *AccountInfo) *BalanceInfo {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 	
 	// Get all assets
 	accountData, err := b.client.NewGetAccountService().Do(ctx)
@@ -153,8 +155,9 @@ func (b *Client) CalculateBalance(account *AccountInfo) *BalanceInfo {
 }
 
 // GetOpenPositions - Get all open positions
-func (b *Client) GetOpenPositions() ([]*PositionInfo, error) {
-	ctx := context.Background()
+func (b *Client) GetOpenPositions(ctx context.Context) ([]*PositionInfo, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 	positions, err := b.client.NewGetPositionRiskService().Do(ctx)
 	if err != nil {
 		return nil, err
@@ -190,8 +193,9 @@ func (b *Client) GetOpenPositions() ([]*PositionInfo, error) {
 }
 
 // GetOpenOrders - Get all open orders (pending orders)
-func (b *Client) GetOpenOrders(symbol string) ([]*futures.Order, error) {
-	ctx := context.Background()
+func (b *Client) GetOpenOrders(ctx context.Context, symbol string) ([]*futures.Order, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 	service := b.client.NewListOpenOrdersService()
 	
 	if symbol != "" {
@@ -207,8 +211,9 @@ func (b *Client) GetOpenOrders(symbol string) ([]*futures.Order, error) {
 }
 
 // CancelOrder - Cancel a specific order
-func (b *Client) CancelOrder(symbol string, orderID int64) error {
-	ctx := context.Background()
+func (b *Client) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 	_, err := b.client.NewCancelOrderService().
 		Symbol(symbol).
 		OrderID(orderID).
@@ -218,8 +223,9 @@ func (b *Client) CancelOrder(symbol string, orderID int64) error {
 }
 
 // CancelAllOrders - Cancel all orders for a symbol
-func (b *Client) CancelAllOrders(symbol string) (int, error) {
-	ctx := context.Background()
+func (b *Client) CancelAllOrders(ctx context.Context, symbol string) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 	
 	err := b.client.NewCancelAllOpenOrdersService().
 		Symbol(symbol).
@@ -230,13 +236,14 @@ func (b *Client) CancelAllOrders(symbol string) (int, error) {
 	}
 
 	// Get count of cancelled orders (before cancellation)
-	orders, _ := b.GetOpenOrders(symbol)
+	orders, _ := b.GetOpenOrders(ctx, symbol)
 	return len(orders), nil
 }
 
 // GetActiveSymbols - Get list of symbols with open positions or orders
-func (b *Client) GetActiveSymbols() ([]string, error) {
-	ctx := context.Background()
+func (b *Client) GetActiveSymbols(ctx context.Context) ([]string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 	
 	positions, err := b.client.NewGetPositionRiskService().Do(ctx)
 	if err != nil {
@@ -261,8 +268,9 @@ func (b *Client) GetActiveSymbols() ([]string, error) {
 }
 
 // ClosePosition - Close an open position
-func (b *Client) ClosePosition(symbol string) (*ClosePositionResult, error) {
-	ctx := context.Background()
+func (b *Client) ClosePosition(ctx context.Context, symbol string) (*ClosePositionResult, error) {
+	ctx, cancel := withOrderTimeout(ctx)
+	defer cancel()
 
 	// Get current position
 	positions, err := b.client.NewGetPositionRiskService().Symbol(symbol).Do(ctx)
@@ -292,7 +300,7 @@ func (b *Client) ClosePosition(symbol string) (*ClosePositionResult, error) {
 		Symbol(symbol).
 		Side(closeSide).
 		Type(futures.OrderTypeMarket).
-		Quantity(fmt.Sprintf("%.3f", absFloat(posAmt))).
+		Quantity(strings.TrimPrefix(position.PositionAmt, "-")).
 		ReduceOnly(true).
 		Do(ctx)
 
@@ -319,8 +327,8 @@ func (b *Client) ClosePosition(symbol string) (*ClosePositionResult, error) {
 }
 
 // GetAccountPnL - Get current account total PnL
-func (b *Client) GetAccountPnL() (float64, error) {
-	account, err := b.GetAccountInfo()
+func (b *Client) GetAccountPnL(ctx context.Context) (float64, error) {
+	account, err := b.GetAccountInfo(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -336,8 +344,9 @@ func absFloat(x float64) float64 {
 }
 
 // GetTradeHistory - Get trade history for period
-func (b *Client) GetTradeHistory(symbol string, startTime, endTime int64) ([]*futures.AccountTrade, error) {
-	ctx := context.Background()
+func (b *Client) GetTradeHistory(ctx context.Context, symbol string, startTime, endTime int64) ([]*futures.AccountTrade, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 	
 	service := b.client.NewListAccountTradeService().
 		Symbol(symbol).
@@ -353,30 +362,130 @@ func (b *Client) GetTradeHistory(symbol string, startTime, endTime int64) ([]*fu
 }
 
 // GetIncomeHistory - Get income history (PnL history)
-func (b *Client) GetIncomeHistory(symbol string, startTime, endTime int64) (float64, error) {
-	ctx := context.Background()
-	
-	service := b.client.NewGetIncomeHistoryService().
-		StartTime(startTime * 1000). // Convert to milliseconds
-		EndTime(endTime * 1000).
-		IncomeType("REALIZED_PNL")
-
-	if symbol != "" {
-		service.Symbol(symbol)
-	}
+func (b *Client) GetIncomeHistory(ctx context.Context, symbol string, startTime, endTime int64) (float64, error) {
+	return b.GetIncomeTotal(ctx, "REALIZED_PNL", symbol, startTime, endTime)
+}
 
-	incomes, err := service.Do(ctx)
+// GetIncomeTotal - Sum income history of one type (REALIZED_PNL, COMMISSION, FUNDING_FEE, ...)
+func (b *Client) GetIncomeTotal(ctx context.Context, incomeType, symbol string, startTime, endTime int64) (float64, error) {
+	records, err := b.GetIncomeRecords(ctx, incomeType, symbol, startTime, endTime)
 	if err != nil {
 		return 0, err
 	}
 
-	totalPnL := 0.0
-	for _, income := range incomes {
-		pnl, _ := strconv.ParseFloat(income.Income, 64)
-		totalPnL += pnl
+	total := 0.0
+	for _, record := range records {
+		total += record.Income
 	}
 
-	return totalPnL, nil
+	return total, nil
+}
+
+// GetIncomeRecords - Get income history entries (startTime/endTime in seconds, empty type = all types), paging through the 1000-record limit
+func (b *Client) GetIncomeRecords(ctx context.Context, incomeType, symbol string, startTime, endTime int64) ([]*models.IncomeRecord, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	const pageLimit = 1000
+
+	records := []*models.IncomeRecord{}
+	from := startTime * 1000 // Convert to milliseconds
+	for {
+		service := b.client.NewGetIncomeHistoryService().
+			StartTime(from).
+			EndTime(endTime * 1000).
+			Limit(pageLimit)
+
+		if incomeType != "" {
+			service.IncomeType(incomeType)
+		}
+		if symbol != "" {
+			service.Symbol(symbol)
+		}
+
+		incomes, err := service.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, income := range incomes {
+			amount, _ := strconv.ParseFloat(income.Income, 64)
+			records = append(records, &models.IncomeRecord{
+				Symbol:     income.Symbol,
+				IncomeType: income.IncomeType,
+				Income:     amount,
+				Asset:      income.Asset,
+				Info:       income.Info,
+				Time:       income.Time,
+				TranID:     income.TranID,
+				TradeID:    income.TradeID,
+			})
+		}
+
+		// Pages are oldest first: continue after the last record returned
+		if len(incomes) < pageLimit {
+			break
+		}
+		from = incomes[len(incomes)-1].Time + 1
+	}
+
+	return records, nil
+}
+
+// GetIncomeRecordsRange - Get all income entries in a long time range (seconds), paging through the 1000-record limit
+func (b *Client) GetIncomeRecordsRange(ctx context.Context, incomeType string, startTime, endTime int64) ([]*models.IncomeRecord, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	const pageLimit = 1000
+	const window = int64(30 * 24 * 60 * 60 * 1000) // Query 30 days at a time
+
+	records := []*models.IncomeRecord{}
+	endMs := endTime * 1000
+
+	for windowStart := startTime * 1000; windowStart < endMs; windowStart += window {
+		windowEnd := windowStart + window - 1
+		if windowEnd > endMs {
+			windowEnd = endMs
+		}
+
+		from := windowStart
+		for {
+			service := b.client.NewGetIncomeHistoryService().
+				StartTime(from).
+				EndTime(windowEnd).
+				Limit(pageLimit)
+			if incomeType != "" {
+				service.IncomeType(incomeType)
+			}
+
+			incomes, err := service.Do(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get income history: %v", err)
+			}
+
+			for _, income := range incomes {
+				amount, _ := strconv.ParseFloat(income.Income, 64)
+				records = append(records, &models.IncomeRecord{
+					Symbol:     income.Symbol,
+					IncomeType: income.IncomeType,
+					Income:     amount,
+					Asset:      income.Asset,
+					Info:       income.Info,
+					Time:       income.Time,
+					TranID:     income.TranID,
+					TradeID:    income.TradeID,
+				})
+			}
+
+			if len(incomes) < pageLimit {
+				break
+			}
+			from = incomes[len(incomes)-1].Time + 1
+		}
+	}
+
+	return records, nil
 }
 
 // SymbolInfo represents trading rules for a symbol
@@ -404,11 +513,12 @@ type ExchangeInfoResponse struct {
 }
 
 // GetExchangeInfo - Get exchange trading rules and symbol information
-func (b *Client) GetExchangeInfo(symbol string) (*ExchangeInfoResponse, error) {
-	ctx := context.Background()
+func (b *Client) GetExchangeInfo(ctx context.Context, symbol string) (*ExchangeInfoResponse, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 
-	// Get exchange info from Binance
-	exchangeInfo, err := b.client.NewExchangeInfoService().Do(ctx)
+	// Get exchange info from Binance (or another instance's recent lookup)
+	exchangeInfo, err := b.exchangeInfo(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get exchange info: %v", err)
 	}
@@ -426,75 +536,80 @@ func (b *Client) GetExchangeInfo(symbol string) (*ExchangeInfoResponse, error) {
 			continue
 		}
 
-		// Extract filters
-		filters := make(map[string]map[string]interface{})
-		for _, filter := range s.Filters {
-			if filterType, ok := filter["filterType"].(string); ok {
-				filters[filterType] = filter
-			}
-		}
+		response.Symbols = append(response.Symbols, symbolInfoFrom(s))
+	}
 
-		// Build symbol info
-		symbolInfo := SymbolInfo{
-			Symbol:            s.Symbol,
-			Status:            string(s.Status),
-			BaseAsset:         s.BaseAsset,
-			QuoteAsset:        s.QuoteAsset,
-			PricePrecision:    s.PricePrecision,
-			QuantityPrecision: s.QuantityPrecision,
+	return response, nil
+}
+
+// symbolInfoFrom reads a symbol's precision and filters from exchange info
+func symbolInfoFrom(s futures.Symbol) SymbolInfo {
+	// Extract filters
+	filters := make(map[string]map[string]interface{})
+	for _, filter := range s.Filters {
+		if filterType, ok := filter["filterType"].(string); ok {
+			filters[filterType] = filter
 		}
+	}
+
+	// Build symbol info
+	symbolInfo := SymbolInfo{
+		Symbol:            s.Symbol,
+		Status:            string(s.Status),
+		BaseAsset:         s.BaseAsset,
+		QuoteAsset:        s.QuoteAsset,
+		PricePrecision:    s.PricePrecision,
+		QuantityPrecision: s.QuantityPrecision,
+	}
 
-		// Extract LOT_SIZE filter (quantity rules)
-		if lotSize, ok := filters["LOT_SIZE"]; ok {
-			if minQty, exists := lotSize["minQty"]; exists {
-				if val, ok := minQty.(string); ok {
-					symbolInfo.MinQuantity = val
-				}
+	// Extract LOT_SIZE filter (quantity rules)
+	if lotSize, ok := filters["LOT_SIZE"]; ok {
+		if minQty, exists := lotSize["minQty"]; exists {
+			if val, ok := minQty.(string); ok {
+				symbolInfo.MinQuantity = val
 			}
-			if maxQty, exists := lotSize["maxQty"]; exists {
-				if val, ok := maxQty.(string); ok {
-					symbolInfo.MaxQuantity = val
-				}
+		}
+		if maxQty, exists := lotSize["maxQty"]; exists {
+			if val, ok := maxQty.(string); ok {
+				symbolInfo.MaxQuantity = val
 			}
-			if stepSize, exists := lotSize["stepSize"]; exists {
-				if val, ok := stepSize.(string); ok {
-					symbolInfo.StepSize = val
-				}
+		}
+		if stepSize, exists := lotSize["stepSize"]; exists {
+			if val, ok := stepSize.(string); ok {
+				symbolInfo.StepSize = val
 			}
 		}
+	}
 
-		// Extract PRICE_FILTER (price rules)
-		if priceFilter, ok := filters["PRICE_FILTER"]; ok {
-			if minPrice, exists := priceFilter["minPrice"]; exists {
-				if val, ok := minPrice.(string); ok {
-					symbolInfo.MinPrice = val
-				}
+	// Extract PRICE_FILTER (price rules)
+	if priceFilter, ok := filters["PRICE_FILTER"]; ok {
+		if minPrice, exists := priceFilter["minPrice"]; exists {
+			if val, ok := minPrice.(string); ok {
+				symbolInfo.MinPrice = val
 			}
-			if maxPrice, exists := priceFilter["maxPrice"]; exists {
-				if val, ok := maxPrice.(string); ok {
-					symbolInfo.MaxPrice = val
-				}
+		}
+		if maxPrice, exists := priceFilter["maxPrice"]; exists {
+			if val, ok := maxPrice.(string); ok {
+				symbolInfo.MaxPrice = val
 			}
-			if tickSize, exists := priceFilter["tickSize"]; exists {
-				if val, ok := tickSize.(string); ok {
-					symbolInfo.TickSize = val
-				}
+		}
+		if tickSize, exists := priceFilter["tickSize"]; exists {
+			if val, ok := tickSize.(string); ok {
+				symbolInfo.TickSize = val
 			}
 		}
+	}
 
-		// Extract MIN_NOTIONAL filter (minimum order value)
-		if minNotional, ok := filters["MIN_NOTIONAL"]; ok {
-			if notional, exists := minNotional["notional"]; exists {
-				if val, ok := notional.(string); ok {
-					symbolInfo.MinNotional = val
-				}
+	// Extract MIN_NOTIONAL filter (minimum order value)
+	if minNotional, ok := filters["MIN_NOTIONAL"]; ok {
+		if notional, exists := minNotional["notional"]; exists {
+			if val, ok := notional.(string); ok {
+				symbolInfo.MinNotional = val
 			}
 		}
-
-		response.Symbols = append(response.Symbols, symbolInfo)
 	}
 
-	return response, nil
+	return symbolInfo
 }
 
 // AccountSnapshotAsset represents asset information in snapshot
@@ -570,8 +685,9 @@ type LiquidationRisk struct {
 }
 
 // GetFundingRate - Get current funding rate for a symbol
-func (b *Client) GetFundingRate(symbol string) (*FundingRateInfo, error) {
-	ctx := context.Background()
+func (b *Client) GetFundingRate(ctx context.Context, symbol string) (*FundingRateInfo, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 
 	premiumIndex, err := b.client.NewPremiumIndexService().
 		Symbol(symbol).
@@ -598,8 +714,9 @@ func (b *Client) GetFundingRate(symbol string) (*FundingRateInfo, error) {
 }
 
 // GetFundingRateHistory - Get historical funding rates
-func (b *Client) GetFundingRateHistory(symbol string, limit int, startTime, endTime int64) ([]*FundingRateHistory, error) {
-	ctx := context.Background()
+func (b *Client) GetFundingRateHistory(ctx context.Context, symbol string, limit int, startTime, endTime int64) ([]*FundingRateHistory, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 
 	service := b.client.NewFundingRateService().Symbol(symbol)
 
@@ -635,8 +752,8 @@ func (b *Client) GetFundingRateHistory(symbol string, limit int, startTime, endT
 }
 
 // CalculateFundingFee - Calculate expected funding fee
-func (b *Client) CalculateFundingFee(symbol string, positionSize float64) (float64, error) {
-	fundingInfo, err := b.GetFundingRate(symbol)
+func (b *Client) CalculateFundingFee(ctx context.Context, symbol string, positionSize float64) (float64, error) {
+	fundingInfo, err := b.GetFundingRate(ctx, symbol)
 	if err != nil {
 		return 0, err
 	}
@@ -650,8 +767,9 @@ func (b *Client) CalculateFundingFee(symbol string, positionSize float64) (float
 }
 
 // GetLiquidationRisk - Calculate liquidation risk for a position
-func (b *Client) GetLiquidationRisk(symbol string) (*LiquidationRisk, error) {
-	ctx := context.Background()
+func (b *Client) GetLiquidationRisk(ctx context.Context, symbol string) (*LiquidationRisk, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 
 	// Get position information
 	positions, err := b.client.NewGetPositionRiskService().
@@ -674,22 +792,16 @@ func (b *Client) GetLiquidationRisk(symbol string) (*LiquidationRisk, error) {
 
 	entryPrice, _ := strconv.ParseFloat(pos.EntryPrice, 64)
 	markPrice, _ := strconv.ParseFloat(pos.MarkPrice, 64)
+	markPrice = freshMarkPrice(symbol, markPrice)
 	liquidationPrice, _ := strconv.ParseFloat(pos.LiquidationPrice, 64)
 	unrealizedPnL, _ := strconv.ParseFloat(pos.UnRealizedProfit, 64)
 	leverage, _ := strconv.Atoi(pos.Leverage)
 
 	// Calculate distance to liquidation (percentage)
-	var distanceToLiquidation float64
-	if liquidationPrice > 0 {
-		if posAmt > 0 { // Long position
-			distanceToLiquidation = ((markPrice - liquidationPrice) / markPrice) * 100
-		} else { // Short position
-			distanceToLiquidation = ((liquidationPrice - markPrice) / markPrice) * 100
-		}
-	}
+	distance := distanceToLiquidation(posAmt, markPrice, liquidationPrice)
 
 	// Calculate margin ratio
-	account, err := b.GetAccountInfo()
+	account, err := b.GetAccountInfo(ctx)
 	var marginRatio float64
 	if err == nil && account.TotalMarginBalance > 0 {
 		marginRatio = (account.TotalMarginBalance + unrealizedPnL) / account.TotalPositionValue * 100
@@ -697,11 +809,11 @@ func (b *Client) GetLiquidationRisk(symbol string) (*LiquidationRisk, error) {
 
 	// Determine risk level
 	riskLevel := "LOW"
-	if distanceToLiquidation < 5 {
+	if distance < 5 {
 		riskLevel = "CRITICAL"
-	} else if distanceToLiquidation < 10 {
+	} else if distance < 10 {
 		riskLevel = "HIGH"
-	} else if distanceToLiquidation < 20 {
+	} else if distance < 20 {
 		riskLevel = "MEDIUM"
 	}
 
@@ -714,29 +826,16 @@ func (b *Client) GetLiquidationRisk(symbol string) (*LiquidationRisk, error) {
 		MarginRatio:           marginRatio,
 		UnrealizedPnL:         unrealizedPnL,
 		Leverage:              leverage,
-		DistanceToLiquidation: distanceToLiquidation,
+		DistanceToLiquidation: distance,
 		RiskLevel:             riskLevel,
 	}, nil
 }
 
 // GetAccountSnapshot - Get daily account snapshot (Futures)
 // This retrieves historical snapshots of your Futures account balance and positions
-func (b *Client) GetAccountSnapshot(startTime, endTime int64, limit int) (*AccountSnapshotResponse, error) {
-	// Get API credentials from environment
-	apiKey := os.Getenv("BINANCE_API_KEY")
-	secretKey := os.Getenv("BINANCE_SECRET_KEY")
-
-	if apiKey == "" || secretKey == "" {
-		return nil, fmt.Errorf("Binance API credentials not found")
-	}
-
-	// Determine base URL (testnet or production)
-	baseURL := "https://api.binance.com"
-	if os.Getenv("BINANCE_TESTNET") == "true" {
-		// Note: Testnet uses different endpoint
-		baseURL = "https://testnet.binance.vision"
-	}
-
+func (b *Client) GetAccountSnapshot(ctx context.Context, startTime, endTime int64, limit int) (*AccountSnapshotResponse, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 	// Build query parameters
 	params := url.Values{}
 	params.Set("type", "FUTURES")
@@ -756,34 +855,24 @@ func (b *Client) GetAccountSnapshot(startTime, endTime int64, limit int) (*Accou
 		params.Set("endTime", strconv.FormatInt(endTime, 10))
 	}
 
-	// Add timestamp
-	timestamp := time.Now().UnixMilli()
-	params.Set("timestamp", strconv.FormatInt(timestamp, 10))
+	// The placeholder signature makes the signing transport stamp and sign
+	// the request with the client's keys and the shared clock
+	params.Set("signature", "pending")
 
-	// Create signature
-	queryString := params.Encode()
-	h := hmac.New(sha256.New, []byte(secretKey))
-	h.Write([]byte(queryString))
-	signature := hex.EncodeToString(h.Sum(nil))
-
-	// Add signature to query
-	params.Set("signature", signature)
-
-	// Build full URL
-	fullURL := fmt.Sprintf("%s/sapi/v1/accountSnapshot?%s", baseURL, params.Encode())
+	// Build full URL (spot API, production or testnet as the client)
+	fullURL := fmt.Sprintf("%s/sapi/v1/accountSnapshot?%s", b.spot.BaseURL, params.Encode())
 
 	// Create HTTP request
-	req, err := http.NewRequest("GET", fullURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %v", err)
 	}
 
 	// Add API key header
-	req.Header.Set("X-MBX-APIKEY", apiKey)
+	req.Header.Set("X-MBX-APIKEY", b.spot.APIKey)
 
-	// Execute request
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	// Execute request on the client's pooled connections
+	resp, err := b.spot.HTTPClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %v", err)
 	}
@@ -808,3 +897,273 @@ func (b *Client) GetAccountSnapshot(startTime, endTime int64, limit int) (*Accou
 
 	return &result, nil
 }
+
+// ChangePositionMargin - Add or remove isolated margin on an open position
+func (b *Client) ChangePositionMargin(ctx context.Context, symbol string, amount float64, add bool) error {
+	ctx, cancel := withOrderTimeout(ctx)
+	defer cancel()
+
+	if amount <= 0 {
+		return fmt.Errorf("margin amount must be greater than 0")
+	}
+
+	// Binance: type 1 = add margin, type 2 = reduce margin
+	actionType := 2
+	if add {
+		actionType = 1
+	}
+
+	err := b.client.NewUpdatePositionMarginService().
+		Symbol(symbol).
+		Amount(strconv.FormatFloat(amount, 'f', -1, 64)).
+		Type(actionType).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to change position margin: %v", err)
+	}
+
+	return nil
+}
+
+// ReducePosition - Close a percentage of an open position with a reduce-only market order
+func (b *Client) ReducePosition(ctx context.Context, symbol string, percent float64) (*ClosePositionResult, error) {
+	ctx, cancel := withOrderTimeout(ctx)
+	defer cancel()
+
+	if percent <= 0 || percent > 100 {
+		return nil, fmt.Errorf("reduce percent must be between 0 and 100")
+	}
+
+	positions, err := b.client.NewGetPositionRiskService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(positions) == 0 {
+		return nil, fmt.Errorf("no position found for symbol %s", symbol)
+	}
+
+	posAmt, _ := strconv.ParseFloat(positions[0].PositionAmt, 64)
+	if posAmt == 0 {
+		return nil, fmt.Errorf("no open position for symbol %s", symbol)
+	}
+
+	symbolInfo, err := b.getSymbolInfo(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol info: %v", err)
+	}
+
+	// Round down to step size so the order never exceeds the position
+	step := filterStep(symbolInfo.StepSize, symbolInfo.QuantityPrecision)
+	amount, _ := decimal.NewFromString(positions[0].PositionAmt)
+	reduceQty := floorToStepDecimal(amount.Abs().Mul(decimal.NewFromFloat(percent)).Div(decimal.NewFromInt(100)), step)
+	if !reduceQty.IsPositive() {
+		return nil, fmt.Errorf("reduce quantity for %s is below step size %s", symbol, symbolInfo.StepSize)
+	}
+
+	closeSide := futures.SideTypeSell
+	if posAmt < 0 {
+		closeSide = futures.SideTypeBuy
+	}
+
+	order, err := b.client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(closeSide).
+		Type(futures.OrderTypeMarket).
+		Quantity(formatStepped(reduceQty, step, symbolInfo.QuantityPrecision)).
+		ReduceOnly(true).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reduce position: %v", err)
+	}
+
+	avgPrice, _ := strconv.ParseFloat(order.AvgPrice, 64)
+	entryPrice, _ := strconv.ParseFloat(positions[0].EntryPrice, 64)
+	executedQty, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+	if posAmt < 0 {
+		executedQty = -executedQty
+	}
+
+	return &ClosePositionResult{
+		Symbol:         symbol,
+		OrderID:        order.OrderID,
+		Side:           string(order.Side),
+		PositionSide:   string(order.PositionSide),
+		Quantity:       order.ExecutedQuantity,
+		Price:          order.AvgPrice,
+		Status:         string(order.Status),
+		RealizedProfit: (avgPrice - entryPrice) * executedQty,
+	}, nil
+}
+
+// distanceToLiquidation returns how far (in %) the mark price is from the liquidation price
+func distanceToLiquidation(posAmt, markPrice, liquidationPrice float64) float64 {
+	if liquidationPrice <= 0 || markPrice <= 0 {
+		return 0
+	}
+	if posAmt > 0 { // Long position
+		return ((markPrice - liquidationPrice) / markPrice) * 100
+	}
+	return ((liquidationPrice - markPrice) / markPrice) * 100 // Short position
+}
+
+// PositionExposure represents a single position's contribution to account risk
+type PositionExposure struct {
+	Symbol                string  `json:"symbol"`
+	PositionAmt           float64 `json:"positionAmt"`
+	Notional              float64 `json:"notional"`
+	Leverage              int     `json:"leverage"`
+	MarginType            string  `json:"marginType"`
+	UnrealizedPnL         float64 `json:"unrealizedPnl"`
+	LiquidationPrice      float64 `json:"liquidationPrice"`
+	DistanceToLiquidation float64 `json:"distanceToLiquidation"` // Percentage
+}
+
+// AccountHealth represents aggregated margin and exposure information
+type AccountHealth struct {
+	TotalMarginBalance   float64            `json:"totalMarginBalance"`
+	TotalWalletBalance   float64            `json:"totalWalletBalance"`
+	AvailableMargin      float64            `json:"availableMargin"`
+	MaintenanceMargin    float64            `json:"maintenanceMargin"`
+	InitialMargin        float64            `json:"initialMargin"`
+	MarginRatio          float64            `json:"marginRatio"` // Maintenance margin / margin balance (%)
+	TotalExposure        float64            `json:"totalExposure"` // Sum of absolute notional
+	EffectiveLeverage    float64            `json:"effectiveLeverage"`
+	TotalUnrealizedPnL   float64            `json:"totalUnrealizedPnl"`
+	OpenPositions        int                `json:"openPositions"`
+	MinDistanceToLiq     float64            `json:"minDistanceToLiquidation"` // Closest position to liquidation (%)
+	HealthScore          float64            `json:"healthScore"` // 0 (liquidation) - 100 (no risk)
+	HealthLevel          string             `json:"healthLevel"` // HEALTHY, WARNING, DANGER, CRITICAL
+	Positions            []PositionExposure `json:"positions"`
+}
+
+// GetAccountHealth - Aggregate margin ratio, exposure and a health score across all positions
+func (b *Client) GetAccountHealth(ctx context.Context) (*AccountHealth, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	account, err := b.client.NewGetAccountService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %v", err)
+	}
+
+	positions, err := b.client.NewGetPositionRiskService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %v", err)
+	}
+
+	health := &AccountHealth{Positions: []PositionExposure{}}
+	health.TotalMarginBalance, _ = strconv.ParseFloat(account.TotalMarginBalance, 64)
+	health.TotalWalletBalance, _ = strconv.ParseFloat(account.TotalWalletBalance, 64)
+	health.AvailableMargin, _ = strconv.ParseFloat(account.AvailableBalance, 64)
+	health.MaintenanceMargin, _ = strconv.ParseFloat(account.TotalMaintMargin, 64)
+	health.InitialMargin, _ = strconv.ParseFloat(account.TotalInitialMargin, 64)
+	health.TotalUnrealizedPnL, _ = strconv.ParseFloat(account.TotalUnrealizedProfit, 64)
+
+	minDistance := -1.0
+	for _, pos := range positions {
+		posAmt, _ := strconv.ParseFloat(pos.PositionAmt, 64)
+		if posAmt == 0 {
+			continue
+		}
+
+		notional, _ := strconv.ParseFloat(pos.Notional, 64)
+		markPrice, _ := strconv.ParseFloat(pos.MarkPrice, 64)
+		markPrice = freshMarkPrice(pos.Symbol, markPrice)
+		liquidationPrice, _ := strconv.ParseFloat(pos.LiquidationPrice, 64)
+		unrealizedPnL, _ := strconv.ParseFloat(pos.UnRealizedProfit, 64)
+		leverage, _ := strconv.Atoi(pos.Leverage)
+		distance := distanceToLiquidation(posAmt, markPrice, liquidationPrice)
+
+		if liquidationPrice > 0 && (minDistance < 0 || distance < minDistance) {
+			minDistance = distance
+		}
+
+		health.TotalExposure += absFloat(notional)
+		health.OpenPositions++
+		health.Positions = append(health.Positions, PositionExposure{
+			Symbol:                pos.Symbol,
+			PositionAmt:           posAmt,
+			Notional:              notional,
+			Leverage:              leverage,
+			MarginType:            pos.MarginType,
+			UnrealizedPnL:         unrealizedPnL,
+			LiquidationPrice:      liquidationPrice,
+			DistanceToLiquidation: distance,
+		})
+	}
+
+	if health.TotalMarginBalance > 0 {
+		health.MarginRatio = health.MaintenanceMargin / health.TotalMarginBalance * 100
+		health.EffectiveLeverage = health.TotalExposure / health.TotalMarginBalance
+	}
+	if minDistance >= 0 {
+		health.MinDistanceToLiq = minDistance
+	}
+
+	// Health score: 100 with no maintenance margin, 0 when margin ratio reaches 100% (liquidation)
+	health.HealthScore = 100 - health.MarginRatio
+	if health.HealthScore < 0 {
+		health.HealthScore = 0
+	}
+
+	health.HealthLevel = "HEALTHY"
+	if health.MarginRatio >= 80 {
+		health.HealthLevel = "CRITICAL"
+	} else if health.MarginRatio >= 50 {
+		health.HealthLevel = "DANGER"
+	} else if health.MarginRatio >= 25 || (minDistance >= 0 && minDistance < 10) {
+		health.HealthLevel = "WARNING"
+	}
+
+	return health, nil
+}
+
+// GetKlineCloses - Get close prices of the most recent klines (oldest first)
+func (b *Client) GetKlineCloses(ctx context.Context, symbol, interval string, limit int) ([]float64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	klines, err := b.client.NewKlinesService().
+		Symbol(symbol).
+		Interval(interval).
+		Limit(limit).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get klines for %s: %v", symbol, err)
+	}
+
+	closes := make([]float64, 0, len(klines))
+	for _, k := range klines {
+		closePrice, _ := strconv.ParseFloat(k.Close, 64)
+		closes = append(closes, closePrice)
+	}
+
+	return closes, nil
+}
+
+// GetOrderCommission - Sum the commission paid across all fills of an order,
+// by asset (fees are charged in BNB when BNB fee discount is on)
+func (b *Client) GetOrderCommission(ctx context.Context, symbol string, orderID int64) (map[string]float64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	fills, err := b.client.NewListAccountTradeService().
+		Symbol(symbol).
+		OrderID(orderID).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order fills: %v", err)
+	}
+
+	commissions := make(map[string]float64)
+	for _, fill := range fills {
+		if fill.OrderID != orderID {
+			continue
+		}
+		amount, _ := strconv.ParseFloat(fill.Commission, 64)
+		commissions[fill.CommissionAsset] += amount
+	}
+
+	return commissions, nil
+}
@@ -2,19 +2,26 @@ package binance
 
 import (
 	"context"
+	"crypto-trading-api/internal/logging"
 	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/tradehistory"
 	"fmt"
-	"log"
-	"os"
+	"net/http"
 	"strconv"
-	"strings"
+	"sync"
 	"time"
 
+	gobinance "github.com/adshao/go-binance/v2"
 	"github.com/adshao/go-binance/v2/futures"
+	"github.com/shopspring/decimal"
 )
 
 type Client struct {
-	client *futures.Client
+	client   *futures.Client
+	spot     *gobinance.Client // Spot market (funding arbitrage hedge leg)
+	orders   *orderEvents      // Order updates from the user data stream
+	signer   *signingTransport // Signs requests of both clients with the current keys
+	settings *symbolSettings   // Known leverage and margin type per symbol
 }
 
 // OrderResult represents the result of a futures order
@@ -25,52 +32,108 @@ type OrderResult struct {
 	Status      string
 	SLOrderID   int64
 	TPOrderID   int64
-}
 
-func InitClient() *Client {
-	apiKey := os.Getenv("BINANCE_API_KEY")
-	secretKey := os.Getenv("BINANCE_SECRET_KEY")
-	useTestnet := os.Getenv("BINANCE_TESTNET") // Add testnet support
+	// Timings of the placement
+	SentAt      time.Time // Entry order sent
+	AckAt       time.Time // Entry order acknowledged
+	FilledAt    time.Time // Entry filled, if it was on acknowledgement (Binance transaction time)
+	ProtectedAt time.Time // SL/TP orders placed
+}
 
+// NewClient creates the primary client and checks that Binance is
+// reachable. testnet switches every client (including the account clients)
+// to the Binance testnet.
+func NewClient(apiKey, secretKey string, testnet bool) (*Client, error) {
 	if apiKey == "" || secretKey == "" {
-		log.Fatal("BINANCE_API_KEY and BINANCE_SECRET_KEY must be set")
+		return nil, fmt.Errorf("BINANCE_API_KEY and BINANCE_SECRET_KEY must be set")
 	}
 
 	// Enable testnet if configured
-	if useTestnet == "true" || useTestnet == "1" {
+	if testnet {
 		futures.UseTestnet = true
-		log.Println("🔧 Using Binance TESTNET")
+		gobinance.UseTestnet = true
+		logging.Info().Msg("Using Binance TESTNET")
 	} else {
-		log.Println("🔧 Using Binance PRODUCTION")
+		logging.Info().Msg("Using Binance PRODUCTION")
 	}
 
-	client := futures.NewClient(apiKey, secretKey)
+	client := newClientFromKeys(apiKey, secretKey)
 
 	// Test connection
-	if err := testBinanceConnection(client); err != nil {
-		log.Fatalf("Failed to connect to Binance: %v", err)
+	if err := testBinanceConnection(client.client); err != nil {
+		return nil, fmt.Errorf("failed to connect to Binance: %v", err)
+	}
+
+	logging.Info().Msg("Binance client initialized successfully")
+
+	return client, nil
+}
+
+// NewAccountClient creates a client for an additional (follower) account.
+// Testnet/production follows the primary client set up by NewClient.
+func NewAccountClient(apiKey, secretKey string) (*Client, error) {
+	if apiKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("api key and secret key are required")
 	}
 
-	log.Println("✅ Binance client initialized successfully")
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+
+	client := newClientFromKeys(apiKey, secretKey)
+	if _, err := client.client.NewGetAccountService().Do(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to Binance: %v", err)
+	}
 
-	return &Client{client: client}
+	return client, nil
+}
+
+// newClientFromKeys creates a client without checking the keys. Signed
+// requests are stamped with the shared server clock offset and recvWindow,
+// and every call goes through the retry policy and circuit breaker.
+func newClientFromKeys(apiKey, secretKey string) *Client {
+	signer := newSigningTransport(apiKey, secretKey)
+	transport := newResilientTransport(signer)
+
+	futuresClient := futures.NewClient(apiKey, secretKey)
+	futuresClient.HTTPClient = &http.Client{Transport: transport}
+
+	spotClient := gobinance.NewClient(apiKey, secretKey)
+	spotClient.HTTPClient = &http.Client{Transport: transport}
+
+	return &Client{client: futuresClient, spot: spotClient, orders: newOrderEvents(), signer: signer, settings: newSymbolSettings()}
+}
+
+// SetCredentials switches the client to a rotated API key pair. Requests
+// already in flight finish with the old keys; open streams keep their
+// listen key until it is renewed.
+func (b *Client) SetCredentials(apiKey, secretKey string) error {
+	if apiKey == "" || secretKey == "" {
+		return fmt.Errorf("api key and secret key are required")
+	}
+	b.signer.keys.Store(&apiKeyPair{apiKey: apiKey, secretKey: secretKey})
+	return nil
 }
 
 func testBinanceConnection(client *futures.Client) error {
-	_, err := client.NewExchangeInfoService().Do(context.Background())
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+
+	_, err := client.NewExchangeInfoService().Do(ctx)
 	return err
 }
 
 // PlaceFuturesOrder - Execute market order with SL/TP
-func (b *Client) PlaceFuturesOrder(trade *models.Trade) (*OrderResult, error) {
-	ctx := context.Background()
+func (b *Client) PlaceFuturesOrder(ctx context.Context, trade *models.Trade) (*OrderResult, error) {
+	ctx, cancel := withOrderTimeout(logging.WithTrade(ctx, trade.ID, trade.Symbol))
+	defer cancel()
+	logger := logging.Ctx(ctx)
 
 	// 0. Get symbol precision info
-	symbolInfo, err := b.getSymbolInfo(trade.Symbol)
+	symbolInfo, err := b.getSymbolInfo(ctx, trade.Symbol)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get symbol info: %v", err)
 	}
-	log.Printf("📊 Symbol Info - %s: PricePrecision=%d, QuantityPrecision=%d, MinNotional=%s",
+	logger.Debug().Msgf("Symbol Info - %s: PricePrecision=%d, QuantityPrecision=%d, MinNotional=%s",
 		trade.Symbol, symbolInfo.PricePrecision, symbolInfo.QuantityPrecision, symbolInfo.MinNotional)
 
 	// 1. Set margin type (default to ISOLATED if not specified)
@@ -79,47 +142,31 @@ func (b *Client) PlaceFuturesOrder(trade *models.Trade) (*OrderResult, error) {
 		marginType = "ISOLATED"
 	}
 
-	err = b.client.NewChangeMarginTypeService().
-		Symbol(trade.Symbol).
-		MarginType(futures.MarginType(marginType)).
-		Do(ctx)
-	if err != nil {
-		// Ignore error if margin type is already set to desired type
-		// Error -4046 means "No need to change margin type"
-		errStr := err.Error()
-		if !strings.Contains(errStr, "-4046") && !strings.Contains(errStr, "No need to change margin type") {
-			log.Printf("Warning: Failed to set margin type to %s: %v", marginType, err)
-		} else {
-			log.Printf("Margin type already set to %s for %s", marginType, trade.Symbol)
-		}
-	} else {
-		log.Printf("✅ Margin type set to %s for %s", marginType, trade.Symbol)
-	}
+	// Leverage and margin type are only changed when they differ from the
+	// account's known settings
+	b.seedSymbolSettings(ctx)
+	b.ensureMarginType(ctx, trade.Symbol, marginType)
 
 	// 2. Set leverage
-	_, err = b.client.NewChangeLeverageService().
-		Symbol(trade.Symbol).
-		Leverage(trade.Leverage).
-		Do(ctx)
-	if err != nil {
+	if err := b.ensureLeverage(ctx, trade.Symbol, trade.Leverage); err != nil {
 		return nil, fmt.Errorf("failed to set leverage: %v", err)
 	}
 
 	// 3. Get current price for MARKET orders (for accurate notional calculation)
 	priceForCalculation := trade.EntryPrice
 	if trade.OrderType == "" || trade.OrderType == "MARKET" {
-		currentPrice, err := b.GetPrice(trade.Symbol)
+		currentPrice, err := b.GetPrice(ctx, trade.Symbol)
 		if err != nil {
-			log.Printf("⚠️ Failed to get current price, using entry price: %v", err)
+			logger.Warn().Err(err).Msg("Failed to get current price, using entry price")
 		} else {
 			priceForCalculation = currentPrice
-			log.Printf("📊 Using current market price for calculation: %.8f", currentPrice)
+			logger.Debug().Msgf("Using current market price for calculation: %.8f", currentPrice)
 		}
 	}
 
 	// 3.1 Calculate quantity
 	quantity := b.calculateQuantity(trade.Size, priceForCalculation, trade.Leverage, symbolInfo.QuantityPrecision, symbolInfo.StepSize)
-	log.Printf("📊 Calculated quantity: %s %s", quantity, trade.Symbol)
+	logger.Debug().Msgf("Calculated quantity: %s %s", quantity, trade.Symbol)
 
 	// 3.2 Validate quantity is not zero
 	parsedQty, _ := strconv.ParseFloat(quantity, 64)
@@ -149,7 +196,7 @@ func (b *Client) PlaceFuturesOrder(trade *models.Trade) (*OrderResult, error) {
 		return nil, fmt.Errorf("order value (%.2f USDT) is below minimum notional (%.2f USDT) for %s. Please increase Size or Leverage",
 			notionalValue, minNotional, trade.Symbol)
 	}
-	log.Printf("✅ Validation passed - Quantity: %s, Notional: %.2f USDT (min: %.2f USDT)", quantity, notionalValue, minNotional)
+	logger.Debug().Msgf("Validation passed - Quantity: %s, Notional: %.2f USDT (min: %.2f USDT)", quantity, notionalValue, minNotional)
 
 	// 3. Place order (MARKET or LIMIT)
 	orderService := b.client.NewCreateOrderService().
@@ -160,22 +207,26 @@ func (b *Client) PlaceFuturesOrder(trade *models.Trade) (*OrderResult, error) {
 	// Choose order type based on trade.OrderType
 	if trade.OrderType == "LIMIT" {
 		// LIMIT order: Wait for specific entry price
-		// Format entry price with correct precision
-		formattedEntryPrice := b.formatPrice(trade.EntryPrice, symbolInfo.PricePrecision)
+		// Round entry price to the tick size
+		formattedEntryPrice := b.formatPrice(trade.EntryPrice, symbolInfo.TickSize, symbolInfo.PricePrecision)
 		orderService.Type(futures.OrderTypeLimit).
 			Price(formattedEntryPrice).
 			TimeInForce(futures.TimeInForceTypeGTC) // Good Till Cancel
-		log.Printf("📌 Placing LIMIT order: Symbol=%s, Price=%s, Quantity=%s", trade.Symbol, formattedEntryPrice, quantity)
+		logger.Info().Msgf("Placing LIMIT order: Symbol=%s, Price=%s, Quantity=%s", trade.Symbol, formattedEntryPrice, quantity)
 	} else {
 		// MARKET order (default): Execute immediately at current price
 		orderService.Type(futures.OrderTypeMarket)
-		log.Printf("📌 Placing MARKET order: Symbol=%s, Quantity=%s", trade.Symbol, quantity)
+		logger.Info().Msgf("Placing MARKET order: Symbol=%s, Quantity=%s", trade.Symbol, quantity)
 	}
 
+	sentAt := time.Now()
 	order, err := orderService.Do(ctx)
 	if err != nil {
+		// The rejection may come from settings changed outside this server
+		b.settings.forget(trade.Symbol)
 		return nil, fmt.Errorf("failed to place order: %v", err)
 	}
+	ackAt := time.Now()
 
 	// 4. Get executed price
 	avgPrice, _ := strconv.ParseFloat(order.AvgPrice, 64)
@@ -185,34 +236,55 @@ func (b *Client) PlaceFuturesOrder(trade *models.Trade) (*OrderResult, error) {
 		AvgPrice:    avgPrice,
 		ExecutedQty: order.ExecutedQuantity,
 		Status:      string(order.Status),
+		SentAt:      sentAt,
+		AckAt:       ackAt,
+	}
+	if order.Status == futures.OrderStatusTypeFilled && order.UpdateTime > 0 {
+		result.FilledAt = time.UnixMilli(localMillis(order.UpdateTime))
 	}
 
-	// 5. Place Stop Loss order
-	log.Printf("📌 Placing Stop Loss order for %s...", trade.Symbol)
-	slOrderID, err := b.placeStopLoss(trade.Symbol, trade.Side, quantity, trade.StopLoss, symbolInfo.PricePrecision)
-	if err != nil {
-		log.Printf("❌ Failed to place SL order: %v", err)
-		// Don't fail the entire trade, just log the error
-	} else {
+	// The position is open now: protect it even if the caller gives up
+	ctx, cancelProtect := withOrderTimeout(context.WithoutCancel(ctx))
+	defer cancelProtect()
+
+	// 5. Place Stop Loss and Take Profit orders. They do not depend on each
+	// other, so both are sent at once.
+	var protection sync.WaitGroup
+	protection.Add(2)
+	go func() {
+		defer protection.Done()
+		logger.Info().Msgf("Placing Stop Loss order for %s...", trade.Symbol)
+		slOrderID, err := b.placeStopLoss(ctx, trade.Symbol, trade.Side, quantity, trade.StopLoss, TradeTrigger(trade), symbolInfo.TickSize, symbolInfo.PricePrecision)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to place SL order")
+			// Don't fail the entire trade, just log the error
+			return
+		}
 		result.SLOrderID = slOrderID
-	}
+	}()
 
 	// 6. Place Take Profit order
-	log.Printf("📌 Placing Take Profit order for %s...", trade.Symbol)
-	tpOrderID, err := b.placeTakeProfit(trade.Symbol, trade.Side, quantity, trade.TakeProfit, symbolInfo.PricePrecision)
-	if err != nil {
-		log.Printf("❌ Failed to place TP order: %v", err)
-		// Don't fail the entire trade, just log the error
-	} else {
+	go func() {
+		defer protection.Done()
+		logger.Info().Msgf("Placing Take Profit order for %s...", trade.Symbol)
+		tpOrderID, err := b.placeTakeProfit(ctx, trade.Symbol, trade.Side, quantity, trade.TakeProfit, TradeTrigger(trade), symbolInfo.TickSize, symbolInfo.PricePrecision)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to place TP order")
+			// Don't fail the entire trade, just log the error
+			return
+		}
 		result.TPOrderID = tpOrderID
-	}
+	}()
+	protection.Wait()
+	result.ProtectedAt = time.Now()
 
 	return result, nil
 }
 
 // Place Stop Loss order
-func (b *Client) placeStopLoss(symbol, side, quantity string, stopPrice float64, pricePrecision int) (int64, error) {
-	ctx := context.Background()
+func (b *Client) placeStopLoss(ctx context.Context, symbol, side, quantity string, stopPrice float64, trigger Trigger, tickSize string, pricePrecision int) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 
 	// Reverse side for closing position
 	closeSide := futures.SideTypeSell
@@ -220,30 +292,31 @@ func (b *Client) placeStopLoss(symbol, side, quantity string, stopPrice float64,
 		closeSide = futures.SideTypeBuy
 	}
 
-	// Format stop price with correct precision
-	formattedStopPrice := b.formatPrice(stopPrice, pricePrecision)
+	// Round stop price to the tick size
+	formattedStopPrice := b.formatPrice(stopPrice, tickSize, pricePrecision)
 
 	// Use ClosePosition(true) to automatically close the entire position
 	// Do NOT specify Quantity when using ClosePosition
-	order, err := b.client.NewCreateOrderService().
+	order, err := trigger.apply(b.client.NewCreateOrderService().
 		Symbol(symbol).
 		Side(closeSide).
 		Type(futures.OrderTypeStopMarket).
 		StopPrice(formattedStopPrice).
-		ClosePosition(true).
+		ClosePosition(true)).
 		Do(ctx)
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to place SL order: %v", err)
 	}
 
-	log.Printf("✅ Stop Loss order placed: OrderID=%d, Symbol=%s, StopPrice=%s", order.OrderID, symbol, formattedStopPrice)
+	logging.Ctx(ctx).Info().Msgf("Stop Loss order placed: OrderID=%d, Symbol=%s, StopPrice=%s", order.OrderID, symbol, formattedStopPrice)
 	return order.OrderID, nil
 }
 
 // Place Take Profit order
-func (b *Client) placeTakeProfit(symbol, side, quantity string, tpPrice float64, pricePrecision int) (int64, error) {
-	ctx := context.Background()
+func (b *Client) placeTakeProfit(ctx context.Context, symbol, side, quantity string, tpPrice float64, trigger Trigger, tickSize string, pricePrecision int) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 
 	// Reverse side for closing position
 	closeSide := futures.SideTypeSell
@@ -251,162 +324,258 @@ func (b *Client) placeTakeProfit(symbol, side, quantity string, tpPrice float64,
 		closeSide = futures.SideTypeBuy
 	}
 
-	// Format TP price with correct precision
-	formattedTPPrice := b.formatPrice(tpPrice, pricePrecision)
+	// Round TP price to the tick size
+	formattedTPPrice := b.formatPrice(tpPrice, tickSize, pricePrecision)
 
 	// Use ClosePosition(true) to automatically close the entire position
 	// Do NOT specify Quantity when using ClosePosition
-	order, err := b.client.NewCreateOrderService().
+	order, err := trigger.apply(b.client.NewCreateOrderService().
 		Symbol(symbol).
 		Side(closeSide).
 		Type(futures.OrderTypeTakeProfitMarket).
 		StopPrice(formattedTPPrice).
-		ClosePosition(true).
+		ClosePosition(true)).
 		Do(ctx)
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to place TP order: %v", err)
 	}
 
-	log.Printf("✅ Take Profit order placed: OrderID=%d, Symbol=%s, TPPrice=%s", order.OrderID, symbol, formattedTPPrice)
+	logging.Ctx(ctx).Info().Msgf("Take Profit order placed: OrderID=%d, Symbol=%s, TPPrice=%s", order.OrderID, symbol, formattedTPPrice)
 	return order.OrderID, nil
 }
 
 // getSymbolInfo - Get symbol precision information
-func (b *Client) getSymbolInfo(symbol string) (*SymbolInfo, error) {
-	exchangeInfo, err := b.GetExchangeInfo(symbol)
+func (b *Client) getSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	symbolInfo, err := b.symbolRules(ctx, symbol)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(exchangeInfo.Symbols) == 0 {
+	if symbolInfo == nil {
 		return nil, fmt.Errorf("symbol %s not found", symbol)
 	}
 
-	return &exchangeInfo.Symbols[0], nil
+	return symbolInfo, nil
+}
+
+// SymbolRules returns a symbol's trading rules (price and lot filters), nil
+// if Binance Futures does not list it. The rules are cached in memory, so
+// checking an order against them rarely costs a request.
+func (b *Client) SymbolRules(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	return b.symbolRules(ctx, symbol)
 }
 
-// formatPrice - Format price with correct precision
-func (b *Client) formatPrice(price float64, precision int) string {
-	formatStr := fmt.Sprintf("%%.%df", precision)
-	return fmt.Sprintf(formatStr, price)
+// formatPrice rounds a price to the nearest multiple of the tick size (one
+// unit of precision when unknown) and formats it exactly
+func (b *Client) formatPrice(price float64, tickSize string, precision int) string {
+	tick := filterStep(tickSize, precision)
+	rounded := roundToStep(decimal.NewFromFloat(price), tick)
+	return formatStepped(rounded, tick, precision)
 }
 
 // Calculate position quantity based on size and leverage
 func (b *Client) calculateQuantity(size, price float64, leverage int, quantityPrecision int, stepSize string) string {
 	// Calculate quantity: (position size in USDT * leverage) / price
-	quantity := (size * float64(leverage)) / price
-
-	// Parse step size
-	step, _ := strconv.ParseFloat(stepSize, 64)
-	if step <= 0 {
-		step = 1.0 / float64(pow10(quantityPrecision))
-	}
+	exact := decimal.NewFromFloat(size).Mul(decimal.NewFromInt(int64(leverage))).Div(decimal.NewFromFloat(price))
 
 	// Round quantity to nearest step size
 	// Example: if stepSize=0.001, quantity=0.0018 → 0.002
-	quantity = roundToStepSize(quantity, step)
-
-	// Calculate the minimum quantity based on step size
-	minQuantity := step
+	step := filterStep(stepSize, quantityPrecision)
+	quantity := roundToStep(exact, step)
 
-	// If quantity is less than minimum, round UP to minimum
-	if quantity < minQuantity {
-		quantity = minQuantity
-		log.Printf("⚠️ Quantity too small (%.8f), rounded up to minimum: %.8f", (size*float64(leverage))/price, quantity)
+	// If quantity is less than one step, round UP to one step
+	if quantity.LessThan(step) {
+		quantity = step
+		logging.Warn().Msgf("Quantity too small (%s), rounded up to minimum: %s", exact.StringFixed(8), step.String())
 	}
 
-	// Format with symbol's quantity precision
-	formatStr := fmt.Sprintf("%%.%df", quantityPrecision)
-	formattedQty := fmt.Sprintf(formatStr, quantity)
-
-	// Parse back to verify it's not zero
-	parsedQty, _ := strconv.ParseFloat(formattedQty, 64)
-	if parsedQty == 0 {
-		// Force to minimum quantity
-		return fmt.Sprintf(formatStr, minQuantity)
-	}
-
-	return formattedQty
+	// Format with symbol's quantity precision (or the step's, if finer)
+	return formatStepped(quantity, step, quantityPrecision)
 }
 
-// roundToStepSize rounds a value to the nearest step size
-func roundToStepSize(value, stepSize float64) float64 {
-	if stepSize == 0 {
-		return value
-	}
-	return float64(int64(value/stepSize+0.5)) * stepSize
-}
+// Entry order polling used by MonitorTrade
+const (
+	monitorPollInterval  = 5 * time.Second // While the user data stream is down
+	monitorCheckInterval = time.Minute     // While streaming, to catch events missed around reconnects
+)
 
-// Helper function to calculate 10^n
-func pow10(n int) int {
-	result := 1
-	for i := 0; i < n; i++ {
-		result *= 10
-	}
-	return result
+// tradeUpdater persists trade changes
+type tradeUpdater interface {
+	UpdateTrade(ctx context.Context, trade *models.Trade) error
 }
 
-// MonitorTrade - Monitor trade and update status in Firebase
+// MonitorTrade - Monitor trade and update status in Firebase until the order
+// is done or ctx is cancelled. Updates come from ORDER_TRADE_UPDATE events of
+// the user data stream; the order is polled over REST only while the stream
+// is down, plus an occasional check for missed events.
 // Note: fb should be interface or concrete type from firebase package
-func (b *Client) MonitorTrade(trade *models.Trade, fb interface {
+func (b *Client) MonitorTrade(ctx context.Context, trade *models.Trade, fb interface {
 	UpdateTrade(ctx context.Context, trade *models.Trade) error
 }) {
-	ctx := context.Background()
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	ctx = tradehistory.WithSource(ctx, tradehistory.SourceMonitor)
+	ctx = logging.WithTrade(ctx, trade.ID, trade.Symbol)
 
-	for range ticker.C {
-		// Check order status
-		order, err := b.client.NewGetOrderService().
-			Symbol(trade.Symbol).
-			OrderID(trade.OrderID).
-			Do(ctx)
-
-		if err != nil {
-			log.Printf("Error checking order status: %v", err)
-			continue
-		}
+	updates, unsubscribe := b.orders.subscribe(trade.OrderID)
+	defer unsubscribe()
 
-		// Update trade status
-		if order.Status != futures.OrderStatusTypeNew &&
-			order.Status != futures.OrderStatusTypePartiallyFilled {
+	// The order may be done before the subscription started (market orders usually are)
+	if b.checkOrderStatus(ctx, trade, fb) {
+		return
+	}
+	lastCheck := time.Now()
 
-			trade.Status = string(order.Status)
-			trade.ClosedAt = time.Now().Unix()
+	ticker := time.NewTicker(monitorPollInterval)
+	defer ticker.Stop()
 
-			if err := fb.UpdateTrade(ctx, trade); err != nil {
-				log.Printf("Error updating trade: %v", err)
+	for {
+		select {
+		case event := <-updates:
+			if b.applyOrderStatus(ctx, trade, futures.OrderStatusType(event.Status), event.Time, fb) {
+				return
 			}
-
-			// Stop monitoring if trade is closed
-			if order.Status == futures.OrderStatusTypeFilled ||
-				order.Status == futures.OrderStatusTypeCanceled {
-				log.Printf("Trade %s closed with status: %s", trade.ID, order.Status)
+		case <-ticker.C:
+			if b.orders.streaming() && time.Since(lastCheck) < monitorCheckInterval {
+				continue
+			}
+			lastCheck = time.Now()
+			if b.checkOrderStatus(ctx, trade, fb) {
 				return
 			}
+		case <-ctx.Done():
+			logging.Ctx(ctx).Info().Msgf("Stopped monitoring trade %s: %v", trade.ID, ctx.Err())
+			return
 		}
 	}
 }
 
-// GetPrice - Get current price
-func (b *Client) GetPrice(symbol string) (float64, error) {
-	prices, err := b.client.NewListPricesService().Symbol(symbol).Do(context.Background())
+// checkOrderStatus polls the entry order and applies its status. It reports
+// whether monitoring is done.
+func (b *Client) checkOrderStatus(ctx context.Context, trade *models.Trade, fb tradeUpdater) bool {
+	callCtx, cancel := withTimeout(ctx)
+	order, err := b.client.NewGetOrderService().
+		Symbol(trade.Symbol).
+		OrderID(trade.OrderID).
+		Do(callCtx)
+	cancel()
+
+	if err != nil {
+		logging.Ctx(ctx).Error().Err(err).Msg("Error checking order status")
+		return false
+	}
+
+	return b.applyOrderStatus(ctx, trade, order.Status, order.UpdateTime, fb)
+}
+
+// applyOrderStatus records a final entry order status, updated at
+// updateTime (Binance ms), on the trade. It reports whether monitoring is
+// done.
+func (b *Client) applyOrderStatus(ctx context.Context, trade *models.Trade, status futures.OrderStatusType, updateTime int64, fb tradeUpdater) bool {
+	if status == futures.OrderStatusTypeNew || status == futures.OrderStatusTypePartiallyFilled {
+		return false
+	}
+
+	trade.Status = string(status)
+	trade.ClosedAt = time.Now().Unix()
+
+	// Record entry fees and fill latency once the order is filled
+	if status == futures.OrderStatusTypeFilled {
+		recordFillLatency(trade, updateTime)
+		if err := b.RecordOrderFees(ctx, trade, trade.OrderID); err != nil {
+			logging.Ctx(ctx).Error().Err(err).Msg("Error getting order commission")
+		}
+	}
+
+	if err := fb.UpdateTrade(ctx, trade); err != nil {
+		logging.Ctx(ctx).Error().Err(err).Msg("Error updating trade")
+	}
+
+	// Stop monitoring if trade is closed
+	if status == futures.OrderStatusTypeFilled ||
+		status == futures.OrderStatusTypeCanceled ||
+		status == futures.OrderStatusTypeExpired {
+		logging.Ctx(ctx).Info().Msgf("Trade %s closed with status: %s", trade.ID, status)
+		return true
+	}
+	return false
+}
+
+// recordFillLatency records when a timed trade's entry filled, from the
+// fill's Binance transaction time (ms), unless already known
+func recordFillLatency(trade *models.Trade, fillTime int64) {
+	if trade.Latency == nil || trade.Latency.FilledMs != 0 || fillTime <= 0 {
+		return
+	}
+	if filled := localMillis(fillTime) - trade.Latency.ReceivedAt; filled > 0 {
+		trade.Latency.FilledMs = filled
+	}
+}
+
+// GetPrice - Get current price, from the streams when fresh, otherwise over REST
+func (b *Client) GetPrice(ctx context.Context, symbol string) (float64, error) {
+	if price, ok := prices.price(symbol); ok {
+		prices.hits.Add(1)
+		return price, nil
+	}
+	if price, ok := getSharedPrice(ctx, symbol); ok {
+		prices.hits.Add(1)
+		return price, nil
+	}
+	prices.misses.Add(1)
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	tickers, err := b.client.NewListPricesService().Symbol(symbol).Do(ctx)
 	if err != nil {
 		return 0, err
 	}
 
-	if len(prices) == 0 {
+	if len(tickers) == 0 {
 		return 0, fmt.Errorf("no price data for symbol %s", symbol)
 	}
 
-	price, err := strconv.ParseFloat(prices[0].Price, 64)
-	return price, err
+	price, err := strconv.ParseFloat(tickers[0].Price, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	// Cached too, so a burst of orders on a symbol costs one request
+	prices.putLast(symbol, price)
+	setSharedPrice(symbol, price)
+	return price, nil
+}
+
+// GetMarkPrice - Get a symbol's mark price, from the streams when fresh,
+// otherwise over REST
+func (b *Client) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	if price, ok := prices.markPrice(symbol); ok {
+		prices.hits.Add(1)
+		return price, nil
+	}
+	prices.misses.Add(1)
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	premiumIndex, err := b.client.NewPremiumIndexService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(premiumIndex) == 0 {
+		return 0, fmt.Errorf("no mark price for symbol %s", symbol)
+	}
+
+	price, err := strconv.ParseFloat(premiumIndex[0].MarkPrice, 64)
+	if err != nil {
+		return 0, err
+	}
+	prices.putMark(symbol, price)
+	return price, nil
 }
 
 // GetBinanceServerTime - Get Binance server time
-func (b *Client) GetBinanceServerTime() (int64, error) {
-	ctx := context.Background()
+func (b *Client) GetBinanceServerTime(ctx context.Context) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 	serverTime, err := b.client.NewServerTimeService().Do(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get server time: %v", err)
@@ -414,29 +583,33 @@ func (b *Client) GetBinanceServerTime() (int64, error) {
 	return serverTime, nil
 }
 
-// SyncTime - Sync local time with Binance server and return offset
-func (b *Client) SyncTime() (int64, error) {
-	localTime := time.Now().UnixMilli()
+// SyncTime - Sync local time with Binance server and return offset. The
+// offset is applied to all subsequent signed requests.
+func (b *Client) SyncTime(ctx context.Context) (int64, error) {
+	requestTime := time.Now().UnixMilli()
 
-	serverTime, err := b.GetBinanceServerTime()
+	serverTime, err := b.GetBinanceServerTime(ctx)
 	if err != nil {
 		return 0, err
 	}
 
+	// Compare against the midpoint of the round trip
+	localTime := (requestTime + time.Now().UnixMilli()) / 2
 	offset := serverTime - localTime
+	clock.offset.Store(offset)
 
-	log.Printf("⏰ Time sync: Local=%d, Server=%d, Offset=%dms", localTime, serverTime, offset)
+	logging.Info().Msgf("Time sync: Local=%d, Server=%d, Offset=%dms", localTime, serverTime, offset)
 
 	if absInt64(offset) > 1000 {
-		log.Printf("⚠️ Clock drift detected: %dms. Consider syncing system clock.", offset)
+		logging.Warn().Msgf("Clock drift detected: %dms, compensating on signed requests. Consider syncing system clock.", offset)
 	}
 
 	return offset, nil
 }
 
 // CheckTimeSyncStatus - Check if time is within acceptable range
-func (b *Client) CheckTimeSyncStatus() (bool, int64, error) {
-	offset, err := b.SyncTime()
+func (b *Client) CheckTimeSyncStatus(ctx context.Context) (bool, int64, error) {
+	offset, err := b.SyncTime(ctx)
 	if err != nil {
 		return false, 0, err
 	}
@@ -446,9 +619,9 @@ func (b *Client) CheckTimeSyncStatus() (bool, int64, error) {
 	isInSync := absInt64(offset) < 1000
 
 	if !isInSync {
-		log.Printf("❌ Time not in sync! Offset: %dms (max recommended: 1000ms)", offset)
+		logging.Error().Msgf("Time not in sync! Offset: %dms (max recommended: 1000ms)", offset)
 	} else {
-		log.Printf("✅ Time in sync. Offset: %dms", offset)
+		logging.Info().Msgf("Time in sync. Offset: %dms", offset)
 	}
 
 	return isInSync, offset, nil
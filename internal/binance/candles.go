@@ -0,0 +1,402 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// candleIntervals are the kline intervals candles can be built for. Longer
+// intervals do not start on multiples of their length, so aggTrades could
+// not be placed in them.
+var candleIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// CandleInterval returns the length of a kline interval, false unless it is
+// one candles can be kept for (1m to 1d)
+func CandleInterval(interval string) (time.Duration, bool) {
+	period, ok := candleIntervals[interval]
+	return period, ok
+}
+
+// candleSeries holds the rolling candles of one symbol and interval
+type candleSeries struct {
+	symbol     string
+	interval   string
+	period     int64           // Interval length (ms)
+	candles    []models.Candle // Oldest first; the last one may still be forming
+	lastClosed int64           // Open time of the last candle published as closed
+	lastUpdate time.Time
+	stopC      chan struct{}
+}
+
+// aggTradeStream is a symbol's aggTrade stream, shared by its series
+type aggTradeStream struct {
+	refs   int
+	lastID int64 // Last aggregate trade applied
+	stopC  chan struct{}
+}
+
+// StartCandleStream keeps rolling candles for a symbol and interval in
+// memory: history is loaded once over REST, then the kline stream keeps the
+// candles up to date and the aggTrade stream moves the forming candle with
+// every trade. Closed candles are published as candle.closed events.
+func (wsm *WebSocketManager) StartCandleStream(symbol, interval string) error {
+	symbol = strings.ToUpper(symbol)
+	period, ok := candleIntervals[interval]
+	if !ok {
+		return fmt.Errorf("unsupported candle interval %q (1m to 1d)", interval)
+	}
+
+	key := symbol + "|" + interval
+	wsm.candleMu.RLock()
+	_, exists := wsm.candles[key]
+	wsm.candleMu.RUnlock()
+	if exists {
+		return nil
+	}
+
+	history, err := wsm.client.GetCandles(context.Background(), symbol, interval, wsm.config.CandleHistory)
+	if err != nil {
+		return err
+	}
+
+	series := &candleSeries{
+		symbol:     symbol,
+		interval:   interval,
+		period:     period.Milliseconds(),
+		candles:    history,
+		lastUpdate: time.Now(),
+		stopC:      make(chan struct{}),
+	}
+
+	for _, candle := range history {
+		if candle.Final {
+			series.lastClosed = candle.OpenTime
+		}
+	}
+
+	wsm.candleMu.Lock()
+	if _, exists := wsm.candles[key]; exists {
+		wsm.candleMu.Unlock()
+		return nil
+	}
+	wsm.candles[key] = series
+
+	trades, ok := wsm.aggTrades[symbol]
+	if !ok {
+		trades = &aggTradeStream{stopC: make(chan struct{})}
+		wsm.aggTrades[symbol] = trades
+		go superviseMarketStream(symbol+" aggTrade stream", trades.stopC, func() (chan struct{}, chan struct{}, error) {
+			return futures.WsAggTradeServe(symbol, wsm.applyAggTrade, func(err error) {
+				logging.Warn().Err(err).Str(logging.FieldSymbol, symbol).Msgf("%s aggTrade stream error", symbol)
+			})
+		})
+	}
+	trades.refs++
+	wsm.candleMu.Unlock()
+
+	go superviseMarketStream(symbol+" "+interval+" kline stream", series.stopC, func() (chan struct{}, chan struct{}, error) {
+		return futures.WsKlineServe(symbol, interval, wsm.applyKline, func(err error) {
+			logging.Warn().Err(err).Str(logging.FieldSymbol, symbol).Msgf("%s %s kline stream error", symbol, interval)
+		})
+	})
+
+	logging.Info().Str(logging.FieldSymbol, symbol).Msgf("Candles started for %s %s (%d loaded)", symbol, interval, len(history))
+	return nil
+}
+
+// StopCandleStream stops and drops the candles of a symbol and interval
+func (wsm *WebSocketManager) StopCandleStream(symbol, interval string) bool {
+	symbol = strings.ToUpper(symbol)
+
+	wsm.candleMu.Lock()
+	defer wsm.candleMu.Unlock()
+
+	key := symbol + "|" + interval
+	series, ok := wsm.candles[key]
+	if !ok {
+		return false
+	}
+	close(series.stopC)
+	delete(wsm.candles, key)
+
+	if trades := wsm.aggTrades[symbol]; trades != nil {
+		trades.refs--
+		if trades.refs == 0 {
+			close(trades.stopC)
+			delete(wsm.aggTrades, symbol)
+		}
+	}
+
+	logging.Info().Str(logging.FieldSymbol, symbol).Msgf("Candles stopped for %s %s", symbol, interval)
+	return true
+}
+
+// stopCandleStreams stops every candle series
+func (wsm *WebSocketManager) stopCandleStreams() {
+	wsm.candleMu.Lock()
+	defer wsm.candleMu.Unlock()
+
+	for key, series := range wsm.candles {
+		close(series.stopC)
+		delete(wsm.candles, key)
+	}
+	for symbol, trades := range wsm.aggTrades {
+		close(trades.stopC)
+		delete(wsm.aggTrades, symbol)
+	}
+}
+
+// Candles returns up to limit of the most recent candles (oldest first, the
+// last one possibly still forming), false if the series is not streamed
+func (wsm *WebSocketManager) Candles(symbol, interval string, limit int) ([]models.Candle, bool) {
+	wsm.candleMu.RLock()
+	defer wsm.candleMu.RUnlock()
+
+	series, ok := wsm.candles[strings.ToUpper(symbol)+"|"+interval]
+	if !ok {
+		return nil, false
+	}
+
+	candles := series.candles
+	if limit > 0 && len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+	return append([]models.Candle(nil), candles...), true
+}
+
+// KlineCloses returns the close prices of the most recent limit candles like
+// Client.GetKlineCloses, false unless the series is streamed with that much
+// history
+func (wsm *WebSocketManager) KlineCloses(symbol, interval string, limit int) ([]float64, bool) {
+	candles, ok := wsm.Candles(symbol, interval, limit)
+	if !ok || len(candles) < limit {
+		return nil, false
+	}
+
+	closes := make([]float64, len(candles))
+	for i, candle := range candles {
+		closes[i] = candle.Close
+	}
+	return closes, true
+}
+
+// candleStatus describes the streamed series for GetStreamStatus
+func (wsm *WebSocketManager) candleStatus() []map[string]interface{} {
+	wsm.candleMu.RLock()
+	defer wsm.candleMu.RUnlock()
+
+	status := []map[string]interface{}{}
+	for _, series := range wsm.candles {
+		status = append(status, map[string]interface{}{
+			"symbol":     series.symbol,
+			"interval":   series.interval,
+			"candles":    len(series.candles),
+			"lastUpdate": formatStreamTime(series.lastUpdate),
+		})
+	}
+	return status
+}
+
+// applyKline replaces the candle a kline event describes, appending it when
+// it is new, and publishes it once it closes
+func (wsm *WebSocketManager) applyKline(event *futures.WsKlineEvent) {
+	k := event.Kline
+	candle := models.Candle{
+		OpenTime:  k.StartTime,
+		CloseTime: k.EndTime,
+		Open:      parseFloat(k.Open),
+		High:      parseFloat(k.High),
+		Low:       parseFloat(k.Low),
+		Close:     parseFloat(k.Close),
+		Volume:    parseFloat(k.Volume),
+		Trades:    k.TradeNum,
+		Final:     k.IsFinal,
+	}
+
+	wsm.candleMu.Lock()
+	series, ok := wsm.candles[event.Symbol+"|"+k.Interval]
+	if !ok {
+		wsm.candleMu.Unlock()
+		return
+	}
+	series.put(candle, wsm.config.CandleHistory)
+	closed := candle.Final && candle.OpenTime > series.lastClosed
+	if closed {
+		series.lastClosed = candle.OpenTime
+	}
+	wsm.candleMu.Unlock()
+
+	if closed {
+		wsm.bus.Publish(events.CandleClosed{Symbol: series.symbol, Interval: series.interval, Candle: candle})
+	}
+}
+
+// applyAggTrade moves the forming candle of every series of the symbol
+// between kline events, opening the next candle if the trade falls past it
+func (wsm *WebSocketManager) applyAggTrade(event *futures.WsAggTradeEvent) {
+	price := parseFloat(event.Price)
+	qty := parseFloat(event.Quantity)
+	if price <= 0 {
+		return
+	}
+	prices.putLast(event.Symbol, price)
+
+	wsm.candleMu.Lock()
+	defer wsm.candleMu.Unlock()
+
+	trades, ok := wsm.aggTrades[event.Symbol]
+	if !ok || event.AggregateTradeID <= trades.lastID {
+		return
+	}
+	trades.lastID = event.AggregateTradeID
+
+	for _, series := range wsm.candles {
+		if series.symbol != event.Symbol || len(series.candles) == 0 {
+			continue
+		}
+
+		openTime := event.TradeTime - event.TradeTime%series.period
+		last := &series.candles[len(series.candles)-1]
+		switch {
+		case openTime == last.OpenTime && !last.Final:
+			last.High = max(last.High, price)
+			last.Low = min(last.Low, price)
+			last.Close = price
+			last.Volume += qty
+			last.Trades += event.LastTradeID - event.FirstTradeID + 1
+		case openTime > last.OpenTime:
+			series.put(models.Candle{
+				OpenTime:  openTime,
+				CloseTime: openTime + series.period - 1,
+				Open:      price,
+				High:      price,
+				Low:       price,
+				Close:     price,
+				Volume:    qty,
+				Trades:    event.LastTradeID - event.FirstTradeID + 1,
+			}, wsm.config.CandleHistory)
+		default:
+			continue
+		}
+		series.lastUpdate = time.Now()
+	}
+}
+
+// put stores a candle in order, keeping at most history candles
+func (s *candleSeries) put(candle models.Candle, history int) {
+	s.lastUpdate = time.Now()
+
+	n := len(s.candles)
+	for i := n - 1; i >= 0 && s.candles[i].OpenTime >= candle.OpenTime; i-- {
+		if s.candles[i].OpenTime == candle.OpenTime {
+			s.candles[i] = candle
+			return
+		}
+	}
+	if n > 0 && s.candles[n-1].OpenTime > candle.OpenTime {
+		return // Older than the kept history
+	}
+
+	// A newer candle means the previous one closed, even if its final kline was missed
+	if n > 0 {
+		s.candles[n-1].Final = true
+	}
+	s.candles = append(s.candles, candle)
+	if len(s.candles) > history {
+		s.candles = s.candles[len(s.candles)-history:]
+	}
+}
+
+// superviseMarketStream keeps a market data stream connected until stop is
+// closed, reconnecting with exponential backoff
+func superviseMarketStream(name string, stop chan struct{}, serve func() (doneC, stopC chan struct{}, err error)) {
+	backoff := time.Second
+	for {
+		doneC, stopC, err := serve()
+		if err != nil {
+			logging.Warn().Msgf("%s failed to connect: %v (retry in %v)", name, err, backoff)
+		} else {
+			connectedAt := time.Now()
+			select {
+			case <-stop:
+				close(stopC)
+				return
+			case <-doneC:
+			}
+
+			// A connection that stayed up for a while starts the backoff over
+			if time.Since(connectedAt) > time.Minute {
+				backoff = time.Second
+			}
+			logging.Warn().Msgf("%s disconnected, reconnecting in %v", name, backoff)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-stop:
+			return
+		}
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
+
+// GetCandles - Get the most recent klines as candles (oldest first)
+func (b *Client) GetCandles(ctx context.Context, symbol, interval string, limit int) ([]models.Candle, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	klines, err := b.client.NewKlinesService().
+		Symbol(symbol).
+		Interval(interval).
+		Limit(limit).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get klines for %s: %v", symbol, err)
+	}
+
+	now := time.Now().UnixMilli()
+	candles := make([]models.Candle, 0, len(klines))
+	for _, k := range klines {
+		candles = append(candles, models.Candle{
+			OpenTime:  k.OpenTime,
+			CloseTime: k.CloseTime,
+			Open:      parseFloat(k.Open),
+			High:      parseFloat(k.High),
+			Low:       parseFloat(k.Low),
+			Close:     parseFloat(k.Close),
+			Volume:    parseFloat(k.Volume),
+			Trades:    k.TradeNum,
+			Final:     k.CloseTime < now,
+		})
+	}
+	return candles, nil
+}
+
+// parseFloat parses a Binance decimal string, 0 when malformed
+func parseFloat(value string) float64 {
+	parsed, _ := strconv.ParseFloat(value, 64)
+	return parsed
+}
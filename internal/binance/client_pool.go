@@ -0,0 +1,211 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/vault"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// PrimaryAccount is the name of the BINANCE_API_KEY account
+const PrimaryAccount = "main"
+
+// ErrUnknownAccount is returned for an account name that is not configured
+var ErrUnknownAccount = errors.New("unknown account")
+
+// CredentialStore loads users' encrypted Binance credentials
+type CredentialStore interface {
+	GetUserCredentials(ctx context.Context, userID string) (*models.UserCredentials, error)
+}
+
+// ClientPool resolves the Binance client to use for a user: the user's own
+// keys when stored, otherwise the primary (operator) account unless
+// requireUserKeys is set. Without a cipher every user gets the primary client.
+// Requests may instead name one of the operator's accounts (main and the
+// sub-accounts added with AddAccount), each with its own client.
+type ClientPool struct {
+	primary         *Client
+	accounts        map[string]*Client
+	store           CredentialStore
+	cipher          *vault.Cipher
+	requireUserKeys bool
+	clients         map[string]*Client
+	mu              sync.Mutex
+}
+
+// NewClientPool creates a client pool
+func NewClientPool(primary *Client, store CredentialStore, cipher *vault.Cipher, requireUserKeys bool) *ClientPool {
+	return &ClientPool{
+		primary:         primary,
+		accounts:        map[string]*Client{PrimaryAccount: primary},
+		store:           store,
+		cipher:          cipher,
+		requireUserKeys: requireUserKeys,
+		clients:         make(map[string]*Client),
+	}
+}
+
+// Enabled reports whether per-user credentials are supported
+func (p *ClientPool) Enabled() bool {
+	return p.cipher != nil
+}
+
+// Primary returns the operator account client
+func (p *ClientPool) Primary() *Client {
+	return p.primary
+}
+
+// Cipher returns the credential cipher (nil when per-user keys are disabled)
+func (p *ClientPool) Cipher() *vault.Cipher {
+	return p.cipher
+}
+
+// AddAccount registers a named operator account. Register accounts before
+// serving requests.
+func (p *ClientPool) AddAccount(name string, client *Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.accounts[name] = client
+}
+
+// Account returns the client of a named operator account; "" is main
+func (p *ClientPool) Account(name string) (*Client, error) {
+	if name == "" {
+		return p.primary, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	client, ok := p.accounts[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q (configured: %v)", ErrUnknownAccount, name, p.accountNames())
+	}
+	return client, nil
+}
+
+// Accounts returns the names of the operator accounts, sorted
+func (p *ClientPool) Accounts() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.accountNames()
+}
+
+func (p *ClientPool) accountNames() []string {
+	names := make([]string, 0, len(p.accounts))
+	for name := range p.accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the client for a request: the named operator account when
+// account is set, otherwise the user's account as ForUser picks it
+func (p *ClientPool) Resolve(ctx context.Context, userID, account string) (*Client, error) {
+	if account != "" {
+		return p.Account(account)
+	}
+	return p.ForUser(ctx, userID)
+}
+
+// ForUser returns the client for a user's account
+func (p *ClientPool) ForUser(ctx context.Context, userID string) (*Client, error) {
+	if !p.Enabled() || userID == "" {
+		return p.primary, nil
+	}
+
+	p.mu.Lock()
+	client, ok := p.clients[userID]
+	p.mu.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	creds, err := p.store.GetUserCredentials(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil {
+		if p.requireUserKeys {
+			return nil, fmt.Errorf("no Binance API keys configured for user %s", userID)
+		}
+		return p.primary, nil
+	}
+
+	apiKey, err := p.cipher.Decrypt(creds.APIKeyEncrypted)
+	if err != nil {
+		return nil, err
+	}
+	secretKey, err := p.cipher.Decrypt(creds.SecretEncrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	client = newClientFromKeys(apiKey, secretKey)
+
+	p.mu.Lock()
+	p.clients[userID] = client
+	p.mu.Unlock()
+
+	return client, nil
+}
+
+// ForTrade returns the client of the account a recorded trade was placed
+// on: the operator account, a named account or the user's own keys
+func (p *ClientPool) ForTrade(ctx context.Context, trade *models.Trade) (*Client, error) {
+	switch trade.Account {
+	case "":
+		return p.primary, nil
+	case models.UserAccount(trade.UserID):
+		if !p.Enabled() {
+			return nil, fmt.Errorf("per-user Binance keys are not enabled")
+		}
+		client, err := p.ForUser(ctx, trade.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if p.IsPrimary(client) {
+			return nil, fmt.Errorf("no Binance API keys configured for user %s", trade.UserID)
+		}
+		return client, nil
+	}
+	return p.Account(trade.Account)
+}
+
+// IsPrimary reports whether client is the operator account
+func (p *ClientPool) IsPrimary(client *Client) bool {
+	return client == p.primary
+}
+
+// OperatorSymbols returns the symbols with an open position on the operator
+// account
+func (p *ClientPool) OperatorSymbols(ctx context.Context) ([]string, error) {
+	return p.primary.GetActiveSymbols(ctx)
+}
+
+// UserSymbols returns the symbols with an open position on a user's own
+// account, none when the user trades on the operator account
+func (p *ClientPool) UserSymbols(ctx context.Context, userID string) ([]string, error) {
+	if !p.Enabled() {
+		return nil, nil
+	}
+
+	client, err := p.ForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if p.IsPrimary(client) {
+		return nil, nil
+	}
+	return client.GetActiveSymbols(ctx)
+}
+
+// Invalidate drops a cached client after the user's keys change
+func (p *ClientPool) Invalidate(userID string) {
+	p.mu.Lock()
+	delete(p.clients, userID)
+	p.mu.Unlock()
+}
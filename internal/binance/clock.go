@@ -0,0 +1,217 @@
+package binance
+
+import (
+	"bytes"
+	"context"
+	"crypto-trading-api/internal/latency"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/tracing"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// minResyncGap limits how often -1021 rejections trigger a resync
+const minResyncGap = 10 * time.Second
+
+// serverClock holds the measured offset to Binance server time (server minus
+// local, in ms) and the recvWindow sent with signed requests. It is shared by
+// every client: the primary account, followers and per-user accounts.
+type serverClock struct {
+	offset     atomic.Int64
+	recvWindow atomic.Int64
+	resync     chan struct{}
+}
+
+var clock = &serverClock{resync: make(chan struct{}, 1)}
+
+// SetRecvWindow sets the recvWindow sent with every signed request (0 = Binance default of 5s)
+func SetRecvWindow(window time.Duration) {
+	clock.recvWindow.Store(window.Milliseconds())
+}
+
+// TimeOffset returns the offset to Binance server time (ms) applied to signed requests
+func TimeOffset() int64 {
+	return clock.offset.Load()
+}
+
+// RecvWindow returns the recvWindow (ms) sent with signed requests, 0 when Binance's default applies
+func RecvWindow() int64 {
+	return clock.recvWindow.Load()
+}
+
+// ClockDrift measures how far (ms) the time stamped on signed requests, with
+// the measured offset applied, is from Binance server time right now
+func (b *Client) ClockDrift(ctx context.Context) (int64, error) {
+	requestTime := time.Now().UnixMilli()
+
+	serverTime, err := b.GetBinanceServerTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Compare against the midpoint of the round trip, as SyncTime does
+	localTime := (requestTime + time.Now().UnixMilli()) / 2
+	return serverTime - (localTime + clock.offset.Load()), nil
+}
+
+// localMillis converts a Binance server timestamp (ms) to local time
+func localMillis(serverTime int64) int64 {
+	return serverTime - clock.offset.Load()
+}
+
+// requestResync asks the TimeSync loop to measure the offset again
+func (c *serverClock) requestResync() {
+	select {
+	case c.resync <- struct{}{}:
+	default:
+	}
+}
+
+// signingTransport stamps signed requests with the server-adjusted time and
+// recvWindow, then signs them again. Requests Binance still rejects with
+// -1021 trigger an immediate resync, and orders rejected for a symbol filter
+// reload the symbol rules. Requests are sent with the current key
+// pair, so keys can be rotated without rebuilding the clients.
+type signingTransport struct {
+	keys atomic.Pointer[apiKeyPair]
+	base http.RoundTripper
+}
+
+// apiKeyPair is the API key and secret requests are sent with
+type apiKeyPair struct {
+	apiKey    string
+	secretKey string
+}
+
+// newSigningTransport creates a transport whose signed requests use the
+// shared clock. Every request sent is traced and timed.
+func newSigningTransport(apiKey, secretKey string) *signingTransport {
+	t := &signingTransport{base: tracing.Transport("binance", latency.Transport("binance", httpTransport, true))}
+	t.keys.Store(&apiKeyPair{apiKey: apiKey, secretKey: secretKey})
+	return t
+}
+
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	keys := t.keys.Load()
+	if sent := req.Header.Get("X-MBX-APIKEY"); sent != "" && sent != keys.apiKey {
+		req = req.Clone(req.Context())
+		req.Header.Set("X-MBX-APIKEY", keys.apiKey)
+	}
+
+	query := req.URL.Query()
+	if query.Get("signature") == "" {
+		return t.base.RoundTrip(req)
+	}
+
+	query.Del("signature")
+	query.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli()+clock.offset.Load(), 10))
+	if window := clock.recvWindow.Load(); window > 0 && query.Get("recvWindow") == "" {
+		query.Set("recvWindow", strconv.FormatInt(window, 10))
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Binance signs the query string followed by the body
+	encoded := query.Encode()
+	mac := hmac.New(sha256.New, []byte(keys.secretKey))
+	mac.Write([]byte(encoded))
+	mac.Write(body)
+
+	signed := req.Clone(req.Context())
+	signed.URL.RawQuery = encoded + "&signature=" + hex.EncodeToString(mac.Sum(nil))
+	signed.Body = io.NopCloser(bytes.NewReader(body))
+	signed.ContentLength = int64(len(body))
+
+	resp, err := t.base.RoundTrip(signed)
+	if err != nil || resp.StatusCode != http.StatusBadRequest {
+		return resp, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	code, _ := apiErrorBody(respBody)
+	switch {
+	case code == ErrCodeTimestampOutOfSync:
+		logging.Warn().Msg("Binance rejected a request timestamp (-1021), resyncing clock")
+		clock.requestResync()
+	case filterErrorCodes[code]:
+		symbols.invalidate(code)
+	}
+	return resp, nil
+}
+
+// TimeSync keeps the offset to Binance server time up to date
+type TimeSync struct {
+	client   *Client
+	interval time.Duration
+	stopChan chan struct{}
+}
+
+// NewTimeSync creates a clock synchronizer
+func NewTimeSync(client *Client, interval time.Duration) *TimeSync {
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	return &TimeSync{
+		client:   client,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start measures the offset once, then refreshes it periodically and
+// whenever Binance rejects a timestamp
+func (s *TimeSync) Start() {
+	if _, err := s.client.SyncTime(context.Background()); err != nil {
+		logging.Warn().Err(err).Msg("Time sync failed")
+	}
+	logging.Info().Msgf("Binance time sync started (interval=%v, offset=%dms, recvWindow=%dms)", s.interval, TimeOffset(), RecvWindow())
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		lastSync := time.Now()
+		for {
+			select {
+			case <-ticker.C:
+			case <-clock.resync:
+				if time.Since(lastSync) < minResyncGap {
+					continue
+				}
+			case <-s.stopChan:
+				return
+			}
+
+			if _, err := s.client.SyncTime(context.Background()); err != nil {
+				logging.Warn().Err(err).Msg("Time sync failed")
+			}
+			lastSync = time.Now()
+		}
+	}()
+}
+
+// Stop stops the synchronization loop
+func (s *TimeSync) Stop() {
+	close(s.stopChan)
+}
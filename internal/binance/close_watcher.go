@@ -0,0 +1,178 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/tradehistory"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CloseWatcherStore loads open trades and closes them
+type CloseWatcherStore interface {
+	GetTradesByStatus(ctx context.Context, status string) ([]*models.Trade, error)
+	UpdateTrade(ctx context.Context, trade *models.Trade) error
+}
+
+// CloseWatcher follows position-to-zero transitions of the primary account on
+// the user data stream. When a position is closed outside the API (Binance
+// app, web or another bot), the SL/TP orders of its trades would stay open
+// and could open a reverse position, so they are cancelled and the trades
+// closed right away instead of at the next order reconciliation. Closes the
+// API knows of (SL/TP fills, POST /api/position/close) get their leftover
+// orders cancelled too, without being announced twice.
+type CloseWatcher struct {
+	client  *Client
+	store   CloseWatcherStore
+	bus     *events.Bus
+	grace   time.Duration
+	pending map[string]bool      // Symbols waiting for the grace period
+	closed  map[string]time.Time // Symbol -> last close published by others
+	mu      sync.Mutex
+}
+
+// NewCloseWatcher creates a watcher on the bus of the primary account's user
+// data stream. A position is checked grace (default 3s) after it reaches
+// zero, so closes published by the API and SL/TP fills are seen first.
+func NewCloseWatcher(client *Client, store CloseWatcherStore, bus *events.Bus, grace time.Duration) *CloseWatcher {
+	if grace <= 0 {
+		grace = 3 * time.Second
+	}
+
+	w := &CloseWatcher{
+		client:  client,
+		store:   store,
+		bus:     bus,
+		grace:   grace,
+		pending: make(map[string]bool),
+		closed:  make(map[string]time.Time),
+	}
+	bus.Subscribe(events.TopicAccountUpdated, "close-watcher", func(event events.Event) {
+		for _, pos := range event.(events.AccountUpdated).Positions {
+			if pos.PositionAmt == 0 {
+				w.schedule(pos.Symbol)
+			}
+		}
+	})
+	bus.Subscribe(events.TopicPositionClosed, "close-watcher", func(event events.Event) {
+		if closed := event.(events.PositionClosed); closed.Reason != events.CloseExternal {
+			w.mu.Lock()
+			w.closed[closed.Symbol] = time.Now()
+			w.mu.Unlock()
+		}
+	})
+	return w
+}
+
+// schedule checks a symbol once the grace period has passed
+func (w *CloseWatcher) schedule(symbol string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.pending[symbol] {
+		return
+	}
+	w.pending[symbol] = true
+	zeroAt := time.Now()
+
+	time.AfterFunc(w.grace, func() {
+		w.mu.Lock()
+		delete(w.pending, symbol)
+		w.mu.Unlock()
+		w.check(symbol, zeroAt)
+	})
+}
+
+// check closes the open trades of a symbol whose position is gone
+func (w *CloseWatcher) check(symbol string, zeroAt time.Time) {
+	ctx := tradehistory.WithSource(context.Background(), tradehistory.SourceCloseWatcher)
+
+	var open []*models.Trade
+	for _, status := range []string{"ACTIVE", "FILLED"} {
+		trades, err := w.store.GetTradesByStatus(ctx, status)
+		if err != nil {
+			logging.Warn().Err(err).Str(logging.FieldSymbol, symbol).Msgf("Close watcher: failed to load %s trades", status)
+			return
+		}
+		for _, trade := range trades {
+			// The user data stream belongs to the primary account
+			if trade.Symbol == symbol && trade.Account == "" {
+				open = append(open, trade)
+			}
+		}
+	}
+	if len(open) == 0 {
+		return
+	}
+
+	// The event may be stale: a new position could be open by now
+	positions, err := w.client.GetOpenPositions(ctx)
+	if err != nil {
+		logging.Warn().Err(err).Str(logging.FieldSymbol, symbol).Msg("Close watcher: failed to confirm the position is closed")
+		return
+	}
+	for _, pos := range positions {
+		if pos.Symbol == symbol {
+			return
+		}
+	}
+
+	orders, err := w.client.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		logging.Warn().Err(err).Str(logging.FieldSymbol, symbol).Msg("Close watcher: failed to get open orders")
+		return
+	}
+	openOrders := make(map[int64]bool, len(orders))
+	for _, order := range orders {
+		openOrders[order.OrderID] = true
+	}
+
+	w.mu.Lock()
+	announced := !w.closed[symbol].Before(zeroAt.Add(-w.grace))
+	w.mu.Unlock()
+
+	for _, trade := range open {
+		// A LIMIT entry still resting has no position yet
+		if openOrders[trade.OrderID] {
+			continue
+		}
+		w.closeTrade(ctx, trade, openOrders, announced)
+	}
+}
+
+// closeTrade cancels a trade's SL/TP orders still open and marks it CLOSED.
+// Its realized PnL is left to the PnL reconciler.
+func (w *CloseWatcher) closeTrade(ctx context.Context, trade *models.Trade, openOrders map[int64]bool, announced bool) {
+	ctx = logging.WithTrade(ctx, trade.ID, trade.Symbol)
+
+	for _, orderID := range []int64{trade.SLOrderID, trade.TPOrderID} {
+		if orderID == 0 || !openOrders[orderID] {
+			continue
+		}
+		err := w.client.CancelOrder(ctx, trade.Symbol, orderID)
+		if err != nil && !strings.Contains(err.Error(), strconv.Itoa(ErrCodeUnknownOrder)) {
+			// The order reconciler cancels it later as an orphan
+			logging.Ctx(ctx).Warn().Err(err).Msgf("Close watcher: failed to cancel %s order %d", trade.Symbol, orderID)
+			continue
+		}
+		logging.Ctx(ctx).Info().Msgf("Close watcher: cancelled %s protective order %d", trade.Symbol, orderID)
+	}
+
+	trade.Status = "CLOSED"
+	trade.ClosedAt = time.Now().Unix()
+	if err := w.store.UpdateTrade(ctx, trade); err != nil {
+		logging.Ctx(ctx).Warn().Err(err).Msgf("Close watcher: failed to close trade %s", trade.ID)
+		return
+	}
+
+	if announced {
+		logging.Ctx(ctx).Info().Msgf("Close watcher: trade %s closed", trade.ID)
+		return
+	}
+	logging.Ctx(ctx).Info().Msgf("Close watcher: %s position closed outside the API, trade %s closed", trade.Symbol, trade.ID)
+	w.bus.Publish(events.PositionClosed{Trade: trade, Symbol: trade.Symbol, Reason: events.CloseExternal})
+}
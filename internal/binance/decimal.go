@@ -0,0 +1,49 @@
+package binance
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// Exchange filters are decimal: prices must be whole multiples of the tick
+// size and quantities of the step size. Most of those steps (0.1, 0.001) have
+// no exact binary float, so float rounding can land one ulp off a multiple
+// and the order is rejected. Rounding to a filter is therefore done in
+// decimal arithmetic and orders get the decimal's exact string.
+
+// filterStep parses a tick or step size, falling back to one unit of the
+// given precision when it is unset
+func filterStep(step string, precision int) decimal.Decimal {
+	parsed, err := decimal.NewFromString(step)
+	if err != nil || !parsed.IsPositive() {
+		return decimal.New(1, -int32(precision))
+	}
+	return parsed
+}
+
+// roundToStep rounds a value to the nearest multiple of step
+func roundToStep(value decimal.Decimal, step decimal.Decimal) decimal.Decimal {
+	return value.DivRound(step, 0).Mul(step)
+}
+
+// floorToStepDecimal rounds a value down to a multiple of step
+func floorToStepDecimal(value decimal.Decimal, step decimal.Decimal) decimal.Decimal {
+	return value.Div(step).Floor().Mul(step)
+}
+
+// stepDecimals is the number of decimals a step size needs (0.0010 -> 3)
+func stepDecimals(step decimal.Decimal) int32 {
+	if exp := -step.Exponent(); exp > 0 {
+		// Trailing zeros do not count
+		for exp > 0 && step.Shift(exp-1).IsInteger() {
+			exp--
+		}
+		return exp
+	}
+	return 0
+}
+
+// formatStepped formats a value rounded to step with the symbol's precision,
+// or the step's decimals when it is finer
+func formatStepped(value decimal.Decimal, step decimal.Decimal, precision int) string {
+	return value.StringFixed(max(int32(precision), stepDecimals(step)))
+}
@@ -0,0 +1,107 @@
+package binance
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCalculateQuantity(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      float64
+		price     float64
+		leverage  int
+		precision int
+		step      string
+		want      string
+	}{
+		{name: "rounds to nearest step", size: 100, price: 50000, leverage: 1, precision: 3, step: "0.001", want: "0.002"},
+		{name: "leverage", size: 100, price: 50000, leverage: 10, precision: 3, step: "0.001", want: "0.020"},
+		{name: "no float drift", size: 30, price: 100, leverage: 1, precision: 1, step: "0.1", want: "0.3"},
+		{name: "below one step rounds up", size: 1, price: 50000, leverage: 1, precision: 3, step: "0.001", want: "0.001"},
+		{name: "whole step", size: 1000, price: 3, leverage: 1, precision: 0, step: "1", want: "333"},
+		{name: "coarse step", size: 1234, price: 1, leverage: 1, precision: 0, step: "10", want: "1230"},
+		{name: "step finer than precision", size: 100, price: 3, leverage: 1, precision: 1, step: "0.01", want: "33.33"},
+		{name: "missing step uses precision", size: 100, price: 3, leverage: 1, precision: 2, step: "", want: "33.33"},
+	}
+
+	b := &Client{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.calculateQuantity(tt.size, tt.price, tt.leverage, tt.precision, tt.step); got != tt.want {
+				t.Errorf("calculateQuantity = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		price     float64
+		tick      string
+		precision int
+		want      string
+	}{
+		{name: "rounds to tick", price: 50000.123, tick: "0.10", precision: 2, want: "50000.10"},
+		{name: "rounds up", price: 50000.16, tick: "0.10", precision: 1, want: "50000.2"},
+		{name: "no float drift", price: 0.3, tick: "0.1", precision: 1, want: "0.3"},
+		{name: "half tick", price: 1.2345, tick: "0.0005", precision: 4, want: "1.2345"},
+		{name: "missing tick uses precision", price: 1.23456, tick: "", precision: 3, want: "1.235"},
+	}
+
+	b := &Client{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.formatPrice(tt.price, tt.tick, tt.precision); got != tt.want {
+				t.Errorf("formatPrice = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFloorToStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity float64
+		step     string
+		want     string
+	}{
+		{name: "rounds down", quantity: 0.0019, step: "0.001", want: "0.001"},
+		{name: "exact multiple kept", quantity: 0.3, step: "0.1", want: "0.3"},
+		{name: "one ulp under a multiple", quantity: 0.1 + 0.2, step: "0.1", want: "0.3"},
+		{name: "below one step", quantity: 0.0004, step: "0.001", want: "0.000"},
+		{name: "whole step", quantity: 12.9, step: "1", want: "12"},
+		{name: "no step", quantity: 1.25, step: "0", want: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := decimal.RequireFromString(tt.step)
+			if got := formatStepped(floorToStep(tt.quantity, step), step, 0); got != tt.want {
+				t.Errorf("floorToStep = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepDecimals(t *testing.T) {
+	tests := []struct {
+		step string
+		want int32
+	}{
+		{"1", 0},
+		{"10", 0},
+		{"0.1", 1},
+		{"0.001", 3},
+		{"0.0010", 3},
+		{"0.00500000", 3},
+	}
+
+	for _, tt := range tests {
+		if got := stepDecimals(decimal.RequireFromString(tt.step)); got != tt.want {
+			t.Errorf("stepDecimals(%s) = %d, want %d", tt.step, got, tt.want)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package binance
 
 import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"errors"
 	"fmt"
-	"log"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -26,11 +30,17 @@ const (
 	ErrCodeUnauthorized          = -2015
 	ErrCodeInsufficientBalance   = -2010
 	ErrCodeMarginInsufficient    = -2019
-	ErrCodePositionSideInvalid   = -4164
+	ErrCodePositionSideInvalid   = -4061
+	ErrCodeMinNotional           = -4164
 	ErrCodeRateLimitExceeded     = -1003
 	ErrCodeIPBanned              = -1003
 	ErrCodeOrderWouldTrigger     = -2021
 	ErrCodeReduceOnlyReject      = -2022
+	ErrCodeMaxPositionAtLeverage = -2027 // Position above the maximum at the leverage
+	ErrCodeOpenOrdersExist       = -4047 // Margin type change with open orders
+	ErrCodePositionExists        = -4048 // Margin type change with an open position
+	ErrCodeIsolatedLeverageCut   = -4161 // Leverage lowered on an open isolated position
+	ErrCodeUnknownOrder          = -2011 // Cancelled order already filled, cancelled or expired
 )
 
 // RetryConfig configures retry behavior
@@ -51,8 +61,9 @@ func DefaultRetryConfig() *RetryConfig {
 	}
 }
 
-// ExecuteWithRetry executes a function with retry logic
-func ExecuteWithRetry(fn func() error, config *RetryConfig) error {
+// ExecuteWithRetry executes a function with retry logic, giving up early
+// when ctx is done
+func ExecuteWithRetry(ctx context.Context, fn func() error, config *RetryConfig) error {
 	if config == nil {
 		config = DefaultRetryConfig()
 	}
@@ -79,10 +90,16 @@ func ExecuteWithRetry(fn func() error, config *RetryConfig) error {
 		}
 
 		// Log retry attempt
-		log.Printf("⚠️ Retry %d/%d after %v: %v", attempt+1, config.MaxRetries, backoff, err)
+		logging.Warn().Err(err).Msgf("Retry %d/%d after %v", attempt+1, config.MaxRetries, backoff)
 
 		// Sleep with exponential backoff
-		time.Sleep(backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
 
 		// Increase backoff
 		backoff = time.Duration(float64(backoff) * config.BackoffFactor)
@@ -100,52 +117,73 @@ func isRetryableError(err error) bool {
 		return false
 	}
 
+	// Classified Binance errors say whether they are worth retrying
+	var binanceErr *BinanceError
+	if errors.As(err, &binanceErr) {
+		return binanceErr.Retry
+	}
+
 	errStr := strings.ToLower(err.Error())
 
 	// Rate limit errors (429)
 	if strings.Contains(errStr, "429") || strings.Contains(errStr, "too many requests") {
-		log.Println("🚦 Rate limit hit, backing off...")
+		logging.Warn().Msg("Rate limit hit, backing off...")
 		return true
 	}
 
 	// Timeout errors
 	if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "deadline exceeded") {
-		log.Println("⏱️ Timeout error, retrying...")
+		logging.Warn().Msg("Timeout error, retrying...")
 		return true
 	}
 
 	// Connection errors
 	if strings.Contains(errStr, "connection") || strings.Contains(errStr, "eof") {
-		log.Println("🔌 Connection error, retrying...")
+		logging.Warn().Msg("Connection error, retrying...")
 		return true
 	}
 
 	// Temporary server errors (5xx)
 	if strings.Contains(errStr, "500") || strings.Contains(errStr, "502") ||
 		strings.Contains(errStr, "503") || strings.Contains(errStr, "504") {
-		log.Println("🔧 Server error, retrying...")
+		logging.Warn().Msg("Server error, retrying...")
 		return true
 	}
 
 	// Binance specific retryable errors
 	if strings.Contains(errStr, "-1003") { // Rate limit
-		log.Println("🚦 Binance rate limit, backing off...")
+		logging.Warn().Msg("Binance rate limit, backing off...")
 		return true
 	}
 
 	return false
 }
 
-// HandleBinanceError handles specific Binance error codes
+// apiErrorCode finds the code in a Binance API error or BinanceError, also
+// when wrapped in a message ("<APIError> code=-2019, msg=Margin is insufficient.")
+var apiErrorCode = regexp.MustCompile(`(?:code=|Binance Error )(-\d+)`)
+
+// HandleBinanceError handles specific Binance error codes. Errors from
+// Binance without a known code keep their code and message.
 func HandleBinanceError(err error) error {
 	if err == nil {
 		return nil
 	}
 
+	var binanceErr *BinanceError
+	if errors.As(err, &binanceErr) {
+		return binanceErr
+	}
+
 	errStr := err.Error()
+	lower := strings.ToLower(errStr)
+	code := 0
+	if match := apiErrorCode.FindStringSubmatch(errStr); match != nil {
+		code, _ = strconv.Atoi(match[1])
+	}
 
 	// Timestamp sync error
-	if strings.Contains(errStr, "-1021") || strings.Contains(errStr, "timestamp") {
+	if code == ErrCodeTimestampOutOfSync || strings.Contains(lower, "outside of the recvwindow") {
 		return &BinanceError{
 			Code:    ErrCodeTimestampOutOfSync,
 			Message: "Timestamp out of sync with server. Please sync your system clock or use NTP.",
@@ -154,7 +192,7 @@ func HandleBinanceError(err error) error {
 	}
 
 	// Invalid signature
-	if strings.Contains(errStr, "-1022") || strings.Contains(errStr, "signature") {
+	if code == ErrCodeInvalidSignature || strings.Contains(lower, "signature for this request is not valid") {
 		return &BinanceError{
 			Code:    ErrCodeInvalidSignature,
 			Message: "Invalid API signature. Check your API secret key.",
@@ -162,8 +200,17 @@ func HandleBinanceError(err error) error {
 		}
 	}
 
+	// Rejected API key
+	if code == ErrCodeUnauthorized {
+		return &BinanceError{
+			Code:    ErrCodeUnauthorized,
+			Message: "Invalid API key, IP or permissions for this action.",
+			Retry:   false,
+		}
+	}
+
 	// Insufficient balance
-	if strings.Contains(errStr, "-2010") || strings.Contains(errStr, "insufficient balance") {
+	if code == ErrCodeInsufficientBalance || strings.Contains(lower, "insufficient balance") {
 		return &BinanceError{
 			Code:    ErrCodeInsufficientBalance,
 			Message: "Insufficient balance to execute this order.",
@@ -172,7 +219,7 @@ func HandleBinanceError(err error) error {
 	}
 
 	// Margin insufficient
-	if strings.Contains(errStr, "-2019") || strings.Contains(errStr, "margin") {
+	if code == ErrCodeMarginInsufficient || strings.Contains(lower, "margin is insufficient") {
 		return &BinanceError{
 			Code:    ErrCodeMarginInsufficient,
 			Message: "Insufficient margin. Reduce position size or add more margin.",
@@ -181,7 +228,7 @@ func HandleBinanceError(err error) error {
 	}
 
 	// Position side invalid
-	if strings.Contains(errStr, "-4164") {
+	if code == ErrCodePositionSideInvalid {
 		return &BinanceError{
 			Code:    ErrCodePositionSideInvalid,
 			Message: "Position side does not match. Check your position mode (One-way/Hedge).",
@@ -189,17 +236,17 @@ func HandleBinanceError(err error) error {
 		}
 	}
 
-	// Rate limit
-	if strings.Contains(errStr, "-1003") || strings.Contains(errStr, "429") {
+	// Order value below the symbol's minimum
+	if code == ErrCodeMinNotional {
 		return &BinanceError{
-			Code:    ErrCodeRateLimitExceeded,
-			Message: "Rate limit exceeded. Backing off...",
-			Retry:   true,
+			Code:    ErrCodeMinNotional,
+			Message: "Order value is below the minimum notional. Increase size or leverage.",
+			Retry:   false,
 		}
 	}
 
-	// IP banned (418)
-	if strings.Contains(errStr, "418") {
+	// IP banned (418), also code -1003 so checked before the rate limit
+	if strings.Contains(lower, "status code 418") || strings.Contains(lower, "way too many requests") {
 		return &BinanceError{
 			Code:    ErrCodeIPBanned,
 			Message: "IP has been auto-banned for continuing to send requests after 429. Stop all trading immediately.",
@@ -207,8 +254,17 @@ func HandleBinanceError(err error) error {
 		}
 	}
 
+	// Rate limit
+	if code == ErrCodeRateLimitExceeded || strings.Contains(lower, "429") || strings.Contains(lower, "too many requests") {
+		return &BinanceError{
+			Code:    ErrCodeRateLimitExceeded,
+			Message: "Rate limit exceeded. Backing off...",
+			Retry:   true,
+		}
+	}
+
 	// Order would trigger immediately
-	if strings.Contains(errStr, "-2021") {
+	if code == ErrCodeOrderWouldTrigger {
 		return &BinanceError{
 			Code:    ErrCodeOrderWouldTrigger,
 			Message: "Order would trigger immediately. Adjust your stop price.",
@@ -217,7 +273,7 @@ func HandleBinanceError(err error) error {
 	}
 
 	// Reduce-only rejected
-	if strings.Contains(errStr, "-2022") {
+	if code == ErrCodeReduceOnlyReject {
 		return &BinanceError{
 			Code:    ErrCodeReduceOnlyReject,
 			Message: "Reduce-only order rejected. This order would increase your position.",
@@ -225,6 +281,15 @@ func HandleBinanceError(err error) error {
 		}
 	}
 
+	// Any other Binance rejection
+	if code != 0 {
+		message := errStr
+		if _, msg, ok := strings.Cut(errStr, "msg="); ok {
+			message = msg
+		}
+		return &BinanceError{Code: code, Message: message, Retry: false}
+	}
+
 	return err
 }
 
@@ -236,20 +301,16 @@ func LogBinanceError(err error) {
 
 	binanceErr, ok := err.(*BinanceError)
 	if !ok {
-		log.Printf("❌ Error: %v", err)
+		logging.Error().Err(err).Msg("Binance request failed")
 		return
 	}
 
-	emoji := "❌"
-	if binanceErr.Code == ErrCodeRateLimitExceeded {
-		emoji = "🚦"
-	} else if binanceErr.Code == ErrCodeTimestampOutOfSync {
-		emoji = "⏰"
-	} else if binanceErr.Code == ErrCodeInsufficientBalance {
-		emoji = "💰"
+	event := logging.Error()
+	if binanceErr.Code == ErrCodeRateLimitExceeded || binanceErr.Code == ErrCodeTimestampOutOfSync {
+		event = logging.Warn()
 	}
 
-	log.Printf("%s Binance Error [%d]: %s", emoji, binanceErr.Code, binanceErr.Message)
+	event.Int("code", binanceErr.Code).Msgf("Binance Error [%d]: %s", binanceErr.Code, binanceErr.Message)
 }
 
 // GetErrorSuggestion provides a suggestion for fixing the error
@@ -265,7 +326,8 @@ func GetErrorSuggestion(err error) string {
 		"-1022": "Verify your BINANCE_SECRET_KEY environment variable is correct",
 		"-2010": "Check your account balance and reduce position size",
 		"-2019": "Add more margin to your account or reduce leverage",
-		"-4164": "Switch between One-way and Hedge mode in Binance settings",
+		"-4061": "Switch between One-way and Hedge mode in Binance settings",
+		"-4164": "Increase the order size or leverage above the symbol's minimum notional",
 		"429":   "Wait before sending more requests. Consider using WebSocket for real-time data",
 		"418":   "CRITICAL: Stop all trading. Your IP is banned. Contact Binance support",
 	}
@@ -278,72 +340,3 @@ func GetErrorSuggestion(err error) string {
 
 	return "Check Binance API documentation for more details"
 }
-
-// CircuitBreaker implements circuit breaker pattern
-type CircuitBreaker struct {
-	maxFailures     int
-	resetTimeout    time.Duration
-	failures        int
-	lastFailureTime time.Time
-	state           string // "closed", "open", "half-open"
-}
-
-// NewCircuitBreaker creates a new circuit breaker
-func NewCircuitBreaker(maxFailures int, resetTimeout time.Duration) *CircuitBreaker {
-	return &CircuitBreaker{
-		maxFailures:  maxFailures,
-		resetTimeout: resetTimeout,
-		state:        "closed",
-	}
-}
-
-// Execute executes a function with circuit breaker protection
-func (cb *CircuitBreaker) Execute(fn func() error) error {
-	// Check if circuit should be reset
-	if cb.state == "open" && time.Since(cb.lastFailureTime) > cb.resetTimeout {
-		cb.state = "half-open"
-		cb.failures = 0
-		log.Println("🔄 Circuit breaker: half-open (testing)")
-	}
-
-	// Block if circuit is open
-	if cb.state == "open" {
-		return fmt.Errorf("circuit breaker is open, rejecting request")
-	}
-
-	// Execute function
-	err := fn()
-
-	if err != nil {
-		cb.failures++
-		cb.lastFailureTime = time.Now()
-
-		if cb.failures >= cb.maxFailures {
-			cb.state = "open"
-			log.Printf("⚠️ Circuit breaker: OPEN (too many failures: %d)", cb.failures)
-		}
-
-		return err
-	}
-
-	// Success - reset circuit
-	if cb.state == "half-open" {
-		cb.state = "closed"
-		log.Println("✅ Circuit breaker: closed (recovered)")
-	}
-	cb.failures = 0
-
-	return nil
-}
-
-// GetState returns the current circuit breaker state
-func (cb *CircuitBreaker) GetState() string {
-	return cb.state
-}
-
-// Reset manually resets the circuit breaker
-func (cb *CircuitBreaker) Reset() {
-	cb.state = "closed"
-	cb.failures = 0
-	log.Println("🔄 Circuit breaker manually reset")
-}
@@ -0,0 +1,322 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/tradehistory"
+	"math"
+	"sync"
+	"time"
+)
+
+// ExcursionStore loads open trades and saves their excursions
+type ExcursionStore interface {
+	GetTrade(ctx context.Context, tradeID string) (*models.Trade, error)
+	GetTradesByStatus(ctx context.Context, status string) ([]*models.Trade, error)
+	UpdateTrade(ctx context.Context, trade *models.Trade) error
+}
+
+// ExcursionTracker follows the unrealized PnL of open trades on the shared
+// mark price feed and records the worst (maximum adverse excursion, MAE) and
+// best (maximum favorable excursion, MFE) on each trade when it closes, so
+// stops and targets can be compared with how far prices actually went.
+// Trades are picked up when opened and by a periodic reload of the open
+// trades, which also finishes trades closed without an event. Excursions are
+// merged with the stored ones, so restarts keep what was saved on shutdown.
+type ExcursionTracker struct {
+	feed        *PriceFeed
+	store       ExcursionStore
+	interval    time.Duration
+	running     bool
+	trades      map[string]*excursion
+	closed      map[string]bool   // Closed by event but maybe not yet in the store
+	unsubscribe map[string]func() // Symbol -> price feed subscription
+	stopChan    chan struct{}
+	mu          sync.Mutex
+}
+
+// excursion is the price path of one open trade
+type excursion struct {
+	symbol    string
+	direction float64 // 1 long, -1 short
+	quantity  float64
+	entry     float64
+	filled    bool // A LIMIT entry counts once the mark price reaches it
+
+	adverse        float64 // Worst unrealized PnL (<= 0)
+	favorable      float64 // Best unrealized PnL (>= 0)
+	adversePrice   float64
+	favorablePrice float64
+	changed        bool // Not saved yet
+}
+
+// NewExcursionTracker creates a tracker and follows trade opens and position
+// closes on the bus. Trades are reloaded every interval (default 1m).
+func NewExcursionTracker(feed *PriceFeed, store ExcursionStore, bus *events.Bus, interval time.Duration) *ExcursionTracker {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	t := &ExcursionTracker{
+		feed:        feed,
+		store:       store,
+		interval:    interval,
+		trades:      make(map[string]*excursion),
+		closed:      make(map[string]bool),
+		unsubscribe: make(map[string]func()),
+		stopChan:    make(chan struct{}),
+	}
+	bus.Subscribe(events.TopicTradeOpened, "excursions", func(event events.Event) {
+		t.track(event.(events.TradeOpened).Trade)
+	})
+	bus.Subscribe(events.TopicPositionClosed, "excursions", func(event events.Event) {
+		if trade := event.(events.PositionClosed).Trade; trade != nil {
+			t.mu.Lock()
+			t.closed[trade.ID] = true
+			t.mu.Unlock()
+			t.finish(trade.ID)
+		}
+	})
+	return t
+}
+
+// Start loads the open trades and reloads them every interval. It may be
+// started again after Stop.
+func (t *ExcursionTracker) Start() {
+	stop := make(chan struct{})
+	t.mu.Lock()
+	t.running = true
+	t.stopChan = stop
+	t.mu.Unlock()
+
+	t.sync()
+	logging.Info().Msgf("Excursion tracker started (%d open trades)", t.count())
+
+	go func() {
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				t.sync()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop saves the excursions of the open trades and stops following prices
+func (t *ExcursionTracker) Stop() {
+	t.mu.Lock()
+	if !t.running {
+		t.mu.Unlock()
+		return
+	}
+	t.running = false
+	close(t.stopChan)
+	ids := make([]string, 0, len(t.trades))
+	for id := range t.trades {
+		ids = append(ids, id)
+	}
+	t.mu.Unlock()
+
+	for _, id := range ids {
+		t.finish(id)
+	}
+	logging.Info().Msg("Excursion tracker stopped")
+}
+
+// count returns the number of trades followed
+func (t *ExcursionTracker) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.trades)
+}
+
+// sync starts following open trades not followed yet and finishes followed
+// trades that are no longer open
+func (t *ExcursionTracker) sync() {
+	ctx := context.Background()
+
+	open := make(map[string]*models.Trade)
+	for _, status := range []string{"ACTIVE", "FILLED"} {
+		trades, err := t.store.GetTradesByStatus(ctx, status)
+		if err != nil {
+			logging.Warn().Err(err).Msgf("Excursion tracker: failed to load %s trades", status)
+			return
+		}
+		for _, trade := range trades {
+			open[trade.ID] = trade
+		}
+	}
+
+	t.mu.Lock()
+	var gone []string
+	for id := range t.trades {
+		if _, ok := open[id]; !ok {
+			gone = append(gone, id)
+		}
+	}
+	// SL/TP fills publish the close before the trade's status is updated
+	for id := range t.closed {
+		if _, ok := open[id]; ok {
+			delete(open, id)
+		} else {
+			delete(t.closed, id)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, trade := range open {
+		t.track(trade)
+	}
+	for _, id := range gone {
+		t.finish(id)
+	}
+}
+
+// track starts following a trade, or refreshes the entry of one followed
+// already (a fill sets the executed price and status)
+func (t *ExcursionTracker) track(trade *models.Trade) {
+	entry := trade.ExecutedPrice
+	if entry <= 0 {
+		entry = trade.EntryPrice
+	}
+	if entry <= 0 || trade.Size <= 0 || trade.Leverage <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.running || t.closed[trade.ID] {
+		return
+	}
+	if ex, ok := t.trades[trade.ID]; ok {
+		ex.entry = entry
+		ex.filled = ex.filled || trade.Status == "FILLED"
+		return
+	}
+
+	ex := &excursion{
+		symbol:         trade.Symbol,
+		direction:      1,
+		quantity:       trade.Size * float64(trade.Leverage) / entry,
+		entry:          entry,
+		filled:         trade.OrderType != "LIMIT" || trade.Status == "FILLED",
+		adverse:        trade.MaxAdverseExcursion,
+		favorable:      trade.MaxFavorableExcursion,
+		adversePrice:   trade.MaxAdversePrice,
+		favorablePrice: trade.MaxFavorablePrice,
+	}
+	if trade.Side == "SELL" {
+		ex.direction = -1
+	}
+	t.trades[trade.ID] = ex
+
+	if _, subscribed := t.unsubscribe[trade.Symbol]; !subscribed {
+		symbol := trade.Symbol
+		t.unsubscribe[symbol] = t.feed.Subscribe(symbol, t.onPrice)
+	}
+}
+
+// onPrice moves the excursions of the symbol's trades
+func (t *ExcursionTracker) onPrice(symbol string, price float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, ex := range t.trades {
+		if ex.symbol != symbol {
+			continue
+		}
+		if !ex.filled {
+			// A LIMIT entry fills once the price trades through it
+			if ex.direction*(price-ex.entry) > 0 {
+				continue
+			}
+			ex.filled = true
+		}
+
+		pnl := ex.direction * ex.quantity * (price - ex.entry)
+		if pnl < ex.adverse {
+			ex.adverse, ex.adversePrice, ex.changed = pnl, price, true
+		}
+		if pnl > ex.favorable {
+			ex.favorable, ex.favorablePrice, ex.changed = pnl, price, true
+		}
+	}
+}
+
+// finish stops following a trade and saves its excursions
+func (t *ExcursionTracker) finish(tradeID string) {
+	t.mu.Lock()
+	ex, ok := t.trades[tradeID]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	delete(t.trades, tradeID)
+	t.releaseSymbol(ex.symbol)
+	t.mu.Unlock()
+
+	if ex.changed {
+		t.save(tradeID, ex)
+	}
+}
+
+// releaseSymbol ends a symbol's price subscription once no followed trade
+// uses it (t.mu held)
+func (t *ExcursionTracker) releaseSymbol(symbol string) {
+	for _, ex := range t.trades {
+		if ex.symbol == symbol {
+			return
+		}
+	}
+	if unsubscribe, ok := t.unsubscribe[symbol]; ok {
+		unsubscribe()
+		delete(t.unsubscribe, symbol)
+	}
+}
+
+// save merges a trade's excursions into the stored ones. The trade is read
+// just before the write, so changes made by whatever closed it are kept.
+func (t *ExcursionTracker) save(tradeID string, ex *excursion) {
+	ctx := tradehistory.WithSource(context.Background(), tradehistory.SourceExcursions)
+
+	trade, err := t.store.GetTrade(ctx, tradeID)
+	if err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, tradeID).Msgf("Excursion tracker: trade %s not found", tradeID)
+		return
+	}
+
+	changed := false
+	if ex.adverse < trade.MaxAdverseExcursion {
+		trade.MaxAdverseExcursion = roundExcursion(ex.adverse)
+		trade.MaxAdversePrice = ex.adversePrice
+		changed = true
+	}
+	if ex.favorable > trade.MaxFavorableExcursion {
+		trade.MaxFavorableExcursion = roundExcursion(ex.favorable)
+		trade.MaxFavorablePrice = ex.favorablePrice
+		changed = true
+	}
+	if !changed {
+		return
+	}
+
+	if err := t.store.UpdateTrade(ctx, trade); err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, tradeID).Msgf("Excursion tracker: failed to save excursions of trade %s", tradeID)
+		return
+	}
+	logging.Debug().Str(logging.FieldTradeID, tradeID).Msgf("Excursions of trade %s: MAE %.2f, MFE %.2f",
+		tradeID, trade.MaxAdverseExcursion, trade.MaxFavorableExcursion)
+}
+
+// roundExcursion rounds an excursion to 8 decimals (USDT)
+func roundExcursion(value float64) float64 {
+	return math.Round(value*1e8) / 1e8
+}
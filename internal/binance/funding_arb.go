@@ -0,0 +1,457 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/policy"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Funding arbitrage directions
+const (
+	ArbDirectionShortPerp = "LONG_SPOT_SHORT_PERP" // Positive funding: shorts are paid
+	ArbDirectionLongPerp  = "SHORT_SPOT_LONG_PERP" // Negative funding: longs are paid (needs margin borrowing, not automated)
+)
+
+// FundingOpportunity is a perpetual with a funding rate worth harvesting
+type FundingOpportunity struct {
+	Symbol            string  `json:"symbol"`
+	FundingRate       float64 `json:"fundingRate"`
+	PredictedRate     float64 `json:"predictedRate"`     // Estimated from the mark/index premium
+	AnnualizedPercent float64 `json:"annualizedPercent"` // Funding rate * periods per year * 100
+	MarkPrice         float64 `json:"markPrice"`
+	QuoteVolume       float64 `json:"quoteVolume"` // Last 24h
+	NextFundingTime   int64   `json:"nextFundingTime"`
+	SpotAvailable     bool    `json:"spotAvailable"` // A TRADING spot market exists for the hedge leg
+	Direction         string  `json:"direction"`
+}
+
+// FundingArbStore persists funding arbitrage groups
+type FundingArbStore interface {
+	SaveFundingArbGroup(ctx context.Context, group *models.FundingArbGroup) error
+	GetFundingArbGroups(ctx context.Context) ([]*models.FundingArbGroup, error)
+}
+
+// FundingArbConfig configures the funding arbitrage bot
+type FundingArbConfig struct {
+	MinRate   float64       // Open pairs when the funding rate is at least this (e.g. 0.0005 = 0.05%)
+	MinVolume float64       // Only open pairs on perpetuals with at least this 24h quote volume
+	ExitRate  float64       // Close pairs when the funding rate drops below this
+	Notional  float64       // USDT per leg
+	Leverage  int           // Perp leg leverage
+	MaxGroups int           // Maximum concurrently open pairs
+	Interval  time.Duration // How often rates are checked
+	UserID    string        // Owner of bot-opened groups
+}
+
+// FundingArbitrage opens and closes delta-neutral spot+perp pairs
+// (long spot, short perp) that collect positive funding
+type FundingArbitrage struct {
+	client   *Client
+	store    FundingArbStore
+	pause    *policy.TradingPause
+	config   FundingArbConfig
+	mu       sync.Mutex // Serializes opening and closing
+	stopChan chan struct{}
+}
+
+// NewFundingArbitrage creates the funding arbitrage helper
+func NewFundingArbitrage(client *Client, store FundingArbStore, pause *policy.TradingPause, config FundingArbConfig) *FundingArbitrage {
+	if config.Interval <= 0 {
+		config.Interval = 15 * time.Minute
+	}
+	if config.Leverage <= 0 {
+		config.Leverage = 1
+	}
+	if config.MaxGroups <= 0 {
+		config.MaxGroups = 1
+	}
+
+	return &FundingArbitrage{
+		client:   client,
+		store:    store,
+		pause:    pause,
+		config:   config,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// GetAllFundingRates - Get the current funding rate of every perpetual
+func (b *Client) GetAllFundingRates(ctx context.Context) ([]*FundingRateInfo, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	premiumIndex, err := b.client.NewPremiumIndexService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get funding rates: %v", err)
+	}
+
+	rates := make([]*FundingRateInfo, 0, len(premiumIndex))
+	for _, p := range premiumIndex {
+		fundingRate, _ := strconv.ParseFloat(p.LastFundingRate, 64)
+		markPrice, _ := strconv.ParseFloat(p.MarkPrice, 64)
+		rates = append(rates, &FundingRateInfo{
+			Symbol:          p.Symbol,
+			FundingRate:     fundingRate,
+			FundingTime:     p.Time,
+			NextFundingTime: p.NextFundingTime,
+			MarkPrice:       markPrice,
+			IndexPrice:      markPrice,
+		})
+	}
+
+	return rates, nil
+}
+
+// Opportunities lists perpetuals whose absolute funding rate is at least
+// minRate and 24h quote volume at least the bot's MinVolume, most extreme
+// first (limit <= 0 returns all)
+func (a *FundingArbitrage) Opportunities(ctx context.Context, minRate float64, limit int) ([]*FundingOpportunity, error) {
+	rates, err := a.client.ScreenFunding(ctx, FundingScreenFilter{
+		MinQuoteVolume: a.config.MinVolume,
+		MinRate:        minRate,
+		SortBy:         FundingSortRate,
+		Limit:          limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	spotSymbols, err := a.client.GetSpotSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	opportunities := []*FundingOpportunity{}
+	for _, rate := range rates {
+		direction := ArbDirectionShortPerp
+		if rate.FundingRate < 0 {
+			direction = ArbDirectionLongPerp
+		}
+		spot, ok := spotSymbols[rate.Symbol]
+
+		opportunities = append(opportunities, &FundingOpportunity{
+			Symbol:            rate.Symbol,
+			FundingRate:       rate.FundingRate,
+			PredictedRate:     rate.PredictedRate,
+			AnnualizedPercent: rate.AnnualizedPercent,
+			MarkPrice:         rate.MarkPrice,
+			QuoteVolume:       rate.QuoteVolume,
+			NextFundingTime:   rate.NextFundingTime,
+			SpotAvailable:     ok && spot.Trading,
+			Direction:         direction,
+		})
+	}
+
+	return opportunities, nil
+}
+
+// Open buys notional USDT of spot and shorts the same quantity on the perp.
+// If the perp leg fails the spot leg is sold back and the group is FAILED.
+func (a *FundingArbitrage) Open(ctx context.Context, userID, symbol string, notional float64, leverage int, openedBy string) (*models.FundingArbGroup, error) {
+	if err := a.pause.Check(); err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if leverage <= 0 {
+		leverage = 1
+	}
+
+	funding, err := a.client.GetFundingRate(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if funding.FundingRate <= 0 {
+		return nil, fmt.Errorf("funding rate for %s is %.4f%%; only positive funding (long spot, short perp) is supported", symbol, funding.FundingRate*100)
+	}
+
+	perpInfo, err := a.client.getSymbolInfo(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol info: %v", err)
+	}
+	spotInfo, err := a.client.GetSpotSymbol(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if !spotInfo.Trading {
+		return nil, fmt.Errorf("spot market %s is not trading", symbol)
+	}
+
+	perpStep := filterStep(perpInfo.StepSize, perpInfo.QuantityPrecision)
+	perpMin, _ := decimal.NewFromString(perpInfo.MinQuantity)
+	spotStep := decimal.NewFromFloat(spotInfo.StepSize)
+	quantity := floorToStep(notional/funding.MarkPrice, decimal.Max(perpStep, spotStep))
+	if !quantity.IsPositive() || quantity.LessThan(perpMin) || quantity.LessThan(decimal.NewFromFloat(spotInfo.MinQty)) {
+		return nil, fmt.Errorf("notional %.2f USDT is below the minimum order size for %s", notional, symbol)
+	}
+
+	group := &models.FundingArbGroup{
+		ID:               uuid.New().String(),
+		UserID:           userID,
+		Symbol:           symbol,
+		Notional:         notional,
+		Leverage:         leverage,
+		EntryFundingRate: funding.FundingRate,
+		SpotLeg:          models.ArbLeg{Market: "SPOT", Side: "BUY"},
+		PerpLeg:          models.ArbLeg{Market: "PERP", Side: "SELL"},
+		Status:           models.ArbStatusOpen,
+		OpenedBy:         openedBy,
+		OpenedAt:         time.Now().Unix(),
+	}
+
+	if err := a.client.ensureLeverage(ctx, symbol, leverage); err != nil {
+		return nil, fmt.Errorf("failed to set leverage: %v", err)
+	}
+
+	// 1. Spot leg
+	spotOrder, err := a.client.PlaceSpotMarketOrder(ctx, symbol, "BUY", formatStepped(quantity, spotStep, 0))
+	if err != nil {
+		return a.fail(ctx, group, err)
+	}
+	group.SpotLeg.OrderID = spotOrder.OrderID
+	group.SpotLeg.AvgPrice = spotOrder.AvgPrice
+	group.SpotLeg.Commission = spotOrder.Commission
+	group.SpotLeg.CommissionAsset = spotOrder.CommissionAsset
+	group.SpotLeg.Quantity = spotOrder.ExecutedQty
+	if spotOrder.CommissionAsset == spotInfo.BaseAsset {
+		// Fee taken from the bought coins; only the remainder can be hedged and sold
+		group.SpotLeg.Quantity -= spotOrder.Commission
+	}
+
+	// 2. Perp leg, hedging what the spot leg actually holds
+	perpQty := floorToStep(group.SpotLeg.Quantity, perpStep)
+	perpOrder, err := a.client.placePerpMarketOrder(ctx, symbol, futures.SideTypeSell, formatStepped(perpQty, perpStep, perpInfo.QuantityPrecision), false)
+	if err != nil {
+		// Unwind the spot leg so no directional exposure is left behind
+		if _, unwindErr := a.client.PlaceSpotMarketOrder(ctx, symbol, "SELL", formatStepped(floorToStep(group.SpotLeg.Quantity, spotStep), spotStep, 0)); unwindErr != nil {
+			err = fmt.Errorf("%v (spot unwind also failed: %v)", err, unwindErr)
+		}
+		return a.fail(ctx, group, err)
+	}
+	group.PerpLeg.OrderID = perpOrder.OrderID
+	group.PerpLeg.Quantity = perpQty.InexactFloat64()
+	group.PerpLeg.AvgPrice, _ = strconv.ParseFloat(perpOrder.AvgPrice, 64)
+
+	if err := a.store.SaveFundingArbGroup(ctx, group); err != nil {
+		return group, err
+	}
+
+	logging.Info().Msgf("Funding arbitrage opened on %s: %.6f long spot / short perp (funding %.4f%%)",
+		symbol, group.PerpLeg.Quantity, funding.FundingRate*100)
+	return group, nil
+}
+
+// Close buys back the perp leg and sells the spot leg. A perp leg that was
+// already closed by a previous partial attempt is skipped.
+func (a *FundingArbitrage) Close(ctx context.Context, group *models.FundingArbGroup) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if group.Status != models.ArbStatusOpen {
+		return fmt.Errorf("group is %s", group.Status)
+	}
+
+	if group.PerpLeg.CloseOrderID == 0 {
+		perpInfo, err := a.client.getSymbolInfo(ctx, group.Symbol)
+		if err != nil {
+			return fmt.Errorf("failed to get symbol info: %v", err)
+		}
+		perpStep := filterStep(perpInfo.StepSize, perpInfo.QuantityPrecision)
+		qty := formatStepped(floorToStep(group.PerpLeg.Quantity, perpStep), perpStep, perpInfo.QuantityPrecision)
+		order, err := a.client.placePerpMarketOrder(ctx, group.Symbol, futures.SideTypeBuy, qty, true)
+		if err != nil {
+			return err
+		}
+		group.PerpLeg.CloseOrderID = order.OrderID
+		group.PerpLeg.ClosePrice, _ = strconv.ParseFloat(order.AvgPrice, 64)
+		a.store.SaveFundingArbGroup(ctx, group)
+	}
+
+	spotInfo, err := a.client.GetSpotSymbol(ctx, group.Symbol)
+	if err != nil {
+		return err
+	}
+	spotStep := decimal.NewFromFloat(spotInfo.StepSize)
+	qty := formatStepped(floorToStep(group.SpotLeg.Quantity, spotStep), spotStep, 0)
+	spotOrder, err := a.client.PlaceSpotMarketOrder(ctx, group.Symbol, "SELL", qty)
+	if err != nil {
+		group.Error = err.Error()
+		a.store.SaveFundingArbGroup(ctx, group)
+		return err
+	}
+	group.SpotLeg.CloseOrderID = spotOrder.OrderID
+	group.SpotLeg.ClosePrice = spotOrder.AvgPrice
+
+	a.RefreshFunding(ctx, group)
+	group.Status = models.ArbStatusClosed
+	group.Error = ""
+	group.ClosedAt = time.Now().Unix()
+
+	if err := a.store.SaveFundingArbGroup(ctx, group); err != nil {
+		return err
+	}
+
+	logging.Info().Str(logging.FieldSymbol, group.Symbol).Msgf("Funding arbitrage closed on %s (funding collected %.4f USDT)", group.Symbol, group.FundingCollected)
+	return nil
+}
+
+// RefreshFunding updates the funding collected since the group opened.
+// Funding income is per symbol, so other positions on it are included.
+func (a *FundingArbitrage) RefreshFunding(ctx context.Context, group *models.FundingArbGroup) {
+	end := time.Now().Unix()
+	if group.ClosedAt > 0 {
+		end = group.ClosedAt
+	}
+
+	total, err := a.client.GetIncomeTotal(ctx, "FUNDING_FEE", group.Symbol, group.OpenedAt, end)
+	if err != nil {
+		logging.Warn().Err(err).Str(logging.FieldSymbol, group.Symbol).Msgf("Failed to get funding income for %s", group.Symbol)
+		return
+	}
+	group.FundingCollected = total
+}
+
+// Start runs the funding arbitrage bot in the background. It may be started
+// again after Stop.
+func (a *FundingArbitrage) Start() {
+	stop := make(chan struct{})
+	a.stopChan = stop
+
+	logging.Info().Msgf("Funding arbitrage bot started (minRate=%.4f%%, exitRate=%.4f%%, notional=%.2f, maxGroups=%d, interval=%v)",
+		a.config.MinRate*100, a.config.ExitRate*100, a.config.Notional, a.config.MaxGroups, a.config.Interval)
+
+	go func() {
+		ticker := time.NewTicker(a.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				a.run()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the funding arbitrage bot
+func (a *FundingArbitrage) Stop() {
+	close(a.stopChan)
+	logging.Info().Msg("Funding arbitrage bot stopped")
+}
+
+// run closes pairs whose funding has faded and opens new ones up to MaxGroups
+func (a *FundingArbitrage) run() {
+	ctx := context.Background()
+
+	groups, err := a.store.GetFundingArbGroups(ctx)
+	if err != nil {
+		logging.Warn().Err(err).Msg("Funding arbitrage: failed to get groups")
+		return
+	}
+
+	openSymbols := make(map[string]bool)
+	for _, group := range groups {
+		if group.Status != models.ArbStatusOpen {
+			continue
+		}
+
+		funding, err := a.client.GetFundingRate(ctx, group.Symbol)
+		if err != nil {
+			logging.Warn().Err(err).Str(logging.FieldSymbol, group.Symbol).Msgf("Funding arbitrage: failed to get funding rate for %s", group.Symbol)
+			openSymbols[group.Symbol] = true
+			continue
+		}
+
+		if funding.FundingRate < a.config.ExitRate {
+			if err := a.Close(ctx, group); err != nil {
+				logging.Error().Err(err).Str(logging.FieldSymbol, group.Symbol).Msgf("Funding arbitrage: failed to close %s", group.Symbol)
+				openSymbols[group.Symbol] = true
+			}
+			continue
+		}
+
+		a.RefreshFunding(ctx, group)
+		a.store.SaveFundingArbGroup(ctx, group)
+		openSymbols[group.Symbol] = true
+	}
+
+	if len(openSymbols) >= a.config.MaxGroups || a.pause.Check() != nil {
+		return
+	}
+
+	opportunities, err := a.Opportunities(ctx, a.config.MinRate, 0)
+	if err != nil {
+		logging.Warn().Err(err).Msg("Funding arbitrage: failed to scan funding rates")
+		return
+	}
+
+	for _, opp := range opportunities {
+		if len(openSymbols) >= a.config.MaxGroups {
+			return
+		}
+		if opp.Direction != ArbDirectionShortPerp || !opp.SpotAvailable || openSymbols[opp.Symbol] {
+			continue
+		}
+		// A pair the next period's funding would close right away
+		if opp.PredictedRate < a.config.ExitRate {
+			continue
+		}
+
+		if _, err := a.Open(ctx, a.config.UserID, opp.Symbol, a.config.Notional, a.config.Leverage, "bot"); err != nil {
+			logging.Error().Err(err).Str(logging.FieldSymbol, opp.Symbol).Msgf("Funding arbitrage: failed to open %s", opp.Symbol)
+			continue
+		}
+		openSymbols[opp.Symbol] = true
+	}
+}
+
+// fail records a failed opening attempt
+func (a *FundingArbitrage) fail(ctx context.Context, group *models.FundingArbGroup, err error) (*models.FundingArbGroup, error) {
+	group.Status = models.ArbStatusFailed
+	group.Error = err.Error()
+	a.store.SaveFundingArbGroup(ctx, group)
+	logging.Error().Err(err).Str(logging.FieldSymbol, group.Symbol).Msgf("Funding arbitrage on %s failed", group.Symbol)
+	return group, err
+}
+
+// placePerpMarketOrder places a plain futures market order (no SL/TP)
+func (b *Client) placePerpMarketOrder(ctx context.Context, symbol string, side futures.SideType, quantity string, reduceOnly bool) (*futures.CreateOrderResponse, error) {
+	ctx, cancel := withOrderTimeout(ctx)
+	defer cancel()
+	service := b.client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		Type(futures.OrderTypeMarket).
+		Quantity(quantity).
+		NewOrderResponseType(futures.NewOrderRespTypeRESULT)
+	if reduceOnly {
+		service.ReduceOnly(true)
+	}
+
+	order, err := service.Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to place perp order: %v", err)
+	}
+	return order, nil
+}
+
+// floorToStep rounds a quantity down to the exchange step size, kept in
+// decimal for formatStepped
+func floorToStep(quantity float64, step decimal.Decimal) decimal.Decimal {
+	if !step.IsPositive() {
+		return decimal.NewFromFloat(quantity)
+	}
+	return floorToStepDecimal(decimal.NewFromFloat(quantity), step)
+}
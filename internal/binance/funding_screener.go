@@ -0,0 +1,295 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Funding screener sort keys
+const (
+	FundingSortRate       = "rate"       // |current funding rate|
+	FundingSortPredicted  = "predicted"  // |predicted funding rate|
+	FundingSortAnnualized = "annualized" // |current rate annualized|, so shorter funding intervals rank higher
+)
+
+// defaultFundingIntervalHours applies to perpetuals Binance lists no
+// adjusted interval for
+const defaultFundingIntervalHours = 8
+
+// interestClamp bounds the interest rate's correction of the premium in the
+// funding formula
+const interestClamp = 0.0005
+
+// FundingScreenEntry is one perpetual's funding in the screener
+type FundingScreenEntry struct {
+	Symbol                     string  `json:"symbol"`
+	FundingRate                float64 `json:"fundingRate"`   // Binance's rate for the current period
+	PredictedRate              float64 `json:"predictedRate"` // Estimated from the mark/index premium
+	AnnualizedPercent          float64 `json:"annualizedPercent"`
+	PredictedAnnualizedPercent float64 `json:"predictedAnnualizedPercent"`
+	FundingIntervalHours       int     `json:"fundingIntervalHours"`
+	Premium                    float64 `json:"premium"` // Mark price / index price - 1
+	InterestRate               float64 `json:"interestRate"`
+	MarkPrice                  float64 `json:"markPrice"`
+	IndexPrice                 float64 `json:"indexPrice"`
+	QuoteVolume                float64 `json:"quoteVolume"` // Last 24h, in the quote asset
+	NextFundingTime            int64   `json:"nextFundingTime"`
+}
+
+// FundingScreenFilter selects and orders screener entries
+type FundingScreenFilter struct {
+	MinQuoteVolume float64 // Minimum 24h quote volume
+	MinRate        float64 // Minimum absolute rate, of the rate sorted by (current rate for annualized)
+	Sign           int     // 1: positive rates only, -1: negative only, 0: both
+	SortBy         string  // rate (default), predicted or annualized
+	Limit          int     // <= 0 returns all
+}
+
+// ScreenFunding ranks every perpetual by its current or predicted funding
+// rate, largest absolute first. The predicted rate applies Binance's formula
+// (premium + interest rate correction clamped to ±0.05%, within the symbol's
+// rate cap) to the current mark/index premium; Binance averages the premium
+// over the period, so it is an estimate.
+func (b *Client) ScreenFunding(ctx context.Context, filter FundingScreenFilter) ([]*FundingScreenEntry, error) {
+	premiumIndex, err := b.getPremiumIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, err := b.get24hQuoteVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only perpetuals with adjusted caps or intervals are listed; the
+	// screener still works with the defaults without it
+	intervals, err := b.getFundingInfo(ctx)
+	if err != nil {
+		logging.Warn().Err(err).Msg("Funding screener: failed to get funding intervals, assuming 8h")
+	}
+
+	entries := []*FundingScreenEntry{}
+	for _, p := range premiumIndex {
+		if p.IndexPrice <= 0 || volumes[p.Symbol] < filter.MinQuoteVolume {
+			continue
+		}
+
+		info, ok := intervals[p.Symbol]
+		if !ok || info.IntervalHours <= 0 {
+			info.IntervalHours = defaultFundingIntervalHours
+		}
+		premium := p.MarkPrice/p.IndexPrice - 1
+		predicted := premium + math.Max(-interestClamp, math.Min(interestClamp, p.InterestRate-premium))
+		if info.Cap > 0 {
+			predicted = math.Min(predicted, info.Cap)
+		}
+		if info.Floor < 0 {
+			predicted = math.Max(predicted, info.Floor)
+		}
+		periodsPerYear := float64(365*24) / float64(info.IntervalHours)
+
+		entry := &FundingScreenEntry{
+			Symbol:                     p.Symbol,
+			FundingRate:                p.FundingRate,
+			PredictedRate:              predicted,
+			AnnualizedPercent:          p.FundingRate * periodsPerYear * 100,
+			PredictedAnnualizedPercent: predicted * periodsPerYear * 100,
+			FundingIntervalHours:       info.IntervalHours,
+			Premium:                    premium,
+			InterestRate:               p.InterestRate,
+			MarkPrice:                  p.MarkPrice,
+			IndexPrice:                 p.IndexPrice,
+			QuoteVolume:                volumes[p.Symbol],
+			NextFundingTime:            p.NextFundingTime,
+		}
+
+		rate := entry.sortRate(filter.SortBy)
+		if math.Abs(rate) < filter.MinRate || rate == 0 || (filter.Sign > 0 && rate < 0) || (filter.Sign < 0 && rate > 0) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		x, y := math.Abs(entries[i].sortKey(filter.SortBy)), math.Abs(entries[j].sortKey(filter.SortBy))
+		if x == y {
+			return entries[i].Symbol < entries[j].Symbol
+		}
+		return x > y
+	})
+
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+// sortRate is the funding rate the filter applies to
+func (e *FundingScreenEntry) sortRate(sortBy string) float64 {
+	if sortBy == FundingSortPredicted {
+		return e.PredictedRate
+	}
+	return e.FundingRate
+}
+
+// sortKey is the value entries are ranked by
+func (e *FundingScreenEntry) sortKey(sortBy string) float64 {
+	if sortBy == FundingSortAnnualized {
+		return e.AnnualizedPercent
+	}
+	return e.sortRate(sortBy)
+}
+
+// premiumIndexEntry is one perpetual of /fapi/v1/premiumIndex
+type premiumIndexEntry struct {
+	Symbol          string
+	MarkPrice       float64
+	IndexPrice      float64
+	FundingRate     float64
+	InterestRate    float64
+	NextFundingTime int64
+}
+
+// getPremiumIndex gets the premium index of every perpetual. go-binance's has
+// no index price or interest rate, so the request is built here.
+func (b *Client) getPremiumIndex(ctx context.Context) ([]premiumIndexEntry, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.client.BaseURL+"/fapi/v1/premiumIndex", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := b.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get funding rates: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get funding rates: %v", responseError(resp))
+	}
+
+	var premiumIndex []struct {
+		Symbol          string `json:"symbol"`
+		MarkPrice       string `json:"markPrice"`
+		IndexPrice      string `json:"indexPrice"`
+		LastFundingRate string `json:"lastFundingRate"`
+		InterestRate    string `json:"interestRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&premiumIndex); err != nil {
+		return nil, fmt.Errorf("failed to parse funding rates: %v", err)
+	}
+
+	entries := make([]premiumIndexEntry, 0, len(premiumIndex))
+	for _, p := range premiumIndex {
+		entry := premiumIndexEntry{Symbol: p.Symbol, NextFundingTime: p.NextFundingTime}
+		entry.MarkPrice, _ = strconv.ParseFloat(p.MarkPrice, 64)
+		entry.IndexPrice, _ = strconv.ParseFloat(p.IndexPrice, 64)
+		entry.FundingRate, _ = strconv.ParseFloat(p.LastFundingRate, 64)
+		entry.InterestRate, _ = strconv.ParseFloat(p.InterestRate, 64)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// get24hQuoteVolumes gets the last 24h quote volume of every perpetual
+func (b *Client) get24hQuoteVolumes(ctx context.Context) (map[string]float64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	stats, err := b.client.NewListPriceChangeStatsService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get 24h volumes: %v", err)
+	}
+
+	volumes := make(map[string]float64, len(stats))
+	for _, s := range stats {
+		volumes[s.Symbol], _ = strconv.ParseFloat(s.QuoteVolume, 64)
+	}
+	return volumes, nil
+}
+
+// TopSymbolsByVolume returns the n perpetuals (delivery contracts excluded)
+// with the highest 24h quote volume, highest first, with their volumes
+func (b *Client) TopSymbolsByVolume(ctx context.Context, n int) ([]string, map[string]float64, error) {
+	volumes, err := b.get24hQuoteVolumes(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ranked := make([]string, 0, len(volumes))
+	for symbol, volume := range volumes {
+		if volume > 0 && !strings.Contains(symbol, "_") {
+			ranked = append(ranked, symbol)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if volumes[ranked[i]] == volumes[ranked[j]] {
+			return ranked[i] < ranked[j]
+		}
+		return volumes[ranked[i]] > volumes[ranked[j]]
+	})
+
+	if n > 0 && len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked, volumes, nil
+}
+
+// fundingInfo is a perpetual's adjusted funding interval and rate cap
+type fundingInfo struct {
+	IntervalHours int
+	Cap           float64
+	Floor         float64
+}
+
+// getFundingInfo gets the perpetuals whose funding interval or rate cap
+// Binance adjusted (not in go-binance)
+func (b *Client) getFundingInfo(ctx context.Context) (map[string]fundingInfo, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.client.BaseURL+"/fapi/v1/fundingInfo", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := b.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get funding info: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get funding info: %v", responseError(resp))
+	}
+
+	var list []struct {
+		Symbol        string `json:"symbol"`
+		Cap           string `json:"adjustedFundingRateCap"`
+		Floor         string `json:"adjustedFundingRateFloor"`
+		IntervalHours int    `json:"fundingIntervalHours"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to parse funding info: %v", err)
+	}
+
+	infos := make(map[string]fundingInfo, len(list))
+	for _, item := range list {
+		info := fundingInfo{IntervalHours: item.IntervalHours}
+		info.Cap, _ = strconv.ParseFloat(item.Cap, 64)
+		info.Floor, _ = strconv.ParseFloat(item.Floor, 64)
+		infos[item.Symbol] = info
+	}
+	return infos, nil
+}
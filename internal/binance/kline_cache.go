@@ -0,0 +1,70 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// klineCacheTTL is how long fetched klines are reused. The forming candle
+// moves with every trade, so they are not kept longer than a minute.
+const klineCacheTTL = time.Minute
+
+// klineCache keeps recently fetched klines per symbol and interval, so
+// screening the same instruments again within a minute does not go back to
+// Binance. Like the price cache it is shared by every client.
+type klineCache struct {
+	mu      sync.Mutex
+	entries map[string]klineCacheEntry
+}
+
+type klineCacheEntry struct {
+	candles   []models.Candle
+	fetchedAt time.Time
+}
+
+var klines = &klineCache{entries: make(map[string]klineCacheEntry)}
+
+// get returns the most recent limit candles of a series fetched within the
+// TTL, false if there are none or fewer
+func (k *klineCache) get(key string, limit int, now time.Time) ([]models.Candle, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	entry, ok := k.entries[key]
+	if !ok || now.Sub(entry.fetchedAt) >= klineCacheTTL || len(entry.candles) < limit {
+		return nil, false
+	}
+	return append([]models.Candle(nil), entry.candles[len(entry.candles)-limit:]...), true
+}
+
+// put stores a series, dropping the entries that expired
+func (k *klineCache) put(key string, candles []models.Candle, now time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for cached, entry := range k.entries {
+		if now.Sub(entry.fetchedAt) >= klineCacheTTL {
+			delete(k.entries, cached)
+		}
+	}
+	k.entries[key] = klineCacheEntry{candles: candles, fetchedAt: now}
+}
+
+// GetCachedCandles returns the most recent klines like GetCandles, reusing
+// the ones fetched for the symbol and interval within the last minute
+func (b *Client) GetCachedCandles(ctx context.Context, symbol, interval string, limit int) ([]models.Candle, error) {
+	key := strings.ToUpper(symbol) + "|" + interval
+	if candles, ok := klines.get(key, limit, time.Now()); ok {
+		return candles, nil
+	}
+
+	candles, err := b.GetCandles(ctx, symbol, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+	klines.put(key, candles, time.Now())
+	return append([]models.Candle(nil), candles...), nil
+}
@@ -0,0 +1,309 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/tradehistory"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Margin guard modes
+const (
+	MarginGuardModeAddMargin = "ADD_MARGIN"
+	MarginGuardModeReduce    = "REDUCE_POSITION"
+)
+
+// marginGuardTarget is how far above MinDistance a top-up brings the
+// liquidation price, so the next mark price tick does not trigger it again
+const marginGuardTarget = 1.25
+
+// MarginGuardConfig configures the auto-deleverage / margin top-up automation
+type MarginGuardConfig struct {
+	Mode          string        // ADD_MARGIN or REDUCE_POSITION
+	MinDistance   float64       // Act when distance to liquidation (%) drops below this
+	TopUpAmount   float64       // Most USDT added per ADD_MARGIN action, 0 for no limit
+	ReducePercent float64       // Percentage of the position closed per REDUCE_POSITION action
+	CheckInterval time.Duration // How often positions are checked
+	Cooldown      time.Duration // Minimum time between actions on the same symbol
+}
+
+// RiskActionJournal persists automated risk actions and records margin
+// top-ups on the trades holding the positions
+type RiskActionJournal interface {
+	SaveRiskAction(ctx context.Context, action *models.RiskAction) error
+	GetTradesByStatus(ctx context.Context, status string) ([]*models.Trade, error)
+	UpdateTrade(ctx context.Context, trade *models.Trade) error
+}
+
+// MarginGuard watches ISOLATED positions and adds margin or reduces size
+// when they get too close to liquidation
+type MarginGuard struct {
+	client     *Client
+	journal    RiskActionJournal
+	bus        *events.Bus
+	config     MarginGuardConfig
+	lastAction map[string]time.Time
+	mu         sync.Mutex
+	stopChan   chan struct{}
+}
+
+// NewMarginGuard creates a new margin guard. An unknown mode is refused
+// rather than falling back to one of the actions.
+func NewMarginGuard(client *Client, journal RiskActionJournal, bus *events.Bus, config MarginGuardConfig) (*MarginGuard, error) {
+	if err := validateMarginGuardMode(config.Mode); err != nil {
+		return nil, err
+	}
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = 30 * time.Second
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = 5 * time.Minute
+	}
+
+	return &MarginGuard{
+		client:     client,
+		journal:    journal,
+		bus:        bus,
+		config:     config,
+		lastAction: make(map[string]time.Time),
+		stopChan:   make(chan struct{}),
+	}, nil
+}
+
+// validateMarginGuardMode rejects modes other than ADD_MARGIN and
+// REDUCE_POSITION
+func validateMarginGuardMode(mode string) error {
+	if mode != MarginGuardModeAddMargin && mode != MarginGuardModeReduce {
+		return fmt.Errorf("unknown margin guard mode %q (expected %s or %s)", mode, MarginGuardModeAddMargin, MarginGuardModeReduce)
+	}
+	return nil
+}
+
+// Start runs the guard loop in the background. It may be started again
+// after Stop.
+func (g *MarginGuard) Start() {
+	stop := make(chan struct{})
+	g.stopChan = stop
+
+	logging.Info().Msgf("Margin guard started (mode=%s, minDistance=%.2f%%, interval=%v)",
+		g.config.Mode, g.config.MinDistance, g.config.CheckInterval)
+
+	go func() {
+		ticker := time.NewTicker(g.config.CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				g.checkPositions()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// SetThresholds changes the mode, trigger distance, action sizes and
+// cooldown (config reload). The check interval is fixed at startup. An
+// unknown mode is refused and the current settings kept.
+func (g *MarginGuard) SetThresholds(config MarginGuardConfig) error {
+	if err := validateMarginGuardMode(config.Mode); err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.config.Mode = config.Mode
+	g.config.MinDistance = config.MinDistance
+	g.config.TopUpAmount = config.TopUpAmount
+	g.config.ReducePercent = config.ReducePercent
+	if config.Cooldown > 0 {
+		g.config.Cooldown = config.Cooldown
+	}
+	return nil
+}
+
+// settings returns the current configuration
+func (g *MarginGuard) settings() MarginGuardConfig {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.config
+}
+
+// Stop stops the guard loop
+func (g *MarginGuard) Stop() {
+	close(g.stopChan)
+	logging.Info().Msg("Margin guard stopped")
+}
+
+// checkPositions evaluates every open ISOLATED position once
+func (g *MarginGuard) checkPositions() {
+	ctx := context.Background()
+	config := g.settings()
+
+	positions, err := g.client.GetOpenPositions(ctx)
+	if err != nil {
+		logging.Warn().Err(err).Msg("Margin guard: failed to get positions")
+		return
+	}
+
+	for _, pos := range positions {
+		if !strings.EqualFold(pos.MarginType, "ISOLATED") {
+			continue
+		}
+
+		pos.MarkPrice = freshMarkPrice(pos.Symbol, pos.MarkPrice)
+		distance := distanceToLiquidation(pos.PositionAmt, pos.MarkPrice, pos.LiquidationPrice)
+		if pos.LiquidationPrice <= 0 || distance >= config.MinDistance {
+			continue
+		}
+
+		if !g.cooldownElapsed(pos.Symbol) {
+			continue
+		}
+
+		g.protect(ctx, config, pos, distance)
+	}
+}
+
+// cooldownElapsed reports whether a new action may be taken on symbol
+func (g *MarginGuard) cooldownElapsed(symbol string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	last, ok := g.lastAction[symbol]
+	return !ok || time.Since(last) >= g.config.Cooldown
+}
+
+// startCooldown records an applied action on symbol. Failed actions do not
+// start one, so they are retried on the next check.
+func (g *MarginGuard) startCooldown(symbol string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lastAction[symbol] = time.Now()
+}
+
+// topUpAmount is the margin (USDT) that moves an isolated position's
+// liquidation price back to marginGuardTarget times MinDistance from the mark
+// price, capped at TopUpAmount. Each USDT added moves the liquidation price
+// by about 1/|positionAmt|.
+func topUpAmount(config MarginGuardConfig, pos *PositionInfo) float64 {
+	target := config.MinDistance * marginGuardTarget / 100
+	targetPrice := pos.MarkPrice * (1 - target) // Long: liquidation below the mark
+	if pos.PositionAmt < 0 {
+		targetPrice = pos.MarkPrice * (1 + target)
+	}
+
+	amount := math.Abs(pos.LiquidationPrice-targetPrice) * math.Abs(pos.PositionAmt)
+	amount = math.Ceil(amount*100) / 100
+	if config.TopUpAmount > 0 && amount > config.TopUpAmount {
+		amount = config.TopUpAmount
+	}
+	return amount
+}
+
+// protect applies the configured action to a position and journals it
+func (g *MarginGuard) protect(ctx context.Context, config MarginGuardConfig, pos *PositionInfo, distance float64) {
+	action := &models.RiskAction{
+		ID:                    uuid.New().String(),
+		Symbol:                pos.Symbol,
+		Action:                config.Mode,
+		Reason:                fmt.Sprintf("distance to liquidation %.2f%% below %.2f%%", distance, config.MinDistance),
+		PositionAmt:           pos.PositionAmt,
+		MarkPrice:             pos.MarkPrice,
+		LiquidationPrice:      pos.LiquidationPrice,
+		DistanceToLiquidation: distance,
+		CreatedAt:             time.Now().Unix(),
+	}
+
+	logging.Info().Str(logging.FieldSymbol, pos.Symbol).Msgf("Margin guard: %s %s", pos.Symbol, action.Reason)
+
+	var err error
+	switch config.Mode {
+	case MarginGuardModeReduce:
+		var result *ClosePositionResult
+		result, err = g.client.ReducePosition(ctx, pos.Symbol, config.ReducePercent)
+		if err == nil {
+			action.Quantity = result.Quantity
+			action.OrderID = result.OrderID
+		}
+	case MarginGuardModeAddMargin:
+		action.Amount = topUpAmount(config, pos)
+		err = g.client.ChangePositionMargin(ctx, pos.Symbol, action.Amount, true)
+		if err == nil {
+			g.recordMargin(ctx, action)
+		}
+	default:
+		// Modes are validated when set; never guess an action
+		err = validateMarginGuardMode(config.Mode)
+	}
+
+	if err != nil {
+		action.Error = err.Error()
+		logging.Error().Err(err).Str(logging.FieldSymbol, pos.Symbol).Msgf("Margin guard: %s on %s failed", action.Action, pos.Symbol)
+	} else {
+		action.Success = true
+		g.startCooldown(pos.Symbol)
+		logging.Info().Str(logging.FieldSymbol, pos.Symbol).Msgf("Margin guard: %s on %s applied", action.Action, pos.Symbol)
+	}
+
+	outcome := action.Action
+	if !action.Success {
+		outcome += " (failed)"
+	}
+	g.bus.Publish(events.RiskWarning{
+		Symbol:           pos.Symbol,
+		MarkPrice:        pos.MarkPrice,
+		LiquidationPrice: pos.LiquidationPrice,
+		Distance:         distance,
+		Action:           outcome,
+	})
+
+	if g.journal != nil {
+		if err := g.journal.SaveRiskAction(ctx, action); err != nil {
+			logging.Warn().Err(err).Msg("Margin guard: failed to journal action")
+		}
+	}
+}
+
+// recordMargin adds a top-up to the open trade holding the position, the
+// newest one if there are several, so it shows in the trade's history
+func (g *MarginGuard) recordMargin(ctx context.Context, action *models.RiskAction) {
+	if g.journal == nil {
+		return
+	}
+
+	var linked *models.Trade
+	for _, status := range []string{"ACTIVE", "FILLED"} {
+		trades, err := g.journal.GetTradesByStatus(ctx, status)
+		if err != nil {
+			logging.Warn().Err(err).Msg("Margin guard: failed to find the trade of the position")
+			return
+		}
+		for _, trade := range trades {
+			// The guard watches the main account
+			if trade.Symbol == action.Symbol && trade.Account == "" && (linked == nil || trade.CreatedAt > linked.CreatedAt) {
+				linked = trade
+			}
+		}
+	}
+	if linked == nil {
+		return
+	}
+
+	linked.MarginAdded += action.Amount
+	if err := g.journal.UpdateTrade(tradehistory.WithSource(ctx, tradehistory.SourceMarginGuard), linked); err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, linked.ID).Msgf("Margin guard: failed to record margin on trade %s", linked.ID)
+		return
+	}
+	action.TradeID = linked.ID
+}
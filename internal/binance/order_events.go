@@ -0,0 +1,65 @@
+package binance
+
+import (
+	"crypto-trading-api/internal/events"
+	"sync"
+	"sync/atomic"
+)
+
+// orderEvents fans out order updates from a client's user data stream to
+// the monitors waiting on individual orders
+type orderEvents struct {
+	subscribers map[int64]map[chan events.Order]struct{}
+	connected   atomic.Bool
+	mu          sync.Mutex
+}
+
+func newOrderEvents() *orderEvents {
+	return &orderEvents{subscribers: make(map[int64]map[chan events.Order]struct{})}
+}
+
+// subscribe returns a channel receiving the updates of an order and a
+// function that ends the subscription
+func (e *orderEvents) subscribe(orderID int64) (<-chan events.Order, func()) {
+	ch := make(chan events.Order, 16)
+
+	e.mu.Lock()
+	if e.subscribers[orderID] == nil {
+		e.subscribers[orderID] = make(map[chan events.Order]struct{})
+	}
+	e.subscribers[orderID][ch] = struct{}{}
+	e.mu.Unlock()
+
+	return ch, func() {
+		e.mu.Lock()
+		delete(e.subscribers[orderID], ch)
+		if len(e.subscribers[orderID]) == 0 {
+			delete(e.subscribers, orderID)
+		}
+		e.mu.Unlock()
+	}
+}
+
+// publish delivers an update to the order's subscribers. A subscriber whose
+// buffer is full misses the update and catches up through the REST fallback.
+func (e *orderEvents) publish(event events.Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for ch := range e.subscribers[event.OrderID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// setConnected records whether the user data stream is delivering events
+func (e *orderEvents) setConnected(connected bool) {
+	e.connected.Store(connected)
+}
+
+// streaming reports whether order updates arrive through the user data stream
+func (e *orderEvents) streaming() bool {
+	return e.connected.Load()
+}
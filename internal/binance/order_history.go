@@ -0,0 +1,157 @@
+package binance
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// OrderCursor is the position of an order in oldest-first order
+type OrderCursor struct {
+	Time    int64 // Milliseconds
+	OrderID int64
+}
+
+// precedes reports whether order comes at or before the cursor
+func (c OrderCursor) precedes(order *futures.Order) bool {
+	return order.Time < c.Time || (order.Time == c.Time && order.OrderID <= c.OrderID)
+}
+
+// String encodes the cursor for the nextCursor response field
+func (c OrderCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.Time, 10) + ":" + strconv.FormatInt(c.OrderID, 10)))
+}
+
+// ParseOrderCursor decodes a cursor returned as nextCursor
+func ParseOrderCursor(s string) (*OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	timeMs, orderID, ok := strings.Cut(string(data), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	cursor := &OrderCursor{}
+	if cursor.Time, err = strconv.ParseInt(timeMs, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if cursor.OrderID, err = strconv.ParseInt(orderID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return cursor, nil
+}
+
+// PageOrders sorts orders oldest first (by creation time, then order ID)
+// and returns up to limit of those after the cursor. Limit 0 returns them all.
+func PageOrders(orders []*futures.Order, after *OrderCursor, limit int) *OrderHistoryPage {
+	sorted := make([]*futures.Order, 0, len(orders))
+	for _, order := range orders {
+		if after == nil || !after.precedes(order) {
+			sorted = append(sorted, order)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Time != sorted[j].Time {
+			return sorted[i].Time < sorted[j].Time
+		}
+		return sorted[i].OrderID < sorted[j].OrderID
+	})
+
+	page := &OrderHistoryPage{Orders: sorted}
+	if limit > 0 && len(sorted) > limit {
+		page.Orders = sorted[:limit]
+		last := page.Orders[limit-1]
+		page.NextCursor = OrderCursor{Time: last.Time, OrderID: last.OrderID}.String()
+	}
+	return page
+}
+
+// OrderHistoryQuery selects orders of one symbol from Binance's all-orders
+// history
+type OrderHistoryQuery struct {
+	Symbol    string
+	Statuses  []string     // Empty = any status
+	StartTime int64        // Unix seconds
+	EndTime   int64        // Unix seconds
+	Limit     int          // Page size
+	After     *OrderCursor // Continue after this order (from the previous page)
+}
+
+// OrderHistoryPage is one page of an order history query
+type OrderHistoryPage struct {
+	Orders     []*futures.Order
+	NextCursor string // Absent on the last page
+}
+
+// GetOrderHistory - Get a page of a symbol's orders (any status), oldest
+// first, paging through Binance's 7-day window and 1000-order limit.
+// Binance keeps cancelled and expired orders without fills for 3 days only.
+func (b *Client) GetOrderHistory(ctx context.Context, query OrderHistoryQuery) (*OrderHistoryPage, error) {
+	const pageLimit = 1000
+
+	statuses := make(map[string]bool, len(query.Statuses))
+	for _, status := range query.Statuses {
+		statuses[status] = true
+	}
+
+	start := query.StartTime * 1000
+	if query.After != nil && query.After.Time > start {
+		start = query.After.Time
+	}
+	endMs := query.EndTime * 1000
+
+	orders := []*futures.Order{}
+	for windowStart := start; windowStart < endMs && len(orders) <= query.Limit; windowStart += accountTradesWindow {
+		windowEnd := min(windowStart+accountTradesWindow-1, endMs)
+
+		from := windowStart
+		for len(orders) <= query.Limit {
+			reqCtx, cancel := withTimeout(ctx)
+			page, err := b.client.NewListOrdersService().
+				Symbol(query.Symbol).
+				StartTime(from).
+				EndTime(windowEnd).
+				Limit(pageLimit).
+				Do(reqCtx)
+			cancel()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get %s orders: %v", query.Symbol, err)
+			}
+
+			sort.Slice(page, func(i, j int) bool {
+				if page[i].Time != page[j].Time {
+					return page[i].Time < page[j].Time
+				}
+				return page[i].OrderID < page[j].OrderID
+			})
+			for _, order := range page {
+				if query.After != nil && query.After.precedes(order) {
+					continue
+				}
+				if len(statuses) > 0 && !statuses[string(order.Status)] {
+					continue
+				}
+				orders = append(orders, order)
+			}
+
+			if len(page) < pageLimit {
+				break
+			}
+			from = page[len(page)-1].Time + 1
+		}
+	}
+
+	result := &OrderHistoryPage{Orders: orders}
+	if len(orders) > query.Limit {
+		result.Orders = orders[:query.Limit]
+		last := result.Orders[query.Limit-1]
+		result.NextCursor = OrderCursor{Time: last.Time, OrderID: last.OrderID}.String()
+	}
+	return result, nil
+}
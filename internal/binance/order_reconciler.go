@@ -0,0 +1,287 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/notifications"
+	"crypto-trading-api/internal/tradehistory"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// OrderReconcilerConfig configures the orphaned order reconciliation job
+type OrderReconcilerConfig struct {
+	Interval    time.Duration // How often to reconcile
+	GracePeriod time.Duration // Trades and orders younger than this are left alone (still being placed)
+	Adopt       bool          // Adopt unmanaged positions as trades once seen for the grace period
+	AdoptUserID string        // Owner of adopted trades (default "operator")
+	Protect     bool          // Attach SL/TP to adopted positions from the owner's settings
+}
+
+// OrderReconcilerStore provides the trades and funding arbitrage pairs the
+// exchange state is compared with, and saves adopted positions
+type OrderReconcilerStore interface {
+	TradeStore
+	SaveTrade(ctx context.Context, trade *models.Trade) error
+	GetFundingArbGroups(ctx context.Context) ([]*models.FundingArbGroup, error)
+	GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error)
+}
+
+// OrderReconciler compares the primary account's open positions and orders
+// with Firebase trades: it cancels SL/TP orders whose positions are gone,
+// marks trades CLOSED when the exchange shows no position and reports
+// positions opened outside the API, which it can adopt as trades
+type OrderReconciler struct {
+	client    *Client
+	store     OrderReconcilerStore
+	bus       *events.Bus
+	notifier  *notifications.Notifier
+	config    OrderReconcilerConfig
+	unmanaged map[string]int64 // Symbol -> first seen, so each position is announced once
+	last      *models.OrderReconcileReport
+	running   sync.Mutex // Serializes scheduled and manual runs
+	mu        sync.RWMutex
+	stopChan  chan struct{}
+}
+
+// NewOrderReconciler creates a new order reconciler
+func NewOrderReconciler(client *Client, store OrderReconcilerStore, bus *events.Bus, notifier *notifications.Notifier, config OrderReconcilerConfig) *OrderReconciler {
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Minute
+	}
+	if config.GracePeriod <= 0 {
+		config.GracePeriod = 2 * time.Minute
+	}
+	if config.AdoptUserID == "" {
+		config.AdoptUserID = "operator"
+	}
+
+	return &OrderReconciler{
+		client:    client,
+		store:     store,
+		bus:       bus,
+		notifier:  notifier,
+		config:    config,
+		unmanaged: make(map[string]int64),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start runs the reconciliation loop in the background
+func (r *OrderReconciler) Start() {
+	logging.Info().Msgf("Order reconciler started (interval=%v, grace=%v)", r.config.Interval, r.config.GracePeriod)
+
+	go func() {
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		r.Reconcile(context.Background())
+		for {
+			select {
+			case <-ticker.C:
+				r.Reconcile(context.Background())
+			case <-r.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the reconciliation loop
+func (r *OrderReconciler) Stop() {
+	close(r.stopChan)
+	logging.Info().Msg("Order reconciler stopped")
+}
+
+// LastReport returns the report of the latest run (nil before the first one)
+func (r *OrderReconciler) LastReport() *models.OrderReconcileReport {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.last
+}
+
+// Reconcile compares the exchange with Firebase once and fixes discrepancies.
+// It fails only if the positions, orders or trades cannot be read; problems
+// with individual trades and orders are listed in the report.
+func (r *OrderReconciler) Reconcile(ctx context.Context) (*models.OrderReconcileReport, error) {
+	ctx = tradehistory.WithSource(ctx, tradehistory.SourceOrderReconciler)
+	r.running.Lock()
+	defer r.running.Unlock()
+
+	report := &models.OrderReconcileReport{
+		StartedAt:          time.Now().Unix(),
+		ClosedTrades:       []string{},
+		CanceledOrders:     []models.OrphanedOrder{},
+		UnmanagedPositions: []models.UnmanagedPosition{},
+	}
+	defer func() {
+		report.FinishedAt = time.Now().Unix()
+		r.mu.Lock()
+		r.last = report
+		r.mu.Unlock()
+	}()
+
+	positions, err := r.client.GetOpenPositions(ctx)
+	if err != nil {
+		return report, r.fail(report, fmt.Errorf("failed to get positions: %v", err))
+	}
+	orders, err := r.client.GetOpenOrders(ctx, "")
+	if err != nil {
+		return report, r.fail(report, fmt.Errorf("failed to get open orders: %v", err))
+	}
+	trades, err := r.store.GetAllTrades(ctx)
+	if err != nil {
+		return report, r.fail(report, fmt.Errorf("failed to get trades: %v", err))
+	}
+	report.OpenPositions = len(positions)
+	report.OpenOrders = len(orders)
+
+	hasPosition := make(map[string]bool)
+	for _, pos := range positions {
+		hasPosition[pos.Symbol] = true
+	}
+	openOrderIDs := make(map[int64]bool)
+	for _, order := range orders {
+		openOrderIDs[order.OrderID] = true
+	}
+
+	graceCutoff := time.Now().Add(-r.config.GracePeriod)
+	managed := make(map[string]bool)  // Symbols with an open trade
+	protected := make(map[int64]bool) // SL/TP orders of trades still open
+
+	for _, trade := range trades {
+		// Positions and orders are read from the primary account only
+		if trade.Account != "" || (trade.Status != "ACTIVE" && trade.Status != "FILLED") {
+			continue
+		}
+		report.OpenTrades++
+
+		// Positions gone while the entry order is not pending any more
+		young := time.Unix(trade.CreatedAt, 0).After(graceCutoff)
+		if !hasPosition[trade.Symbol] && !young && !openOrderIDs[trade.OrderID] {
+			r.closeTrade(ctx, trade, report)
+			continue
+		}
+
+		managed[trade.Symbol] = true
+		protected[trade.SLOrderID] = true
+		protected[trade.TPOrderID] = true
+	}
+
+	// Perp legs of open funding arbitrage pairs are managed too
+	if err := r.addArbSymbols(ctx, managed); err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+
+	for _, order := range orders {
+		if hasPosition[order.Symbol] || protected[order.OrderID] || !isProtectiveOrder(order) ||
+			time.UnixMilli(order.Time).After(graceCutoff) {
+			continue
+		}
+		r.cancelOrphan(ctx, order, report)
+	}
+
+	r.reportUnmanaged(positions, managed, report)
+
+	// Positions still unmanaged after the grace period are not being placed
+	// by the API, so they are adopted
+	if r.config.Adopt {
+		for _, unmanaged := range report.UnmanagedPositions {
+			if time.Unix(unmanaged.FirstSeenAt, 0).After(graceCutoff) {
+				continue
+			}
+			for _, pos := range positions {
+				if pos.Symbol == unmanaged.Symbol {
+					adopted := r.adopt(ctx, pos, orders, r.config.AdoptUserID, r.config.Protect)
+					report.AdoptedPositions = append(report.AdoptedPositions, adopted)
+				}
+			}
+		}
+	}
+
+	if len(report.ClosedTrades)+len(report.CanceledOrders)+len(report.UnmanagedPositions) > 0 {
+		logging.Info().Msgf("Order reconciler: closed=%d canceled=%d unmanaged=%d adopted=%d",
+			len(report.ClosedTrades), len(report.CanceledOrders), len(report.UnmanagedPositions), len(report.AdoptedPositions))
+	}
+	return report, nil
+}
+
+// fail records an error that stopped the run
+func (r *OrderReconciler) fail(report *models.OrderReconcileReport, err error) error {
+	report.Errors = append(report.Errors, err.Error())
+	logging.Warn().Err(err).Msg("Order reconciler")
+	return err
+}
+
+// closeTrade marks a trade CLOSED because its position no longer exists. Its
+// realized PnL is left to the PnL reconciler.
+func (r *OrderReconciler) closeTrade(ctx context.Context, trade *models.Trade, report *models.OrderReconcileReport) {
+	trade.Status = "CLOSED"
+	trade.ClosedAt = time.Now().Unix()
+
+	if err := r.store.UpdateTrade(ctx, trade); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to close trade %s: %v", trade.ID, err))
+		logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("Order reconciler: failed to close trade %s", trade.ID)
+		return
+	}
+
+	report.ClosedTrades = append(report.ClosedTrades, trade.ID)
+	logging.Info().Str(logging.FieldTradeID, trade.ID).Str(logging.FieldSymbol, trade.Symbol).Msgf("Order reconciler: trade %s closed, no %s position on the exchange", trade.ID, trade.Symbol)
+	r.bus.Publish(events.PositionClosed{Trade: trade, Symbol: trade.Symbol, Reason: events.CloseReconciled})
+}
+
+// cancelOrphan cancels a protective order left behind by a closed position
+func (r *OrderReconciler) cancelOrphan(ctx context.Context, order *futures.Order, report *models.OrderReconcileReport) {
+	orphan := models.OrphanedOrder{
+		Symbol:  order.Symbol,
+		OrderID: order.OrderID,
+		Type:    string(order.OrigType),
+	}
+
+	if err := r.client.CancelOrder(ctx, order.Symbol, order.OrderID); err != nil {
+		orphan.Error = err.Error()
+		logging.Warn().Err(err).Str(logging.FieldSymbol, order.Symbol).Msgf("Order reconciler: failed to cancel orphaned %s order %d", order.Symbol, order.OrderID)
+	} else {
+		orphan.Canceled = true
+		logging.Info().Str(logging.FieldSymbol, order.Symbol).Msgf("Order reconciler: canceled orphaned %s %s order %d", order.Symbol, orphan.Type, order.OrderID)
+	}
+
+	report.CanceledOrders = append(report.CanceledOrders, orphan)
+}
+
+// reportUnmanaged lists positions no open trade accounts for and announces
+// each one the first time it is seen
+func (r *OrderReconciler) reportUnmanaged(positions []*PositionInfo, managed map[string]bool, report *models.OrderReconcileReport) {
+	current := make(map[string]int64)
+	for _, pos := range positions {
+		if managed[pos.Symbol] {
+			continue
+		}
+
+		firstSeen, known := r.unmanaged[pos.Symbol]
+		if !known {
+			firstSeen = time.Now().Unix()
+			logging.Warn().Str(logging.FieldSymbol, pos.Symbol).Msgf("Order reconciler: unmanaged %s position %g", pos.Symbol, pos.PositionAmt)
+			r.notifier.Publish(notifications.UnmanagedPosition(pos.Symbol, pos.PositionAmt, pos.EntryPrice))
+		}
+		current[pos.Symbol] = firstSeen
+
+		report.UnmanagedPositions = append(report.UnmanagedPositions, models.UnmanagedPosition{
+			Symbol:      pos.Symbol,
+			PositionAmt: pos.PositionAmt,
+			EntryPrice:  pos.EntryPrice,
+			FirstSeenAt: firstSeen,
+		})
+	}
+	r.unmanaged = current
+}
+
+// isProtectiveOrder reports whether an order only reduces or closes a position
+func isProtectiveOrder(order *futures.Order) bool {
+	return order.ClosePosition || order.ReduceOnly
+}
@@ -0,0 +1,214 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/tradehistory"
+	"math"
+	"time"
+)
+
+// Reconciliation statuses recorded on trades
+const (
+	ReconcileMatched   = "MATCHED"
+	ReconcileUnmatched = "UNMATCHED"
+	ReconcileAmbiguous = "AMBIGUOUS"
+	ReconcileNoAccount = "NO_ACCOUNT" // The trade's account is not configured here
+)
+
+// TradeStore provides the trades a background worker reads and corrects
+type TradeStore interface {
+	GetAllTrades(ctx context.Context) ([]*models.Trade, error)
+	UpdateTrade(ctx context.Context, trade *models.Trade) error
+}
+
+// PnLReconcilerConfig configures the realized PnL reconciliation job
+type PnLReconcilerConfig struct {
+	Interval  time.Duration      // How often to reconcile
+	Lookback  time.Duration      // Only trades closed within this window are checked
+	Tolerance float64            // Differences below this (USDT) are not reported as discrepancies
+	Followers map[string]*Client // Copy trading follower accounts, by name
+}
+
+// PnLReconciler corrects trade PnL from Binance REALIZED_PNL income records,
+// read from the account each trade was placed on
+type PnLReconciler struct {
+	clients  *ClientPool
+	store    TradeStore
+	config   PnLReconcilerConfig
+	stopChan chan struct{}
+}
+
+// NewPnLReconciler creates a new PnL reconciler
+func NewPnLReconciler(clients *ClientPool, store TradeStore, config PnLReconcilerConfig) *PnLReconciler {
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	if config.Lookback <= 0 {
+		config.Lookback = 7 * 24 * time.Hour
+	}
+
+	return &PnLReconciler{
+		clients:  clients,
+		store:    store,
+		config:   config,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs the reconciliation loop in the background
+func (r *PnLReconciler) Start() {
+	logging.Info().Msgf("PnL reconciler started (interval=%v, lookback=%v)", r.config.Interval, r.config.Lookback)
+
+	go func() {
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		r.Reconcile()
+		for {
+			select {
+			case <-ticker.C:
+				r.Reconcile()
+			case <-r.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the reconciliation loop
+func (r *PnLReconciler) Stop() {
+	close(r.stopChan)
+}
+
+// Reconcile matches closed trades to REALIZED_PNL income and corrects their PnL
+func (r *PnLReconciler) Reconcile() {
+	ctx := tradehistory.WithSource(context.Background(), tradehistory.SourcePnLReconciler)
+
+	trades, err := r.store.GetAllTrades(ctx)
+	if err != nil {
+		logging.Warn().Err(err).Msg("PnL reconciler: failed to get trades")
+		return
+	}
+
+	cutoff := time.Now().Add(-r.config.Lookback).Unix()
+	closed := []*models.Trade{}
+	for _, trade := range trades {
+		if trade.Status == "CLOSED" && trade.ClosedAt >= cutoff {
+			closed = append(closed, trade)
+		}
+	}
+
+	now := time.Now().Unix()
+	matched, unmatched, ambiguous, noAccount := 0, 0, 0, 0
+	for _, trade := range closed {
+		if !reconcileDue(trade, now) {
+			continue
+		}
+
+		status := r.reconcileTrade(ctx, trade, closed)
+		if status == "" {
+			// Income could not be read: leave the trade for the next run
+			continue
+		}
+		trade.ReconciledAt = now
+
+		switch status {
+		case ReconcileMatched:
+			matched++
+		case ReconcileUnmatched:
+			unmatched++
+		case ReconcileAmbiguous:
+			ambiguous++
+		case ReconcileNoAccount:
+			noAccount++
+		}
+
+		if err := r.store.UpdateTrade(ctx, trade); err != nil {
+			logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("PnL reconciler: failed to update trade %s", trade.ID)
+		}
+	}
+
+	if matched+unmatched+ambiguous+noAccount > 0 {
+		logging.Info().Msgf("PnL reconciler: matched=%d unmatched=%d ambiguous=%d noAccount=%d", matched, unmatched, ambiguous, noAccount)
+	}
+}
+
+// reconcileDue reports whether a trade needs (another) reconciliation.
+// MATCHED, AMBIGUOUS and NO_ACCOUNT are final. An UNMATCHED trade is retried in case its
+// income showed up late, each time after waiting as long as it had been
+// closed at the previous attempt, so retries back off until the lookback
+// window drops the trade.
+func reconcileDue(trade *models.Trade, now int64) bool {
+	switch trade.ReconcileStatus {
+	case ReconcileMatched, ReconcileAmbiguous, ReconcileNoAccount:
+		return false
+	case ReconcileUnmatched:
+		return now-trade.ReconciledAt >= trade.ReconciledAt-trade.ClosedAt
+	default:
+		return true
+	}
+}
+
+// reconcileTrade sums REALIZED_PNL for the trade's symbol while it was open.
+// It returns "" when the income could not be read, leaving the trade as it was.
+func (r *PnLReconciler) reconcileTrade(ctx context.Context, trade *models.Trade, closed []*models.Trade) string {
+	openedAt := trade.ExecutedAt
+	if openedAt == 0 {
+		openedAt = trade.CreatedAt
+	}
+
+	// Income records carry no trade ID of ours, so overlapping trades on the
+	// same symbol and account cannot be told apart
+	for _, other := range closed {
+		if other.ID != trade.ID && other.Symbol == trade.Symbol && other.Account == trade.Account &&
+			other.CreatedAt < trade.ClosedAt && other.ClosedAt > openedAt {
+			trade.ReconcileStatus = ReconcileAmbiguous
+			return trade.ReconcileStatus
+		}
+	}
+
+	client, err := r.clientFor(ctx, trade)
+	if err != nil {
+		trade.ReconcileStatus = ReconcileNoAccount
+		logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("PnL reconciler: no client for trade %s (account %q)", trade.ID, trade.Account)
+		return trade.ReconcileStatus
+	}
+
+	// Allow a little slack for the close fill being recorded after ClosedAt
+	records, err := client.GetIncomeRecords(ctx, "REALIZED_PNL", trade.Symbol, openedAt, trade.ClosedAt+60)
+	if err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("PnL reconciler: failed to get income for %s", trade.ID)
+		return ""
+	}
+
+	if len(records) == 0 {
+		trade.ReconcileStatus = ReconcileUnmatched
+		logging.Warn().Str(logging.FieldTradeID, trade.ID).Str(logging.FieldSymbol, trade.Symbol).Msgf("PnL reconciler: no REALIZED_PNL income found for trade %s (%s)", trade.ID, trade.Symbol)
+		return trade.ReconcileStatus
+	}
+
+	exchangePnL := 0.0
+	for _, record := range records {
+		exchangePnL += record.Income
+	}
+
+	discrepancy := exchangePnL - trade.PnL
+	if math.Abs(discrepancy) >= r.config.Tolerance {
+		trade.PnLDiscrepancy = discrepancy
+		logging.Info().Str(logging.FieldTradeID, trade.ID).Msgf("PnL reconciler: trade %s PnL corrected %.4f → %.4f", trade.ID, trade.PnL, exchangePnL)
+	}
+
+	trade.PnL = exchangePnL
+	trade.ReconcileStatus = ReconcileMatched
+	return trade.ReconcileStatus
+}
+
+// clientFor returns the client of the account a trade was placed on
+func (r *PnLReconciler) clientFor(ctx context.Context, trade *models.Trade) (*Client, error) {
+	if client, ok := r.config.Followers[trade.Account]; ok {
+		return client, nil
+	}
+	return r.clients.ForTrade(ctx, trade)
+}
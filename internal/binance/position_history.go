@@ -0,0 +1,255 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// accountTradesWindow is the longest range Binance accepts per account
+// trades request
+const accountTradesWindow = int64(7 * 24 * 60 * 60 * 1000)
+
+// positionDust is the quantity below which a position counts as flat
+const positionDust = 1e-9
+
+// ClosedPosition is a position rebuilt from account fills, from the fill
+// that opened it to the one that brought it back to flat
+type ClosedPosition struct {
+	Symbol          string  `json:"symbol"`
+	Side            string  `json:"side"`         // LONG or SHORT
+	PositionSide    string  `json:"positionSide"` // BOTH in one-way mode
+	Quantity        float64 `json:"quantity"`     // Largest size held
+	EntryPrice      float64 `json:"entryPrice"`   // Average of the opening fills
+	ExitPrice       float64 `json:"exitPrice"`    // Average of the closing fills
+	OpenedAt        int64   `json:"openedAt"`     // Unix seconds
+	ClosedAt        int64   `json:"closedAt"`     // Unix seconds
+	DurationSeconds int64   `json:"durationSeconds"`
+	RealizedPnL     float64 `json:"realizedPnl"`
+	Fees            float64 `json:"fees"`    // Commission, in the commission asset (normally USDT)
+	Funding         float64 `json:"funding"` // Funding fees received (+) or paid (-) while open
+	NetPnL          float64 `json:"netPnl"`  // Realized PnL - fees + funding
+	Fills           int     `json:"fills"`
+
+	openedMs int64
+	closedMs int64
+}
+
+// GetAccountTrades - Get every fill of a symbol in a time range (seconds),
+// paging through Binance's 7-day window and 1000-fill limit
+func (b *Client) GetAccountTrades(ctx context.Context, symbol string, startTime, endTime int64) ([]*futures.AccountTrade, error) {
+	const pageLimit = 1000
+
+	fills := []*futures.AccountTrade{}
+	endMs := endTime * 1000
+
+	for windowStart := startTime * 1000; windowStart < endMs; windowStart += accountTradesWindow {
+		windowEnd := min(windowStart+accountTradesWindow-1, endMs)
+
+		from := windowStart
+		for {
+			reqCtx, cancel := withTimeout(ctx)
+			page, err := b.client.NewListAccountTradeService().
+				Symbol(symbol).
+				StartTime(from).
+				EndTime(windowEnd).
+				Limit(pageLimit).
+				Do(reqCtx)
+			cancel()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get %s account trades: %v", symbol, err)
+			}
+
+			fills = append(fills, page...)
+			if len(page) < pageLimit {
+				break
+			}
+			from = page[len(page)-1].Time + 1
+		}
+	}
+
+	return fills, nil
+}
+
+// GetPositionHistory - Rebuild positions closed in a time range (seconds)
+// from account fills and funding income. Without a symbol, every symbol
+// with realized PnL in the range is included. Positions opened before the
+// range are skipped, since their opening fills are missing.
+func (b *Client) GetPositionHistory(ctx context.Context, symbol string, startTime, endTime int64) ([]*ClosedPosition, error) {
+	symbols := []string{symbol}
+	if symbol == "" {
+		pnl, err := b.GetIncomeRecordsRange(ctx, "REALIZED_PNL", startTime, endTime)
+		if err != nil {
+			return nil, err
+		}
+		symbols = incomeSymbols(pnl)
+	}
+
+	funding, err := b.GetIncomeRecordsRange(ctx, "FUNDING_FEE", startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := []*ClosedPosition{}
+	for _, sym := range symbols {
+		fills, err := b.GetAccountTrades(ctx, sym, startTime, endTime)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, ReconstructPositions(fills)...)
+	}
+
+	for _, pos := range positions {
+		for _, income := range funding {
+			if income.Symbol == pos.Symbol && income.Time >= pos.openedMs && income.Time <= pos.closedMs {
+				pos.Funding += income.Income
+			}
+		}
+		pos.NetPnL += pos.Funding
+	}
+
+	// Most recently closed first
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].closedMs > positions[j].closedMs
+	})
+
+	return positions, nil
+}
+
+// ReconstructPositions replays fills (of any symbols) in order and returns
+// each position that went from flat back to flat. Hedge-mode LONG and SHORT
+// positions are tracked separately. A fill that flips a one-way position
+// closes it and opens the opposite one with the remainder.
+func ReconstructPositions(fills []*futures.AccountTrade) []*ClosedPosition {
+	sorted := make([]*futures.AccountTrade, len(fills))
+	copy(sorted, fills)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Time != sorted[j].Time {
+			return sorted[i].Time < sorted[j].Time
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	type openPosition struct {
+		pos       *ClosedPosition
+		size      float64 // Signed: positive long, negative short
+		entryCost float64
+		entryQty  float64
+		exitCost  float64
+		exitQty   float64
+	}
+	open := make(map[string]*openPosition)
+	closed := []*ClosedPosition{}
+
+	for _, fill := range sorted {
+		qty, _ := strconv.ParseFloat(fill.Quantity, 64)
+		price, _ := strconv.ParseFloat(fill.Price, 64)
+		commission, _ := strconv.ParseFloat(fill.Commission, 64)
+		realized, _ := strconv.ParseFloat(fill.RealizedPnl, 64)
+		if qty <= 0 {
+			continue
+		}
+
+		delta := qty
+		if fill.Side == futures.SideTypeSell {
+			delta = -qty
+		}
+
+		key := fill.Symbol + "/" + string(fill.PositionSide)
+		cur := open[key]
+
+		if cur == nil {
+			// Realized PnL while flat means the fill closes a position
+			// opened before the first fill we have
+			if realized != 0 {
+				continue
+			}
+			cur = &openPosition{pos: newClosedPosition(fill, delta)}
+			open[key] = cur
+		}
+		cur.pos.Fills++
+
+		// Adding to the position
+		if (delta > 0) == (cur.size > 0) || cur.size == 0 {
+			cur.size += delta
+			cur.entryCost += qty * price
+			cur.entryQty += qty
+			cur.pos.Fees += commission
+			cur.pos.Quantity = math.Max(cur.pos.Quantity, math.Abs(cur.size))
+			continue
+		}
+
+		// Reducing it, possibly through zero
+		closeQty := math.Min(qty, math.Abs(cur.size))
+		share := closeQty / qty
+		cur.size += math.Copysign(closeQty, delta)
+		cur.exitCost += closeQty * price
+		cur.exitQty += closeQty
+		cur.pos.RealizedPnL += realized
+		cur.pos.Fees += commission * share
+
+		if math.Abs(cur.size) > positionDust {
+			continue
+		}
+
+		pos := cur.pos
+		pos.EntryPrice = cur.entryCost / cur.entryQty
+		pos.ExitPrice = cur.exitCost / cur.exitQty
+		pos.closedMs = fill.Time
+		pos.ClosedAt = fill.Time / 1000
+		pos.DurationSeconds = (pos.closedMs - pos.openedMs) / 1000
+		pos.NetPnL = pos.RealizedPnL - pos.Fees
+		closed = append(closed, pos)
+		delete(open, key)
+
+		if remainder := qty - closeQty; remainder > positionDust {
+			next := &openPosition{
+				pos:       newClosedPosition(fill, delta),
+				size:      math.Copysign(remainder, delta),
+				entryCost: remainder * price,
+				entryQty:  remainder,
+			}
+			next.pos.Fills = 1
+			next.pos.Fees = commission * (1 - share)
+			next.pos.Quantity = remainder
+			open[key] = next
+		}
+	}
+
+	return closed
+}
+
+// newClosedPosition starts a position at its opening fill
+func newClosedPosition(fill *futures.AccountTrade, delta float64) *ClosedPosition {
+	side := "LONG"
+	if fill.PositionSide == futures.PositionSideTypeShort || (fill.PositionSide != futures.PositionSideTypeLong && delta < 0) {
+		side = "SHORT"
+	}
+
+	return &ClosedPosition{
+		Symbol:       fill.Symbol,
+		Side:         side,
+		PositionSide: string(fill.PositionSide),
+		OpenedAt:     fill.Time / 1000,
+		openedMs:     fill.Time,
+	}
+}
+
+// incomeSymbols lists the distinct symbols of income records
+func incomeSymbols(records []*models.IncomeRecord) []string {
+	seen := make(map[string]bool)
+	symbols := []string{}
+	for _, record := range records {
+		if record.Symbol != "" && !seen[record.Symbol] {
+			seen[record.Symbol] = true
+			symbols = append(symbols, record.Symbol)
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
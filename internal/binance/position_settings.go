@@ -0,0 +1,202 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// Changing a symbol's leverage or margin type outside of an order is checked
+// against the open position first, so the caller gets a clear reason instead
+// of Binance's rejection:
+//   - leverage cannot exceed the symbol's first bracket, nor leave the open
+//     position above the maximum notional at the new leverage (-2027)
+//   - an ISOLATED position's leverage cannot be lowered (-4161)
+//   - the margin type cannot change with an open position (-4048) or open
+//     orders (-4047) on the symbol
+
+// ErrPositionConstraint is returned for a change the symbol's open position
+// or orders do not allow
+var ErrPositionConstraint = errors.New("not allowed with the open position or orders")
+
+// ErrLeverageRange is returned for a leverage the symbol does not offer
+var ErrLeverageRange = errors.New("leverage out of range")
+
+// LeverageChange is the result of a leverage change
+type LeverageChange struct {
+	Symbol           string  `json:"symbol" example:"BTCUSDT"`
+	Leverage         int     `json:"leverage" example:"10"`
+	PreviousLeverage int     `json:"previousLeverage,omitempty" example:"20"`
+	MaxLeverage      int     `json:"maxLeverage" example:"125"`
+	MaxNotionalValue float64 `json:"maxNotionalValue" example:"10000000"` // Largest position at the new leverage
+}
+
+// MarginTypeChange is the result of a margin type change
+type MarginTypeChange struct {
+	Symbol     string `json:"symbol" example:"BTCUSDT"`
+	MarginType string `json:"marginType" example:"ISOLATED"`
+	Changed    bool   `json:"changed" example:"true"` // false when the symbol already had it
+}
+
+// symbolExposure is a symbol's open position, summed over position sides
+type symbolExposure struct {
+	notional   float64 // Absolute notional
+	marginType string  // ISOLATED or CROSSED
+	leverage   int
+}
+
+// open reports whether the symbol has a position
+func (e symbolExposure) open() bool {
+	return e.notional > 0
+}
+
+// exposure returns a symbol's open position from position risk
+func (b *Client) exposure(ctx context.Context, symbol string) (symbolExposure, error) {
+	positions, err := b.client.NewGetPositionRiskService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return symbolExposure{}, fmt.Errorf("failed to get position: %v", err)
+	}
+
+	var exposure symbolExposure
+	for _, position := range positions {
+		exposure.marginType = normalizeMarginType(position.MarginType)
+		exposure.leverage, _ = strconv.Atoi(position.Leverage)
+		amount, _ := strconv.ParseFloat(position.PositionAmt, 64)
+		if amount == 0 {
+			continue
+		}
+		notional, _ := strconv.ParseFloat(position.Notional, 64)
+		exposure.notional += math.Abs(notional)
+	}
+	return exposure, nil
+}
+
+// leverageBrackets returns a symbol's notional brackets, highest leverage first
+func (b *Client) leverageBrackets(ctx context.Context, symbol string) ([]futures.Bracket, error) {
+	brackets, err := b.client.NewGetLeverageBracketService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leverage brackets: %v", err)
+	}
+	for _, bracket := range brackets {
+		if bracket.Symbol == symbol && len(bracket.Brackets) > 0 {
+			return bracket.Brackets, nil
+		}
+	}
+	return nil, fmt.Errorf("no leverage brackets for symbol %s", symbol)
+}
+
+// maxNotionalAt is the largest position notional the brackets allow at a
+// leverage
+func maxNotionalAt(brackets []futures.Bracket, leverage int) float64 {
+	var maxNotional float64
+	for _, bracket := range brackets {
+		if bracket.InitialLeverage >= leverage {
+			maxNotional = math.Max(maxNotional, bracket.NotionalCap)
+		}
+	}
+	return maxNotional
+}
+
+// ChangeLeverage sets a symbol's leverage, checked against its brackets and
+// open position
+func (b *Client) ChangeLeverage(ctx context.Context, symbol string, leverage int) (*LeverageChange, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	brackets, err := b.leverageBrackets(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	maxLeverage := brackets[0].InitialLeverage
+	if leverage < 1 || leverage > maxLeverage {
+		return nil, fmt.Errorf("%w: %s allows 1x to %dx, got %dx", ErrLeverageRange, symbol, maxLeverage, leverage)
+	}
+
+	exposure, err := b.exposure(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	maxNotional := maxNotionalAt(brackets, leverage)
+	if exposure.open() {
+		if exposure.marginType == "ISOLATED" && leverage < exposure.leverage {
+			return nil, fmt.Errorf("%w: cannot lower the leverage of the open ISOLATED %s position from %dx to %dx",
+				ErrPositionConstraint, symbol, exposure.leverage, leverage)
+		}
+		if exposure.notional > maxNotional {
+			return nil, fmt.Errorf("%w: the open %s position (%.2f notional) is above the %.2f maximum at %dx",
+				ErrPositionConstraint, symbol, exposure.notional, maxNotional, leverage)
+		}
+	}
+
+	result, err := b.client.NewChangeLeverageService().Symbol(symbol).Leverage(leverage).Do(ctx)
+	if err != nil {
+		b.settings.forget(symbol)
+		return nil, fmt.Errorf("failed to change leverage: %v", err)
+	}
+	b.settings.setLeverage(symbol, result.Leverage)
+	logging.Ctx(ctx).Info().Str(logging.FieldSymbol, symbol).Msgf("Leverage changed to %dx for %s", result.Leverage, symbol)
+
+	if parsed, err := strconv.ParseFloat(result.MaxNotionalValue, 64); err == nil {
+		maxNotional = parsed
+	}
+	return &LeverageChange{
+		Symbol:           symbol,
+		Leverage:         result.Leverage,
+		PreviousLeverage: exposure.leverage,
+		MaxLeverage:      maxLeverage,
+		MaxNotionalValue: maxNotional,
+	}, nil
+}
+
+// ChangeMarginType switches a symbol between ISOLATED and CROSSED margin.
+// Binance only allows it with no open position or orders on the symbol.
+func (b *Client) ChangeMarginType(ctx context.Context, symbol, marginType string) (*MarginTypeChange, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	change := &MarginTypeChange{Symbol: symbol, MarginType: marginType}
+
+	exposure, err := b.exposure(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if exposure.marginType == marginType {
+		b.settings.setMarginType(symbol, marginType)
+		return change, nil
+	}
+	if exposure.open() {
+		return nil, fmt.Errorf("%w: close the open %s position before switching it to %s margin", ErrPositionConstraint, symbol, marginType)
+	}
+
+	orders, err := b.client.NewListOpenOrdersService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open orders: %v", err)
+	}
+	if len(orders) > 0 {
+		return nil, fmt.Errorf("%w: cancel the %d open %s orders before switching it to %s margin",
+			ErrPositionConstraint, len(orders), symbol, marginType)
+	}
+
+	err = b.client.NewChangeMarginTypeService().
+		Symbol(symbol).
+		MarginType(futures.MarginType(marginType)).
+		Do(ctx)
+	if err != nil {
+		// Error -4046 means "No need to change margin type"
+		if !strings.Contains(err.Error(), "-4046") {
+			b.settings.forget(symbol)
+			return nil, fmt.Errorf("failed to change margin type: %v", err)
+		}
+	} else {
+		change.Changed = true
+		logging.Ctx(ctx).Info().Str(logging.FieldSymbol, symbol).Msgf("Margin type changed to %s for %s", marginType, symbol)
+	}
+	b.settings.setMarginType(symbol, marginType)
+	return change, nil
+}
@@ -0,0 +1,128 @@
+package binance
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// priceCache holds the latest streamed prices per symbol: mark prices from
+// the shared price feed and last trade prices from aggTrade streams. Like the
+// server clock it is shared by every client, since market data is the same
+// for all accounts. Prices older than maxAge are ignored and callers go to
+// REST instead.
+type priceCache struct {
+	entries map[string]*cachedPrice
+	maxAge  time.Duration
+	hits    atomic.Int64 // GetPrice answered from a stream or the shared cache
+	misses  atomic.Int64 // GetPrice fell back to REST
+	mu      sync.RWMutex
+}
+
+type cachedPrice struct {
+	mark   float64
+	markAt time.Time
+	last   float64
+	lastAt time.Time
+}
+
+// PriceCacheStatus describes the shared price cache
+type PriceCacheStatus struct {
+	MaxAge  string `json:"maxAge" example:"5s"`
+	Symbols int    `json:"symbols" example:"12"`
+	Hits    int64  `json:"hits" example:"340"` // Prices served from the streams or the shared cache
+	Misses  int64  `json:"misses" example:"7"` // Prices fetched over REST
+}
+
+var prices = &priceCache{entries: make(map[string]*cachedPrice), maxAge: 5 * time.Second}
+
+// SetPriceMaxAge sets how old a streamed price may be before REST is used
+// instead (0 always uses REST)
+func SetPriceMaxAge(maxAge time.Duration) {
+	prices.mu.Lock()
+	defer prices.mu.Unlock()
+	prices.maxAge = maxAge
+}
+
+// PriceCacheStats returns the cache settings and how often it was used
+func PriceCacheStats() PriceCacheStatus {
+	prices.mu.RLock()
+	defer prices.mu.RUnlock()
+
+	return PriceCacheStatus{
+		MaxAge:  prices.maxAge.String(),
+		Symbols: len(prices.entries),
+		Hits:    prices.hits.Load(),
+		Misses:  prices.misses.Load(),
+	}
+}
+
+// putMark records a mark price
+func (c *priceCache) putMark(symbol string, price float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entry(symbol)
+	entry.mark, entry.markAt = price, time.Now()
+}
+
+// putLast records a last trade price
+func (c *priceCache) putLast(symbol string, price float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entry(symbol)
+	entry.last, entry.lastAt = price, time.Now()
+}
+
+// entry returns the symbol's prices, creating them (c.mu held)
+func (c *priceCache) entry(symbol string) *cachedPrice {
+	entry, ok := c.entries[symbol]
+	if !ok {
+		entry = &cachedPrice{}
+		c.entries[symbol] = entry
+	}
+	return entry
+}
+
+// price returns the most recent of the symbol's last and mark price, false
+// if neither is fresh
+func (c *priceCache) price(symbol string) (float64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[symbol]
+	if !ok {
+		return 0, false
+	}
+	if entry.lastAt.After(entry.markAt) {
+		return entry.last, c.fresh(entry.lastAt)
+	}
+	return entry.mark, c.fresh(entry.markAt)
+}
+
+// markPrice returns the symbol's mark price, false if it is not fresh
+func (c *priceCache) markPrice(symbol string) (float64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[symbol]
+	if !ok {
+		return 0, false
+	}
+	return entry.mark, c.fresh(entry.markAt)
+}
+
+// fresh reports whether a price stamped at t may still be used (c.mu held)
+func (c *priceCache) fresh(t time.Time) bool {
+	return !t.IsZero() && time.Since(t) <= c.maxAge
+}
+
+// freshMarkPrice returns the streamed mark price of a symbol when fresh,
+// otherwise fallback (typically the mark price of a REST response)
+func freshMarkPrice(symbol string, fallback float64) float64 {
+	if price, ok := prices.markPrice(symbol); ok {
+		return price
+	}
+	return fallback
+}
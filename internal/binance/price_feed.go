@@ -0,0 +1,344 @@
+package binance
+
+import (
+	"crypto-trading-api/internal/logging"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// Combined mark price stream connections
+const (
+	feedShardSize   = 50                     // Symbols multiplexed over one connection
+	feedResubscribe = 500 * time.Millisecond // Batches subscription changes into one reconnect
+)
+
+// PriceHandler receives mark price updates for a symbol
+type PriceHandler func(symbol string, price float64)
+
+// PriceFeed shares mark prices between any number of subscribers. Symbols are
+// multiplexed over combined stream connections of up to 50 symbols each: a
+// symbol joins a connection with its first subscriber and leaves it with its
+// last, and connections reconnect while anyone is still listening.
+type PriceFeed struct {
+	streams map[string]*feedStream
+	shards  []*feedShard
+	nextID  int
+	closed  bool
+	mu      sync.Mutex
+}
+
+type feedStream struct {
+	handlers   map[int]PriceHandler
+	lastPrice  float64
+	lastUpdate time.Time
+	shard      *feedShard
+}
+
+// feedShard is one combined stream connection
+type feedShard struct {
+	id        int
+	symbols   map[string]bool // Symbols with subscribers
+	serving   map[string]bool // Symbols of the live connection
+	connected bool
+	stopC     chan struct{}
+	changed   chan struct{} // Symbols were added, or the shard emptied
+	closed    bool
+}
+
+// FeedStatus describes one symbol of the shared mark price feed
+type FeedStatus struct {
+	Symbol      string  `json:"symbol" example:"BTCUSDT"`
+	Connection  int     `json:"connection" example:"1"` // Combined stream connection carrying the symbol
+	Connected   bool    `json:"connected" example:"true"`
+	LastPrice   float64 `json:"lastPrice" example:"50250"`
+	LastUpdate  string  `json:"lastUpdate" example:"2024-01-01T12:00:00Z"` // Empty until the first price arrives
+	Subscribers int     `json:"subscribers" example:"2"`
+}
+
+// NewPriceFeed creates a shared price feed
+func NewPriceFeed() *PriceFeed {
+	return &PriceFeed{streams: make(map[string]*feedStream)}
+}
+
+// Subscribe registers a handler for a symbol and returns a function that removes it
+func (f *PriceFeed) Subscribe(symbol string, handler PriceHandler) func() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return func() {}
+	}
+
+	stream, ok := f.streams[symbol]
+	if !ok {
+		stream = &feedStream{handlers: make(map[int]PriceHandler)}
+		f.streams[symbol] = stream
+		f.assign(symbol, stream)
+	}
+
+	f.nextID++
+	id := f.nextID
+	stream.handlers[id] = handler
+
+	return func() { f.unsubscribe(symbol, id) }
+}
+
+// assign adds a symbol to a connection, preferring one that still carries it,
+// then one with room, and opens a new connection when all are full (f.mu held)
+func (f *PriceFeed) assign(symbol string, stream *feedStream) {
+	var shard *feedShard
+	for _, s := range f.shards {
+		if s.serving[symbol] && !s.closed {
+			shard = s
+			break
+		}
+	}
+	if shard == nil {
+		for _, s := range f.shards {
+			if len(s.symbols) < feedShardSize && !s.closed {
+				shard = s
+				break
+			}
+		}
+	}
+	if shard == nil {
+		id := 1
+		if len(f.shards) > 0 {
+			id = f.shards[len(f.shards)-1].id + 1
+		}
+		shard = &feedShard{
+			id:      id,
+			symbols: make(map[string]bool),
+			serving: make(map[string]bool),
+			changed: make(chan struct{}, 1),
+		}
+		f.shards = append(f.shards, shard)
+		go f.run(shard)
+	}
+
+	shard.symbols[symbol] = true
+	stream.shard = shard
+	if !shard.serving[symbol] {
+		shard.notify()
+	}
+}
+
+// LastPrice returns the latest mark price seen for a symbol (0 if not streaming)
+func (f *PriceFeed) LastPrice(symbol string) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if stream, ok := f.streams[symbol]; ok {
+		return stream.lastPrice
+	}
+	return 0
+}
+
+// Symbols returns the symbols currently streamed
+func (f *PriceFeed) Symbols() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	symbols := make([]string, 0, len(f.streams))
+	for symbol := range f.streams {
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
+// Status returns every streamed symbol with the time of its last price
+func (f *PriceFeed) Status() []FeedStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status := make([]FeedStatus, 0, len(f.streams))
+	for symbol, stream := range f.streams {
+		status = append(status, FeedStatus{
+			Symbol:      symbol,
+			Connection:  stream.shard.id,
+			Connected:   stream.shard.connected && stream.shard.serving[symbol],
+			LastPrice:   stream.lastPrice,
+			LastUpdate:  formatStreamTime(stream.lastUpdate),
+			Subscribers: len(stream.handlers),
+		})
+	}
+	return status
+}
+
+func (f *PriceFeed) unsubscribe(symbol string, id int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stream, ok := f.streams[symbol]
+	if !ok {
+		return
+	}
+
+	delete(stream.handlers, id)
+	if len(stream.handlers) > 0 {
+		return
+	}
+
+	// The symbol stays on the live connection until it next reconnects;
+	// its prices are dropped meanwhile
+	delete(f.streams, symbol)
+	shard := stream.shard
+	delete(shard.symbols, symbol)
+	if len(shard.symbols) == 0 {
+		shard.closed = true
+		shard.notify()
+	}
+	logging.Info().Str(logging.FieldSymbol, symbol).Msgf("Price feed closed for %s", symbol)
+}
+
+// Close disconnects every connection; later subscriptions receive nothing
+func (f *PriceFeed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	for _, shard := range f.shards {
+		shard.closed = true
+		if shard.stopC != nil {
+			close(shard.stopC)
+			shard.stopC = nil
+		}
+		shard.notify()
+	}
+	logging.Info().Msg("Price feed closed")
+}
+
+// notify wakes the shard's connection loop
+func (s *feedShard) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// run keeps a connection streaming its shard's symbols until the shard
+// empties. New symbols are picked up by opening a connection with the new
+// set before closing the old one, so prices of the others keep flowing.
+func (f *PriceFeed) run(shard *feedShard) {
+	backoff := time.Second
+
+	for {
+		// Let a burst of subscriptions settle into one connection
+		time.Sleep(feedResubscribe)
+		select {
+		case <-shard.changed:
+		default:
+		}
+
+		f.mu.Lock()
+		if shard.closed {
+			if shard.stopC != nil {
+				close(shard.stopC)
+			}
+			for i, s := range f.shards {
+				if s == shard {
+					f.shards = append(f.shards[:i], f.shards[i+1:]...)
+					break
+				}
+			}
+			f.mu.Unlock()
+			logging.Info().Msgf("Price feed connection %d closed", shard.id)
+			return
+		}
+		symbols := make([]string, 0, len(shard.symbols))
+		for symbol := range shard.symbols {
+			symbols = append(symbols, symbol)
+		}
+		f.mu.Unlock()
+
+		errHandler := func(err error) {
+			logging.Warn().Err(err).Msgf("Price feed connection %d error", shard.id)
+		}
+
+		doneC, stopC, err := futures.WsCombinedMarkPriceServe(symbols, f.dispatch, errHandler)
+		if err != nil {
+			logging.Warn().Msgf("Price feed connection %d failed to connect: %v (retry in %v)", shard.id, err, backoff)
+			select {
+			case <-time.After(backoff):
+			case <-shard.changed:
+				shard.notify() // Handled at the top of the loop
+			}
+			if backoff < time.Minute {
+				backoff *= 2
+			}
+			continue
+		}
+
+		f.mu.Lock()
+		previous := shard.stopC
+		shard.stopC = stopC
+		shard.connected = true
+		shard.serving = make(map[string]bool, len(symbols))
+		for _, symbol := range symbols {
+			shard.serving[symbol] = true
+		}
+		f.mu.Unlock()
+		if previous != nil {
+			close(previous)
+		}
+
+		logging.Info().Msgf("Price feed connection %d streaming %d symbols", shard.id, len(symbols))
+		connectedAt := time.Now()
+
+		select {
+		case <-doneC:
+			f.mu.Lock()
+			if shard.stopC == stopC {
+				shard.stopC = nil
+				shard.connected = false
+			}
+			f.mu.Unlock()
+
+			// Back off from connections that drop right away
+			if time.Since(connectedAt) > time.Minute {
+				backoff = time.Second
+			} else {
+				time.Sleep(backoff)
+				if backoff < time.Minute {
+					backoff *= 2
+				}
+			}
+		case <-shard.changed:
+			shard.notify() // Handled at the top of the loop
+		}
+	}
+}
+
+// dispatch hands a mark price to the symbol's subscribers
+func (f *PriceFeed) dispatch(event *futures.WsMarkPriceEvent) {
+	price, err := strconv.ParseFloat(event.MarkPrice, 64)
+	if err != nil {
+		return
+	}
+
+	prices.putMark(event.Symbol, price)
+	if shared != nil {
+		go setSharedPrice(event.Symbol, price)
+	}
+
+	f.mu.Lock()
+	stream, ok := f.streams[event.Symbol]
+	if !ok {
+		f.mu.Unlock()
+		return
+	}
+	stream.lastPrice = price
+	stream.lastUpdate = time.Now()
+	handlers := make([]PriceHandler, 0, len(stream.handlers))
+	for _, h := range stream.handlers {
+		handlers = append(handlers, h)
+	}
+	f.mu.Unlock()
+
+	for _, h := range handlers {
+		h(event.Symbol, price)
+	}
+}
@@ -0,0 +1,219 @@
+package binance
+
+import (
+	"bytes"
+	"context"
+	"crypto-trading-api/internal/httpclient"
+	"crypto-trading-api/internal/resilience"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RequestPolicy sets the retries, circuit breaker and connection pool of
+// Binance REST calls, set from config at startup by SetRequestPolicy
+type RequestPolicy struct {
+	Retries         int           // Further attempts after a network error, 429 or 5xx
+	RetryBackoff    time.Duration // Wait before the first retry, doubled for each one after
+	BreakerFailures int           // Failed calls in a row that open the circuit
+	BreakerReset    time.Duration // How long an open circuit rejects calls before testing again
+	HTTP            httpclient.Config
+}
+
+var requestPolicy = RequestPolicy{
+	Retries:         2,
+	RetryBackoff:    250 * time.Millisecond,
+	BreakerFailures: 5,
+	BreakerReset:    30 * time.Second,
+}
+
+// httpTransport is the connection pool every client shares, so follower,
+// operator and per-user accounts reuse the same warm connections
+var httpTransport http.RoundTripper = httpclient.NewTransport(httpclient.Config{})
+
+// maxRetryBackoff caps the wait between retries
+const maxRetryBackoff = 5 * time.Second
+
+// SetRequestPolicy sets the retry, circuit breaker and connection pool
+// settings. Call it before the first client is created: clients keep the
+// breaker and pool they were created with. Zero fields keep the default;
+// Retries < 0 disables retries.
+func SetRequestPolicy(policy RequestPolicy) {
+	if policy.Retries > 0 {
+		requestPolicy.Retries = policy.Retries
+	} else if policy.Retries < 0 {
+		requestPolicy.Retries = 0
+	}
+	if policy.RetryBackoff > 0 {
+		requestPolicy.RetryBackoff = policy.RetryBackoff
+	}
+	if policy.BreakerFailures > 0 {
+		requestPolicy.BreakerFailures = policy.BreakerFailures
+	}
+	if policy.BreakerReset > 0 {
+		requestPolicy.BreakerReset = policy.BreakerReset
+	}
+	requestPolicy.HTTP = policy.HTTP
+	httpTransport = httpclient.NewTransport(policy.HTTP)
+}
+
+// nonRepeatable lists the endpoints never retried: a request that timed out
+// or got a 5xx may still have been executed, and sending it again would open
+// a second position or move margin twice. Reads and idempotent changes
+// (leverage, margin type, cancels, listen keys) are retried.
+var nonRepeatable = map[string]bool{
+	"POST /fapi/v1/order":          true,
+	"POST /fapi/v1/batchOrders":    true,
+	"POST /fapi/v1/positionMargin": true,
+	"POST /api/v3/order":           true,
+}
+
+// retryConfig returns the retry policy of an endpoint
+func retryConfig(method, path string) *RetryConfig {
+	retries := requestPolicy.Retries
+	if nonRepeatable[method+" "+path] {
+		retries = 0
+	}
+	return &RetryConfig{
+		MaxRetries:     retries,
+		InitialBackoff: requestPolicy.RetryBackoff,
+		MaxBackoff:     maxRetryBackoff,
+		BackoffFactor:  2.0,
+	}
+}
+
+// breakers holds one circuit breaker per Binance host (futures, spot),
+// shared by every client since they all reach Binance from this server
+var breakers = struct {
+	sync.Mutex
+	byHost map[string]*resilience.CircuitBreaker
+}{byHost: make(map[string]*resilience.CircuitBreaker)}
+
+// breakerFor returns the circuit breaker of a host
+func breakerFor(host string) *resilience.CircuitBreaker {
+	breakers.Lock()
+	defer breakers.Unlock()
+	breaker, ok := breakers.byHost[host]
+	if !ok {
+		breaker = resilience.NewCircuitBreaker(requestPolicy.BreakerFailures, requestPolicy.BreakerReset)
+		breakers.byHost[host] = breaker
+	}
+	return breaker
+}
+
+// CircuitStates returns the circuit breaker of each Binance host called so far
+func CircuitStates() map[string]resilience.CircuitStatus {
+	breakers.Lock()
+	defer breakers.Unlock()
+	states := make(map[string]resilience.CircuitStatus, len(breakers.byHost))
+	for host, breaker := range breakers.byHost {
+		states[host] = breaker.Status()
+	}
+	return states
+}
+
+// resilientTransport sends every Binance REST call through its host's
+// circuit breaker, retrying network errors, rate limits (429) and 5xx
+// responses as the endpoint's policy allows. Requests Binance rejects (4xx)
+// are returned at once and do not count against the breaker; neither do
+// calls cancelled by the caller. Each attempt is signed again by base.
+type resilientTransport struct {
+	base http.RoundTripper
+}
+
+func newResilientTransport(base http.RoundTripper) *resilientTransport {
+	return &resilientTransport{base: base}
+}
+
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var resp *http.Response
+	var sendErr error
+	err := breakerFor(req.URL.Host).Execute(func() error {
+		err := ExecuteWithRetry(ctx, func() error {
+			if resp != nil {
+				// The previous attempt failed and is being retried
+				resp.Body.Close()
+			}
+			attempt := req.Clone(ctx)
+			if body != nil {
+				attempt.Body = io.NopCloser(bytes.NewReader(body))
+			}
+			resp, sendErr = t.base.RoundTrip(attempt)
+			return attemptError(ctx, resp, sendErr)
+		}, retryConfig(req.Method, req.URL.Path))
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	})
+	if errors.Is(err, resilience.ErrCircuitOpen) {
+		return nil, err
+	}
+	return resp, sendErr
+}
+
+// attemptError classifies an attempt: nil when it succeeded, Binance
+// rejected the request or the caller cancelled it, otherwise the failure to
+// retry and count against the breaker. Rate limits and IP bans are
+// classified by HandleBinanceError.
+func attemptError(ctx context.Context, resp *http.Response, err error) error {
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return nil
+		}
+		return err
+	}
+
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot:
+		classified := HandleBinanceError(responseError(resp))
+		LogBinanceError(classified)
+		return classified
+	}
+	return nil
+}
+
+// responseError describes an error response in go-binance's format, leaving
+// the body readable
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	code, message := apiErrorBody(body)
+	if code == 0 {
+		return fmt.Errorf("status code %d: %s", resp.StatusCode, string(body))
+	}
+	return fmt.Errorf("<APIError> code=%d, msg=%s (status code %d)", code, message, resp.StatusCode)
+}
+
+// apiErrorBody reads the code and message of a Binance error response, 0
+// when the body is not one
+func apiErrorBody(body []byte) (int, string) {
+	var apiErr struct {
+		Code    int    `json:"code"`
+		Message string `json:"msg"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil {
+		return 0, ""
+	}
+	return apiErr.Code, apiErr.Message
+}
@@ -0,0 +1,113 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// exchangeInfoTTL is how long exchange rules (symbol filters) are shared
+const exchangeInfoTTL = 5 * time.Minute
+
+// sharedCacheTimeout bounds a shared cache lookup so a slow cache never
+// delays a trade by more than going to Binance would
+const sharedCacheTimeout = 200 * time.Millisecond
+
+// SharedCache is a cache shared between server instances (Redis). Exchange
+// rules and prices are read from it before calling Binance, so instances
+// behind a load balancer share one set of REST lookups and price streams.
+type SharedCache interface {
+	GetJSON(ctx context.Context, key string, value interface{}) (bool, error)
+	SetJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+}
+
+var shared SharedCache
+
+// SetSharedCache enables the shared cache for every client. Call it at
+// startup, before any Binance call.
+func SetSharedCache(cache SharedCache) {
+	shared = cache
+}
+
+// sharedPrice is a price as shared between instances
+type sharedPrice struct {
+	Price float64 `json:"price"`
+	At    int64   `json:"at"` // Unix ms
+}
+
+// getSharedPrice returns a price another instance streamed or fetched, false
+// unless it is fresh
+func getSharedPrice(ctx context.Context, symbol string) (float64, bool) {
+	if shared == nil {
+		return 0, false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sharedCacheTimeout)
+	defer cancel()
+
+	var cached sharedPrice
+	found, err := shared.GetJSON(ctx, "binance:price:"+symbol, &cached)
+	if err != nil || !found {
+		return 0, false
+	}
+
+	prices.mu.RLock()
+	defer prices.mu.RUnlock()
+	if !prices.fresh(time.UnixMilli(cached.At)) {
+		return 0, false
+	}
+	return cached.Price, true
+}
+
+// setSharedPrice shares a price with the other instances
+func setSharedPrice(symbol string, price float64) {
+	if shared == nil {
+		return
+	}
+
+	prices.mu.RLock()
+	maxAge := prices.maxAge
+	prices.mu.RUnlock()
+	if maxAge <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sharedCacheTimeout)
+	defer cancel()
+
+	value := sharedPrice{Price: price, At: time.Now().UnixMilli()}
+	if err := shared.SetJSON(ctx, "binance:price:"+symbol, value, maxAge); err != nil {
+		logging.Warn().Err(err).Str(logging.FieldSymbol, symbol).Msgf("Shared cache: failed to store %s price", symbol)
+	}
+}
+
+// fetchExchangeInfo reads the exchange rules from the shared cache, or from
+// Binance (sharing them) when no instance has looked them up recently or
+// fromBinance is set
+func (b *Client) fetchExchangeInfo(ctx context.Context, fromBinance bool) (*futures.ExchangeInfo, error) {
+	if shared != nil && !fromBinance {
+		lookupCtx, cancel := context.WithTimeout(ctx, sharedCacheTimeout)
+		var cached futures.ExchangeInfo
+		found, err := shared.GetJSON(lookupCtx, "binance:exchangeInfo", &cached)
+		cancel()
+		if err == nil && found {
+			return &cached, nil
+		}
+	}
+
+	info, err := b.client.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if shared != nil {
+		storeCtx, cancel := context.WithTimeout(context.Background(), sharedCacheTimeout)
+		if err := shared.SetJSON(storeCtx, "binance:exchangeInfo", info, exchangeInfoTTL); err != nil {
+			logging.Warn().Err(err).Msg("Shared cache: failed to store exchange info")
+		}
+		cancel()
+	}
+	return info, nil
+}
@@ -0,0 +1,65 @@
+package binance
+
+import (
+	"context"
+	"math"
+)
+
+// MaintenanceBracket is the leverage bracket a position's notional falls in,
+// which sets its maintenance margin and so its liquidation price
+type MaintenanceBracket struct {
+	NotionalCap      float64 `json:"notionalCap" example:"50000"`
+	MaintMarginRatio float64 `json:"maintMarginRatio" example:"0.004"`
+	MaintAmount      float64 `json:"maintAmount" example:"0"` // Binance's cum: maintenance margin not covered by the ratio
+	MaxLeverage      int     `json:"maxLeverage" example:"125"`
+}
+
+// MaintenanceBracket returns the bracket of a position notional on a symbol
+func (b *Client) MaintenanceBracket(ctx context.Context, symbol string, notional float64) (*MaintenanceBracket, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	brackets, err := b.leverageBrackets(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// Brackets run from the smallest notional up; the last has no limit
+	match := brackets[len(brackets)-1]
+	for _, bracket := range brackets {
+		if notional < bracket.NotionalCap {
+			match = bracket
+			break
+		}
+	}
+	return &MaintenanceBracket{
+		NotionalCap:      match.NotionalCap,
+		MaintMarginRatio: match.MaintMarginRatio,
+		MaintAmount:      match.Cum,
+		MaxLeverage:      match.InitialLeverage,
+	}, nil
+}
+
+// LiquidationPrice estimates the mark price at which a one-way position
+// backed by margin alone (ISOLATED) is liquidated, using Binance's formula.
+// CROSSED positions are also backed by the rest of the balance and last
+// longer. 0 means the position cannot be liquidated (a fully backed long).
+func LiquidationPrice(side string, quantity, entryPrice, margin float64, bracket *MaintenanceBracket) float64 {
+	direction := 1.0
+	if side == "SELL" {
+		direction = -1
+	}
+
+	price := (margin + bracket.MaintAmount - direction*quantity*entryPrice) /
+		(quantity*bracket.MaintMarginRatio - direction*quantity)
+	return math.Max(price, 0)
+}
+
+// BreakEvenPrice is the exit price at which a position's price PnL pays its
+// entry and exit fees
+func BreakEvenPrice(side string, entryPrice, entryRate, exitRate float64) float64 {
+	if side == "SELL" {
+		return entryPrice * (1 - entryRate) / (1 + exitRate)
+	}
+	return entryPrice * (1 + entryRate) / (1 - exitRate)
+}
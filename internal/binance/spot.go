@@ -0,0 +1,110 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	gobinance "github.com/adshao/go-binance/v2"
+)
+
+// SpotSymbolInfo holds the spot trading rules needed to size orders
+type SpotSymbolInfo struct {
+	Symbol    string
+	BaseAsset string
+	StepSize  float64
+	MinQty    float64
+	Trading   bool
+}
+
+// SpotOrderResult represents an executed spot market order
+type SpotOrderResult struct {
+	OrderID         int64
+	ExecutedQty     float64
+	AvgPrice        float64
+	Commission      float64
+	CommissionAsset string
+}
+
+// GetSpotSymbols - Get spot trading rules for every listed symbol
+func (b *Client) GetSpotSymbols(ctx context.Context) (map[string]*SpotSymbolInfo, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	info, err := b.spot.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spot exchange info: %v", err)
+	}
+
+	symbols := make(map[string]*SpotSymbolInfo, len(info.Symbols))
+	for i := range info.Symbols {
+		s := &info.Symbols[i]
+		symbol := &SpotSymbolInfo{
+			Symbol:    s.Symbol,
+			BaseAsset: s.BaseAsset,
+			Trading:   s.Status == "TRADING",
+		}
+		if lot := s.LotSizeFilter(); lot != nil {
+			symbol.StepSize, _ = strconv.ParseFloat(lot.StepSize, 64)
+			symbol.MinQty, _ = strconv.ParseFloat(lot.MinQuantity, 64)
+		}
+		symbols[s.Symbol] = symbol
+	}
+
+	return symbols, nil
+}
+
+// GetSpotSymbol - Get spot trading rules for one symbol
+func (b *Client) GetSpotSymbol(ctx context.Context, symbol string) (*SpotSymbolInfo, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	info, err := b.spot.NewExchangeInfoService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spot symbol info: %v", err)
+	}
+
+	for i := range info.Symbols {
+		s := &info.Symbols[i]
+		if s.Symbol != symbol {
+			continue
+		}
+		result := &SpotSymbolInfo{Symbol: s.Symbol, BaseAsset: s.BaseAsset, Trading: s.Status == "TRADING"}
+		if lot := s.LotSizeFilter(); lot != nil {
+			result.StepSize, _ = strconv.ParseFloat(lot.StepSize, 64)
+			result.MinQty, _ = strconv.ParseFloat(lot.MinQuantity, 64)
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("spot symbol %s not found", symbol)
+}
+
+// PlaceSpotMarketOrder - Execute a spot market order for a base-asset quantity
+func (b *Client) PlaceSpotMarketOrder(ctx context.Context, symbol, side, quantity string) (*SpotOrderResult, error) {
+	ctx, cancel := withOrderTimeout(ctx)
+	defer cancel()
+	order, err := b.spot.NewCreateOrderService().
+		Symbol(symbol).
+		Side(gobinance.SideType(side)).
+		Type(gobinance.OrderTypeMarket).
+		Quantity(quantity).
+		NewOrderRespType(gobinance.NewOrderRespTypeFULL).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to place spot order: %v", err)
+	}
+
+	result := &SpotOrderResult{OrderID: order.OrderID}
+	result.ExecutedQty, _ = strconv.ParseFloat(order.ExecutedQuantity, 64)
+	quoteQty, _ := strconv.ParseFloat(order.CummulativeQuoteQuantity, 64)
+	if result.ExecutedQty > 0 {
+		result.AvgPrice = quoteQty / result.ExecutedQty
+	}
+
+	for _, fill := range order.Fills {
+		commission, _ := strconv.ParseFloat(fill.Commission, 64)
+		result.Commission += commission
+		result.CommissionAsset = fill.CommissionAsset
+	}
+
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// Trigger is the price closing SL/TP orders trigger on
+type Trigger struct {
+	WorkingType  string // models.WorkingTypeMark or models.WorkingTypeLast ("" = last price)
+	PriceProtect bool   // Do not trigger while mark and last price diverge too far
+}
+
+// TradeTrigger returns the trigger of a trade's SL/TP orders
+func TradeTrigger(trade *models.Trade) Trigger {
+	return Trigger{WorkingType: trade.WorkingType, PriceProtect: trade.PriceProtect}
+}
+
+// apply sets the trigger on an order. Binance's default is the last price
+// (CONTRACT_PRICE) without price protection.
+func (t Trigger) apply(service *futures.CreateOrderService) *futures.CreateOrderService {
+	if t.WorkingType == models.WorkingTypeMark {
+		service.WorkingType(futures.WorkingTypeMarkPrice)
+	}
+	if t.PriceProtect {
+		service.PriceProtect(true)
+	}
+	return service
+}
+
+// ReplaceStopLoss moves an open trade's stop loss to stopPrice and records
+// the new order on the trade. Binance allows one closePosition stop per side
+// (-4130), so the current stop is cancelled first; if the new one is
+// rejected, the previous stop is placed again.
+func (b *Client) ReplaceStopLoss(ctx context.Context, trade *models.Trade, stopPrice float64) error {
+	return b.replaceProtectiveOrder(ctx, trade, "stop loss", &trade.StopLoss, &trade.SLOrderID, stopPrice, b.placeStopLoss)
+}
+
+// ReplaceTakeProfit moves an open trade's take profit to price the same way
+func (b *Client) ReplaceTakeProfit(ctx context.Context, trade *models.Trade, price float64) error {
+	return b.replaceProtectiveOrder(ctx, trade, "take profit", &trade.TakeProfit, &trade.TPOrderID, price, b.placeTakeProfit)
+}
+
+// placeFunc places a closePosition SL or TP order
+type placeFunc func(ctx context.Context, symbol, side, quantity string, price float64, trigger Trigger, tickSize string, pricePrecision int) (int64, error)
+
+// replaceProtectiveOrder cancels the order in *orderID and places a new one
+// at price, restoring the one at *current if the new one is rejected
+func (b *Client) replaceProtectiveOrder(ctx context.Context, trade *models.Trade, kind string, current *float64, orderID *int64, price float64, place placeFunc) error {
+	ctx, cancel := withOrderTimeout(logging.WithTrade(ctx, trade.ID, trade.Symbol))
+	defer cancel()
+
+	symbolInfo, err := b.getSymbolInfo(ctx, trade.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get symbol info: %v", err)
+	}
+
+	if *orderID != 0 {
+		err := b.CancelOrder(ctx, trade.Symbol, *orderID)
+		if err != nil && !strings.Contains(err.Error(), strconv.Itoa(ErrCodeUnknownOrder)) {
+			return fmt.Errorf("failed to cancel %s order %d: %v", kind, *orderID, err)
+		}
+	}
+
+	// The position lacks the order until one is placed: finish even if
+	// the caller gives up
+	ctx, cancelProtect := withOrderTimeout(context.WithoutCancel(ctx))
+	defer cancelProtect()
+
+	newOrderID, err := place(ctx, trade.Symbol, trade.Side, "", price, TradeTrigger(trade), symbolInfo.TickSize, symbolInfo.PricePrecision)
+	if err != nil {
+		*orderID = 0
+		if *current > 0 {
+			restored, restoreErr := place(ctx, trade.Symbol, trade.Side, "", *current, TradeTrigger(trade), symbolInfo.TickSize, symbolInfo.PricePrecision)
+			if restoreErr != nil {
+				logging.Ctx(ctx).Error().Err(restoreErr).Msgf("Failed to restore the %s of trade %s: position unprotected", kind, trade.ID)
+			} else {
+				*orderID = restored
+			}
+		}
+		return err
+	}
+
+	*current = price
+	*orderID = newOrderID
+	return nil
+}
+
+// CancelProtectiveOrders cancels the reduce-only and closePosition orders
+// left on a symbol, such as the SL/TP of a position just closed. Orders
+// already gone (-2011) are skipped. It returns how many were cancelled.
+func (b *Client) CancelProtectiveOrders(ctx context.Context, symbol string) (int, error) {
+	orders, err := b.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get open orders: %v", err)
+	}
+
+	cancelled := 0
+	for _, order := range orders {
+		if !isProtectiveOrder(order) {
+			continue
+		}
+		err := b.CancelOrder(ctx, symbol, order.OrderID)
+		if err != nil && !strings.Contains(err.Error(), strconv.Itoa(ErrCodeUnknownOrder)) {
+			return cancelled, fmt.Errorf("failed to cancel order %d: %v", order.OrderID, err)
+		}
+		if err == nil {
+			cancelled++
+		}
+	}
+	return cancelled, nil
+}
@@ -0,0 +1,125 @@
+package binance
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// submitPool bounds the order submissions sent to Binance at once. Calls
+// with the same key (one symbol of one account) run one at a time, in the
+// order they were submitted, so simultaneous signals on a symbol cannot
+// interleave their leverage, entry and SL/TP orders; calls on different
+// keys run concurrently on the free workers.
+type submitPool struct {
+	workers chan struct{}
+	tails   map[string]chan struct{} // Done channel of the last call queued per key
+	running atomic.Int64
+	waiting atomic.Int64
+	mu      sync.Mutex
+}
+
+// SubmitPoolStatus describes the order submission pool
+type SubmitPoolStatus struct {
+	Workers int   `json:"workers" example:"8"`
+	Running int64 `json:"running" example:"2"` // Submissions talking to Binance
+	Waiting int64 `json:"waiting" example:"0"` // Submissions queued behind their symbol or for a worker
+}
+
+var submissions atomic.Pointer[submitPool]
+
+func init() {
+	SetOrderWorkers(8)
+}
+
+// SetOrderWorkers sets how many order submissions run at once (minimum 1).
+// Call it at startup: submissions already queued finish on the old pool.
+func SetOrderWorkers(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	submissions.Store(&submitPool{
+		workers: make(chan struct{}, workers),
+		tails:   make(map[string]chan struct{}),
+	})
+}
+
+// OrderKey is the key submissions are ordered by: a symbol of an account
+// (the trade's Account, "" for the primary account)
+func OrderKey(account, symbol string) string {
+	return account + "/" + symbol
+}
+
+// SubmitOrder runs fn once the calls submitted before it under key are done
+// and a worker is free. It returns ctx's error, without running fn, if ctx
+// ends first. fn must not submit under the same key: it would wait on itself.
+func SubmitOrder(ctx context.Context, key string, fn func()) error {
+	return submissions.Load().do(ctx, key, fn)
+}
+
+// SubmitPoolStats returns the pool size and how busy it is
+func SubmitPoolStats() SubmitPoolStatus {
+	pool := submissions.Load()
+	return SubmitPoolStatus{
+		Workers: cap(pool.workers),
+		Running: pool.running.Load(),
+		Waiting: pool.waiting.Load(),
+	}
+}
+
+func (p *submitPool) do(ctx context.Context, key string, fn func()) error {
+	done := make(chan struct{})
+	p.mu.Lock()
+	previous := p.tails[key]
+	p.tails[key] = done
+	p.mu.Unlock()
+
+	p.waiting.Add(1)
+	waiting := true
+	stopWaiting := func() {
+		if waiting {
+			p.waiting.Add(-1)
+			waiting = false
+		}
+	}
+	defer stopWaiting()
+
+	if previous != nil {
+		select {
+		case <-previous:
+		case <-ctx.Done():
+			// The calls behind this one still wait for the one before it
+			go func() {
+				<-previous
+				p.finish(key, done)
+			}()
+			return ctx.Err()
+		}
+	}
+	defer p.finish(key, done)
+
+	select {
+	case p.workers <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	stopWaiting()
+	p.running.Add(1)
+	defer func() {
+		p.running.Add(-1)
+		<-p.workers
+	}()
+
+	fn()
+	return nil
+}
+
+// finish releases the next call of key
+func (p *submitPool) finish(key string, done chan struct{}) {
+	p.mu.Lock()
+	if p.tails[key] == done {
+		delete(p.tails, key)
+	}
+	p.mu.Unlock()
+	close(done)
+}
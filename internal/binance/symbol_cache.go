@@ -0,0 +1,249 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// minSymbolReloadGap limits how often filter rejections reload the rules
+const minSymbolReloadGap = 10 * time.Second
+
+// filterErrorCodes are order rejections for breaking a symbol filter. They
+// mean the cached rules may be out of date, so the cache is reloaded.
+var filterErrorCodes = map[int]bool{
+	-1111:              true, // Precision over the maximum for the symbol
+	-4003:              true, // Quantity <= 0 after rounding
+	-4004:              true, // Quantity below minQty
+	-4005:              true, // Quantity above maxQty
+	-4013:              true, // Price below minPrice
+	-4014:              true, // Price not a multiple of tickSize
+	-4016:              true, // Price above maxPrice
+	-4023:              true, // Quantity not a multiple of stepSize
+	ErrCodeMinNotional: true,
+}
+
+// symbolCache keeps the futures exchange rules in memory, parsed per symbol,
+// so placing an order looks up its filters without going through the whole
+// exchange info payload. Like the price cache it is shared by every client.
+// SymbolRefresh reloads it on a schedule; lookups reload it when it is older
+// than maxAge or was invalidated by a filter rejection.
+type symbolCache struct {
+	mu         sync.RWMutex
+	info       *futures.ExchangeInfo
+	symbols    map[string]SymbolInfo
+	loadedAt   time.Time
+	reloadedAt time.Time // Last load forced from Binance by an invalidation
+	stale      bool      // Invalidated; the next load skips the shared cache
+	maxAge     time.Duration
+
+	loading sync.Mutex // One load at a time
+
+	hits          atomic.Int64 // Symbol lookups answered from memory
+	misses        atomic.Int64 // Symbol lookups that had to load the rules
+	loads         atomic.Int64
+	invalidations atomic.Int64
+}
+
+// SymbolCacheStatus describes the symbol rules cache
+type SymbolCacheStatus struct {
+	Symbols       int   `json:"symbols" example:"310"`
+	LoadedAt      int64 `json:"loadedAt,omitempty" example:"1640995200"` // Unix seconds
+	Hits          int64 `json:"hits" example:"1200"`
+	Misses        int64 `json:"misses" example:"3"`
+	Loads         int64 `json:"loads" example:"48"`
+	Invalidations int64 `json:"invalidations" example:"1"` // Reloads after filter rejections
+}
+
+var symbols = &symbolCache{maxAge: 2 * exchangeInfoTTL}
+
+// SymbolCacheStats returns the cache's size and how often it was used
+func SymbolCacheStats() SymbolCacheStatus {
+	symbols.mu.RLock()
+	defer symbols.mu.RUnlock()
+
+	status := SymbolCacheStatus{
+		Symbols:       len(symbols.symbols),
+		Hits:          symbols.hits.Load(),
+		Misses:        symbols.misses.Load(),
+		Loads:         symbols.loads.Load(),
+		Invalidations: symbols.invalidations.Load(),
+	}
+	if !symbols.loadedAt.IsZero() {
+		status.LoadedAt = symbols.loadedAt.Unix()
+	}
+	return status
+}
+
+// current returns the cached rules, nil unless they may still be used
+func (c *symbolCache) current() (*futures.ExchangeInfo, map[string]SymbolInfo) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.info == nil || c.stale || time.Since(c.loadedAt) >= c.maxAge {
+		return nil, nil
+	}
+	return c.info, c.symbols
+}
+
+// invalidate makes the next lookup reload the rules from Binance
+func (c *symbolCache) invalidate(code int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stale || time.Since(c.reloadedAt) < minSymbolReloadGap {
+		return
+	}
+	c.stale = true
+	c.invalidations.Add(1)
+	logging.Info().Msgf("Binance rejected an order for a symbol filter (%d), reloading exchange rules", code)
+}
+
+// exchangeInfo returns the futures exchange rules, loading them when the
+// cache cannot be used
+func (b *Client) exchangeInfo(ctx context.Context) (*futures.ExchangeInfo, error) {
+	info, _, err := b.loadSymbols(ctx)
+	return info, err
+}
+
+// symbolRules returns a symbol's rules, nil if Binance Futures does not list it
+func (b *Client) symbolRules(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	if _, cached := symbols.current(); cached != nil {
+		symbols.hits.Add(1)
+		return findSymbol(cached, symbol), nil
+	}
+	symbols.misses.Add(1)
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, loaded, err := b.loadSymbols(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange info: %v", err)
+	}
+	return findSymbol(loaded, symbol), nil
+}
+
+// findSymbol returns a copy of a symbol's rules, nil if it is not listed
+func findSymbol(rules map[string]SymbolInfo, symbol string) *SymbolInfo {
+	info, ok := rules[symbol]
+	if !ok {
+		return nil
+	}
+	return &info
+}
+
+// loadSymbols returns the cached rules, loading them unless they may still
+// be used. Concurrent callers wait for a single load.
+func (b *Client) loadSymbols(ctx context.Context) (*futures.ExchangeInfo, map[string]SymbolInfo, error) {
+	if info, cached := symbols.current(); info != nil {
+		return info, cached, nil
+	}
+
+	symbols.loading.Lock()
+	defer symbols.loading.Unlock()
+
+	// Loaded by another caller while this one waited
+	if info, cached := symbols.current(); info != nil {
+		return info, cached, nil
+	}
+	return b.reloadSymbols(ctx)
+}
+
+// reloadSymbols fetches the rules and replaces the cached ones (loading
+// held). After an invalidation they come from Binance, not from the shared
+// cache, whose copy may be just as old.
+func (b *Client) reloadSymbols(ctx context.Context) (*futures.ExchangeInfo, map[string]SymbolInfo, error) {
+	symbols.mu.RLock()
+	stale := symbols.stale
+	symbols.mu.RUnlock()
+
+	info, err := b.fetchExchangeInfo(ctx, stale)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parsed := make(map[string]SymbolInfo, len(info.Symbols))
+	for _, s := range info.Symbols {
+		parsed[s.Symbol] = symbolInfoFrom(s)
+	}
+
+	symbols.mu.Lock()
+	symbols.info, symbols.symbols, symbols.loadedAt = info, parsed, time.Now()
+	if stale {
+		symbols.reloadedAt = symbols.loadedAt
+		symbols.stale = false
+	}
+	symbols.mu.Unlock()
+	symbols.loads.Add(1)
+
+	return info, parsed, nil
+}
+
+// SymbolRefresh reloads the symbol rules cache on a schedule, so orders
+// rarely wait for exchange info
+type SymbolRefresh struct {
+	client   *Client
+	interval time.Duration
+	stopChan chan struct{}
+}
+
+// NewSymbolRefresh creates a symbol rules refresher. Cached rules are used
+// for up to two intervals, so a failed refresh does not empty the cache.
+func NewSymbolRefresh(client *Client, interval time.Duration) *SymbolRefresh {
+	if interval <= 0 {
+		interval = exchangeInfoTTL
+	}
+
+	symbols.mu.Lock()
+	symbols.maxAge = 2 * interval
+	symbols.mu.Unlock()
+
+	return &SymbolRefresh{
+		client:   client,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start loads the rules once, then reloads them every interval
+func (r *SymbolRefresh) Start() {
+	r.refresh()
+	logging.Info().Msgf("Symbol rules cache started (interval=%v, symbols=%d)", r.interval, SymbolCacheStats().Symbols)
+
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.refresh()
+			case <-r.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the refresh loop
+func (r *SymbolRefresh) Stop() {
+	close(r.stopChan)
+}
+
+func (r *SymbolRefresh) refresh() {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+
+	symbols.loading.Lock()
+	defer symbols.loading.Unlock()
+
+	if _, _, err := r.client.reloadSymbols(ctx); err != nil {
+		logging.Warn().Err(err).Msg("Symbol rules refresh failed")
+	}
+}
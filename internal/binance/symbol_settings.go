@@ -0,0 +1,154 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// symbolSettings remembers the leverage and margin type each symbol of an
+// account is set to, so orders only send change requests when the requested
+// values differ. It is seeded from position risk on first use and kept up to
+// date by ACCOUNT_CONFIG_UPDATE and ACCOUNT_UPDATE events. A symbol is
+// forgotten when a change or an order fails, since its settings are then
+// uncertain, and the next order sets them again.
+type symbolSettings struct {
+	mu       sync.Mutex
+	seeded   bool
+	bySymbol map[string]symbolSetting
+}
+
+// symbolSetting is a symbol's known settings; zero values are unknown
+type symbolSetting struct {
+	leverage   int
+	marginType string // ISOLATED or CROSSED
+}
+
+func newSymbolSettings() *symbolSettings {
+	return &symbolSettings{bySymbol: make(map[string]symbolSetting)}
+}
+
+func (s *symbolSettings) get(symbol string) symbolSetting {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bySymbol[symbol]
+}
+
+func (s *symbolSettings) setLeverage(symbol string, leverage int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	setting := s.bySymbol[symbol]
+	setting.leverage = leverage
+	s.bySymbol[symbol] = setting
+}
+
+func (s *symbolSettings) setMarginType(symbol, marginType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	setting := s.bySymbol[symbol]
+	setting.marginType = normalizeMarginType(marginType)
+	s.bySymbol[symbol] = setting
+}
+
+// forget drops a symbol's settings
+func (s *symbolSettings) forget(symbol string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.bySymbol, symbol)
+}
+
+// normalizeMarginType maps position risk and stream values (isolated, cross)
+// to the values orders use (ISOLATED, CROSSED)
+func normalizeMarginType(marginType string) string {
+	switch strings.ToUpper(marginType) {
+	case "CROSS", "CROSSED":
+		return "CROSSED"
+	case "ISOLATED":
+		return "ISOLATED"
+	}
+	return ""
+}
+
+// seedSymbolSettings loads every symbol's settings from position risk, once
+// per client. A failed load is retried on the next order.
+func (b *Client) seedSymbolSettings(ctx context.Context) {
+	b.settings.mu.Lock()
+	seeded := b.settings.seeded
+	b.settings.mu.Unlock()
+	if seeded {
+		return
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	positions, err := b.client.NewGetPositionRiskService().Do(ctx)
+	if err != nil {
+		logging.Ctx(ctx).Warn().Err(err).Msg("Failed to load leverage and margin type settings")
+		return
+	}
+
+	b.settings.mu.Lock()
+	defer b.settings.mu.Unlock()
+	for _, position := range positions {
+		leverage, _ := strconv.Atoi(position.Leverage)
+		// Settings learned since the load started are newer
+		if _, known := b.settings.bySymbol[position.Symbol]; !known {
+			b.settings.bySymbol[position.Symbol] = symbolSetting{
+				leverage:   leverage,
+				marginType: normalizeMarginType(position.MarginType),
+			}
+		}
+	}
+	b.settings.seeded = true
+}
+
+// ensureMarginType sets a symbol's margin type unless it is known to be set
+// already. Failures are logged, not returned: the order goes ahead with the
+// account's current margin type.
+func (b *Client) ensureMarginType(ctx context.Context, symbol, marginType string) {
+	logger := logging.Ctx(ctx)
+	if b.settings.get(symbol).marginType == marginType {
+		logger.Debug().Msgf("Margin type already set to %s for %s", marginType, symbol)
+		return
+	}
+
+	err := b.client.NewChangeMarginTypeService().
+		Symbol(symbol).
+		MarginType(futures.MarginType(marginType)).
+		Do(ctx)
+	if err != nil {
+		// Ignore error if margin type is already set to desired type
+		// Error -4046 means "No need to change margin type"
+		errStr := err.Error()
+		if !strings.Contains(errStr, "-4046") && !strings.Contains(errStr, "No need to change margin type") {
+			logger.Warn().Err(err).Msgf("Failed to set margin type to %s", marginType)
+			b.settings.forget(symbol)
+			return
+		}
+		logger.Debug().Msgf("Margin type already set to %s for %s", marginType, symbol)
+	} else {
+		logger.Info().Msgf("Margin type set to %s for %s", marginType, symbol)
+	}
+	b.settings.setMarginType(symbol, marginType)
+}
+
+// ensureLeverage sets a symbol's leverage unless it is known to be set
+// already
+func (b *Client) ensureLeverage(ctx context.Context, symbol string, leverage int) error {
+	if b.settings.get(symbol).leverage == leverage {
+		logging.Ctx(ctx).Debug().Msgf("Leverage already set to %dx for %s", leverage, symbol)
+		return nil
+	}
+
+	if _, err := b.client.NewChangeLeverageService().Symbol(symbol).Leverage(leverage).Do(ctx); err != nil {
+		b.settings.forget(symbol)
+		return err
+	}
+	b.settings.setLeverage(symbol, leverage)
+	return nil
+}
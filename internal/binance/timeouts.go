@@ -0,0 +1,33 @@
+package binance
+
+import (
+	"context"
+	"time"
+)
+
+// Deadlines for Binance calls, set from config at startup by SetTimeouts
+var (
+	requestTimeout = 10 * time.Second // Single requests (account, positions, market data)
+	orderTimeout   = 30 * time.Second // Order placement and position changes, which may take several requests
+)
+
+// SetTimeouts sets the deadlines for Binance calls. Zero keeps the default.
+func SetTimeouts(request, order time.Duration) {
+	if request > 0 {
+		requestTimeout = request
+	}
+	if order > 0 {
+		orderTimeout = order
+	}
+}
+
+// withTimeout bounds a Binance call by the request timeout (or the caller's
+// earlier deadline)
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, requestTimeout)
+}
+
+// withOrderTimeout bounds an order operation by the order timeout
+func withOrderTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, orderTimeout)
+}
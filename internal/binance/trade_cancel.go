@@ -0,0 +1,79 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// ErrEntryFilled refuses cancelling a trade whose entry order has filled,
+// even partly: it holds a position that has to be closed instead
+var ErrEntryFilled = errors.New("entry order already filled")
+
+// CancelEntry cancels a trade's unfilled entry order and the SL/TP orders
+// placed with it. Orders already gone (-2011) are skipped. If any of the entry
+// has filled, it fails with ErrEntryFilled and the SL/TP orders are kept.
+func (b *Client) CancelEntry(ctx context.Context, trade *models.Trade) error {
+	ctx, cancel := withOrderTimeout(logging.WithTrade(ctx, trade.ID, trade.Symbol))
+	defer cancel()
+
+	if trade.OrderID != 0 {
+		status, err := b.entryStatus(ctx, trade)
+		if err != nil {
+			return err
+		}
+
+		if status == futures.OrderStatusTypeNew {
+			cancelled, err := b.client.NewCancelOrderService().
+				Symbol(trade.Symbol).
+				OrderID(trade.OrderID).
+				Do(ctx)
+			switch {
+			case err != nil && !strings.Contains(err.Error(), strconv.Itoa(ErrCodeUnknownOrder)):
+				return fmt.Errorf("failed to cancel entry order %d: %v", trade.OrderID, err)
+			case err != nil:
+				// Gone in the meantime: filled, or cancelled elsewhere
+				if _, err := b.entryStatus(ctx, trade); err != nil {
+					return err
+				}
+			default:
+				if executed, _ := strconv.ParseFloat(cancelled.ExecutedQuantity, 64); executed > 0 {
+					return fmt.Errorf("%w: %s of %s filled before the rest was cancelled", ErrEntryFilled, cancelled.ExecutedQuantity, cancelled.OrigQuantity)
+				}
+			}
+		}
+	}
+
+	for _, orderID := range []int64{trade.SLOrderID, trade.TPOrderID} {
+		if orderID == 0 {
+			continue
+		}
+		err := b.CancelOrder(ctx, trade.Symbol, orderID)
+		if err != nil && !strings.Contains(err.Error(), strconv.Itoa(ErrCodeUnknownOrder)) {
+			return fmt.Errorf("failed to cancel order %d: %v", orderID, err)
+		}
+	}
+	return nil
+}
+
+// entryStatus returns the status of a trade's entry order, ErrEntryFilled if
+// any of it has filled
+func (b *Client) entryStatus(ctx context.Context, trade *models.Trade) (futures.OrderStatusType, error) {
+	order, err := b.client.NewGetOrderService().
+		Symbol(trade.Symbol).
+		OrderID(trade.OrderID).
+		Do(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get entry order %d: %v", trade.OrderID, err)
+	}
+	if executed, _ := strconv.ParseFloat(order.ExecutedQuantity, 64); executed > 0 {
+		return "", fmt.Errorf("%w: %s of %s filled", ErrEntryFilled, order.ExecutedQuantity, order.OrigQuantity)
+	}
+	return order.Status, nil
+}
@@ -0,0 +1,296 @@
+package binance
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/notifications"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// VolatilityGuardConfig configures the volatility circuit breaker
+type VolatilityGuardConfig struct {
+	Symbols       []string      // Symbols watched; empty = every perpetual
+	MaxChange     float64       // Halt when the price moves more than this within Window (%); 0 = off
+	Window        time.Duration // Window of the price change (default 1m)
+	MaxDivergence float64       // Halt when mark and index prices differ by more than this (%); 0 = off
+	Cooldown      time.Duration // Conditions must stay within limits this long before resuming (default 5m)
+	Interval      time.Duration // How often prices are read (default 5s)
+	Announce      func() bool   // Whether this instance notifies (the leader); nil = always
+}
+
+// MarkIndexPrice is a perpetual's mark and index price
+type MarkIndexPrice struct {
+	Symbol     string
+	MarkPrice  float64
+	IndexPrice float64
+}
+
+// VolatilityGuard pauses new entries on a symbol whose price moves too far
+// within a minute or whose mark price leaves the index (flash crashes,
+// squeezes, broken index components), and resumes them once conditions have
+// stayed normal for the cooldown. Every instance runs it, as each refuses
+// entries on its own; only the one Announce picks notifies.
+type VolatilityGuard struct {
+	client   *Client
+	notifier *notifications.Notifier
+	config   VolatilityGuardConfig
+	watched  map[string]bool
+	samples  map[string][]priceSample
+	halts    map[string]*models.VolatilityHalt
+	stopChan chan struct{}
+	mu       sync.RWMutex
+}
+
+// priceSample is a mark price read at a time
+type priceSample struct {
+	at    time.Time
+	price float64
+}
+
+// NewVolatilityGuard creates a volatility circuit breaker
+func NewVolatilityGuard(client *Client, notifier *notifications.Notifier, config VolatilityGuardConfig) *VolatilityGuard {
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = 5 * time.Minute
+	}
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Second
+	}
+
+	var watched map[string]bool
+	if len(config.Symbols) > 0 {
+		watched = make(map[string]bool, len(config.Symbols))
+		for _, symbol := range config.Symbols {
+			watched[symbol] = true
+		}
+	}
+
+	return &VolatilityGuard{
+		client:   client,
+		notifier: notifier,
+		config:   config,
+		watched:  watched,
+		samples:  make(map[string][]priceSample),
+		halts:    make(map[string]*models.VolatilityHalt),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start reads prices every interval in the background. It may be started
+// again after Stop.
+func (g *VolatilityGuard) Start() {
+	stop := make(chan struct{})
+	g.mu.Lock()
+	g.stopChan = stop
+	g.mu.Unlock()
+
+	logging.Info().Msgf("Volatility guard started (maxChange=%.2f%%/%v, maxDivergence=%.2f%%, cooldown=%v)",
+		g.config.MaxChange, g.config.Window, g.config.MaxDivergence, g.config.Cooldown)
+
+	go func() {
+		ticker := time.NewTicker(g.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				g.check()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the guard loop. Halts in effect stay until it is started again.
+func (g *VolatilityGuard) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	close(g.stopChan)
+}
+
+// Check returns an error while new entries on symbol are halted
+func (g *VolatilityGuard) Check(symbol string) error {
+	if g == nil {
+		return nil
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if halt, ok := g.halts[symbol]; ok {
+		return fmt.Errorf("%s is halted by the volatility circuit breaker since %s: %s",
+			symbol, time.Unix(halt.Since, 0).UTC().Format(time.RFC3339), halt.Reason)
+	}
+	return nil
+}
+
+// Halts returns the symbols halted now, oldest first
+func (g *VolatilityGuard) Halts() []models.VolatilityHalt {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	halts := make([]models.VolatilityHalt, 0, len(g.halts))
+	for _, halt := range g.halts {
+		halts = append(halts, *halt)
+	}
+	sort.Slice(halts, func(i, j int) bool {
+		if halts[i].Since != halts[j].Since {
+			return halts[i].Since < halts[j].Since
+		}
+		return halts[i].Symbol < halts[j].Symbol
+	})
+	return halts
+}
+
+// check reads the prices of every perpetual and halts or resumes symbols
+func (g *VolatilityGuard) check() {
+	prices, err := g.client.GetMarkIndexPrices(context.Background())
+	if err != nil {
+		logging.Warn().Err(err).Msg("Volatility guard: failed to read prices")
+		return
+	}
+
+	now := time.Now()
+	var tripped, resumed []models.VolatilityHalt
+
+	g.mu.Lock()
+	for _, p := range prices {
+		if p.MarkPrice <= 0 || (g.watched != nil && !g.watched[p.Symbol]) {
+			continue
+		}
+
+		change := g.record(p.Symbol, p.MarkPrice, now)
+		divergence := 0.0
+		if p.IndexPrice > 0 {
+			divergence = math.Abs(p.MarkPrice-p.IndexPrice) / p.IndexPrice * 100
+		}
+		reason := g.breach(change, divergence)
+
+		halt, halted := g.halts[p.Symbol]
+		switch {
+		case reason != "" && !halted:
+			halt = &models.VolatilityHalt{
+				Symbol:     p.Symbol,
+				Reason:     reason,
+				Change:     math.Round(change*100) / 100,
+				Divergence: math.Round(divergence*100) / 100,
+				MarkPrice:  p.MarkPrice,
+				Since:      now.Unix(),
+			}
+			g.halts[p.Symbol] = halt
+			tripped = append(tripped, *halt)
+		case reason != "":
+			halt.CalmSince = 0
+		case halted && halt.CalmSince == 0:
+			halt.CalmSince = now.Unix()
+		case halted && now.Sub(time.Unix(halt.CalmSince, 0)) >= g.config.Cooldown:
+			delete(g.halts, p.Symbol)
+			resumed = append(resumed, *halt)
+		}
+	}
+	g.mu.Unlock()
+
+	for _, halt := range tripped {
+		logging.Warn().Str(logging.FieldSymbol, halt.Symbol).Msgf("Volatility guard: new entries on %s halted: %s", halt.Symbol, halt.Reason)
+		g.announce(notifications.VolatilityHalt(halt.Symbol, halt.Reason, halt.MarkPrice))
+	}
+	for _, halt := range resumed {
+		logging.Info().Str(logging.FieldSymbol, halt.Symbol).Msgf("Volatility guard: new entries on %s resumed", halt.Symbol)
+		g.announce(notifications.VolatilityResumed(halt.Symbol, time.Since(time.Unix(halt.Since, 0))))
+	}
+}
+
+// record adds a mark price to the symbol's window and returns the largest
+// move of the price from the window's low or high (%) (g.mu held)
+func (g *VolatilityGuard) record(symbol string, price float64, now time.Time) float64 {
+	samples := g.samples[symbol]
+	cutoff := now.Add(-g.config.Window)
+	kept := samples[:0]
+	for _, s := range samples {
+		if !s.at.Before(cutoff) {
+			kept = append(kept, s)
+		}
+	}
+	kept = append(kept, priceSample{at: now, price: price})
+	g.samples[symbol] = kept
+
+	low, high := price, price
+	for _, s := range kept {
+		low = math.Min(low, s.price)
+		high = math.Max(high, s.price)
+	}
+	return math.Max((price-low)/low, (high-price)/high) * 100
+}
+
+// breach describes the limit a symbol's prices exceed, "" when none
+func (g *VolatilityGuard) breach(change, divergence float64) string {
+	if g.config.MaxChange > 0 && change > g.config.MaxChange {
+		return fmt.Sprintf("price moved %.2f%% within %v (limit %.2f%%)", change, g.config.Window, g.config.MaxChange)
+	}
+	if g.config.MaxDivergence > 0 && divergence > g.config.MaxDivergence {
+		return fmt.Sprintf("mark price %.2f%% from the index (limit %.2f%%)", divergence, g.config.MaxDivergence)
+	}
+	return ""
+}
+
+// announce notifies the operator, from the announcing instance only
+func (g *VolatilityGuard) announce(event *notifications.Event) {
+	if g.notifier == nil || (g.config.Announce != nil && !g.config.Announce()) {
+		return
+	}
+	g.notifier.Publish(event)
+}
+
+// GetMarkIndexPrices - Get the mark and index price of every perpetual.
+// go-binance's premium index has no index price, so the request is built here.
+func (b *Client) GetMarkIndexPrices(ctx context.Context) ([]MarkIndexPrice, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.client.BaseURL+"/fapi/v1/premiumIndex", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := b.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mark prices: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get mark prices: %v", responseError(resp))
+	}
+
+	var premiumIndex []struct {
+		Symbol     string `json:"symbol"`
+		MarkPrice  string `json:"markPrice"`
+		IndexPrice string `json:"indexPrice"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&premiumIndex); err != nil {
+		return nil, fmt.Errorf("failed to parse mark prices: %v", err)
+	}
+
+	prices := make([]MarkIndexPrice, 0, len(premiumIndex))
+	for _, p := range premiumIndex {
+		markPrice, _ := strconv.ParseFloat(p.MarkPrice, 64)
+		indexPrice, _ := strconv.ParseFloat(p.IndexPrice, 64)
+		prices = append(prices, MarkIndexPrice{
+			Symbol:     p.Symbol,
+			MarkPrice:  markPrice,
+			IndexPrice: indexPrice,
+		})
+	}
+	return prices, nil
+}
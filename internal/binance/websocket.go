@@ -2,9 +2,11 @@ package binance
 
 import (
 	"context"
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/logging"
 	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/tradehistory"
 	"fmt"
-	"log"
 	"strconv"
 	"sync"
 	"time"
@@ -15,57 +17,49 @@ import (
 // WebSocketManager manages WebSocket connections
 type WebSocketManager struct {
 	client           *Client
+	bus              *events.Bus
+	config           WebSocketConfig
 	userDataStream   *UserDataStream
-	priceStreams     map[string]*PriceStream
+	feed             *PriceFeed
+	priceStreams     map[string]func() // Symbol -> price feed unsubscribe
+	candles          map[string]*candleSeries   // Symbol|interval -> rolling candles
+	aggTrades        map[string]*aggTradeStream // Symbol -> trades moving the forming candles
+	candleMu         sync.RWMutex
 	mu               sync.RWMutex
-	isRunning        bool
-	stopChan         chan struct{}
+	isRunning        bool // User data stream supervisor started
+	reconnects       int
+	staleRestarts    int
+	lastUserEvent    time.Time // Last user data event on any stream
+	stopChan         chan struct{} // Stops the current supervisor
 }
 
+// WebSocketConfig tunes the user data stream watchdog
+type WebSocketConfig struct {
+	StaleAfter    time.Duration // Silence after which the listen key is probed (default 10m)
+	CheckInterval time.Duration // How often the watchdog looks at the stream (default 1m)
+	CandleHistory int           // Candles kept per symbol and interval (default 500, max 1500)
+}
+
+// User data stream supervision
+const (
+	userStreamMinBackoff = time.Second
+	userStreamMaxBackoff = time.Minute
+	listenKeyKeepalive   = 30 * time.Minute // Listen keys expire after 60 minutes without a keepalive
+)
+
 // UserDataStream represents user data WebSocket stream
 type UserDataStream struct {
 	ListenKey    string
 	DoneC        chan struct{}
 	StopC        chan struct{}
 	LastPing     time.Time
+	LastEvent    time.Time
+	ConnectedAt  time.Time
 	IsConnected  bool
+	expired      chan struct{} // Binance sent listenKeyExpired
 	mu           sync.RWMutex
 }
 
-// PriceStream represents market price WebSocket stream
-type PriceStream struct {
-	Symbol      string
-	LastPrice   float64
-	LastUpdate  time.Time
-	DoneC       chan struct{}
-	StopC       chan struct{}
-	IsConnected bool
-	mu          sync.RWMutex
-}
-
-// OrderUpdateEvent represents order update from WebSocket
-type OrderUpdateEvent struct {
-	Symbol           string
-	Side             string
-	OrderType        string
-	OrderID          int64
-	ClientOrderID    string
-	Price            string
-	Quantity         string
-	ExecutedQty      string
-	CumulativeQty    string
-	Status           string
-	TimeInForce      string
-	AvgPrice         string
-	IsReduceOnly     bool
-	WorkingType      string
-	OriginalType     string
-	PositionSide     string
-	IsClosePosition  bool
-	RealizedProfit   string
-	TransactionTime  int64
-}
-
 // AccountUpdateEvent represents account update from WebSocket
 type AccountUpdateEvent struct {
 	Reason          string
@@ -91,220 +85,387 @@ type PositionUpdate struct {
 	PositionSide     string
 }
 
-// NewWebSocketManager creates a new WebSocket manager
-func NewWebSocketManager(client *Client) *WebSocketManager {
+// NewWebSocketManager creates a new WebSocket manager publishing the
+// client's user data stream on bus
+func NewWebSocketManager(client *Client, bus *events.Bus, feed *PriceFeed, config WebSocketConfig) *WebSocketManager {
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = 10 * time.Minute
+	}
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = time.Minute
+	}
+	if config.CandleHistory <= 0 || config.CandleHistory > 1500 {
+		config.CandleHistory = 500
+	}
+
+	// The client's trade monitors follow their orders through the bus
+	bus.Subscribe(events.TopicOrderUpdated, "trade monitors", func(event events.Event) {
+		client.orders.publish(event.(events.OrderUpdated).Order)
+	})
+
 	return &WebSocketManager{
 		client:       client,
-		priceStreams: make(map[string]*PriceStream),
+		bus:          bus,
+		config:       config,
+		feed:         feed,
+		priceStreams: make(map[string]func()),
+		candles:      make(map[string]*candleSeries),
+		aggTrades:    make(map[string]*aggTradeStream),
 		stopChan:     make(chan struct{}),
 	}
 }
 
-// StartUserDataStream starts the user data WebSocket stream
-func (wsm *WebSocketManager) StartUserDataStream(onOrderUpdate func(*OrderUpdateEvent), onAccountUpdate func(*AccountUpdateEvent)) error {
-	ctx := context.Background()
+// Start supervises the user data stream in the background: it connects with
+// a fresh listen key, renews the key every 30 minutes and reconnects with
+// exponential backoff whenever the stream drops or the key expires. It does
+// nothing if the supervisor is already running.
+func (wsm *WebSocketManager) Start() {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+
+	if wsm.isRunning {
+		return
+	}
+	wsm.isRunning = true
+	wsm.stopChan = make(chan struct{})
+	go wsm.superviseUserDataStream(wsm.stopChan)
+}
+
+// StopUserDataStream stops the supervisor and closes the stream and its
+// listen key, leaving price and candle streams running. Start may be called
+// again afterwards.
+func (wsm *WebSocketManager) StopUserDataStream() {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+
+	wsm.stopSupervisor()
+}
+
+// stopSupervisor stops the user data stream supervisor and its stream (wsm.mu held)
+func (wsm *WebSocketManager) stopSupervisor() {
+	if !wsm.isRunning {
+		return
+	}
+	close(wsm.stopChan)
+	wsm.isRunning = false
+
+	if wsm.userDataStream != nil {
+		wsm.stopUserDataStream()
+		logging.Info().Msg("User data stream stopped")
+	}
+}
+
+// Running reports whether the user data stream supervisor has been started
+func (wsm *WebSocketManager) Running() bool {
+	wsm.mu.RLock()
+	defer wsm.mu.RUnlock()
+	return wsm.isRunning
+}
+
+// superviseUserDataStream keeps the user data stream connected until stop
+// is closed
+func (wsm *WebSocketManager) superviseUserDataStream(stop chan struct{}) {
+	backoff := userStreamMinBackoff
+	for {
+		stream, err := wsm.connectUserDataStream()
+		if err != nil {
+			logging.Warn().Err(err).Msgf("User data stream unavailable, retrying in %v", backoff)
+		} else {
+			connectedAt := time.Now()
+			reason := wsm.runUserDataStream(stream, stop)
+			if reason == "" {
+				return
+			}
+
+			// A connection that stayed up for a while starts the backoff over
+			if time.Since(connectedAt) > userStreamMaxBackoff {
+				backoff = userStreamMinBackoff
+			}
+			logging.Warn().Msgf("User data stream %s, reconnecting in %v", reason, backoff)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-stop:
+			return
+		}
+
+		wsm.mu.Lock()
+		wsm.reconnects++
+		wsm.mu.Unlock()
+
+		backoff *= 2
+		if backoff > userStreamMaxBackoff {
+			backoff = userStreamMaxBackoff
+		}
+	}
+}
+
+// connectUserDataStream opens the user data stream with a new listen key.
+// Order and account updates are published on the manager's event bus.
+func (wsm *WebSocketManager) connectUserDataStream() (*UserDataStream, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
 
 	// Get listen key
 	listenKey, err := wsm.client.client.NewStartUserStreamService().Do(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to start user stream: %v", err)
+		return nil, fmt.Errorf("failed to start user stream: %v", err)
 	}
 
-	log.Printf("📡 WebSocket User Data Stream started (listenKey: %s...)", listenKey[:10])
-
-	wsm.userDataStream = &UserDataStream{
-		ListenKey:   listenKey,
-		LastPing:    time.Now(),
-		IsConnected: false,
+	stream := &UserDataStream{
+		ListenKey: listenKey,
+		LastPing:  time.Now(),
+		expired:   make(chan struct{}, 1),
 	}
 
-	// Start keep-alive goroutine (ping every 30 minutes)
-	go wsm.keepAliveUserStream()
-
 	// WebSocket handler
 	wsHandler := func(event *futures.WsUserDataEvent) {
-		wsm.userDataStream.mu.Lock()
-		wsm.userDataStream.IsConnected = true
-		wsm.userDataStream.mu.Unlock()
+		now := time.Now()
+		stream.mu.Lock()
+		stream.LastEvent = now
+		stream.mu.Unlock()
+		wsm.mu.Lock()
+		wsm.lastUserEvent = now
+		wsm.mu.Unlock()
+
+		switch event.Event {
+		case futures.UserDataEventTypeListenKeyExpired:
+			select {
+			case stream.expired <- struct{}{}:
+			default:
+			}
 
 		// Handle ORDER_TRADE_UPDATE
-		if event.Event == futures.UserDataEventTypeOrderTradeUpdate {
-			orderUpdate := &OrderUpdateEvent{
-				Symbol:          event.OrderTradeUpdate.Symbol,
-				Side:            string(event.OrderTradeUpdate.Side),
-				OrderType:       string(event.OrderTradeUpdate.Type),
-				OrderID:         event.OrderTradeUpdate.ID,
-				ClientOrderID:   event.OrderTradeUpdate.ClientOrderID,
-				Price:           event.OrderTradeUpdate.OriginalPrice,
-				Quantity:        event.OrderTradeUpdate.OriginalQty,
-				ExecutedQty:     event.OrderTradeUpdate.AccumulatedFilledQty,
-				Status:          string(event.OrderTradeUpdate.Status),
-				AvgPrice:        event.OrderTradeUpdate.AveragePrice,
-				IsReduceOnly:    event.OrderTradeUpdate.IsReduceOnly,
-				PositionSide:    string(event.OrderTradeUpdate.PositionSide),
-				IsClosePosition: false, // Field not available in this SDK version
-				RealizedProfit:  event.OrderTradeUpdate.RealizedPnL,
-				TransactionTime: event.OrderTradeUpdate.TradeTime,
-			}
+		case futures.UserDataEventTypeOrderTradeUpdate:
+			order := orderFromStream(&event.OrderTradeUpdate)
 
-			log.Printf("🔔 Order Update: %s %s %s - Status: %s",
-				orderUpdate.Symbol, orderUpdate.Side, orderUpdate.OrderType, orderUpdate.Status)
+			logging.Info().Msgf("Order Update: %s %s %s - Status: %s",
+				order.Symbol, order.Side, order.Type, order.Status)
 
-			if onOrderUpdate != nil {
-				onOrderUpdate(orderUpdate)
+			wsm.bus.Publish(events.OrderUpdated{Order: order})
+			if order.Status == string(futures.OrderStatusTypeFilled) {
+				wsm.bus.Publish(events.OrderFilled{Order: order})
 			}
-		}
 
 		// Handle ACCOUNT_UPDATE
-		if event.Event == futures.UserDataEventTypeAccountUpdate {
-			balances := []BalanceUpdate{}
+		case futures.UserDataEventTypeAccountUpdate:
+			update := events.AccountUpdated{
+				Reason: string(event.AccountUpdate.Reason),
+				Time:   event.Time,
+			}
 			for _, bal := range event.AccountUpdate.Balances {
-				balances = append(balances, BalanceUpdate{
+				wallet, _ := strconv.ParseFloat(bal.Balance, 64)
+				cross, _ := strconv.ParseFloat(bal.CrossWalletBalance, 64)
+				update.Balances = append(update.Balances, events.Balance{
 					Asset:              bal.Asset,
-					WalletBalance:      bal.Balance,
-					CrossWalletBalance: bal.CrossWalletBalance,
-					BalanceChange:      "0", // Not available in SDK
+					WalletBalance:      wallet,
+					CrossWalletBalance: cross,
 				})
 			}
-
-			positions := []PositionUpdate{}
 			for _, pos := range event.AccountUpdate.Positions {
-				positions = append(positions, PositionUpdate{
-					Symbol:         pos.Symbol,
-					PositionAmount: pos.Amount,
-					EntryPrice:     pos.EntryPrice,
-					UnrealizedPnL:  pos.UnrealizedPnL,
-					PositionSide:   string(pos.Side),
+				if pos.MarginType != "" {
+					wsm.client.settings.setMarginType(pos.Symbol, string(pos.MarginType))
+				}
+				amount, _ := strconv.ParseFloat(pos.Amount, 64)
+				entry, _ := strconv.ParseFloat(pos.EntryPrice, 64)
+				pnl, _ := strconv.ParseFloat(pos.UnrealizedPnL, 64)
+				update.Positions = append(update.Positions, events.Position{
+					Symbol:        pos.Symbol,
+					PositionSide:  string(pos.Side),
+					PositionAmt:   amount,
+					EntryPrice:    entry,
+					UnrealizedPnL: pnl,
 				})
 			}
 
-			accountUpdate := &AccountUpdateEvent{
-				Reason:          string(event.AccountUpdate.Reason),
-				Balances:        balances,
-				Positions:       positions,
-				TransactionTime: event.Time,
-			}
+			logging.Info().Msgf("Account Update: %s - Balances: %d, Positions: %d",
+				update.Reason, len(update.Balances), len(update.Positions))
 
-			log.Printf("💰 Account Update: %s - Balances: %d, Positions: %d",
-				accountUpdate.Reason, len(accountUpdate.Balances), len(accountUpdate.Positions))
+			wsm.bus.Publish(update)
 
-			if onAccountUpdate != nil {
-				onAccountUpdate(accountUpdate)
+		// Handle ACCOUNT_CONFIG_UPDATE (leverage changed, here or elsewhere)
+		case futures.UserDataEventTypeAccountConfigUpdate:
+			if config := event.AccountConfigUpdate; config.Symbol != "" {
+				wsm.client.settings.setLeverage(config.Symbol, int(config.Leverage))
 			}
 		}
 	}
 
-	// Error handler
+	// Error handler (the supervisor reconnects once the stream is done)
 	errHandler := func(err error) {
-		log.Printf("⚠️ WebSocket error: %v", err)
-		wsm.userDataStream.mu.Lock()
-		wsm.userDataStream.IsConnected = false
-		wsm.userDataStream.mu.Unlock()
-
-		// Attempt reconnection after 5 seconds
-		time.Sleep(5 * time.Second)
-		log.Println("🔄 Attempting to reconnect WebSocket...")
-		wsm.StartUserDataStream(onOrderUpdate, onAccountUpdate)
+		logging.Warn().Err(err).Msg("WebSocket error")
 	}
 
 	// Start WebSocket
 	doneC, stopC, err := futures.WsUserDataServe(listenKey, wsHandler, errHandler)
 	if err != nil {
-		return fmt.Errorf("failed to serve user data: %v", err)
+		return nil, fmt.Errorf("failed to serve user data: %v", err)
 	}
+	stream.DoneC = doneC
+	stream.StopC = stopC
+	stream.IsConnected = true
+	stream.ConnectedAt = time.Now()
 
-	wsm.userDataStream.DoneC = doneC
-	wsm.userDataStream.StopC = stopC
-
-	log.Println("✅ WebSocket User Data Stream connected")
+	wsm.mu.Lock()
+	wsm.userDataStream = stream
+	wsm.mu.Unlock()
+	wsm.client.orders.setConnected(true)
 
-	return nil
+	logging.Info().Msgf("WebSocket User Data Stream connected (listenKey: %s...)", listenKey[:10])
+	return stream, nil
 }
 
-// keepAliveUserStream pings the listen key every 30 minutes
-func (wsm *WebSocketManager) keepAliveUserStream() {
-	ticker := time.NewTicker(30 * time.Minute)
+// runUserDataStream renews the stream's listen key until the stream drops,
+// goes stale, the key expires or cannot be renewed, or stop is closed. It
+// returns why the stream ended, empty when it was stopped.
+func (wsm *WebSocketManager) runUserDataStream(stream *UserDataStream, stop chan struct{}) string {
+	ticker := time.NewTicker(listenKeyKeepalive)
 	defer ticker.Stop()
+	watchdog := time.NewTicker(wsm.config.CheckInterval)
+	defer watchdog.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			if wsm.userDataStream == nil {
-				return
+			if err := wsm.keepAlive(stream); err != nil {
+				logging.Warn().Err(err).Msg("Failed to renew listen key")
+				wsm.closeUserDataStream(stream)
+				return "listen key renewal failed"
 			}
-
-			ctx := context.Background()
-			err := wsm.client.client.NewKeepaliveUserStreamService().
-				ListenKey(wsm.userDataStream.ListenKey).
-				Do(ctx)
-
-			if err != nil {
-				log.Printf("⚠️ Failed to ping listen key: %v", err)
-			} else {
-				wsm.userDataStream.mu.Lock()
-				wsm.userDataStream.LastPing = time.Now()
-				wsm.userDataStream.mu.Unlock()
-				log.Println("🏓 WebSocket keep-alive ping sent")
+			logging.Debug().Msg("WebSocket keep-alive ping sent")
+
+		case <-watchdog.C:
+			// A quiet account sends nothing for hours, so silence alone is
+			// not enough: the stream is stale once its listen key is rejected too
+			silence := time.Since(stream.lastActivity())
+			if silence < wsm.config.StaleAfter {
+				continue
+			}
+			if err := wsm.keepAlive(stream); err != nil {
+				logging.Warn().Err(err).Msgf("User data stream stale: no events for %v and keepalive failed", silence.Round(time.Second))
+				wsm.mu.Lock()
+				wsm.staleRestarts++
+				wsm.mu.Unlock()
+				wsm.closeUserDataStream(stream)
+				return "stale"
 			}
 
-		case <-wsm.stopChan:
-			return
-		}
-	}
-}
+		case <-stream.expired:
+			wsm.closeUserDataStream(stream)
+			return "listen key expired"
 
-// StartPriceStream starts a price WebSocket stream for a symbol
-func (wsm *WebSocketManager) StartPriceStream(symbol string, onPriceUpdate func(symbol string, price float64)) error {
-	wsm.mu.Lock()
-	defer wsm.mu.Unlock()
+		case <-stream.DoneC:
+			wsm.closeUserDataStream(stream)
+			return "disconnected"
 
-	// Check if already streaming
-	if _, exists := wsm.priceStreams[symbol]; exists {
-		return fmt.Errorf("price stream already exists for %s", symbol)
+		case <-stop:
+			wsm.closeUserDataStream(stream)
+			return ""
+		}
 	}
+}
 
-	log.Printf("📈 Starting price stream for %s", symbol)
+// keepAlive extends the stream's listen key
+func (wsm *WebSocketManager) keepAlive(stream *UserDataStream) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
 
-	priceStream := &PriceStream{
-		Symbol:      symbol,
-		IsConnected: false,
+	err := wsm.client.client.NewKeepaliveUserStreamService().
+		ListenKey(stream.ListenKey).
+		Do(ctx)
+	if err != nil {
+		return err
 	}
 
-	// WebSocket handler
-	wsHandler := func(event *futures.WsMarkPriceEvent) {
-		markPrice, _ := strconv.ParseFloat(event.MarkPrice, 64)
+	stream.mu.Lock()
+	stream.LastPing = time.Now()
+	stream.mu.Unlock()
+	return nil
+}
 
-		priceStream.mu.Lock()
-		priceStream.LastPrice = markPrice
-		priceStream.LastUpdate = time.Now()
-		priceStream.IsConnected = true
-		priceStream.mu.Unlock()
+// lastActivity returns when the stream last received an event or had its
+// listen key renewed, counting from the connection
+func (s *UserDataStream) lastActivity() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-		if onPriceUpdate != nil {
-			onPriceUpdate(symbol, markPrice)
+	last := s.ConnectedAt
+	for _, t := range []time.Time{s.LastEvent, s.LastPing} {
+		if t.After(last) {
+			last = t
 		}
 	}
+	return last
+}
 
-	// Error handler
-	errHandler := func(err error) {
-		log.Printf("⚠️ Price stream error for %s: %v", symbol, err)
-		priceStream.mu.Lock()
-		priceStream.IsConnected = false
-		priceStream.mu.Unlock()
+// closeUserDataStream closes a stream and its listen key unless it has
+// already been replaced or closed
+func (wsm *WebSocketManager) closeUserDataStream(stream *UserDataStream) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+
+	if wsm.userDataStream == stream {
+		wsm.stopUserDataStream()
 	}
+}
 
-	// Start WebSocket
-	doneC, stopC, err := futures.WsMarkPriceServe(symbol, wsHandler, errHandler)
-	if err != nil {
-		return fmt.Errorf("failed to start price stream: %v", err)
+// stopUserDataStream closes the current stream and its listen key (wsm.mu held)
+func (wsm *WebSocketManager) stopUserDataStream() {
+	stream := wsm.userDataStream
+
+	ctx, cancel := withTimeout(context.Background())
+	wsm.client.client.NewCloseUserStreamService().
+		ListenKey(stream.ListenKey).
+		Do(ctx)
+	cancel()
+
+	close(stream.StopC)
+	wsm.userDataStream = nil
+	wsm.client.orders.setConnected(false) // Trade monitors poll until reconnected
+}
+
+// orderFromStream converts an ORDER_TRADE_UPDATE payload
+func orderFromStream(update *futures.WsOrderTradeUpdate) events.Order {
+	avgPrice, _ := strconv.ParseFloat(update.AveragePrice, 64)
+	filledQty, _ := strconv.ParseFloat(update.AccumulatedFilledQty, 64)
+	realizedPnL, _ := strconv.ParseFloat(update.RealizedPnL, 64)
+	commission, _ := strconv.ParseFloat(update.Commission, 64)
+
+	return events.Order{
+		Symbol:          update.Symbol,
+		OrderID:         update.ID,
+		Side:            string(update.Side),
+		Type:            string(update.Type),
+		OrigType:        string(update.OriginalType),
+		Status:          string(update.Status),
+		AvgPrice:        avgPrice,
+		FilledQty:       filledQty,
+		RealizedPnL:     realizedPnL,
+		Commission:      commission,
+		CommissionAsset: update.CommissionAsset,
+		Time:            update.TradeTime,
 	}
+}
 
-	priceStream.DoneC = doneC
-	priceStream.StopC = stopC
+// StartPriceStream streams a symbol's mark price through the shared,
+// multiplexed price feed
+func (wsm *WebSocketManager) StartPriceStream(symbol string, onPriceUpdate func(symbol string, price float64)) error {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
 
-	wsm.priceStreams[symbol] = priceStream
+	// Check if already streaming
+	if _, exists := wsm.priceStreams[symbol]; exists {
+		return fmt.Errorf("price stream already exists for %s", symbol)
+	}
 
-	log.Printf("✅ Price stream connected for %s", symbol)
+	wsm.priceStreams[symbol] = wsm.feed.Subscribe(symbol, func(symbol string, price float64) {
+		if onPriceUpdate != nil {
+			onPriceUpdate(symbol, price)
+		}
+	})
+
+	logging.Info().Str(logging.FieldSymbol, symbol).Msgf("Price stream started for %s", symbol)
 
 	return nil
 }
@@ -314,10 +475,10 @@ func (wsm *WebSocketManager) StopPriceStream(symbol string) {
 	wsm.mu.Lock()
 	defer wsm.mu.Unlock()
 
-	if stream, exists := wsm.priceStreams[symbol]; exists {
-		close(stream.StopC)
+	if unsubscribe, exists := wsm.priceStreams[symbol]; exists {
+		unsubscribe()
 		delete(wsm.priceStreams, symbol)
-		log.Printf("🛑 Price stream stopped for %s", symbol)
+		logging.Info().Str(logging.FieldSymbol, symbol).Msgf("Price stream stopped for %s", symbol)
 	}
 }
 
@@ -327,30 +488,19 @@ func (wsm *WebSocketManager) StopAllStreams() {
 	defer wsm.mu.Unlock()
 
 	// Stop user data stream
-	if wsm.userDataStream != nil {
-		ctx := context.Background()
-		wsm.client.client.NewCloseUserStreamService().
-			ListenKey(wsm.userDataStream.ListenKey).
-			Do(ctx)
-
-		if wsm.userDataStream.StopC != nil {
-			close(wsm.userDataStream.StopC)
-		}
-		wsm.userDataStream = nil
-		log.Println("🛑 User data stream stopped")
-	}
+	wsm.stopSupervisor()
 
 	// Stop all price streams
-	for symbol, stream := range wsm.priceStreams {
-		if stream.StopC != nil {
-			close(stream.StopC)
-		}
-		log.Printf("🛑 Price stream stopped for %s", symbol)
+	for symbol, unsubscribe := range wsm.priceStreams {
+		unsubscribe()
+		logging.Info().Str(logging.FieldSymbol, symbol).Msgf("Price stream stopped for %s", symbol)
 	}
-	wsm.priceStreams = make(map[string]*PriceStream)
+	wsm.priceStreams = make(map[string]func())
 
-	close(wsm.stopChan)
-	log.Println("✅ All WebSocket streams stopped")
+	// Stop candle streams
+	wsm.stopCandleStreams()
+
+	logging.Info().Msg("All WebSocket streams stopped")
 }
 
 // GetStreamStatus returns the status of all streams
@@ -359,8 +509,12 @@ func (wsm *WebSocketManager) GetStreamStatus() map[string]interface{} {
 	defer wsm.mu.RUnlock()
 
 	status := map[string]interface{}{
-		"userDataStream": "disconnected",
-		"priceStreams":   []map[string]interface{}{},
+		"userDataStream":    "disconnected",
+		"supervised":        wsm.isRunning,
+		"reconnects":        wsm.reconnects,
+		"staleRestarts":     wsm.staleRestarts,
+		"staleAfter":        wsm.config.StaleAfter.String(),
+		"lastUserDataEvent": formatStreamTime(wsm.lastUserEvent),
 	}
 
 	// User data stream status
@@ -368,57 +522,56 @@ func (wsm *WebSocketManager) GetStreamStatus() map[string]interface{} {
 		wsm.userDataStream.mu.RLock()
 		if wsm.userDataStream.IsConnected {
 			status["userDataStream"] = map[string]interface{}{
-				"status":   "connected",
-				"lastPing": wsm.userDataStream.LastPing.Format(time.RFC3339),
+				"status":      "connected",
+				"connectedAt": formatStreamTime(wsm.userDataStream.ConnectedAt),
+				"lastEvent":   formatStreamTime(wsm.userDataStream.LastEvent),
+				"lastPing":    wsm.userDataStream.LastPing.Format(time.RFC3339),
 			}
 		}
 		wsm.userDataStream.mu.RUnlock()
 	}
 
-	// Price streams status
-	priceStreamsStatus := []map[string]interface{}{}
-	for symbol, stream := range wsm.priceStreams {
-		stream.mu.RLock()
-		streamStatus := map[string]interface{}{
-			"symbol":     symbol,
-			"connected":  stream.IsConnected,
-			"lastPrice":  stream.LastPrice,
-			"lastUpdate": stream.LastUpdate.Format(time.RFC3339),
-		}
-		stream.mu.RUnlock()
-		priceStreamsStatus = append(priceStreamsStatus, streamStatus)
-	}
-	status["priceStreams"] = priceStreamsStatus
+	// Price streams status (every symbol of the shared feed)
+	status["priceStreams"] = wsm.feed.Status()
+	status["priceCache"] = PriceCacheStats()
+	status["candleStreams"] = wsm.candleStatus()
 
 	return status
 }
 
-// UpdateTradeFromWebSocket updates trade from WebSocket order event
-func UpdateTradeFromWebSocket(trade *models.Trade, event *OrderUpdateEvent, fb interface {
-	UpdateTrade(ctx context.Context, trade *models.Trade) error
-}) {
-	ctx := context.Background()
-
-	// Update trade status
-	trade.Status = event.Status
+// UserDataStreamConnected reports whether the user data stream is connected
+// and when the last user data event arrived (zero if none yet)
+func (wsm *WebSocketManager) UserDataStreamConnected() (bool, time.Time) {
+	wsm.mu.RLock()
+	defer wsm.mu.RUnlock()
 
-	if event.Status == string(futures.OrderStatusTypeFilled) ||
-		event.Status == string(futures.OrderStatusTypeCanceled) ||
-		event.Status == string(futures.OrderStatusTypeExpired) {
-		trade.ClosedAt = time.Now().Unix()
+	if wsm.userDataStream == nil {
+		return false, wsm.lastUserEvent
 	}
+	wsm.userDataStream.mu.RLock()
+	defer wsm.userDataStream.mu.RUnlock()
+	return wsm.userDataStream.IsConnected, wsm.lastUserEvent
+}
 
-	// Update executed price if available
-	if event.AvgPrice != "" && event.AvgPrice != "0" {
-		// Parse avg price
-		// avgPrice, _ := strconv.ParseFloat(event.AvgPrice, 64)
-		// trade.ExecutedPrice = avgPrice
+// formatStreamTime formats a stream timestamp, empty when it never happened
+func formatStreamTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
 	}
+	return t.Format(time.RFC3339)
+}
+
+// RecordExitFill records the fees of a trade's filled SL/TP order, as
+// reported by the user data stream, and saves the trade. Closing it and
+// cancelling the other protective order is left to the close watcher.
+func (b *Client) RecordExitFill(trade *models.Trade, order events.Order, fb tradeUpdater) {
+	ctx := logging.WithTrade(tradehistory.WithSource(context.Background(), tradehistory.SourceUserDataStream), trade.ID, trade.Symbol)
 
-	// Save to Firebase
+	if err := b.RecordOrderFees(ctx, trade, order.OrderID); err != nil {
+		logging.Ctx(ctx).Warn().Err(err).Msgf("Failed to record exit fees of %s order %d", order.Symbol, order.OrderID)
+		return
+	}
 	if err := fb.UpdateTrade(ctx, trade); err != nil {
-		log.Printf("⚠️ Failed to update trade from WebSocket: %v", err)
-	} else {
-		log.Printf("✅ Trade %s updated from WebSocket: %s", trade.ID, trade.Status)
+		logging.Ctx(ctx).Warn().Err(err).Msg("Failed to update trade from WebSocket")
 	}
 }
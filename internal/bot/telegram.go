@@ -0,0 +1,209 @@
+package bot
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/notifications"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommandFunc runs a bot command; chatID identifies the caller, args are the words after the command
+type CommandFunc func(ctx context.Context, chatID int64, args []string) (string, error)
+
+type command struct {
+	description string
+	run         CommandFunc
+}
+
+// TelegramBot long-polls Telegram for commands from allowed chats
+type TelegramBot struct {
+	token        string
+	allowedChats map[int64]bool
+	commands     map[string]command
+	sender       *notifications.TelegramChannel
+	httpClient   *http.Client
+	offset       int64
+	stopChan     chan struct{}
+}
+
+// telegramUpdate is the subset of a getUpdates result the bot reads
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		From struct {
+			Username string `json:"username"`
+		} `json:"from"`
+	} `json:"message"`
+}
+
+// NewTelegramBot creates a bot that only obeys the given chat IDs
+func NewTelegramBot(token string, allowedChats []int64) *TelegramBot {
+	allowed := make(map[int64]bool, len(allowedChats))
+	for _, id := range allowedChats {
+		allowed[id] = true
+	}
+
+	return &TelegramBot{
+		token:        token,
+		allowedChats: allowed,
+		commands:     make(map[string]command),
+		sender:       notifications.NewTelegramChannel(token, ""),
+		httpClient:   &http.Client{Timeout: 40 * time.Second},
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Handle registers a command such as "/positions"
+func (b *TelegramBot) Handle(name, description string, run CommandFunc) {
+	b.commands[strings.ToLower(name)] = command{description: description, run: run}
+}
+
+// Start polls for updates in the background. It may be started again after
+// Stop.
+func (b *TelegramBot) Start() {
+	stop := make(chan struct{})
+	b.stopChan = stop
+
+	logging.Info().Msgf("Telegram bot started (%d allowed chats, %d commands)", len(b.allowedChats), len(b.commands))
+
+	go func() {
+		backoff := time.Second
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			updates, err := b.getUpdates()
+			if err != nil {
+				logging.Warn().Err(err).Msg("Telegram bot: failed to get updates")
+				select {
+				case <-time.After(backoff):
+				case <-stop:
+					return
+				}
+				if backoff < time.Minute {
+					backoff *= 2
+				}
+				continue
+			}
+			backoff = time.Second
+
+			for _, update := range updates {
+				b.offset = update.UpdateID + 1
+				b.handleUpdate(update)
+			}
+		}
+	}()
+}
+
+// Stop stops polling
+func (b *TelegramBot) Stop() {
+	close(b.stopChan)
+}
+
+// getUpdates long-polls Telegram for new messages
+func (b *TelegramBot) getUpdates() ([]telegramUpdate, error) {
+	params := url.Values{}
+	params.Set("offset", strconv.FormatInt(b.offset, 10))
+	params.Set("timeout", "30")
+	params.Set("allowed_updates", `["message"]`)
+
+	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates?%s", b.token, params.Encode())
+	resp, err := b.httpClient.Get(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updates: %v", notifications.StripURL(err))
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool             `json:"ok"`
+		Description string           `json:"description"`
+		Result      []telegramUpdate `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode updates: %v", err)
+	}
+	if !result.OK {
+		return nil, fmt.Errorf("telegram API error: %s", result.Description)
+	}
+
+	return result.Result, nil
+}
+
+// handleUpdate authorizes the chat, runs the command and replies
+func (b *TelegramBot) handleUpdate(update telegramUpdate) {
+	msg := update.Message
+	if msg == nil || !strings.HasPrefix(msg.Text, "/") {
+		return
+	}
+
+	chatID := msg.Chat.ID
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if !b.allowedChats[chatID] {
+		logging.Warn().Msgf("Telegram bot: rejected %q from unauthorized chat %d (@%s)", msg.Text, chatID, msg.From.Username)
+		b.reply(ctx, chatID, "⛔ This chat is not authorized.")
+		return
+	}
+
+	fields := strings.Fields(msg.Text)
+	name := strings.ToLower(fields[0])
+	if at := strings.Index(name, "@"); at > 0 {
+		name = name[:at] // "/positions@MyBot" in group chats
+	}
+	args := fields[1:]
+
+	logging.Info().Msgf("Telegram bot: %s %v from chat %d", name, args, chatID)
+
+	if name == "/help" || name == "/start" {
+		b.reply(ctx, chatID, b.help())
+		return
+	}
+
+	cmd, ok := b.commands[name]
+	if !ok {
+		b.reply(ctx, chatID, "Unknown command. Send /help for the list of commands.")
+		return
+	}
+
+	text, err := cmd.run(ctx, chatID, args)
+	if err != nil {
+		text = "❌ " + err.Error()
+	}
+	b.reply(ctx, chatID, text)
+}
+
+func (b *TelegramBot) reply(ctx context.Context, chatID int64, text string) {
+	if err := b.sender.SendMessage(ctx, strconv.FormatInt(chatID, 10), text); err != nil {
+		logging.Warn().Err(err).Msgf("Telegram bot: failed to reply to %d", chatID)
+	}
+}
+
+func (b *TelegramBot) help() string {
+	names := make([]string, 0, len(b.commands))
+	for name := range b.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("Available commands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%s – %s\n", name, b.commands[name].description)
+	}
+	return strings.TrimSpace(sb.String())
+}
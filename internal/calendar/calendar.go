@@ -0,0 +1,237 @@
+package calendar
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keepPast is how long past events stay listed
+const keepPast = 24 * time.Hour
+
+// Config configures the economic calendar
+type Config struct {
+	Currencies     []string      // Currencies followed (USD, EUR, ...); empty = all
+	BlackoutImpact string        // Lowest impact that opens a blackout window (default HIGH)
+	Before         time.Duration // Blackout start before the release
+	After          time.Duration // Blackout end after the release
+	BlockEntries   bool          // Refuse every new entry during blackouts
+	Refresh        time.Duration // How often the provider is read
+}
+
+// Calendar keeps upcoming economic events from a provider and the blackout
+// windows around the releases that move markets most
+type Calendar struct {
+	provider  Provider
+	config    Config
+	events    []models.EconomicEvent // By time
+	updatedAt time.Time
+	lastErr   error
+	stopChan  chan struct{}
+	mu        sync.RWMutex
+}
+
+// Upcoming is the calendar as served by GET /api/calendar/upcoming
+type Upcoming struct {
+	Provider       string                 `json:"provider" example:"forexfactory"`
+	UpdatedAt      int64                  `json:"updatedAt,omitempty" example:"1763030000"`
+	Error          string                 `json:"error,omitempty" example:""` // Last refresh failure; events are from the previous one
+	BlockEntries   bool                   `json:"blockEntries" example:"false"`
+	ActiveBlackout *models.EconomicEvent  `json:"activeBlackout,omitempty"` // Event whose blackout covers now
+	Events         []models.EconomicEvent `json:"events"`
+}
+
+// New creates a calendar reading from provider
+func New(provider Provider, config Config) *Calendar {
+	config.BlackoutImpact = strings.ToUpper(config.BlackoutImpact)
+	if impactRank(config.BlackoutImpact) == 0 {
+		config.BlackoutImpact = models.ImpactHigh
+	}
+	if config.Refresh <= 0 {
+		config.Refresh = time.Hour
+	}
+	for i, currency := range config.Currencies {
+		config.Currencies[i] = strings.ToUpper(currency)
+	}
+
+	return &Calendar{
+		provider: provider,
+		config:   config,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start loads the events and refreshes them every Refresh. It may be
+// started again after Stop.
+func (c *Calendar) Start() {
+	stop := make(chan struct{})
+	c.mu.Lock()
+	c.stopChan = stop
+	c.mu.Unlock()
+
+	if err := c.Refresh(context.Background()); err != nil {
+		logging.Warn().Err(err).Msg("Economic calendar: initial load failed")
+	}
+	logging.Info().Msgf("Economic calendar started (provider=%s, blackout %s events -%v/+%v)",
+		c.provider.Name(), c.config.BlackoutImpact, c.config.Before, c.config.After)
+
+	go func() {
+		ticker := time.NewTicker(c.config.Refresh)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.Refresh(context.Background()); err != nil {
+					logging.Warn().Err(err).Msg("Economic calendar: refresh failed, keeping previous events")
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops refreshing
+func (c *Calendar) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	close(c.stopChan)
+}
+
+// Refresh reads the provider. Events already known and no longer served (a
+// weekly feed rolling over) are kept until they are a day old.
+func (c *Calendar) Refresh(ctx context.Context) error {
+	fetched, err := c.provider.Fetch(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastErr = err
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-keepPast).Unix()
+	merged := make(map[string]models.EconomicEvent)
+	for _, event := range c.events {
+		if event.Time >= cutoff {
+			merged[event.ID] = event
+		}
+	}
+	for _, event := range fetched {
+		if event.Time < cutoff || !c.follows(event.Currency) {
+			continue
+		}
+		if event.ID == "" {
+			event.ID = eventID(event)
+		}
+		event.BlackoutFrom, event.BlackoutUntil = 0, 0
+		if impactRank(event.Impact) >= impactRank(c.config.BlackoutImpact) && c.config.Before+c.config.After > 0 {
+			event.BlackoutFrom = event.Time - int64(c.config.Before.Seconds())
+			event.BlackoutUntil = event.Time + int64(c.config.After.Seconds())
+		}
+		merged[event.ID] = event
+	}
+
+	c.events = make([]models.EconomicEvent, 0, len(merged))
+	for _, event := range merged {
+		c.events = append(c.events, event)
+	}
+	sort.Slice(c.events, func(i, j int) bool {
+		return c.events[i].Time < c.events[j].Time
+	})
+	c.updatedAt = time.Now()
+	return nil
+}
+
+// follows reports whether events of a currency are kept
+func (c *Calendar) follows(currency string) bool {
+	if len(c.config.Currencies) == 0 {
+		return true
+	}
+	for _, followed := range c.config.Currencies {
+		if followed == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// BlocksEntries reports whether every new entry is refused during blackouts
+func (c *Calendar) BlocksEntries() bool {
+	return c != nil && c.config.BlockEntries
+}
+
+// Upcoming lists the events from now until within, plus those whose
+// blackout still covers now, at minImpact or above and in currency (any
+// when empty)
+func (c *Calendar) Upcoming(now time.Time, within time.Duration, minImpact, currency string) *Upcoming {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	upcoming := &Upcoming{
+		Provider:     c.provider.Name(),
+		BlockEntries: c.config.BlockEntries,
+		Events:       []models.EconomicEvent{},
+	}
+	if !c.updatedAt.IsZero() {
+		upcoming.UpdatedAt = c.updatedAt.Unix()
+	}
+	if c.lastErr != nil {
+		upcoming.Error = c.lastErr.Error()
+	}
+
+	until := now.Add(within).Unix()
+	for _, event := range c.events {
+		if upcoming.ActiveBlackout == nil && event.BlackoutFrom <= now.Unix() && now.Unix() < event.BlackoutUntil {
+			active := event
+			upcoming.ActiveBlackout = &active
+		}
+		if event.Time > until || (event.Time < now.Unix() && event.BlackoutUntil <= now.Unix()) {
+			continue
+		}
+		if impactRank(event.Impact) < impactRank(minImpact) || (currency != "" && event.Currency != currency) {
+			continue
+		}
+		upcoming.Events = append(upcoming.Events, event)
+	}
+	return upcoming
+}
+
+// Blackouts returns the blackout windows that have not ended yet, as
+// trading hours blackouts
+func (c *Calendar) Blackouts(now time.Time) []models.Blackout {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var blackouts []models.Blackout
+	for _, event := range c.events {
+		if event.BlackoutUntil > now.Unix() {
+			blackouts = append(blackouts, models.Blackout{
+				From:   event.BlackoutFrom,
+				To:     event.BlackoutUntil,
+				Reason: event.Currency + " " + event.Title,
+			})
+		}
+	}
+	return blackouts
+}
+
+// ActiveBlackout returns the event whose blackout covers now, nil if none
+func (c *Calendar) ActiveBlackout(now time.Time) *models.EconomicEvent {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, event := range c.events {
+		if event.BlackoutFrom <= now.Unix() && now.Unix() < event.BlackoutUntil {
+			active := event
+			return &active
+		}
+	}
+	return nil
+}
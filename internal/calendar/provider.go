@@ -0,0 +1,159 @@
+package calendar
+
+import (
+	"context"
+	"crypto-trading-api/internal/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Calendar providers
+const (
+	ProviderForexFactory = "forexfactory" // Forex Factory's weekly JSON export
+	ProviderJSON         = "json"         // Any feed serving []models.EconomicEvent
+)
+
+// DefaultForexFactoryURL is Forex Factory's export of the current week
+const DefaultForexFactoryURL = "https://nfs.faireconomy.media/ff_calendar_thisweek.json"
+
+// Provider fetches scheduled economic events
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context) ([]models.EconomicEvent, error)
+}
+
+// NewProvider returns the named provider reading from url (the provider's
+// default when empty)
+func NewProvider(name, url string) (Provider, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+
+	switch strings.ToLower(name) {
+	case "", ProviderForexFactory:
+		if url == "" {
+			url = DefaultForexFactoryURL
+		}
+		return &forexFactory{url: url, httpClient: client}, nil
+	case ProviderJSON:
+		if url == "" {
+			return nil, fmt.Errorf("the %s calendar provider needs CALENDAR_URL", ProviderJSON)
+		}
+		return &jsonFeed{url: url, httpClient: client}, nil
+	}
+	return nil, fmt.Errorf("unknown calendar provider %q (use %s or %s)", name, ProviderForexFactory, ProviderJSON)
+}
+
+// get fetches a feed and decodes its JSON body into v
+func get(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch calendar: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to fetch calendar: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse calendar: %v", err)
+	}
+	return nil
+}
+
+// forexFactory reads Forex Factory's weekly export. It only covers the
+// current week, so events early next week appear once it starts.
+type forexFactory struct {
+	url        string
+	httpClient *http.Client
+}
+
+func (p *forexFactory) Name() string { return ProviderForexFactory }
+
+func (p *forexFactory) Fetch(ctx context.Context) ([]models.EconomicEvent, error) {
+	var entries []struct {
+		Title    string `json:"title"`
+		Country  string `json:"country"` // Currency code
+		Date     string `json:"date"`    // RFC 3339 with the exporter's offset
+		Impact   string `json:"impact"`  // High, Medium, Low, Holiday or Non-Economic
+		Forecast string `json:"forecast"`
+		Previous string `json:"previous"`
+	}
+	if err := get(ctx, p.httpClient, p.url, &entries); err != nil {
+		return nil, err
+	}
+
+	events := make([]models.EconomicEvent, 0, len(entries))
+	for _, entry := range entries {
+		impact := strings.ToUpper(entry.Impact)
+		if impactRank(impact) == 0 {
+			continue // Holidays and speeches without a release
+		}
+		at, err := time.Parse(time.RFC3339, entry.Date)
+		if err != nil {
+			continue
+		}
+		events = append(events, models.EconomicEvent{
+			Title:    entry.Title,
+			Currency: strings.ToUpper(entry.Country),
+			Impact:   impact,
+			Time:     at.Unix(),
+			Forecast: entry.Forecast,
+			Previous: entry.Previous,
+		})
+	}
+	return events, nil
+}
+
+// jsonFeed reads events already in this API's format, e.g. from an in-house
+// calendar service
+type jsonFeed struct {
+	url        string
+	httpClient *http.Client
+}
+
+func (p *jsonFeed) Name() string { return ProviderJSON }
+
+func (p *jsonFeed) Fetch(ctx context.Context) ([]models.EconomicEvent, error) {
+	var events []models.EconomicEvent
+	if err := get(ctx, p.httpClient, p.url, &events); err != nil {
+		return nil, err
+	}
+	for i := range events {
+		events[i].Currency = strings.ToUpper(events[i].Currency)
+		events[i].Impact = strings.ToUpper(events[i].Impact)
+	}
+	return events, nil
+}
+
+// impactRank orders impact levels, 0 for anything else
+func impactRank(impact string) int {
+	switch impact {
+	case models.ImpactLow:
+		return 1
+	case models.ImpactMedium:
+		return 2
+	case models.ImpactHigh:
+		return 3
+	}
+	return 0
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// eventID identifies an event by currency, time and title, so it stays the
+// same across refreshes
+func eventID(event models.EconomicEvent) string {
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(event.Title), "-"), "-")
+	return fmt.Sprintf("%s-%d-%s", event.Currency, event.Time, slug)
+}
@@ -0,0 +1,180 @@
+package calendar
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/tradehistory"
+	"math"
+	"sync"
+	"time"
+)
+
+// StopMover reads prices and moves stop losses on the exchange
+type StopMover interface {
+	GetPrice(ctx context.Context, symbol string) (float64, error)
+	ReplaceStopLoss(ctx context.Context, trade *models.Trade, stopPrice float64) error
+}
+
+// TradeStore loads open trades and saves their new stops
+type TradeStore interface {
+	GetTradesByStatus(ctx context.Context, status string) ([]*models.Trade, error)
+	UpdateTrade(ctx context.Context, trade *models.Trade) error
+}
+
+// StopGuard tightens the stop losses of open trades when a blackout starts:
+// the distance from the mark price to each stop shrinks by Percent, so a
+// release that moves against a position costs less. Stops are never moved
+// back afterwards. It covers trades on the main Binance account.
+type StopGuard struct {
+	calendar  *Calendar
+	client    StopMover
+	store     TradeStore
+	percent   float64
+	interval  time.Duration
+	tightened map[string]int64 // Event ID + trade ID -> blackout end
+	stopChan  chan struct{}
+	mu        sync.Mutex
+}
+
+// NewStopGuard creates a stop guard checking for blackouts every interval
+// (default 30s). percent is capped at 90 so stops stay off the mark price.
+func NewStopGuard(calendar *Calendar, client StopMover, store TradeStore, percent float64, interval time.Duration) *StopGuard {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	return &StopGuard{
+		calendar:  calendar,
+		client:    client,
+		store:     store,
+		percent:   math.Min(percent, 90),
+		interval:  interval,
+		tightened: make(map[string]int64),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start runs the guard loop in the background. It may be started again
+// after Stop.
+func (g *StopGuard) Start() {
+	stop := make(chan struct{})
+	g.stopChan = stop
+
+	logging.Info().Msgf("News stop guard started (tighten %.0f%%, interval=%v)", g.percent, g.interval)
+
+	go func() {
+		ticker := time.NewTicker(g.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				g.check()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the guard loop
+func (g *StopGuard) Stop() {
+	close(g.stopChan)
+	logging.Info().Msg("News stop guard stopped")
+}
+
+// check tightens the stops of open trades not tightened for the current
+// blackout yet. Trades that fail are tried again on the next check.
+func (g *StopGuard) check() {
+	now := time.Now()
+	event := g.calendar.ActiveBlackout(now)
+
+	g.mu.Lock()
+	for key, until := range g.tightened {
+		if until <= now.Unix() {
+			delete(g.tightened, key)
+		}
+	}
+	g.mu.Unlock()
+
+	if event == nil {
+		return
+	}
+
+	ctx := tradehistory.WithSource(context.Background(), tradehistory.SourceNewsBlackout)
+	for _, status := range []string{"ACTIVE", "FILLED"} {
+		trades, err := g.store.GetTradesByStatus(ctx, status)
+		if err != nil {
+			logging.Warn().Err(err).Msgf("News stop guard: failed to load %s trades", status)
+			return
+		}
+
+		for _, trade := range trades {
+			// Unfilled LIMIT entries have no position to protect yet
+			if trade.Account != "" || trade.SLOrderID == 0 || (trade.Status == "ACTIVE" && trade.OrderType == "LIMIT") {
+				continue
+			}
+
+			key := event.ID + ":" + trade.ID
+			g.mu.Lock()
+			_, done := g.tightened[key]
+			g.mu.Unlock()
+			if done {
+				continue
+			}
+
+			if g.tighten(ctx, trade, event) {
+				g.mu.Lock()
+				g.tightened[key] = event.BlackoutUntil
+				g.mu.Unlock()
+			}
+		}
+	}
+}
+
+// tighten moves one trade's stop closer to the mark price. It reports
+// whether the trade is done for the blackout (moved, or nothing to move).
+func (g *StopGuard) tighten(ctx context.Context, trade *models.Trade, event *models.EconomicEvent) bool {
+	price, err := g.client.GetPrice(ctx, trade.Symbol)
+	if err != nil {
+		logging.Warn().Err(err).Str(logging.FieldSymbol, trade.Symbol).Msgf("News stop guard: failed to get %s price", trade.Symbol)
+		return false
+	}
+
+	stop := TightenedStop(trade.Side, price, trade.StopLoss, g.percent)
+	if stop == 0 {
+		return true
+	}
+
+	previous := trade.StopLoss
+	if err := g.client.ReplaceStopLoss(ctx, trade, stop); err != nil {
+		logging.Error().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("News stop guard: failed to move the stop of trade %s", trade.ID)
+		if err := g.store.UpdateTrade(ctx, trade); err != nil {
+			logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("News stop guard: failed to save trade %s", trade.ID)
+		}
+		return false
+	}
+	if err := g.store.UpdateTrade(ctx, trade); err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("News stop guard: failed to save trade %s", trade.ID)
+	}
+
+	logging.Info().Str(logging.FieldTradeID, trade.ID).Str(logging.FieldSymbol, trade.Symbol).
+		Msgf("News stop guard: %s %s stop moved %.8g -> %.8g for %s %s", trade.Side, trade.Symbol, previous, stop, event.Currency, event.Title)
+	return true
+}
+
+// TightenedStop returns the stop percent closer to price than stop, or 0
+// when stop is not below a long's price (above a short's)
+func TightenedStop(side string, price, stop, percent float64) float64 {
+	direction := 1.0
+	if side == "SELL" {
+		direction = -1
+	}
+
+	distance := direction * (price - stop)
+	if stop <= 0 || distance <= 0 || percent <= 0 {
+		return 0
+	}
+	return price - direction*distance*(1-percent/100)
+}
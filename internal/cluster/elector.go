@@ -0,0 +1,195 @@
+package cluster
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"sync"
+	"time"
+)
+
+const (
+	leaderLock = "leader"
+
+	// lockTimeout bounds each call to the shared locks
+	lockTimeout = 5 * time.Second
+)
+
+// duty is a job only the leader runs
+type duty struct {
+	name  string
+	start func()
+	stop  func()
+}
+
+// Elector elects one leader among the server instances through an expiring
+// lock. The leader renews the lock every third of its TTL and runs the
+// registered duties; if it stops, crashes or cannot renew the lock, another
+// instance takes over once the lock expires.
+type Elector struct {
+	locker *Locker
+	ttl    time.Duration
+	duties []duty
+
+	leader    bool
+	since     time.Time // Elected at
+	renewedAt time.Time // Last successful renewal
+	running   bool
+	stopChan  chan struct{}
+	stopped   chan struct{}
+	mu        sync.Mutex
+}
+
+// NewElector creates an elector (ttl defaults to 15s)
+func NewElector(locker *Locker, ttl time.Duration) *Elector {
+	if ttl <= 0 {
+		ttl = 15 * time.Second
+	}
+	return &Elector{
+		locker:   locker,
+		ttl:      ttl,
+		stopChan: make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Run registers a duty: start is called when this instance becomes the
+// leader and stop when it steps down. Both may be called repeatedly.
+func (e *Elector) Run(name string, start, stop func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	d := duty{name: name, start: start, stop: stop}
+	e.duties = append(e.duties, d)
+	if e.leader {
+		d.start()
+	}
+}
+
+// Start campaigns for leadership in the background
+func (e *Elector) Start() {
+	e.mu.Lock()
+	e.running = true
+	e.mu.Unlock()
+
+	logging.Info().Msgf("Leader election started (instance %s, lock TTL %v)", e.locker.Instance(), e.ttl)
+
+	go func() {
+		defer close(e.stopped)
+
+		ticker := time.NewTicker(e.ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			e.campaign()
+
+			select {
+			case <-ticker.C:
+			case <-e.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop steps down, stopping the duties and releasing the lock so another
+// instance takes over right away
+func (e *Elector) Stop() {
+	e.mu.Lock()
+	running := e.running
+	e.mu.Unlock()
+	if !running {
+		return
+	}
+
+	close(e.stopChan)
+	<-e.stopped
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.leader {
+		e.stepDown()
+		logging.Info().Msgf("Instance %s stepped down as leader", e.locker.Instance())
+
+		ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+		defer cancel()
+		if err := e.locker.Unlock(ctx, leaderLock); err != nil {
+			logging.Warn().Err(err).Msg("Failed to release leader lock")
+		}
+	}
+}
+
+// IsLeader reports whether this instance currently leads
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+// Status describes this instance and the current leader
+func (e *Elector) Status(ctx context.Context) models.ClusterStatus {
+	e.mu.Lock()
+	status := models.ClusterStatus{
+		InstanceID: e.locker.Instance(),
+		Shared:     e.locker.Shared(),
+		Leader:     e.leader,
+		Duties:     make([]string, 0, len(e.duties)),
+	}
+	if e.leader {
+		status.LeaderID = e.locker.Instance()
+		status.LeaderSince = e.since.Unix()
+	}
+	for _, d := range e.duties {
+		status.Duties = append(status.Duties, d.name)
+	}
+	e.mu.Unlock()
+
+	if !status.Leader {
+		if holder, err := e.locker.Holder(ctx, leaderLock); err == nil {
+			status.LeaderID = holder
+		}
+	}
+	return status
+}
+
+// campaign takes or renews the leader lock and starts or stops the duties
+func (e *Elector) campaign() {
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	acquired, err := e.locker.TryLock(ctx, leaderLock, e.ttl)
+	cancel()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	switch {
+	case err != nil:
+		logging.Warn().Err(err).Msg("Leader election: lock unavailable")
+		// Step down before the lock can expire and another instance take over
+		if e.leader && time.Since(e.renewedAt) >= 2*e.ttl/3 {
+			e.stepDown()
+			logging.Warn().Msgf("Instance %s is no longer leader: lock could not be renewed", e.locker.Instance())
+		}
+	case acquired:
+		e.renewedAt = time.Now()
+		if !e.leader {
+			e.leader = true
+			e.since = time.Now()
+			logging.Info().Msgf("Instance %s elected leader", e.locker.Instance())
+			for _, d := range e.duties {
+				d.start()
+			}
+		}
+	case e.leader:
+		e.stepDown()
+		logging.Warn().Msgf("Instance %s is no longer leader: lock taken by another instance", e.locker.Instance())
+	}
+}
+
+// stepDown stops the duties in reverse order (e.mu held)
+func (e *Elector) stepDown() {
+	for i := len(e.duties) - 1; i >= 0; i-- {
+		e.duties[i].stop()
+	}
+	e.leader = false
+}
@@ -0,0 +1,76 @@
+package cluster
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"time"
+)
+
+// SharedLocks are expiring locks shared by every server instance (Redis)
+type SharedLocks interface {
+	TryLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	Unlock(ctx context.Context, name, owner string) error
+	LockHolder(ctx context.Context, name string) (string, error)
+}
+
+// Locker takes named locks on behalf of this instance. Without shared locks
+// this is the only instance and every lock is granted.
+type Locker struct {
+	instance string
+	shared   SharedLocks // nil for a single instance
+}
+
+// NewLocker creates a locker for an instance (shared may be nil)
+func NewLocker(instanceID string, shared SharedLocks) *Locker {
+	if instanceID == "" {
+		instanceID = NewInstanceID()
+	}
+	return &Locker{instance: instanceID, shared: shared}
+}
+
+// NewInstanceID returns the host name with a random suffix, unique per process
+func NewInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "instance"
+	}
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	return host + "-" + hex.EncodeToString(suffix)
+}
+
+// Instance returns the ID locks are held under
+func (l *Locker) Instance() string {
+	return l.instance
+}
+
+// Shared reports whether locks are coordinated with other instances
+func (l *Locker) Shared() bool {
+	return l.shared != nil
+}
+
+// TryLock acquires a lock for ttl, or extends it if this instance holds it
+func (l *Locker) TryLock(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	if l.shared == nil {
+		return true, nil
+	}
+	return l.shared.TryLock(ctx, name, l.instance, ttl)
+}
+
+// Unlock releases a lock held by this instance
+func (l *Locker) Unlock(ctx context.Context, name string) error {
+	if l.shared == nil {
+		return nil
+	}
+	return l.shared.Unlock(ctx, name, l.instance)
+}
+
+// Holder returns the instance holding a lock, or "" if it is free
+func (l *Locker) Holder(ctx context.Context, name string) (string, error) {
+	if l.shared == nil {
+		return l.instance, nil
+	}
+	return l.shared.LockHolder(ctx, name)
+}
@@ -0,0 +1,46 @@
+// Package dashboard serves the admin web dashboard: a single page embedded in
+// the binary that reads the REST API and the /ws push stream with the API key
+// or token the operator enters, so the page itself needs no authentication.
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Path is where the dashboard is served
+const Path = "/dashboard"
+
+//go:embed static
+var static embed.FS
+
+// contentSecurityPolicy keeps the page to its own scripts and styles, and
+// its requests to this server
+const contentSecurityPolicy = "default-src 'self'; connect-src 'self' ws: wss:; img-src 'self' data:; frame-ancestors 'none'; base-uri 'none'; form-action 'none'"
+
+// Register serves the dashboard under Path
+func Register(router *gin.Engine) {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+
+	group := router.Group(Path, securityHeaders())
+	group.StaticFS("/", http.FS(files))
+}
+
+// securityHeaders stops the dashboard from being framed or cached, and from
+// loading anything but its own files
+func securityHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Security-Policy", contentSecurityPolicy)
+		c.Header("X-Frame-Options", "DENY")
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("Referrer-Policy", "no-referrer")
+		c.Header("Cache-Control", "no-cache")
+		c.Next()
+	}
+}
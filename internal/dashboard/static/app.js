@@ -0,0 +1,402 @@
+// Admin dashboard: REST API for snapshots and actions, /ws for live updates.
+// The API key or JWT entered at sign-in authenticates both.
+(function () {
+  'use strict';
+
+  var API = '/api/v1';
+  var STORAGE_KEY = 'tradingapi.credential';
+  var REFRESH_MS = 30000;
+  var MAX_TRADES = 25;
+
+  var state = {
+    credential: null,
+    positions: {}, // symbol:side -> position
+    trades: [],
+    socket: null,
+    reconnectDelay: 1000,
+    timers: []
+  };
+
+  var $ = function (id) { return document.getElementById(id); };
+
+  // ---- Formatting --------------------------------------------------------
+
+  function num(value, digits) {
+    if (value === undefined || value === null || isNaN(value)) return '-';
+    return Number(value).toLocaleString(undefined, { minimumFractionDigits: digits || 0, maximumFractionDigits: digits === undefined ? 8 : digits });
+  }
+
+  function usd(value) {
+    return num(value, 2) + ' USDT';
+  }
+
+  function time(seconds) {
+    if (!seconds) return '-';
+    var ms = seconds > 1e12 ? seconds : seconds * 1000;
+    return new Date(ms).toLocaleString();
+  }
+
+  function signClass(value) {
+    return value > 0 ? 'up' : value < 0 ? 'down' : '';
+  }
+
+  function cell(row, text, className) {
+    var td = document.createElement('td');
+    td.textContent = text;
+    if (className) td.className = className;
+    row.appendChild(td);
+    return td;
+  }
+
+  function button(label, className, onClick) {
+    var b = document.createElement('button');
+    b.textContent = label;
+    b.className = className;
+    b.addEventListener('click', onClick);
+    return b;
+  }
+
+  function toast(message, isError) {
+    var el = $('toast');
+    el.textContent = message;
+    el.className = 'toast' + (isError ? ' error' : '');
+    el.hidden = false;
+    clearTimeout(toast.timer);
+    toast.timer = setTimeout(function () { el.hidden = true; }, 5000);
+  }
+
+  // ---- API ---------------------------------------------------------------
+
+  function api(method, path, body) {
+    var options = {
+      method: method,
+      headers: { 'Authorization': 'Bearer ' + state.credential }
+    };
+    if (body !== undefined) {
+      options.headers['Content-Type'] = 'application/json';
+      options.body = JSON.stringify(body);
+    }
+
+    return fetch(API + path, options).then(function (resp) {
+      return resp.json().catch(function () { return {}; }).then(function (result) {
+        if (resp.status === 401) {
+          signOut('Sign-in rejected: ' + (result.error || 'unauthorized'));
+          throw new Error('unauthorized');
+        }
+        if (!resp.ok || !result.success) {
+          throw new Error((result.message || 'HTTP ' + resp.status) + (result.error ? ': ' + result.error : ''));
+        }
+        return result;
+      });
+    });
+  }
+
+  function action(promise, done) {
+    return promise.then(function (result) {
+      toast(result.message || 'Done');
+      if (done) done();
+    }).catch(function (err) {
+      if (err.message !== 'unauthorized') toast(err.message, true);
+    });
+  }
+
+  // ---- Loaders -----------------------------------------------------------
+
+  function loadPositions() {
+    return api('GET', '/positions').then(function (result) {
+      state.positions = {};
+      (result.data.positions || []).forEach(function (p) {
+        state.positions[p.symbol + ':' + p.side] = p;
+      });
+      renderPositions();
+    }).catch(report);
+  }
+
+  function loadOrders() {
+    return api('GET', '/orders').then(function (result) {
+      renderOrders(result.data.orders || []);
+    }).catch(report);
+  }
+
+  function loadTrades() {
+    return api('GET', '/trades?limit=' + MAX_TRADES).then(function (result) {
+      state.trades = result.data.trades || [];
+      renderTrades();
+    }).catch(report);
+  }
+
+  function loadSummary() {
+    return api('GET', '/summary?period=' + encodeURIComponent($('period').value)).then(function (result) {
+      var s = result.data;
+      $('pnl').textContent = usd(s.netPnL);
+      $('pnl').className = signClass(s.netPnL);
+      $('winrate').textContent = s.totalTrades + ' trades, ' + num(s.winRate, 1) + '% won, fees ' + usd(s.totalFees);
+    }).catch(report);
+  }
+
+  function loadHealth() {
+    return api('GET', '/risk/account').then(function (result) {
+      var h = result.data;
+      $('wallet').textContent = usd(h.totalWalletBalance);
+      $('available').textContent = 'Available ' + usd(h.availableMargin);
+      $('health').textContent = h.healthLevel + ' (' + num(h.healthScore, 0) + ')';
+      $('health').className = h.healthLevel === 'HEALTHY' ? 'up' : 'down';
+      var margin = 'Margin ratio ' + num(h.marginRatio, 2) + '%, leverage ' + num(h.effectiveLeverage, 2) + 'x';
+      if (h.openPositions > 0) margin += ', closest liquidation ' + num(h.minDistanceToLiquidation, 2) + '%';
+      $('margin').textContent = margin;
+    }).catch(report);
+  }
+
+  function loadPause() {
+    return api('GET', '/admin/pause').then(function (result) {
+      renderPause(result.data);
+    }).catch(function () {
+      // Only admins see the pause state
+      $('pause-state').hidden = true;
+      $('pause').hidden = true;
+      $('resume').hidden = true;
+    });
+  }
+
+  function report(err) {
+    if (err.message !== 'unauthorized') toast(err.message, true);
+  }
+
+  // ---- Rendering ---------------------------------------------------------
+
+  function renderPositions() {
+    var body = $('positions');
+    body.textContent = '';
+
+    var totalPnL = 0;
+    var keys = Object.keys(state.positions).sort();
+    keys.forEach(function (key) {
+      var p = state.positions[key];
+      totalPnL += Number(p.unrealizedProfit) || 0;
+
+      var row = document.createElement('tr');
+      cell(row, p.symbol + (p.side && p.side !== 'BOTH' ? ' ' + p.side : ''));
+      cell(row, num(p.positionAmt), signClass(p.positionAmt));
+      cell(row, num(p.entryPrice));
+      cell(row, num(p.markPrice));
+      cell(row, num(p.unrealizedProfit, 2), signClass(p.unrealizedProfit));
+      cell(row, p.leverage ? p.leverage + 'x' : '-');
+      cell(row, p.liquidationPrice ? num(p.liquidationPrice) : '-');
+      cell(row, '').appendChild(button('Close', 'danger small', function () { closePosition(p.symbol); }));
+      body.appendChild(row);
+    });
+
+    $('no-positions').hidden = keys.length > 0;
+    $('upnl').textContent = usd(totalPnL);
+    $('upnl').className = signClass(totalPnL);
+    $('position-count').textContent = keys.length + ' open position' + (keys.length === 1 ? '' : 's');
+  }
+
+  function renderOrders(orders) {
+    var body = $('orders');
+    body.textContent = '';
+
+    orders.forEach(function (o) {
+      var row = document.createElement('tr');
+      cell(row, o.symbol);
+      cell(row, o.side, o.side === 'BUY' ? 'up' : 'down');
+      cell(row, o.type + (o.reduceOnly || o.closePosition ? ' (reduce)' : ''));
+      cell(row, Number(o.price) ? num(o.price) : '-');
+      cell(row, Number(o.stopPrice) ? num(o.stopPrice) : '-');
+      cell(row, o.closePosition ? 'position' : num(o.quantity));
+      cell(row, time(o.createdTime));
+      cell(row, '').appendChild(button('Cancel', 'secondary small', function () { cancelOrder(o); }));
+      body.appendChild(row);
+    });
+
+    $('no-orders').hidden = orders.length > 0;
+    $('cancel-all').disabled = orders.length === 0;
+  }
+
+  function renderTrades() {
+    var body = $('trades');
+    body.textContent = '';
+
+    state.trades.forEach(function (t) {
+      var row = document.createElement('tr');
+      cell(row, time(t.createdAt));
+      cell(row, t.userId + (t.account ? ' (' + t.account + ')' : ''));
+      cell(row, t.symbol);
+      cell(row, t.side, t.side === 'BUY' ? 'up' : 'down');
+      cell(row, t.status).title = t.error || '';
+      cell(row, num(t.executedPrice || t.entryPrice));
+      cell(row, num(t.stopLoss) + ' / ' + num(t.takeProfit));
+      cell(row, num(t.size, 2) + ' x' + t.leverage);
+      cell(row, t.pnl ? num(t.pnl, 2) : '-', signClass(t.pnl));
+      body.appendChild(row);
+    });
+
+    $('no-trades').hidden = state.trades.length > 0;
+  }
+
+  function renderPause(status) {
+    $('pause-state').hidden = false;
+    $('pause-state').textContent = status.paused ? 'paused' + (status.reason ? ': ' + status.reason : '') : 'trading';
+    $('pause-state').className = 'badge ' + (status.paused ? 'bad' : 'ok');
+    $('pause').hidden = status.paused;
+    $('resume').hidden = !status.paused;
+  }
+
+  // ---- Actions -----------------------------------------------------------
+
+  function closePosition(symbol) {
+    if (!confirm('Close the ' + symbol + ' position at market?')) return;
+    action(api('POST', '/position/close', { symbol: symbol }), function () {
+      loadPositions();
+      loadOrders();
+    });
+  }
+
+  function cancelOrder(order) {
+    if (!confirm('Cancel ' + order.type + ' order ' + order.orderId + ' on ' + order.symbol + '?')) return;
+    action(api('POST', '/orders/cancel', { symbol: order.symbol, orderId: order.orderId }), loadOrders);
+  }
+
+  function cancelAll() {
+    if (!confirm('Cancel ALL open orders, including stop losses and take profits?')) return;
+    action(api('POST', '/orders/cancel', {}), loadOrders);
+  }
+
+  function pause() {
+    var reason = prompt('Pause new trades. Reason shown to rejected callers:', 'Paused from dashboard');
+    if (reason === null) return;
+    action(api('POST', '/admin/pause', { reason: reason }), loadPause);
+  }
+
+  function resume() {
+    if (!confirm('Accept new trades again?')) return;
+    action(api('POST', '/admin/resume'), loadPause);
+  }
+
+  // ---- Live updates ------------------------------------------------------
+
+  function connect() {
+    var scheme = location.protocol === 'https:' ? 'wss://' : 'ws://';
+    var socket = new WebSocket(scheme + location.host + '/ws?token=' + encodeURIComponent(state.credential));
+    state.socket = socket;
+
+    socket.onopen = function () {
+      state.reconnectDelay = 1000;
+      setStream('live', 'ok');
+    };
+    socket.onmessage = function (event) {
+      var msg;
+      try { msg = JSON.parse(event.data); } catch (e) { return; }
+      onMessage(msg);
+    };
+    socket.onclose = function () {
+      if (state.socket !== socket) return; // Signed out
+      setStream('reconnecting', 'warn');
+      setTimeout(function () { if (state.socket === socket) connect(); }, state.reconnectDelay);
+      state.reconnectDelay = Math.min(state.reconnectDelay * 2, 30000);
+    };
+  }
+
+  function setStream(text, level) {
+    $('stream').textContent = text;
+    $('stream').className = 'badge ' + level;
+  }
+
+  function onMessage(msg) {
+    switch (msg.type) {
+      case 'position':
+        var p = msg.data;
+        var key = p.symbol + ':' + (p.positionSide || 'BOTH');
+        if (!Number(p.positionAmt)) {
+          delete state.positions[key];
+        } else if (state.positions[key]) {
+          Object.assign(state.positions[key], {
+            positionAmt: p.positionAmt,
+            entryPrice: p.entryPrice,
+            unrealizedProfit: p.unrealizedProfit
+          });
+          if (p.markPrice) state.positions[key].markPrice = p.markPrice;
+        } else {
+          // New position: fetch leverage and liquidation price
+          loadPositions();
+          return;
+        }
+        renderPositions();
+        break;
+      case 'balance':
+        if (msg.data.asset === 'USDT') $('wallet').textContent = usd(msg.data.walletBalance);
+        break;
+      case 'trade':
+        var trade = msg.data;
+        state.trades = state.trades.filter(function (t) { return t.id !== trade.id; });
+        state.trades.unshift(trade);
+        state.trades.sort(function (a, b) { return b.createdAt - a.createdAt; });
+        state.trades = state.trades.slice(0, MAX_TRADES);
+        renderTrades();
+        break;
+    }
+  }
+
+  // ---- Session -----------------------------------------------------------
+
+  function start() {
+    $('login').hidden = true;
+    $('app').hidden = false;
+
+    loadPause();
+    loadPositions();
+    loadOrders();
+    loadTrades();
+    loadSummary();
+    loadHealth();
+    connect();
+
+    state.timers.push(setInterval(function () {
+      loadPause();
+      loadOrders();
+      loadSummary();
+      loadHealth();
+    }, REFRESH_MS));
+    state.timers.push(setInterval(loadPositions, 2 * REFRESH_MS));
+  }
+
+  function signOut(message) {
+    sessionStorage.removeItem(STORAGE_KEY);
+    localStorage.removeItem(STORAGE_KEY);
+    state.credential = null;
+
+    state.timers.forEach(clearInterval);
+    state.timers = [];
+    if (state.socket) {
+      var socket = state.socket;
+      state.socket = null;
+      socket.close();
+    }
+    setStream('offline', '');
+
+    $('app').hidden = true;
+    $('login').hidden = false;
+    $('login-error').hidden = !message;
+    $('login-error').textContent = message || '';
+  }
+
+  $('login-form').addEventListener('submit', function (event) {
+    event.preventDefault();
+    state.credential = $('credential').value.trim();
+    $('credential').value = '';
+    (($('remember').checked) ? localStorage : sessionStorage).setItem(STORAGE_KEY, state.credential);
+    start();
+  });
+  $('logout').addEventListener('click', function () { signOut(); });
+  $('pause').addEventListener('click', pause);
+  $('resume').addEventListener('click', resume);
+  $('cancel-all').addEventListener('click', cancelAll);
+  $('period').addEventListener('change', loadSummary);
+
+  state.credential = sessionStorage.getItem(STORAGE_KEY) || localStorage.getItem(STORAGE_KEY);
+  if (state.credential) {
+    start();
+  } else {
+    $('login').hidden = false;
+  }
+})();
@@ -0,0 +1,116 @@
+package events
+
+import (
+	"crypto-trading-api/internal/logging"
+	"sync"
+)
+
+// subscriberBuffer is how many events may queue for a slow subscriber
+// before further events to it are dropped
+const subscriberBuffer = 256
+
+// Event is a typed message published on the bus
+type Event interface {
+	Topic() Topic
+}
+
+// Handler consumes events of one topic
+type Handler func(event Event)
+
+// Bus delivers published events to every subscriber of their topic. Each
+// subscriber has its own queue and goroutine, so a slow consumer (Firebase,
+// Telegram) never delays the publisher or other consumers, and events reach
+// a subscriber in the order they were published. A nil Bus is valid and
+// drops all events.
+type Bus struct {
+	subscribers map[Topic]map[int]*subscriber
+	nextID      int
+	mu          sync.RWMutex
+}
+
+type subscriber struct {
+	name  string
+	queue chan Event
+	done  chan struct{}
+}
+
+// NewBus creates an event bus
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[Topic]map[int]*subscriber)}
+}
+
+// Subscribe registers a handler for a topic and returns a function that
+// removes it. name identifies the subscriber in logs.
+func (b *Bus) Subscribe(topic Topic, name string, handler Handler) func() {
+	if b == nil {
+		return func() {}
+	}
+
+	sub := &subscriber{
+		name:  name,
+		queue: make(chan Event, subscriberBuffer),
+		done:  make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = make(map[int]*subscriber)
+	}
+	b.nextID++
+	id := b.nextID
+	b.subscribers[topic][id] = sub
+	b.mu.Unlock()
+
+	go sub.run(handler)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers[topic], id)
+			b.mu.Unlock()
+			close(sub.done)
+		})
+	}
+}
+
+// Publish queues an event for every subscriber of its topic
+func (b *Bus) Publish(event Event) {
+	if b == nil || event == nil {
+		return
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers[event.Topic()] {
+		select {
+		case sub.queue <- event:
+		default:
+			logging.Warn().Msgf("Event bus: %s is not keeping up, dropped %s", sub.name, event.Topic())
+		}
+	}
+}
+
+// run hands queued events to the handler until the subscription ends
+func (s *subscriber) run(handler Handler) {
+	for {
+		select {
+		case event := <-s.queue:
+			s.handle(handler, event)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// handle runs the handler, keeping a panicking consumer from taking the
+// subscription down
+func (s *subscriber) handle(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.Warn().Msgf("Event bus: %s panicked on %s: %v", s.name, event.Topic(), r)
+		}
+	}()
+	handler(event)
+}
@@ -0,0 +1,136 @@
+package events
+
+import "crypto-trading-api/internal/models"
+
+// Topic names an event type
+type Topic string
+
+// Topics published on the bus
+const (
+	TopicTradeOpened    Topic = "trade.opened"    // A trade's entry order was placed
+	TopicOrderUpdated   Topic = "order.updated"   // Any ORDER_TRADE_UPDATE from the user data stream
+	TopicOrderFilled    Topic = "order.filled"    // An order filled completely
+	TopicPositionClosed Topic = "position.closed" // A position was closed (manually, by SL/TP or found gone)
+	TopicRiskWarning    Topic = "risk.warning"    // A position came close to liquidation
+	TopicAccountUpdated Topic = "account.updated" // Balance or position change from the user data stream
+	TopicCandleClosed   Topic = "candle.closed"   // An in-memory candle closed (kline streams)
+)
+
+// Reasons a position closed
+const (
+	CloseManual     = "MANUAL"      // POST /api/position/close, Telegram /close, ...
+	CloseStopLoss   = "STOP_LOSS"   // Stop loss order filled
+	CloseTakeProfit = "TAKE_PROFIT" // Take profit order filled
+	CloseReconciled = "RECONCILED"  // Reconciler found the position gone
+	CloseExternal   = "EXTERNAL"    // Closed outside the API (Binance app, web, another bot)
+)
+
+// TradeOpened is published once a trade's entry order is placed
+type TradeOpened struct {
+	Trade *models.Trade
+}
+
+// Topic implements Event
+func (TradeOpened) Topic() Topic { return TopicTradeOpened }
+
+// Order describes an order as reported by the user data stream
+type Order struct {
+	Symbol          string
+	OrderID         int64
+	Side            string
+	Type            string  // Current type (triggered stops report MARKET)
+	OrigType        string  // Type the order was placed with (STOP_MARKET, TAKE_PROFIT_MARKET, ...)
+	Status          string  // NEW, PARTIALLY_FILLED, FILLED, CANCELED, EXPIRED
+	AvgPrice        float64 // Average fill price so far
+	FilledQty       float64 // Accumulated filled quantity
+	RealizedPnL     float64 // Realized PnL of this fill
+	Commission      float64 // Fee of this fill
+	CommissionAsset string
+	Time            int64 // Transaction time (ms)
+}
+
+// ProtectiveType returns the type that tells stop loss from take profit
+// orders: triggered stops report MARKET, so the original type wins
+func (o Order) ProtectiveType() string {
+	if o.OrigType != "" {
+		return o.OrigType
+	}
+	return o.Type
+}
+
+// OrderUpdated is published for every order update of the primary account
+type OrderUpdated struct {
+	Order
+}
+
+// Topic implements Event
+func (OrderUpdated) Topic() Topic { return TopicOrderUpdated }
+
+// OrderFilled is published when an order of the primary account fills completely
+type OrderFilled struct {
+	Order
+}
+
+// Topic implements Event
+func (OrderFilled) Topic() Topic { return TopicOrderFilled }
+
+// PositionClosed is published when a position is closed. Trade is the
+// linked trade, nil when none is known.
+type PositionClosed struct {
+	Trade       *models.Trade
+	Symbol      string
+	RealizedPnL float64
+	Reason      string // MANUAL, STOP_LOSS, TAKE_PROFIT, RECONCILED or EXTERNAL
+}
+
+// Topic implements Event
+func (PositionClosed) Topic() Topic { return TopicPositionClosed }
+
+// RiskWarning is published when a position gets close to liquidation
+type RiskWarning struct {
+	Symbol           string
+	MarkPrice        float64
+	LiquidationPrice float64
+	Distance         float64 // Distance to liquidation (%)
+	Action           string  // What the margin guard did about it
+}
+
+// Topic implements Event
+func (RiskWarning) Topic() Topic { return TopicRiskWarning }
+
+// Balance is a wallet balance reported by an account update
+type Balance struct {
+	Asset              string
+	WalletBalance      float64
+	CrossWalletBalance float64
+}
+
+// Position is a position reported by an account update
+type Position struct {
+	Symbol        string
+	PositionSide  string
+	PositionAmt   float64 // 0 once closed
+	EntryPrice    float64
+	UnrealizedPnL float64
+}
+
+// AccountUpdated is published for every ACCOUNT_UPDATE of the primary account
+type AccountUpdated struct {
+	Reason    string
+	Balances  []Balance
+	Positions []Position
+	Time      int64 // Event time (ms)
+}
+
+// Topic implements Event
+func (AccountUpdated) Topic() Topic { return TopicAccountUpdated }
+
+// CandleClosed is published when an in-memory candle closes
+type CandleClosed struct {
+	Symbol   string
+	Interval string
+	Candle   models.Candle
+}
+
+// Topic implements Event
+func (CandleClosed) Topic() Topic { return TopicCandleClosed }
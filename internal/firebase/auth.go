@@ -0,0 +1,87 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// OAuth scopes of the Realtime Database and Firestore REST APIs
+var (
+	databaseScopes = []string{
+		"https://www.googleapis.com/auth/userinfo.email",
+		"https://www.googleapis.com/auth/firebase.database",
+	}
+	firestoreScopes = []string{"https://www.googleapis.com/auth/datastore"}
+)
+
+// tokenSource hands out OAuth access tokens for Firebase requests. Tokens are
+// cached and refreshed shortly before they expire (after about an hour), and
+// can be refreshed early when Firebase rejects one.
+type tokenSource struct {
+	mu              sync.Mutex
+	credentialsFile string
+	scopes          []string
+	source          oauth2.TokenSource
+	projectID       string
+}
+
+// newTokenSource loads the service account from credentialsFile, or from the
+// default credential locations without one
+func newTokenSource(credentialsFile string, scopes ...string) (*tokenSource, error) {
+	t := &tokenSource{credentialsFile: credentialsFile, scopes: scopes}
+	if err := t.refresh(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// token returns a valid access token, fetching a new one if the cached token
+// has expired
+func (t *tokenSource) token() (string, error) {
+	t.mu.Lock()
+	source := t.source
+	t.mu.Unlock()
+
+	token, err := source.Token()
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// refresh drops the cached token so the next request fetches a new one. The
+// token source outlives any request, so it fetches with the background context
+// (and the shared connection pool). The credentials file is read again, so
+// rotated credentials are picked up.
+func (t *tokenSource) refresh() error {
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: httpTransport})
+	var credentials *google.Credentials
+	if t.credentialsFile == "" {
+		found, err := google.FindDefaultCredentials(ctx, t.scopes...)
+		if err != nil {
+			return err
+		}
+		credentials = found
+	} else {
+		data, err := os.ReadFile(t.credentialsFile)
+		if err != nil {
+			return fmt.Errorf("failed to read credentials file: %v", err)
+		}
+		credentials, err = google.CredentialsFromJSON(ctx, data, t.scopes...)
+		if err != nil {
+			return err
+		}
+	}
+
+	t.mu.Lock()
+	t.source = credentials.TokenSource
+	t.projectID = credentials.ProjectID
+	t.mu.Unlock()
+	return nil
+}
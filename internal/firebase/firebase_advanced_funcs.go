@@ -2,10 +2,11 @@ package firebase
 
 import (
 	"context"
+	"crypto-trading-api/internal/logging"
 	"crypto-trading-api/internal/models"
 	"encoding/json"
 	"fmt"
-	"log"
+	"sort"
 	"time"
 )
 
@@ -60,6 +61,88 @@ func (f *Client) GetTradesByStatus(ctx context.Context, status string) ([]*model
 	return trades, nil
 }
 
+// tradeQueryBatch is how many trades one indexed read returns while filters
+// the index cannot apply (status, symbol) are checked afterwards
+const tradeQueryBatch = 200
+
+// QueryTrades - Get one page of trades matching the filters, newest first.
+// Trades are read from the createdAt index (".indexOn": "createdAt") in
+// batches going back in time; status and symbol are filtered per batch.
+func (f *Client) QueryTrades(ctx context.Context, query models.TradeQuery) (*models.TradePage, error) {
+	path := "/trades"
+	if query.UserID != "" {
+		path = fmt.Sprintf("/users/%s/trades", query.UserID)
+	}
+
+	endAt := query.To
+	if query.After != nil && (endAt == 0 || query.After.CreatedAt < endAt) {
+		endAt = query.After.CreatedAt
+	}
+	batch := query.Limit + 1
+	if (query.Status != "" || query.Symbol != "") && batch < tradeQueryBatch {
+		batch = tradeQueryBatch
+	}
+
+	matched := []*models.Trade{}
+	for {
+		params := fmt.Sprintf("orderBy=\"createdAt\"&limitToLast=%d", batch)
+		if query.From != 0 {
+			params += fmt.Sprintf("&startAt=%d", query.From)
+		}
+		if endAt != 0 {
+			params += fmt.Sprintf("&endAt=%d", endAt)
+		}
+		respBody, err := f.makeRequest(ctx, "GET", path+"?"+params, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query trades: %v", err)
+		}
+
+		var tradesMap map[string]*models.Trade
+		if string(respBody) != "null" && string(respBody) != "" {
+			if err := json.Unmarshal(respBody, &tradesMap); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal trades: %v", err)
+			}
+		}
+
+		trades := make([]*models.Trade, 0, len(tradesMap))
+		for _, trade := range tradesMap {
+			trades = append(trades, trade)
+		}
+		sort.Slice(trades, func(i, j int) bool {
+			if trades[i].CreatedAt != trades[j].CreatedAt {
+				return trades[i].CreatedAt > trades[j].CreatedAt
+			}
+			return trades[i].ID > trades[j].ID
+		})
+
+		for _, trade := range trades {
+			if query.Matches(trade) {
+				matched = append(matched, trade)
+			}
+		}
+		if len(matched) > query.Limit || len(trades) < batch {
+			break
+		}
+
+		// Continue before the oldest trade read. Reads end at a whole second,
+		// so trades already seen are skipped by the cursor; if a single
+		// second holds the whole batch, read more at once.
+		oldest := trades[len(trades)-1]
+		if oldest.CreatedAt == endAt {
+			batch *= 2
+		}
+		endAt = oldest.CreatedAt
+		if query.After == nil || query.After.Precedes(oldest) {
+			query.After = &models.TradeCursor{CreatedAt: oldest.CreatedAt, ID: oldest.ID}
+		}
+	}
+
+	if len(matched) > query.Limit+1 {
+		matched = matched[:query.Limit+1]
+	}
+	return models.NewTradePage(matched, query.Limit), nil
+}
+
 // UpdateTradePnL - Update trade PnL
 func (f *Client) UpdateTradePnL(ctx context.Context, tradeID string, pnl float64, userID string) error {
 	// Get the trade first
@@ -109,6 +192,92 @@ func (f *Client) UpdateUserStats(ctx context.Context, userID string, stats map[s
 	return nil
 }
 
+// GetUserTradeStats - Get a user's running trade totals (nil if none)
+func (f *Client) GetUserTradeStats(ctx context.Context, userID string) (*models.UserTradeStats, error) {
+	path := fmt.Sprintf("/users/%s/stats", userID)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user stats: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return nil, nil
+	}
+
+	var stats models.UserTradeStats
+	if err := json.Unmarshal(respBody, &stats); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user stats: %v", err)
+	}
+
+	return &stats, nil
+}
+
+// SaveUserTradeStats - Store a user's running trade totals
+func (f *Client) SaveUserTradeStats(ctx context.Context, stats *models.UserTradeStats) error {
+	path := fmt.Sprintf("/users/%s/stats", stats.UserID)
+	_, err := f.makeRequest(ctx, "PUT", path, stats)
+	if err != nil {
+		return fmt.Errorf("failed to save user stats: %v", err)
+	}
+	return nil
+}
+
+// GetTradeContribution - Get what a trade added to its owner's stats (nil if
+// it was not counted)
+func (f *Client) GetTradeContribution(ctx context.Context, userID, tradeID string) (*models.TradeContribution, error) {
+	path := fmt.Sprintf("/users/%s/statsTrades/%s", userID, tradeID)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade contribution: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return nil, nil
+	}
+
+	var contribution models.TradeContribution
+	if err := json.Unmarshal(respBody, &contribution); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trade contribution: %v", err)
+	}
+
+	return &contribution, nil
+}
+
+// SaveTradeContribution - Store what a trade added to its owner's stats
+func (f *Client) SaveTradeContribution(ctx context.Context, contribution *models.TradeContribution) error {
+	path := fmt.Sprintf("/users/%s/statsTrades/%s", contribution.UserID, contribution.TradeID)
+	_, err := f.makeRequest(ctx, "PUT", path, contribution)
+	if err != nil {
+		return fmt.Errorf("failed to save trade contribution: %v", err)
+	}
+	return nil
+}
+
+// GetTradeContributions - Get every trade counted in a user's stats
+func (f *Client) GetTradeContributions(ctx context.Context, userID string) ([]*models.TradeContribution, error) {
+	path := fmt.Sprintf("/users/%s/statsTrades", userID)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade contributions: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return []*models.TradeContribution{}, nil
+	}
+
+	var contributionsMap map[string]*models.TradeContribution
+	if err := json.Unmarshal(respBody, &contributionsMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trade contributions: %v", err)
+	}
+
+	contributions := make([]*models.TradeContribution, 0, len(contributionsMap))
+	for _, contribution := range contributionsMap {
+		contributions = append(contributions, contribution)
+	}
+
+	return contributions, nil
+}
+
 // SaveSystemStats - Save system-wide statistics
 func (f *Client) SaveSystemStats(ctx context.Context, stats map[string]interface{}) error {
 	stats["lastUpdate"] = getCurrentTimestamp()
@@ -145,7 +314,7 @@ func (f *Client) GetSystemStats(ctx context.Context) (map[string]interface{}, er
 func (f *Client) BatchUpdateTrades(ctx context.Context, trades []*models.Trade) error {
 	for _, trade := range trades {
 		if err := f.UpdateTrade(ctx, trade); err != nil {
-			log.Printf("Error updating trade %s: %v", trade.ID, err)
+			logging.Error().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("Error updating trade %s", trade.ID)
 		}
 	}
 	return nil
@@ -201,3 +370,730 @@ func currentTimeMillis() int64 {
 func currentTime() time.Time {
 	return time.Now()
 }
+
+// SaveRiskAction - Journal an automated risk action
+func (f *Client) SaveRiskAction(ctx context.Context, action *models.RiskAction) error {
+	path := fmt.Sprintf("/risk/actions/%s", action.ID)
+	_, err := f.makeRequest(ctx, "PUT", path, action)
+	if err != nil {
+		return fmt.Errorf("failed to save risk action: %v", err)
+	}
+	return nil
+}
+
+// GetSymbolPolicy - Get the persisted symbol allow/block lists (nil if never saved)
+func (f *Client) GetSymbolPolicy(ctx context.Context) (*models.SymbolPolicyConfig, error) {
+	path := "/settings/symbolPolicy"
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol policy: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return nil, nil
+	}
+
+	var cfg models.SymbolPolicyConfig
+	if err := json.Unmarshal(respBody, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal symbol policy: %v", err)
+	}
+
+	return &cfg, nil
+}
+
+// SaveSymbolPolicy - Persist the symbol allow/block lists
+func (f *Client) SaveSymbolPolicy(ctx context.Context, cfg *models.SymbolPolicyConfig) error {
+	path := "/settings/symbolPolicy"
+	_, err := f.makeRequest(ctx, "PUT", path, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to save symbol policy: %v", err)
+	}
+	return nil
+}
+
+// SaveReport - Store a summary report
+func (f *Client) SaveReport(ctx context.Context, report *models.SummaryReport) error {
+	path := fmt.Sprintf("/reports/%s", report.ID)
+	_, err := f.makeRequest(ctx, "PUT", path, report)
+	if err != nil {
+		return fmt.Errorf("failed to save report: %v", err)
+	}
+	return nil
+}
+
+// GetReport - Get a summary report by ID (nil if it doesn't exist)
+func (f *Client) GetReport(ctx context.Context, reportID string) (*models.SummaryReport, error) {
+	path := fmt.Sprintf("/reports/%s", reportID)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return nil, nil
+	}
+
+	var report models.SummaryReport
+	if err := json.Unmarshal(respBody, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal report: %v", err)
+	}
+
+	return &report, nil
+}
+
+// GetReports - Get all stored summary reports
+func (f *Client) GetReports(ctx context.Context) ([]*models.SummaryReport, error) {
+	respBody, err := f.makeRequest(ctx, "GET", "/reports", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return []*models.SummaryReport{}, nil
+	}
+
+	var reportsMap map[string]*models.SummaryReport
+	if err := json.Unmarshal(respBody, &reportsMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reports: %v", err)
+	}
+
+	reports := make([]*models.SummaryReport, 0, len(reportsMap))
+	for _, report := range reportsMap {
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// SaveBalanceSnapshot - Store a daily balance snapshot
+func (f *Client) SaveBalanceSnapshot(ctx context.Context, snapshot *models.BalanceSnapshot) error {
+	path := fmt.Sprintf("/balanceHistory/%s", snapshot.Date)
+	_, err := f.makeRequest(ctx, "PUT", path, snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to save balance snapshot: %v", err)
+	}
+	return nil
+}
+
+// GetBalanceSnapshots - Get all daily balance snapshots
+func (f *Client) GetBalanceSnapshots(ctx context.Context) ([]*models.BalanceSnapshot, error) {
+	respBody, err := f.makeRequest(ctx, "GET", "/balanceHistory", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance snapshots: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return []*models.BalanceSnapshot{}, nil
+	}
+
+	var snapshotsMap map[string]*models.BalanceSnapshot
+	if err := json.Unmarshal(respBody, &snapshotsMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal balance snapshots: %v", err)
+	}
+
+	snapshots := make([]*models.BalanceSnapshot, 0, len(snapshotsMap))
+	for _, snapshot := range snapshotsMap {
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// SaveWebhook - Store a webhook registration
+func (f *Client) SaveWebhook(ctx context.Context, hook *models.Webhook) error {
+	path := fmt.Sprintf("/webhooks/%s", hook.ID)
+	_, err := f.makeRequest(ctx, "PUT", path, hook)
+	if err != nil {
+		return fmt.Errorf("failed to save webhook: %v", err)
+	}
+	return nil
+}
+
+// GetWebhooks - Get all webhook registrations
+func (f *Client) GetWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	respBody, err := f.makeRequest(ctx, "GET", "/webhooks", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return []*models.Webhook{}, nil
+	}
+
+	var hooksMap map[string]*models.Webhook
+	if err := json.Unmarshal(respBody, &hooksMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhooks: %v", err)
+	}
+
+	hooks := make([]*models.Webhook, 0, len(hooksMap))
+	for _, hook := range hooksMap {
+		hooks = append(hooks, hook)
+	}
+
+	return hooks, nil
+}
+
+// GetWebhook - Get a webhook registration by ID
+func (f *Client) GetWebhook(ctx context.Context, hookID string) (*models.Webhook, error) {
+	path := fmt.Sprintf("/webhooks/%s", hookID)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return nil, fmt.Errorf("webhook not found")
+	}
+
+	var hook models.Webhook
+	if err := json.Unmarshal(respBody, &hook); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook: %v", err)
+	}
+
+	return &hook, nil
+}
+
+// DeleteWebhook - Remove a webhook registration
+func (f *Client) DeleteWebhook(ctx context.Context, hookID string) error {
+	path := fmt.Sprintf("/webhooks/%s", hookID)
+	_, err := f.makeRequest(ctx, "DELETE", path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %v", err)
+	}
+	return nil
+}
+
+// SavePriceAlert - Store a price alert
+func (f *Client) SavePriceAlert(ctx context.Context, alert *models.PriceAlert) error {
+	path := fmt.Sprintf("/alerts/price/%s", alert.ID)
+	_, err := f.makeRequest(ctx, "PUT", path, alert)
+	if err != nil {
+		return fmt.Errorf("failed to save price alert: %v", err)
+	}
+	return nil
+}
+
+// GetPriceAlerts - Get all price alerts
+func (f *Client) GetPriceAlerts(ctx context.Context) ([]*models.PriceAlert, error) {
+	respBody, err := f.makeRequest(ctx, "GET", "/alerts/price", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price alerts: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return []*models.PriceAlert{}, nil
+	}
+
+	var alertsMap map[string]*models.PriceAlert
+	if err := json.Unmarshal(respBody, &alertsMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal price alerts: %v", err)
+	}
+
+	alerts := make([]*models.PriceAlert, 0, len(alertsMap))
+	for _, alert := range alertsMap {
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, nil
+}
+
+// SavePerformanceAlert - Store a performance alert
+func (f *Client) SavePerformanceAlert(ctx context.Context, alert *models.PerformanceAlert) error {
+	path := fmt.Sprintf("/alerts/performance/%s", alert.ID)
+	_, err := f.makeRequest(ctx, "PUT", path, alert)
+	if err != nil {
+		return fmt.Errorf("failed to save performance alert: %v", err)
+	}
+	return nil
+}
+
+// GetPerformanceAlerts - Get all performance alerts
+func (f *Client) GetPerformanceAlerts(ctx context.Context) ([]*models.PerformanceAlert, error) {
+	respBody, err := f.makeRequest(ctx, "GET", "/alerts/performance", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get performance alerts: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return []*models.PerformanceAlert{}, nil
+	}
+
+	var alertsMap map[string]*models.PerformanceAlert
+	if err := json.Unmarshal(respBody, &alertsMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal performance alerts: %v", err)
+	}
+
+	alerts := make([]*models.PerformanceAlert, 0, len(alertsMap))
+	for _, alert := range alertsMap {
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, nil
+}
+
+// SaveTradingViewTemplate - Store a TradingView alert template
+func (f *Client) SaveTradingViewTemplate(ctx context.Context, tpl *models.TradingViewTemplate) error {
+	path := fmt.Sprintf("/tradingview/templates/%s", tpl.Name)
+	_, err := f.makeRequest(ctx, "PUT", path, tpl)
+	if err != nil {
+		return fmt.Errorf("failed to save tradingview template: %v", err)
+	}
+	return nil
+}
+
+// GetTradingViewTemplate - Get a TradingView alert template by name (nil if it does not exist)
+func (f *Client) GetTradingViewTemplate(ctx context.Context, name string) (*models.TradingViewTemplate, error) {
+	path := fmt.Sprintf("/tradingview/templates/%s", name)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tradingview template: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return nil, nil
+	}
+
+	var tpl models.TradingViewTemplate
+	if err := json.Unmarshal(respBody, &tpl); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tradingview template: %v", err)
+	}
+
+	return &tpl, nil
+}
+
+// GetTradingViewTemplates - Get all TradingView alert templates
+func (f *Client) GetTradingViewTemplates(ctx context.Context) ([]*models.TradingViewTemplate, error) {
+	respBody, err := f.makeRequest(ctx, "GET", "/tradingview/templates", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tradingview templates: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return []*models.TradingViewTemplate{}, nil
+	}
+
+	var tplMap map[string]*models.TradingViewTemplate
+	if err := json.Unmarshal(respBody, &tplMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tradingview templates: %v", err)
+	}
+
+	templates := make([]*models.TradingViewTemplate, 0, len(tplMap))
+	for _, tpl := range tplMap {
+		templates = append(templates, tpl)
+	}
+
+	return templates, nil
+}
+
+// DeleteTradingViewTemplate - Remove a TradingView alert template
+func (f *Client) DeleteTradingViewTemplate(ctx context.Context, name string) error {
+	path := fmt.Sprintf("/tradingview/templates/%s", name)
+	_, err := f.makeRequest(ctx, "DELETE", path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete tradingview template: %v", err)
+	}
+	return nil
+}
+
+// SaveFundingArbGroup - Store a funding arbitrage group
+func (f *Client) SaveFundingArbGroup(ctx context.Context, group *models.FundingArbGroup) error {
+	path := fmt.Sprintf("/arbitrage/funding/%s", group.ID)
+	_, err := f.makeRequest(ctx, "PUT", path, group)
+	if err != nil {
+		return fmt.Errorf("failed to save funding arbitrage group: %v", err)
+	}
+	return nil
+}
+
+// GetFundingArbGroups - Get all funding arbitrage groups
+func (f *Client) GetFundingArbGroups(ctx context.Context) ([]*models.FundingArbGroup, error) {
+	respBody, err := f.makeRequest(ctx, "GET", "/arbitrage/funding", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get funding arbitrage groups: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return []*models.FundingArbGroup{}, nil
+	}
+
+	var groupsMap map[string]*models.FundingArbGroup
+	if err := json.Unmarshal(respBody, &groupsMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal funding arbitrage groups: %v", err)
+	}
+
+	groups := make([]*models.FundingArbGroup, 0, len(groupsMap))
+	for _, group := range groupsMap {
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// SaveStrategyPreset - Store a strategy preset
+func (f *Client) SaveStrategyPreset(ctx context.Context, preset *models.StrategyPreset) error {
+	path := fmt.Sprintf("/presets/%s", preset.Name)
+	_, err := f.makeRequest(ctx, "PUT", path, preset)
+	if err != nil {
+		return fmt.Errorf("failed to save preset: %v", err)
+	}
+	return nil
+}
+
+// GetStrategyPreset - Get a strategy preset by name (nil if it does not exist)
+func (f *Client) GetStrategyPreset(ctx context.Context, name string) (*models.StrategyPreset, error) {
+	path := fmt.Sprintf("/presets/%s", name)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preset: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return nil, nil
+	}
+
+	var preset models.StrategyPreset
+	if err := json.Unmarshal(respBody, &preset); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal preset: %v", err)
+	}
+
+	return &preset, nil
+}
+
+// GetStrategyPresets - Get all strategy presets
+func (f *Client) GetStrategyPresets(ctx context.Context) ([]*models.StrategyPreset, error) {
+	respBody, err := f.makeRequest(ctx, "GET", "/presets", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get presets: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return []*models.StrategyPreset{}, nil
+	}
+
+	var presetsMap map[string]*models.StrategyPreset
+	if err := json.Unmarshal(respBody, &presetsMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal presets: %v", err)
+	}
+
+	presets := make([]*models.StrategyPreset, 0, len(presetsMap))
+	for _, preset := range presetsMap {
+		presets = append(presets, preset)
+	}
+
+	return presets, nil
+}
+
+// DeleteStrategyPreset - Remove a strategy preset
+func (f *Client) DeleteStrategyPreset(ctx context.Context, name string) error {
+	path := fmt.Sprintf("/presets/%s", name)
+	_, err := f.makeRequest(ctx, "DELETE", path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete preset: %v", err)
+	}
+	return nil
+}
+
+// SaveUserCredentials - Store a user's encrypted Binance credentials
+func (f *Client) SaveUserCredentials(ctx context.Context, creds *models.UserCredentials) error {
+	path := fmt.Sprintf("/credentials/%s", creds.UserID)
+	_, err := f.makeRequest(ctx, "PUT", path, creds)
+	if err != nil {
+		return fmt.Errorf("failed to save credentials: %v", err)
+	}
+	return nil
+}
+
+// GetUserCredentials - Get a user's encrypted Binance credentials (nil if none)
+func (f *Client) GetUserCredentials(ctx context.Context, userID string) (*models.UserCredentials, error) {
+	path := fmt.Sprintf("/credentials/%s", userID)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return nil, nil
+	}
+
+	var creds models.UserCredentials
+	if err := json.Unmarshal(respBody, &creds); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal credentials: %v", err)
+	}
+
+	return &creds, nil
+}
+
+// DeleteUserCredentials - Remove a user's Binance credentials
+func (f *Client) DeleteUserCredentials(ctx context.Context, userID string) error {
+	path := fmt.Sprintf("/credentials/%s", userID)
+	_, err := f.makeRequest(ctx, "DELETE", path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete credentials: %v", err)
+	}
+	return nil
+}
+
+// SaveUserSettings - Store a user's settings
+func (f *Client) SaveUserSettings(ctx context.Context, settings *models.UserSettings) error {
+	path := fmt.Sprintf("/users/%s/settings", settings.UserID)
+	_, err := f.makeRequest(ctx, "PUT", path, settings)
+	if err != nil {
+		return fmt.Errorf("failed to save user settings: %v", err)
+	}
+	return nil
+}
+
+// GetUserSettings - Get a user's settings (nil if none)
+func (f *Client) GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error) {
+	path := fmt.Sprintf("/users/%s/settings", userID)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return nil, nil
+	}
+
+	var settings models.UserSettings
+	if err := json.Unmarshal(respBody, &settings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user settings: %v", err)
+	}
+
+	return &settings, nil
+}
+
+// DeleteUserSettings - Remove a user's settings
+func (f *Client) DeleteUserSettings(ctx context.Context, userID string) error {
+	path := fmt.Sprintf("/users/%s/settings", userID)
+	_, err := f.makeRequest(ctx, "DELETE", path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete user settings: %v", err)
+	}
+	return nil
+}
+
+// SaveAPIKey - Store a managed API key record
+func (f *Client) SaveAPIKey(ctx context.Context, key *models.APIKey) error {
+	path := fmt.Sprintf("/apikeys/%s", key.ID)
+	_, err := f.makeRequest(ctx, "PUT", path, key)
+	if err != nil {
+		return fmt.Errorf("failed to save api key: %v", err)
+	}
+	return nil
+}
+
+// GetAPIKey - Get a managed API key record (nil if not found)
+func (f *Client) GetAPIKey(ctx context.Context, keyID string) (*models.APIKey, error) {
+	path := fmt.Sprintf("/apikeys/%s", keyID)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return nil, nil
+	}
+
+	var key models.APIKey
+	if err := json.Unmarshal(respBody, &key); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal api key: %v", err)
+	}
+
+	return &key, nil
+}
+
+// GetAPIKeys - Get all managed API key records
+func (f *Client) GetAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	respBody, err := f.makeRequest(ctx, "GET", "/apikeys", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api keys: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return []*models.APIKey{}, nil
+	}
+
+	var keysMap map[string]*models.APIKey
+	if err := json.Unmarshal(respBody, &keysMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal api keys: %v", err)
+	}
+
+	keys := make([]*models.APIKey, 0, len(keysMap))
+	for _, key := range keysMap {
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// SaveRoleAssignment - Store a user's role
+func (f *Client) SaveRoleAssignment(ctx context.Context, assignment *models.RoleAssignment) error {
+	path := fmt.Sprintf("/roles/%s", assignment.UserID)
+	_, err := f.makeRequest(ctx, "PUT", path, assignment)
+	if err != nil {
+		return fmt.Errorf("failed to save role: %v", err)
+	}
+	return nil
+}
+
+// GetRoleAssignment - Get a user's role (nil if none assigned)
+func (f *Client) GetRoleAssignment(ctx context.Context, userID string) (*models.RoleAssignment, error) {
+	path := fmt.Sprintf("/roles/%s", userID)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return nil, nil
+	}
+
+	var assignment models.RoleAssignment
+	if err := json.Unmarshal(respBody, &assignment); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal role: %v", err)
+	}
+
+	return &assignment, nil
+}
+
+// GetRoleAssignments - Get all role assignments
+func (f *Client) GetRoleAssignments(ctx context.Context) ([]*models.RoleAssignment, error) {
+	respBody, err := f.makeRequest(ctx, "GET", "/roles", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return []*models.RoleAssignment{}, nil
+	}
+
+	var rolesMap map[string]*models.RoleAssignment
+	if err := json.Unmarshal(respBody, &rolesMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal roles: %v", err)
+	}
+
+	roles := make([]*models.RoleAssignment, 0, len(rolesMap))
+	for _, assignment := range rolesMap {
+		roles = append(roles, assignment)
+	}
+
+	return roles, nil
+}
+
+// DeleteRoleAssignment - Remove a user's role (the default role applies again)
+func (f *Client) DeleteRoleAssignment(ctx context.Context, userID string) error {
+	path := fmt.Sprintf("/roles/%s", userID)
+	_, err := f.makeRequest(ctx, "DELETE", path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete role: %v", err)
+	}
+	return nil
+}
+
+// SaveTradeEvent - Append an event to a trade's history. Events live beside
+// the trades rather than under them, since every trade write replaces the
+// whole /trades/{id} node.
+func (f *Client) SaveTradeEvent(ctx context.Context, event *models.TradeEvent) error {
+	path := fmt.Sprintf("/tradeEvents/%s/%s", event.TradeID, event.ID)
+	_, err := f.makeRequest(ctx, "PUT", path, event)
+	if err != nil {
+		return fmt.Errorf("failed to save trade event: %v", err)
+	}
+	return nil
+}
+
+// GetTradeEvents - Get a trade's history, oldest first
+func (f *Client) GetTradeEvents(ctx context.Context, tradeID string) ([]*models.TradeEvent, error) {
+	path := fmt.Sprintf("/tradeEvents/%s", tradeID)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade events: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return []*models.TradeEvent{}, nil
+	}
+
+	var eventsMap map[string]*models.TradeEvent
+	if err := json.Unmarshal(respBody, &eventsMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trade events: %v", err)
+	}
+
+	events := make([]*models.TradeEvent, 0, len(eventsMap))
+	for _, event := range eventsMap {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+
+	return events, nil
+}
+
+// SaveAuditEntry - Append an audit log entry
+func (f *Client) SaveAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	path := fmt.Sprintf("/audit/%s", entry.ID)
+	_, err := f.makeRequest(ctx, "PUT", path, entry)
+	if err != nil {
+		return fmt.Errorf("failed to save audit entry: %v", err)
+	}
+	return nil
+}
+
+// GetAuditEntries - Get up to limit audit entries between two entry IDs
+// (inclusive), newest last. Entry IDs start with the zero-padded Unix
+// nanosecond time, so key order is chronological and needs no index.
+func (f *Client) GetAuditEntries(ctx context.Context, startID, endID string, limit int) ([]*models.AuditEntry, error) {
+	path := fmt.Sprintf("/audit?orderBy=\"$key\"&startAt=\"%s\"&endAt=\"%s\"&limitToLast=%d", startID, endID, limit)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return []*models.AuditEntry{}, nil
+	}
+
+	var entriesMap map[string]*models.AuditEntry
+	if err := json.Unmarshal(respBody, &entriesMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit entries: %v", err)
+	}
+
+	entries := make([]*models.AuditEntry, 0, len(entriesMap))
+	for _, entry := range entriesMap {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+
+	return entries, nil
+}
+
+// SaveIdempotencyRecord - Store the response to replay for an idempotency key
+func (f *Client) SaveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	path := fmt.Sprintf("/idempotency/%s", record.Key)
+	_, err := f.makeRequest(ctx, "PUT", path, record)
+	if err != nil {
+		return fmt.Errorf("failed to save idempotency record: %v", err)
+	}
+	return nil
+}
+
+// GetIdempotencyRecord - Get the stored response for an idempotency key (nil
+// if none)
+func (f *Client) GetIdempotencyRecord(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	path := fmt.Sprintf("/idempotency/%s", key)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return nil, nil
+	}
+
+	var record models.IdempotencyRecord
+	if err := json.Unmarshal(respBody, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal idempotency record: %v", err)
+	}
+
+	return &record, nil
+}
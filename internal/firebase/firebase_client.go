@@ -3,31 +3,26 @@ package firebase
 import (
 	"bytes"
 	"context"
+	"crypto-trading-api/internal/logging"
 	"crypto-trading-api/internal/models"
 	"encoding/json"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
-	"os"
+	"net/url"
 	"strings"
-
-	"golang.org/x/oauth2/google"
+	"sync"
+	"time"
 )
 
 type Client struct {
-	databaseURL string
-	authToken   string
-	httpClient  *http.Client
+	databaseURL    string
+	rest           *restClient
+	tradeListeners []func(ctx context.Context, trade *models.Trade)
+	repairWarning  sync.Once // Logs once that read-repair lacks its index
 }
 
-func InitClient() (*Client, error) {
-	ctx := context.Background()
-
-	// Firebase config
-	databaseURL := os.Getenv("FIREBASE_DATABASE_URL")
-	credentialsFile := os.Getenv("FIREBASE_CREDENTIALS_FILE")
-
+// NewClient connects to a Realtime Database, authenticated with the service
+// account in credentialsFile or unauthenticated without one
+func NewClient(databaseURL, credentialsFile string) (*Client, error) {
 	if databaseURL == "" {
 		return nil, fmt.Errorf("FIREBASE_DATABASE_URL must be set")
 	}
@@ -35,85 +30,53 @@ func InitClient() (*Client, error) {
 	// Remove trailing slash if present
 	databaseURL = strings.TrimRight(databaseURL, "/")
 
-	// Get OAuth access token using service account credentials
-	var authToken string
+	// Get OAuth access tokens using service account credentials. Tokens
+	// expire after an hour, so they are fetched per request, not once.
+	var tokens *tokenSource
 	if credentialsFile != "" {
-		// Set credentials file as environment variable for Google Default Credentials
-		os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", credentialsFile)
-
-		// Get access token using default credentials
-		tokenSource, err := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/firebase.database")
+		var err error
+		tokens, err = newTokenSource(credentialsFile, databaseScopes...)
 		if err != nil {
-			log.Printf("Warning: Could not get token source: %v", err)
-		} else {
-			token, err := tokenSource.Token()
-			if err != nil {
-				log.Printf("Warning: Could not get access token: %v", err)
-			} else {
-				authToken = token.AccessToken
-			}
+			logging.Warn().Err(err).Msg("Could not get token source")
+		} else if _, err := tokens.token(); err != nil {
+			logging.Warn().Err(err).Msg("Could not get access token")
 		}
 	}
 
-	httpClient := &http.Client{}
-
-	log.Printf("✅ Firebase client initialized successfully")
-	log.Printf("   Database URL: %s", databaseURL)
-	if authToken != "" {
-		log.Printf("   Auth: ✅ Access token obtained")
+	if tokens != nil {
+		logging.Info().Str("database_url", databaseURL).Str("auth", "service_account").
+			Msg("Firebase client initialized successfully (access token refreshed automatically)")
 	} else {
-		log.Printf("   Auth: ⚠️  No access token (using unauthenticated requests)")
+		logging.Warn().Str("database_url", databaseURL).Str("auth", "none").
+			Msg("Firebase client initialized without an access token (using unauthenticated requests)")
 	}
 
 	return &Client{
 		databaseURL: databaseURL,
-		authToken:   authToken,
-		httpClient:  httpClient,
+		rest:        newRestClient(tokens),
 	}, nil
 }
 
-// makeRequest makes an HTTP request to Firebase REST API
+// makeRequest makes an HTTP request to Firebase REST API. Query parameters
+// in path (orderBy, startAt, ...) are encoded after the .json suffix.
 func (f *Client) makeRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
-	url := fmt.Sprintf("%s%s.json", f.databaseURL, path)
-
-	// Add auth parameter if we have a token
-	if f.authToken != "" {
-		if strings.Contains(url, "?") {
-			url += "&auth=" + f.authToken
-		} else {
-			url += "?auth=" + f.authToken
-		}
-	}
-
-	var reqBody io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
+	path, query, _ := strings.Cut(path, "?")
+	endpoint := fmt.Sprintf("%s%s.json", f.databaseURL, path)
+	if query != "" {
+		params, err := url.ParseQuery(query)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %v", err)
+			return nil, fmt.Errorf("invalid query: %v", err)
 		}
-		reqBody = bytes.NewBuffer(jsonData)
+		endpoint += "?" + params.Encode()
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	status, respBody, err := f.rest.do(ctx, method, endpoint, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := f.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
-	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("firebase request failed with status %d: %s", resp.StatusCode, string(respBody))
+	if status < 200 || status >= 300 {
+		return nil, fmt.Errorf("firebase request failed with status %d: %s", status, string(respBody))
 	}
 
 	return respBody, nil
@@ -121,40 +84,61 @@ func (f *Client) makeRequest(ctx context.Context, method, path string, body inte
 
 // SaveTrade - Save trade to Firebase
 func (f *Client) SaveTrade(ctx context.Context, trade *models.Trade) error {
-	// Save to main trades collection
-	path := fmt.Sprintf("/trades/%s", trade.ID)
-	_, err := f.makeRequest(ctx, "PUT", path, trade)
-	if err != nil {
+	if err := f.writeTrade(ctx, trade); err != nil {
 		return fmt.Errorf("failed to save trade: %v", err)
 	}
 
-	// Also save under user's trades for easy querying
-	userTradePath := fmt.Sprintf("/users/%s/trades/%s", trade.UserID, trade.ID)
-	_, err = f.makeRequest(ctx, "PUT", userTradePath, trade)
-	if err != nil {
-		log.Printf("Warning: Failed to save trade under user: %v", err)
-	}
-
+	f.tradeSaved(ctx, trade)
 	return nil
 }
 
 // UpdateTrade - Update existing trade
 func (f *Client) UpdateTrade(ctx context.Context, trade *models.Trade) error {
-	// Update main trade
-	path := fmt.Sprintf("/trades/%s", trade.ID)
-	_, err := f.makeRequest(ctx, "PUT", path, trade)
-	if err != nil {
+	if err := f.writeTrade(ctx, trade); err != nil {
 		return fmt.Errorf("failed to update trade: %v", err)
 	}
 
-	// Also update under user's trades
-	userTradePath := fmt.Sprintf("/users/%s/trades/%s", trade.UserID, trade.ID)
-	_, err = f.makeRequest(ctx, "PUT", userTradePath, trade)
-	if err != nil {
-		log.Printf("Warning: Failed to update trade under user: %v", err)
+	f.tradeSaved(ctx, trade)
+	return nil
+}
+
+// writeTrade writes the main copy, the user's copy and the account's copy
+// (for easy querying) in one multi-location update, so all are written or
+// none is
+func (f *Client) writeTrade(ctx context.Context, trade *models.Trade) error {
+	_, err := f.makeRequest(ctx, "PATCH", "/", tradeLocations(trade.ID, trade.UserID, trade.Account, trade))
+	return err
+}
+
+// tradeLocations fans a value (a trade, or nil to delete) out to every copy
+// of a trade for a multi-location update. Trades of the main account and of
+// users' own keys have no account copy; other accounts (sub-accounts,
+// followers) each keep theirs under accounts/<name>/trades.
+func tradeLocations(tradeID, userID, account string, value interface{}) map[string]interface{} {
+	locations := map[string]interface{}{
+		fmt.Sprintf("trades/%s", tradeID): value,
 	}
+	if userID != "" {
+		locations[fmt.Sprintf("users/%s/trades/%s", userID, tradeID)] = value
+	}
+	if account != "" && !strings.HasPrefix(account, models.UserAccount("")) {
+		locations[fmt.Sprintf("accounts/%s/trades/%s", account, tradeID)] = value
+	}
+	return locations
+}
 
-	return nil
+// OnTradeSaved registers a listener told about every trade written by
+// SaveTrade or UpdateTrade. Register listeners before serving requests.
+func (f *Client) OnTradeSaved(listener func(ctx context.Context, trade *models.Trade)) {
+	f.tradeListeners = append(f.tradeListeners, listener)
+}
+
+// tradeSaved passes a snapshot of a written trade to the listeners
+func (f *Client) tradeSaved(ctx context.Context, trade *models.Trade) {
+	for _, listener := range f.tradeListeners {
+		snapshot := *trade
+		listener(ctx, &snapshot)
+	}
 }
 
 // GetTrade - Get single trade by ID
@@ -178,31 +162,89 @@ func (f *Client) GetTrade(ctx context.Context, tradeID string) (*models.Trade, e
 	return &trade, nil
 }
 
-// GetUserTrades - Get all trades for a user
+// GetUserTrades - Get all trades for a user. The user's copies are checked
+// against the main trades and repaired where they diverge (left by partial
+// writes from before trades were written atomically).
 func (f *Client) GetUserTrades(ctx context.Context, userID string) ([]*models.Trade, error) {
-	path := fmt.Sprintf("/users/%s/trades", userID)
-	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	userTrades, err := f.readTrades(ctx, fmt.Sprintf("/users/%s/trades", userID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user trades: %v", err)
 	}
 
+	// The main copies, via the userId index on /trades
+	mainTrades, err := f.readTrades(ctx, fmt.Sprintf("/trades?orderBy=\"userId\"&equalTo=\"%s\"", userID))
+	if err != nil {
+		f.repairWarning.Do(func() {
+			logging.Warn().Err(err).Msg("Cannot check user trades against /trades (add \".indexOn\": [\"userId\"] to the trades rules)")
+		})
+		return tradeList(userTrades), nil
+	}
+
+	f.repairUserTrades(ctx, userID, mainTrades, userTrades)
+	return tradeList(mainTrades), nil
+}
+
+// readTrades reads a trades node (or query) keyed by trade ID
+func (f *Client) readTrades(ctx context.Context, path string) (map[string]*models.Trade, error) {
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if response is null (no trades)
 	if string(respBody) == "null" || string(respBody) == "" {
-		return []*models.Trade{}, nil
+		return map[string]*models.Trade{}, nil
 	}
 
 	var tradesMap map[string]*models.Trade
 	if err := json.Unmarshal(respBody, &tradesMap); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal trades: %v", err)
 	}
+	return tradesMap, nil
+}
 
-	// Convert map to slice
+// repairUserTrades rewrites user copies that differ from the main trade and
+// removes copies whose main trade is gone (a delete that failed halfway)
+func (f *Client) repairUserTrades(ctx context.Context, userID string, mainTrades, userTrades map[string]*models.Trade) {
+	updates := map[string]interface{}{}
+	for id, trade := range mainTrades {
+		if !sameTrade(trade, userTrades[id]) {
+			updates[fmt.Sprintf("users/%s/trades/%s", userID, id)] = trade
+		}
+	}
+	for id := range userTrades {
+		if _, ok := mainTrades[id]; !ok {
+			updates[fmt.Sprintf("users/%s/trades/%s", userID, id)] = nil
+		}
+	}
+	if len(updates) == 0 {
+		return
+	}
+
+	if _, err := f.makeRequest(ctx, "PATCH", "/", updates); err != nil {
+		logging.Warn().Err(err).Msgf("Failed to repair %d trade copies of user %s", len(updates), userID)
+		return
+	}
+	logging.Info().Msgf("Repaired %d diverged trade copies of user %s", len(updates), userID)
+}
+
+// sameTrade reports whether two copies of a trade hold the same data
+func sameTrade(a, b *models.Trade) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
+
+// tradeList converts trades keyed by ID to a slice
+func tradeList(tradesMap map[string]*models.Trade) []*models.Trade {
 	trades := make([]*models.Trade, 0, len(tradesMap))
 	for _, trade := range tradesMap {
 		trades = append(trades, trade)
 	}
-
-	return trades, nil
+	return trades
 }
 
 // GetActiveTrades - Get all active trades for monitoring
@@ -237,20 +279,70 @@ func (f *Client) GetActiveTrades(ctx context.Context) ([]*models.Trade, error) {
 
 // DeleteTrade - Delete a trade
 func (f *Client) DeleteTrade(ctx context.Context, tradeID string, userID string) error {
-	// Delete from main trades
-	path := fmt.Sprintf("/trades/%s", tradeID)
-	_, err := f.makeRequest(ctx, "DELETE", path, nil)
+	// The account's copy is found through the trade
+	account := ""
+	if trade, err := f.GetTrade(ctx, tradeID); err == nil {
+		account = trade.Account
+	}
+
+	// Delete every copy in one multi-location update
+	_, err := f.makeRequest(ctx, "PATCH", "/", tradeLocations(tradeID, userID, account, nil))
 	if err != nil {
 		return fmt.Errorf("failed to delete trade: %v", err)
 	}
 
-	// Delete from user's trades
-	userTradePath := fmt.Sprintf("/users/%s/trades/%s", userID, tradeID)
-	_, err = f.makeRequest(ctx, "DELETE", userTradePath, nil)
+	return nil
+}
+
+// ArchiveTrades - Move trades from /trades (and their other copies) to
+// /archive/trades in one multi-location update
+func (f *Client) ArchiveTrades(ctx context.Context, trades []*models.Trade) error {
+	updates := make(map[string]interface{}, 4*len(trades))
+	for _, trade := range trades {
+		for location := range tradeLocations(trade.ID, trade.UserID, trade.Account, nil) {
+			updates[location] = nil
+		}
+		updates[fmt.Sprintf("archive/trades/%s", trade.ID)] = trade
+	}
+
+	if _, err := f.makeRequest(ctx, "PATCH", "/", updates); err != nil {
+		return fmt.Errorf("failed to archive trades: %v", err)
+	}
+	return nil
+}
+
+// GetArchivedTrade - Get a trade moved to /archive/trades
+func (f *Client) GetArchivedTrade(ctx context.Context, tradeID string) (*models.Trade, error) {
+	path := fmt.Sprintf("/archive/trades/%s", tradeID)
+	respBody, err := f.makeRequest(ctx, "GET", path, nil)
 	if err != nil {
-		log.Printf("Warning: Failed to delete trade from user: %v", err)
+		return nil, fmt.Errorf("failed to get archived trade: %v", err)
+	}
+
+	if string(respBody) == "null" || string(respBody) == "" {
+		return nil, fmt.Errorf("trade not found")
+	}
+
+	var trade models.Trade
+	if err := json.Unmarshal(respBody, &trade); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trade: %v", err)
 	}
 
+	return &trade, nil
+}
+
+// Ping - Check that Firebase is reachable and accepts writes, by writing
+// /health/probe directly (never queued)
+func (f *Client) Ping(ctx context.Context) error {
+	endpoint := fmt.Sprintf("%s/health/probe.json", f.databaseURL)
+	if err := f.rest.probe(ctx, "PUT", endpoint, map[string]int64{"time": time.Now().Unix()}); err != nil {
+		return fmt.Errorf("failed to write health probe: %v", err)
+	}
+	return nil
+}
+
+// Flush - Nothing to flush: writes go straight to Firebase
+func (f *Client) Flush(ctx context.Context) error {
 	return nil
 }
 
@@ -0,0 +1,1115 @@
+package firebase
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Firestore collections. Documents hold the same fields as the Realtime
+// Database nodes, so both backends store identical JSON.
+const (
+	firestoreTrades         = "trades"
+	firestoreArchive        = "archivedTrades"
+	firestoreUserStats      = "userStats"
+	firestoreSettings       = "settings"
+	firestoreRiskActions    = "riskActions"
+	firestoreReports        = "reports"
+	firestoreWebhooks       = "webhooks"
+	firestoreAlerts         = "priceAlerts"
+	firestorePerfAlerts     = "performanceAlerts"
+	firestoreTemplates      = "tradingviewTemplates"
+	firestorePresets        = "presets"
+	firestoreArbGroups      = "fundingArbGroups"
+	firestoreCredentials    = "credentials"
+	firestoreUserSettings   = "userSettings"
+	firestoreBalanceHistory = "balanceHistory"
+	firestoreAPIKeys        = "apikeys"
+	firestoreRoles          = "roles"
+	firestoreAuditEntries   = "audit"
+	firestoreIdempotency    = "idempotency"
+	firestoreHealth         = "health"
+)
+
+// firestorePageSize is how many documents a list request returns per page
+const firestorePageSize = 300
+
+// FirestoreClient stores trades and records in Cloud Firestore through its
+// REST API. Trade queries by status or user are ordered by creation time and
+// need the composite indexes in firestore.indexes.json.
+type FirestoreClient struct {
+	baseURL        string // .../v1/projects/<project>/databases/<database>/documents
+	documents      string // projects/<project>/databases/<database>/documents
+	rest           *restClient
+	tradeListeners []func(ctx context.Context, trade *models.Trade)
+}
+
+// NewFirestoreClient connects to a Firestore database. The project defaults
+// to the one of the service account in credentialsFile (or the default
+// credentials), and the database to "(default)". With emulatorHost set,
+// requests go to the local emulator unauthenticated.
+func NewFirestoreClient(projectID, databaseID, credentialsFile, emulatorHost string) (*FirestoreClient, error) {
+	if databaseID == "" {
+		databaseID = "(default)"
+	}
+
+	var tokens *tokenSource
+	if emulatorHost == "" {
+		var err error
+		tokens, err = newTokenSource(credentialsFile, firestoreScopes...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Firestore credentials: %v", err)
+		}
+		if _, err := tokens.token(); err != nil {
+			logging.Warn().Err(err).Msg("Could not get access token")
+		}
+		if projectID == "" {
+			projectID = tokens.projectID
+		}
+	}
+
+	if projectID == "" {
+		return nil, fmt.Errorf("FIRESTORE_PROJECT_ID must be set")
+	}
+
+	documents := fmt.Sprintf("projects/%s/databases/%s/documents", projectID, databaseID)
+	baseURL := "https://firestore.googleapis.com/v1/" + documents
+	if emulatorHost != "" {
+		baseURL = "http://" + emulatorHost + "/v1/" + documents
+	}
+
+	logging.Info().Str("project", projectID).Str("database", databaseID).Str("emulator", emulatorHost).
+		Msg("Firestore client initialized successfully")
+
+	return &FirestoreClient{
+		baseURL:   baseURL,
+		documents: documents,
+		rest:      newRestClient(tokens),
+	}, nil
+}
+
+// request calls the Firestore REST API. found is false for 404 responses.
+func (c *FirestoreClient) request(ctx context.Context, method, url string, body interface{}) (respBody []byte, found bool, err error) {
+	status, respBody, err := c.rest.do(ctx, method, url, body)
+	if err != nil {
+		return nil, false, err
+	}
+	if status == http.StatusNotFound {
+		return respBody, false, nil
+	}
+	if status < 200 || status >= 300 {
+		return nil, false, fmt.Errorf("firestore request failed with status %d: %s", status, string(respBody))
+	}
+	return respBody, true, nil
+}
+
+func (c *FirestoreClient) documentURL(collection, id string) string {
+	return c.baseURL + "/" + collection + "/" + url.PathEscape(id)
+}
+
+// documentName is a document's full resource name, as used in commits
+func (c *FirestoreClient) documentName(collection, id string) string {
+	return c.documents + "/" + collection + "/" + id
+}
+
+// SaveTrade - Save a trade
+func (c *FirestoreClient) SaveTrade(ctx context.Context, trade *models.Trade) error {
+	if err := c.putDocument(ctx, firestoreTrades, trade.ID, trade); err != nil {
+		return fmt.Errorf("failed to save trade: %v", err)
+	}
+	c.tradeSaved(ctx, trade)
+	return nil
+}
+
+// UpdateTrade - Update an existing trade
+func (c *FirestoreClient) UpdateTrade(ctx context.Context, trade *models.Trade) error {
+	if err := c.putDocument(ctx, firestoreTrades, trade.ID, trade); err != nil {
+		return fmt.Errorf("failed to update trade: %v", err)
+	}
+	c.tradeSaved(ctx, trade)
+	return nil
+}
+
+// OnTradeSaved registers a listener told about every trade written by
+// SaveTrade or UpdateTrade. Register listeners before serving requests.
+func (c *FirestoreClient) OnTradeSaved(listener func(ctx context.Context, trade *models.Trade)) {
+	c.tradeListeners = append(c.tradeListeners, listener)
+}
+
+// tradeSaved passes a snapshot of a written trade to the listeners
+func (c *FirestoreClient) tradeSaved(ctx context.Context, trade *models.Trade) {
+	for _, listener := range c.tradeListeners {
+		snapshot := *trade
+		listener(ctx, &snapshot)
+	}
+}
+
+// GetTrade - Get a single trade by ID
+func (c *FirestoreClient) GetTrade(ctx context.Context, tradeID string) (*models.Trade, error) {
+	var trade models.Trade
+	found, err := c.getDocument(ctx, firestoreTrades, tradeID, &trade)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade: %v", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("trade not found")
+	}
+	return &trade, nil
+}
+
+// GetUserTrades - Get all trades of a user, newest first
+func (c *FirestoreClient) GetUserTrades(ctx context.Context, userID string) ([]*models.Trade, error) {
+	trades, err := c.queryTrades(ctx, "userId", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user trades: %v", err)
+	}
+	return trades, nil
+}
+
+// GetActiveTrades - Get all active trades for monitoring
+func (c *FirestoreClient) GetActiveTrades(ctx context.Context) ([]*models.Trade, error) {
+	trades, err := c.queryTrades(ctx, "status", "ACTIVE")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trades: %v", err)
+	}
+	return trades, nil
+}
+
+// GetAllTrades - Get all trades
+func (c *FirestoreClient) GetAllTrades(ctx context.Context) ([]*models.Trade, error) {
+	trades, err := listDocuments[models.Trade](ctx, c, firestoreTrades)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all trades: %v", err)
+	}
+	return trades, nil
+}
+
+// GetTradesByStatus - Get trades filtered by status, newest first
+func (c *FirestoreClient) GetTradesByStatus(ctx context.Context, status string) ([]*models.Trade, error) {
+	trades, err := c.queryTrades(ctx, "status", status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trades by status: %v", err)
+	}
+	return trades, nil
+}
+
+// queryTrades reads the trades whose field equals value, newest first
+func (c *FirestoreClient) queryTrades(ctx context.Context, field, value string) ([]*models.Trade, error) {
+	docs, err := c.runQuery(ctx, map[string]interface{}{
+		"from":  []interface{}{map[string]interface{}{"collectionId": firestoreTrades}},
+		"where": fieldFilter(field, "EQUAL", stringValue(value)),
+		"orderBy": []interface{}{map[string]interface{}{
+			"field":     map[string]interface{}{"fieldPath": "createdAt"},
+			"direction": "DESCENDING",
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeTrades(docs)
+}
+
+// QueryTrades - Get one page of trades matching the filters, newest first.
+// Equality filters are merged with the composite indexes on createdAt.
+func (c *FirestoreClient) QueryTrades(ctx context.Context, query models.TradeQuery) (*models.TradePage, error) {
+	var filters []interface{}
+	for _, filter := range []struct{ field, value string }{
+		{"userId", query.UserID},
+		{"status", query.Status},
+		{"symbol", query.Symbol},
+	} {
+		if filter.value != "" {
+			filters = append(filters, fieldFilter(filter.field, "EQUAL", stringValue(filter.value)))
+		}
+	}
+	if query.From != 0 {
+		filters = append(filters, fieldFilter("createdAt", "GREATER_THAN_OR_EQUAL", integerValue(query.From)))
+	}
+	if query.To != 0 {
+		filters = append(filters, fieldFilter("createdAt", "LESS_THAN_OR_EQUAL", integerValue(query.To)))
+	}
+
+	structuredQuery := map[string]interface{}{
+		"from": []interface{}{map[string]interface{}{"collectionId": firestoreTrades}},
+		"orderBy": []interface{}{
+			map[string]interface{}{"field": map[string]interface{}{"fieldPath": "createdAt"}, "direction": "DESCENDING"},
+			map[string]interface{}{"field": map[string]interface{}{"fieldPath": "__name__"}, "direction": "DESCENDING"},
+		},
+		"limit": query.Limit + 1,
+	}
+	switch len(filters) {
+	case 0:
+	case 1:
+		structuredQuery["where"] = filters[0]
+	default:
+		structuredQuery["where"] = map[string]interface{}{
+			"compositeFilter": map[string]interface{}{"op": "AND", "filters": filters},
+		}
+	}
+	if query.After != nil {
+		// Start just after the previous page's last trade
+		structuredQuery["startAt"] = map[string]interface{}{
+			"values": []interface{}{
+				integerValue(query.After.CreatedAt),
+				map[string]interface{}{"referenceValue": c.documents + "/" + firestoreTrades + "/" + query.After.ID},
+			},
+			"before": false,
+		}
+	}
+
+	docs, err := c.runQuery(ctx, structuredQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trades: %v", err)
+	}
+	trades, err := decodeTrades(docs)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewTradePage(trades, query.Limit), nil
+}
+
+func decodeTrades(docs []*firestoreDocument) ([]*models.Trade, error) {
+	trades := make([]*models.Trade, 0, len(docs))
+	for _, doc := range docs {
+		var trade models.Trade
+		if err := decodeDocument(doc, &trade); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal trade: %v", err)
+		}
+		trades = append(trades, &trade)
+	}
+	return trades, nil
+}
+
+// DeleteTrade - Delete a trade
+func (c *FirestoreClient) DeleteTrade(ctx context.Context, tradeID string, userID string) error {
+	if err := c.deleteDocument(ctx, firestoreTrades, tradeID); err != nil {
+		return fmt.Errorf("failed to delete trade: %v", err)
+	}
+	return nil
+}
+
+// ArchiveTrades - Move trades to the archivedTrades collection, each with
+// its copy and delete committed together
+func (c *FirestoreClient) ArchiveTrades(ctx context.Context, trades []*models.Trade) error {
+	writes := make([]interface{}, 0, 2*len(trades))
+	for _, trade := range trades {
+		fields, err := encodeDocument(trade)
+		if err != nil {
+			return fmt.Errorf("failed to archive trades: %v", err)
+		}
+		writes = append(writes,
+			map[string]interface{}{"update": map[string]interface{}{
+				"name":   c.documentName(firestoreArchive, trade.ID),
+				"fields": fields,
+			}},
+			map[string]interface{}{"delete": c.documentName(firestoreTrades, trade.ID)},
+		)
+	}
+
+	if err := c.commit(ctx, writes); err != nil {
+		return fmt.Errorf("failed to archive trades: %v", err)
+	}
+	return nil
+}
+
+// GetArchivedTrade - Get a trade moved to the archivedTrades collection
+func (c *FirestoreClient) GetArchivedTrade(ctx context.Context, tradeID string) (*models.Trade, error) {
+	var trade models.Trade
+	found, err := c.getDocument(ctx, firestoreArchive, tradeID, &trade)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archived trade: %v", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("trade not found")
+	}
+	return &trade, nil
+}
+
+// UpdateTradePnL - Close a trade with its PnL
+func (c *FirestoreClient) UpdateTradePnL(ctx context.Context, tradeID string, pnl float64, userID string) error {
+	trade, err := c.GetTrade(ctx, tradeID)
+	if err != nil {
+		return err
+	}
+
+	trade.PnL = pnl
+	trade.Status = "CLOSED"
+	trade.ClosedAt = time.Now().Unix()
+
+	return c.UpdateTrade(ctx, trade)
+}
+
+// BatchUpdateTrades - Update multiple trades at once
+func (c *FirestoreClient) BatchUpdateTrades(ctx context.Context, trades []*models.Trade) error {
+	for _, trade := range trades {
+		if err := c.UpdateTrade(ctx, trade); err != nil {
+			logging.Error().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("Error updating trade %s", trade.ID)
+		}
+	}
+	return nil
+}
+
+// GetUserStats - Get user statistics
+func (c *FirestoreClient) GetUserStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+	var stats map[string]interface{}
+	found, err := c.getDocument(ctx, firestoreUserStats, userID, &stats)
+	if err != nil || !found {
+		return map[string]interface{}{
+			"totalTrades":  0,
+			"activeTrades": 0,
+			"totalPnL":     0.0,
+			"winRate":      0.0,
+		}, nil
+	}
+	return stats, nil
+}
+
+// UpdateUserStats - Update user statistics
+func (c *FirestoreClient) UpdateUserStats(ctx context.Context, userID string, stats map[string]interface{}) error {
+	if err := c.putDocument(ctx, firestoreUserStats, userID, stats); err != nil {
+		return fmt.Errorf("failed to update user stats: %v", err)
+	}
+	return nil
+}
+
+// CalculateUserStatistics - Calculate and update user statistics
+func (c *FirestoreClient) CalculateUserStatistics(ctx context.Context, userID string) error {
+	trades, err := c.GetUserTrades(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	activeTrades := 0
+	totalPnL := 0.0
+	winningTrades := 0
+	for _, trade := range trades {
+		if trade.Status == "ACTIVE" {
+			activeTrades++
+		}
+		totalPnL += trade.PnL
+		if trade.PnL > 0 {
+			winningTrades++
+		}
+	}
+
+	winRate := 0.0
+	if len(trades) > 0 {
+		winRate = float64(winningTrades) / float64(len(trades)) * 100
+	}
+
+	return c.UpdateUserStats(ctx, userID, map[string]interface{}{
+		"totalTrades":  len(trades),
+		"activeTrades": activeTrades,
+		"totalPnL":     totalPnL,
+		"winRate":      winRate,
+		"lastUpdate":   time.Now().Unix(),
+	})
+}
+
+// GetUserTradeStats - Get a user's running trade totals (nil if none)
+func (c *FirestoreClient) GetUserTradeStats(ctx context.Context, userID string) (*models.UserTradeStats, error) {
+	var stats models.UserTradeStats
+	found, err := c.getDocument(ctx, firestoreUserStats, userID, &stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user stats: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &stats, nil
+}
+
+// SaveUserTradeStats - Store a user's running trade totals
+func (c *FirestoreClient) SaveUserTradeStats(ctx context.Context, stats *models.UserTradeStats) error {
+	if err := c.putDocument(ctx, firestoreUserStats, stats.UserID, stats); err != nil {
+		return fmt.Errorf("failed to save user stats: %v", err)
+	}
+	return nil
+}
+
+// GetTradeContribution - Get what a trade added to its owner's stats (nil if
+// it was not counted)
+func (c *FirestoreClient) GetTradeContribution(ctx context.Context, userID, tradeID string) (*models.TradeContribution, error) {
+	var contribution models.TradeContribution
+	found, err := c.getDocument(ctx, statsTradesCollection(userID), tradeID, &contribution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade contribution: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &contribution, nil
+}
+
+// SaveTradeContribution - Store what a trade added to its owner's stats (the
+// user's stats trades subcollection)
+func (c *FirestoreClient) SaveTradeContribution(ctx context.Context, contribution *models.TradeContribution) error {
+	if err := c.putDocument(ctx, statsTradesCollection(contribution.UserID), contribution.TradeID, contribution); err != nil {
+		return fmt.Errorf("failed to save trade contribution: %v", err)
+	}
+	return nil
+}
+
+// GetTradeContributions - Get every trade counted in a user's stats
+func (c *FirestoreClient) GetTradeContributions(ctx context.Context, userID string) ([]*models.TradeContribution, error) {
+	contributions, err := listDocuments[models.TradeContribution](ctx, c, statsTradesCollection(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade contributions: %v", err)
+	}
+	return contributions, nil
+}
+
+func statsTradesCollection(userID string) string {
+	return firestoreUserStats + "/" + url.PathEscape(userID) + "/trades"
+}
+
+// SaveSystemStats - Save system-wide statistics
+func (c *FirestoreClient) SaveSystemStats(ctx context.Context, stats map[string]interface{}) error {
+	stats["lastUpdate"] = time.Now().Unix()
+	if err := c.putDocument(ctx, firestoreSettings, "systemStats", stats); err != nil {
+		return fmt.Errorf("failed to save system stats: %v", err)
+	}
+	return nil
+}
+
+// GetSystemStats - Get system statistics
+func (c *FirestoreClient) GetSystemStats(ctx context.Context) (map[string]interface{}, error) {
+	var stats map[string]interface{}
+	found, err := c.getDocument(ctx, firestoreSettings, "systemStats", &stats)
+	if err != nil || !found {
+		return map[string]interface{}{
+			"totalTrades":  0,
+			"activeTrades": 0,
+			"totalVolume":  0.0,
+			"lastUpdate":   time.Now().Unix(),
+		}, nil
+	}
+	return stats, nil
+}
+
+// SaveRiskAction - Journal an automated risk action
+func (c *FirestoreClient) SaveRiskAction(ctx context.Context, action *models.RiskAction) error {
+	if err := c.putDocument(ctx, firestoreRiskActions, action.ID, action); err != nil {
+		return fmt.Errorf("failed to save risk action: %v", err)
+	}
+	return nil
+}
+
+// GetSymbolPolicy - Get the persisted symbol allow/block lists (nil if never saved)
+func (c *FirestoreClient) GetSymbolPolicy(ctx context.Context) (*models.SymbolPolicyConfig, error) {
+	var cfg models.SymbolPolicyConfig
+	found, err := c.getDocument(ctx, firestoreSettings, "symbolPolicy", &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol policy: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &cfg, nil
+}
+
+// SaveSymbolPolicy - Persist the symbol allow/block lists
+func (c *FirestoreClient) SaveSymbolPolicy(ctx context.Context, cfg *models.SymbolPolicyConfig) error {
+	if err := c.putDocument(ctx, firestoreSettings, "symbolPolicy", cfg); err != nil {
+		return fmt.Errorf("failed to save symbol policy: %v", err)
+	}
+	return nil
+}
+
+// SaveReport - Store a summary report
+func (c *FirestoreClient) SaveReport(ctx context.Context, report *models.SummaryReport) error {
+	if err := c.putDocument(ctx, firestoreReports, report.ID, report); err != nil {
+		return fmt.Errorf("failed to save report: %v", err)
+	}
+	return nil
+}
+
+// GetReport - Get a summary report by ID (nil if it doesn't exist)
+func (c *FirestoreClient) GetReport(ctx context.Context, reportID string) (*models.SummaryReport, error) {
+	var report models.SummaryReport
+	found, err := c.getDocument(ctx, firestoreReports, reportID, &report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &report, nil
+}
+
+// GetReports - Get all stored summary reports
+func (c *FirestoreClient) GetReports(ctx context.Context) ([]*models.SummaryReport, error) {
+	reports, err := listDocuments[models.SummaryReport](ctx, c, firestoreReports)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports: %v", err)
+	}
+	return reports, nil
+}
+
+// SaveBalanceSnapshot - Store a daily balance snapshot
+func (c *FirestoreClient) SaveBalanceSnapshot(ctx context.Context, snapshot *models.BalanceSnapshot) error {
+	if err := c.putDocument(ctx, firestoreBalanceHistory, snapshot.Date, snapshot); err != nil {
+		return fmt.Errorf("failed to save balance snapshot: %v", err)
+	}
+	return nil
+}
+
+// GetBalanceSnapshots - Get all daily balance snapshots
+func (c *FirestoreClient) GetBalanceSnapshots(ctx context.Context) ([]*models.BalanceSnapshot, error) {
+	snapshots, err := listDocuments[models.BalanceSnapshot](ctx, c, firestoreBalanceHistory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance snapshots: %v", err)
+	}
+	return snapshots, nil
+}
+
+// SaveWebhook - Store a webhook registration
+func (c *FirestoreClient) SaveWebhook(ctx context.Context, hook *models.Webhook) error {
+	if err := c.putDocument(ctx, firestoreWebhooks, hook.ID, hook); err != nil {
+		return fmt.Errorf("failed to save webhook: %v", err)
+	}
+	return nil
+}
+
+// GetWebhooks - Get all webhook registrations
+func (c *FirestoreClient) GetWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	hooks, err := listDocuments[models.Webhook](ctx, c, firestoreWebhooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %v", err)
+	}
+	return hooks, nil
+}
+
+// GetWebhook - Get a webhook registration by ID
+func (c *FirestoreClient) GetWebhook(ctx context.Context, hookID string) (*models.Webhook, error) {
+	var hook models.Webhook
+	found, err := c.getDocument(ctx, firestoreWebhooks, hookID, &hook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %v", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	return &hook, nil
+}
+
+// DeleteWebhook - Remove a webhook registration
+func (c *FirestoreClient) DeleteWebhook(ctx context.Context, hookID string) error {
+	if err := c.deleteDocument(ctx, firestoreWebhooks, hookID); err != nil {
+		return fmt.Errorf("failed to delete webhook: %v", err)
+	}
+	return nil
+}
+
+// SavePriceAlert - Store a price alert
+func (c *FirestoreClient) SavePriceAlert(ctx context.Context, alert *models.PriceAlert) error {
+	if err := c.putDocument(ctx, firestoreAlerts, alert.ID, alert); err != nil {
+		return fmt.Errorf("failed to save price alert: %v", err)
+	}
+	return nil
+}
+
+// GetPriceAlerts - Get all price alerts
+func (c *FirestoreClient) GetPriceAlerts(ctx context.Context) ([]*models.PriceAlert, error) {
+	alerts, err := listDocuments[models.PriceAlert](ctx, c, firestoreAlerts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price alerts: %v", err)
+	}
+	return alerts, nil
+}
+
+// SavePerformanceAlert - Store a performance alert
+func (c *FirestoreClient) SavePerformanceAlert(ctx context.Context, alert *models.PerformanceAlert) error {
+	if err := c.putDocument(ctx, firestorePerfAlerts, alert.ID, alert); err != nil {
+		return fmt.Errorf("failed to save performance alert: %v", err)
+	}
+	return nil
+}
+
+// GetPerformanceAlerts - Get all performance alerts
+func (c *FirestoreClient) GetPerformanceAlerts(ctx context.Context) ([]*models.PerformanceAlert, error) {
+	alerts, err := listDocuments[models.PerformanceAlert](ctx, c, firestorePerfAlerts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get performance alerts: %v", err)
+	}
+	return alerts, nil
+}
+
+// SaveTradingViewTemplate - Store a TradingView alert template
+func (c *FirestoreClient) SaveTradingViewTemplate(ctx context.Context, tpl *models.TradingViewTemplate) error {
+	if err := c.putDocument(ctx, firestoreTemplates, tpl.Name, tpl); err != nil {
+		return fmt.Errorf("failed to save tradingview template: %v", err)
+	}
+	return nil
+}
+
+// GetTradingViewTemplate - Get a TradingView alert template by name (nil if it does not exist)
+func (c *FirestoreClient) GetTradingViewTemplate(ctx context.Context, name string) (*models.TradingViewTemplate, error) {
+	var tpl models.TradingViewTemplate
+	found, err := c.getDocument(ctx, firestoreTemplates, name, &tpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tradingview template: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &tpl, nil
+}
+
+// GetTradingViewTemplates - Get all TradingView alert templates
+func (c *FirestoreClient) GetTradingViewTemplates(ctx context.Context) ([]*models.TradingViewTemplate, error) {
+	templates, err := listDocuments[models.TradingViewTemplate](ctx, c, firestoreTemplates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tradingview templates: %v", err)
+	}
+	return templates, nil
+}
+
+// DeleteTradingViewTemplate - Remove a TradingView alert template
+func (c *FirestoreClient) DeleteTradingViewTemplate(ctx context.Context, name string) error {
+	if err := c.deleteDocument(ctx, firestoreTemplates, name); err != nil {
+		return fmt.Errorf("failed to delete tradingview template: %v", err)
+	}
+	return nil
+}
+
+// SaveStrategyPreset - Store a strategy preset
+func (c *FirestoreClient) SaveStrategyPreset(ctx context.Context, preset *models.StrategyPreset) error {
+	if err := c.putDocument(ctx, firestorePresets, preset.Name, preset); err != nil {
+		return fmt.Errorf("failed to save preset: %v", err)
+	}
+	return nil
+}
+
+// GetStrategyPreset - Get a strategy preset by name (nil if it does not exist)
+func (c *FirestoreClient) GetStrategyPreset(ctx context.Context, name string) (*models.StrategyPreset, error) {
+	var preset models.StrategyPreset
+	found, err := c.getDocument(ctx, firestorePresets, name, &preset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preset: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &preset, nil
+}
+
+// GetStrategyPresets - Get all strategy presets
+func (c *FirestoreClient) GetStrategyPresets(ctx context.Context) ([]*models.StrategyPreset, error) {
+	presets, err := listDocuments[models.StrategyPreset](ctx, c, firestorePresets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get presets: %v", err)
+	}
+	return presets, nil
+}
+
+// DeleteStrategyPreset - Remove a strategy preset
+func (c *FirestoreClient) DeleteStrategyPreset(ctx context.Context, name string) error {
+	if err := c.deleteDocument(ctx, firestorePresets, name); err != nil {
+		return fmt.Errorf("failed to delete preset: %v", err)
+	}
+	return nil
+}
+
+// SaveFundingArbGroup - Store a funding arbitrage group
+func (c *FirestoreClient) SaveFundingArbGroup(ctx context.Context, group *models.FundingArbGroup) error {
+	if err := c.putDocument(ctx, firestoreArbGroups, group.ID, group); err != nil {
+		return fmt.Errorf("failed to save funding arbitrage group: %v", err)
+	}
+	return nil
+}
+
+// GetFundingArbGroups - Get all funding arbitrage groups
+func (c *FirestoreClient) GetFundingArbGroups(ctx context.Context) ([]*models.FundingArbGroup, error) {
+	groups, err := listDocuments[models.FundingArbGroup](ctx, c, firestoreArbGroups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get funding arbitrage groups: %v", err)
+	}
+	return groups, nil
+}
+
+// SaveUserCredentials - Store a user's encrypted Binance credentials
+func (c *FirestoreClient) SaveUserCredentials(ctx context.Context, creds *models.UserCredentials) error {
+	if err := c.putDocument(ctx, firestoreCredentials, creds.UserID, creds); err != nil {
+		return fmt.Errorf("failed to save credentials: %v", err)
+	}
+	return nil
+}
+
+// GetUserCredentials - Get a user's encrypted Binance credentials (nil if none)
+func (c *FirestoreClient) GetUserCredentials(ctx context.Context, userID string) (*models.UserCredentials, error) {
+	var creds models.UserCredentials
+	found, err := c.getDocument(ctx, firestoreCredentials, userID, &creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &creds, nil
+}
+
+// DeleteUserCredentials - Remove a user's Binance credentials
+func (c *FirestoreClient) DeleteUserCredentials(ctx context.Context, userID string) error {
+	if err := c.deleteDocument(ctx, firestoreCredentials, userID); err != nil {
+		return fmt.Errorf("failed to delete credentials: %v", err)
+	}
+	return nil
+}
+
+// SaveUserSettings - Store a user's settings
+func (c *FirestoreClient) SaveUserSettings(ctx context.Context, settings *models.UserSettings) error {
+	if err := c.putDocument(ctx, firestoreUserSettings, settings.UserID, settings); err != nil {
+		return fmt.Errorf("failed to save user settings: %v", err)
+	}
+	return nil
+}
+
+// GetUserSettings - Get a user's settings (nil if none)
+func (c *FirestoreClient) GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error) {
+	var settings models.UserSettings
+	found, err := c.getDocument(ctx, firestoreUserSettings, userID, &settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &settings, nil
+}
+
+// DeleteUserSettings - Remove a user's settings
+func (c *FirestoreClient) DeleteUserSettings(ctx context.Context, userID string) error {
+	if err := c.deleteDocument(ctx, firestoreUserSettings, userID); err != nil {
+		return fmt.Errorf("failed to delete user settings: %v", err)
+	}
+	return nil
+}
+
+// SaveAPIKey - Store a managed API key record
+func (c *FirestoreClient) SaveAPIKey(ctx context.Context, key *models.APIKey) error {
+	if err := c.putDocument(ctx, firestoreAPIKeys, key.ID, key); err != nil {
+		return fmt.Errorf("failed to save api key: %v", err)
+	}
+	return nil
+}
+
+// GetAPIKey - Get a managed API key record (nil if not found)
+func (c *FirestoreClient) GetAPIKey(ctx context.Context, keyID string) (*models.APIKey, error) {
+	var key models.APIKey
+	found, err := c.getDocument(ctx, firestoreAPIKeys, keyID, &key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+// GetAPIKeys - Get all managed API key records
+func (c *FirestoreClient) GetAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	keys, err := listDocuments[models.APIKey](ctx, c, firestoreAPIKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api keys: %v", err)
+	}
+	return keys, nil
+}
+
+// SaveRoleAssignment - Store a user's role
+func (c *FirestoreClient) SaveRoleAssignment(ctx context.Context, assignment *models.RoleAssignment) error {
+	if err := c.putDocument(ctx, firestoreRoles, assignment.UserID, assignment); err != nil {
+		return fmt.Errorf("failed to save role: %v", err)
+	}
+	return nil
+}
+
+// GetRoleAssignment - Get a user's role (nil if none assigned)
+func (c *FirestoreClient) GetRoleAssignment(ctx context.Context, userID string) (*models.RoleAssignment, error) {
+	var assignment models.RoleAssignment
+	found, err := c.getDocument(ctx, firestoreRoles, userID, &assignment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &assignment, nil
+}
+
+// GetRoleAssignments - Get all role assignments
+func (c *FirestoreClient) GetRoleAssignments(ctx context.Context) ([]*models.RoleAssignment, error) {
+	roles, err := listDocuments[models.RoleAssignment](ctx, c, firestoreRoles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %v", err)
+	}
+	return roles, nil
+}
+
+// DeleteRoleAssignment - Remove a user's role (the default role applies again)
+func (c *FirestoreClient) DeleteRoleAssignment(ctx context.Context, userID string) error {
+	if err := c.deleteDocument(ctx, firestoreRoles, userID); err != nil {
+		return fmt.Errorf("failed to delete role: %v", err)
+	}
+	return nil
+}
+
+// SaveTradeEvent - Append an event to a trade's history (the trade's events
+// subcollection)
+func (c *FirestoreClient) SaveTradeEvent(ctx context.Context, event *models.TradeEvent) error {
+	if err := c.putDocument(ctx, tradeEventsCollection(event.TradeID), event.ID, event); err != nil {
+		return fmt.Errorf("failed to save trade event: %v", err)
+	}
+	return nil
+}
+
+// GetTradeEvents - Get a trade's history, oldest first
+func (c *FirestoreClient) GetTradeEvents(ctx context.Context, tradeID string) ([]*models.TradeEvent, error) {
+	events, err := listDocuments[models.TradeEvent](ctx, c, tradeEventsCollection(tradeID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade events: %v", err)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+func tradeEventsCollection(tradeID string) string {
+	return firestoreTrades + "/" + url.PathEscape(tradeID) + "/events"
+}
+
+// SaveAuditEntry - Append an audit log entry
+func (c *FirestoreClient) SaveAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	if err := c.putDocument(ctx, firestoreAuditEntries, entry.ID, entry); err != nil {
+		return fmt.Errorf("failed to save audit entry: %v", err)
+	}
+	return nil
+}
+
+// GetAuditEntries - Get up to limit audit entries between two entry IDs
+// (inclusive), newest last
+func (c *FirestoreClient) GetAuditEntries(ctx context.Context, startID, endID string, limit int) ([]*models.AuditEntry, error) {
+	idFilter := func(op, id string) map[string]interface{} {
+		reference := map[string]interface{}{"referenceValue": c.documents + "/" + firestoreAuditEntries + "/" + id}
+		return fieldFilter("__name__", op, reference)
+	}
+
+	// Read newest first so the limit keeps the latest
+	docs, err := c.runQuery(ctx, map[string]interface{}{
+		"from": []interface{}{map[string]interface{}{"collectionId": firestoreAuditEntries}},
+		"where": map[string]interface{}{
+			"compositeFilter": map[string]interface{}{
+				"op": "AND",
+				"filters": []interface{}{
+					idFilter("GREATER_THAN_OR_EQUAL", startID),
+					idFilter("LESS_THAN_OR_EQUAL", endID),
+				},
+			},
+		},
+		"orderBy": []interface{}{map[string]interface{}{
+			"field":     map[string]interface{}{"fieldPath": "__name__"},
+			"direction": "DESCENDING",
+		}},
+		"limit": limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %v", err)
+	}
+
+	// Return oldest first
+	entries := make([]*models.AuditEntry, len(docs))
+	for i, doc := range docs {
+		var entry models.AuditEntry
+		if err := decodeDocument(doc, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal audit entries: %v", err)
+		}
+		entries[len(docs)-1-i] = &entry
+	}
+	return entries, nil
+}
+
+// SaveIdempotencyRecord - Store the response to replay for an idempotency key
+func (c *FirestoreClient) SaveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if err := c.putDocument(ctx, firestoreIdempotency, record.Key, record); err != nil {
+		return fmt.Errorf("failed to save idempotency record: %v", err)
+	}
+	return nil
+}
+
+// GetIdempotencyRecord - Get the stored response for an idempotency key (nil
+// if none)
+func (c *FirestoreClient) GetIdempotencyRecord(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	var record models.IdempotencyRecord
+	found, err := c.getDocument(ctx, firestoreIdempotency, key, &record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// Ping - Check that Firestore is reachable and accepts writes, by writing
+// health/probe directly (never queued)
+func (c *FirestoreClient) Ping(ctx context.Context) error {
+	fields, err := encodeDocument(map[string]int64{"time": time.Now().Unix()})
+	if err != nil {
+		return err
+	}
+	if err := c.rest.probe(ctx, "PATCH", c.documentURL(firestoreHealth, "probe"), map[string]interface{}{"fields": fields}); err != nil {
+		return fmt.Errorf("failed to write health probe: %v", err)
+	}
+	return nil
+}
+
+// Flush - Nothing to flush: writes go straight to Firestore
+func (c *FirestoreClient) Flush(ctx context.Context) error {
+	return nil
+}
+
+// Close - Close the Firestore client
+func (c *FirestoreClient) Close() error {
+	// HTTP client doesn't require explicit closing
+	return nil
+}
+
+// firestoreMaxWrites is the most writes one commit may hold
+const firestoreMaxWrites = 500
+
+// commit applies writes atomically, in commits of up to 500 writes. Callers
+// keep writes that belong together within one commit.
+func (c *FirestoreClient) commit(ctx context.Context, writes []interface{}) error {
+	for len(writes) > 0 {
+		n := min(len(writes), firestoreMaxWrites)
+		if _, _, err := c.request(ctx, "POST", c.baseURL+":commit", map[string]interface{}{"writes": writes[:n]}); err != nil {
+			return err
+		}
+		writes = writes[n:]
+	}
+	return nil
+}
+
+// putDocument stores a document, replacing any previous version
+func (c *FirestoreClient) putDocument(ctx context.Context, collection, id string, value interface{}) error {
+	fields, err := encodeDocument(value)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.request(ctx, "PATCH", c.documentURL(collection, id), map[string]interface{}{"fields": fields})
+	return err
+}
+
+// getDocument reads a document into value, false if it does not exist
+func (c *FirestoreClient) getDocument(ctx context.Context, collection, id string, value interface{}) (bool, error) {
+	respBody, found, err := c.request(ctx, "GET", c.documentURL(collection, id), nil)
+	if err != nil || !found {
+		return false, err
+	}
+
+	var doc firestoreDocument
+	if err := json.Unmarshal(respBody, &doc); err != nil {
+		return false, fmt.Errorf("failed to unmarshal %s document: %v", collection, err)
+	}
+	if err := decodeDocument(&doc, value); err != nil {
+		return false, fmt.Errorf("failed to unmarshal %s document: %v", collection, err)
+	}
+	return true, nil
+}
+
+// deleteDocument removes a document (no error if it does not exist)
+func (c *FirestoreClient) deleteDocument(ctx context.Context, collection, id string) error {
+	_, _, err := c.request(ctx, "DELETE", c.documentURL(collection, id), nil)
+	return err
+}
+
+// listDocuments reads every document of a collection, a page at a time
+func listDocuments[T any](ctx context.Context, c *FirestoreClient, collection string) ([]*T, error) {
+	records := []*T{}
+	pageToken := ""
+	for {
+		query := url.Values{"pageSize": {fmt.Sprint(firestorePageSize)}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		respBody, found, err := c.request(ctx, "GET", c.baseURL+"/"+collection+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return records, nil
+		}
+
+		var page struct {
+			Documents     []*firestoreDocument `json:"documents"`
+			NextPageToken string               `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(respBody, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s documents: %v", collection, err)
+		}
+		for _, doc := range page.Documents {
+			record := new(T)
+			if err := decodeDocument(doc, record); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal %s document: %v", collection, err)
+			}
+			records = append(records, record)
+		}
+
+		if page.NextPageToken == "" {
+			return records, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// fieldFilter compares a field with a typed value in a structured query
+func fieldFilter(field, op string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{"fieldFilter": map[string]interface{}{
+		"field": map[string]interface{}{"fieldPath": field},
+		"op":    op,
+		"value": value,
+	}}
+}
+
+func stringValue(s string) map[string]interface{} {
+	return map[string]interface{}{"stringValue": s}
+}
+
+func integerValue(n int64) map[string]interface{} {
+	return map[string]interface{}{"integerValue": strconv.FormatInt(n, 10)}
+}
+
+// runQuery runs a structured query and returns the matching documents. A
+// query missing its composite index fails with a link to create it.
+func (c *FirestoreClient) runQuery(ctx context.Context, query map[string]interface{}) ([]*firestoreDocument, error) {
+	respBody, found, err := c.request(ctx, "POST", c.baseURL+":runQuery", map[string]interface{}{"structuredQuery": query})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("firestore database not found: %s", string(respBody))
+	}
+
+	var results []struct {
+		Document *firestoreDocument `json:"document"`
+	}
+	if err := json.Unmarshal(respBody, &results); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query results: %v", err)
+	}
+
+	docs := make([]*firestoreDocument, 0, len(results))
+	for _, result := range results {
+		// Results without a document only report progress
+		if result.Document != nil {
+			docs = append(docs, result.Document)
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,155 @@
+package firebase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Firestore's REST API wraps every field in a typed value, e.g.
+// {"status": {"stringValue": "ACTIVE"}}. Documents are converted through
+// their JSON encoding, so models keep their json tags as field names and
+// read back exactly as the Realtime Database returns them.
+
+// firestoreValue is one typed value: a single key naming the type
+type firestoreValue map[string]json.RawMessage
+
+// firestoreDocument is a document as returned by the REST API
+type firestoreDocument struct {
+	Name   string                    `json:"name,omitempty"`
+	Fields map[string]firestoreValue `json:"fields"`
+}
+
+// encodeDocument converts a value to document fields
+func encodeDocument(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	encoded := make(map[string]interface{}, len(fields))
+	for name, field := range fields {
+		encoded[name] = encodeValue(field)
+	}
+	return encoded, nil
+}
+
+// encodeValue converts a decoded JSON value to a typed value. Whole numbers
+// become integers so they can be compared and ordered as such.
+func encodeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return map[string]interface{}{"nullValue": nil}
+	case bool:
+		return map[string]interface{}{"booleanValue": v}
+	case json.Number:
+		if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return map[string]interface{}{"integerValue": string(v)}
+		}
+		return map[string]interface{}{"doubleValue": v}
+	case string:
+		return map[string]interface{}{"stringValue": v}
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, item := range v {
+			values[i] = encodeValue(item)
+		}
+		return map[string]interface{}{"arrayValue": map[string]interface{}{"values": values}}
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for name, field := range v {
+			fields[name] = encodeValue(field)
+		}
+		return map[string]interface{}{"mapValue": map[string]interface{}{"fields": fields}}
+	default:
+		return map[string]interface{}{"stringValue": fmt.Sprint(v)}
+	}
+}
+
+// decodeDocument reads document fields into value
+func decodeDocument(doc *firestoreDocument, value interface{}) error {
+	fields, err := decodeFields(doc.Fields)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+func decodeFields(fields map[string]firestoreValue) (map[string]interface{}, error) {
+	decoded := make(map[string]interface{}, len(fields))
+	for name, field := range fields {
+		value, err := decodeValue(field)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %v", name, err)
+		}
+		decoded[name] = value
+	}
+	return decoded, nil
+}
+
+// decodeValue converts a typed value back to its JSON form
+func decodeValue(value firestoreValue) (interface{}, error) {
+	for kind, raw := range value {
+		switch kind {
+		case "nullValue":
+			return nil, nil
+		case "integerValue":
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return nil, err
+			}
+			return json.Number(s), nil
+		case "doubleValue":
+			var n json.Number
+			if err := json.Unmarshal(raw, &n); err != nil {
+				return nil, err
+			}
+			return n, nil
+		case "arrayValue":
+			var array struct {
+				Values []firestoreValue `json:"values"`
+			}
+			if err := json.Unmarshal(raw, &array); err != nil {
+				return nil, err
+			}
+			values := make([]interface{}, len(array.Values))
+			for i, item := range array.Values {
+				v, err := decodeValue(item)
+				if err != nil {
+					return nil, err
+				}
+				values[i] = v
+			}
+			return values, nil
+		case "mapValue":
+			var m struct {
+				Fields map[string]firestoreValue `json:"fields"`
+			}
+			if err := json.Unmarshal(raw, &m); err != nil {
+				return nil, err
+			}
+			return decodeFields(m.Fields)
+		default:
+			// booleanValue, stringValue, timestampValue, referenceValue, ...
+			var v interface{}
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return nil, err
+			}
+			return v, nil
+		}
+	}
+	return nil, nil
+}
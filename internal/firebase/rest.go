@@ -0,0 +1,239 @@
+package firebase
+
+import (
+	"bytes"
+	"context"
+	"crypto-trading-api/internal/httpclient"
+	"crypto-trading-api/internal/latency"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/resilience"
+	"crypto-trading-api/internal/tracing"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestPolicy bounds Firebase REST calls, set from config at startup by
+// SetRequestPolicy
+type RequestPolicy struct {
+	Timeout         time.Duration // Per attempt
+	Retries         int           // Further attempts after a timeout, network error, 429 or 5xx
+	RetryBackoff    time.Duration // Wait before the first retry, doubled for each one after
+	BreakerFailures int           // Failed calls in a row that open the circuit
+	BreakerReset    time.Duration // How long an open circuit rejects calls before testing again
+	HTTP            httpclient.Config
+}
+
+var requestPolicy = RequestPolicy{
+	Timeout:         10 * time.Second,
+	Retries:         2,
+	RetryBackoff:    200 * time.Millisecond,
+	BreakerFailures: 5,
+	BreakerReset:    30 * time.Second,
+}
+
+// httpTransport is the connection pool the Realtime Database and Firestore
+// clients share, OAuth token requests included
+var httpTransport http.RoundTripper = httpclient.NewTransport(httpclient.Config{})
+
+// SetRequestPolicy sets the timeout, retry, circuit breaker and connection
+// pool settings of clients created afterwards. Zero fields keep the default;
+// Retries < 0 disables retries.
+func SetRequestPolicy(policy RequestPolicy) {
+	if policy.Timeout > 0 {
+		requestPolicy.Timeout = policy.Timeout
+	}
+	if policy.Retries > 0 {
+		requestPolicy.Retries = policy.Retries
+	} else if policy.Retries < 0 {
+		requestPolicy.Retries = 0
+	}
+	if policy.RetryBackoff > 0 {
+		requestPolicy.RetryBackoff = policy.RetryBackoff
+	}
+	if policy.BreakerFailures > 0 {
+		requestPolicy.BreakerFailures = policy.BreakerFailures
+	}
+	if policy.BreakerReset > 0 {
+		requestPolicy.BreakerReset = policy.BreakerReset
+	}
+	requestPolicy.HTTP = policy.HTTP
+	httpTransport = httpclient.NewTransport(policy.HTTP)
+}
+
+// restClient sends JSON requests to the Firebase REST APIs. Failed calls
+// are retried with backoff, and a circuit breaker fails calls fast while
+// Firebase keeps failing. Writes that still fail are returned to the
+// caller; trade writes are retried by the write-behind queue
+// (storage.WriteBehind), the one place writes are held for later.
+type restClient struct {
+	httpClient *http.Client
+	tokens     *tokenSource // nil for unauthenticated requests
+	policy     RequestPolicy
+	breaker    *resilience.CircuitBreaker
+}
+
+func newRestClient(tokens *tokenSource) *restClient {
+	return &restClient{
+		httpClient: &http.Client{Transport: tracing.Transport("firebase", latency.Transport("firebase", httpTransport, false))},
+		tokens:     tokens,
+		policy:     requestPolicy,
+		breaker:    resilience.NewCircuitBreaker(requestPolicy.BreakerFailures, requestPolicy.BreakerReset),
+	}
+}
+
+// errTransient marks failures worth retrying (and counting against the
+// circuit breaker)
+var errTransient = errors.New("firebase unavailable")
+
+// do sends a request and returns the response status and body, traced as
+// one span covering its retries
+func (r *restClient) do(ctx context.Context, method, url string, body interface{}) (int, []byte, error) {
+	ctx, span := tracing.Start(ctx, "firebase "+method, trace.WithAttributes(attribute.String("db.system", "firebase")))
+	status, respBody, err := r.request(ctx, method, url, body)
+	tracing.End(span, err)
+	return status, respBody, err
+}
+
+// request marshals the body and sends a request
+func (r *restClient) request(ctx context.Context, method, url string, body interface{}) (int, []byte, error) {
+	var jsonData []byte
+	if body != nil {
+		var err error
+		jsonData, err = json.Marshal(body)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to marshal request body: %v", err)
+		}
+	}
+	return r.call(ctx, method, url, jsonData)
+}
+
+// probe sends one attempt of a request, bypassing the retries and circuit
+// breaker, so health checks see Firebase as it is now
+func (r *restClient) probe(ctx context.Context, method, url string, body interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %v", err)
+	}
+
+	status, respBody, err := r.attempt(ctx, method, url, jsonData)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("status %d: %s", status, string(respBody))
+	}
+	return nil
+}
+
+// call sends a request through the circuit breaker. Only failures of
+// Firebase count against it, not rejected requests or cancelled callers.
+func (r *restClient) call(ctx context.Context, method, url string, jsonData []byte) (int, []byte, error) {
+	var status int
+	var respBody []byte
+	var callErr error
+	err := r.breaker.Execute(func() error {
+		status, respBody, callErr = r.retry(ctx, method, url, jsonData)
+		if errors.Is(callErr, errTransient) {
+			return callErr
+		}
+		return nil
+	})
+	if callErr == nil {
+		callErr = err
+	}
+	return status, respBody, callErr
+}
+
+// retry sends a request, retrying timeouts, network errors, 429 and 5xx
+// responses with backoff
+func (r *restClient) retry(ctx context.Context, method, url string, jsonData []byte) (int, []byte, error) {
+	backoff := r.policy.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		status, respBody, err := r.attempt(ctx, method, url, jsonData)
+		if err == nil && status != http.StatusTooManyRequests && status < 500 {
+			return status, respBody, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("status %d: %s", status, string(respBody))
+		}
+		if ctx.Err() != nil {
+			return 0, nil, err
+		}
+		if attempt >= r.policy.Retries {
+			return 0, nil, fmt.Errorf("%w: %v", errTransient, err)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return 0, nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// attempt sends a request once within the per-attempt timeout. A request
+// rejected with 401 is sent again with a freshly fetched access token.
+func (r *restClient) attempt(ctx context.Context, method, url string, jsonData []byte) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.policy.Timeout)
+	defer cancel()
+
+	status, respBody, err := r.send(ctx, method, url, jsonData)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if status == http.StatusUnauthorized && r.tokens != nil {
+		logging.Warn().Msg("Firebase rejected the access token, refreshing and retrying")
+		if err := r.tokens.refresh(); err != nil {
+			return 0, nil, fmt.Errorf("failed to refresh access token: %v", err)
+		}
+		return r.send(ctx, method, url, jsonData)
+	}
+
+	return status, respBody, nil
+}
+
+// send sends one request with the current access token
+func (r *restClient) send(ctx context.Context, method, url string, jsonData []byte) (int, []byte, error) {
+	var reqBody io.Reader
+	if jsonData != nil {
+		reqBody = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send the token as a header so it never ends up in logged URLs
+	if r.tokens != nil {
+		token, err := r.tokens.token()
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to get access token: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to execute request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	return resp.StatusCode, respBody, nil
+}
@@ -0,0 +1,90 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Config tunes the connection pool of an outbound client. Zero fields keep
+// the default.
+type Config struct {
+	MaxIdleConns          int           // Idle connections kept across all hosts
+	MaxIdleConnsPerHost   int           // Idle connections kept per host, the ones reused under load
+	MaxConnsPerHost       int           // Connections per host at once, 0 for no limit
+	IdleConnTimeout       time.Duration // How long an idle connection is kept
+	KeepAlive             time.Duration // TCP keep-alive probe interval, < 0 disables probes
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration // Wait for the response headers once the request is sent, 0 for none
+	TLSSessionCache       int           // TLS sessions kept for resumption, < 0 disables resumption
+}
+
+// Defaults keep more idle connections per host than net/http (2), so bursts
+// of calls to one API reuse warm connections instead of dialing new ones
+var Defaults = Config{
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 32,
+	IdleConnTimeout:     90 * time.Second,
+	KeepAlive:           30 * time.Second,
+	DialTimeout:         10 * time.Second,
+	TLSHandshakeTimeout: 10 * time.Second,
+	TLSSessionCache:     64,
+}
+
+// NewTransport creates a transport pooling connections as config sets. Share
+// one per API: each transport has its own pool.
+func NewTransport(config Config) *http.Transport {
+	config = config.withDefaults()
+
+	dialer := &net.Dialer{Timeout: config.DialTimeout, KeepAlive: config.KeepAlive}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       config.MaxConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+		ExpectContinueTimeout: time.Second,
+		TLSClientConfig:       &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+	if config.TLSSessionCache > 0 {
+		transport.TLSClientConfig.ClientSessionCache = tls.NewLRUClientSessionCache(config.TLSSessionCache)
+	}
+	return transport
+}
+
+func (c Config) withDefaults() Config {
+	if c.MaxIdleConns <= 0 {
+		c.MaxIdleConns = Defaults.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost <= 0 {
+		c.MaxIdleConnsPerHost = Defaults.MaxIdleConnsPerHost
+	}
+	if c.MaxConnsPerHost < 0 {
+		c.MaxConnsPerHost = 0
+	}
+	if c.IdleConnTimeout <= 0 {
+		c.IdleConnTimeout = Defaults.IdleConnTimeout
+	}
+	if c.KeepAlive == 0 {
+		c.KeepAlive = Defaults.KeepAlive
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = Defaults.DialTimeout
+	}
+	if c.TLSHandshakeTimeout <= 0 {
+		c.TLSHandshakeTimeout = Defaults.TLSHandshakeTimeout
+	}
+	if c.ResponseHeaderTimeout < 0 {
+		c.ResponseHeaderTimeout = 0
+	}
+	if c.TLSSessionCache == 0 {
+		c.TLSSessionCache = Defaults.TLSSessionCache
+	}
+	return c
+}
@@ -0,0 +1,286 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// firebaseJWKSURL publishes the keys that sign Firebase Auth ID tokens
+const firebaseJWKSURL = "https://www.googleapis.com/service_accounts/v1/jwk/securetoken@system.gserviceaccount.com"
+
+const (
+	jwksRefreshInterval = time.Hour
+	jwksMinRefetch      = time.Minute // Unknown key IDs trigger at most one refetch per minute
+)
+
+// Config describes which tokens are accepted
+type Config struct {
+	Issuer    string        // Required "iss" (empty = not checked)
+	Audience  string        // Required "aud" entry (empty = not checked)
+	JWKSURL   string        // RS256 signing keys
+	Secret    string        // HS256 shared secret
+	UserClaim string        // Claim holding the user ID (default "sub")
+	Leeway    time.Duration // Allowed clock skew for exp/nbf
+}
+
+// FirebaseConfig returns the settings for Firebase Auth ID tokens of a project
+func FirebaseConfig(projectID string) Config {
+	return Config{
+		Issuer:   "https://securetoken.google.com/" + projectID,
+		Audience: projectID,
+		JWKSURL:  firebaseJWKSURL,
+	}
+}
+
+// Claims holds a verified token's payload
+type Claims map[string]interface{}
+
+// String returns a string claim ("" when missing or not a string)
+func (c Claims) String(name string) string {
+	value, _ := c[name].(string)
+	return value
+}
+
+// Verifier validates JWT bearer tokens signed with RS256 (JWKS) or HS256
+type Verifier struct {
+	config     Config
+	httpClient *http.Client
+	keys       map[string]*rsa.PublicKey
+	fetchedAt  time.Time
+	mu         sync.Mutex
+}
+
+// NewVerifier creates a token verifier
+func NewVerifier(config Config) *Verifier {
+	if config.UserClaim == "" {
+		config.UserClaim = "sub"
+	}
+	if config.Leeway == 0 {
+		config.Leeway = time.Minute
+	}
+
+	return &Verifier{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		keys:       make(map[string]*rsa.PublicKey),
+	}
+}
+
+// Enabled reports whether JWT authentication is configured
+func (v *Verifier) Enabled() bool {
+	return v != nil && (v.config.JWKSURL != "" || v.config.Secret != "")
+}
+
+// LooksLikeJWT distinguishes tokens (header.payload.signature) from API keys
+func LooksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// Verify checks a token's signature and registered claims and returns its
+// claims together with the user ID
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, "", fmt.Errorf("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, "", fmt.Errorf("invalid token header: %v", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid token signature encoding")
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	switch header.Alg {
+	case "RS256":
+		if v.config.JWKSURL == "" {
+			return nil, "", fmt.Errorf("RS256 tokens are not accepted")
+		}
+		key, err := v.publicKey(ctx, header.Kid)
+		if err != nil {
+			return nil, "", err
+		}
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, "", fmt.Errorf("invalid token signature")
+		}
+	case "HS256":
+		if v.config.Secret == "" {
+			return nil, "", fmt.Errorf("HS256 tokens are not accepted")
+		}
+		mac := hmac.New(sha256.New, []byte(v.config.Secret))
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return nil, "", fmt.Errorf("invalid token signature")
+		}
+	default:
+		return nil, "", fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, "", fmt.Errorf("invalid token payload: %v", err)
+	}
+
+	if err := v.checkClaims(claims); err != nil {
+		return nil, "", err
+	}
+
+	userID := claims.String(v.config.UserClaim)
+	if userID == "" {
+		return nil, "", fmt.Errorf("token has no %q claim", v.config.UserClaim)
+	}
+
+	return claims, userID, nil
+}
+
+// checkClaims validates expiry, not-before, issuer and audience
+func (v *Verifier) checkClaims(claims Claims) error {
+	now := time.Now()
+	leeway := v.config.Leeway
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("token has no expiry")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(leeway)) {
+		return fmt.Errorf("token has expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(leeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("token is not valid yet")
+	}
+
+	if v.config.Issuer != "" && claims.String("iss") != v.config.Issuer {
+		return fmt.Errorf("unexpected token issuer %q", claims.String("iss"))
+	}
+
+	if v.config.Audience != "" {
+		matched := false
+		switch aud := claims["aud"].(type) {
+		case string:
+			matched = aud == v.config.Audience
+		case []interface{}:
+			for _, entry := range aud {
+				if entry == v.config.Audience {
+					matched = true
+					break
+				}
+			}
+		}
+		if !matched {
+			return fmt.Errorf("token audience does not include %q", v.config.Audience)
+		}
+	}
+
+	return nil
+}
+
+// publicKey returns the JWKS key for a key ID, refetching the set when it is
+// stale or does not contain the ID
+func (v *Verifier) publicKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key, ok := v.keys[kid]
+	stale := time.Since(v.fetchedAt) > jwksRefreshInterval
+	if ok && !stale {
+		return key, nil
+	}
+
+	if stale || time.Since(v.fetchedAt) > jwksMinRefetch {
+		keys, err := v.fetchKeys(ctx)
+		if err != nil {
+			// Keep serving known keys if the JWKS endpoint is briefly unavailable
+			if ok {
+				return key, nil
+			}
+			return nil, err
+		}
+		v.keys = keys
+		v.fetchedAt = time.Now()
+	}
+
+	key, ok = v.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown token signing key %q", kid)
+	}
+	return key, nil
+}
+
+// fetchKeys downloads the RSA keys of the JWKS endpoint
+func (v *Verifier) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", v.config.JWKSURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %v", err)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %v", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS contains no RSA keys")
+	}
+	return keys, nil
+}
+
+// decodeSegment decodes a base64url JSON token segment
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,172 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// encodeSegment encodes a JSON token segment
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signHS256 builds an HS256 token for claims
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	t.Helper()
+	signed := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signRS256 builds an RS256 token for claims signed by key under kid
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	signed := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// tamper swaps a token's payload, keeping its signature
+func tamper(token, payload string) string {
+	parts := strings.Split(token, ".")
+	return parts[0] + "." + payload + "." + parts[2]
+}
+
+func TestVerifyHS256(t *testing.T) {
+	const secret = "jwt-secret"
+	now := time.Now()
+	claims := func(extra map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{"sub": "u1", "iss": "trading", "aud": "api", "exp": now.Add(time.Hour).Unix()}
+		for name, value := range extra {
+			if value == nil {
+				delete(c, name)
+				continue
+			}
+			c[name] = value
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		config   Config
+		token    string
+		wantUser string
+		wantErr  string // Substring of the error ("" = accepted)
+	}{
+		{name: "valid", token: signHS256(t, secret, claims(nil)), wantUser: "u1"},
+		{name: "audience list", token: signHS256(t, secret, claims(map[string]interface{}{"aud": []string{"other", "api"}})), wantUser: "u1"},
+		{name: "custom user claim", config: Config{UserClaim: "user_id"}, token: signHS256(t, secret, claims(map[string]interface{}{"user_id": "u2"})), wantUser: "u2"},
+		{name: "expired within leeway", token: signHS256(t, secret, claims(map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()})), wantUser: "u1"},
+		{name: "expired", token: signHS256(t, secret, claims(map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()})), wantErr: "expired"},
+		{name: "no expiry", token: signHS256(t, secret, claims(map[string]interface{}{"exp": nil})), wantErr: "no expiry"},
+		{name: "not valid yet", token: signHS256(t, secret, claims(map[string]interface{}{"nbf": now.Add(time.Hour).Unix()})), wantErr: "not valid yet"},
+		{name: "other issuer", token: signHS256(t, secret, claims(map[string]interface{}{"iss": "someone"})), wantErr: "issuer"},
+		{name: "other audience", token: signHS256(t, secret, claims(map[string]interface{}{"aud": "web"})), wantErr: "audience"},
+		{name: "no user", token: signHS256(t, secret, claims(map[string]interface{}{"sub": nil})), wantErr: `no "sub" claim`},
+		{name: "other secret", token: signHS256(t, "other", claims(nil)), wantErr: "invalid token signature"},
+		{name: "tampered payload", token: tamper(signHS256(t, secret, claims(nil)), encodeSegment(t, claims(map[string]interface{}{"sub": "admin"}))), wantErr: "invalid token signature"},
+		{name: "unsigned", token: encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, claims(nil)) + ".", wantErr: "unsupported token algorithm"},
+		{name: "malformed", token: "not-a-token", wantErr: "malformed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Secret = secret
+			config.Issuer = "trading"
+			config.Audience = "api"
+
+			_, userID, err := NewVerifier(config).Verify(context.Background(), tt.token)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("Verify: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("Verify = %v, want error containing %q", err, tt.wantErr)
+			}
+			if userID != tt.wantUser {
+				t.Errorf("user = %q, want %q", userID, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestVerifyRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetches := 0
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "k1",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer jwks.Close()
+
+	claims := map[string]interface{}{"sub": "u1", "exp": time.Now().Add(time.Hour).Unix()}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{name: "valid", token: signRS256(t, key, "k1", claims)},
+		{name: "cached key", token: signRS256(t, key, "k1", claims)},
+		{name: "other key", token: signRS256(t, other, "k1", claims), wantErr: "invalid token signature"},
+		{name: "unknown key ID", token: signRS256(t, key, "k2", claims), wantErr: "unknown token signing key"},
+		{name: "HS256 not accepted", token: signHS256(t, "secret", claims), wantErr: "HS256 tokens are not accepted"},
+	}
+
+	// Cases run in order on one verifier: the keys are fetched once
+	verifier := NewVerifier(Config{JWKSURL: jwks.URL})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, userID, err := verifier.Verify(context.Background(), tt.token)
+			switch {
+			case tt.wantErr == "" && (err != nil || userID != "u1"):
+				t.Errorf("Verify = %q, %v, want u1", userID, err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Verify = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// An unknown key ID refetches at most once a minute
+	if fetches != 1 {
+		t.Errorf("JWKS fetched %d times, want 1", fetches)
+	}
+}
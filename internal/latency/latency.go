@@ -0,0 +1,121 @@
+package latency
+
+import (
+	"crypto-trading-api/internal/models"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxSamples is how many recent calls are kept per dependency or endpoint
+const maxSamples = 1000
+
+// Summarize computes the percentiles of latencies in milliseconds
+func Summarize(samples []float64) models.LatencyStats {
+	if len(samples) == 0 {
+		return models.LatencyStats{}
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	return models.LatencyStats{
+		Count: len(sorted),
+		P50:   percentile(sorted, 50),
+		P90:   percentile(sorted, 90),
+		P95:   percentile(sorted, 95),
+		P99:   percentile(sorted, 99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the p-th percentile of sorted values (nearest rank)
+func percentile(sorted []float64, p float64) float64 {
+	idx := int(p/100*float64(len(sorted)-1) + 0.5)
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// series holds the latest samples of one dependency or endpoint, oldest
+// overwritten first
+type series struct {
+	samples []float64
+	next    int
+}
+
+func (s *series) add(ms float64) {
+	if len(s.samples) < maxSamples {
+		s.samples = append(s.samples, ms)
+		return
+	}
+	s.samples[s.next] = ms
+	s.next = (s.next + 1) % maxSamples
+}
+
+// recorder keeps the calls of this instance, by dependency ("binance") and
+// endpoint ("binance POST /fapi/v1/order")
+var recorder = struct {
+	mu     sync.Mutex
+	series map[string]*series
+	since  time.Time
+}{series: make(map[string]*series), since: time.Now()}
+
+// Observe records the duration of a call to name
+func Observe(name string, elapsed time.Duration) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	s, ok := recorder.series[name]
+	if !ok {
+		s = &series{}
+		recorder.series[name] = s
+	}
+	s.add(float64(elapsed.Microseconds()) / 1000)
+}
+
+// Report returns the percentiles of the recent calls of every dependency
+// and endpoint, and when recording started
+func Report() (map[string]models.LatencyStats, time.Time) {
+	recorder.mu.Lock()
+	samples := make(map[string][]float64, len(recorder.series))
+	for name, s := range recorder.series {
+		samples[name] = append([]float64(nil), s.samples...)
+	}
+	since := recorder.since
+	recorder.mu.Unlock()
+
+	report := make(map[string]models.LatencyStats, len(samples))
+	for name, values := range samples {
+		report[name] = Summarize(values)
+	}
+	return report, since
+}
+
+// Transport times every request sent through base under dependency, and
+// under its method and path too when byEndpoint is set (paths without IDs
+// only, e.g. Binance's). Each attempt of a retried call counts once.
+func Transport(dependency string, base http.RoundTripper, byEndpoint bool) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{dependency: dependency, base: base, byEndpoint: byEndpoint}
+}
+
+type transport struct {
+	dependency string
+	base       http.RoundTripper
+	byEndpoint bool
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	Observe(t.dependency, elapsed)
+	if t.byEndpoint {
+		Observe(t.dependency+" "+req.Method+" "+req.URL.Path, elapsed)
+	}
+	return resp, err
+}
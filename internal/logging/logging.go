@@ -0,0 +1,143 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	stdlog "log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Field names shared by every log line that concerns a request or trade
+const (
+	FieldRequestID = "request_id"
+	FieldTraceID   = "trace_id"
+	FieldUserID    = "user_id"
+	FieldTradeID   = "trade_id"
+	FieldSymbol    = "symbol"
+)
+
+// Config configures the process logger
+type Config struct {
+	Level  string // debug, info, warn or error
+	Format string // json (one object per line) or console (human-readable)
+}
+
+// logger is the process logger; JSON at info level until Init runs
+var logger = newLogger(os.Stdout, "json")
+
+func init() {
+	zerolog.TimeFieldFormat = time.RFC3339Nano
+	zerolog.MessageFieldName = "msg"
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+}
+
+// Init replaces the process logger and routes the standard library logger
+// (net/http, third-party packages) through it. Every line is redacted
+// before it is written.
+func Init(config Config) error {
+	level, err := parseLevel(config.Level)
+	if err != nil {
+		return err
+	}
+
+	format := strings.ToLower(config.Format)
+	if format != "" && format != "json" && format != "console" {
+		return fmt.Errorf("invalid log format %q (expected json or console)", config.Format)
+	}
+
+	logger = newLogger(os.Stdout, format)
+	zerolog.SetGlobalLevel(level)
+	stdlog.SetFlags(0)
+	stdlog.SetOutput(stdWriter{})
+	return nil
+}
+
+// SetLevel changes the level of the process logger at runtime
+func SetLevel(level string) error {
+	parsed, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	zerolog.SetGlobalLevel(parsed)
+	return nil
+}
+
+// parseLevel parses a level name; empty means info
+func parseLevel(level string) (zerolog.Level, error) {
+	if level == "" {
+		return zerolog.InfoLevel, nil
+	}
+	parsed, err := zerolog.ParseLevel(strings.ToLower(level))
+	if err != nil || parsed == zerolog.NoLevel {
+		return zerolog.NoLevel, fmt.Errorf("invalid log level %q (expected debug, info, warn or error)", level)
+	}
+	return parsed, nil
+}
+
+// newLogger builds a logger for format; the global level filters its entries
+// so SetLevel also applies to loggers already derived from it (see With)
+func newLogger(out io.Writer, format string) zerolog.Logger {
+	out = redactingWriter{out: out}
+	if format == "console" {
+		out = zerolog.ConsoleWriter{Out: out, TimeFormat: time.DateTime}
+	}
+	return zerolog.New(out).With().Timestamp().Logger()
+}
+
+// Debug starts a debug-level entry
+func Debug() *zerolog.Event { return logger.Debug() }
+
+// Info starts an info-level entry
+func Info() *zerolog.Event { return logger.Info() }
+
+// Warn starts a warn-level entry
+func Warn() *zerolog.Event { return logger.Warn() }
+
+// Error starts an error-level entry
+func Error() *zerolog.Event { return logger.Error() }
+
+// Fatal starts an entry that exits the process once sent
+func Fatal() *zerolog.Event { return logger.Fatal() }
+
+type contextKey struct{}
+
+// Ctx returns the logger carried by ctx (see With), or the process logger
+func Ctx(ctx context.Context) *zerolog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*zerolog.Logger); ok {
+			return l
+		}
+	}
+	return &logger
+}
+
+// With returns ctx carrying a logger that adds the given key/value pairs
+// (e.g. FieldTradeID, trade.ID) to every entry. Empty values are skipped.
+func With(ctx context.Context, keyValues ...string) context.Context {
+	fields := Ctx(ctx).With()
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		if keyValues[i+1] != "" {
+			fields = fields.Str(keyValues[i], keyValues[i+1])
+		}
+	}
+	l := fields.Logger()
+	return context.WithValue(ctx, contextKey{}, &l)
+}
+
+// WithTrade tags the logger in ctx with a trade's ID and symbol
+func WithTrade(ctx context.Context, tradeID, symbol string) context.Context {
+	return With(ctx, FieldTradeID, tradeID, FieldSymbol, symbol)
+}
+
+// stdWriter turns standard library log output into info entries
+type stdWriter struct{}
+
+func (stdWriter) Write(p []byte) (int, error) {
+	logger.Info().Str("source", "stdlib").Msg(strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redacted replaces secrets in log output
+const redacted = "[REDACTED]"
+
+// secretPatterns match credentials by shape or by the name they are logged
+// under; the name is kept
+var secretPatterns = []struct {
+	pattern *regexp.Regexp
+	replace string
+}{
+	// signature=..., "apiKey":"...", X-API-Key: ..., Authorization: Bearer ...
+	{regexp.MustCompile(`(?i)((?:signature|api[_-]?key|secret[_-]?key|secret|password|passwd|listen[_-]?key|x-mbx-apikey|authorization)\\?"?\s*[:=]\s*\\?"?)(?:bearer\s+)?[^\s"'\\&,;}]+`), "${1}" + redacted},
+	// token=... in URLs and form bodies
+	{regexp.MustCompile(`(?i)((?:access_token|refresh_token|token)=)[^\s"'\\&,;}]+`), "${1}" + redacted},
+	// Telegram bot tokens in API URLs
+	{regexp.MustCompile(`bot\d+:[A-Za-z0-9_-]+`), "bot" + redacted},
+	// JWTs
+	{regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), redacted},
+}
+
+var (
+	secretsMu sync.RWMutex
+	secrets   []string
+)
+
+// AddSecrets registers values (API keys, tokens, passwords) that must never
+// appear in log output. Values shorter than 8 characters are ignored, since
+// masking them would mangle unrelated text.
+func AddSecrets(values ...string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+
+	for _, value := range values {
+		if len(value) >= 8 {
+			secrets = append(secrets, value)
+		}
+	}
+}
+
+// Redact masks registered secrets and credential-shaped values in s
+func Redact(s string) string {
+	secretsMu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	secretsMu.RUnlock()
+
+	for _, p := range secretPatterns {
+		s = p.pattern.ReplaceAllString(s, p.replace)
+	}
+	return s
+}
+
+// redactingWriter redacts every line before writing it to out
+type redactingWriter struct {
+	out io.Writer
+}
+
+func (w redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.out, Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,34 @@
+package models
+
+// Price alert conditions
+const (
+	AlertConditionAbove = "ABOVE" // Triggers when the mark price rises to or above the target
+	AlertConditionBelow = "BELOW" // Triggers when the mark price falls to or below the target
+)
+
+// PriceAlert represents a one-shot alert on a symbol's mark price
+type PriceAlert struct {
+	ID            string        `json:"id" example:"0b9f6c1e-7d2a-4f4e-8a1b-3c5d7e9f1a2b"`
+	UserID        string        `json:"userId" example:"user123"`
+	Symbol        string        `json:"symbol" example:"BTCUSDT"`
+	Condition     string        `json:"condition" example:"ABOVE"` // ABOVE or BELOW
+	Price         float64       `json:"price" example:"52000"`
+	Note          string        `json:"note,omitempty" example:"Breakout above range high"`
+	TradeTemplate *TradeRequest `json:"tradeTemplate,omitempty"` // Optional: trade submitted when the alert fires
+	Status        string        `json:"status" example:"ACTIVE"` // ACTIVE, TRIGGERED, CANCELED
+	CreatedAt     int64         `json:"createdAt" example:"1640995200"`
+	TriggeredAt   int64         `json:"triggeredAt,omitempty" example:"1641000000"`
+	TriggerPrice  float64       `json:"triggerPrice,omitempty" example:"52010.5"`
+	TradeID       string        `json:"tradeId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // Trade created from the template
+	TradeError    string        `json:"tradeError,omitempty" example:""`
+}
+
+// PriceAlertRequest represents a price alert registration
+type PriceAlertRequest struct {
+	UserID        string        `json:"userId" binding:"required" example:"user123"`
+	Symbol        string        `json:"symbol" binding:"required" example:"BTCUSDT"`
+	Condition     string        `json:"condition" binding:"required" example:"ABOVE"`
+	Price         float64       `json:"price" binding:"required,gt=0" example:"52000"`
+	Note          string        `json:"note,omitempty" example:"Breakout above range high"`
+	TradeTemplate *TradeRequest `json:"tradeTemplate,omitempty"`
+}
@@ -0,0 +1,41 @@
+package models
+
+// API key scopes
+const (
+	ScopeReadOnly = "read-only" // GET endpoints only
+	ScopeTrade    = "trade"     // Read plus placing, closing and managing trades
+	ScopeAdmin    = "admin"     // Everything, including /api/admin endpoints
+)
+
+// APIKey represents a managed API key. Only the SHA-256 hash of the key is stored.
+type APIKey struct {
+	ID        string   `json:"id" example:"3f9a1c0e7b2d4a65"`
+	Name      string   `json:"name" example:"tradingview-alerts"`
+	Prefix    string   `json:"prefix" example:"tk_3f9a1c0e7b2d4a65"` // Identifies the key in logs and listings
+	KeyHash   string   `json:"keyHash,omitempty"`                    // Hex SHA-256 of the full key, never returned by the API
+	Scopes    []string `json:"scopes" example:"read-only,trade"`
+	RateLimit int      `json:"rateLimit,omitempty" example:"60"` // Requests per minute (0 = RATE_LIMIT_PER_KEY)
+	ExpiresAt int64    `json:"expiresAt,omitempty" example:"1672531200"`
+	CreatedAt int64    `json:"createdAt" example:"1640995200"`
+	RevokedAt int64    `json:"revokedAt,omitempty" example:"1641995200"`
+	RotatedTo string   `json:"rotatedTo,omitempty" example:"8c4e2b1f9a7d3e50"` // Replacement key ID after rotation
+}
+
+// APIKeyRequest represents a request to issue an API key
+type APIKeyRequest struct {
+	Name      string   `json:"name" binding:"required" example:"tradingview-alerts"`
+	Scopes    []string `json:"scopes" binding:"required,min=1" example:"trade"`
+	ExpiresIn string   `json:"expiresIn,omitempty" example:"720h"` // Go duration; never expires when omitted
+	RateLimit int      `json:"rateLimit,omitempty" binding:"omitempty,min=1" example:"60"`
+}
+
+// APIKeyRotateRequest represents a request to rotate an API key
+type APIKeyRotateRequest struct {
+	GracePeriod string `json:"gracePeriod,omitempty" example:"1h"` // Old key keeps working this long (default: revoked immediately)
+}
+
+// IssuedAPIKey is returned once when a key is issued or rotated
+type IssuedAPIKey struct {
+	Key    string  `json:"key" example:"tk_3f9a1c0e7b2d4a65_9b0c..."` // Plaintext key, shown only once
+	APIKey *APIKey `json:"apiKey"`
+}
@@ -0,0 +1,22 @@
+package models
+
+// AuditEntry records one state-changing API call
+type AuditEntry struct {
+	ID          string `json:"id" example:"1700000000123456789-6f1c2a9e"` // Sorts chronologically
+	Timestamp   int64  `json:"timestamp" example:"1700000000"`
+	Actor       string `json:"actor" example:"key:3f9a1c0e7b2d4a65"` // key:<id>, user:<id>, signature or anonymous
+	Method      string `json:"method" example:"POST"`
+	Route       string `json:"route" example:"/api/trade"`
+	URL         string `json:"url" example:"/api/trade?template=tv-default"`
+	ClientIP    string `json:"clientIp" example:"52.89.214.238"`
+	UserAgent   string `json:"userAgent,omitempty" example:"Go-http-client/1.1"`
+	PayloadHash string `json:"payloadHash,omitempty" example:"9f86d081884c7d65..."` // Hex SHA-256 of the request body
+	PayloadSize int    `json:"payloadSize" example:"214"`
+	Status      int    `json:"status" example:"200"`
+	Success     bool   `json:"success" example:"true"`
+	Message     string `json:"message,omitempty" example:"Trade executed successfully"`
+	Error       string `json:"error,omitempty" example:""`
+	TradeID     string `json:"tradeId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrderID     int64  `json:"orderId,omitempty" example:"123456789"`
+	DurationMs  int64  `json:"durationMs" example:"412"`
+}
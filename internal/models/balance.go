@@ -0,0 +1,38 @@
+package models
+
+// BalanceAsset is one asset's balances in a daily snapshot
+type BalanceAsset struct {
+	Asset         string  `json:"asset" example:"USDT"`
+	WalletBalance float64 `json:"walletBalance" example:"10250.5"`
+	MarginBalance float64 `json:"marginBalance" example:"10310.2"` // Wallet balance + unrealized PnL
+}
+
+// BalanceSnapshot is the futures account balance at the end of one UTC day,
+// kept after Binance's 30-day snapshot window has rolled past
+type BalanceSnapshot struct {
+	Date          string         `json:"date" example:"2024-01-15"` // YYYY-MM-DD (UTC), also the ID
+	Time          int64          `json:"time" example:"1705363199"` // Binance snapshot time (Unix seconds)
+	Equity        float64        `json:"equity" example:"10310.2"`  // Margin balance of USD stablecoin assets
+	WalletBalance float64        `json:"walletBalance" example:"10250.5"`
+	UnrealizedPnL float64        `json:"unrealizedPnl" example:"59.7"`
+	Assets        []BalanceAsset `json:"assets"`
+	CreatedAt     int64          `json:"createdAt" example:"1705366800"`
+}
+
+// EquityPoint is one day of the balance history series
+type EquityPoint struct {
+	Date          string  `json:"date" example:"2024-01-15"`
+	Equity        float64 `json:"equity" example:"10310.2"`
+	WalletBalance float64 `json:"walletBalance" example:"10250.5"`
+	UnrealizedPnL float64 `json:"unrealizedPnl" example:"59.7"`
+	Change        float64 `json:"change" example:"120.4"`           // Equity change since the previous day
+	Index         float64 `json:"index" example:"103.1"`            // Equity relative to the first day (= 100)
+	Filled        bool    `json:"filled,omitempty" example:"false"` // No snapshot that day; the previous day's balance is carried forward
+}
+
+// BalanceHistory is a daily equity series
+type BalanceHistory struct {
+	From   string        `json:"from" example:"2024-01-01"`
+	To     string        `json:"to" example:"2024-03-31"`
+	Points []EquityPoint `json:"points"`
+}
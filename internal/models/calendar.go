@@ -0,0 +1,21 @@
+package models
+
+// Economic event impact levels, lowest first
+const (
+	ImpactLow    = "LOW"
+	ImpactMedium = "MEDIUM"
+	ImpactHigh   = "HIGH"
+)
+
+// EconomicEvent is a scheduled release from the economic calendar
+type EconomicEvent struct {
+	ID            string `json:"id" example:"USD-1763037000-cpi-m-m"`
+	Title         string `json:"title" example:"CPI m/m"`
+	Currency      string `json:"currency" example:"USD"`
+	Impact        string `json:"impact" example:"HIGH"`     // LOW, MEDIUM or HIGH
+	Time          int64  `json:"time" example:"1763037000"` // Release time, Unix seconds
+	Forecast      string `json:"forecast,omitempty" example:"0.3%"`
+	Previous      string `json:"previous,omitempty" example:"0.4%"`
+	BlackoutFrom  int64  `json:"blackoutFrom,omitempty" example:"1763036100"` // Window around the release, for events at the blackout impact
+	BlackoutUntil int64  `json:"blackoutUntil,omitempty" example:"1763037900"`
+}
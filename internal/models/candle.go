@@ -0,0 +1,20 @@
+package models
+
+// Candle is an OHLCV bar kept in memory from kline and aggTrade streams
+type Candle struct {
+	OpenTime  int64   `json:"openTime" example:"1704067200000"`  // Unix ms
+	CloseTime int64   `json:"closeTime" example:"1704067259999"` // Unix ms
+	Open      float64 `json:"open" example:"42000.5"`
+	High      float64 `json:"high" example:"42080"`
+	Low       float64 `json:"low" example:"41990.1"`
+	Close     float64 `json:"close" example:"42050"`
+	Volume    float64 `json:"volume" example:"152.34"` // Base asset
+	Trades    int64   `json:"trades" example:"1820"`
+	Final     bool    `json:"final"` // false while the candle is still forming
+}
+
+// CandleStreamRequest starts in-memory candles for a symbol and interval
+type CandleStreamRequest struct {
+	Symbol   string `json:"symbol" binding:"required" example:"BTCUSDT"`
+	Interval string `json:"interval" binding:"required" example:"1m"` // 1m to 1d
+}
@@ -0,0 +1,11 @@
+package models
+
+// ClusterStatus describes this instance's part in leader election
+type ClusterStatus struct {
+	InstanceID  string   `json:"instanceId" example:"api-1-3f9a2c"`
+	Shared      bool     `json:"shared"` // Locks shared through Redis; false for a single instance
+	Leader      bool     `json:"leader"`
+	LeaderID    string   `json:"leaderId,omitempty" example:"api-2-81c0d4"` // Instance holding the leader lock
+	LeaderSince int64    `json:"leaderSince,omitempty" example:"1640995200"`
+	Duties      []string `json:"duties"` // Jobs only the leader runs
+}
@@ -0,0 +1,15 @@
+package models
+
+// ConfigReloadResult reports what a configuration reload changed
+type ConfigReloadResult struct {
+	Applied         []SettingChange `json:"applied"`                                       // Changed settings now in effect
+	RestartRequired []string        `json:"restartRequired" example:"BinanceOrderTimeout"` // Changed settings that only take effect after a restart
+	ReloadedAt      int64           `json:"reloadedAt" example:"1640995200"`
+}
+
+// SettingChange is one setting changed by a configuration reload
+type SettingChange struct {
+	Setting string `json:"setting" example:"RATE_LIMIT_PER_KEY"`
+	Old     string `json:"old" example:"120"`
+	New     string `json:"new" example:"60"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// UserCredentials holds a user's own Binance API keys, encrypted at rest
+type UserCredentials struct {
+	UserID          string `json:"userId" example:"user123"`
+	APIKeyEncrypted string `json:"apiKeyEncrypted"`
+	SecretEncrypted string `json:"secretEncrypted"`
+	APIKeyHint      string `json:"apiKeyHint" example:"...x7Qa"` // Last characters of the API key, for display
+	CreatedAt       int64  `json:"createdAt" example:"1640995200"`
+	UpdatedAt       int64  `json:"updatedAt" example:"1640995200"`
+}
+
+// UserCredentialsRequest represents a request to store a user's Binance keys
+type UserCredentialsRequest struct {
+	APIKey    string `json:"apiKey" binding:"required" example:"your-binance-api-key"`
+	SecretKey string `json:"secretKey" binding:"required" example:"your-binance-secret-key"`
+}
+
+// UserCredentialsStatus describes stored credentials without exposing them
+type UserCredentialsStatus struct {
+	UserID     string `json:"userId" example:"user123"`
+	Configured bool   `json:"configured" example:"true"`
+	APIKeyHint string `json:"apiKeyHint,omitempty" example:"...x7Qa"`
+	UpdatedAt  int64  `json:"updatedAt,omitempty" example:"1640995200"`
+}
+
+// UserAccount is the Trade.Account of trades placed with a user's own keys
+func UserAccount(userID string) string {
+	return "user:" + userID
+}
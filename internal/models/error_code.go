@@ -0,0 +1,44 @@
+package models
+
+// Error codes sent as errorCode in failed responses, so clients can branch on
+// the failure instead of parsing messages. New codes may be added; clients
+// should treat unknown ones by HTTP status.
+const (
+	// Request and access
+	ErrInvalidRequest = "ERR_INVALID_REQUEST" // Malformed or invalid body or parameters (see details)
+	ErrUnauthorized   = "ERR_UNAUTHORIZED"    // Missing or invalid API key, token or signature
+	ErrForbidden      = "ERR_FORBIDDEN"       // Role or scope does not allow the call
+	ErrNotFound       = "ERR_NOT_FOUND"
+	ErrConflict       = "ERR_CONFLICT"     // State conflict, e.g. a request with the same Idempotency-Key in progress
+	ErrRateLimited    = "ERR_RATE_LIMITED" // This API's rate limits
+	ErrGone           = "ERR_GONE"         // Retired endpoint
+
+	// Trading policy
+	ErrTradingPaused       = "ERR_TRADING_PAUSED"
+	ErrShuttingDown        = "ERR_SHUTTING_DOWN"
+	ErrSymbolNotAllowed    = "ERR_SYMBOL_NOT_ALLOWED"
+	ErrRiskLimit           = "ERR_RISK_LIMIT"            // Per-user risk limits
+	ErrPositionLimit       = "ERR_POSITION_LIMIT"        // Maximum concurrent positions
+	ErrNoAccount           = "ERR_NO_ACCOUNT"            // No Binance account for the user
+	ErrOpenPosition        = "ERR_OPEN_POSITION"         // Leverage or margin type change the open position or orders do not allow
+	ErrOutsideTradingHours = "ERR_OUTSIDE_TRADING_HOURS" // Signal outside its preset's trading hours
+	ErrSymbolHalted        = "ERR_SYMBOL_HALTED"         // Volatility circuit breaker tripped on the symbol
+
+	// Exchange
+	ErrInsufficientMargin  = "ERR_INSUFFICIENT_MARGIN"
+	ErrInsufficientBalance = "ERR_INSUFFICIENT_BALANCE"
+	ErrMinNotional         = "ERR_MIN_NOTIONAL"
+	ErrOrderWouldTrigger   = "ERR_ORDER_WOULD_TRIGGER" // Stop price already crossed
+	ErrReduceOnlyRejected  = "ERR_REDUCE_ONLY_REJECTED"
+	ErrPositionSide        = "ERR_POSITION_SIDE" // Position mode (one-way/hedge) mismatch
+	ErrClockSkew           = "ERR_CLOCK_SKEW"    // Request timestamp outside Binance's window
+	ErrExchangeAuth        = "ERR_EXCHANGE_AUTH" // Binance rejected the API key or signature
+	ErrExchangeRateLimited = "ERR_EXCHANGE_RATE_LIMITED"
+	ErrExchangeRejected    = "ERR_EXCHANGE_REJECTED"    // Any other Binance rejection
+	ErrExchangeUnavailable = "ERR_EXCHANGE_UNAVAILABLE" // Binance unreachable, timing out or circuit open
+
+	// Server
+	ErrStorage     = "ERR_STORAGE" // Database read or write failed
+	ErrUnavailable = "ERR_UNAVAILABLE"
+	ErrInternal    = "ERR_INTERNAL"
+)
@@ -0,0 +1,48 @@
+package models
+
+// Funding arbitrage group statuses
+const (
+	ArbStatusOpen   = "OPEN"
+	ArbStatusClosed = "CLOSED"
+	ArbStatusFailed = "FAILED"
+)
+
+// ArbLeg is one side of a funding arbitrage pair
+type ArbLeg struct {
+	Market          string  `json:"market" example:"SPOT"` // SPOT or PERP
+	Side            string  `json:"side" example:"BUY"`
+	OrderID         int64   `json:"orderId,omitempty" example:"123456789"`
+	Quantity        float64 `json:"quantity" example:"0.002"`
+	AvgPrice        float64 `json:"avgPrice,omitempty" example:"50000"`
+	Commission      float64 `json:"commission,omitempty" example:"0.05"`
+	CommissionAsset string  `json:"commissionAsset,omitempty" example:"USDT"`
+	CloseOrderID    int64   `json:"closeOrderId,omitempty" example:"123456799"`
+	ClosePrice      float64 `json:"closePrice,omitempty" example:"50500"`
+}
+
+// FundingArbGroup links the spot and perpetual legs of a delta-neutral
+// position that harvests positive funding (long spot, short perp)
+type FundingArbGroup struct {
+	ID               string  `json:"id" example:"0b9f6c1e-7d2a-4f4e-8a1b-3c5d7e9f1a2b"`
+	UserID           string  `json:"userId" example:"user123"`
+	Symbol           string  `json:"symbol" example:"BTCUSDT"`
+	Notional         float64 `json:"notional" example:"100"` // USDT per leg at entry
+	Leverage         int     `json:"leverage" example:"2"`   // Perp leg leverage
+	EntryFundingRate float64 `json:"entryFundingRate" example:"0.0008"`
+	SpotLeg          ArbLeg  `json:"spotLeg"`
+	PerpLeg          ArbLeg  `json:"perpLeg"`
+	FundingCollected float64 `json:"fundingCollected" example:"0.24"` // FUNDING_FEE income on the symbol since opening
+	Status           string  `json:"status" example:"OPEN"`           // OPEN, CLOSED, FAILED
+	Error            string  `json:"error,omitempty" example:""`
+	OpenedBy         string  `json:"openedBy" example:"api"` // api or bot
+	OpenedAt         int64   `json:"openedAt" example:"1640995200"`
+	ClosedAt         int64   `json:"closedAt,omitempty" example:"1641081600"`
+}
+
+// FundingArbRequest represents a request to open a funding arbitrage pair
+type FundingArbRequest struct {
+	UserID   string  `json:"userId" binding:"required" example:"user123"`
+	Symbol   string  `json:"symbol" binding:"required" example:"BTCUSDT"`
+	Notional float64 `json:"notional" binding:"required,gt=0" example:"100"`                  // USDT per leg
+	Leverage int     `json:"leverage,omitempty" binding:"omitempty,min=1,max=20" example:"2"` // Perp leg leverage (default 1)
+}
@@ -0,0 +1,14 @@
+package models
+
+// IdempotencyRecord is the stored response to a POST made with an
+// Idempotency-Key header, replayed when the request is retried
+type IdempotencyRecord struct {
+	Key         string `json:"key"`         // Hex SHA-256 of the caller and the Idempotency-Key
+	Fingerprint string `json:"fingerprint"` // Hex SHA-256 of the method, path and body
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        string `json:"body"`
+	Withheld    bool   `json:"withheld,omitempty"` // The response carried a secret; only the status is kept
+	CreatedAt   int64  `json:"createdAt"`
+	ExpiresAt   int64  `json:"expiresAt"`
+}
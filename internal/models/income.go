@@ -0,0 +1,13 @@
+package models
+
+// IncomeRecord represents a single Binance income history entry
+type IncomeRecord struct {
+	Symbol     string  `json:"symbol"`
+	IncomeType string  `json:"incomeType"`
+	Income     float64 `json:"income"`
+	Asset      string  `json:"asset"`
+	Info       string  `json:"info"`
+	Time       int64   `json:"time"` // Milliseconds
+	TranID     int64   `json:"tranId"`
+	TradeID    string  `json:"tradeId"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// TradeJournal holds the user's notes about a trade
+type TradeJournal struct {
+	Notes       string   `json:"notes,omitempty" example:"Entered on the 4h breakout retest"`
+	Tags        []string `json:"tags,omitempty" example:"breakout,btc"`
+	Screenshots []string `json:"screenshots,omitempty" example:"https://www.tradingview.com/x/abc123/"` // External chart image URLs
+	UpdatedAt   int64    `json:"updatedAt,omitempty" example:"1641000000"`
+}
+
+// TradeJournalRequest represents a journal update; omitted fields are left unchanged
+type TradeJournalRequest struct {
+	Notes       *string  `json:"notes,omitempty" example:"Entered on the 4h breakout retest"`
+	Tags        []string `json:"tags,omitempty" example:"breakout,btc"`
+	Screenshots []string `json:"screenshots,omitempty" example:"https://www.tradingview.com/x/abc123/"`
+}
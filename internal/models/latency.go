@@ -0,0 +1,30 @@
+package models
+
+// LatencyStats summarizes latencies in milliseconds
+type LatencyStats struct {
+	Count int     `json:"count" example:"240"`
+	P50   float64 `json:"p50" example:"38"`
+	P90   float64 `json:"p90" example:"72"`
+	P95   float64 `json:"p95" example:"95"`
+	P99   float64 `json:"p99" example:"210"`
+	Max   float64 `json:"max" example:"480"`
+}
+
+// LatencyReport is the outcome of GET /api/analytics/latency
+type LatencyReport struct {
+	Period            string                  `json:"period" example:"1d"`
+	Trades            int                     `json:"trades" example:"42"`                    // Timed trades created in the period
+	Stages            map[string]LatencyStats `json:"stages"`                                 // validation, order, acknowledged, protection, filled (see TradeLatency)
+	Slowest           []SlowTrade             `json:"slowest"`                                // Slowest acknowledgements, slowest first
+	Dependencies      map[string]LatencyStats `json:"dependencies"`                           // Recent calls of this instance, by dependency and endpoint
+	DependenciesSince int64                   `json:"dependenciesSince" example:"1640995200"` // Instance start, Unix seconds
+}
+
+// SlowTrade is a trade of a latency report with its timings
+type SlowTrade struct {
+	TradeID   string       `json:"tradeId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Symbol    string       `json:"symbol" example:"BTCUSDT"`
+	OrderType string       `json:"orderType" example:"MARKET"`
+	CreatedAt int64        `json:"createdAt" example:"1640995200"`
+	Latency   TradeLatency `json:"latency"`
+}
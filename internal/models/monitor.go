@@ -0,0 +1,13 @@
+package models
+
+// TradeMonitor represents a running watch on a trade's entry order
+type TradeMonitor struct {
+	TradeID   string `json:"tradeId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID    string `json:"userId" example:"user123"`
+	Symbol    string `json:"symbol" example:"BTCUSDT"`
+	OrderID   int64  `json:"orderId" example:"123456789"`
+	Account   string `json:"account,omitempty" example:"user:user123"` // Empty = primary account
+	Instance  string `json:"instance" example:"api-1-3f9a2c"`          // Server instance running the monitor
+	StartedAt int64  `json:"startedAt" example:"1640995200"`
+	Recovered bool   `json:"recovered"` // Re-attached at startup from an ACTIVE trade
+}
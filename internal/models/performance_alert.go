@@ -0,0 +1,54 @@
+package models
+
+// Performance alert kinds
+const (
+	PerformanceAlertDrawdown     = "DRAWDOWN"      // Net PnL fell this far (USDT) below its peak within the rolling window
+	PerformanceAlertLosingStreak = "LOSING_STREAK" // The latest closed trades all lost
+	PerformanceAlertDailyLoss    = "DAILY_LOSS"    // The day's closed trades lost this much (USDT, net of fees)
+)
+
+// Performance alert statuses
+const (
+	PerformanceAlertActive       = "ACTIVE"       // Threshold crossed; reminders are sent until acknowledged
+	PerformanceAlertAcknowledged = "ACKNOWLEDGED" // Threshold still crossed; no more reminders
+	PerformanceAlertResolved     = "RESOLVED"     // Back within the threshold
+)
+
+// PerformanceThresholds are the levels performance alerts fire at (zero =
+// the server default)
+type PerformanceThresholds struct {
+	MaxDrawdown  float64 `json:"maxDrawdown,omitempty" example:"500"` // USDT
+	LosingStreak int     `json:"losingStreak,omitempty" example:"5"`  // Losing trades in a row
+	DailyLoss    float64 `json:"dailyLoss,omitempty" example:"200"`   // USDT
+}
+
+// PerformanceAlert represents a user's drawdown, losing streak or daily loss
+// crossing its threshold. It stays open (ACTIVE or ACKNOWLEDGED) until the
+// value is back within the threshold.
+type PerformanceAlert struct {
+	ID             string  `json:"id" example:"0b9f6c1e-7d2a-4f4e-8a1b-3c5d7e9f1a2b"`
+	UserID         string  `json:"userId" example:"user123"`
+	Kind           string  `json:"kind" example:"DRAWDOWN"` // DRAWDOWN, LOSING_STREAK or DAILY_LOSS
+	Status         string  `json:"status" example:"ACTIVE"` // ACTIVE, ACKNOWLEDGED, RESOLVED
+	Threshold      float64 `json:"threshold" example:"500"`
+	Value          float64 `json:"value" example:"612.4"`              // Latest value measured
+	Worst          float64 `json:"worst" example:"640.1"`              // Highest value while open
+	Day            string  `json:"day,omitempty" example:"2025-01-10"` // DAILY_LOSS: the day, in the user's time zone
+	TriggeredAt    int64   `json:"triggeredAt" example:"1736500000"`
+	NotifiedAt     int64   `json:"notifiedAt,omitempty" example:"1736500000"`   // Last notification or reminder
+	SnoozedUntil   int64   `json:"snoozedUntil,omitempty" example:"1736514400"` // No reminders before then
+	AcknowledgedAt int64   `json:"acknowledgedAt,omitempty" example:"1736501000"`
+	AcknowledgedBy string  `json:"acknowledgedBy,omitempty" example:"user123"`
+	ResolvedAt     int64   `json:"resolvedAt,omitempty" example:"1736600000"`
+	UpdatedAt      int64   `json:"updatedAt" example:"1736500000"`
+}
+
+// Open reports whether the alert's threshold is still crossed
+func (a *PerformanceAlert) Open() bool {
+	return a.Status == PerformanceAlertActive || a.Status == PerformanceAlertAcknowledged
+}
+
+// SnoozeRequest represents a request to pause an alert's reminders
+type SnoozeRequest struct {
+	Duration string `json:"duration" binding:"required" example:"4h"` // Go duration, e.g. 30m, 4h
+}
@@ -0,0 +1,21 @@
+package models
+
+// SymbolPolicyConfig represents the symbols allowed or blocked for trading
+type SymbolPolicyConfig struct {
+	Allowlist []string `json:"allowlist" example:"BTCUSDT,ETHUSDT"` // Empty = all symbols allowed
+	Blocklist []string `json:"blocklist" example:"LUNAUSDT"`        // Always rejected, even if allowlisted
+	UpdatedAt int64    `json:"updatedAt,omitempty" example:"1640995200"`
+}
+
+// TradingPauseStatus represents whether new trade intake is paused
+type TradingPauseStatus struct {
+	Paused   bool   `json:"paused" example:"true"`
+	Reason   string `json:"reason,omitempty" example:"Binance maintenance"`
+	PausedBy string `json:"pausedBy,omitempty" example:"telegram:123456789"`
+	Since    int64  `json:"since,omitempty" example:"1640995200"`
+}
+
+// TradingPauseRequest represents a request to pause new trade intake
+type TradingPauseRequest struct {
+	Reason string `json:"reason" example:"Binance maintenance"`
+}
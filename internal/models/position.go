@@ -0,0 +1,82 @@
+package models
+
+// DetailedPosition is a live Binance position joined with the open trades
+// that hold it
+type DetailedPosition struct {
+	Symbol           string          `json:"symbol" example:"BTCUSDT"`
+	Side             string          `json:"side" example:"LONG"`         // LONG or SHORT
+	PositionSide     string          `json:"positionSide" example:"BOTH"` // BOTH in one-way mode
+	PositionAmt      float64         `json:"positionAmt" example:"0.02"`
+	EntryPrice       float64         `json:"entryPrice" example:"50000.00"`
+	MarkPrice        float64         `json:"markPrice" example:"50500.00"`
+	Notional         float64         `json:"notional" example:"1010.00"` // |positionAmt| x mark price, USDT
+	UnrealizedProfit float64         `json:"unrealizedProfit" example:"10.00"`
+	Leverage         int             `json:"leverage" example:"10"`
+	LiquidationPrice float64         `json:"liquidationPrice" example:"45500.00"`
+	MarginType       string          `json:"marginType" example:"isolated"`
+	OpenedAt         int64           `json:"openedAt,omitempty" example:"1640995260"` // Fill of the oldest trade, Unix seconds
+	AgeSeconds       int64           `json:"ageSeconds,omitempty" example:"3600"`     // Since openedAt
+	FundingAccrued   float64         `json:"fundingAccrued" example:"-0.35"`          // FUNDING_FEE income since openedAt: received (+) or paid (-)
+	Managed          bool            `json:"managed" example:"true"`                  // Held by trades of this API
+	Trades           []PositionTrade `json:"trades"`                                  // Open trades on the symbol, oldest first
+}
+
+// PositionTrade is the context an open trade gives its position
+type PositionTrade struct {
+	TradeID               string   `json:"tradeId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID                string   `json:"userId" example:"user123"`
+	Side                  string   `json:"side" example:"BUY"`
+	Status                string   `json:"status" example:"FILLED"`
+	Tags                  []string `json:"tags,omitempty" example:"breakout,btc"` // Strategy tags from the trade journal
+	ExecutedPrice         float64  `json:"executedPrice,omitempty" example:"50100.50"`
+	Size                  float64  `json:"size" example:"100.00"`
+	StopLoss              float64  `json:"stopLoss" example:"49000.00"`
+	TakeProfit            float64  `json:"takeProfit" example:"52000.00"`
+	SLOrderID             int64    `json:"slOrderId,omitempty" example:"123456790"`
+	TPOrderID             int64    `json:"tpOrderId,omitempty" example:"123456791"`
+	OpenedAt              int64    `json:"openedAt" example:"1640995260"`
+	AgeSeconds            int64    `json:"ageSeconds" example:"3600"`
+	MaxAdverseExcursion   float64  `json:"maxAdverseExcursion,omitempty" example:"-85.40"`
+	MaxFavorableExcursion float64  `json:"maxFavorableExcursion,omitempty" example:"312.10"`
+	Adopted               bool     `json:"adopted,omitempty" example:"false"`
+}
+
+// DetailedPositions is the unified position view of an account
+type DetailedPositions struct {
+	TotalPositions int                `json:"totalPositions" example:"2"`
+	Unmanaged      int                `json:"unmanaged" example:"0"` // Positions no open trade holds
+	TotalPnL       float64            `json:"totalPnL" example:"25.40"`
+	TotalFunding   float64            `json:"totalFunding" example:"-0.35"`
+	FundingError   string             `json:"fundingError,omitempty" example:""` // Funding could not be read; fundingAccrued is 0
+	Positions      []DetailedPosition `json:"positions"`
+}
+
+// CloseAllRequest selects the positions POST /api/positions/close-all closes;
+// an empty request closes every open position of the account
+type CloseAllRequest struct {
+	Side    string   `json:"side,omitempty" binding:"omitempty,oneof=LONG SHORT" example:"LONG"` // Optional: only LONG or only SHORT positions
+	Symbols []string `json:"symbols,omitempty" example:"BTCUSDT,ETHUSDT"`                        // Optional: only these symbols
+	UserID  string   `json:"userId,omitempty" example:"user123"`                                 // Optional: close on the user's own Binance account
+	Account string   `json:"account,omitempty" example:"sub1"`                                   // Optional: close on this operator account (main or a BINANCE_ACCOUNTS name)
+}
+
+// ClosedPositionResult is the outcome of closing one position
+type ClosedPositionResult struct {
+	Symbol          string   `json:"symbol" example:"BTCUSDT"`
+	Side            string   `json:"side" example:"LONG"`
+	PositionAmt     float64  `json:"positionAmt" example:"0.02"`
+	OrderID         int64    `json:"orderId,omitempty" example:"123456792"` // Reduce-only market order
+	Price           string   `json:"price,omitempty" example:"50480.10"`    // Average fill price
+	RealizedPnL     float64  `json:"realizedPnl" example:"9.60"`
+	CancelledOrders int      `json:"cancelledOrders" example:"2"`                                       // SL/TP and other reduce-only orders left on the symbol
+	TradeIDs        []string `json:"tradeIds,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // Trades marked CLOSED
+	Error           string   `json:"error,omitempty" example:""`
+}
+
+// CloseAllResult is the outcome of POST /api/positions/close-all
+type CloseAllResult struct {
+	Closed           int                    `json:"closed" example:"2"`
+	Failed           int                    `json:"failed" example:"0"`
+	TotalRealizedPnL float64                `json:"totalRealizedPnl" example:"14.20"`
+	Results          []ClosedPositionResult `json:"results"` // By symbol
+}
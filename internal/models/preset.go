@@ -0,0 +1,79 @@
+package models
+
+// Preset sizing modes
+const (
+	SizingModeFixed       = "FIXED"        // Size is a fixed USDT amount
+	SizingModeRiskPercent = "RISK_PERCENT" // Size risks RiskPercent of account equity at the stop loss
+)
+
+// What happens to a preset's signals outside its trading hours
+const (
+	OutsideHoursReject = "REJECT" // Refused with ERR_OUTSIDE_TRADING_HOURS
+	OutsideHoursQueue  = "QUEUE"  // QUEUED until the trading hours open
+)
+
+// StrategyPreset holds default trade parameters referenced by name from a
+// TradeRequest (preset: "scalp-btc"). Values set on the request win.
+type StrategyPreset struct {
+	Name              string        `json:"name" example:"scalp-btc"`
+	UserID            string        `json:"userId" example:"user123"`
+	Leverage          int           `json:"leverage" example:"10"`
+	MarginType        string        `json:"marginType,omitempty" example:"ISOLATED"`
+	OrderType         string        `json:"orderType,omitempty" example:"MARKET"`
+	StopLossPercent   float64       `json:"stopLossPercent,omitempty" example:"1"`              // Distance from entry, in percent
+	TakeProfitPercent float64       `json:"takeProfitPercent,omitempty" example:"2"`            // Distance from entry, in percent
+	SizingMode        string        `json:"sizingMode" example:"FIXED"`                         // FIXED or RISK_PERCENT
+	Size              float64       `json:"size,omitempty" example:"100"`                       // FIXED: position size in USDT
+	RiskPercent       float64       `json:"riskPercent,omitempty" example:"1"`                  // RISK_PERCENT: equity percent lost if the stop loss is hit
+	AllowedSymbols    []string      `json:"allowedSymbols,omitempty" example:"BTCUSDT,ETHUSDT"` // Empty = any symbol
+	TradingHours      *TradingHours `json:"tradingHours,omitempty"`                             // Empty = any time
+	WorkingType       string        `json:"workingType,omitempty" example:"MARK_PRICE"`         // SL/TP trigger: MARK_PRICE or LAST_PRICE (empty = LAST_PRICE)
+	PriceProtect      bool          `json:"priceProtect,omitempty" example:"false"`             // SL/TP price protection
+	MaxPositions      int           `json:"maxPositions,omitempty" example:"3"`                 // Open trades placed with the preset at once (0 = no limit)
+	CreatedAt         int64         `json:"createdAt" example:"1640995200"`
+	UpdatedAt         int64         `json:"updatedAt" example:"1640995200"`
+}
+
+// StrategyPresetRequest represents a preset create/update request
+type StrategyPresetRequest struct {
+	UserID            string        `json:"userId" binding:"required" example:"user123"`
+	Leverage          int           `json:"leverage" binding:"required,min=1,max=125" example:"10"`
+	MarginType        string        `json:"marginType,omitempty" example:"ISOLATED"`
+	OrderType         string        `json:"orderType,omitempty" example:"MARKET"`
+	StopLossPercent   float64       `json:"stopLossPercent" binding:"gte=0,lt=100" example:"1"`
+	TakeProfitPercent float64       `json:"takeProfitPercent" binding:"gte=0" example:"2"`
+	SizingMode        string        `json:"sizingMode" example:"FIXED"`
+	Size              float64       `json:"size" binding:"gte=0" example:"100"`
+	RiskPercent       float64       `json:"riskPercent" binding:"gte=0,lte=100" example:"1"`
+	AllowedSymbols    []string      `json:"allowedSymbols,omitempty" example:"BTCUSDT,ETHUSDT"`
+	TradingHours      *TradingHours `json:"tradingHours,omitempty"`
+	WorkingType       string        `json:"workingType,omitempty" example:"MARK_PRICE"`
+	PriceProtect      bool          `json:"priceProtect,omitempty" example:"false"`
+	MaxPositions      int           `json:"maxPositions" binding:"gte=0" example:"3"`
+}
+
+// TradingHours limits when a preset's signals are placed. All times are UTC;
+// a signal is placed when it falls in a window, on an allowed weekday and
+// outside every blackout.
+type TradingHours struct {
+	Windows      []TradingWindow `json:"windows,omitempty"`                                // Empty = all day
+	Weekdays     []string        `json:"weekdays,omitempty" example:"MON,TUE,WED,THU,FRI"` // Day a window starts on; empty = every day
+	Blackouts    []Blackout      `json:"blackouts,omitempty"`
+	NewsBlackout bool            `json:"newsBlackout,omitempty" example:"true"` // Also closed during economic calendar blackouts
+	Outside      string          `json:"outside,omitempty" example:"QUEUE"`     // REJECT (default) or QUEUE
+}
+
+// TradingWindow is a daily time range. An end before the start runs past
+// midnight (22:00-02:00).
+type TradingWindow struct {
+	Start string `json:"start" example:"08:00"` // HH:MM, inclusive
+	End   string `json:"end" example:"16:30"`   // HH:MM, exclusive
+}
+
+// Blackout is a period no signal is placed in, such as a CPI release or an
+// FOMC decision
+type Blackout struct {
+	From   int64  `json:"from" example:"1763037000"` // Unix seconds, inclusive
+	To     int64  `json:"to" example:"1763040600"`   // Unix seconds, exclusive
+	Reason string `json:"reason,omitempty" example:"CPI"`
+}
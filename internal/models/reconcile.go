@@ -0,0 +1,53 @@
+package models
+
+// OrderReconcileReport is the outcome of one orphaned order reconciliation run
+type OrderReconcileReport struct {
+	StartedAt          int64               `json:"startedAt" example:"1640995200"`
+	FinishedAt         int64               `json:"finishedAt" example:"1640995202"`
+	OpenPositions      int                 `json:"openPositions" example:"2"`
+	OpenOrders         int                 `json:"openOrders" example:"5"`
+	OpenTrades         int                 `json:"openTrades" example:"3"`
+	ClosedTrades       []string            `json:"closedTrades"`               // Trades marked CLOSED because the exchange shows no position
+	CanceledOrders     []OrphanedOrder     `json:"canceledOrders"`             // SL/TP orders left behind by closed positions
+	UnmanagedPositions []UnmanagedPosition `json:"unmanagedPositions"`         // Positions opened outside the API
+	AdoptedPositions   []AdoptedPosition   `json:"adoptedPositions,omitempty"` // Unmanaged positions adopted as trades (ORDER_RECONCILE_ADOPT)
+	Errors             []string            `json:"errors,omitempty"`
+}
+
+// OrphanedOrder is a protective order whose position is gone
+type OrphanedOrder struct {
+	Symbol   string `json:"symbol" example:"BTCUSDT"`
+	OrderID  int64  `json:"orderId" example:"123456790"`
+	Type     string `json:"type" example:"STOP_MARKET"`
+	Canceled bool   `json:"canceled" example:"true"`
+	Error    string `json:"error,omitempty" example:""`
+}
+
+// UnmanagedPosition is an exchange position no open trade accounts for
+type UnmanagedPosition struct {
+	Symbol      string  `json:"symbol" example:"ETHUSDT"`
+	PositionAmt float64 `json:"positionAmt" example:"-0.5"`
+	EntryPrice  float64 `json:"entryPrice" example:"3000.00"`
+	FirstSeenAt int64   `json:"firstSeenAt" example:"1640995200"`
+}
+
+// AdoptRequest adopts positions opened outside the API as trades
+type AdoptRequest struct {
+	Symbols []string `json:"symbols,omitempty" example:"ETHUSDT"` // Unmanaged positions to adopt (default: all)
+	UserID  string   `json:"userId,omitempty" example:"operator"` // Owner of the trades, whose settings give the SL/TP (default: ORDER_RECONCILE_ADOPT_USER_ID)
+	Protect *bool    `json:"protect,omitempty" example:"true"`    // Attach SL/TP from the owner's defaultStopLoss/defaultTakeProfit (default: ORDER_RECONCILE_ADOPT_PROTECT)
+}
+
+// AdoptedPosition is an unmanaged position adopted as a trade
+type AdoptedPosition struct {
+	Symbol      string  `json:"symbol" example:"ETHUSDT"`
+	PositionAmt float64 `json:"positionAmt" example:"-0.5"`
+	EntryPrice  float64 `json:"entryPrice" example:"3000.00"`
+	TradeID     string  `json:"tradeId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	StopLoss    float64 `json:"stopLoss,omitempty" example:"3060.00"`
+	TakeProfit  float64 `json:"takeProfit,omitempty" example:"2880.00"`
+	SLOrderID   int64   `json:"slOrderId,omitempty" example:"123456790"`
+	TPOrderID   int64   `json:"tpOrderId,omitempty" example:"123456791"`
+	Linked      bool    `json:"linked,omitempty" example:"false"` // SL/TP orders already on the exchange were linked instead of placed
+	Error       string  `json:"error,omitempty" example:""`       // Why the position or its SL/TP could not be adopted
+}
@@ -0,0 +1,37 @@
+package models
+
+// Report periods
+const (
+	ReportPeriodDaily  = "DAILY"
+	ReportPeriodWeekly = "WEEKLY"
+)
+
+// TradeHighlight represents a notable trade in a summary report
+type TradeHighlight struct {
+	TradeID string  `json:"tradeId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Symbol  string  `json:"symbol" example:"BTCUSDT"`
+	Side    string  `json:"side" example:"BUY"`
+	PnL     float64 `json:"pnl" example:"250.75"`
+}
+
+// SummaryReport represents a scheduled daily or weekly trading summary
+type SummaryReport struct {
+	ID            string          `json:"id" example:"daily-2024-01-15"`
+	Period        string          `json:"period" example:"DAILY"` // DAILY or WEEKLY
+	StartTime     int64           `json:"startTime" example:"1705276800"`
+	EndTime       int64           `json:"endTime" example:"1705363200"`
+	TotalTrades   int             `json:"totalTrades" example:"12"` // Trades opened in the period
+	ClosedTrades  int             `json:"closedTrades" example:"10"`
+	Wins          int             `json:"wins" example:"6"`
+	Losses        int             `json:"losses" example:"4"`
+	WinRate       float64         `json:"winRate" example:"60"`
+	TotalPnL      float64         `json:"totalPnl" example:"320.5"`
+	TotalFees     float64         `json:"totalFees" example:"12.4"`
+	NetPnL        float64         `json:"netPnl" example:"308.1"`
+	BestTrade     *TradeHighlight `json:"bestTrade,omitempty"`
+	WorstTrade    *TradeHighlight `json:"worstTrade,omitempty"`
+	OpenPositions int             `json:"openPositions" example:"2"`
+	OpenNotional  float64         `json:"openNotional" example:"4500"`
+	UnrealizedPnL float64         `json:"unrealizedPnl" example:"-35.2"`
+	CreatedAt     int64           `json:"createdAt" example:"1705363260"`
+}
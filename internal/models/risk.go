@@ -0,0 +1,32 @@
+package models
+
+// RiskAction represents an automated risk action taken on a position
+type RiskAction struct {
+	ID                    string  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Symbol                string  `json:"symbol" example:"BTCUSDT"`
+	TradeID               string  `json:"tradeId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Action                string  `json:"action" example:"ADD_MARGIN"` // ADD_MARGIN or REDUCE_POSITION
+	Reason                string  `json:"reason" example:"distance to liquidation 4.20% below 8.00%"`
+	PositionAmt           float64 `json:"positionAmt" example:"0.015"`
+	MarkPrice             float64 `json:"markPrice" example:"50000.00"`
+	LiquidationPrice      float64 `json:"liquidationPrice" example:"47900.00"`
+	DistanceToLiquidation float64 `json:"distanceToLiquidation" example:"4.2"`
+	Amount                float64 `json:"amount,omitempty" example:"50.00"`      // Margin added (USDT)
+	Quantity              string  `json:"quantity,omitempty" example:"0.003"`    // Quantity reduced
+	OrderID               int64   `json:"orderId,omitempty" example:"123456789"` // Reduce-only order ID
+	Success               bool    `json:"success" example:"true"`
+	Error                 string  `json:"error,omitempty" example:""`
+	CreatedAt             int64   `json:"createdAt" example:"1640995200"`
+}
+
+// VolatilityHalt is a symbol whose new entries are paused by the volatility
+// circuit breaker
+type VolatilityHalt struct {
+	Symbol     string  `json:"symbol" example:"BTCUSDT"`
+	Reason     string  `json:"reason" example:"price moved 6.20% within 1m0s (limit 5.00%)"`
+	Change     float64 `json:"change" example:"6.2"`      // Largest move within the window when tripped (%)
+	Divergence float64 `json:"divergence" example:"0.4"`  // Mark/index divergence when tripped (%)
+	MarkPrice  float64 `json:"markPrice" example:"47000"` // Mark price when tripped
+	Since      int64   `json:"since" example:"1640995200"`
+	CalmSince  int64   `json:"calmSince,omitempty" example:"1640995500"` // Conditions back within limits since, resuming after the cooldown
+}
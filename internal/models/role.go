@@ -0,0 +1,21 @@
+package models
+
+// Roles assigned to authenticated users
+const (
+	RoleViewer = "viewer" // GET endpoints only
+	RoleTrader = "trader" // Place, close and manage their own trades
+	RoleAdmin  = "admin"  // Any user's resources plus system, cancel-all and configuration endpoints
+)
+
+// RoleAssignment binds a user to a role
+type RoleAssignment struct {
+	UserID    string `json:"userId" example:"user123"`
+	Role      string `json:"role" example:"trader"`
+	UpdatedAt int64  `json:"updatedAt" example:"1640995200"`
+	UpdatedBy string `json:"updatedBy,omitempty" example:"master"` // Key ID or user that assigned the role
+}
+
+// RoleAssignmentRequest represents a request to assign a role
+type RoleAssignmentRequest struct {
+	Role string `json:"role" binding:"required,oneof=viewer trader admin" example:"trader"`
+}
@@ -14,7 +14,7 @@ type Trade struct {
 	TakeProfit    float64 `json:"takeProfit" example:"52000.00"`
 	Leverage      int     `json:"leverage" example:"10"`
 	Size          float64 `json:"size" example:"1000.00"`
-	Status        string  `json:"status" example:"ACTIVE"` // PENDING, ACTIVE, FILLED, CANCELED, FAILED
+	Status        string  `json:"status" example:"ACTIVE"` // PENDING, QUEUED, ACTIVE, FILLED, CANCELED, FAILED, EXPIRED
 	OrderID       int64   `json:"orderId,omitempty" example:"123456789"`
 	SLOrderID     int64   `json:"slOrderId,omitempty" example:"123456790"` // Stop Loss order ID
 	TPOrderID     int64   `json:"tpOrderId,omitempty" example:"123456791"` // Take Profit order ID
@@ -23,31 +23,100 @@ type Trade struct {
 	ExecutedAt    int64   `json:"executedAt,omitempty" example:"1640995260"`
 	ClosedAt      int64   `json:"closedAt,omitempty" example:"1640999800"`
 	PnL           float64 `json:"pnl,omitempty" example:"250.75"`
+	Commission      float64 `json:"commission,omitempty" example:"1.25"`         // Total fees paid on entry and exit fills, in CommissionAsset
+	CommissionAsset string  `json:"commissionAsset,omitempty" example:"USDT"`    // Quote asset the fees are converted to
+	Commissions     map[string]float64 `json:"commissions,omitempty"`          // Fees by the asset they were charged in (USDT, BNB)
+	ReconcileStatus string  `json:"reconcileStatus,omitempty" example:"MATCHED"` // MATCHED, UNMATCHED, AMBIGUOUS, NO_ACCOUNT
+	PnLDiscrepancy  float64 `json:"pnlDiscrepancy,omitempty" example:"-0.42"`    // Exchange PnL minus previously recorded PnL
+	ReconciledAt    int64   `json:"reconciledAt,omitempty" example:"1641000000"`
+	Journal         *TradeJournal `json:"journal,omitempty"`
+	Account         string  `json:"account,omitempty" example:"follower1"`                                 // Account placed on: a BINANCE_ACCOUNTS or follower name, or user:<id> (empty = main account)
+	Preset          string  `json:"preset,omitempty" example:"scalp-btc"`                              // Strategy preset the trade was placed with
+	CopyOf          string  `json:"copyOf,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // Primary trade this follower trade replicates
+	MarginAdded     float64 `json:"marginAdded,omitempty" example:"50.00"`                           // Net isolated margin added (negative: removed) since entry, USDT
+	MaxAdverseExcursion   float64 `json:"maxAdverseExcursion,omitempty" example:"-85.40"`  // MAE: worst unrealized PnL while open, USDT
+	MaxFavorableExcursion float64 `json:"maxFavorableExcursion,omitempty" example:"312.10"` // MFE: best unrealized PnL while open, USDT
+	MaxAdversePrice       float64 `json:"maxAdversePrice,omitempty" example:"49573.00"`    // Mark price at the MAE
+	MaxFavorablePrice     float64 `json:"maxFavorablePrice,omitempty" example:"51560.50"`  // Mark price at the MFE
+	QueuedUntil           int64   `json:"queuedUntil,omitempty" example:"1641024000"`     // QUEUED outside its preset's trading hours: placed from then on
+	Adopted               bool    `json:"adopted,omitempty" example:"false"`                // Position opened outside the API, adopted by reconciliation
+	WorkingType           string  `json:"workingType,omitempty" example:"MARK_PRICE"`      // Price the SL/TP orders trigger on: MARK_PRICE or LAST_PRICE (empty = LAST_PRICE)
+	PriceProtect          bool    `json:"priceProtect,omitempty" example:"false"`           // SL/TP do not trigger while mark and last price diverge too far
+	Latency               *TradeLatency `json:"latency,omitempty"`                            // Entry order timings
+}
+
+// TradeLatency times a trade's entry, in milliseconds from when its signal
+// was received. Queued trades are timed from when they were released.
+type TradeLatency struct {
+	ReceivedAt     int64 `json:"receivedAt" example:"1640995260120"`         // Signal received, Unix ms
+	ValidationMs   int64 `json:"validationMs" example:"42"`                  // Checks, leverage and margin type, until the entry order was sent
+	OrderMs        int64 `json:"orderMs" example:"61"`                       // Entry order round trip to Binance
+	AcknowledgedMs int64 `json:"acknowledgedMs" example:"103"`               // Entry order acknowledged by Binance
+	ProtectionMs   int64 `json:"protectionMs,omitempty" example:"118"`       // SL/TP orders placed, after the acknowledgement
+	FilledMs       int64 `json:"filledMs,omitempty" example:"104"`           // Entry filled (Binance transaction time)
+}
+
+// SL/TP trigger prices (Trade.WorkingType)
+const (
+	WorkingTypeMark = "MARK_PRICE" // Mark price: ignores wicks on the last traded price
+	WorkingTypeLast = "LAST_PRICE" // Last traded price (Binance's default)
+)
+
+// FinalStatus reports whether a trade status is final: the trade is closed
+// or never opened, and nothing will trade on it again
+func FinalStatus(status string) bool {
+	switch status {
+	case "CLOSED", "CANCELED", "FAILED", "EXPIRED", "REJECTED":
+		return true
+	}
+	return false
 }
 
 // TradeRequest represents incoming trade order
 type TradeRequest struct {
-	UserID     string  `json:"userId" binding:"required" example:"user123"`
+	UserID     string  `json:"userId" example:"user123"`                             // Required with API keys; defaults to the token's user with JWT auth
 	Symbol     string  `json:"symbol" binding:"required" example:"BTCUSDT"`         // e.g., "BTCUSDT"
 	Side       string  `json:"side" binding:"required" example:"BUY"`               // "BUY" or "SELL"
 	EntryPrice float64 `json:"entryPrice" binding:"required" example:"50000.00"`    // Entry price
-	StopLoss   float64 `json:"stopLoss" binding:"required" example:"49000.00"`      // Stop loss price
-	TakeProfit float64 `json:"takeProfit" binding:"required" example:"52000.00"`    // Take profit price
-	Leverage   int     `json:"leverage" binding:"required,min=1,max=125" example:"10"` // Leverage (1-125x)
-	Size       float64 `json:"size" binding:"required,gt=0" example:"1000.00"`      // Position size in USDT
+	StopLoss   float64 `json:"stopLoss,omitempty" example:"49000.00"`               // Stop loss price (required unless the preset sets stopLossPercent)
+	TakeProfit float64 `json:"takeProfit,omitempty" example:"52000.00"`             // Take profit price (required unless the preset sets takeProfitPercent)
+	Leverage   int     `json:"leverage,omitempty" binding:"omitempty,min=1,max=125" example:"10"` // Leverage (1-125x, required unless set by the preset)
+	Size       float64 `json:"size,omitempty" binding:"omitempty,gt=0" example:"1000.00"` // Position size in USDT (required unless set by the preset)
 	OrderType  string  `json:"orderType,omitempty" example:"MARKET"`                // "MARKET" or "LIMIT" (default: MARKET)
 	MarginType string  `json:"marginType,omitempty" example:"ISOLATED"`             // "ISOLATED" or "CROSSED" (default: ISOLATED)
 	APIKey     string  `json:"apiKey,omitempty" example:"your-api-key-here"`        // Optional: API key for authentication (useful for TradingView alerts)
+	Preset     string  `json:"preset,omitempty" example:"scalp-btc"`                // Optional: strategy preset supplying any omitted parameters
+	Account    string  `json:"account,omitempty" example:"sub1"`                    // Optional: operator account to trade on ("main" or a BINANCE_ACCOUNTS name; default: the user's account)
+	WorkingType  string `json:"workingType,omitempty" example:"MARK_PRICE"` // Optional: SL/TP trigger, MARK_PRICE or LAST_PRICE (default: the preset's, else LAST_PRICE)
+	PriceProtect *bool  `json:"priceProtect,omitempty" example:"false"`     // Optional: SL/TP price protection (default: the preset's, else off)
+}
+
+// TradeReplaceRequest represents new entry parameters for a pending trade;
+// omitted fields keep their current value
+type TradeReplaceRequest struct {
+	EntryPrice float64 `json:"entryPrice,omitempty" binding:"omitempty,gt=0" example:"49800.00"` // New LIMIT entry price
+	StopLoss   float64 `json:"stopLoss,omitempty" binding:"omitempty,gt=0" example:"48800.00"`
+	TakeProfit float64 `json:"takeProfit,omitempty" binding:"omitempty,gt=0" example:"51800.00"`
+	Size       float64 `json:"size,omitempty" binding:"omitempty,gt=0" example:"1000.00"` // Position size in USDT
+}
+
+// TradeProtectionRequest represents new stop loss and/or take profit prices
+// for an open trade; an omitted price is left unchanged
+type TradeProtectionRequest struct {
+	StopLoss   float64 `json:"stopLoss,omitempty" binding:"omitempty,gt=0" example:"50200.00"`
+	TakeProfit float64 `json:"takeProfit,omitempty" binding:"omitempty,gt=0" example:"53000.00"`
 }
 
 // TradeResponse represents API response
 type TradeResponse struct {
-	Success   bool        `json:"success" example:"true"`
-	TradeID   string      `json:"tradeId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Message   string      `json:"message" example:"Trade executed successfully"`
-	Data      interface{} `json:"data,omitempty"`
-	Error     string      `json:"error,omitempty" example:""`
-	Timestamp int64       `json:"timestamp" example:"1640995200"`
+	Success   bool         `json:"success" example:"true"`
+	TradeID   string       `json:"tradeId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Message   string       `json:"message" example:"Trade executed successfully"`
+	Data      interface{}  `json:"data,omitempty"`
+	Error     string       `json:"error,omitempty" example:""`
+	ErrorCode string       `json:"errorCode,omitempty" example:"ERR_INSUFFICIENT_MARGIN"` // Machine-readable failure (see error_code.go)
+	Details   []FieldError `json:"details,omitempty"`                                     // Field-level errors of a rejected request
+	Timestamp int64        `json:"timestamp" example:"1640995200"`
 }
 
 // CancelOrderRequest represents order cancellation request
@@ -60,4 +129,32 @@ type CancelOrderRequest struct {
 type ClosePositionRequest struct {
 	Symbol  string `json:"symbol" binding:"required" example:"BTCUSDT"`
 	TradeID string `json:"tradeId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // Optional: link to Firebase trade
+	UserID  string `json:"userId,omitempty" example:"user123"`                               // Optional: close on the user's own Binance account
+	Account string `json:"account,omitempty" example:"sub1"`                                 // Optional: close on this operator account (main or a BINANCE_ACCOUNTS name)
+}
+
+// PositionMarginRequest represents an isolated margin change on an open position
+type PositionMarginRequest struct {
+	Symbol  string  `json:"symbol" binding:"required" example:"BTCUSDT"`
+	Type    string  `json:"type" binding:"required,oneof=ADD REMOVE" example:"ADD"`            // ADD or REMOVE margin
+	Amount  float64 `json:"amount" binding:"required,gt=0" example:"50.00"`                   // USDT
+	TradeID string  `json:"tradeId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // Optional: trade to record the change on
+	UserID  string  `json:"userId,omitempty" example:"user123"`                               // Optional: change on the user's own Binance account
+	Account string  `json:"account,omitempty" example:"sub1"`                                 // Optional: change on this operator account (main or a BINANCE_ACCOUNTS name)
+}
+
+// PositionLeverageRequest represents a leverage change on a symbol
+type PositionLeverageRequest struct {
+	Symbol   string `json:"symbol" binding:"required" example:"BTCUSDT"`
+	Leverage int    `json:"leverage" binding:"required,min=1,max=125" example:"10"`
+	UserID   string `json:"userId,omitempty" example:"user123"` // Optional: change on the user's own Binance account
+	Account  string `json:"account,omitempty" example:"sub1"`   // Optional: change on this operator account (main or a BINANCE_ACCOUNTS name)
+}
+
+// MarginTypeRequest represents a margin type change on a symbol
+type MarginTypeRequest struct {
+	Symbol     string `json:"symbol" binding:"required" example:"BTCUSDT"`
+	MarginType string `json:"marginType" binding:"required,oneof=ISOLATED CROSSED" example:"ISOLATED"`
+	UserID     string `json:"userId,omitempty" example:"user123"` // Optional: change on the user's own Binance account
+	Account    string `json:"account,omitempty" example:"sub1"`   // Optional: change on this operator account (main or a BINANCE_ACCOUNTS name)
 }
@@ -0,0 +1,22 @@
+package models
+
+// TradeEvent is one recorded state of a trade, appended whenever its status,
+// orders, prices or margin change. Events are never modified.
+type TradeEvent struct {
+	ID            string  `json:"id" example:"1700000000123456789-6f1c2a9e"` // Sorts chronologically
+	TradeID       string  `json:"tradeId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Timestamp     int64   `json:"timestamp" example:"1700000000123"`  // Unix milliseconds
+	Source        string  `json:"source,omitempty" example:"monitor"` // api, copy-trading, position-queue, monitor, user-data-stream, close-position, order-reconciler, pnl-reconciler, margin-adjustment, margin-guard, journal
+	PrevStatus    string  `json:"prevStatus,omitempty" example:"PENDING"`
+	Status        string  `json:"status" example:"FILLED"`
+	OrderID       int64   `json:"orderId,omitempty" example:"123456789"`
+	SLOrderID     int64   `json:"slOrderId,omitempty" example:"123456790"`
+	TPOrderID     int64   `json:"tpOrderId,omitempty" example:"123456791"`
+	EntryPrice    float64 `json:"entryPrice,omitempty" example:"50000.00"`
+	ExecutedPrice float64 `json:"executedPrice,omitempty" example:"50100.50"`
+	StopLoss      float64 `json:"stopLoss,omitempty" example:"49000.00"`
+	TakeProfit    float64 `json:"takeProfit,omitempty" example:"52000.00"`
+	PnL           float64 `json:"pnl,omitempty" example:"250.75"`
+	MarginAdded   float64 `json:"marginAdded,omitempty" example:"50.00"` // Net isolated margin added since entry (USDT)
+	Error         string  `json:"error,omitempty" example:""`
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TradeQuery selects one page of trades, newest first. Empty fields do not
+// filter.
+type TradeQuery struct {
+	UserID string
+	Status string
+	Symbol string
+	From   int64        // createdAt >= From (Unix seconds)
+	To     int64        // createdAt <= To (Unix seconds)
+	Limit  int          // Page size
+	After  *TradeCursor // Continue after this trade (from the previous page)
+}
+
+// Matches reports whether a trade passes the query's filters and comes after
+// its cursor
+func (q TradeQuery) Matches(trade *Trade) bool {
+	if q.UserID != "" && trade.UserID != q.UserID {
+		return false
+	}
+	if q.Status != "" && trade.Status != q.Status {
+		return false
+	}
+	if q.Symbol != "" && trade.Symbol != q.Symbol {
+		return false
+	}
+	if q.From != 0 && trade.CreatedAt < q.From {
+		return false
+	}
+	if q.To != 0 && trade.CreatedAt > q.To {
+		return false
+	}
+	return q.After == nil || q.After.Precedes(trade)
+}
+
+// TradeCursor is the position of a trade in newest-first order. Trades
+// created in the same second are ordered by descending ID.
+type TradeCursor struct {
+	CreatedAt int64
+	ID        string
+}
+
+// Precedes reports whether trade comes after the cursor
+func (c TradeCursor) Precedes(trade *Trade) bool {
+	return trade.CreatedAt < c.CreatedAt || (trade.CreatedAt == c.CreatedAt && trade.ID < c.ID)
+}
+
+// String encodes the cursor for the nextCursor response field
+func (c TradeCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.CreatedAt, 10) + ":" + c.ID))
+}
+
+// ParseTradeCursor decodes a cursor returned as nextCursor
+func ParseTradeCursor(s string) (*TradeCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	createdAt, id, ok := strings.Cut(string(data), ":")
+	if !ok || id == "" {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	seconds, err := strconv.ParseInt(createdAt, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &TradeCursor{CreatedAt: seconds, ID: id}, nil
+}
+
+// TradePage is one page of a trade query
+type TradePage struct {
+	Trades     []*Trade `json:"trades"`
+	NextCursor string   `json:"nextCursor,omitempty" example:"MTY0MDk5NTIwMDo1NTBlODQwMA"` // Pass as cursor for the next page; absent on the last page
+}
+
+// NewTradePage builds a page from up to limit+1 trades in newest-first order.
+// The extra trade only signals that another page follows.
+func NewTradePage(trades []*Trade, limit int) *TradePage {
+	if trades == nil {
+		trades = []*Trade{}
+	}
+	if len(trades) <= limit {
+		return &TradePage{Trades: trades}
+	}
+
+	trades = trades[:limit]
+	last := trades[limit-1]
+	return &TradePage{
+		Trades:     trades,
+		NextCursor: TradeCursor{CreatedAt: last.CreatedAt, ID: last.ID}.String(),
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+// TradingViewTemplate maps a TradingView alert onto a full trade request.
+// Alerts reference it by name and only need to carry what changes per signal
+// (ticker, action, price); everything else comes from the template.
+type TradingViewTemplate struct {
+	Name              string  `json:"name" example:"btc-scalp"`
+	UserID            string  `json:"userId" example:"user123"`
+	Leverage          int     `json:"leverage" example:"10"`
+	Size              float64 `json:"size" example:"100"`                   // Position size in USDT
+	OrderType         string  `json:"orderType,omitempty" example:"MARKET"` // MARKET or LIMIT
+	MarginType        string  `json:"marginType,omitempty" example:"ISOLATED"`
+	StopLossPercent   float64 `json:"stopLossPercent" example:"1.5"` // Distance from entry, in percent
+	TakeProfitPercent float64 `json:"takeProfitPercent" example:"3"` // Distance from entry, in percent
+	CreatedAt         int64   `json:"createdAt" example:"1640995200"`
+	UpdatedAt         int64   `json:"updatedAt" example:"1640995200"`
+}
+
+// TradingViewTemplateRequest represents a template create/update request
+type TradingViewTemplateRequest struct {
+	UserID            string  `json:"userId" binding:"required" example:"user123"`
+	Leverage          int     `json:"leverage" binding:"required,min=1,max=125" example:"10"`
+	Size              float64 `json:"size" binding:"required,gt=0" example:"100"`
+	OrderType         string  `json:"orderType,omitempty" example:"MARKET"`
+	MarginType        string  `json:"marginType,omitempty" example:"ISOLATED"`
+	StopLossPercent   float64 `json:"stopLossPercent" binding:"gte=0,lt=100" example:"1.5"`
+	TakeProfitPercent float64 `json:"takeProfitPercent" binding:"gte=0" example:"3"`
+}
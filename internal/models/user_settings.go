@@ -0,0 +1,46 @@
+package models
+
+// UserSettings holds a user's trade defaults, notification preferences,
+// risk limits and performance alert thresholds. Defaults fill parameters a trade request (or its preset)
+// leaves empty, so webhooks don't need to repeat them.
+type UserSettings struct {
+	UserID            string                `json:"userId" example:"user123"`
+	DefaultLeverage   int                   `json:"defaultLeverage,omitempty" example:"5"`
+	DefaultMarginType string                `json:"defaultMarginType,omitempty" example:"ISOLATED"`
+	DefaultStopLoss   float64               `json:"defaultStopLoss,omitempty" example:"2"`                     // Stop loss distance from entry (%) attached to adopted positions
+	DefaultTakeProfit float64               `json:"defaultTakeProfit,omitempty" example:"4"`                   // Take profit distance from entry (%) attached to adopted positions
+	Timezone          string                `json:"timezone,omitempty" example:"Asia/Bangkok"`                 // IANA time zone for displaying times
+	MutedEvents       []string              `json:"mutedEvents,omitempty" example:"TRADE_OPENED,TRADE_CLOSED"` // Notification types not sent for this user's trades
+	RiskLimits        RiskLimits            `json:"riskLimits"`
+	AlertThresholds   PerformanceThresholds `json:"alertThresholds"` // Drawdown, losing streak and daily loss alert levels
+	CreatedAt         int64                 `json:"createdAt" example:"1640995200"`
+	UpdatedAt         int64                 `json:"updatedAt" example:"1640995200"`
+}
+
+// RiskLimits caps what a user's trades may request (zero = no limit)
+type RiskLimits struct {
+	MaxLeverage     int     `json:"maxLeverage,omitempty" example:"20"`
+	MaxPositionSize float64 `json:"maxPositionSize,omitempty" example:"5000"` // USDT
+}
+
+// UserSettingsRequest represents a settings update; it replaces all settings
+type UserSettingsRequest struct {
+	DefaultLeverage   int                   `json:"defaultLeverage" binding:"omitempty,min=1,max=125" example:"5"`
+	DefaultMarginType string                `json:"defaultMarginType,omitempty" example:"ISOLATED"`
+	DefaultStopLoss   float64               `json:"defaultStopLoss" binding:"omitempty,gt=0,lt=100" example:"2"`
+	DefaultTakeProfit float64               `json:"defaultTakeProfit" binding:"omitempty,gt=0" example:"4"`
+	Timezone          string                `json:"timezone,omitempty" example:"Asia/Bangkok"`
+	MutedEvents       []string              `json:"mutedEvents,omitempty" example:"TRADE_OPENED"`
+	RiskLimits        RiskLimits            `json:"riskLimits"`
+	AlertThresholds   PerformanceThresholds `json:"alertThresholds"`
+}
+
+// Mutes reports whether the user turned off notifications of an event type
+func (s *UserSettings) Mutes(eventType string) bool {
+	for _, muted := range s.MutedEvents {
+		if muted == eventType {
+			return true
+		}
+	}
+	return false
+}
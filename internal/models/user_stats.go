@@ -0,0 +1,119 @@
+package models
+
+// UserTradeStats are a user's running totals over their closed trades,
+// updated as each trade closes so summaries never reload the trade history
+type UserTradeStats struct {
+	UserID        string         `json:"userId" example:"user123"`
+	ClosedTrades  int            `json:"closedTrades" example:"42"`
+	WinningTrades int            `json:"winningTrades" example:"25"`
+	LosingTrades  int            `json:"losingTrades" example:"16"`
+	TotalPnL      float64        `json:"totalPnL" example:"1250.5"`
+	TotalVolume   float64        `json:"totalVolume" example:"42000"` // Sum of trade sizes (margin, USDT)
+	TotalFees     float64        `json:"totalFees" example:"38.2"`
+	BestTrade     float64        `json:"bestTrade" example:"310.4"`
+	WorstTrade    float64        `json:"worstTrade" example:"-180.2"`
+	Symbols       map[string]int `json:"symbols,omitempty"` // Closed trades per symbol
+	FirstClosedAt int64          `json:"firstClosedAt,omitempty" example:"1700000000"`
+	LastClosedAt  int64          `json:"lastClosedAt,omitempty" example:"1736000000"`
+	UpdatedAt     int64          `json:"updatedAt" example:"1736000000"`
+	RebuiltAt     int64          `json:"rebuiltAt,omitempty" example:"1736000000"` // Last full recount (refresh)
+}
+
+// TradeContribution is what one closed trade adds to its owner's stats. It is
+// stored so a later correction (e.g. reconciled PnL) replaces the trade's
+// share instead of counting it twice, and so archived trades stay counted.
+type TradeContribution struct {
+	TradeID  string  `json:"tradeId"`
+	UserID   string  `json:"userId"`
+	Symbol   string  `json:"symbol"`
+	PnL      float64 `json:"pnl"`
+	Volume   float64 `json:"volume"`
+	Fees     float64 `json:"fees"`
+	ClosedAt int64   `json:"closedAt"`
+}
+
+// ContributionOf returns what a closed trade adds to its owner's stats
+func ContributionOf(trade *Trade) *TradeContribution {
+	return &TradeContribution{
+		TradeID:  trade.ID,
+		UserID:   trade.UserID,
+		Symbol:   trade.Symbol,
+		PnL:      trade.PnL,
+		Volume:   trade.Size,
+		Fees:     trade.Commission,
+		ClosedAt: trade.ClosedAt,
+	}
+}
+
+// Add counts a trade's contribution
+func (s *UserTradeStats) Add(c *TradeContribution) {
+	s.ClosedTrades++
+	switch {
+	case c.PnL > 0:
+		s.WinningTrades++
+	case c.PnL < 0:
+		s.LosingTrades++
+	}
+	s.TotalPnL += c.PnL
+	s.TotalVolume += c.Volume
+	s.TotalFees += c.Fees
+	if c.PnL > s.BestTrade {
+		s.BestTrade = c.PnL
+	}
+	if c.PnL < s.WorstTrade {
+		s.WorstTrade = c.PnL
+	}
+	if s.Symbols == nil {
+		s.Symbols = make(map[string]int)
+	}
+	s.Symbols[c.Symbol]++
+	if c.ClosedAt > 0 && (s.FirstClosedAt == 0 || c.ClosedAt < s.FirstClosedAt) {
+		s.FirstClosedAt = c.ClosedAt
+	}
+	if c.ClosedAt > s.LastClosedAt {
+		s.LastClosedAt = c.ClosedAt
+	}
+}
+
+// Remove takes back a contribution counted before. Best and worst trade stay
+// as they were: only a recount from the contributions lowers them.
+func (s *UserTradeStats) Remove(c *TradeContribution) {
+	s.ClosedTrades--
+	switch {
+	case c.PnL > 0:
+		s.WinningTrades--
+	case c.PnL < 0:
+		s.LosingTrades--
+	}
+	s.TotalPnL -= c.PnL
+	s.TotalVolume -= c.Volume
+	s.TotalFees -= c.Fees
+	if s.Symbols[c.Symbol] > 1 {
+		s.Symbols[c.Symbol]--
+	} else {
+		delete(s.Symbols, c.Symbol)
+	}
+}
+
+// UserSummary is a user's trading summary, derived from their stats
+type UserSummary struct {
+	UserTradeStats
+	WinRate    float64 `json:"winRate" example:"59.5"` // Percent of closed trades
+	AveragePnL float64 `json:"averagePnL" example:"29.77"`
+	NetPnL     float64 `json:"netPnL" example:"1212.3"` // Total PnL minus fees
+	CachedAt   int64   `json:"cachedAt" example:"1736000000"`
+}
+
+// NewUserSummary derives a summary from a user's stats
+func NewUserSummary(stats *UserTradeStats, cachedAt int64) *UserSummary {
+	summary := &UserSummary{
+		UserTradeStats: *stats,
+		NetPnL:         stats.TotalPnL - stats.TotalFees,
+		CachedAt:       cachedAt,
+	}
+	if stats.ClosedTrades > 0 {
+		summary.WinRate = float64(stats.WinningTrades) / float64(stats.ClosedTrades) * 100
+		summary.AveragePnL = stats.TotalPnL / float64(stats.ClosedTrades)
+	}
+	return summary
+}
@@ -0,0 +1,10 @@
+package models
+
+// FieldError explains why one request field was rejected and how to fix it
+type FieldError struct {
+	Field      string `json:"field" example:"stopLoss"`                        // JSON name; empty for the body as a whole
+	Code       string `json:"code" example:"tick_size"`                        // Machine-readable reason
+	Constraint string `json:"constraint,omitempty" example:"multiple of 0.10"` // The rule that failed
+	Message    string `json:"message" example:"stopLoss 49000.05 is not a multiple of the tick size 0.10"`
+	Suggestion string `json:"suggestion,omitempty" example:"Use 49000.1"` // Suggested fix
+}
@@ -0,0 +1,27 @@
+package models
+
+// Webhook represents a user-registered callback URL for trade lifecycle events
+type Webhook struct {
+	ID        string   `json:"id" example:"6f1c2a9e-1b7d-4c1e-9a57-2f0d5c1e8b11"`
+	UserID    string   `json:"userId" example:"user123"`
+	URL       string   `json:"url" example:"https://example.com/hooks/trades"`
+	Secret    string   `json:"secret,omitempty" example:"whsec_2b9f..."` // HMAC-SHA256 key, only returned on registration
+	Events    []string `json:"events" example:"FILLED,CLOSED"`           // FILLED, SL_HIT, TP_HIT, CLOSED
+	CreatedAt int64    `json:"createdAt" example:"1640995200"`
+}
+
+// WebhookRequest represents a webhook registration
+type WebhookRequest struct {
+	UserID string   `json:"userId" binding:"required" example:"user123"`
+	URL    string   `json:"url" binding:"required" example:"https://example.com/hooks/trades"`
+	Secret string   `json:"secret,omitempty" example:"my-shared-secret"` // Optional: generated when omitted
+	Events []string `json:"events,omitempty" example:"FILLED,CLOSED"`    // Optional: all events when omitted
+}
+
+// WebhookPayload represents the JSON body delivered to a webhook
+type WebhookPayload struct {
+	ID        string `json:"id"` // Delivery ID, stable across retries
+	Event     string `json:"event"`
+	Trade     *Trade `json:"trade"`
+	Timestamp int64  `json:"timestamp"`
+}
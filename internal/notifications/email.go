@@ -0,0 +1,100 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// EmailConfig configures the SMTP email channel
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// EmailChannel sends events as HTML emails over SMTP
+type EmailChannel struct {
+	config EmailConfig
+	alert  *template.Template
+	digest *template.Template
+}
+
+// NewEmailChannel creates an SMTP email channel
+func NewEmailChannel(config EmailConfig) *EmailChannel {
+	return &EmailChannel{
+		config: config,
+		alert:  template.Must(template.New("alert").Funcs(templateFuncs).Parse(alertTemplate)),
+		digest: template.Must(template.New("digest").Funcs(templateFuncs).Parse(digestTemplate)),
+	}
+}
+
+// Name returns the channel name
+func (e *EmailChannel) Name() string {
+	return "email"
+}
+
+// Send renders the event and mails it to all recipients. Only daily reports
+// are mailed as digests; weekly reports stay on the chat channels.
+func (e *EmailChannel) Send(ctx context.Context, event *Event) error {
+	var body bytes.Buffer
+	subject := event.Title
+
+	if report, ok := event.Data.(*models.SummaryReport); ok {
+		if report.Period != models.ReportPeriodDaily {
+			return nil
+		}
+		subject = fmt.Sprintf("Daily performance digest – %s", time.Unix(report.StartTime, 0).UTC().Format("2006-01-02"))
+		if err := e.digest.Execute(&body, report); err != nil {
+			return fmt.Errorf("failed to render digest email: %v", err)
+		}
+	} else if err := e.alert.Execute(&body, event); err != nil {
+		return fmt.Errorf("failed to render alert email: %v", err)
+	}
+
+	return e.sendMail(ctx, subject, body.String())
+}
+
+// sendMail delivers an HTML message via SMTP
+func (e *EmailChannel) sendMail(ctx context.Context, subject, html string) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	msg.WriteString(html)
+
+	var auth smtp.Auth
+	if e.config.Username != "" {
+		auth = smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", e.config.Host, e.config.Port)
+
+	// net/smtp has no context support, so give up waiting when ctx ends
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, e.config.From, e.config.To, msg.Bytes())
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %v", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send email: %v", ctx.Err())
+	}
+}
@@ -0,0 +1,54 @@
+package notifications
+
+import (
+	"html/template"
+	"time"
+)
+
+var templateFuncs = template.FuncMap{
+	"datetime": func(ts int64) string {
+		return time.Unix(ts, 0).UTC().Format("2006-01-02 15:04 UTC")
+	},
+	"date": func(ts int64) string {
+		return time.Unix(ts, 0).UTC().Format("2006-01-02")
+	},
+	"pnlColor": func(v float64) string {
+		if v < 0 {
+			return "#c0392b"
+		}
+		return "#27ae60"
+	},
+}
+
+const alertTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #222;">
+  <h2 style="margin-bottom: 4px;">{{.Title}}</h2>
+  <p style="color: #666; margin-top: 0;">{{datetime .Time}}{{if .Symbol}} · {{.Symbol}}{{end}}</p>
+  <p style="font-size: 15px;">{{.Message}}</p>
+  {{if .TradeID}}<p style="color: #666; font-size: 12px;">Trade ID: {{.TradeID}}</p>{{end}}
+  <hr style="border: none; border-top: 1px solid #eee;">
+  <p style="color: #999; font-size: 12px;">Crypto Trading API alert</p>
+</body>
+</html>`
+
+const digestTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #222;">
+  <h2>Daily performance digest – {{date .StartTime}}</h2>
+  <table cellpadding="6" style="border-collapse: collapse; font-size: 14px;">
+    <tr><td>Trades opened</td><td><b>{{.TotalTrades}}</b></td></tr>
+    <tr><td>Trades closed</td><td><b>{{.ClosedTrades}}</b></td></tr>
+    <tr><td>Win rate</td><td><b>{{printf "%.1f" .WinRate}}%</b> ({{.Wins}}W / {{.Losses}}L)</td></tr>
+    <tr><td>Realized PnL</td><td style="color: {{pnlColor .TotalPnL}};"><b>{{printf "%.2f" .TotalPnL}} USDT</b></td></tr>
+    <tr><td>Fees</td><td>{{printf "%.2f" .TotalFees}} USDT</td></tr>
+    <tr><td>Net PnL</td><td style="color: {{pnlColor .NetPnL}};"><b>{{printf "%.2f" .NetPnL}} USDT</b></td></tr>
+  </table>
+  {{if .BestTrade}}<p>Best trade: {{.BestTrade.Symbol}} {{.BestTrade.Side}} <span style="color: {{pnlColor .BestTrade.PnL}};">{{printf "%.2f" .BestTrade.PnL}}</span></p>{{end}}
+  {{if .WorstTrade}}<p>Worst trade: {{.WorstTrade.Symbol}} {{.WorstTrade.Side}} <span style="color: {{pnlColor .WorstTrade.PnL}};">{{printf "%.2f" .WorstTrade.PnL}}</span></p>{{end}}
+  <h3>Open risk</h3>
+  <p>{{.OpenPositions}} open positions · {{printf "%.2f" .OpenNotional}} USDT notional · unrealized <span style="color: {{pnlColor .UnrealizedPnL}};">{{printf "%.2f" .UnrealizedPnL}}</span></p>
+  <hr style="border: none; border-top: 1px solid #eee;">
+  <p style="color: #999; font-size: 12px;">Generated {{datetime .CreatedAt}} by Crypto Trading API</p>
+</body>
+</html>`
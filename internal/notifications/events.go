@@ -0,0 +1,159 @@
+package notifications
+
+import (
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TradeOpened builds the event for a newly placed trade
+func TradeOpened(trade *models.Trade) *Event {
+	return &Event{
+		Type:  EventTradeOpened,
+		Title: fmt.Sprintf("🟢 %s %s opened", trade.Side, trade.Symbol),
+		Message: fmt.Sprintf("Entry: %.4f | SL: %.4f | TP: %.4f | Size: %.2f USDT x%d",
+			trade.ExecutedPrice, trade.StopLoss, trade.TakeProfit, trade.Size, trade.Leverage),
+		Symbol:  trade.Symbol,
+		TradeID: trade.ID,
+		UserID:  trade.UserID,
+	}
+}
+
+// TradeClosed builds the event for a closed trade
+func TradeClosed(trade *models.Trade) *Event {
+	return &Event{
+		Type:    EventTradeClosed,
+		Title:   fmt.Sprintf("🔴 %s closed", strings.TrimSpace(trade.Side+" "+trade.Symbol)),
+		Message: fmt.Sprintf("PnL: %.2f USDT | Fees: %.2f", trade.PnL, trade.Commission),
+		Symbol:  trade.Symbol,
+		TradeID: trade.ID,
+		UserID:  trade.UserID,
+	}
+}
+
+// StopLossHit builds the event for a filled stop loss order
+func StopLossHit(symbol string, price, realizedPnL float64) *Event {
+	return &Event{
+		Type:    EventStopLossHit,
+		Title:   fmt.Sprintf("🛑 Stop loss hit on %s", symbol),
+		Message: fmt.Sprintf("Filled at %.4f | Realized PnL: %.2f USDT", price, realizedPnL),
+		Symbol:  symbol,
+	}
+}
+
+// TakeProfitHit builds the event for a filled take profit order
+func TakeProfitHit(symbol string, price, realizedPnL float64) *Event {
+	return &Event{
+		Type:    EventTakeProfitHit,
+		Title:   fmt.Sprintf("🎯 Take profit hit on %s", symbol),
+		Message: fmt.Sprintf("Filled at %.4f | Realized PnL: %.2f USDT", price, realizedPnL),
+		Symbol:  symbol,
+	}
+}
+
+// LiquidationRisk builds the event for a position close to liquidation
+func LiquidationRisk(symbol string, markPrice, liquidationPrice, distance float64, action string) *Event {
+	return &Event{
+		Type:  EventLiquidationRisk,
+		Title: fmt.Sprintf("⚠️ Liquidation risk on %s", symbol),
+		Message: fmt.Sprintf("Mark: %.4f | Liquidation: %.4f | Distance: %.2f%% | Action: %s",
+			markPrice, liquidationPrice, distance, action),
+		Symbol: symbol,
+	}
+}
+
+// PriceAlertTriggered builds the event for a fired price alert
+func PriceAlertTriggered(alert *models.PriceAlert) *Event {
+	message := fmt.Sprintf("Mark price %.4f is %s %.4f", alert.TriggerPrice, strings.ToLower(alert.Condition), alert.Price)
+	if alert.Note != "" {
+		message += " | " + alert.Note
+	}
+	if alert.TradeID != "" {
+		message += " | Trade submitted: " + alert.TradeID
+	} else if alert.TradeError != "" {
+		message += " | Trade failed: " + alert.TradeError
+	}
+
+	return &Event{
+		Type:    EventPriceAlert,
+		Title:   fmt.Sprintf("🔔 Price alert on %s", alert.Symbol),
+		Message: message,
+		Symbol:  alert.Symbol,
+		TradeID: alert.TradeID,
+	}
+}
+
+// PerformanceAlertTriggered builds the event for a user's drawdown, losing
+// streak or daily loss crossing its threshold, or a reminder that it still is
+func PerformanceAlertTriggered(alert *models.PerformanceAlert, reminder bool) *Event {
+	var title, message string
+	switch alert.Kind {
+	case models.PerformanceAlertLosingStreak:
+		title = "📉 Losing streak"
+		message = fmt.Sprintf("%d losing trades in a row (threshold: %d)", int(alert.Value), int(alert.Threshold))
+	case models.PerformanceAlertDailyLoss:
+		title = "📉 Daily loss limit reached"
+		message = fmt.Sprintf("Lost %.2f USDT on %s (threshold: %.2f)", alert.Value, alert.Day, alert.Threshold)
+	default:
+		title = "📉 Drawdown limit reached"
+		message = fmt.Sprintf("Drawdown: %.2f USDT (threshold: %.2f, worst: %.2f)", alert.Value, alert.Threshold, alert.Worst)
+	}
+	if reminder {
+		title += " (reminder)"
+	}
+	message += " | Acknowledge or snooze alert " + alert.ID
+
+	return &Event{
+		Type:    EventPerformanceAlert,
+		Title:   title,
+		Message: message,
+		UserID:  alert.UserID,
+		Data:    alert,
+	}
+}
+
+// UnmanagedPosition builds the event for a position opened outside the API
+func UnmanagedPosition(symbol string, positionAmt, entryPrice float64) *Event {
+	return &Event{
+		Type:    EventUnmanagedPosition,
+		Title:   fmt.Sprintf("👻 Unmanaged position on %s", symbol),
+		Message: fmt.Sprintf("Amount: %g | Entry: %.4f | No open trade accounts for it", positionAmt, entryPrice),
+		Symbol:  symbol,
+	}
+}
+
+// VolatilityHalt builds the event for new entries on a symbol halted by the
+// volatility circuit breaker
+func VolatilityHalt(symbol, reason string, markPrice float64) *Event {
+	return &Event{
+		Type:    EventVolatilityHalt,
+		Title:   fmt.Sprintf("🌪️ New entries on %s halted", symbol),
+		Message: fmt.Sprintf("Mark: %.4f | %s", markPrice, reason),
+		Symbol:  symbol,
+	}
+}
+
+// VolatilityResumed builds the event for new entries on a symbol resumed
+// once its prices calmed down
+func VolatilityResumed(symbol string, halted time.Duration) *Event {
+	return &Event{
+		Type:    EventVolatilityHalt,
+		Title:   fmt.Sprintf("✅ New entries on %s resumed", symbol),
+		Message: fmt.Sprintf("Prices back within limits after a %v halt", halted.Round(time.Second)),
+		Symbol:  symbol,
+	}
+}
+
+// KillSwitch builds the event for trading being halted or resumed
+func KillSwitch(active bool, reason string) *Event {
+	title := "⛔ Trading halted"
+	if !active {
+		title = "✅ Trading resumed"
+	}
+	return &Event{
+		Type:    EventKillSwitch,
+		Title:   title,
+		Message: reason,
+	}
+}
@@ -0,0 +1,212 @@
+package notifications
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"encoding/json"
+	"time"
+)
+
+// Event types
+const (
+	EventTradeOpened       = "TRADE_OPENED"
+	EventTradeClosed       = "TRADE_CLOSED"
+	EventStopLossHit       = "SL_HIT"
+	EventTakeProfitHit     = "TP_HIT"
+	EventLiquidationRisk   = "LIQUIDATION_RISK"
+	EventKillSwitch        = "KILL_SWITCH"
+	EventPriceAlert        = "PRICE_ALERT"
+	EventReport            = "REPORT"
+	EventUnmanagedPosition = "UNMANAGED_POSITION"
+	EventVolatilityHalt    = "VOLATILITY_HALT"
+	EventPerformanceAlert  = "PERFORMANCE_ALERT"
+)
+
+// Event represents something users should be told about
+type Event struct {
+	Type    string      `json:"type"`
+	Title   string      `json:"title"`
+	Message string      `json:"message"`
+	Symbol  string      `json:"symbol,omitempty"`
+	TradeID string      `json:"tradeId,omitempty"`
+	UserID  string      `json:"userId,omitempty"` // Owner of the trade, for per-user preferences
+	Time    int64       `json:"time"`
+	Data    interface{} `json:"data,omitempty"` // Structured payload for rich channels (e.g. *models.SummaryReport)
+}
+
+// Channel delivers events to one destination (Telegram, email, ...)
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, event *Event) error
+}
+
+// Queue hands events to delivery workers, possibly on another server
+// instance, and retries failed deliveries (Redis)
+type Queue interface {
+	Enqueue(ctx context.Context, queue string, payload interface{}) error
+	Handle(queue string, handler func(ctx context.Context, payload []byte) error)
+}
+
+// notificationQueue is the queue events wait in for delivery
+const notificationQueue = "notifications"
+
+// queuedEvent is an event waiting for delivery through one channel
+type queuedEvent struct {
+	Channel string `json:"channel"`
+	Event   *Event `json:"event"`
+}
+
+// registeredChannel is a channel plus the events it is limited to (nil = all)
+type registeredChannel struct {
+	Channel
+	events map[string]bool
+}
+
+// Notifier fans events out to every registered channel. A nil Notifier is
+// valid and drops all events, so callers don't need to check for it.
+type Notifier struct {
+	channels []registeredChannel
+	enabled  map[string]bool
+	queue    Queue
+	muted    func(userID, eventType string) bool
+}
+
+// NewNotifier creates a notifier; events missing from enabled are sent by default
+func NewNotifier(enabled map[string]bool) *Notifier {
+	if enabled == nil {
+		enabled = make(map[string]bool)
+	}
+	return &Notifier{enabled: enabled}
+}
+
+// AddChannel registers a delivery channel, optionally limited to some event types
+func (n *Notifier) AddChannel(ch Channel, events ...string) {
+	registered := registeredChannel{Channel: ch}
+	if len(events) > 0 {
+		registered.events = make(map[string]bool, len(events))
+		for _, event := range events {
+			registered.events[event] = true
+		}
+	}
+
+	n.channels = append(n.channels, registered)
+	logging.Info().Msgf("Notification channel enabled: %s", ch.Name())
+}
+
+// UseQueue delivers events through a queue: Publish enqueues one job per
+// channel, and workers on any instance send it, retrying failed deliveries.
+// Every instance must register the same channels.
+func (n *Notifier) UseQueue(queue Queue) {
+	n.queue = queue
+	queue.Handle(notificationQueue, n.deliverQueued)
+}
+
+// UsePreferences drops events a user muted. muted is asked about every
+// event that belongs to a user's trade.
+func (n *Notifier) UsePreferences(muted func(userID, eventType string) bool) {
+	n.muted = muted
+}
+
+// deliverQueued sends a queued event through its channel
+func (n *Notifier) deliverQueued(ctx context.Context, payload []byte) error {
+	var queued queuedEvent
+	if err := json.Unmarshal(payload, &queued); err != nil || queued.Event == nil {
+		logging.Warn().Err(err).Msg("Dropping malformed queued notification")
+		return nil
+	}
+
+	for _, ch := range n.channels {
+		if ch.Name() != queued.Channel {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		if err := ch.Send(ctx, queued.Event); err != nil {
+			logging.Warn().Err(err).Msgf("Failed to send %s notification via %s", queued.Event.Type, ch.Name())
+			return err
+		}
+		return nil
+	}
+
+	logging.Warn().Msgf("Dropping queued %s notification for unknown channel %s", queued.Event.Type, queued.Channel)
+	return nil
+}
+
+// wants reports whether a channel receives an event type
+func (c registeredChannel) wants(eventType string) bool {
+	return c.events == nil || c.events[eventType]
+}
+
+// IsEnabled reports whether events of this type are delivered
+func (n *Notifier) IsEnabled(eventType string) bool {
+	if n == nil || len(n.channels) == 0 {
+		return false
+	}
+	enabled, ok := n.enabled[eventType]
+	return !ok || enabled
+}
+
+// Publish sends an event to all channels in the background
+func (n *Notifier) Publish(event *Event) {
+	if !n.IsEnabled(event.Type) {
+		return
+	}
+	if event.UserID != "" && n.muted != nil && n.muted(event.UserID, event.Type) {
+		return
+	}
+	if event.Time == 0 {
+		event.Time = time.Now().Unix()
+	}
+
+	for _, ch := range n.channels {
+		if !ch.wants(event.Type) {
+			continue
+		}
+
+		if n.queue != nil {
+			err := n.queue.Enqueue(context.Background(), notificationQueue, queuedEvent{Channel: ch.Name(), Event: event})
+			if err == nil {
+				continue
+			}
+			logging.Warn().Err(err).Msgf("Failed to queue %s notification, sending directly", event.Type)
+		}
+
+		go func(ch Channel) {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+
+			if err := ch.Send(ctx, event); err != nil {
+				logging.Warn().Err(err).Msgf("Failed to send %s notification via %s", event.Type, ch.Name())
+			}
+		}(ch.Channel)
+	}
+}
+
+// NotifyReport sends a scheduled summary report and waits for delivery
+func (n *Notifier) NotifyReport(ctx context.Context, report *models.SummaryReport, text string) error {
+	if !n.IsEnabled(EventReport) {
+		return nil
+	}
+
+	event := &Event{
+		Type:    EventReport,
+		Title:   "📊 Trading summary",
+		Message: text,
+		Time:    time.Now().Unix(),
+		Data:    report,
+	}
+
+	var lastErr error
+	for _, ch := range n.channels {
+		if !ch.wants(EventReport) {
+			continue
+		}
+		if err := ch.Send(ctx, event); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,42 @@
+package notifications
+
+import (
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/models"
+)
+
+// Listen publishes notifications for trade, order and risk events on the bus
+func (n *Notifier) Listen(bus *events.Bus) {
+	bus.Subscribe(events.TopicTradeOpened, "notifier", func(event events.Event) {
+		n.Publish(TradeOpened(event.(events.TradeOpened).Trade))
+	})
+
+	bus.Subscribe(events.TopicOrderFilled, "notifier", func(event events.Event) {
+		order := event.(events.OrderFilled).Order
+		switch order.ProtectiveType() {
+		case "STOP_MARKET", "STOP":
+			n.Publish(StopLossHit(order.Symbol, order.AvgPrice, order.RealizedPnL))
+		case "TAKE_PROFIT_MARKET", "TAKE_PROFIT":
+			n.Publish(TakeProfitHit(order.Symbol, order.AvgPrice, order.RealizedPnL))
+		}
+	})
+
+	bus.Subscribe(events.TopicPositionClosed, "notifier", func(event events.Event) {
+		closed := event.(events.PositionClosed)
+		// SL/TP fills are announced as STOP_LOSS_HIT/TAKE_PROFIT_HIT above
+		if closed.Reason == events.CloseStopLoss || closed.Reason == events.CloseTakeProfit {
+			return
+		}
+
+		trade := closed.Trade
+		if trade == nil {
+			trade = &models.Trade{Symbol: closed.Symbol, PnL: closed.RealizedPnL}
+		}
+		n.Publish(TradeClosed(trade))
+	})
+
+	bus.Subscribe(events.TopicRiskWarning, "notifier", func(event events.Event) {
+		warning := event.(events.RiskWarning)
+		n.Publish(LiquidationRisk(warning.Symbol, warning.MarkPrice, warning.LiquidationPrice, warning.Distance, warning.Action))
+	})
+}
@@ -0,0 +1,81 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TelegramChannel sends events as Telegram bot messages
+type TelegramChannel struct {
+	token      string
+	chatID     string
+	httpClient *http.Client
+}
+
+// NewTelegramChannel creates a Telegram channel for a bot token and chat ID
+func NewTelegramChannel(token, chatID string) *TelegramChannel {
+	return &TelegramChannel{
+		token:      token,
+		chatID:     chatID,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the channel name
+func (t *TelegramChannel) Name() string {
+	return "telegram"
+}
+
+// Send posts the event to the configured chat
+func (t *TelegramChannel) Send(ctx context.Context, event *Event) error {
+	return t.SendMessage(ctx, t.chatID, event.Title+"\n"+event.Message)
+}
+
+// SendMessage posts plain text to a chat
+func (t *TelegramChannel) SendMessage(ctx context.Context, chatID, text string) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal telegram message: %v", err)
+	}
+
+	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.token)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create telegram request: %v", StripURL(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telegram message: %v", StripURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("telegram API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// StripURL drops the request URL from an HTTP client error. Telegram API
+// URLs hold the bot token, which must not end up in logs.
+func StripURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package policy
+
+import (
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TradingPause is a server-wide switch that stops new trade intake
+type TradingPause struct {
+	status models.TradingPauseStatus
+	mu     sync.RWMutex
+}
+
+// NewTradingPause creates a pause switch in the running state
+func NewTradingPause() *TradingPause {
+	return &TradingPause{}
+}
+
+// Pause stops new trade intake until Resume is called
+func (p *TradingPause) Pause(reason, by string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.status = models.TradingPauseStatus{
+		Paused:   true,
+		Reason:   reason,
+		PausedBy: by,
+		Since:    time.Now().Unix(),
+	}
+}
+
+// Resume re-enables trade intake
+func (p *TradingPause) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.status = models.TradingPauseStatus{}
+}
+
+// Status returns the current pause state
+func (p *TradingPause) Status() models.TradingPauseStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.status
+}
+
+// Check returns an error while trading is paused
+func (p *TradingPause) Check() error {
+	status := p.Status()
+	if !status.Paused {
+		return nil
+	}
+	if status.Reason != "" {
+		return fmt.Errorf("trading is paused: %s", status.Reason)
+	}
+	return fmt.Errorf("trading is paused")
+}
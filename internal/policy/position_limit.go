@@ -0,0 +1,405 @@
+package policy
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto-trading-api/internal/tradehistory"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Position limit modes
+const (
+	LimitModeReject = "REJECT"
+	LimitModeQueue  = "QUEUE"
+)
+
+// TradeSource provides the trades used to count open positions and the
+// presets holding per-strategy limits
+type TradeSource interface {
+	GetAllTrades(ctx context.Context) ([]*models.Trade, error)
+	UpdateTrade(ctx context.Context, trade *models.Trade) error
+	GetStrategyPreset(ctx context.Context, name string) (*models.StrategyPreset, error)
+}
+
+// PositionSource provides live exchange positions
+type PositionSource interface {
+	OperatorSymbols(ctx context.Context) ([]string, error)
+	UserSymbols(ctx context.Context, userID string) ([]string, error) // None when the user trades on the operator account
+}
+
+// reservationTTL bounds how long a slot taken for a trade being placed is
+// kept; placement finishes long before
+const reservationTTL = 10 * time.Minute
+
+// PositionLimit enforces the maxConcurrentPositions policy per user, and the
+// maxPositions of strategy presets per preset
+type PositionLimit struct {
+	max       int
+	mode      string
+	queueTTL  time.Duration
+	trades    TradeSource
+	positions PositionSource
+	reserved  map[string]reservation // Trade ID -> slot taken while the trade is placed
+	stopChan  chan struct{}
+	mu        sync.RWMutex
+	slotMu    sync.Mutex
+}
+
+// reservation is a slot taken by a trade between the capacity check and its
+// stored result
+type reservation struct {
+	userID string
+	preset string
+	status string // Status of the trade when reserved; the slot counts until the stored trade leaves it
+	at     time.Time
+}
+
+// NewPositionLimit creates a position limit (max <= 0 disables the per-user
+// cap; preset limits still apply)
+func NewPositionLimit(max int, mode string, queueTTL time.Duration, trades TradeSource, positions PositionSource) *PositionLimit {
+	if mode != LimitModeQueue {
+		mode = LimitModeReject
+	}
+
+	return &PositionLimit{
+		max:       max,
+		mode:      mode,
+		queueTTL:  queueTTL,
+		trades:    trades,
+		positions: positions,
+		reserved:  make(map[string]reservation),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Enabled reports whether the per-user limit is active
+func (l *PositionLimit) Enabled() bool {
+	return l != nil && l.Max() > 0
+}
+
+// Mode returns REJECT or QUEUE
+func (l *PositionLimit) Mode() string {
+	return l.mode
+}
+
+// Max returns the configured cap
+func (l *PositionLimit) Max() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.max
+}
+
+// SetMax changes the cap and the queue timeout (config reload). The mode
+// is fixed at startup, since it decides whether the queue drain runs.
+func (l *PositionLimit) SetMax(max int, queueTTL time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.max = max
+	l.queueTTL = queueTTL
+}
+
+// queueTimeout returns how long a queued trade may wait for a slot
+func (l *PositionLimit) queueTimeout() time.Duration {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.queueTTL
+}
+
+// openPositions counts open positions per user and per preset: open trades,
+// plus live positions no open trade holds. Those on a user's own account are
+// the user's; those on the operator account belong to the user whose trade
+// last held the symbol there, or to nobody when the API never traded it
+// (adopting the position, POST /api/admin/positions/adopt, assigns it).
+type openPositions struct {
+	users    map[string]int
+	presets  map[string]int
+	held     map[string]map[string]bool // Account -> symbols held by open trades
+	lastUser map[string]string          // Symbol -> user of the newest trade on the operator account
+	stored   map[string]string          // Trade ID -> stored status
+	live     map[string]bool            // Accounts whose live positions are counted
+}
+
+// countOpen counts the open trades among trades
+func countOpen(trades []*models.Trade) *openPositions {
+	open := &openPositions{
+		users:    make(map[string]int),
+		presets:  make(map[string]int),
+		held:     make(map[string]map[string]bool),
+		lastUser: make(map[string]string),
+		stored:   make(map[string]string),
+		live:     make(map[string]bool),
+	}
+
+	newest := make(map[string]int64)
+	for _, trade := range trades {
+		open.stored[trade.ID] = trade.Status
+		if trade.Account == "" && trade.CreatedAt >= newest[trade.Symbol] {
+			newest[trade.Symbol] = trade.CreatedAt
+			open.lastUser[trade.Symbol] = trade.UserID
+		}
+		if holdsSlot(trade) {
+			open.add(trade)
+		}
+	}
+	return open
+}
+
+// holdsSlot reports whether a trade takes one of its user's slots: an open
+// (ACTIVE or FILLED) trade on the operator account or the user's own.
+// Follower account copies live on other accounts and don't use the user's
+// slots.
+func holdsSlot(trade *models.Trade) bool {
+	return (trade.Status == "ACTIVE" || trade.Status == "FILLED") &&
+		(trade.Account == "" || trade.Account == models.UserAccount(trade.UserID))
+}
+
+// add counts one more open trade
+func (o *openPositions) add(trade *models.Trade) {
+	o.users[trade.UserID]++
+	if trade.Preset != "" {
+		o.presets[trade.Preset]++
+	}
+	if o.held[trade.Account] == nil {
+		o.held[trade.Account] = make(map[string]bool)
+	}
+	o.held[trade.Account][trade.Symbol] = true
+}
+
+// countLive adds the live positions of the operator account and of the
+// user's own account that no open trade holds. Each account is read once
+// per snapshot.
+func (l *PositionLimit) countLive(ctx context.Context, open *openPositions, userID string) error {
+	if l.positions == nil {
+		return nil
+	}
+
+	if !open.live[""] {
+		symbols, err := l.positions.OperatorSymbols(ctx)
+		if err != nil {
+			return err
+		}
+		open.live[""] = true
+		for _, symbol := range symbols {
+			if owner := open.lastUser[symbol]; owner != "" && !open.held[""][symbol] {
+				open.users[owner]++
+			}
+		}
+	}
+
+	account := models.UserAccount(userID)
+	if !open.live[account] {
+		symbols, err := l.positions.UserSymbols(ctx, userID)
+		if err != nil {
+			return err
+		}
+		open.live[account] = true
+		for _, symbol := range symbols {
+			if !open.held[account][symbol] {
+				open.users[userID]++
+			}
+		}
+	}
+	return nil
+}
+
+// reserve takes a slot for trade if the user's and the preset's limits
+// (presetMax, 0 for none) allow it, counting the slots reserved by trades
+// the snapshot does not show placed yet. When not, it says which limit is
+// reached.
+func (l *PositionLimit) reserve(open *openPositions, trade *models.Trade, presetMax int) (bool, string) {
+	l.slotMu.Lock()
+	defer l.slotMu.Unlock()
+
+	users, presets := open.users[trade.UserID], open.presets[trade.Preset]
+	now := time.Now()
+	for tradeID, r := range l.reserved {
+		if now.Sub(r.at) > reservationTTL {
+			delete(l.reserved, tradeID)
+			continue
+		}
+		if status, stored := open.stored[tradeID]; stored && status != r.status {
+			continue // Counted as stored
+		}
+		if r.userID == trade.UserID {
+			users++
+		}
+		if trade.Preset != "" && r.preset == trade.Preset {
+			presets++
+		}
+	}
+
+	if max := l.Max(); max > 0 && users >= max {
+		return false, fmt.Sprintf("%d of %d concurrent positions open", users, max)
+	}
+	if trade.Preset != "" && presetMax > 0 && presets >= presetMax {
+		return false, fmt.Sprintf("%d of %d positions of preset %q open", presets, presetMax, trade.Preset)
+	}
+
+	l.reserved[trade.ID] = reservation{userID: trade.UserID, preset: trade.Preset, status: trade.Status, at: now}
+	return true, ""
+}
+
+// Reserve takes a slot for a trade about to be placed if the user may open
+// another position, with the preset's own limit (preset nil for none). When
+// not, it says which limit is reached. The slot is held until the trade is
+// stored with another status, so concurrent signals cannot overshoot the
+// limits; call Release if the trade is not placed.
+func (l *PositionLimit) Reserve(ctx context.Context, trade *models.Trade, preset *models.StrategyPreset) (bool, string, error) {
+	presetMax := 0
+	if preset != nil {
+		presetMax = preset.MaxPositions
+	}
+	if !l.Enabled() && presetMax <= 0 {
+		return true, "", nil
+	}
+
+	trades, err := l.trades.GetAllTrades(ctx)
+	if err != nil {
+		return false, "", err
+	}
+	open := countOpen(trades)
+	if err := l.countLive(ctx, open, trade.UserID); err != nil {
+		return false, "", err
+	}
+
+	ok, reason := l.reserve(open, trade, presetMax)
+	return ok, reason, nil
+}
+
+// Release frees the slot reserved for a trade that was not placed
+func (l *PositionLimit) Release(tradeID string) {
+	if l == nil {
+		return
+	}
+
+	l.slotMu.Lock()
+	defer l.slotMu.Unlock()
+	delete(l.reserved, tradeID)
+}
+
+// StartQueueDrain periodically places QUEUED trades as slots free up and as
+// the trading hours they wait for open. It may be started again after
+// StopQueueDrain.
+func (l *PositionLimit) StartQueueDrain(interval time.Duration, execute func(ctx context.Context, trade *models.Trade) error) {
+	stop := make(chan struct{})
+	l.stopChan = stop
+
+	logging.Info().Msgf("Position queue drain started (max=%d, interval=%v)", l.Max(), interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				l.drainQueue(execute)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopQueueDrain stops the queue drain worker
+func (l *PositionLimit) StopQueueDrain() {
+	close(l.stopChan)
+}
+
+// drainQueue places queued trades oldest-first while users have capacity.
+// Trades waiting for trading hours are placed once they open; their queue
+// timeout counts from then.
+func (l *PositionLimit) drainQueue(execute func(ctx context.Context, trade *models.Trade) error) {
+	ctx := tradehistory.WithSource(context.Background(), tradehistory.SourcePositionQueue)
+
+	trades, err := l.trades.GetAllTrades(ctx)
+	if err != nil {
+		logging.Warn().Err(err).Msg("Queue drain: failed to get trades")
+		return
+	}
+
+	queued := []*models.Trade{}
+	for _, trade := range trades {
+		if trade.Status == "QUEUED" {
+			queued = append(queued, trade)
+		}
+	}
+
+	sort.Slice(queued, func(i, j int) bool {
+		return queued[i].CreatedAt < queued[j].CreatedAt
+	})
+
+	// Counted once per tick from the trades already loaded; the slots of
+	// trades placed meanwhile are reserved
+	open := countOpen(trades)
+	presetMax := make(map[string]int)
+
+	queueTTL := l.queueTimeout()
+	now := time.Now()
+	for _, trade := range queued {
+		if trade.QueuedUntil > now.Unix() {
+			continue
+		}
+
+		// Expire stale signals instead of executing them late
+		waitingSince := max(trade.CreatedAt, trade.QueuedUntil)
+		if queueTTL > 0 && now.Sub(time.Unix(waitingSince, 0)) > queueTTL {
+			trade.Status = "EXPIRED"
+			trade.Error = "position slot did not free up before queue timeout"
+			trade.ClosedAt = time.Now().Unix()
+			if err := l.trades.UpdateTrade(ctx, trade); err != nil {
+				logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("Queue drain: failed to expire trade %s", trade.ID)
+			}
+			continue
+		}
+
+		if trade.Preset != "" {
+			if _, ok := presetMax[trade.Preset]; !ok {
+				preset, err := l.trades.GetStrategyPreset(ctx, trade.Preset)
+				if err != nil {
+					logging.Warn().Err(err).Msgf("Queue drain: failed to load preset %q", trade.Preset)
+					continue
+				}
+				presetMax[trade.Preset] = 0
+				if preset != nil {
+					presetMax[trade.Preset] = preset.MaxPositions
+				}
+			}
+		}
+
+		limited := l.Enabled() || presetMax[trade.Preset] > 0
+		if limited {
+			if err := l.countLive(ctx, open, trade.UserID); err != nil {
+				logging.Warn().Err(err).Msgf("Queue drain: failed to get positions for %s", trade.UserID)
+				continue
+			}
+		}
+
+		if ok, _ := l.reserve(open, trade, presetMax[trade.Preset]); !ok {
+			// Only trading hours queue trades in REJECT mode: refuse them
+			// like a trade submitted now
+			if l.Mode() != LimitModeQueue {
+				trade.Status = "FAILED"
+				trade.Error = "maximum concurrent positions reached when trading hours opened"
+				trade.ClosedAt = now.Unix()
+				if err := l.trades.UpdateTrade(ctx, trade); err != nil {
+					logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("Queue drain: failed to reject trade %s", trade.ID)
+				}
+			}
+			continue
+		}
+
+		logging.Info().Str(logging.FieldTradeID, trade.ID).Str(logging.FieldSymbol, trade.Symbol).Msgf("Queue drain: placing queued trade %s (%s %s)", trade.ID, trade.Side, trade.Symbol)
+		if err := execute(ctx, trade); err != nil {
+			logging.Error().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("Queue drain: trade %s failed", trade.ID)
+			if trade.Status != "ACTIVE" {
+				l.Release(trade.ID) // Not placed
+			}
+		}
+	}
+}
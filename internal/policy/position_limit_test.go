@@ -0,0 +1,211 @@
+package policy
+
+import (
+	"context"
+	"crypto-trading-api/internal/models"
+	"testing"
+	"time"
+)
+
+// fakeTrades serves a fixed set of trades and presets
+type fakeTrades struct {
+	trades  []*models.Trade
+	presets map[string]*models.StrategyPreset
+}
+
+func (f *fakeTrades) GetAllTrades(ctx context.Context) ([]*models.Trade, error) {
+	return f.trades, nil
+}
+
+func (f *fakeTrades) UpdateTrade(ctx context.Context, trade *models.Trade) error {
+	return nil
+}
+
+func (f *fakeTrades) GetStrategyPreset(ctx context.Context, name string) (*models.StrategyPreset, error) {
+	return f.presets[name], nil
+}
+
+// fakePositions serves fixed live positions
+type fakePositions struct {
+	operator []string
+	users    map[string][]string
+}
+
+func (f *fakePositions) OperatorSymbols(ctx context.Context) ([]string, error) {
+	return f.operator, nil
+}
+
+func (f *fakePositions) UserSymbols(ctx context.Context, userID string) ([]string, error) {
+	return f.users[userID], nil
+}
+
+func TestPositionLimitSlotCounting(t *testing.T) {
+	tests := []struct {
+		name      string
+		max       int
+		trades    []*models.Trade
+		positions *fakePositions
+		preset    *models.StrategyPreset
+		want      bool
+	}{
+		{
+			name:   "under limit",
+			max:    2,
+			trades: []*models.Trade{{ID: "a", UserID: "u1", Symbol: "BTCUSDT", Status: "ACTIVE"}},
+			want:   true,
+		},
+		{
+			name: "active and filled count",
+			max:  2,
+			trades: []*models.Trade{
+				{ID: "a", UserID: "u1", Symbol: "BTCUSDT", Status: "ACTIVE"},
+				{ID: "b", UserID: "u1", Symbol: "ETHUSDT", Status: "FILLED"},
+			},
+			want: false,
+		},
+		{
+			name: "finished trades don't count",
+			max:  1,
+			trades: []*models.Trade{
+				{ID: "a", UserID: "u1", Symbol: "BTCUSDT", Status: "CLOSED"},
+				{ID: "b", UserID: "u1", Symbol: "ETHUSDT", Status: "CANCELED"},
+				{ID: "c", UserID: "u1", Symbol: "SOLUSDT", Status: "FAILED"},
+				{ID: "d", UserID: "u1", Symbol: "XRPUSDT", Status: "QUEUED"},
+			},
+			want: true,
+		},
+		{
+			name:   "other users don't count",
+			max:    1,
+			trades: []*models.Trade{{ID: "a", UserID: "u2", Symbol: "BTCUSDT", Status: "ACTIVE"}},
+			want:   true,
+		},
+		{
+			name:   "follower copies don't count",
+			max:    1,
+			trades: []*models.Trade{{ID: "a", UserID: "u1", Symbol: "BTCUSDT", Status: "ACTIVE", Account: "follower-1"}},
+			want:   true,
+		},
+		{
+			name:   "own account trades count",
+			max:    1,
+			trades: []*models.Trade{{ID: "a", UserID: "u1", Symbol: "BTCUSDT", Status: "ACTIVE", Account: models.UserAccount("u1")}},
+			want:   false,
+		},
+		{
+			name:      "live position held by a trade counted once",
+			max:       2,
+			trades:    []*models.Trade{{ID: "a", UserID: "u1", Symbol: "BTCUSDT", Status: "ACTIVE"}},
+			positions: &fakePositions{operator: []string{"BTCUSDT"}},
+			want:      true,
+		},
+		{
+			name:      "operator position of a closed trade counts for its user",
+			max:       1,
+			trades:    []*models.Trade{{ID: "a", UserID: "u1", Symbol: "BTCUSDT", Status: "CLOSED"}},
+			positions: &fakePositions{operator: []string{"BTCUSDT"}},
+			want:      false,
+		},
+		{
+			name: "operator position counts for the newest trade's user",
+			max:  1,
+			trades: []*models.Trade{
+				{ID: "a", UserID: "u1", Symbol: "BTCUSDT", Status: "CLOSED", CreatedAt: 100},
+				{ID: "b", UserID: "u2", Symbol: "BTCUSDT", Status: "CLOSED", CreatedAt: 200},
+			},
+			positions: &fakePositions{operator: []string{"BTCUSDT"}},
+			want:      true,
+		},
+		{
+			name:      "never traded operator position counts for nobody",
+			max:       1,
+			positions: &fakePositions{operator: []string{"BTCUSDT"}},
+			want:      true,
+		},
+		{
+			name:      "own account position counts",
+			max:       1,
+			positions: &fakePositions{users: map[string][]string{"u1": {"BTCUSDT"}}},
+			want:      false,
+		},
+		{
+			name:   "preset limit",
+			trades: []*models.Trade{{ID: "a", UserID: "u2", Symbol: "BTCUSDT", Status: "ACTIVE", Preset: "scalp"}},
+			preset: &models.StrategyPreset{Name: "scalp", MaxPositions: 1},
+			want:   false,
+		},
+		{
+			name:   "other presets don't count",
+			trades: []*models.Trade{{ID: "a", UserID: "u1", Symbol: "BTCUSDT", Status: "ACTIVE", Preset: "swing"}},
+			preset: &models.StrategyPreset{Name: "scalp", MaxPositions: 1},
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var positions PositionSource
+			if tt.positions != nil {
+				positions = tt.positions
+			}
+			limit := NewPositionLimit(tt.max, LimitModeReject, 0, &fakeTrades{trades: tt.trades}, positions)
+
+			trade := &models.Trade{ID: "new", UserID: "u1", Symbol: "ETHUSDT", Status: "PENDING"}
+			if tt.preset != nil {
+				trade.Preset = tt.preset.Name
+			}
+			ok, reason, err := limit.Reserve(context.Background(), trade, tt.preset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.want {
+				t.Errorf("Reserve = %v (%s), want %v", ok, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestPositionLimitReservation(t *testing.T) {
+	ctx := context.Background()
+	trades := &fakeTrades{}
+	limit := NewPositionLimit(1, LimitModeReject, 0, trades, nil)
+
+	reserve := func(id string) bool {
+		t.Helper()
+		ok, _, err := limit.Reserve(ctx, &models.Trade{ID: id, UserID: "u1", Status: "PENDING"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	// Concurrent signals: the second sees the first's slot before it is stored
+	if !reserve("a") {
+		t.Fatal("first trade rejected")
+	}
+	if reserve("b") {
+		t.Fatal("second trade reserved past the limit")
+	}
+
+	// A failed placement gives the slot back
+	limit.Release("a")
+	if !reserve("b") {
+		t.Fatal("slot not released")
+	}
+
+	// Stored as placed, the trade holds the slot instead of its reservation
+	trades.trades = []*models.Trade{{ID: "b", UserID: "u1", Status: "ACTIVE"}}
+	if reserve("c") {
+		t.Fatal("placed trade's slot counted as free")
+	}
+	trades.trades[0].Status = "CLOSED"
+	if !reserve("c") {
+		t.Fatal("closed trade still holds its slot")
+	}
+
+	// Stale reservations expire
+	limit.reserved["c"] = reservation{userID: "u1", status: "PENDING", at: time.Now().Add(-2 * reservationTTL)}
+	if !reserve("d") {
+		t.Fatal("stale reservation still counted")
+	}
+}
@@ -0,0 +1,203 @@
+package policy
+
+import (
+	"crypto-trading-api/internal/models"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrOutsideTradingHours is returned for a signal outside its preset's
+// trading hours
+var ErrOutsideTradingHours = errors.New("outside trading hours")
+
+// sessionHorizon is how far ahead the next opening of trading hours is
+// looked for; hours that stay closed longer never open
+const sessionHorizon = 8 * 24 * time.Hour
+
+// weekdays maps the accepted weekday names to time.Weekday
+var weekdays = map[string]time.Weekday{
+	"SUN": time.Sunday,
+	"MON": time.Monday,
+	"TUE": time.Tuesday,
+	"WED": time.Wednesday,
+	"THU": time.Thursday,
+	"FRI": time.Friday,
+	"SAT": time.Saturday,
+}
+
+// session is parsed trading hours
+type session struct {
+	windows   [][2]int // Start and end, in minutes after midnight UTC
+	weekdays  map[time.Weekday]bool
+	blackouts []models.Blackout
+}
+
+// NormalizeTradingHours upper-cases weekday names and the outside mode, and
+// checks that the trading hours parse
+func NormalizeTradingHours(hours *models.TradingHours) error {
+	hours.Outside = strings.ToUpper(hours.Outside)
+	if hours.Outside == "" {
+		hours.Outside = models.OutsideHoursReject
+	}
+	if hours.Outside != models.OutsideHoursReject && hours.Outside != models.OutsideHoursQueue {
+		return fmt.Errorf("tradingHours.outside must be %s or %s", models.OutsideHoursReject, models.OutsideHoursQueue)
+	}
+	for i, day := range hours.Weekdays {
+		hours.Weekdays[i] = strings.ToUpper(strings.TrimSpace(day))
+	}
+
+	_, err := parseSession(hours)
+	return err
+}
+
+// parseSession parses trading hours
+func parseSession(hours *models.TradingHours) (*session, error) {
+	s := &session{blackouts: hours.Blackouts}
+
+	for _, window := range hours.Windows {
+		start, err := parseClock(window.Start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window start %q: %v", window.Start, err)
+		}
+		end, err := parseClock(window.End)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window end %q: %v", window.End, err)
+		}
+		if start == end {
+			return nil, fmt.Errorf("window %s-%s is empty", window.Start, window.End)
+		}
+		s.windows = append(s.windows, [2]int{start, end})
+	}
+
+	if len(hours.Weekdays) > 0 {
+		s.weekdays = make(map[time.Weekday]bool)
+		for _, name := range hours.Weekdays {
+			day, ok := weekdays[strings.ToUpper(name)]
+			if !ok {
+				return nil, fmt.Errorf("invalid weekday %q (use MON, TUE, WED, THU, FRI, SAT or SUN)", name)
+			}
+			s.weekdays[day] = true
+		}
+	}
+
+	for _, blackout := range hours.Blackouts {
+		if blackout.To <= blackout.From {
+			return nil, fmt.Errorf("blackout %s ends before it starts", blackout.Reason)
+		}
+	}
+	return s, nil
+}
+
+// parseClock parses HH:MM into minutes after midnight
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// CheckTradingHours returns nil when trading hours are open at now.
+// Otherwise it returns an error wrapping ErrOutsideTradingHours and the time
+// they next open, zero when they stay closed for more than a week.
+func CheckTradingHours(hours *models.TradingHours, now time.Time) (time.Time, error) {
+	s, err := parseSession(hours)
+	if err != nil {
+		return time.Time{}, err
+	}
+	now = now.UTC()
+
+	reason := s.closedReason(now)
+	if reason == "" {
+		return time.Time{}, nil
+	}
+
+	opensAt := s.nextOpen(now)
+	if opensAt.IsZero() {
+		return opensAt, fmt.Errorf("%w: %s, and they stay closed for over a week", ErrOutsideTradingHours, reason)
+	}
+	return opensAt, fmt.Errorf("%w: %s, they open at %s", ErrOutsideTradingHours, reason, opensAt.Format(time.RFC3339))
+}
+
+// closedReason says why trading hours are closed at t, "" when open
+func (s *session) closedReason(t time.Time) string {
+	for _, blackout := range s.blackouts {
+		if t.Unix() >= blackout.From && t.Unix() < blackout.To {
+			reason := "blackout"
+			if blackout.Reason != "" {
+				reason += " (" + blackout.Reason + ")"
+			}
+			return reason + " until " + time.Unix(blackout.To, 0).UTC().Format(time.RFC3339)
+		}
+	}
+
+	if len(s.windows) == 0 {
+		if !s.tradingDay(t.Weekday()) {
+			return "no trading on " + strings.ToUpper(t.Weekday().String()[:3])
+		}
+		return ""
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	for _, window := range s.windows {
+		start, end := window[0], window[1]
+		switch {
+		case start < end && minute >= start && minute < end:
+			if s.tradingDay(t.Weekday()) {
+				return ""
+			}
+		case start > end && minute >= start:
+			if s.tradingDay(t.Weekday()) {
+				return ""
+			}
+		case start > end && minute < end:
+			// Past midnight: the window started the day before
+			if s.tradingDay(t.AddDate(0, 0, -1).Weekday()) {
+				return ""
+			}
+		}
+	}
+	return "no trading window at " + t.Format("Mon 15:04") + " UTC"
+}
+
+// tradingDay reports whether windows may start on a weekday
+func (s *session) tradingDay(day time.Weekday) bool {
+	return s.weekdays == nil || s.weekdays[day]
+}
+
+// nextOpen returns the first time after t trading hours are open, zero if
+// not within the horizon. Hours can only open at a window start (midnight
+// without windows) or at the end of a blackout, so only those are tried.
+func (s *session) nextOpen(t time.Time) time.Time {
+	starts := []int{0}
+	if len(s.windows) > 0 {
+		starts = starts[:0]
+		for _, window := range s.windows {
+			starts = append(starts, window[0])
+		}
+	}
+
+	var candidates []time.Time
+	midnight := t.Truncate(24 * time.Hour)
+	for day := midnight; day.Before(t.Add(sessionHorizon)); day = day.AddDate(0, 0, 1) {
+		for _, start := range starts {
+			candidates = append(candidates, day.Add(time.Duration(start)*time.Minute))
+		}
+	}
+	for _, blackout := range s.blackouts {
+		candidates = append(candidates, time.Unix(blackout.To, 0).UTC())
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Before(candidates[j])
+	})
+
+	for _, candidate := range candidates {
+		if candidate.After(t) && candidate.Before(t.Add(sessionHorizon)) && s.closedReason(candidate) == "" {
+			return candidate
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,85 @@
+package policy
+
+import (
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SymbolPolicy decides which symbols may be traded
+type SymbolPolicy struct {
+	allow map[string]bool
+	block map[string]bool
+	mu    sync.RWMutex
+}
+
+// NewSymbolPolicy creates a symbol policy from allow/block lists
+func NewSymbolPolicy(allowlist, blocklist []string) *SymbolPolicy {
+	p := &SymbolPolicy{}
+	p.Update(models.SymbolPolicyConfig{Allowlist: allowlist, Blocklist: blocklist})
+	return p
+}
+
+// Check returns an error if the symbol may not be traded
+func (p *SymbolPolicy) Check(symbol string) error {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.block[symbol] {
+		return fmt.Errorf("symbol %s is blocked for trading", symbol)
+	}
+
+	if len(p.allow) > 0 && !p.allow[symbol] {
+		return fmt.Errorf("symbol %s is not in the trading allowlist", symbol)
+	}
+
+	return nil
+}
+
+// Update replaces the allow/block lists
+func (p *SymbolPolicy) Update(cfg models.SymbolPolicyConfig) {
+	allow := toSet(cfg.Allowlist)
+	block := toSet(cfg.Blocklist)
+
+	p.mu.Lock()
+	p.allow = allow
+	p.block = block
+	p.mu.Unlock()
+}
+
+// Config returns the current allow/block lists
+func (p *SymbolPolicy) Config() models.SymbolPolicyConfig {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return models.SymbolPolicyConfig{
+		Allowlist: fromSet(p.allow),
+		Blocklist: fromSet(p.block),
+		UpdatedAt: time.Now().Unix(),
+	}
+}
+
+// toSet normalizes symbols into an uppercase lookup set
+func toSet(symbols []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, s := range symbols {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if s != "" {
+			set[s] = true
+		}
+	}
+	return set
+}
+
+// fromSet converts a lookup set back to a slice
+func fromSet(set map[string]bool) []string {
+	symbols := make([]string, 0, len(set))
+	for s := range set {
+		symbols = append(symbols, s)
+	}
+	return symbols
+}
@@ -0,0 +1,229 @@
+package push
+
+import (
+	"crypto-trading-api/internal/logging"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Message types pushed to clients
+const (
+	TypeTrade    = "trade"    // A trade was created or changed
+	TypePosition = "position" // Position change or mark price PnL tick
+	TypeBalance  = "balance"  // Wallet balance change
+)
+
+// Types lists every message type a client can subscribe to
+var Types = []string{TypeTrade, TypePosition, TypeBalance}
+
+// Connection timing
+const (
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = 50 * time.Second // Must be shorter than pongWait
+	sendBuffer = 64               // Messages queued per client before it is dropped as too slow
+)
+
+// Message is one update pushed to clients as JSON
+type Message struct {
+	Type   string      `json:"type" example:"position"`
+	UserID string      `json:"userId,omitempty" example:"user123"` // Owner of a trade ("" = operator account data)
+	Data   interface{} `json:"data"`
+	Time   int64       `json:"time" example:"1640995200"`
+}
+
+// Subscription selects the messages a client receives
+type Subscription struct {
+	UserID string          // Only this user's trades ("" = everything, including operator account data)
+	Types  map[string]bool // Message types (empty = all)
+}
+
+// wants reports whether a message matches the subscription
+func (s Subscription) wants(msg *Message) bool {
+	if len(s.Types) > 0 && !s.Types[msg.Type] {
+		return false
+	}
+	return s.UserID == "" || s.UserID == msg.UserID
+}
+
+// Hub fans messages out to connected WebSocket clients. A nil Hub is valid
+// and drops all messages.
+type Hub struct {
+	clients map[*client]struct{}
+	watch   func(active bool) // Called when the first position subscriber joins and the last leaves
+	mu      sync.Mutex
+}
+
+type client struct {
+	conn *websocket.Conn
+	sub  Subscription
+	send chan []byte
+	done chan struct{}
+	once sync.Once
+}
+
+// NewHub creates a push hub
+func NewHub() *Hub {
+	return &Hub{clients: make(map[*client]struct{})}
+}
+
+// OnPositionWatchers registers a callback told when position messages start
+// and stop having subscribers, so their sources only run while needed
+func (h *Hub) OnPositionWatchers(watch func(active bool)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.watch = watch
+}
+
+// Clients returns the number of connected clients
+func (h *Hub) Clients() int {
+	if h == nil {
+		return 0
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// Publish sends a message to every client subscribed to it. Clients that
+// cannot keep up are disconnected.
+func (h *Hub) Publish(msg *Message) {
+	if h == nil {
+		return
+	}
+	if msg.Time == 0 {
+		msg.Time = time.Now().Unix()
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		logging.Warn().Err(err).Msgf("Push: failed to encode %s message", msg.Type)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.clients {
+		if !c.sub.wants(msg) {
+			continue
+		}
+		select {
+		case c.send <- body:
+		default:
+			logging.Warn().Msgf("Push: dropping slow client %s", c.conn.RemoteAddr())
+			c.close()
+		}
+	}
+}
+
+// Serve registers a connection and pumps messages to it until it closes
+func (h *Hub) Serve(conn *websocket.Conn, sub Subscription) {
+	c := &client{conn: conn, sub: sub, send: make(chan []byte, sendBuffer), done: make(chan struct{})}
+
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	watch := h.watch != nil && c.wantsPositions() && h.positionWatchers() == 1
+	h.mu.Unlock()
+	if watch {
+		h.watch(true)
+	}
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		unwatch := h.watch != nil && c.wantsPositions() && h.positionWatchers() == 0
+		h.mu.Unlock()
+		if unwatch {
+			h.watch(false)
+		}
+	}()
+
+	go c.readPump()
+	c.writePump()
+}
+
+// Close disconnects every client, e.g. at shutdown (the HTTP server does not
+// track upgraded connections)
+func (h *Hub) Close() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.clients {
+		c.close()
+	}
+}
+
+// positionWatchers counts clients receiving position messages (h.mu held)
+func (h *Hub) positionWatchers() int {
+	count := 0
+	for c := range h.clients {
+		if c.wantsPositions() {
+			count++
+		}
+	}
+	return count
+}
+
+// wantsPositions reports whether the client receives operator position data
+func (c *client) wantsPositions() bool {
+	return c.sub.wants(&Message{Type: TypePosition})
+}
+
+// close ends the connection once; the pumps exit as a result
+func (c *client) close() {
+	c.once.Do(func() {
+		close(c.done)
+	})
+}
+
+// readPump discards client messages and notices when the connection drops
+func (c *client) readPump() {
+	defer c.close()
+
+	c.conn.SetReadLimit(512)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writePump writes queued messages and keep-alive pings
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case body := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, body); err != nil {
+				return
+			}
+		case <-c.done:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
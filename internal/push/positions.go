@@ -0,0 +1,192 @@
+package push
+
+import (
+	"context"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/events"
+	"crypto-trading-api/internal/logging"
+	"sync"
+	"time"
+)
+
+// tickInterval throttles mark price PnL ticks per position
+const tickInterval = time.Second
+
+// Position is the payload of position messages
+type Position struct {
+	Symbol           string  `json:"symbol" example:"BTCUSDT"`
+	PositionSide     string  `json:"positionSide" example:"BOTH"`
+	PositionAmt      float64 `json:"positionAmt" example:"0.02"` // 0 once the position is closed
+	EntryPrice       float64 `json:"entryPrice" example:"50000"`
+	MarkPrice        float64 `json:"markPrice,omitempty" example:"50250"`
+	UnrealizedProfit float64 `json:"unrealizedProfit" example:"5"`
+}
+
+// Balance is the payload of balance messages
+type Balance struct {
+	Asset              string  `json:"asset" example:"USDT"`
+	WalletBalance      float64 `json:"walletBalance" example:"1000"`
+	CrossWalletBalance float64 `json:"crossWalletBalance" example:"1000"`
+	Reason             string  `json:"reason,omitempty" example:"ORDER"` // Binance ACCOUNT_UPDATE reason
+}
+
+// PositionRelay pushes the operator account's position and balance changes
+// from the user data stream, plus PnL ticks from the shared mark price feed
+// while at least one client watches positions
+type PositionRelay struct {
+	hub         *Hub
+	client      *binance.Client
+	feed        *binance.PriceFeed
+	active      bool
+	positions   map[string]*Position // Symbol + side -> position
+	unsubscribe map[string]func()    // Symbol -> price feed subscription
+	lastTick    map[string]time.Time
+	mu          sync.Mutex
+}
+
+// NewPositionRelay creates a relay, ties it to the hub's position subscribers
+// and relays account updates from the bus
+func NewPositionRelay(hub *Hub, client *binance.Client, feed *binance.PriceFeed, bus *events.Bus) *PositionRelay {
+	r := &PositionRelay{
+		hub:         hub,
+		client:      client,
+		feed:        feed,
+		positions:   make(map[string]*Position),
+		unsubscribe: make(map[string]func()),
+		lastTick:    make(map[string]time.Time),
+	}
+	hub.OnPositionWatchers(func(active bool) {
+		if active {
+			r.start()
+		} else {
+			r.stop()
+		}
+	})
+	bus.Subscribe(events.TopicAccountUpdated, "push", func(event events.Event) {
+		r.accountUpdate(event.(events.AccountUpdated))
+	})
+	return r
+}
+
+// start loads the open positions and streams their mark prices
+func (r *PositionRelay) start() {
+	positions, err := r.client.GetOpenPositions(context.Background())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.active = true
+	if err != nil {
+		logging.Warn().Err(err).Msg("Push: failed to load positions")
+		return
+	}
+	for _, pos := range positions {
+		r.track(&Position{
+			Symbol:           pos.Symbol,
+			PositionSide:     pos.PositionSide,
+			PositionAmt:      pos.PositionAmt,
+			EntryPrice:       pos.EntryPrice,
+			MarkPrice:        pos.MarkPrice,
+			UnrealizedProfit: pos.UnrealizedProfit,
+		})
+	}
+	logging.Info().Msgf("Push: relaying %d positions", len(positions))
+}
+
+// stop closes the price subscriptions once nobody watches positions
+func (r *PositionRelay) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.active = false
+	for symbol, unsubscribe := range r.unsubscribe {
+		unsubscribe()
+		delete(r.unsubscribe, symbol)
+	}
+	r.positions = make(map[string]*Position)
+	r.lastTick = make(map[string]time.Time)
+}
+
+// accountUpdate relays an ACCOUNT_UPDATE event of the user data stream
+func (r *PositionRelay) accountUpdate(event events.AccountUpdated) {
+	for _, bal := range event.Balances {
+		r.hub.Publish(&Message{Type: TypeBalance, Data: &Balance{
+			Asset:              bal.Asset,
+			WalletBalance:      bal.WalletBalance,
+			CrossWalletBalance: bal.CrossWalletBalance,
+			Reason:             event.Reason,
+		}})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, update := range event.Positions {
+		pos := &Position{
+			Symbol:           update.Symbol,
+			PositionSide:     update.PositionSide,
+			PositionAmt:      update.PositionAmt,
+			EntryPrice:       update.EntryPrice,
+			UnrealizedProfit: update.UnrealizedPnL,
+		}
+		if r.active {
+			r.track(pos)
+		}
+		r.hub.Publish(&Message{Type: TypePosition, Data: pos})
+	}
+}
+
+// track records a position and keeps its symbol's price subscription in
+// step (r.mu held)
+func (r *PositionRelay) track(pos *Position) {
+	key := pos.Symbol + "|" + pos.PositionSide
+	if pos.PositionAmt == 0 {
+		delete(r.positions, key)
+	} else {
+		r.positions[key] = pos
+	}
+
+	open := false
+	for _, p := range r.positions {
+		if p.Symbol == pos.Symbol {
+			open = true
+			break
+		}
+	}
+
+	unsubscribe, subscribed := r.unsubscribe[pos.Symbol]
+	switch {
+	case open && !subscribed:
+		r.unsubscribe[pos.Symbol] = r.feed.Subscribe(pos.Symbol, r.onPrice)
+	case !open && subscribed:
+		unsubscribe()
+		delete(r.unsubscribe, pos.Symbol)
+		delete(r.lastTick, pos.Symbol)
+	}
+}
+
+// onPrice pushes the PnL of the symbol's positions at the new mark price
+func (r *PositionRelay) onPrice(symbol string, price float64) {
+	r.mu.Lock()
+	if !r.active || time.Since(r.lastTick[symbol]) < tickInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastTick[symbol] = time.Now()
+
+	ticks := []*Position{}
+	for _, pos := range r.positions {
+		if pos.Symbol != symbol {
+			continue
+		}
+		pos.MarkPrice = price
+		pos.UnrealizedProfit = pos.PositionAmt * (price - pos.EntryPrice)
+		tick := *pos
+		ticks = append(ticks, &tick)
+	}
+	r.mu.Unlock()
+
+	for _, tick := range ticks {
+		r.hub.Publish(&Message{Type: TypePosition, Data: tick})
+	}
+}
@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Client shares caches, rate limits and job queues between server instances.
+// Every key is prefixed so several deployments can share one Redis.
+type Client struct {
+	rdb     *goredis.Client
+	prefix  string
+	ctx     context.Context // Cancelled by Close to stop the queue workers
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+// Config holds the Redis connection settings
+type Config struct {
+	URL    string // redis://[:password@]host:6379/0 (rediss:// for TLS)
+	Prefix string // Key prefix, e.g. "tradingapi:"
+}
+
+// New connects to Redis
+func New(ctx context.Context, config Config) (*Client, error) {
+	options, err := goredis.ParseURL(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %v", err)
+	}
+
+	rdb := goredis.NewClient(options)
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %v", err)
+	}
+
+	logging.Info().Msgf("Redis connected (%s, prefix=%q)", options.Addr, config.Prefix)
+	workerCtx, cancel := context.WithCancel(context.Background())
+	return &Client{rdb: rdb, prefix: config.Prefix, ctx: workerCtx, cancel: cancel}, nil
+}
+
+// Close stops the queue workers and closes the connection
+func (c *Client) Close() error {
+	c.cancel()
+	c.workers.Wait()
+	return c.rdb.Close()
+}
+
+func (c *Client) key(key string) string {
+	return c.prefix + key
+}
+
+// GetJSON reads a cached value into value, false if it is missing or expired
+func (c *Client) GetJSON(ctx context.Context, key string, value interface{}) (bool, error) {
+	data, err := c.rdb.Get(ctx, c.key(key)).Bytes()
+	if err == goredis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, fmt.Errorf("failed to unmarshal cached %s: %v", key, err)
+	}
+	return true, nil
+}
+
+// SetJSON caches a value for ttl
+func (c *Client) SetJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, c.key(key), data, ttl).Err()
+}
+
+// SetOnce stores key for ttl unless it is already stored (SET NX PX), and
+// reports whether it was stored now
+func (c *Client) SetOnce(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, c.key(key), 1, ttl).Result()
+}
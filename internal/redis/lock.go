@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// acquireLockScript takes a free lock or extends one the owner already holds.
+//
+// KEYS[1] lock, ARGV[1] owner, ARGV[2] TTL (ms)
+var acquireLockScript = goredis.NewScript(`
+local holder = redis.call('GET', KEYS[1])
+if holder == false then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+if holder == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseLockScript deletes a lock only if the owner still holds it.
+//
+// KEYS[1] lock, ARGV[1] owner
+var releaseLockScript = goredis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// TryLock acquires a lock shared by every server instance for owner, or
+// extends it if owner already holds it. It reports whether owner holds the
+// lock for ttl from now.
+func (c *Client) TryLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	acquired, err := acquireLockScript.Run(ctx, c.rdb, []string{c.key("lock:" + name)}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return acquired == 1, nil
+}
+
+// Unlock releases a lock if owner holds it
+func (c *Client) Unlock(ctx context.Context, name, owner string) error {
+	return releaseLockScript.Run(ctx, c.rdb, []string{c.key("lock:" + name)}, owner).Err()
+}
+
+// LockHolder returns the owner of a lock, or "" if nobody holds it
+func (c *Client) LockHolder(ctx context.Context, name string) (string, error) {
+	holder, err := c.rdb.Get(ctx, c.key("lock:"+name)).Result()
+	if err == goredis.Nil {
+		return "", nil
+	}
+	return holder, err
+}
@@ -0,0 +1,123 @@
+package redis
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// maxJobAttempts is how often a failing job is tried before it is dropped
+const maxJobAttempts = 3
+
+// job is a queued payload with the number of failed attempts so far
+type job struct {
+	Attempts int             `json:"attempts"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// Enqueue appends a job to a queue. Any instance with a worker on the queue
+// may run it.
+func (c *Client) Enqueue(ctx context.Context, queue string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return c.push(ctx, queue, job{Payload: data})
+}
+
+func (c *Client) push(ctx context.Context, queue string, j job) error {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	return c.rdb.RPush(ctx, c.key("queue:"+queue), data).Err()
+}
+
+// Handle starts a worker taking jobs off a queue until Close. A job whose
+// handler fails goes to the back of the queue, up to 3 attempts in all.
+func (c *Client) Handle(queue string, handler func(ctx context.Context, payload []byte) error) {
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+
+		for {
+			result, err := c.rdb.BLPop(c.ctx, 5*time.Second, c.key("queue:"+queue)).Result()
+			if c.ctx.Err() != nil {
+				return
+			}
+			if err == goredis.Nil {
+				continue
+			}
+			if err != nil {
+				logging.Warn().Err(err).Msgf("Redis queue %s", queue)
+				time.Sleep(time.Second)
+				continue
+			}
+
+			var j job
+			if err := json.Unmarshal([]byte(result[1]), &j); err != nil {
+				logging.Warn().Err(err).Msgf("Redis queue %s: dropping malformed job", queue)
+				continue
+			}
+
+			if err := handler(c.ctx, j.Payload); err != nil {
+				j.Attempts++
+				if j.Attempts >= maxJobAttempts {
+					logging.Warn().Err(err).Msgf("Redis queue %s: job failed %d times, dropped", queue, j.Attempts)
+					continue
+				}
+				if err := c.push(context.Background(), queue, j); err != nil {
+					logging.Warn().Err(err).Msgf("Redis queue %s: failed to requeue job", queue)
+				}
+			}
+		}
+	}()
+}
+
+// Every runs a job once per interval across all instances. Each instance
+// ticks, the first to claim the interval enqueues the run, and whichever
+// worker takes it off the queue runs it.
+func (c *Client) Every(name string, interval time.Duration, run func(ctx context.Context)) {
+	if interval <= 0 {
+		logging.Warn().Msgf("Redis job %s not scheduled: interval must be positive", name)
+		return
+	}
+
+	queue := "jobs:" + name
+	c.Handle(queue, func(ctx context.Context, _ []byte) error {
+		run(ctx)
+		return nil
+	})
+
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			slot := time.Now().Truncate(interval).Unix()
+			claimKey := c.key("jobs:claim:" + name + ":" + strconv.FormatInt(slot, 10))
+			claimed, err := c.rdb.SetNX(c.ctx, claimKey, 1, interval).Result()
+			if err != nil && c.ctx.Err() == nil {
+				logging.Warn().Err(err).Msgf("Redis job %s: failed to claim run", name)
+			}
+			if claimed {
+				if err := c.Enqueue(c.ctx, queue, slot); err != nil {
+					logging.Warn().Err(err).Msgf("Redis job %s: failed to enqueue run", name)
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-c.ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript counts a request in the current fixed window unless the
+// sliding window ending now is full. The previous window's count is weighted
+// by how much of it still overlaps, like the in-memory limiter.
+//
+// KEYS[1] current window, KEYS[2] previous window
+// ARGV[1] limit, ARGV[2] overlap of the previous window (0-1), ARGV[3] TTL (ms)
+var slidingWindowScript = goredis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local used = math.ceil(previous * tonumber(ARGV[2])) + current
+if used >= tonumber(ARGV[1]) then
+	return {0, used}
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {1, used}
+`)
+
+// Take counts a request against a limit per window shared by every server
+// instance. It returns whether the request is allowed, how many remain and
+// when the current window ends.
+func (c *Client) Take(ctx context.Context, key string, limit int, window time.Duration) (bool, int, time.Time, error) {
+	now := time.Now()
+	start := now.Truncate(window)
+	overlap := 1 - float64(now.Sub(start))/float64(window)
+
+	keys := []string{
+		c.key("ratelimit:" + key + ":" + strconv.FormatInt(start.Unix(), 10)),
+		c.key("ratelimit:" + key + ":" + strconv.FormatInt(start.Add(-window).Unix(), 10)),
+	}
+	result, err := slidingWindowScript.Run(ctx, c.rdb, keys, limit, overlap, (2 * window).Milliseconds()).Int64Slice()
+	if err != nil {
+		return false, 0, time.Time{}, err
+	}
+
+	reset := start.Add(window)
+	if result[0] == 0 {
+		return false, 0, reset, nil
+	}
+	return true, limit - int(result[1]) - 1, reset, nil
+}
@@ -0,0 +1,195 @@
+package reports
+
+import (
+	"context"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"time"
+)
+
+// snapshotDays is how many days of snapshots Binance returns at most
+const snapshotDays = 30
+
+// equityAssets are the assets counted at face value in the equity series
+var equityAssets = map[string]bool{"USDT": true, "USDC": true, "BUSD": true, "FDUSD": true}
+
+// SnapshotSource provides Binance's daily account snapshots
+type SnapshotSource interface {
+	GetAccountSnapshot(ctx context.Context, startTime, endTime int64, limit int) (*binance.AccountSnapshotResponse, error)
+}
+
+// BalanceStore persists daily balance snapshots
+type BalanceStore interface {
+	SaveBalanceSnapshot(ctx context.Context, snapshot *models.BalanceSnapshot) error
+	GetBalanceSnapshots(ctx context.Context) ([]*models.BalanceSnapshot, error)
+}
+
+// BalanceRecorderConfig configures the balance snapshot capture job
+type BalanceRecorderConfig struct {
+	Interval time.Duration // How often to look for new snapshots
+}
+
+// BalanceRecorder copies Binance's daily futures account snapshots into
+// storage, so the balance history outlives Binance's 30-day window
+type BalanceRecorder struct {
+	source   SnapshotSource
+	store    BalanceStore
+	config   BalanceRecorderConfig
+	stopChan chan struct{}
+}
+
+// NewBalanceRecorder creates a new balance snapshot recorder
+func NewBalanceRecorder(source SnapshotSource, store BalanceStore, config BalanceRecorderConfig) *BalanceRecorder {
+	if config.Interval <= 0 {
+		config.Interval = 6 * time.Hour
+	}
+
+	return &BalanceRecorder{
+		source:   source,
+		store:    store,
+		config:   config,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs the capture loop in the background
+func (r *BalanceRecorder) Start() {
+	logging.Info().Msgf("Balance history recorder started (interval=%v)", r.config.Interval)
+
+	go func() {
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		r.Capture(context.Background())
+		for {
+			select {
+			case <-ticker.C:
+				r.Capture(context.Background())
+			case <-r.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the capture loop
+func (r *BalanceRecorder) Stop() {
+	close(r.stopChan)
+}
+
+// Capture stores every snapshot of the last 30 days not stored yet and
+// returns how many it stored
+func (r *BalanceRecorder) Capture(ctx context.Context) int {
+	response, err := r.source.GetAccountSnapshot(ctx, 0, 0, snapshotDays)
+	if err != nil {
+		logging.Warn().Err(err).Msg("Balance history: failed to get account snapshots")
+		return 0
+	}
+
+	stored, err := r.store.GetBalanceSnapshots(ctx)
+	if err != nil {
+		logging.Warn().Err(err).Msg("Balance history")
+		return 0
+	}
+	have := make(map[string]bool, len(stored))
+	for _, snapshot := range stored {
+		have[snapshot.Date] = true
+	}
+
+	saved := 0
+	for _, vo := range response.SnapshotVos {
+		snapshot := balanceSnapshot(vo)
+		if have[snapshot.Date] {
+			continue
+		}
+		if err := r.store.SaveBalanceSnapshot(ctx, snapshot); err != nil {
+			logging.Warn().Err(err).Msg("Balance history")
+			continue
+		}
+		have[snapshot.Date] = true
+		saved++
+	}
+
+	if saved > 0 {
+		logging.Info().Msgf("Stored %d daily balance snapshots", saved)
+	}
+	return saved
+}
+
+// balanceSnapshot converts a Binance snapshot into a stored one
+func balanceSnapshot(vo binance.AccountSnapshot) *models.BalanceSnapshot {
+	t := time.UnixMilli(vo.UpdateTime).UTC()
+	snapshot := &models.BalanceSnapshot{
+		Date:      t.Format("2006-01-02"),
+		Time:      t.Unix(),
+		Assets:    make([]models.BalanceAsset, 0, len(vo.Data.Assets)),
+		CreatedAt: time.Now().Unix(),
+	}
+
+	for _, asset := range vo.Data.Assets {
+		snapshot.Assets = append(snapshot.Assets, models.BalanceAsset{
+			Asset:         asset.Asset,
+			WalletBalance: asset.WalletBalance,
+			MarginBalance: asset.MarginBalance,
+		})
+		if equityAssets[asset.Asset] {
+			snapshot.Equity += asset.MarginBalance
+			snapshot.WalletBalance += asset.WalletBalance
+			snapshot.UnrealizedPnL += asset.UnrealizedProfit
+		}
+	}
+
+	return snapshot
+}
+
+// BuildBalanceHistory returns one point per UTC day from from to to. Days
+// without a snapshot carry the previous day's balance forward; days before
+// the first snapshot are left out.
+func BuildBalanceHistory(snapshots []*models.BalanceSnapshot, from, to time.Time) *models.BalanceHistory {
+	byDate := make(map[string]*models.BalanceSnapshot, len(snapshots))
+	for _, snapshot := range snapshots {
+		byDate[snapshot.Date] = snapshot
+	}
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	history := &models.BalanceHistory{
+		From:   start.Format("2006-01-02"),
+		To:     to.UTC().Format("2006-01-02"),
+		Points: []models.EquityPoint{},
+	}
+
+	var previous *models.EquityPoint
+	base := 0.0
+	for day := start; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+
+		point := models.EquityPoint{Date: date}
+		if snapshot := byDate[date]; snapshot != nil {
+			point.Equity = snapshot.Equity
+			point.WalletBalance = snapshot.WalletBalance
+			point.UnrealizedPnL = snapshot.UnrealizedPnL
+		} else if previous != nil {
+			point.Equity = previous.Equity
+			point.WalletBalance = previous.WalletBalance
+			point.UnrealizedPnL = previous.UnrealizedPnL
+			point.Filled = true
+		} else {
+			continue
+		}
+
+		if previous == nil {
+			base = point.Equity
+		} else {
+			point.Change = point.Equity - previous.Equity
+		}
+		if base != 0 {
+			point.Index = point.Equity / base * 100
+		}
+
+		history.Points = append(history.Points, point)
+		previous = &history.Points[len(history.Points)-1]
+	}
+
+	return history
+}
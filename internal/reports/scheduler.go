@@ -0,0 +1,159 @@
+package reports
+
+import (
+	"context"
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"time"
+)
+
+// ReportStore persists summary reports and provides the trades they cover
+type ReportStore interface {
+	GetAllTrades(ctx context.Context) ([]*models.Trade, error)
+	GetReport(ctx context.Context, reportID string) (*models.SummaryReport, error)
+	SaveReport(ctx context.Context, report *models.SummaryReport) error
+}
+
+// PositionSource provides the open positions used for the open-risk section
+type PositionSource interface {
+	GetOpenPositions(ctx context.Context) ([]*binance.PositionInfo, error)
+}
+
+// Notifier delivers a composed report to users
+type Notifier interface {
+	NotifyReport(ctx context.Context, report *models.SummaryReport, text string) error
+}
+
+// SchedulerConfig configures which reports are generated and when
+type SchedulerConfig struct {
+	Daily  bool
+	Weekly bool
+	Hour   int // UTC hour after which the previous period's report is generated
+}
+
+// Scheduler composes daily/weekly summary reports once their period has ended
+type Scheduler struct {
+	store     ReportStore
+	positions PositionSource
+	notifier  Notifier // Optional
+	config    SchedulerConfig
+	generated map[string]bool
+	stopChan  chan struct{}
+}
+
+// NewScheduler creates a new report scheduler (notifier may be nil)
+func NewScheduler(store ReportStore, positions PositionSource, notifier Notifier, config SchedulerConfig) *Scheduler {
+	return &Scheduler{
+		store:     store,
+		positions: positions,
+		notifier:  notifier,
+		config:    config,
+		generated: make(map[string]bool),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler loop in the background. It may be started again
+// after Stop.
+func (s *Scheduler) Start() {
+	stop := make(chan struct{})
+	s.stopChan = stop
+
+	logging.Info().Msgf("Report scheduler started (daily=%v, weekly=%v, hour=%02d:00 UTC)", s.config.Daily, s.config.Weekly, s.config.Hour)
+
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		s.runDue(time.Now().UTC())
+		for {
+			select {
+			case now := <-ticker.C:
+				s.runDue(now.UTC())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler loop
+func (s *Scheduler) Stop() {
+	close(s.stopChan)
+}
+
+// runDue generates the reports for the most recently completed periods
+func (s *Scheduler) runDue(now time.Time) {
+	if now.Hour() < s.config.Hour {
+		return
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	if s.config.Daily {
+		s.generate(models.ReportPeriodDaily, today.AddDate(0, 0, -1), today)
+	}
+
+	if s.config.Weekly {
+		// Weeks run Monday to Monday
+		offset := (int(today.Weekday()) + 6) % 7
+		weekStart := today.AddDate(0, 0, -offset)
+		s.generate(models.ReportPeriodWeekly, weekStart.AddDate(0, 0, -7), weekStart)
+	}
+}
+
+// generate composes, stores and sends a report unless it already exists
+func (s *Scheduler) generate(period string, start, end time.Time) {
+	id := ReportID(period, start)
+	if s.generated[id] {
+		return
+	}
+
+	ctx := context.Background()
+
+	existing, err := s.store.GetReport(ctx, id)
+	if err != nil {
+		logging.Warn().Err(err).Msgf("Report scheduler: failed to check report %s", id)
+		return
+	}
+	if existing != nil {
+		s.generated[id] = true
+		return
+	}
+
+	report, err := Generate(ctx, s.store, s.positions, period, start, end)
+	if err != nil {
+		logging.Warn().Err(err).Msgf("Report scheduler: failed to generate %s", id)
+		return
+	}
+
+	if err := s.store.SaveReport(ctx, report); err != nil {
+		logging.Warn().Err(err).Msgf("Report scheduler: failed to save %s", id)
+		return
+	}
+	s.generated[id] = true
+	logging.Info().Msgf("Report %s saved (closed=%d, pnl=%.2f)", id, report.ClosedTrades, report.TotalPnL)
+
+	if s.notifier != nil {
+		if err := s.notifier.NotifyReport(ctx, report, FormatSummary(report)); err != nil {
+			logging.Warn().Err(err).Msgf("Report scheduler: failed to send %s", id)
+		}
+	}
+}
+
+// Generate composes a summary report for [start, end) from stored trades and open positions
+func Generate(ctx context.Context, store ReportStore, positions PositionSource, period string, start, end time.Time) (*models.SummaryReport, error) {
+	trades, err := store.GetAllTrades(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	openPositions, err := positions.GetOpenPositions(ctx)
+	if err != nil {
+		logging.Warn().Err(err).Msg("Report scheduler: failed to get open positions")
+		openPositions = nil
+	}
+
+	return ComposeSummary(period, start, end, trades, openPositions), nil
+}
@@ -0,0 +1,105 @@
+package reports
+
+import (
+	"crypto-trading-api/internal/binance"
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ComposeSummary builds a summary report for trades in [start, end)
+func ComposeSummary(period string, start, end time.Time, trades []*models.Trade, positions []*binance.PositionInfo) *models.SummaryReport {
+	report := &models.SummaryReport{
+		ID:        ReportID(period, start),
+		Period:    period,
+		StartTime: start.Unix(),
+		EndTime:   end.Unix(),
+		CreatedAt: time.Now().Unix(),
+	}
+
+	for _, trade := range trades {
+		if trade.CreatedAt >= report.StartTime && trade.CreatedAt < report.EndTime {
+			report.TotalTrades++
+		}
+
+		if trade.Status != "CLOSED" || trade.ClosedAt < report.StartTime || trade.ClosedAt >= report.EndTime {
+			continue
+		}
+
+		report.ClosedTrades++
+		report.TotalPnL += trade.PnL
+		report.TotalFees += trade.Commission
+
+		if trade.PnL > 0 {
+			report.Wins++
+		} else if trade.PnL < 0 {
+			report.Losses++
+		}
+
+		if report.BestTrade == nil || trade.PnL > report.BestTrade.PnL {
+			report.BestTrade = highlight(trade)
+		}
+		if report.WorstTrade == nil || trade.PnL < report.WorstTrade.PnL {
+			report.WorstTrade = highlight(trade)
+		}
+	}
+
+	if report.ClosedTrades > 0 {
+		report.WinRate = float64(report.Wins) / float64(report.ClosedTrades) * 100
+	}
+	report.NetPnL = report.TotalPnL - report.TotalFees
+
+	for _, pos := range positions {
+		report.OpenPositions++
+		report.OpenNotional += math.Abs(pos.PositionAmt) * pos.MarkPrice
+		report.UnrealizedPnL += pos.UnrealizedProfit
+	}
+
+	return report
+}
+
+// ReportID returns the deterministic ID of the report starting at start
+func ReportID(period string, start time.Time) string {
+	if period == models.ReportPeriodWeekly {
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("weekly-%d-W%02d", year, week)
+	}
+	return fmt.Sprintf("daily-%s", start.Format("2006-01-02"))
+}
+
+// FormatSummary renders a report as plain text for notifications
+func FormatSummary(report *models.SummaryReport) string {
+	var sb strings.Builder
+
+	start := time.Unix(report.StartTime, 0).UTC()
+	end := time.Unix(report.EndTime, 0).UTC().Add(-time.Second)
+	if report.Period == models.ReportPeriodWeekly {
+		fmt.Fprintf(&sb, "📊 Weekly summary %s – %s\n", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	} else {
+		fmt.Fprintf(&sb, "📊 Daily summary %s\n", start.Format("2006-01-02"))
+	}
+
+	fmt.Fprintf(&sb, "Trades: %d opened, %d closed\n", report.TotalTrades, report.ClosedTrades)
+	fmt.Fprintf(&sb, "Win rate: %.1f%% (%dW / %dL)\n", report.WinRate, report.Wins, report.Losses)
+	fmt.Fprintf(&sb, "PnL: %.2f (fees %.2f, net %.2f)\n", report.TotalPnL, report.TotalFees, report.NetPnL)
+	if report.BestTrade != nil {
+		fmt.Fprintf(&sb, "Best: %s %s %.2f\n", report.BestTrade.Symbol, report.BestTrade.Side, report.BestTrade.PnL)
+	}
+	if report.WorstTrade != nil {
+		fmt.Fprintf(&sb, "Worst: %s %s %.2f\n", report.WorstTrade.Symbol, report.WorstTrade.Side, report.WorstTrade.PnL)
+	}
+	fmt.Fprintf(&sb, "Open risk: %d positions, %.2f notional, %.2f unrealized", report.OpenPositions, report.OpenNotional, report.UnrealizedPnL)
+
+	return sb.String()
+}
+
+func highlight(trade *models.Trade) *models.TradeHighlight {
+	return &models.TradeHighlight{
+		TradeID: trade.ID,
+		Symbol:  trade.Symbol,
+		Side:    trade.Side,
+		PnL:     trade.PnL,
+	}
+}
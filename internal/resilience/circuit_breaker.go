@@ -0,0 +1,112 @@
+// Package resilience holds the circuit breaker shared by the Binance and
+// Firebase clients
+package resilience
+
+import (
+	"crypto-trading-api/internal/logging"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by CircuitBreaker.Execute while the circuit is open
+var ErrCircuitOpen = errors.New("circuit breaker is open, rejecting request")
+
+// CircuitBreaker implements circuit breaker pattern. It is safe for
+// concurrent use; fn runs without holding the lock.
+type CircuitBreaker struct {
+	mu              sync.Mutex
+	maxFailures     int
+	resetTimeout    time.Duration
+	failures        int
+	lastFailureTime time.Time
+	state           string // "closed", "open", "half-open"
+}
+
+// NewCircuitBreaker creates a new circuit breaker
+func NewCircuitBreaker(maxFailures int, resetTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		maxFailures:  maxFailures,
+		resetTimeout: resetTimeout,
+		state:        "closed",
+	}
+}
+
+// Execute executes a function with circuit breaker protection
+func (cb *CircuitBreaker) Execute(fn func() error) error {
+	cb.mu.Lock()
+	// Check if circuit should be reset
+	if cb.state == "open" && time.Since(cb.lastFailureTime) > cb.resetTimeout {
+		cb.state = "half-open"
+		cb.failures = 0
+		logging.Info().Msg("Circuit breaker: half-open (testing)")
+	}
+
+	// Block if circuit is open
+	if cb.state == "open" {
+		cb.mu.Unlock()
+		return ErrCircuitOpen
+	}
+	cb.mu.Unlock()
+
+	// Execute function
+	err := fn()
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err != nil {
+		cb.failures++
+		cb.lastFailureTime = time.Now()
+
+		if cb.state != "open" && cb.failures >= cb.maxFailures {
+			cb.state = "open"
+			logging.Warn().Msgf("Circuit breaker: OPEN (too many failures: %d)", cb.failures)
+		}
+
+		return err
+	}
+
+	// Success - reset circuit
+	if cb.state == "half-open" {
+		cb.state = "closed"
+		logging.Info().Msg("Circuit breaker: closed (recovered)")
+	}
+	cb.failures = 0
+
+	return nil
+}
+
+// CircuitStatus is a snapshot of a circuit breaker
+type CircuitStatus struct {
+	State       string `json:"state"`
+	Failures    int    `json:"failures"`              // Failed calls in a row
+	LastFailure int64  `json:"lastFailure,omitempty"` // Unix seconds
+}
+
+// Status returns the circuit breaker's state and failure count
+func (cb *CircuitBreaker) Status() CircuitStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	status := CircuitStatus{State: cb.state, Failures: cb.failures}
+	if !cb.lastFailureTime.IsZero() {
+		status.LastFailure = cb.lastFailureTime.Unix()
+	}
+	return status
+}
+
+// GetState returns the current circuit breaker state
+func (cb *CircuitBreaker) GetState() string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// Reset manually resets the circuit breaker
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	cb.state = "closed"
+	cb.failures = 0
+	cb.mu.Unlock()
+	logging.Info().Msg("Circuit breaker manually reset")
+}
@@ -0,0 +1,251 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// awsProvider reads an AWS Secrets Manager secret. Requests are signed with
+// Signature Version 4 using the environment credentials (AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN) or, without them, the ECS task
+// role or EC2 instance profile.
+type awsProvider struct {
+	secretID string
+	region   string
+	endpoint string
+
+	credentials *awsCredentials
+	mu          sync.Mutex
+}
+
+// awsCredentials are temporary or static AWS access keys
+type awsCredentials struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	Token           string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+func newAWSProvider(config Config) (*awsProvider, error) {
+	region := config.AWSRegion
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		return nil, fmt.Errorf("AWS_REGION must be set")
+	}
+
+	return &awsProvider{
+		secretID: config.Name,
+		region:   region,
+		endpoint: fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", region),
+	}, nil
+}
+
+// Fetch reads the current version of the secret
+func (p *awsProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	credentials, err := p.currentCredentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS credentials: %v", err)
+	}
+
+	payload, _ := json.Marshal(map[string]string{"SecretId": p.secretID})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signAWSRequest(req, payload, credentials, p.region, "secretsmanager", time.Now().UTC())
+
+	body, err := doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AWS secret: %v", err)
+	}
+
+	var result struct {
+		SecretString string `json:"SecretString"`
+		SecretBinary string `json:"SecretBinary"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse AWS response: %v", err)
+	}
+	if result.SecretString == "" && result.SecretBinary != "" {
+		data, err := base64.StdEncoding.DecodeString(result.SecretBinary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode AWS secret: %v", err)
+		}
+		return parseValues(data)
+	}
+	return parseValues([]byte(result.SecretString))
+}
+
+// currentCredentials returns the environment credentials, or role
+// credentials cached until shortly before they expire
+func (p *awsProvider) currentCredentials(ctx context.Context) (*awsCredentials, error) {
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		return &awsCredentials{
+			AccessKeyID:     id,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			Token:           os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.credentials != nil && time.Until(p.credentials.Expiration) > 5*time.Minute {
+		return p.credentials, nil
+	}
+
+	credentials, err := fetchRoleCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.credentials = credentials
+	return credentials, nil
+}
+
+// fetchRoleCredentials reads the ECS task role or, outside ECS, the EC2
+// instance profile (IMDSv2)
+func fetchRoleCredentials(ctx context.Context) (*awsCredentials, error) {
+	var req *http.Request
+	var err error
+	if uri := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); uri != "" {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, "http://169.254.170.2"+uri, nil)
+	} else if uri := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI"); uri != "" {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+		if err == nil && os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN") != "" {
+			req.Header.Set("Authorization", os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"))
+		}
+	} else {
+		return fetchInstanceCredentials(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	var credentials awsCredentials
+	if err := json.Unmarshal(body, &credentials); err != nil {
+		return nil, err
+	}
+	return &credentials, nil
+}
+
+// fetchInstanceCredentials reads the instance profile from the EC2
+// metadata service
+func fetchInstanceCredentials(ctx context.Context) (*awsCredentials, error) {
+	const metadata = "http://169.254.169.254/latest"
+
+	tokenReq, err := http.NewRequestWithContext(ctx, http.MethodPut, metadata+"/api/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	tokenReq.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+	token, err := doRequest(tokenReq)
+	if err != nil {
+		return nil, fmt.Errorf("no AWS credentials in the environment and no instance metadata: %v", err)
+	}
+
+	get := func(path string) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadata+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-aws-ec2-metadata-token", string(token))
+		return doRequest(req)
+	}
+
+	role, err := get("/meta-data/iam/security-credentials/")
+	if err != nil {
+		return nil, err
+	}
+	body, err := get("/meta-data/iam/security-credentials/" + strings.TrimSpace(strings.Split(string(role), "\n")[0]))
+	if err != nil {
+		return nil, err
+	}
+	var credentials awsCredentials
+	if err := json.Unmarshal(body, &credentials); err != nil {
+		return nil, err
+	}
+	return &credentials, nil
+}
+
+// signAWSRequest adds Signature Version 4 headers to req
+func signAWSRequest(req *http.Request, payload []byte, credentials *awsCredentials, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.Token != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.Token)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashHex(payload),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
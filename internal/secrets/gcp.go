@@ -0,0 +1,78 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// gcpProvider reads the latest version of a GCP Secret Manager secret with
+// the default credentials (GOOGLE_APPLICATION_CREDENTIALS, workload identity)
+type gcpProvider struct {
+	url    string
+	tokens oauth2.TokenSource
+}
+
+func newGCPProvider(config Config) (*gcpProvider, error) {
+	credentials, err := google.FindDefaultCredentials(context.Background(), "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GCP credentials: %v", err)
+	}
+
+	// Accept a full resource name (projects/p/secrets/s/versions/v) as is
+	resource := config.Name
+	if !strings.HasPrefix(resource, "projects/") {
+		project := config.GCPProject
+		if project == "" {
+			project = credentials.ProjectID
+		}
+		if project == "" {
+			return nil, fmt.Errorf("SECRETS_GCP_PROJECT must be set")
+		}
+		resource = fmt.Sprintf("projects/%s/secrets/%s/versions/latest", project, resource)
+	}
+
+	return &gcpProvider{
+		url:    "https://secretmanager.googleapis.com/v1/" + resource + ":access",
+		tokens: credentials.TokenSource,
+	}, nil
+}
+
+// Fetch reads the secret version
+func (p *gcpProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	token, err := p.tokens.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GCP access token: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	body, err := doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GCP secret: %v", err)
+	}
+
+	var result struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse GCP response: %v", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(result.Payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode GCP secret payload: %v", err)
+	}
+	return parseValues(data)
+}
@@ -0,0 +1,209 @@
+package secrets
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Supported providers
+const (
+	ProviderVault = "vault"
+	ProviderAWS   = "aws"
+	ProviderGCP   = "gcp"
+)
+
+// fetchTimeout bounds a single secret read
+const fetchTimeout = 15 * time.Second
+
+// Config selects the secret holding the credentials and the provider
+// storing it
+type Config struct {
+	Provider string // vault, aws or gcp
+	Name     string // Vault KV path, AWS secret ID or ARN, GCP secret name
+
+	VaultAddr      string // e.g. https://vault.internal:8200
+	VaultToken     string
+	VaultMount     string // KV v2 mount, "secret" by default
+	VaultNamespace string // Vault Enterprise namespace
+
+	AWSRegion string
+
+	GCPProject string // Defaults to the project of the default credentials
+}
+
+// Provider reads a secret holding settings as a JSON object of name/value
+// pairs, e.g. {"BINANCE_API_KEY": "...", "BINANCE_SECRET_KEY": "..."}
+type Provider interface {
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// New creates the provider selected by config
+func New(config Config) (Provider, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("SECRETS_NAME must be set")
+	}
+
+	switch strings.ToLower(config.Provider) {
+	case ProviderVault:
+		return newVaultProvider(config)
+	case ProviderAWS:
+		return newAWSProvider(config)
+	case ProviderGCP:
+		return newGCPProvider(config)
+	default:
+		return nil, fmt.Errorf("unknown secrets provider %q (expected vault, aws or gcp)", config.Provider)
+	}
+}
+
+var httpClient = &http.Client{Timeout: fetchTimeout}
+
+// doRequest sends req and returns the body of a 2xx response
+func doRequest(req *http.Request) ([]byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// parseValues decodes a secret stored as a JSON object. Non-string values
+// (numbers, booleans) are kept in their JSON form.
+func parseValues(data []byte) (map[string]string, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("secret is not a JSON object of settings: %v", err)
+	}
+	return stringValues(raw), nil
+}
+
+// stringValues converts decoded JSON values to setting strings
+func stringValues(raw map[string]interface{}) map[string]string {
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		if s, ok := value.(string); ok {
+			values[key] = s
+			continue
+		}
+		encoded, _ := json.Marshal(value)
+		values[key] = string(encoded)
+	}
+	return values
+}
+
+// Rotator re-reads the secret periodically and reports rotated values
+type Rotator struct {
+	provider  Provider
+	interval  time.Duration
+	values    map[string]string
+	listeners []func(changed map[string]string)
+	mu        sync.Mutex
+	stopChan  chan struct{}
+}
+
+// NewRotator creates a rotator starting from the values already loaded
+func NewRotator(provider Provider, initial map[string]string, interval time.Duration) *Rotator {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	return &Rotator{
+		provider: provider,
+		interval: interval,
+		values:   initial,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// OnChange registers fn to receive the values that changed in a rotation,
+// including removed ones (as ""). Listeners run in registration order.
+func (r *Rotator) OnChange(fn func(changed map[string]string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// Start checks for rotated values in the background
+func (r *Rotator) Start() {
+	logging.Info().Msgf("Secret rotation check started (interval=%v)", r.interval)
+
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+				if _, err := r.Check(ctx); err != nil {
+					logging.Warn().Err(err).Msg("Secret rotation check failed, keeping the current credentials")
+				}
+				cancel()
+			case <-r.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background checks
+func (r *Rotator) Stop() {
+	close(r.stopChan)
+}
+
+// Check re-reads the secret and notifies listeners of changed values. It
+// returns the names of the settings that changed.
+func (r *Rotator) Check(ctx context.Context) ([]string, error) {
+	fresh, err := r.provider.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	changed := map[string]string{}
+	for key, value := range fresh {
+		if r.values[key] != value {
+			changed[key] = value
+		}
+	}
+	for key := range r.values {
+		if _, ok := fresh[key]; !ok {
+			changed[key] = ""
+		}
+	}
+	r.values = fresh
+	listeners := r.listeners
+	r.mu.Unlock()
+
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(changed))
+	for key := range changed {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	logging.Info().Strs("settings", names).Msg("Rotated secrets loaded")
+
+	for _, fn := range listeners {
+		fn(changed)
+	}
+	return names, nil
+}
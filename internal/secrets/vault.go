@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// vaultProvider reads a HashiCorp Vault KV v2 secret with token auth
+type vaultProvider struct {
+	url       string
+	token     string
+	namespace string
+}
+
+func newVaultProvider(config Config) (*vaultProvider, error) {
+	if config.VaultAddr == "" || config.VaultToken == "" {
+		return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN must be set")
+	}
+
+	mount := strings.Trim(config.VaultMount, "/")
+	if mount == "" {
+		mount = "secret"
+	}
+
+	return &vaultProvider{
+		url:       strings.TrimRight(config.VaultAddr, "/") + "/v1/" + mount + "/data/" + strings.Trim(config.Name, "/"),
+		token:     config.VaultToken,
+		namespace: config.VaultNamespace,
+	}, nil
+}
+
+// Fetch reads the latest version of the secret
+func (p *vaultProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	body, err := doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vault secret: %v", err)
+	}
+
+	var result struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Vault response: %v", err)
+	}
+	if result.Data.Data == nil {
+		return nil, fmt.Errorf("Vault secret has no data (is the mount KV version 2?)")
+	}
+	return stringValues(result.Data.Data), nil
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"time"
+)
+
+// archiveBatch is how many trades are read, and moved, at a time
+const archiveBatch = 200
+
+// ArchiverConfig configures the trade archival job
+type ArchiverConfig struct {
+	Interval time.Duration // How often to archive
+	MaxAge   time.Duration // Finished trades older than this are archived
+}
+
+// Archiver moves finished trades older than MaxAge out of the trades the
+// server queries (active trades, searches, reconcilers) into the archive.
+// Archived trades can still be fetched by ID.
+type Archiver struct {
+	store    TradeStore
+	config   ArchiverConfig
+	stopChan chan struct{}
+}
+
+// NewArchiver creates a new trade archiver
+func NewArchiver(store TradeStore, config ArchiverConfig) *Archiver {
+	if config.Interval <= 0 {
+		config.Interval = 24 * time.Hour
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = 90 * 24 * time.Hour
+	}
+
+	return &Archiver{
+		store:    store,
+		config:   config,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs the archival loop in the background
+func (a *Archiver) Start() {
+	logging.Info().Msgf("Trade archiver started (interval=%v, maxAge=%v)", a.config.Interval, a.config.MaxAge)
+
+	go func() {
+		ticker := time.NewTicker(a.config.Interval)
+		defer ticker.Stop()
+
+		a.Archive(context.Background())
+		for {
+			select {
+			case <-ticker.C:
+				a.Archive(context.Background())
+			case <-a.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the archival loop
+func (a *Archiver) Stop() {
+	close(a.stopChan)
+}
+
+// Archive moves every trade that is finished and older than MaxAge (by close
+// time, or creation time if it never opened) and returns how many it moved.
+// Open trades are never archived, however old.
+func (a *Archiver) Archive(ctx context.Context) int {
+	cutoff := time.Now().Add(-a.config.MaxAge).Unix()
+	query := models.TradeQuery{To: cutoff, Limit: archiveBatch}
+
+	archived := 0
+	for {
+		page, err := a.store.QueryTrades(ctx, query)
+		if err != nil {
+			logging.Warn().Err(err).Msg("Trade archiver")
+			break
+		}
+
+		var expired []*models.Trade
+		for _, trade := range page.Trades {
+			if models.FinalStatus(trade.Status) && trade.ClosedAt <= cutoff {
+				expired = append(expired, trade)
+			}
+		}
+		if len(expired) > 0 {
+			if err := a.store.ArchiveTrades(ctx, expired); err != nil {
+				logging.Warn().Err(err).Msg("Trade archiver")
+				break
+			}
+			archived += len(expired)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		last := page.Trades[len(page.Trades)-1]
+		query.After = &models.TradeCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	if archived > 0 {
+		logging.Info().Msgf("Archived %d trades finished before %s", archived, time.Unix(cutoff, 0).Format(time.RFC3339))
+	}
+	return archived
+}
@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// migration is one schema version. Statements use {key} for primary key
+// columns, which must compare byte-wise on every database (audit entry IDs
+// are range-scanned in key order), and {data.<field>} to read a field of a
+// row's JSON document.
+type migration struct {
+	version    int
+	name       string
+	statements []string
+}
+
+// migrations are applied in order and never edited once released; schema
+// changes go in a new version
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "initial schema",
+		statements: []string{
+			`CREATE TABLE trades (
+				id {key} PRIMARY KEY,
+				user_id {key} NOT NULL,
+				status TEXT NOT NULL,
+				created_at BIGINT NOT NULL,
+				data TEXT NOT NULL
+			)`,
+			`CREATE INDEX trades_user_id ON trades (user_id)`,
+			`CREATE INDEX trades_status ON trades (status)`,
+			`CREATE TABLE records (
+				collection TEXT NOT NULL,
+				id {key} NOT NULL,
+				data TEXT NOT NULL,
+				PRIMARY KEY (collection, id)
+			)`,
+			`CREATE TABLE audit_entries (
+				id {key} PRIMARY KEY,
+				data TEXT NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "trade queries",
+		statements: []string{
+			`ALTER TABLE trades ADD COLUMN symbol TEXT NOT NULL DEFAULT ''`,
+			`UPDATE trades SET symbol = COALESCE({data.symbol}, '')`,
+			`CREATE INDEX trades_created_at ON trades (created_at, id)`,
+			`CREATE INDEX trades_symbol ON trades (symbol, created_at)`,
+		},
+	},
+	{
+		version: 3,
+		name:    "trade events",
+		statements: []string{
+			`CREATE TABLE trade_events (
+				trade_id {key} NOT NULL,
+				id {key} NOT NULL,
+				data TEXT NOT NULL,
+				PRIMARY KEY (trade_id, id)
+			)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "trade archive",
+		statements: []string{
+			`CREATE TABLE archived_trades (
+				id {key} PRIMARY KEY,
+				user_id {key} NOT NULL,
+				created_at BIGINT NOT NULL,
+				archived_at BIGINT NOT NULL,
+				data TEXT NOT NULL
+			)`,
+		},
+	},
+}
+
+// dataField matches {data.<field>} in migration statements
+var dataField = regexp.MustCompile(`\{data\.(\w+)\}`)
+
+// expand fills in a statement's placeholders for the dialect
+func (d dialect) expand(statement string) string {
+	statement = strings.ReplaceAll(statement, "{key}", d.keyType)
+	return dataField.ReplaceAllStringFunc(statement, func(match string) string {
+		return fmt.Sprintf(d.jsonField, dataField.FindStringSubmatch(match)[1])
+	})
+}
+
+// migrate brings the schema to the latest version, each version in its own
+// transaction
+func migrate(ctx context.Context, db *sql.DB, d dialect) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %v", err)
+	}
+
+	var current int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %v", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to start migration %d: %v", m.version, err)
+		}
+		for _, statement := range m.statements {
+			if _, err := tx.ExecContext(ctx, d.expand(statement)); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %v", m.version, m.name, err)
+			}
+		}
+		_, err = tx.ExecContext(ctx, d.rebind(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`),
+			m.version, m.name, time.Now().Unix())
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %v", m.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %v", m.version, err)
+		}
+
+		logging.Info().Msgf("Applied migration %d: %s", m.version, m.name)
+	}
+
+	return nil
+}
@@ -0,0 +1,1040 @@
+package storage
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"  // postgres driver
+	_ "modernc.org/sqlite" // sqlite driver (pure Go)
+)
+
+// dialect holds what differs between the SQL databases
+type dialect struct {
+	name      string
+	driver    string
+	keyType   string // Column type of keys, compared byte-wise
+	numbered  bool   // $1, $2, ... placeholders instead of ?
+	jsonField string // Expression reading a field of the data column (%s is the field)
+}
+
+var (
+	postgres = dialect{name: BackendPostgres, driver: "postgres", keyType: `TEXT COLLATE "C"`, numbered: true, jsonField: "(data::json->>'%s')"}
+	sqlite   = dialect{name: BackendSQLite, driver: "sqlite", keyType: "TEXT", jsonField: "json_extract(data, '$.%s')"}
+)
+
+// rebind converts ? placeholders to the dialect's style
+func (d dialect) rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Record collections of the records table
+const (
+	collectionUserStats      = "user_stats"
+	collectionSettings       = "settings"
+	collectionRiskActions    = "risk_actions"
+	collectionReports        = "reports"
+	collectionBalanceHistory = "balance_history"
+	collectionWebhooks       = "webhooks"
+	collectionAlerts         = "price_alerts"
+	collectionPerfAlerts     = "performance_alerts"
+	collectionTemplates      = "tradingview_templates"
+	collectionPresets        = "presets"
+	collectionArbGroups      = "funding_arb_groups"
+	collectionCredentials    = "credentials"
+	collectionUserSettings   = "user_settings"
+	collectionAPIKeys        = "apikeys"
+	collectionRoles          = "roles"
+	collectionIdempotency    = "idempotency"
+	collectionHealth         = "health"
+)
+
+// SQLStore keeps trades in their own table, indexed by user and status, and
+// every other record as JSON in a table keyed by collection and ID. Trades
+// and records are stored as the same JSON documents Firebase holds.
+type SQLStore struct {
+	db             *sql.DB
+	dialect        dialect
+	tradeListeners []func(ctx context.Context, trade *models.Trade)
+}
+
+// openSQL connects to a Postgres or SQLite database and migrates it
+func openSQL(ctx context.Context, d dialect, dsn string) (TradeStore, error) {
+	db, err := sql.Open(d.driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s database: %v", d.name, err)
+	}
+	if d.name == BackendSQLite {
+		// One connection: writes are serialized anyway, and an in-memory
+		// database exists only on the connection that created it
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to %s database: %v", d.name, err)
+	}
+	if err := migrate(ctx, db, d); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	logging.Info().Msgf("%s storage initialized successfully", d.name)
+	return &SQLStore{db: db, dialect: d}, nil
+}
+
+func (s *SQLStore) exec(ctx context.Context, query string, args ...interface{}) error {
+	_, err := s.db.ExecContext(ctx, s.dialect.rebind(query), args...)
+	return err
+}
+
+// SaveTrade - Save a trade
+func (s *SQLStore) SaveTrade(ctx context.Context, trade *models.Trade) error {
+	if err := s.putTrade(ctx, trade); err != nil {
+		return fmt.Errorf("failed to save trade: %v", err)
+	}
+	s.tradeSaved(ctx, trade)
+	return nil
+}
+
+// UpdateTrade - Update an existing trade
+func (s *SQLStore) UpdateTrade(ctx context.Context, trade *models.Trade) error {
+	if err := s.putTrade(ctx, trade); err != nil {
+		return fmt.Errorf("failed to update trade: %v", err)
+	}
+	s.tradeSaved(ctx, trade)
+	return nil
+}
+
+func (s *SQLStore) putTrade(ctx context.Context, trade *models.Trade) error {
+	data, err := json.Marshal(trade)
+	if err != nil {
+		return err
+	}
+	return s.exec(ctx, `INSERT INTO trades (id, user_id, status, symbol, created_at, data) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET user_id = excluded.user_id, status = excluded.status,
+		symbol = excluded.symbol, created_at = excluded.created_at, data = excluded.data`,
+		trade.ID, trade.UserID, trade.Status, trade.Symbol, trade.CreatedAt, string(data))
+}
+
+// OnTradeSaved registers a listener told about every trade written by
+// SaveTrade or UpdateTrade. Register listeners before serving requests.
+func (s *SQLStore) OnTradeSaved(listener func(ctx context.Context, trade *models.Trade)) {
+	s.tradeListeners = append(s.tradeListeners, listener)
+}
+
+// tradeSaved passes a snapshot of a written trade to the listeners
+func (s *SQLStore) tradeSaved(ctx context.Context, trade *models.Trade) {
+	for _, listener := range s.tradeListeners {
+		snapshot := *trade
+		listener(ctx, &snapshot)
+	}
+}
+
+// GetTrade - Get a single trade by ID
+func (s *SQLStore) GetTrade(ctx context.Context, tradeID string) (*models.Trade, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, s.dialect.rebind(`SELECT data FROM trades WHERE id = ?`), tradeID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("trade not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade: %v", err)
+	}
+
+	var trade models.Trade
+	if err := json.Unmarshal([]byte(data), &trade); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trade: %v", err)
+	}
+	return &trade, nil
+}
+
+// GetUserTrades - Get all trades of a user
+func (s *SQLStore) GetUserTrades(ctx context.Context, userID string) ([]*models.Trade, error) {
+	trades, err := s.queryTrades(ctx, `SELECT data FROM trades WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user trades: %v", err)
+	}
+	return trades, nil
+}
+
+// GetActiveTrades - Get all active trades for monitoring
+func (s *SQLStore) GetActiveTrades(ctx context.Context) ([]*models.Trade, error) {
+	trades, err := s.queryTrades(ctx, `SELECT data FROM trades WHERE status = ?`, "ACTIVE")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trades: %v", err)
+	}
+	return trades, nil
+}
+
+// GetAllTrades - Get all trades
+func (s *SQLStore) GetAllTrades(ctx context.Context) ([]*models.Trade, error) {
+	trades, err := s.queryTrades(ctx, `SELECT data FROM trades`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all trades: %v", err)
+	}
+	return trades, nil
+}
+
+// GetTradesByStatus - Get trades filtered by status
+func (s *SQLStore) GetTradesByStatus(ctx context.Context, status string) ([]*models.Trade, error) {
+	trades, err := s.queryTrades(ctx, `SELECT data FROM trades WHERE status = ?`, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trades by status: %v", err)
+	}
+	return trades, nil
+}
+
+// QueryTrades - Get one page of trades matching the filters, newest first
+func (s *SQLStore) QueryTrades(ctx context.Context, query models.TradeQuery) (*models.TradePage, error) {
+	var conditions []string
+	var args []interface{}
+	for _, filter := range []struct{ column, value string }{
+		{"user_id", query.UserID},
+		{"status", query.Status},
+		{"symbol", query.Symbol},
+	} {
+		if filter.value != "" {
+			conditions = append(conditions, filter.column+" = ?")
+			args = append(args, filter.value)
+		}
+	}
+	if query.From != 0 {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, query.From)
+	}
+	if query.To != 0 {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, query.To)
+	}
+	if query.After != nil {
+		conditions = append(conditions, "(created_at < ? OR (created_at = ? AND id < ?))")
+		args = append(args, query.After.CreatedAt, query.After.CreatedAt, query.After.ID)
+	}
+
+	statement := `SELECT data FROM trades`
+	if len(conditions) > 0 {
+		statement += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	statement += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, query.Limit+1)
+
+	trades, err := s.queryTrades(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trades: %v", err)
+	}
+	return models.NewTradePage(trades, query.Limit), nil
+}
+
+func (s *SQLStore) queryTrades(ctx context.Context, query string, args ...interface{}) ([]*models.Trade, error) {
+	rows, err := s.db.QueryContext(ctx, s.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trades := []*models.Trade{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var trade models.Trade
+		if err := json.Unmarshal([]byte(data), &trade); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal trade: %v", err)
+		}
+		trades = append(trades, &trade)
+	}
+	return trades, rows.Err()
+}
+
+// DeleteTrade - Delete a trade
+func (s *SQLStore) DeleteTrade(ctx context.Context, tradeID string, userID string) error {
+	if err := s.exec(ctx, `DELETE FROM trades WHERE id = ?`, tradeID); err != nil {
+		return fmt.Errorf("failed to delete trade: %v", err)
+	}
+	return nil
+}
+
+// ArchiveTrades - Move trades to the archived_trades table in one
+// transaction
+func (s *SQLStore) ArchiveTrades(ctx context.Context, trades []*models.Trade) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to archive trades: %v", err)
+	}
+	defer tx.Rollback()
+
+	archivedAt := time.Now().Unix()
+	for _, trade := range trades {
+		data, err := json.Marshal(trade)
+		if err != nil {
+			return fmt.Errorf("failed to archive trades: %v", err)
+		}
+		_, err = tx.ExecContext(ctx, s.dialect.rebind(`INSERT INTO archived_trades (id, user_id, created_at, archived_at, data) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET user_id = excluded.user_id, created_at = excluded.created_at,
+			archived_at = excluded.archived_at, data = excluded.data`),
+			trade.ID, trade.UserID, trade.CreatedAt, archivedAt, string(data))
+		if err == nil {
+			_, err = tx.ExecContext(ctx, s.dialect.rebind(`DELETE FROM trades WHERE id = ?`), trade.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to archive trade %s: %v", trade.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to archive trades: %v", err)
+	}
+	return nil
+}
+
+// GetArchivedTrade - Get a trade moved to the archived_trades table
+func (s *SQLStore) GetArchivedTrade(ctx context.Context, tradeID string) (*models.Trade, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, s.dialect.rebind(`SELECT data FROM archived_trades WHERE id = ?`), tradeID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("trade not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archived trade: %v", err)
+	}
+
+	var trade models.Trade
+	if err := json.Unmarshal([]byte(data), &trade); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trade: %v", err)
+	}
+	return &trade, nil
+}
+
+// UpdateTradePnL - Close a trade with its PnL
+func (s *SQLStore) UpdateTradePnL(ctx context.Context, tradeID string, pnl float64, userID string) error {
+	trade, err := s.GetTrade(ctx, tradeID)
+	if err != nil {
+		return err
+	}
+
+	trade.PnL = pnl
+	trade.Status = "CLOSED"
+	trade.ClosedAt = time.Now().Unix()
+
+	return s.UpdateTrade(ctx, trade)
+}
+
+// BatchUpdateTrades - Update multiple trades at once
+func (s *SQLStore) BatchUpdateTrades(ctx context.Context, trades []*models.Trade) error {
+	for _, trade := range trades {
+		if err := s.UpdateTrade(ctx, trade); err != nil {
+			logging.Error().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("Error updating trade %s", trade.ID)
+		}
+	}
+	return nil
+}
+
+// GetUserStats - Get user statistics
+func (s *SQLStore) GetUserStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+	var stats map[string]interface{}
+	found, err := s.getRecord(ctx, collectionUserStats, userID, &stats)
+	if err != nil || !found {
+		return map[string]interface{}{
+			"totalTrades":  0,
+			"activeTrades": 0,
+			"totalPnL":     0.0,
+			"winRate":      0.0,
+		}, nil
+	}
+	return stats, nil
+}
+
+// UpdateUserStats - Update user statistics
+func (s *SQLStore) UpdateUserStats(ctx context.Context, userID string, stats map[string]interface{}) error {
+	if err := s.putRecord(ctx, collectionUserStats, userID, stats); err != nil {
+		return fmt.Errorf("failed to update user stats: %v", err)
+	}
+	return nil
+}
+
+// CalculateUserStatistics - Calculate and update user statistics
+func (s *SQLStore) CalculateUserStatistics(ctx context.Context, userID string) error {
+	trades, err := s.GetUserTrades(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	activeTrades := 0
+	totalPnL := 0.0
+	winningTrades := 0
+	for _, trade := range trades {
+		if trade.Status == "ACTIVE" {
+			activeTrades++
+		}
+		totalPnL += trade.PnL
+		if trade.PnL > 0 {
+			winningTrades++
+		}
+	}
+
+	winRate := 0.0
+	if len(trades) > 0 {
+		winRate = float64(winningTrades) / float64(len(trades)) * 100
+	}
+
+	return s.UpdateUserStats(ctx, userID, map[string]interface{}{
+		"totalTrades":  len(trades),
+		"activeTrades": activeTrades,
+		"totalPnL":     totalPnL,
+		"winRate":      winRate,
+		"lastUpdate":   time.Now().Unix(),
+	})
+}
+
+// GetUserTradeStats - Get a user's running trade totals (nil if none)
+func (s *SQLStore) GetUserTradeStats(ctx context.Context, userID string) (*models.UserTradeStats, error) {
+	var stats models.UserTradeStats
+	found, err := s.getRecord(ctx, collectionUserStats, userID, &stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user stats: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &stats, nil
+}
+
+// SaveUserTradeStats - Store a user's running trade totals
+func (s *SQLStore) SaveUserTradeStats(ctx context.Context, stats *models.UserTradeStats) error {
+	if err := s.putRecord(ctx, collectionUserStats, stats.UserID, stats); err != nil {
+		return fmt.Errorf("failed to save user stats: %v", err)
+	}
+	return nil
+}
+
+// GetTradeContribution - Get what a trade added to its owner's stats (nil if
+// it was not counted)
+func (s *SQLStore) GetTradeContribution(ctx context.Context, userID, tradeID string) (*models.TradeContribution, error) {
+	var contribution models.TradeContribution
+	found, err := s.getRecord(ctx, statsTradesCollection(userID), tradeID, &contribution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade contribution: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &contribution, nil
+}
+
+// SaveTradeContribution - Store what a trade added to its owner's stats
+func (s *SQLStore) SaveTradeContribution(ctx context.Context, contribution *models.TradeContribution) error {
+	if err := s.putRecord(ctx, statsTradesCollection(contribution.UserID), contribution.TradeID, contribution); err != nil {
+		return fmt.Errorf("failed to save trade contribution: %v", err)
+	}
+	return nil
+}
+
+// GetTradeContributions - Get every trade counted in a user's stats
+func (s *SQLStore) GetTradeContributions(ctx context.Context, userID string) ([]*models.TradeContribution, error) {
+	contributions, err := listRecords[models.TradeContribution](ctx, s, statsTradesCollection(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade contributions: %v", err)
+	}
+	return contributions, nil
+}
+
+// statsTradesCollection is the records collection of a user's counted trades
+func statsTradesCollection(userID string) string {
+	return collectionUserStats + "/" + userID + "/trades"
+}
+
+// SaveSystemStats - Save system-wide statistics
+func (s *SQLStore) SaveSystemStats(ctx context.Context, stats map[string]interface{}) error {
+	stats["lastUpdate"] = time.Now().Unix()
+	if err := s.putRecord(ctx, collectionSettings, "systemStats", stats); err != nil {
+		return fmt.Errorf("failed to save system stats: %v", err)
+	}
+	return nil
+}
+
+// GetSystemStats - Get system statistics
+func (s *SQLStore) GetSystemStats(ctx context.Context) (map[string]interface{}, error) {
+	var stats map[string]interface{}
+	found, err := s.getRecord(ctx, collectionSettings, "systemStats", &stats)
+	if err != nil || !found {
+		return map[string]interface{}{
+			"totalTrades":  0,
+			"activeTrades": 0,
+			"totalVolume":  0.0,
+			"lastUpdate":   time.Now().Unix(),
+		}, nil
+	}
+	return stats, nil
+}
+
+// SaveRiskAction - Journal an automated risk action
+func (s *SQLStore) SaveRiskAction(ctx context.Context, action *models.RiskAction) error {
+	if err := s.putRecord(ctx, collectionRiskActions, action.ID, action); err != nil {
+		return fmt.Errorf("failed to save risk action: %v", err)
+	}
+	return nil
+}
+
+// GetSymbolPolicy - Get the persisted symbol allow/block lists (nil if never saved)
+func (s *SQLStore) GetSymbolPolicy(ctx context.Context) (*models.SymbolPolicyConfig, error) {
+	var cfg models.SymbolPolicyConfig
+	found, err := s.getRecord(ctx, collectionSettings, "symbolPolicy", &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol policy: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &cfg, nil
+}
+
+// SaveSymbolPolicy - Persist the symbol allow/block lists
+func (s *SQLStore) SaveSymbolPolicy(ctx context.Context, cfg *models.SymbolPolicyConfig) error {
+	if err := s.putRecord(ctx, collectionSettings, "symbolPolicy", cfg); err != nil {
+		return fmt.Errorf("failed to save symbol policy: %v", err)
+	}
+	return nil
+}
+
+// SaveReport - Store a summary report
+func (s *SQLStore) SaveReport(ctx context.Context, report *models.SummaryReport) error {
+	if err := s.putRecord(ctx, collectionReports, report.ID, report); err != nil {
+		return fmt.Errorf("failed to save report: %v", err)
+	}
+	return nil
+}
+
+// GetReport - Get a summary report by ID (nil if it doesn't exist)
+func (s *SQLStore) GetReport(ctx context.Context, reportID string) (*models.SummaryReport, error) {
+	var report models.SummaryReport
+	found, err := s.getRecord(ctx, collectionReports, reportID, &report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &report, nil
+}
+
+// GetReports - Get all stored summary reports
+func (s *SQLStore) GetReports(ctx context.Context) ([]*models.SummaryReport, error) {
+	reports, err := listRecords[models.SummaryReport](ctx, s, collectionReports)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports: %v", err)
+	}
+	return reports, nil
+}
+
+// SaveBalanceSnapshot - Store a daily balance snapshot
+func (s *SQLStore) SaveBalanceSnapshot(ctx context.Context, snapshot *models.BalanceSnapshot) error {
+	if err := s.putRecord(ctx, collectionBalanceHistory, snapshot.Date, snapshot); err != nil {
+		return fmt.Errorf("failed to save balance snapshot: %v", err)
+	}
+	return nil
+}
+
+// GetBalanceSnapshots - Get all daily balance snapshots
+func (s *SQLStore) GetBalanceSnapshots(ctx context.Context) ([]*models.BalanceSnapshot, error) {
+	snapshots, err := listRecords[models.BalanceSnapshot](ctx, s, collectionBalanceHistory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance snapshots: %v", err)
+	}
+	return snapshots, nil
+}
+
+// SaveWebhook - Store a webhook registration
+func (s *SQLStore) SaveWebhook(ctx context.Context, hook *models.Webhook) error {
+	if err := s.putRecord(ctx, collectionWebhooks, hook.ID, hook); err != nil {
+		return fmt.Errorf("failed to save webhook: %v", err)
+	}
+	return nil
+}
+
+// GetWebhooks - Get all webhook registrations
+func (s *SQLStore) GetWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	hooks, err := listRecords[models.Webhook](ctx, s, collectionWebhooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %v", err)
+	}
+	return hooks, nil
+}
+
+// GetWebhook - Get a webhook registration by ID
+func (s *SQLStore) GetWebhook(ctx context.Context, hookID string) (*models.Webhook, error) {
+	var hook models.Webhook
+	found, err := s.getRecord(ctx, collectionWebhooks, hookID, &hook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %v", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	return &hook, nil
+}
+
+// DeleteWebhook - Remove a webhook registration
+func (s *SQLStore) DeleteWebhook(ctx context.Context, hookID string) error {
+	if err := s.deleteRecord(ctx, collectionWebhooks, hookID); err != nil {
+		return fmt.Errorf("failed to delete webhook: %v", err)
+	}
+	return nil
+}
+
+// SavePriceAlert - Store a price alert
+func (s *SQLStore) SavePriceAlert(ctx context.Context, alert *models.PriceAlert) error {
+	if err := s.putRecord(ctx, collectionAlerts, alert.ID, alert); err != nil {
+		return fmt.Errorf("failed to save price alert: %v", err)
+	}
+	return nil
+}
+
+// GetPriceAlerts - Get all price alerts
+func (s *SQLStore) GetPriceAlerts(ctx context.Context) ([]*models.PriceAlert, error) {
+	alerts, err := listRecords[models.PriceAlert](ctx, s, collectionAlerts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price alerts: %v", err)
+	}
+	return alerts, nil
+}
+
+// SavePerformanceAlert - Store a performance alert
+func (s *SQLStore) SavePerformanceAlert(ctx context.Context, alert *models.PerformanceAlert) error {
+	if err := s.putRecord(ctx, collectionPerfAlerts, alert.ID, alert); err != nil {
+		return fmt.Errorf("failed to save performance alert: %v", err)
+	}
+	return nil
+}
+
+// GetPerformanceAlerts - Get all performance alerts
+func (s *SQLStore) GetPerformanceAlerts(ctx context.Context) ([]*models.PerformanceAlert, error) {
+	alerts, err := listRecords[models.PerformanceAlert](ctx, s, collectionPerfAlerts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get performance alerts: %v", err)
+	}
+	return alerts, nil
+}
+
+// SaveTradingViewTemplate - Store a TradingView alert template
+func (s *SQLStore) SaveTradingViewTemplate(ctx context.Context, tpl *models.TradingViewTemplate) error {
+	if err := s.putRecord(ctx, collectionTemplates, tpl.Name, tpl); err != nil {
+		return fmt.Errorf("failed to save tradingview template: %v", err)
+	}
+	return nil
+}
+
+// GetTradingViewTemplate - Get a TradingView alert template by name (nil if it does not exist)
+func (s *SQLStore) GetTradingViewTemplate(ctx context.Context, name string) (*models.TradingViewTemplate, error) {
+	var tpl models.TradingViewTemplate
+	found, err := s.getRecord(ctx, collectionTemplates, name, &tpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tradingview template: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &tpl, nil
+}
+
+// GetTradingViewTemplates - Get all TradingView alert templates
+func (s *SQLStore) GetTradingViewTemplates(ctx context.Context) ([]*models.TradingViewTemplate, error) {
+	templates, err := listRecords[models.TradingViewTemplate](ctx, s, collectionTemplates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tradingview templates: %v", err)
+	}
+	return templates, nil
+}
+
+// DeleteTradingViewTemplate - Remove a TradingView alert template
+func (s *SQLStore) DeleteTradingViewTemplate(ctx context.Context, name string) error {
+	if err := s.deleteRecord(ctx, collectionTemplates, name); err != nil {
+		return fmt.Errorf("failed to delete tradingview template: %v", err)
+	}
+	return nil
+}
+
+// SaveStrategyPreset - Store a strategy preset
+func (s *SQLStore) SaveStrategyPreset(ctx context.Context, preset *models.StrategyPreset) error {
+	if err := s.putRecord(ctx, collectionPresets, preset.Name, preset); err != nil {
+		return fmt.Errorf("failed to save preset: %v", err)
+	}
+	return nil
+}
+
+// GetStrategyPreset - Get a strategy preset by name (nil if it does not exist)
+func (s *SQLStore) GetStrategyPreset(ctx context.Context, name string) (*models.StrategyPreset, error) {
+	var preset models.StrategyPreset
+	found, err := s.getRecord(ctx, collectionPresets, name, &preset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preset: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &preset, nil
+}
+
+// GetStrategyPresets - Get all strategy presets
+func (s *SQLStore) GetStrategyPresets(ctx context.Context) ([]*models.StrategyPreset, error) {
+	presets, err := listRecords[models.StrategyPreset](ctx, s, collectionPresets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get presets: %v", err)
+	}
+	return presets, nil
+}
+
+// DeleteStrategyPreset - Remove a strategy preset
+func (s *SQLStore) DeleteStrategyPreset(ctx context.Context, name string) error {
+	if err := s.deleteRecord(ctx, collectionPresets, name); err != nil {
+		return fmt.Errorf("failed to delete preset: %v", err)
+	}
+	return nil
+}
+
+// SaveFundingArbGroup - Store a funding arbitrage group
+func (s *SQLStore) SaveFundingArbGroup(ctx context.Context, group *models.FundingArbGroup) error {
+	if err := s.putRecord(ctx, collectionArbGroups, group.ID, group); err != nil {
+		return fmt.Errorf("failed to save funding arbitrage group: %v", err)
+	}
+	return nil
+}
+
+// GetFundingArbGroups - Get all funding arbitrage groups
+func (s *SQLStore) GetFundingArbGroups(ctx context.Context) ([]*models.FundingArbGroup, error) {
+	groups, err := listRecords[models.FundingArbGroup](ctx, s, collectionArbGroups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get funding arbitrage groups: %v", err)
+	}
+	return groups, nil
+}
+
+// SaveUserCredentials - Store a user's encrypted Binance credentials
+func (s *SQLStore) SaveUserCredentials(ctx context.Context, creds *models.UserCredentials) error {
+	if err := s.putRecord(ctx, collectionCredentials, creds.UserID, creds); err != nil {
+		return fmt.Errorf("failed to save credentials: %v", err)
+	}
+	return nil
+}
+
+// GetUserCredentials - Get a user's encrypted Binance credentials (nil if none)
+func (s *SQLStore) GetUserCredentials(ctx context.Context, userID string) (*models.UserCredentials, error) {
+	var creds models.UserCredentials
+	found, err := s.getRecord(ctx, collectionCredentials, userID, &creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &creds, nil
+}
+
+// DeleteUserCredentials - Remove a user's Binance credentials
+func (s *SQLStore) DeleteUserCredentials(ctx context.Context, userID string) error {
+	if err := s.deleteRecord(ctx, collectionCredentials, userID); err != nil {
+		return fmt.Errorf("failed to delete credentials: %v", err)
+	}
+	return nil
+}
+
+// SaveUserSettings - Store a user's settings
+func (s *SQLStore) SaveUserSettings(ctx context.Context, settings *models.UserSettings) error {
+	if err := s.putRecord(ctx, collectionUserSettings, settings.UserID, settings); err != nil {
+		return fmt.Errorf("failed to save user settings: %v", err)
+	}
+	return nil
+}
+
+// GetUserSettings - Get a user's settings (nil if none)
+func (s *SQLStore) GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error) {
+	var settings models.UserSettings
+	found, err := s.getRecord(ctx, collectionUserSettings, userID, &settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &settings, nil
+}
+
+// DeleteUserSettings - Remove a user's settings
+func (s *SQLStore) DeleteUserSettings(ctx context.Context, userID string) error {
+	if err := s.deleteRecord(ctx, collectionUserSettings, userID); err != nil {
+		return fmt.Errorf("failed to delete user settings: %v", err)
+	}
+	return nil
+}
+
+// SaveAPIKey - Store a managed API key record
+func (s *SQLStore) SaveAPIKey(ctx context.Context, key *models.APIKey) error {
+	if err := s.putRecord(ctx, collectionAPIKeys, key.ID, key); err != nil {
+		return fmt.Errorf("failed to save api key: %v", err)
+	}
+	return nil
+}
+
+// GetAPIKey - Get a managed API key record (nil if not found)
+func (s *SQLStore) GetAPIKey(ctx context.Context, keyID string) (*models.APIKey, error) {
+	var key models.APIKey
+	found, err := s.getRecord(ctx, collectionAPIKeys, keyID, &key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+// GetAPIKeys - Get all managed API key records
+func (s *SQLStore) GetAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	keys, err := listRecords[models.APIKey](ctx, s, collectionAPIKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api keys: %v", err)
+	}
+	return keys, nil
+}
+
+// SaveRoleAssignment - Store a user's role
+func (s *SQLStore) SaveRoleAssignment(ctx context.Context, assignment *models.RoleAssignment) error {
+	if err := s.putRecord(ctx, collectionRoles, assignment.UserID, assignment); err != nil {
+		return fmt.Errorf("failed to save role: %v", err)
+	}
+	return nil
+}
+
+// GetRoleAssignment - Get a user's role (nil if none assigned)
+func (s *SQLStore) GetRoleAssignment(ctx context.Context, userID string) (*models.RoleAssignment, error) {
+	var assignment models.RoleAssignment
+	found, err := s.getRecord(ctx, collectionRoles, userID, &assignment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &assignment, nil
+}
+
+// GetRoleAssignments - Get all role assignments
+func (s *SQLStore) GetRoleAssignments(ctx context.Context) ([]*models.RoleAssignment, error) {
+	roles, err := listRecords[models.RoleAssignment](ctx, s, collectionRoles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %v", err)
+	}
+	return roles, nil
+}
+
+// DeleteRoleAssignment - Remove a user's role (the default role applies again)
+func (s *SQLStore) DeleteRoleAssignment(ctx context.Context, userID string) error {
+	if err := s.deleteRecord(ctx, collectionRoles, userID); err != nil {
+		return fmt.Errorf("failed to delete role: %v", err)
+	}
+	return nil
+}
+
+// SaveTradeEvent - Append an event to a trade's history
+func (s *SQLStore) SaveTradeEvent(ctx context.Context, event *models.TradeEvent) error {
+	data, err := json.Marshal(event)
+	if err == nil {
+		err = s.exec(ctx, `INSERT INTO trade_events (trade_id, id, data) VALUES (?, ?, ?)`,
+			event.TradeID, event.ID, string(data))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save trade event: %v", err)
+	}
+	return nil
+}
+
+// GetTradeEvents - Get a trade's history, oldest first
+func (s *SQLStore) GetTradeEvents(ctx context.Context, tradeID string) ([]*models.TradeEvent, error) {
+	rows, err := s.db.QueryContext(ctx, s.dialect.rebind(`SELECT data FROM trade_events WHERE trade_id = ? ORDER BY id`), tradeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade events: %v", err)
+	}
+	defer rows.Close()
+
+	events := []*models.TradeEvent{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to get trade events: %v", err)
+		}
+		var event models.TradeEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal trade events: %v", err)
+		}
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get trade events: %v", err)
+	}
+	return events, nil
+}
+
+// SaveAuditEntry - Append an audit log entry
+func (s *SQLStore) SaveAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = s.exec(ctx, `INSERT INTO audit_entries (id, data) VALUES (?, ?)
+			ON CONFLICT (id) DO UPDATE SET data = excluded.data`, entry.ID, string(data))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save audit entry: %v", err)
+	}
+	return nil
+}
+
+// GetAuditEntries - Get up to limit audit entries between two entry IDs
+// (inclusive), newest last
+func (s *SQLStore) GetAuditEntries(ctx context.Context, startID, endID string, limit int) ([]*models.AuditEntry, error) {
+	rows, err := s.db.QueryContext(ctx, s.dialect.rebind(`SELECT data FROM audit_entries
+		WHERE id >= ? AND id <= ? ORDER BY id DESC LIMIT ?`), startID, endID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.AuditEntry{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to get audit entries: %v", err)
+		}
+		var entry models.AuditEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal audit entries: %v", err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %v", err)
+	}
+
+	// Read newest first so the limit keeps the latest; return oldest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// SaveIdempotencyRecord - Store the response to replay for an idempotency key
+func (s *SQLStore) SaveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if err := s.putRecord(ctx, collectionIdempotency, record.Key, record); err != nil {
+		return fmt.Errorf("failed to save idempotency record: %v", err)
+	}
+	return nil
+}
+
+// GetIdempotencyRecord - Get the stored response for an idempotency key (nil
+// if none)
+func (s *SQLStore) GetIdempotencyRecord(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	var record models.IdempotencyRecord
+	found, err := s.getRecord(ctx, collectionIdempotency, key, &record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// Ping - Check that the database is reachable and accepts writes
+func (s *SQLStore) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to reach database: %v", err)
+	}
+	if err := s.putRecord(ctx, collectionHealth, "probe", map[string]int64{"time": time.Now().Unix()}); err != nil {
+		return fmt.Errorf("failed to write health probe: %v", err)
+	}
+	return nil
+}
+
+// Flush - Nothing to flush: writes go straight to the database
+func (s *SQLStore) Flush(ctx context.Context) error {
+	return nil
+}
+
+// Close - Close the database connection
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+// putRecord stores a record as JSON, replacing any previous version
+func (s *SQLStore) putRecord(ctx context.Context, collection, id string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.exec(ctx, `INSERT INTO records (collection, id, data) VALUES (?, ?, ?)
+		ON CONFLICT (collection, id) DO UPDATE SET data = excluded.data`, collection, id, string(data))
+}
+
+// getRecord reads a record into value, false if it does not exist
+func (s *SQLStore) getRecord(ctx context.Context, collection, id string, value interface{}) (bool, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, s.dialect.rebind(`SELECT data FROM records WHERE collection = ? AND id = ?`),
+		collection, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(data), value); err != nil {
+		return false, fmt.Errorf("failed to unmarshal %s record: %v", collection, err)
+	}
+	return true, nil
+}
+
+// deleteRecord removes a record (no error if it does not exist)
+func (s *SQLStore) deleteRecord(ctx context.Context, collection, id string) error {
+	return s.exec(ctx, `DELETE FROM records WHERE collection = ? AND id = ?`, collection, id)
+}
+
+// listRecords reads every record of a collection
+func listRecords[T any](ctx context.Context, s *SQLStore, collection string) ([]*T, error) {
+	rows, err := s.db.QueryContext(ctx, s.dialect.rebind(`SELECT data FROM records WHERE collection = ?`), collection)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []*T{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		record := new(T)
+		if err := json.Unmarshal([]byte(data), record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s record: %v", collection, err)
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
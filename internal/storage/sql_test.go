@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"context"
+	"crypto-trading-api/internal/models"
+	"database/sql"
+	"testing"
+)
+
+// openMemory opens a migrated in-memory SQLite store
+func openMemory(t *testing.T) *SQLStore {
+	t.Helper()
+	store, err := openSQL(context.Background(), sqlite, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store.(*SQLStore)
+}
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect dialect
+		query   string
+		want    string
+	}{
+		{"sqlite keeps ?", sqlite, `SELECT data FROM trades WHERE id = ? AND user_id = ?`, `SELECT data FROM trades WHERE id = ? AND user_id = ?`},
+		{"postgres numbers", postgres, `SELECT data FROM trades WHERE id = ? AND user_id = ?`, `SELECT data FROM trades WHERE id = $1 AND user_id = $2`},
+		{"postgres without args", postgres, `SELECT data FROM trades`, `SELECT data FROM trades`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.rebind(tt.query); got != tt.want {
+				t.Errorf("rebind = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name      string
+		dialect   dialect
+		statement string
+		want      string
+	}{
+		{"sqlite key", sqlite, `id {key} PRIMARY KEY`, `id TEXT PRIMARY KEY`},
+		{"postgres key", postgres, `id {key} PRIMARY KEY`, `id TEXT COLLATE "C" PRIMARY KEY`},
+		{"sqlite field", sqlite, `COALESCE({data.symbol}, '')`, `COALESCE(json_extract(data, '$.symbol'), '')`},
+		{"postgres field", postgres, `COALESCE({data.symbol}, '')`, `COALESCE((data::json->>'symbol'), '')`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.expand(tt.statement); got != tt.want {
+				t.Errorf("expand = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	latest := migrations[len(migrations)-1].version
+
+	tests := []struct {
+		name  string
+		from  int    // Versions applied before, with a trade stored at that version
+		trade string // Trade document stored at version from
+	}{
+		{name: "empty database"},
+		{name: "initial schema", from: 1, trade: `{"id":"a","userId":"u1","symbol":"BTCUSDT","status":"ACTIVE"}`},
+		{name: "already latest", from: latest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db, err := sql.Open("sqlite", ":memory:")
+			if err != nil {
+				t.Fatal(err)
+			}
+			db.SetMaxOpenConns(1)
+			defer db.Close()
+
+			if tt.from > 0 {
+				all := migrations
+				migrations = all[:tt.from]
+				err := migrate(ctx, db, sqlite)
+				migrations = all
+				if err != nil {
+					t.Fatalf("migrate to %d: %v", tt.from, err)
+				}
+			}
+			if tt.trade != "" {
+				_, err := db.ExecContext(ctx, `INSERT INTO trades (id, user_id, status, created_at, data) VALUES ('a', 'u1', 'ACTIVE', 1, ?)`, tt.trade)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Applying twice is a no-op
+			for i := 0; i < 2; i++ {
+				if err := migrate(ctx, db, sqlite); err != nil {
+					t.Fatalf("migrate: %v", err)
+				}
+			}
+
+			var version, applied int
+			if err := db.QueryRowContext(ctx, `SELECT MAX(version), COUNT(*) FROM schema_migrations`).Scan(&version, &applied); err != nil {
+				t.Fatal(err)
+			}
+			if version != latest || applied != len(migrations) {
+				t.Errorf("schema at version %d with %d migrations, want %d with %d", version, applied, latest, len(migrations))
+			}
+
+			if tt.trade != "" {
+				var symbol string
+				if err := db.QueryRowContext(ctx, `SELECT symbol FROM trades WHERE id = 'a'`).Scan(&symbol); err != nil {
+					t.Fatal(err)
+				}
+				if symbol != "BTCUSDT" {
+					t.Errorf("backfilled symbol = %q, want BTCUSDT", symbol)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLStoreQueryTrades(t *testing.T) {
+	ctx := context.Background()
+	store := openMemory(t)
+
+	for _, trade := range []*models.Trade{
+		{ID: "a", UserID: "u1", Symbol: "BTCUSDT", Status: "ACTIVE", CreatedAt: 100},
+		{ID: "b", UserID: "u1", Symbol: "ETHUSDT", Status: "CLOSED", CreatedAt: 200},
+		{ID: "c", UserID: "u2", Symbol: "BTCUSDT", Status: "ACTIVE", CreatedAt: 300},
+		{ID: "d", UserID: "u2", Symbol: "BTCUSDT", Status: "CLOSED", CreatedAt: 300},
+	} {
+		if err := store.SaveTrade(ctx, trade); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		query    models.TradeQuery
+		want     []string // Trade IDs, newest first
+		nextPage bool
+	}{
+		{name: "all", query: models.TradeQuery{Limit: 10}, want: []string{"d", "c", "b", "a"}},
+		{name: "by user", query: models.TradeQuery{UserID: "u1", Limit: 10}, want: []string{"b", "a"}},
+		{name: "by status and symbol", query: models.TradeQuery{Status: "ACTIVE", Symbol: "BTCUSDT", Limit: 10}, want: []string{"c", "a"}},
+		{name: "time range", query: models.TradeQuery{From: 150, To: 250, Limit: 10}, want: []string{"b"}},
+		{name: "first page", query: models.TradeQuery{Limit: 2}, want: []string{"d", "c"}, nextPage: true},
+		{name: "after cursor", query: models.TradeQuery{Limit: 2, After: &models.TradeCursor{CreatedAt: 300, ID: "c"}}, want: []string{"b", "a"}},
+		{name: "no match", query: models.TradeQuery{UserID: "u3", Limit: 10}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := store.QueryTrades(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, trade := range page.Trades {
+				got = append(got, trade.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("trades = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("trades = %v, want %v", got, tt.want)
+				}
+			}
+			if (page.NextCursor != "") != tt.nextPage {
+				t.Errorf("next cursor = %q, want one: %v", page.NextCursor, tt.nextPage)
+			}
+		})
+	}
+}
+
+func TestSQLStoreTradeLifecycle(t *testing.T) {
+	ctx := context.Background()
+	store := openMemory(t)
+
+	tests := []struct {
+		name   string
+		apply  func() error
+		id     string
+		status string // Stored status ("" = gone)
+	}{
+		{
+			name:   "save",
+			apply:  func() error { return store.SaveTrade(ctx, &models.Trade{ID: "a", UserID: "u1", Status: "ACTIVE"}) },
+			id:     "a",
+			status: "ACTIVE",
+		},
+		{
+			name:   "update",
+			apply:  func() error { return store.UpdateTrade(ctx, &models.Trade{ID: "a", UserID: "u1", Status: "CLOSED"}) },
+			id:     "a",
+			status: "CLOSED",
+		},
+		{
+			name:  "delete",
+			apply: func() error { return store.DeleteTrade(ctx, "a", "u1") },
+			id:    "a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.apply(); err != nil {
+				t.Fatal(err)
+			}
+			trade, err := store.GetTrade(ctx, tt.id)
+			switch {
+			case tt.status == "" && err == nil:
+				t.Errorf("trade %s still stored as %s", tt.id, trade.Status)
+			case tt.status != "" && err != nil:
+				t.Errorf("GetTrade: %v", err)
+			case tt.status != "" && trade.Status != tt.status:
+				t.Errorf("status = %s, want %s", trade.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestSQLStoreRecords(t *testing.T) {
+	ctx := context.Background()
+	store := openMemory(t)
+
+	if err := store.SaveStrategyPreset(ctx, &models.StrategyPreset{Name: "scalp-btc", UserID: "u1", Leverage: 10, MaxPositions: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		preset   string
+		found    bool
+		leverage int
+	}{
+		{"stored", "scalp-btc", true, 10},
+		{"missing", "swing-eth", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preset, err := store.GetStrategyPreset(ctx, tt.preset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (preset != nil) != tt.found {
+				t.Fatalf("preset = %+v, want found: %v", preset, tt.found)
+			}
+			if preset != nil && (preset.Leverage != tt.leverage || preset.MaxPositions != 2) {
+				t.Errorf("preset = %+v, want leverage %d", preset, tt.leverage)
+			}
+		})
+	}
+}
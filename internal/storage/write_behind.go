@@ -0,0 +1,466 @@
+package storage
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Write-behind retry policy. Trade writes are never given up on: a write
+// that keeps failing is retried every writeBehindMaxBackoff, and reported
+// as failing once writeBehindFailingAttempts attempts in a row failed.
+const (
+	writeBehindBackoff         = time.Second
+	writeBehindMaxBackoff      = 5 * time.Minute
+	writeBehindFailingAttempts = 10
+)
+
+// WriteBehindConfig configures the write-behind queue
+type WriteBehindConfig struct {
+	DrainTimeout time.Duration // How long Close waits for queued writes
+	JournalDir   string        // Directory queued writes are persisted to until stored
+}
+
+// WriteBehindStats reports the write-behind backlog
+type WriteBehindStats struct {
+	Pending       int     `json:"pending"`             // Trades with a write waiting to be stored
+	OldestSeconds float64 `json:"oldestSeconds"`       // Age of the oldest waiting write
+	Flushed       uint64  `json:"flushed"`             // Writes stored since startup
+	Retries       uint64  `json:"retries"`             // Failed attempts that were retried
+	Failing       int     `json:"failing"`             // Trades whose writes failed writeBehindFailingAttempts times in a row
+	Unpersisted   int     `json:"unpersisted"`         // Waiting writes the journal failed to persist
+	LastError     string  `json:"lastError,omitempty"` // Latest failure of a waiting write
+	Journal       string  `json:"journal"`
+}
+
+// tradeWrite is a queued trade save or delete, as persisted in the journal
+type tradeWrite struct {
+	Trade    *models.Trade `json:"trade,omitempty"` // Snapshot to store; nil for a delete
+	TradeID  string        `json:"tradeId"`
+	UserID   string        `json:"userId"`
+	QueuedAt time.Time     `json:"queuedAt"`
+
+	ctx context.Context
+	seq uint64 // Version of the trade's pending write
+}
+
+// pendingTrade is the latest write of a trade that is not stored yet. Only
+// the latest state of a trade needs storing, so a newer write replaces an
+// older one still waiting.
+type pendingTrade struct {
+	write       *tradeWrite
+	since       time.Time // First write not stored yet
+	attempts    int       // Failed attempts in a row
+	nextAttempt time.Time
+	lastError   string
+	persisted   bool
+}
+
+// WriteBehind queues trade writes and stores them in the background, so
+// trade execution never waits on the database. Queued writes are persisted
+// to a journal directory until stored, so a crash or a database outage
+// longer than the shutdown drain loses nothing: the journal is replayed on
+// the next start. Each trade is retried on its own backoff, so a write that
+// keeps failing never holds up the others. GetTrade sees queued writes;
+// list queries catch up once the queue is flushed. Every other call goes
+// straight to the wrapped store.
+type WriteBehind struct {
+	TradeStore
+	config WriteBehindConfig
+
+	mu      sync.Mutex
+	pending map[string]*pendingTrade
+	order   []string // Trade IDs of pending, oldest first
+	seq     uint64
+	stats   WriteBehindStats
+	wake    chan struct{}
+	idle    chan struct{} // Closed while nothing is pending
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewWriteBehind replays the writes left in the journal, then starts
+// flushing trade writes to store. Close drains the queue (for up to
+// DrainTimeout) before closing store.
+func NewWriteBehind(store TradeStore, config WriteBehindConfig) (*WriteBehind, error) {
+	if err := os.MkdirAll(config.JournalDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create write-behind journal: %v", err)
+	}
+
+	w := &WriteBehind{
+		TradeStore: store,
+		config:     config,
+		pending:    make(map[string]*pendingTrade),
+		wake:       make(chan struct{}, 1),
+		idle:       make(chan struct{}),
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	w.stats.Journal = config.JournalDir
+
+	writes, err := w.loadJournal()
+	if err != nil {
+		return nil, err
+	}
+	for _, write := range writes {
+		w.seq++
+		write.seq = w.seq
+		write.ctx = context.Background()
+		w.pending[write.TradeID] = &pendingTrade{write: write, since: write.QueuedAt, persisted: true}
+		w.order = append(w.order, write.TradeID)
+	}
+	if len(writes) > 0 {
+		logging.Warn().Msgf("Write-behind: replaying %d trade writes left in %s", len(writes), config.JournalDir)
+	} else {
+		close(w.idle)
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// SaveTrade - Queue a new trade
+func (w *WriteBehind) SaveTrade(ctx context.Context, trade *models.Trade) error {
+	w.enqueue(ctx, trade.ID, trade.UserID, trade)
+	return nil
+}
+
+// UpdateTrade - Queue a trade update
+func (w *WriteBehind) UpdateTrade(ctx context.Context, trade *models.Trade) error {
+	w.enqueue(ctx, trade.ID, trade.UserID, trade)
+	return nil
+}
+
+// DeleteTrade - Queue a trade deletion, replacing the trade's waiting writes
+func (w *WriteBehind) DeleteTrade(ctx context.Context, tradeID string, userID string) error {
+	w.enqueue(ctx, tradeID, userID, nil)
+	return nil
+}
+
+// UpdateTradePnL - Close a trade with its PnL
+func (w *WriteBehind) UpdateTradePnL(ctx context.Context, tradeID string, pnl float64, userID string) error {
+	trade, err := w.GetTrade(ctx, tradeID)
+	if err != nil {
+		return err
+	}
+
+	trade.PnL = pnl
+	trade.Status = "CLOSED"
+	trade.ClosedAt = time.Now().Unix()
+
+	return w.UpdateTrade(ctx, trade)
+}
+
+// BatchUpdateTrades - Queue updates of multiple trades
+func (w *WriteBehind) BatchUpdateTrades(ctx context.Context, trades []*models.Trade) error {
+	for _, trade := range trades {
+		w.enqueue(ctx, trade.ID, trade.UserID, trade)
+	}
+	return nil
+}
+
+// GetTrade - Get a trade, including writes not stored yet
+func (w *WriteBehind) GetTrade(ctx context.Context, tradeID string) (*models.Trade, error) {
+	w.mu.Lock()
+	pending, ok := w.pending[tradeID]
+	var snapshot *models.Trade
+	if ok && pending.write.Trade != nil {
+		trade := *pending.write.Trade
+		snapshot = &trade
+	}
+	w.mu.Unlock()
+
+	if !ok {
+		return w.TradeStore.GetTrade(ctx, tradeID)
+	}
+	if snapshot == nil {
+		return nil, fmt.Errorf("trade not found")
+	}
+	return snapshot, nil
+}
+
+// Backlog reports the queued writes, flush counters and failing writes
+func (w *WriteBehind) Backlog() WriteBehindStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := w.stats
+	stats.Pending = len(w.pending)
+	var oldest time.Time
+	for _, pending := range w.pending {
+		if oldest.IsZero() || pending.since.Before(oldest) {
+			oldest = pending.since
+		}
+		if pending.attempts >= writeBehindFailingAttempts {
+			stats.Failing++
+		}
+		if !pending.persisted {
+			stats.Unpersisted++
+		}
+		if pending.lastError != "" {
+			stats.LastError = pending.lastError
+		}
+	}
+	if !oldest.IsZero() {
+		stats.OldestSeconds = time.Since(oldest).Seconds()
+	}
+	return stats
+}
+
+// Drain waits until every queued write is stored, or ctx is done
+func (w *WriteBehind) Drain(ctx context.Context) error {
+	w.mu.Lock()
+	idle := w.idle
+	w.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d trade writes not stored: %v", w.Backlog().Pending, ctx.Err())
+	}
+}
+
+// Flush stores the queued writes, then flushes the wrapped store
+func (w *WriteBehind) Flush(ctx context.Context) error {
+	if err := w.Drain(ctx); err != nil {
+		return err
+	}
+	return w.TradeStore.Flush(ctx)
+}
+
+// Close drains the queue, then closes the wrapped store. Writes still
+// waiting stay in the journal for the next start.
+func (w *WriteBehind) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.DrainTimeout)
+	defer cancel()
+
+	logging.Info().Msgf("Storing %d queued trade writes before shutdown", w.Backlog().Pending)
+	if err := w.Drain(ctx); err != nil {
+		logging.Warn().Err(err).Msgf("Write-behind: writes kept in %s for the next start", w.config.JournalDir)
+	}
+	close(w.stop)
+	<-w.stopped
+
+	return w.TradeStore.Close()
+}
+
+// enqueue persists and queues a snapshot of trade (nil to delete it)
+func (w *WriteBehind) enqueue(ctx context.Context, tradeID, userID string, trade *models.Trade) {
+	write := &tradeWrite{
+		TradeID:  tradeID,
+		UserID:   userID,
+		QueuedAt: time.Now(),
+		ctx:      context.WithoutCancel(ctx),
+	}
+	if trade != nil {
+		snapshot := *trade
+		write.Trade = &snapshot
+	}
+
+	w.mu.Lock()
+	w.seq++
+	write.seq = w.seq
+
+	if len(w.pending) == 0 {
+		w.idle = make(chan struct{})
+	}
+	pending := w.pending[tradeID]
+	if pending == nil {
+		pending = &pendingTrade{since: write.QueuedAt}
+		w.pending[tradeID] = pending
+		w.order = append(w.order, tradeID)
+	}
+	pending.write = write
+	pending.nextAttempt = time.Time{} // A new state is worth trying now
+
+	// Persisted under the lock, so an older write's removal never deletes
+	// a newer journal entry
+	pending.persisted = true
+	if err := w.persist(write); err != nil {
+		pending.persisted = false
+		logging.Error().Err(err).Str(logging.FieldTradeID, tradeID).Msgf("Write-behind: failed to journal trade %s, it is only held in memory", tradeID)
+	}
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run stores due writes, oldest first, until Close
+func (w *WriteBehind) run() {
+	defer close(w.stopped)
+
+	for {
+		write, wait := w.next()
+		if write == nil {
+			var timer <-chan time.Time
+			if wait > 0 {
+				timer = time.After(wait)
+			}
+			select {
+			case <-w.wake:
+			case <-timer:
+			case <-w.stop:
+				return
+			}
+			continue
+		}
+
+		var err error
+		if write.Trade != nil {
+			err = w.TradeStore.UpdateTrade(write.ctx, write.Trade)
+		} else {
+			err = w.TradeStore.DeleteTrade(write.ctx, write.TradeID, write.UserID)
+		}
+		w.finish(write, err)
+
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+	}
+}
+
+// next returns the oldest write due for an attempt, or how long until one
+// is due (0 if nothing is pending)
+func (w *WriteBehind) next() (*tradeWrite, time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	var wait time.Duration
+	for _, tradeID := range w.order {
+		pending := w.pending[tradeID]
+		if !pending.nextAttempt.After(now) {
+			return pending.write, 0
+		}
+		if until := pending.nextAttempt.Sub(now); wait == 0 || until < wait {
+			wait = until
+		}
+	}
+	return nil, wait
+}
+
+// finish records the outcome of storing a write. A stored write leaves the
+// queue and the journal unless a newer one replaced it meanwhile; a failed
+// one is retried after its trade's backoff.
+func (w *WriteBehind) finish(write *tradeWrite, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	pending := w.pending[write.TradeID]
+	if err == nil {
+		w.stats.Flushed++
+		if pending.write.seq != write.seq {
+			pending.attempts, pending.lastError = 0, ""
+			return
+		}
+
+		delete(w.pending, write.TradeID)
+		for i, tradeID := range w.order {
+			if tradeID == write.TradeID {
+				w.order = append(w.order[:i], w.order[i+1:]...)
+				break
+			}
+		}
+		if err := os.Remove(w.journalPath(write.TradeID)); err != nil && !os.IsNotExist(err) {
+			logging.Warn().Err(err).Str(logging.FieldTradeID, write.TradeID).Msg("Write-behind: failed to remove journal entry")
+		}
+		if len(w.pending) == 0 {
+			close(w.idle)
+		}
+		return
+	}
+
+	w.stats.Retries++
+	pending.attempts++
+	pending.lastError = err.Error()
+	backoff := writeBehindBackoff << min(pending.attempts-1, 16)
+	pending.nextAttempt = time.Now().Add(min(backoff, writeBehindMaxBackoff))
+
+	event := logging.Warn()
+	if pending.attempts == writeBehindFailingAttempts {
+		event = logging.Error()
+	}
+	event.Err(err).Str(logging.FieldTradeID, write.TradeID).Msgf("Write-behind: attempt %d for trade %s failed, retrying in %v", pending.attempts, write.TradeID, pending.nextAttempt.Sub(time.Now()).Round(time.Second))
+}
+
+// journalPath is the journal file of a trade's pending write
+func (w *WriteBehind) journalPath(tradeID string) string {
+	return filepath.Join(w.config.JournalDir, url.PathEscape(tradeID)+".json")
+}
+
+// persist writes a trade's pending write to the journal, replacing the
+// previous one atomically
+func (w *WriteBehind) persist(write *tradeWrite) error {
+	data, err := json.Marshal(write)
+	if err != nil {
+		return fmt.Errorf("failed to encode write: %v", err)
+	}
+
+	path := w.journalPath(write.TradeID)
+	tmp, err := os.CreateTemp(w.config.JournalDir, ".write-*")
+	if err != nil {
+		return fmt.Errorf("failed to create journal entry: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write journal entry: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync journal entry: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write journal entry: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write journal entry: %v", err)
+	}
+	return nil
+}
+
+// loadJournal reads the writes left in the journal, oldest first
+func (w *WriteBehind) loadJournal() ([]*tradeWrite, error) {
+	entries, err := os.ReadDir(w.config.JournalDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read write-behind journal: %v", err)
+	}
+
+	writes := []*tradeWrite{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(w.config.JournalDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read write-behind journal: %v", err)
+		}
+		var write tradeWrite
+		if err := json.Unmarshal(data, &write); err != nil || write.TradeID == "" {
+			return nil, fmt.Errorf("corrupt write-behind journal entry %s: %v", name, err)
+		}
+		writes = append(writes, &write)
+	}
+
+	sort.Slice(writes, func(i, j int) bool {
+		return writes[i].QueuedAt.Before(writes[j].QueuedAt)
+	})
+	return writes, nil
+}
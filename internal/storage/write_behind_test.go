@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"context"
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyStore stores trades in memory, failing the writes of some trades a
+// number of times first
+type flakyStore struct {
+	TradeStore
+
+	mu       sync.Mutex
+	failures map[string]int // Trade ID -> writes still to fail (-1 = always)
+	trades   map[string]*models.Trade
+	attempts map[string]int
+}
+
+func newFlakyStore(failures map[string]int) *flakyStore {
+	return &flakyStore{failures: failures, trades: make(map[string]*models.Trade), attempts: make(map[string]int)}
+}
+
+func (s *flakyStore) fail(tradeID string) error {
+	s.attempts[tradeID]++
+	switch n := s.failures[tradeID]; {
+	case n < 0:
+		return fmt.Errorf("database unavailable")
+	case n > 0:
+		s.failures[tradeID]--
+		return fmt.Errorf("database unavailable")
+	}
+	return nil
+}
+
+func (s *flakyStore) UpdateTrade(ctx context.Context, trade *models.Trade) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.fail(trade.ID); err != nil {
+		return err
+	}
+	snapshot := *trade
+	s.trades[trade.ID] = &snapshot
+	return nil
+}
+
+func (s *flakyStore) DeleteTrade(ctx context.Context, tradeID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.fail(tradeID); err != nil {
+		return err
+	}
+	delete(s.trades, tradeID)
+	return nil
+}
+
+func (s *flakyStore) GetTrade(ctx context.Context, tradeID string) (*models.Trade, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	trade, ok := s.trades[tradeID]
+	if !ok {
+		return nil, fmt.Errorf("trade not found")
+	}
+	snapshot := *trade
+	return &snapshot, nil
+}
+
+func (s *flakyStore) stored(tradeID string) (*models.Trade, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	trade, ok := s.trades[tradeID]
+	return trade, ok
+}
+
+func (s *flakyStore) Flush(ctx context.Context) error { return nil }
+func (s *flakyStore) Close() error                    { return nil }
+
+func TestWriteBehindRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures map[string]int
+		writes   func(w *WriteBehind)
+		want     map[string]string // Trade ID -> stored status ("" = not stored)
+		stuck    int               // Trades expected to still be pending
+	}{
+		{
+			name:   "stored first time",
+			writes: func(w *WriteBehind) { w.SaveTrade(context.Background(), &models.Trade{ID: "a", Status: "ACTIVE"}) },
+			want:   map[string]string{"a": "ACTIVE"},
+		},
+		{
+			name:     "retried until stored",
+			failures: map[string]int{"a": 2},
+			writes:   func(w *WriteBehind) { w.SaveTrade(context.Background(), &models.Trade{ID: "a", Status: "ACTIVE"}) },
+			want:     map[string]string{"a": "ACTIVE"},
+		},
+		{
+			name:     "latest state wins",
+			failures: map[string]int{"a": 1},
+			writes: func(w *WriteBehind) {
+				w.SaveTrade(context.Background(), &models.Trade{ID: "a", Status: "ACTIVE"})
+				w.UpdateTrade(context.Background(), &models.Trade{ID: "a", Status: "CLOSED"})
+			},
+			want: map[string]string{"a": "CLOSED"},
+		},
+		{
+			name: "delete after save",
+			writes: func(w *WriteBehind) {
+				w.SaveTrade(context.Background(), &models.Trade{ID: "a", Status: "ACTIVE"})
+				w.DeleteTrade(context.Background(), "a", "")
+			},
+			want: map[string]string{"a": ""},
+		},
+		{
+			name:     "stuck write does not block others",
+			failures: map[string]int{"a": -1},
+			writes: func(w *WriteBehind) {
+				w.SaveTrade(context.Background(), &models.Trade{ID: "a", Status: "ACTIVE"})
+				w.SaveTrade(context.Background(), &models.Trade{ID: "b", Status: "ACTIVE"})
+			},
+			want:  map[string]string{"a": "", "b": "ACTIVE"},
+			stuck: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := tt.failures
+			if failures == nil {
+				failures = map[string]int{}
+			}
+			store := newFlakyStore(failures)
+			dir := t.TempDir()
+			w, err := NewWriteBehind(store, WriteBehindConfig{DrainTimeout: time.Second, JournalDir: dir})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.writes(w)
+
+			timeout := 5 * time.Second
+			if tt.stuck > 0 {
+				timeout = time.Second // Never drains; the others are stored meanwhile
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			err = w.Drain(ctx)
+			if tt.stuck == 0 && err != nil {
+				t.Fatalf("Drain: %v", err)
+			}
+
+			for tradeID, status := range tt.want {
+				trade, ok := store.stored(tradeID)
+				switch {
+				case status == "" && ok:
+					t.Errorf("trade %s stored, want not stored", tradeID)
+				case status != "" && (!ok || trade.Status != status):
+					t.Errorf("trade %s stored as %+v, want %s", tradeID, trade, status)
+				}
+			}
+			if backlog := w.Backlog(); backlog.Pending != tt.stuck {
+				t.Errorf("pending = %d, want %d", backlog.Pending, tt.stuck)
+			}
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != tt.stuck {
+				t.Errorf("journal entries = %d, want %d", len(entries), tt.stuck)
+			}
+			w.Close()
+		})
+	}
+}
+
+func TestWriteBehindReplaysJournal(t *testing.T) {
+	dir := t.TempDir()
+
+	// Never stored before shutdown: the database is down
+	down := newFlakyStore(map[string]int{"a": -1})
+	w, err := NewWriteBehind(down, WriteBehindConfig{DrainTimeout: 10 * time.Millisecond, JournalDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SaveTrade(context.Background(), &models.Trade{ID: "a", UserID: "u1", Status: "ACTIVE"})
+	if trade, err := w.GetTrade(context.Background(), "a"); err != nil || trade.Status != "ACTIVE" {
+		t.Fatalf("GetTrade of a queued write = %+v, %v", trade, err)
+	}
+	w.Close()
+	if _, err := os.Stat(filepath.Join(dir, "a.json")); err != nil {
+		t.Fatalf("journal entry missing after shutdown: %v", err)
+	}
+
+	// Stored on the next start
+	up := newFlakyStore(nil)
+	w, err = NewWriteBehind(up, WriteBehindConfig{DrainTimeout: time.Second, JournalDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.Drain(ctx); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+	if trade, ok := up.stored("a"); !ok || trade.UserID != "u1" {
+		t.Errorf("replayed trade = %+v, want trade a of u1", trade)
+	}
+}
@@ -0,0 +1,136 @@
+package tradehistory
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sources of trade writes, recorded with each event
+const (
+	SourceAPI             = "api"
+	SourceCopyTrading     = "copy-trading"
+	SourcePositionQueue   = "position-queue"
+	SourceMonitor         = "monitor"
+	SourceUserDataStream  = "user-data-stream"
+	SourceClosePosition   = "close-position"
+	SourceOrderReconciler = "order-reconciler"
+	SourcePnLReconciler   = "pnl-reconciler"
+	SourceMarginAdjust    = "margin-adjustment"
+	SourceMarginGuard     = "margin-guard"
+	SourceExcursions      = "excursion-tracker"
+	SourceNewsBlackout    = "news-blackout"
+	SourceCloseWatcher    = "close-watcher"
+	SourceAdoption        = "adoption"
+)
+
+type sourceKey struct{}
+
+// WithSource tags the trade writes made with ctx with what made them
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// Source returns what a context's trade writes come from ("" if untagged)
+func Source(ctx context.Context) string {
+	source, _ := ctx.Value(sourceKey{}).(string)
+	return source
+}
+
+// Store persists trade events
+type Store interface {
+	SaveTradeEvent(ctx context.Context, event *models.TradeEvent) error
+	GetTradeEvents(ctx context.Context, tradeID string) ([]*models.TradeEvent, error)
+}
+
+// Recorder appends an event to a trade's history whenever a write changes its
+// status, orders, prices or margin. It remembers the last event of open trades, so
+// only the first write after a restart reads the history back.
+type Recorder struct {
+	store Store
+	mu    sync.Mutex
+	last  map[string]*models.TradeEvent
+}
+
+// NewRecorder creates a recorder; register its Record with OnTradeSaved
+func NewRecorder(store Store) *Recorder {
+	return &Recorder{store: store, last: make(map[string]*models.TradeEvent)}
+}
+
+// Record appends an event for a written trade unless its state is unchanged
+func (r *Recorder) Record(ctx context.Context, trade *models.Trade) {
+	now := time.Now()
+	event := &models.TradeEvent{
+		ID:            fmt.Sprintf("%019d-%s", now.UnixNano(), uuid.New().String()[:8]),
+		TradeID:       trade.ID,
+		Timestamp:     now.UnixMilli(),
+		Source:        Source(ctx),
+		Status:        trade.Status,
+		OrderID:       trade.OrderID,
+		SLOrderID:     trade.SLOrderID,
+		TPOrderID:     trade.TPOrderID,
+		EntryPrice:    trade.EntryPrice,
+		ExecutedPrice: trade.ExecutedPrice,
+		StopLoss:      trade.StopLoss,
+		TakeProfit:    trade.TakeProfit,
+		PnL:           trade.PnL,
+		MarginAdded:   trade.MarginAdded,
+		Error:         trade.Error,
+	}
+
+	last, err := r.lastEvent(ctx, trade.ID)
+	if err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("Trade history: failed to read history of trade %s", trade.ID)
+	}
+	if last != nil {
+		if sameState(last, event) {
+			return
+		}
+		event.PrevStatus = last.Status
+	}
+
+	if err := r.store.SaveTradeEvent(ctx, event); err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("Trade history: failed to record %s of trade %s", event.Status, trade.ID)
+		return
+	}
+
+	// Done trades are dropped from memory; later writes (e.g. reconciliation)
+	// read their history again
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if models.FinalStatus(event.Status) {
+		delete(r.last, trade.ID)
+	} else {
+		r.last[trade.ID] = event
+	}
+}
+
+// lastEvent returns the latest event of a trade, nil if it has none
+func (r *Recorder) lastEvent(ctx context.Context, tradeID string) (*models.TradeEvent, error) {
+	r.mu.Lock()
+	last, ok := r.last[tradeID]
+	r.mu.Unlock()
+	if ok {
+		return last, nil
+	}
+
+	events, err := r.store.GetTradeEvents(ctx, tradeID)
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+	return events[len(events)-1], nil
+}
+
+// sameState reports whether two events record the same trade state
+func sameState(a, b *models.TradeEvent) bool {
+	return a.Status == b.Status &&
+		a.OrderID == b.OrderID && a.SLOrderID == b.SLOrderID && a.TPOrderID == b.TPOrderID &&
+		a.EntryPrice == b.EntryPrice && a.ExecutedPrice == b.ExecutedPrice &&
+		a.StopLoss == b.StopLoss && a.TakeProfit == b.TakeProfit &&
+		a.PnL == b.PnL && a.MarginAdded == b.MarginAdded && a.Error == b.Error
+}
@@ -0,0 +1,246 @@
+package tradingview
+
+import (
+	"context"
+	"crypto-trading-api/internal/models"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TemplateStore looks up alert templates by name
+type TemplateStore interface {
+	GetTradingViewTemplate(ctx context.Context, name string) (*models.TradingViewTemplate, error)
+}
+
+// Alert holds the fields of a TradingView alert message, keyed by lower-case name
+type Alert map[string]string
+
+// Field aliases, in order of preference. TradingView placeholder names are
+// accepted as keys so messages like "action={{strategy.order.action}}" and
+// {"strategy.order.action": "{{strategy.order.action}}"} both work.
+var (
+	templateKeys   = []string{"template"}
+	symbolKeys     = []string{"ticker", "symbol", "syminfo.ticker"}
+	actionKeys     = []string{"action", "side", "strategy.order.action"}
+	priceKeys      = []string{"price", "entryprice", "strategy.order.price", "close"}
+	stopLossKeys   = []string{"stoploss", "sl"}
+	takeProfitKeys = []string{"takeprofit", "tp"}
+	leverageKeys   = []string{"leverage"}
+	sizeKeys       = []string{"size"}
+	apiKeyKeys     = []string{"apikey"}
+)
+
+// defaultStrategyMessage matches TradingView's default strategy alert text:
+// "order {{strategy.order.action}} @ {{strategy.order.contracts}} filled on {{ticker}}. ..."
+var defaultStrategyMessage = regexp.MustCompile(`(?i)order\s+(buy|sell)\s+@\s+([\d.]+)\s+filled\s+on\s+([\w:.!-]+?)\.?(\s|$)`)
+
+// pairPattern matches key=value and key: value tokens in plain-text messages
+var pairPattern = regexp.MustCompile(`([A-Za-z][\w.]*)\s*[=:]\s*("[^"]*"|[^\s,;]+)`)
+
+// Parse reads a TradingView alert body: either a JSON object or plain text
+// made of key=value / key: value pairs (newline, comma or space separated),
+// optionally prefixed by TradingView's default strategy message.
+func Parse(body []byte) (Alert, error) {
+	text := strings.TrimSpace(string(body))
+	if text == "" {
+		return nil, fmt.Errorf("empty alert message")
+	}
+
+	alert := Alert{}
+
+	if strings.HasPrefix(text, "{") {
+		var raw map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON alert: %v", err)
+		}
+		for key, value := range raw {
+			switch v := value.(type) {
+			case string:
+				alert[strings.ToLower(key)] = strings.TrimSpace(v)
+			case float64:
+				alert[strings.ToLower(key)] = strconv.FormatFloat(v, 'f', -1, 64)
+			case bool:
+				alert[strings.ToLower(key)] = strconv.FormatBool(v)
+			}
+		}
+		return alert, nil
+	}
+
+	for _, m := range pairPattern.FindAllStringSubmatch(text, -1) {
+		alert[strings.ToLower(m[1])] = strings.Trim(m[2], `"`)
+	}
+
+	if m := defaultStrategyMessage.FindStringSubmatch(text); m != nil {
+		alert.setDefault("action", m[1])
+		alert.setDefault("contracts", m[2])
+		alert.setDefault("ticker", m[3])
+	}
+
+	if len(alert) == 0 {
+		return nil, fmt.Errorf("no key=value fields found in alert message")
+	}
+
+	return alert, nil
+}
+
+// IsTemplated reports whether a JSON body should go through a template rather
+// than being bound as a plain TradeRequest
+func IsTemplated(body []byte) bool {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return true // Plain-text message
+	}
+	for key := range raw {
+		if strings.EqualFold(key, "template") {
+			return true
+		}
+	}
+	return false
+}
+
+// Template returns the template name referenced by the alert
+func (a Alert) Template() string {
+	return a.get(templateKeys)
+}
+
+// APIKey returns the apiKey field, if the alert carries one
+func (a Alert) APIKey() string {
+	return a.get(apiKeyKeys)
+}
+
+// BuildTradeRequest fills a trade request from the alert fields and the template.
+// Explicit alert values (stopLoss, takeProfit, leverage, size) override the template.
+func BuildTradeRequest(a Alert, tpl *models.TradingViewTemplate) (*models.TradeRequest, error) {
+	for key, value := range a {
+		if strings.Contains(value, "{{") {
+			return nil, fmt.Errorf("field %q has an unresolved placeholder %s", key, value)
+		}
+	}
+
+	symbol := NormalizeSymbol(a.get(symbolKeys))
+	if symbol == "" {
+		return nil, fmt.Errorf("alert has no ticker")
+	}
+
+	side, err := normalizeAction(a.get(actionKeys))
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := a.float(priceKeys)
+	if err != nil {
+		return nil, err
+	}
+	if price <= 0 {
+		return nil, fmt.Errorf("alert has no price (use price={{close}})")
+	}
+
+	req := &models.TradeRequest{
+		UserID:     tpl.UserID,
+		Symbol:     symbol,
+		Side:       side,
+		EntryPrice: price,
+		Leverage:   tpl.Leverage,
+		Size:       tpl.Size,
+		OrderType:  tpl.OrderType,
+		MarginType: tpl.MarginType,
+	}
+
+	if req.StopLoss, err = a.float(stopLossKeys); err != nil {
+		return nil, err
+	}
+	if req.StopLoss == 0 && tpl.StopLossPercent > 0 {
+		req.StopLoss = offsetPrice(price, tpl.StopLossPercent, side == "SELL")
+	}
+
+	if req.TakeProfit, err = a.float(takeProfitKeys); err != nil {
+		return nil, err
+	}
+	if req.TakeProfit == 0 && tpl.TakeProfitPercent > 0 {
+		req.TakeProfit = offsetPrice(price, tpl.TakeProfitPercent, side == "BUY")
+	}
+
+	if req.StopLoss == 0 || req.TakeProfit == 0 {
+		return nil, fmt.Errorf("stop loss and take profit must come from the alert or the template percentages")
+	}
+
+	if leverage, err := a.float(leverageKeys); err != nil {
+		return nil, err
+	} else if leverage > 0 {
+		req.Leverage = int(leverage)
+	}
+
+	if size, err := a.float(sizeKeys); err != nil {
+		return nil, err
+	} else if size > 0 {
+		req.Size = size
+	}
+
+	return req, nil
+}
+
+// NormalizeSymbol converts TradingView tickers ("BINANCE:BTCUSDT.P", "BTCUSDTPERP") to Binance symbols
+func NormalizeSymbol(ticker string) string {
+	symbol := strings.ToUpper(strings.TrimSpace(ticker))
+	if i := strings.LastIndex(symbol, ":"); i >= 0 {
+		symbol = symbol[i+1:]
+	}
+	symbol = strings.TrimSuffix(symbol, ".P")
+	symbol = strings.TrimSuffix(symbol, "PERP")
+	return symbol
+}
+
+// normalizeAction maps TradingView order actions to BUY/SELL
+func normalizeAction(action string) (string, error) {
+	switch strings.ToLower(action) {
+	case "buy", "long":
+		return "BUY", nil
+	case "sell", "short":
+		return "SELL", nil
+	case "":
+		return "", fmt.Errorf("alert has no action (use action={{strategy.order.action}})")
+	default:
+		return "", fmt.Errorf("unsupported action %q (expected buy/sell/long/short)", action)
+	}
+}
+
+// offsetPrice moves price up (or down) by percent
+func offsetPrice(price, percent float64, up bool) float64 {
+	if up {
+		return price * (1 + percent/100)
+	}
+	return price * (1 - percent/100)
+}
+
+func (a Alert) setDefault(key, value string) {
+	if _, ok := a[key]; !ok {
+		a[key] = value
+	}
+}
+
+func (a Alert) get(keys []string) string {
+	for _, key := range keys {
+		if value := a[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func (a Alert) float(keys []string) (float64, error) {
+	for _, key := range keys {
+		value := a[key]
+		if value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+		if err != nil {
+			return 0, fmt.Errorf("field %q is not a number: %s", key, value)
+		}
+		return f, nil
+	}
+	return 0, nil
+}
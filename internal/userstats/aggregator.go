@@ -0,0 +1,190 @@
+package userstats
+
+import (
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"sync"
+	"time"
+)
+
+// Store persists the stats and the trades they are recounted from
+type Store interface {
+	GetUserTrades(ctx context.Context, userID string) ([]*models.Trade, error)
+	GetUserTradeStats(ctx context.Context, userID string) (*models.UserTradeStats, error)
+	SaveUserTradeStats(ctx context.Context, stats *models.UserTradeStats) error
+	GetTradeContribution(ctx context.Context, userID, tradeID string) (*models.TradeContribution, error)
+	SaveTradeContribution(ctx context.Context, contribution *models.TradeContribution) error
+	GetTradeContributions(ctx context.Context, userID string) ([]*models.TradeContribution, error)
+}
+
+// Aggregator keeps every user's stats up to date as their trades close, so a
+// summary is one read instead of a download of the user's trade history.
+// Summaries are cached in memory for the cache TTL; a user's entry is dropped
+// as soon as one of their trades is counted here.
+type Aggregator struct {
+	store Store
+	ttl   time.Duration
+
+	mu    sync.Mutex
+	users map[string]*sync.Mutex // Serializes the updates of each user's stats
+	cache map[string]*models.UserSummary
+}
+
+// NewAggregator creates an aggregator; register its Record with OnTradeSaved
+func NewAggregator(store Store, ttl time.Duration) *Aggregator {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &Aggregator{
+		store: store,
+		ttl:   ttl,
+		users: make(map[string]*sync.Mutex),
+		cache: make(map[string]*models.UserSummary),
+	}
+}
+
+// Record counts a CLOSED trade in its owner's stats. A trade counted before
+// has its share replaced when its PnL, fees or size changed (e.g. by the PnL
+// reconciler), so repeated writes never count it twice.
+func (a *Aggregator) Record(ctx context.Context, trade *models.Trade) {
+	if trade.UserID == "" || trade.Status != "CLOSED" {
+		return
+	}
+	contribution := models.ContributionOf(trade)
+
+	unlock := a.lock(trade.UserID)
+	defer unlock()
+
+	previous, err := a.store.GetTradeContribution(ctx, trade.UserID, trade.ID)
+	if err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("User stats: failed to read what trade %s counted", trade.ID)
+		return
+	}
+	if previous != nil && *previous == *contribution {
+		return
+	}
+
+	stats, err := a.store.GetUserTradeStats(ctx, trade.UserID)
+	if err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("User stats: failed to read the stats of %s", trade.UserID)
+		return
+	}
+	if stats == nil {
+		// Never counted: the first summary recounts the user's trades,
+		// this one included
+		return
+	}
+
+	if previous != nil {
+		stats.Remove(previous)
+	}
+	stats.Add(contribution)
+	stats.UpdatedAt = time.Now().Unix()
+
+	// The trade's share is stored first: if the stats write then fails, the
+	// trade is left out (until a refresh) rather than counted twice later
+	if err := a.store.SaveTradeContribution(ctx, contribution); err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("User stats: failed to count trade %s", trade.ID)
+		return
+	}
+	if err := a.store.SaveUserTradeStats(ctx, stats); err != nil {
+		logging.Warn().Err(err).Str(logging.FieldTradeID, trade.ID).Msgf("User stats: failed to save the stats of %s, refresh their summary to recount", trade.UserID)
+	}
+	a.invalidate(trade.UserID)
+}
+
+// Summary returns a user's summary, from the cache unless it is older than the
+// cache TTL. Users whose trades were never counted are recounted first.
+func (a *Aggregator) Summary(ctx context.Context, userID string) (*models.UserSummary, error) {
+	a.mu.Lock()
+	cached, ok := a.cache[userID]
+	a.mu.Unlock()
+	if ok && time.Since(time.Unix(cached.CachedAt, 0)) < a.ttl {
+		return cached, nil
+	}
+
+	stats, err := a.store.GetUserTradeStats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		return a.Refresh(ctx, userID)
+	}
+	return a.cached(userID, stats), nil
+}
+
+// Refresh recounts a user's stats from their closed trades and returns the
+// new summary. Trades counted before and no longer listed (archived) stay
+// counted with their last known share.
+func (a *Aggregator) Refresh(ctx context.Context, userID string) (*models.UserSummary, error) {
+	unlock := a.lock(userID)
+	defer unlock()
+
+	trades, err := a.store.GetUserTrades(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	counted, err := a.store.GetTradeContributions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	contributions := make(map[string]*models.TradeContribution, len(counted))
+	for _, contribution := range counted {
+		contributions[contribution.TradeID] = contribution
+	}
+	for _, trade := range trades {
+		if trade.Status != "CLOSED" {
+			continue
+		}
+		contribution := models.ContributionOf(trade)
+		if previous, ok := contributions[trade.ID]; ok && *previous == *contribution {
+			continue
+		}
+		if err := a.store.SaveTradeContribution(ctx, contribution); err != nil {
+			return nil, err
+		}
+		contributions[trade.ID] = contribution
+	}
+
+	now := time.Now().Unix()
+	stats := &models.UserTradeStats{UserID: userID, UpdatedAt: now, RebuiltAt: now}
+	for _, contribution := range contributions {
+		stats.Add(contribution)
+	}
+	if err := a.store.SaveUserTradeStats(ctx, stats); err != nil {
+		return nil, err
+	}
+	logging.Info().Msgf("User stats: recounted %d closed trades of %s", stats.ClosedTrades, userID)
+	return a.cached(userID, stats), nil
+}
+
+// cached stores a user's summary in the cache
+func (a *Aggregator) cached(userID string, stats *models.UserTradeStats) *models.UserSummary {
+	summary := models.NewUserSummary(stats, time.Now().Unix())
+	a.mu.Lock()
+	a.cache[userID] = summary
+	a.mu.Unlock()
+	return summary
+}
+
+func (a *Aggregator) invalidate(userID string) {
+	a.mu.Lock()
+	delete(a.cache, userID)
+	a.mu.Unlock()
+}
+
+// lock serializes the stats updates of one user and returns the unlock
+func (a *Aggregator) lock(userID string) func() {
+	a.mu.Lock()
+	userLock, ok := a.users[userID]
+	if !ok {
+		userLock = &sync.Mutex{}
+		a.users[userID] = userLock
+	}
+	a.mu.Unlock()
+
+	userLock.Lock()
+	return userLock.Unlock
+}
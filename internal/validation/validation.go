@@ -0,0 +1,216 @@
+// Package validation reports rejected request bodies field by field: which
+// field, a machine-readable code, the rule it broke and a suggested fix.
+package validation
+
+import (
+	"crypto-trading-api/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Error codes
+const (
+	CodeRequired        = "required"         // Missing or zero
+	CodeInvalid         = "invalid"          // Not an accepted value
+	CodeType            = "invalid_type"     // Wrong JSON type
+	CodeSyntax          = "invalid_json"     // Body is not valid JSON
+	CodeRange           = "out_of_range"     // Below a minimum or above a maximum
+	CodeConflict        = "conflict"         // Inconsistent with another field
+	CodeTickSize        = "tick_size"        // Price not a multiple of the symbol's tick size
+	CodeMinQuantity     = "min_quantity"     // Order quantity below the symbol's lot size
+	CodeMaxQuantity     = "max_quantity"     // Order quantity above the symbol's lot size
+	CodeMinNotional     = "min_notional"     // Order value below the symbol's minimum
+	CodeUnknown         = "unknown"          // Unknown symbol, template or preset
+	CodeNotTradeable    = "not_tradeable"    // Symbol listed but not trading
+	CodeTriggerDistance = "trigger_distance" // SL/TP too close to the mark price
+)
+
+func init() {
+	// Name fields as clients send them (stopLoss, not StopLoss). Registered
+	// before any request is bound, since the validator caches struct fields.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonName)
+	}
+}
+
+// Errors is the list of field errors of a rejected request
+type Errors []models.FieldError
+
+// Add records a field error
+func (e *Errors) Add(field, code, constraint, message, suggestion string) {
+	*e = append(*e, models.FieldError{
+		Field:      field,
+		Code:       code,
+		Constraint: constraint,
+		Message:    message,
+		Suggestion: suggestion,
+	})
+}
+
+// Err returns the errors as an error, nil when there are none
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Error joins the messages, keeping the error string readable by clients
+// that ignore the details
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Details returns the field errors in err, nil if it carries none
+func Details(err error) []models.FieldError {
+	var errs Errors
+	if errors.As(err, &errs) {
+		return errs
+	}
+	return nil
+}
+
+// FromBinding turns a gin binding error into field errors: failed binding
+// tags, JSON type mismatches and malformed or empty bodies. Other errors are
+// returned unchanged.
+func FromBinding(err error) error {
+	var errs Errors
+
+	var tagErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &tagErrs):
+		for _, tagErr := range tagErrs {
+			errs = append(errs, fromTag(tagErr))
+		}
+	case errors.As(err, &typeErr):
+		want := jsonType(typeErr.Type)
+		errs.Add(typeErr.Field, CodeType, want,
+			fmt.Sprintf("%s must be %s, got %s", fieldOrBody(typeErr.Field), want, typeErr.Value),
+			fmt.Sprintf("Send %s as %s", fieldOrBody(typeErr.Field), want))
+	case errors.As(err, &syntaxErr):
+		errs.Add("", CodeSyntax, "",
+			fmt.Sprintf("malformed JSON at byte %d: %v", syntaxErr.Offset, syntaxErr),
+			"Check for missing quotes, commas or braces")
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		errs.Add("", CodeSyntax, "", "request body is empty or incomplete", "Send a JSON object")
+	default:
+		return err
+	}
+	return errs
+}
+
+// fromTag describes a failed binding tag
+func fromTag(tagErr validator.FieldError) models.FieldError {
+	field := fieldPath(tagErr)
+	param := tagErr.Param()
+	constraint := tagErr.Tag()
+	if param != "" {
+		constraint += "=" + param
+	}
+
+	// min/max/len count characters or items for strings, slices and maps
+	unit := ""
+	switch tagErr.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	fieldErr := models.FieldError{Field: field, Code: CodeInvalid, Constraint: constraint}
+	switch tagErr.Tag() {
+	case "required":
+		fieldErr.Code = CodeRequired
+		fieldErr.Message = field + " is required"
+		fieldErr.Suggestion = "Add " + field + " to the request"
+	case "min", "gte":
+		fieldErr.Code = CodeRange
+		fieldErr.Message = fmt.Sprintf("%s must be at least %s%s", field, param, unit)
+		fieldErr.Suggestion = fmt.Sprintf("Use %s or more%s", param, unit)
+	case "max", "lte":
+		fieldErr.Code = CodeRange
+		fieldErr.Message = fmt.Sprintf("%s must be at most %s%s", field, param, unit)
+		fieldErr.Suggestion = fmt.Sprintf("Use %s or less%s", param, unit)
+	case "gt":
+		fieldErr.Code = CodeRange
+		fieldErr.Message = fmt.Sprintf("%s must be greater than %s%s", field, param, unit)
+		fieldErr.Suggestion = fmt.Sprintf("Use more than %s%s", param, unit)
+	case "lt":
+		fieldErr.Code = CodeRange
+		fieldErr.Message = fmt.Sprintf("%s must be less than %s%s", field, param, unit)
+		fieldErr.Suggestion = fmt.Sprintf("Use less than %s%s", param, unit)
+	case "len":
+		fieldErr.Code = CodeRange
+		fieldErr.Message = fmt.Sprintf("%s must be exactly %s%s long", field, param, unit)
+	case "oneof":
+		fieldErr.Message = fmt.Sprintf("%s must be one of %s", field, strings.ReplaceAll(param, " ", ", "))
+		fieldErr.Suggestion = "Use one of " + strings.ReplaceAll(param, " ", ", ")
+	case "url", "http_url":
+		fieldErr.Message = field + " must be a URL"
+		fieldErr.Suggestion = "Use an absolute URL such as https://example.com/hook"
+	case "email":
+		fieldErr.Message = field + " must be an email address"
+	default:
+		fieldErr.Message = fmt.Sprintf("%s failed the %s check", field, constraint)
+	}
+	return fieldErr
+}
+
+// fieldPath is the field's JSON path without the struct name
+// (TradeRequest.stopLoss -> stopLoss, Preset.legs[0].size -> legs[0].size)
+func fieldPath(tagErr validator.FieldError) string {
+	if _, path, ok := strings.Cut(tagErr.Namespace(), "."); ok {
+		return path
+	}
+	return tagErr.Field()
+}
+
+// jsonName returns the name a struct field has in JSON
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// jsonType describes a Go type as a JSON type
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
+
+func fieldOrBody(field string) string {
+	if field == "" {
+		return "the body"
+	}
+	return field
+}
@@ -0,0 +1,165 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto-trading-api/internal/logging"
+	"crypto-trading-api/internal/models"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Trade lifecycle events delivered to webhooks
+const (
+	EventFilled = "FILLED"
+	EventSLHit  = "SL_HIT"
+	EventTPHit  = "TP_HIT"
+	EventClosed = "CLOSED"
+)
+
+// Delivery retry policy (backoff doubles after each failed attempt)
+const (
+	maxAttempts  = 4
+	retryBackoff = 2 * time.Second
+)
+
+// Events lists every event a webhook can subscribe to
+var Events = []string{EventFilled, EventSLHit, EventTPHit, EventClosed}
+
+// Store provides webhook registrations
+type Store interface {
+	GetWebhooks(ctx context.Context) ([]*models.Webhook, error)
+}
+
+// Dispatcher delivers signed trade lifecycle payloads to registered webhooks.
+// A nil Dispatcher is valid and drops all events.
+type Dispatcher struct {
+	store      Store
+	httpClient *http.Client
+}
+
+// NewDispatcher creates a webhook dispatcher. Deliveries connect directly,
+// without a proxy, and only to public addresses (see CheckURL).
+func NewDispatcher(store Store) *Dispatcher {
+	transport := &http.Transport{
+		DialContext:         newDialer().DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &Dispatcher{
+		store:      store,
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: transport},
+	}
+}
+
+// Dispatch sends the event to every webhook of the trade's user in the background
+func (d *Dispatcher) Dispatch(event string, trade *models.Trade) {
+	if d == nil || trade == nil {
+		return
+	}
+
+	// Snapshot the trade; callers keep mutating theirs
+	snapshot := *trade
+
+	go func() {
+		hooks, err := d.store.GetWebhooks(context.Background())
+		if err != nil {
+			logging.Warn().Err(err).Msg("Webhooks: failed to load registrations")
+			return
+		}
+
+		payload := &models.WebhookPayload{
+			ID:        uuid.New().String(),
+			Event:     event,
+			Trade:     &snapshot,
+			Timestamp: time.Now().Unix(),
+		}
+
+		for _, hook := range hooks {
+			if hook.UserID == snapshot.UserID && Subscribed(hook, event) {
+				go d.deliver(hook, payload)
+			}
+		}
+	}()
+}
+
+// Subscribed reports whether a webhook wants an event (no list = all events)
+func Subscribed(hook *models.Webhook, event string) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, e := range hook.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// deliver POSTs the payload, retrying with exponential backoff on failure
+func (d *Dispatcher) deliver(hook *models.Webhook, payload *models.WebhookPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logging.Warn().Err(err).Msg("Webhooks: failed to marshal payload")
+		return
+	}
+
+	backoff := retryBackoff
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = d.post(hook, payload, body)
+		if err == nil {
+			logging.Info().Str(logging.FieldTradeID, payload.Trade.ID).Msgf("Webhook %s delivered %s for trade %s", hook.ID, payload.Event, payload.Trade.ID)
+			return
+		}
+
+		logging.Warn().Err(err).Msgf("Webhook %s attempt %d/%d failed", hook.ID, attempt, maxAttempts)
+		if attempt < maxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	logging.Error().Str(logging.FieldTradeID, payload.Trade.ID).Msgf("Webhook %s gave up on %s for trade %s", hook.ID, payload.Event, payload.Trade.ID)
+}
+
+func (d *Dispatcher) post(hook *models.Webhook, payload *models.WebhookPayload, body []byte) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest("POST", hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-ID", payload.ID)
+	req.Header.Set("X-Webhook-Event", payload.Event)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+Sign(hook.Secret, timestamp, body))
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign computes the hex HMAC-SHA256 of "timestamp.body" with the webhook secret
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,20 @@
+package webhooks
+
+import "crypto-trading-api/internal/events"
+
+// Listen delivers webhooks for positions closed on the bus: SL_HIT and
+// TP_HIT for protective order fills, CLOSED for manual closes, here or
+// outside the API, and for positions the reconciler found gone
+func (d *Dispatcher) Listen(bus *events.Bus) {
+	bus.Subscribe(events.TopicPositionClosed, "webhooks", func(event events.Event) {
+		closed := event.(events.PositionClosed)
+		switch closed.Reason {
+		case events.CloseStopLoss:
+			d.Dispatch(EventSLHit, closed.Trade)
+		case events.CloseTakeProfit:
+			d.Dispatch(EventTPHit, closed.Trade)
+		case events.CloseManual, events.CloseExternal, events.CloseReconciled:
+			d.Dispatch(EventClosed, closed.Trade)
+		}
+	})
+}